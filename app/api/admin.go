@@ -0,0 +1,819 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/magicpool-co/pool/core/audit"
+	"github.com/magicpool-co/pool/core/credit"
+	"github.com/magicpool-co/pool/core/trade"
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+var (
+	errAdminUnauthorized = newHttpError(401, "Unauthorized", "Unauthorized", false)
+	errAdminForbidden    = newHttpError(403, "Forbidden", "Role not allowed for this action", true)
+	errBatchNotFound     = newHttpError(404, "BatchNotFound", "Batch not found", false)
+)
+
+func newAdminActionError(err error) httpResponse {
+	return newHttpError(409, "AdminActionFailed", err.Error(), true)
+}
+
+/* roles */
+
+// roles are hierarchical, every role is allowed to do everything the roles below it can do:
+//
+//	viewer     read-only access to the audit log, rounds, batches, recipients and flagged miners
+//	operator   re-credit and orphan rounds, cancel batches, ban miners and reset their audits
+//	treasurer  adjust balances and edit the fee recipients
+type adminRole int
+
+const (
+	adminRoleViewer adminRole = iota + 1
+	adminRoleOperator
+	adminRoleTreasurer
+)
+
+var adminRoleNames = map[adminRole]string{
+	adminRoleViewer:    "viewer",
+	adminRoleOperator:  "operator",
+	adminRoleTreasurer: "treasurer",
+}
+
+func (r adminRole) String() string {
+	return adminRoleNames[r]
+}
+
+func parseAdminRole(raw string) (adminRole, error) {
+	for role, name := range adminRoleNames {
+		if name == strings.ToLower(raw) {
+			return role, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown admin role %s", raw)
+}
+
+type adminUser struct {
+	name      string
+	role      adminRole
+	tokenHash [32]byte
+}
+
+type adminContextKey struct{}
+
+// SetAdminTokens enables the /admin routes. the tokens are formatted as a comma
+// separated list of "name:role:token" entries, the name is what ends up in the audit log.
+func (ctx *Context) SetAdminTokens(raw string) error {
+	users := make([]*adminUser, 0)
+	names := make(map[string]bool)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || len(parts[0]) == 0 || len(parts[2]) == 0 {
+			return fmt.Errorf("invalid admin token entry")
+		} else if names[parts[0]] {
+			return fmt.Errorf("duplicate admin %s", parts[0])
+		}
+
+		role, err := parseAdminRole(parts[1])
+		if err != nil {
+			return err
+		}
+
+		names[parts[0]] = true
+		users = append(users, &adminUser{
+			name:      parts[0],
+			role:      role,
+			tokenHash: sha256.Sum256([]byte(parts[2])),
+		})
+	}
+
+	ctx.adminUsers = users
+
+	return nil
+}
+
+func (ctx *Context) authenticateAdmin(r *http.Request) *adminUser {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if len(token) == 0 {
+		return nil
+	}
+
+	// compare the hashes so every comparison takes the same time regardless of the token length
+	var match *adminUser
+	tokenHash := sha256.Sum256([]byte(token))
+	for _, user := range ctx.adminUsers {
+		if subtle.ConstantTimeCompare(tokenHash[:], user.tokenHash[:]) == 1 {
+			match = user
+		}
+	}
+
+	return match
+}
+
+func (ctx *Context) requireAdmin(role adminRole, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := ctx.authenticateAdmin(r)
+		if user == nil {
+			ctx.writeErrorResponse(w, errAdminUnauthorized)
+			return
+		} else if user.role < role {
+			ctx.writeErrorResponse(w, errAdminForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminContextKey{}, user)))
+	})
+}
+
+/* audit log */
+
+// auditAdminAction runs an admin action between two append-only audit log entries. the
+// attempt is written before the action runs and the action is refused if it can't be
+// written, so there is never a change without a record of it (even if the process dies
+// halfway through). the outcome references the attempt once the action has finished.
+func (ctx *Context) auditAdminAction(
+	r *http.Request,
+	action, target string,
+	params interface{},
+	fn func() error,
+) error {
+	user, ok := r.Context().Value(adminContextKey{}).(*adminUser)
+	if !ok {
+		return errAdminUnauthorized
+	}
+
+	var rawParams *string
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		rawParams = types.StringPtr(string(data))
+	}
+
+	attempt := &pooldb.AdminAuditLog{
+		Actor:  user.name,
+		Role:   user.role.String(),
+		Action: action,
+		Target: target,
+		Params: rawParams,
+		Status: "attempted",
+	}
+
+	attemptID, err := pooldb.InsertAdminAuditLog(ctx.pooldb.Writer(), attempt)
+	if err != nil {
+		return err
+	}
+
+	actionErr := fn()
+
+	outcome := &pooldb.AdminAuditLog{
+		RefID:  types.Uint64Ptr(attemptID),
+		Actor:  user.name,
+		Role:   user.role.String(),
+		Action: action,
+		Target: target,
+		Status: "succeeded",
+	}
+	if actionErr != nil {
+		outcome.Status = "failed"
+		outcome.ErrorMessage = types.StringPtr(actionErr.Error())
+	}
+
+	_, err = pooldb.InsertAdminAuditLog(ctx.pooldb.Writer(), outcome)
+	if err != nil {
+		ctx.logger.Error(fmt.Errorf("admin: failed to write outcome for audit log %d: %v", attemptID, err))
+	}
+
+	if actionErr != nil {
+		return newAdminActionError(actionErr)
+	}
+
+	return nil
+}
+
+/* responses */
+
+func nullBigIntString(value dbcl.NullBigInt) *string {
+	if !value.Valid || value.BigInt == nil {
+		return nil
+	}
+
+	return types.StringPtr(value.BigInt.String())
+}
+
+type AdminAuditLog struct {
+	ID           uint64    `json:"id"`
+	RefID        *uint64   `json:"refID"`
+	Actor        string    `json:"actor"`
+	Role         string    `json:"role"`
+	Action       string    `json:"action"`
+	Target       string    `json:"target"`
+	Params       *string   `json:"params"`
+	Status       string    `json:"status"`
+	ErrorMessage *string   `json:"errorMessage"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type AdminRound struct {
+	ID           uint64    `json:"id"`
+	ChainID      string    `json:"chain"`
+	MinerID      uint64    `json:"minerID"`
+	Height       uint64    `json:"height"`
+	Hash         string    `json:"hash"`
+	CoinbaseTxID *string   `json:"coinbaseTxID"`
+	Value        *string   `json:"value"`
+	Solo         bool      `json:"solo"`
+	SelfPaid     bool      `json:"selfPaid"`
+	Pending      bool      `json:"pending"`
+	Uncle        bool      `json:"uncle"`
+	Orphan       bool      `json:"orphan"`
+	Mature       bool      `json:"mature"`
+	Spent        bool      `json:"spent"`
+	Held         bool      `json:"held"`
+	CreatedAt    time.Time `json:"createdAt"`
+
+	BalanceInputs  []*AdminBalanceInput  `json:"balanceInputs"`
+	BalanceOutputs []*AdminBalanceOutput `json:"balanceOutputs"`
+}
+
+type AdminBalanceInput struct {
+	ID              uint64  `json:"id"`
+	MinerID         uint64  `json:"minerID"`
+	OutChainID      string  `json:"outChain"`
+	BalanceOutputID *uint64 `json:"balanceOutputID"`
+	BatchID         *uint64 `json:"batchID"`
+	Value           *string `json:"value"`
+	PoolFees        *string `json:"poolFees"`
+	Mature          bool    `json:"mature"`
+	Pending         bool    `json:"pending"`
+}
+
+type AdminBalanceOutput struct {
+	ID          uint64  `json:"id"`
+	ChainID     string  `json:"chain"`
+	MinerID     uint64  `json:"minerID"`
+	OutPayoutID *uint64 `json:"outPayoutID"`
+	Value       *string `json:"value"`
+	Mature      bool    `json:"mature"`
+	Spent       bool    `json:"spent"`
+}
+
+type AdminBatch struct {
+	ID          uint64     `json:"id"`
+	ExchangeID  int        `json:"exchangeID"`
+	Status      int        `json:"status"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+type AdminMinerShareAudit struct {
+	MinerID                 uint64    `json:"minerID"`
+	ChainID                 string    `json:"chain"`
+	AcceptedShares          uint64    `json:"acceptedShares"`
+	HighDiffShares          uint64    `json:"highDiffShares"`
+	NearBlockShares         uint64    `json:"nearBlockShares"`
+	EarlyShares             uint64    `json:"earlyShares"`
+	FoundBlocks             uint64    `json:"foundBlocks"`
+	ExpectedBlocks          float64   `json:"expectedBlocks"`
+	ExpectedNearBlockShares float64   `json:"expectedNearBlockShares"`
+	Excluded                bool      `json:"excluded"`
+	Banned                  bool      `json:"banned"`
+	FlagReason              *string   `json:"flagReason"`
+	UpdatedAt               time.Time `json:"updatedAt"`
+}
+
+type AdminRecipient struct {
+	MinerID    uint64 `json:"minerID"`
+	Chain      string `json:"chain"`
+	Address    string `json:"address"`
+	FeePercent uint64 `json:"feePercent"`
+}
+
+/* viewer handlers */
+
+type adminAuditArgs struct {
+	page string
+	size string
+}
+
+func (ctx *Context) getAdminAuditLogs(args adminAuditArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, size, err := ctx.parsePageSize(args.page, args.size)
+		if err != nil {
+			ctx.writeErrorResponse(w, errInvalidParameters)
+			return
+		}
+
+		logs, err := pooldb.GetAdminAuditLogs(ctx.pooldb.Reader(), page, size)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		count, err := pooldb.GetAdminAuditLogsCount(ctx.pooldb.Reader())
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		items := make([]interface{}, len(logs))
+		for i, log := range logs {
+			items[i] = &AdminAuditLog{
+				ID:           log.ID,
+				RefID:        log.RefID,
+				Actor:        log.Actor,
+				Role:         log.Role,
+				Action:       log.Action,
+				Target:       log.Target,
+				Params:       log.Params,
+				Status:       log.Status,
+				ErrorMessage: log.ErrorMessage,
+				CreatedAt:    log.CreatedAt,
+			}
+		}
+
+		ctx.writeOkResponse(w, paginatedResponse{
+			Page:    page,
+			Size:    size,
+			Results: count,
+			Next:    (page+1)*size < count,
+			Items:   items,
+		})
+	})
+}
+
+type adminRoundArgs struct {
+	id string
+}
+
+func (ctx *Context) getAdminRound(args adminRoundArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roundID, err := strconv.ParseUint(args.id, 10, 64)
+		if err != nil {
+			ctx.writeErrorResponse(w, errRoundNotFound)
+			return
+		}
+
+		round, err := pooldb.GetRound(ctx.pooldb.Reader(), roundID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		} else if round == nil {
+			ctx.writeErrorResponse(w, errRoundNotFound)
+			return
+		}
+
+		balanceInputs, err := pooldb.GetBalanceInputsByRound(ctx.pooldb.Reader(), roundID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		balanceOutputs, err := pooldb.GetBalanceOutputsByRound(ctx.pooldb.Reader(), roundID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		data := &AdminRound{
+			ID:           round.ID,
+			ChainID:      round.ChainID,
+			MinerID:      round.MinerID,
+			Height:       round.Height,
+			Hash:         round.Hash,
+			CoinbaseTxID: round.CoinbaseTxID,
+			Value:        nullBigIntString(round.Value),
+			Solo:         round.Solo,
+			SelfPaid:     round.SelfPaid,
+			Pending:      round.Pending,
+			Uncle:        round.Uncle,
+			Orphan:       round.Orphan,
+			Mature:       round.Mature,
+			Spent:        round.Spent,
+			Held:         round.Held,
+			CreatedAt:    round.CreatedAt,
+
+			BalanceInputs:  make([]*AdminBalanceInput, len(balanceInputs)),
+			BalanceOutputs: make([]*AdminBalanceOutput, len(balanceOutputs)),
+		}
+
+		for i, balanceInput := range balanceInputs {
+			data.BalanceInputs[i] = &AdminBalanceInput{
+				ID:              balanceInput.ID,
+				MinerID:         balanceInput.MinerID,
+				OutChainID:      balanceInput.OutChainID,
+				BalanceOutputID: balanceInput.BalanceOutputID,
+				BatchID:         balanceInput.BatchID,
+				Value:           nullBigIntString(balanceInput.Value),
+				PoolFees:        nullBigIntString(balanceInput.PoolFees),
+				Mature:          balanceInput.Mature,
+				Pending:         balanceInput.Pending,
+			}
+		}
+
+		for i, balanceOutput := range balanceOutputs {
+			data.BalanceOutputs[i] = &AdminBalanceOutput{
+				ID:          balanceOutput.ID,
+				ChainID:     balanceOutput.ChainID,
+				MinerID:     balanceOutput.MinerID,
+				OutPayoutID: balanceOutput.OutPayoutID,
+				Value:       nullBigIntString(balanceOutput.Value),
+				Mature:      balanceOutput.Mature,
+				Spent:       balanceOutput.Spent,
+			}
+		}
+
+		ctx.writeOkResponse(w, data)
+	})
+}
+
+type adminBatchesArgs struct {
+	page string
+	size string
+}
+
+func (ctx *Context) getAdminBatches(args adminBatchesArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, size, err := ctx.parsePageSize(args.page, args.size)
+		if err != nil {
+			ctx.writeErrorResponse(w, errInvalidParameters)
+			return
+		}
+
+		batches, err := pooldb.GetExchangeBatches(ctx.pooldb.Reader(), page, size)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		count, err := pooldb.GetExchangeBatchesCount(ctx.pooldb.Reader())
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		items := make([]interface{}, len(batches))
+		for i, batch := range batches {
+			items[i] = &AdminBatch{
+				ID:          batch.ID,
+				ExchangeID:  batch.ExchangeID,
+				Status:      batch.Status,
+				CreatedAt:   batch.CreatedAt,
+				CompletedAt: batch.CompletedAt,
+			}
+		}
+
+		ctx.writeOkResponse(w, paginatedResponse{
+			Page:    page,
+			Size:    size,
+			Results: count,
+			Next:    (page+1)*size < count,
+			Items:   items,
+		})
+	})
+}
+
+func (ctx *Context) getAdminRecipients() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recipients, err := pooldb.GetRecipients(ctx.pooldb.Reader())
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		data := make([]*AdminRecipient, len(recipients))
+		for i, recipient := range recipients {
+			data[i] = &AdminRecipient{
+				MinerID:    recipient.ID,
+				Chain:      recipient.ChainID,
+				Address:    recipient.Address,
+				FeePercent: types.Uint64Value(recipient.RecipientFeePercent),
+			}
+		}
+
+		ctx.writeOkResponse(w, data)
+	})
+}
+
+func (ctx *Context) getAdminFlaggedMiners() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		audits, err := pooldb.GetFlaggedMinerShareAudits(ctx.pooldb.Reader())
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		data := make([]*AdminMinerShareAudit, len(audits))
+		for i, obj := range audits {
+			data[i] = &AdminMinerShareAudit{
+				MinerID:                 obj.MinerID,
+				ChainID:                 obj.ChainID,
+				AcceptedShares:          obj.AcceptedShares,
+				HighDiffShares:          obj.HighDiffShares,
+				NearBlockShares:         obj.NearBlockShares,
+				EarlyShares:             obj.EarlyShares,
+				FoundBlocks:             obj.FoundBlocks,
+				ExpectedBlocks:          obj.ExpectedBlocks,
+				ExpectedNearBlockShares: obj.ExpectedNearBlockShares,
+				Excluded:                obj.Excluded,
+				Banned:                  obj.Banned,
+				FlagReason:              obj.FlagReason,
+				UpdatedAt:               obj.UpdatedAt,
+			}
+		}
+
+		ctx.writeOkResponse(w, data)
+	})
+}
+
+/* operator handlers */
+
+func (ctx *Context) recreditAdminRound(args adminRoundArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roundID, err := strconv.ParseUint(args.id, 10, 64)
+		if err != nil {
+			ctx.writeErrorResponse(w, errRoundNotFound)
+			return
+		}
+
+		round, err := pooldb.GetRound(ctx.pooldb.Reader(), roundID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		} else if round == nil {
+			ctx.writeErrorResponse(w, errRoundNotFound)
+			return
+		}
+
+		target := fmt.Sprintf("round:%d", roundID)
+		err = ctx.auditAdminAction(r, "round.credit", target, nil, func() error {
+			shares, err := pooldb.GetSharesByRound(ctx.pooldb.Reader(), roundID)
+			if err != nil {
+				return err
+			}
+
+			return credit.RecreditRound(pooldb.NewStore(ctx.pooldb), round, shares, ctx.poolFeeBasisPoints)
+		})
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		ctx.getAdminRound(args).ServeHTTP(w, r)
+	})
+}
+
+func (ctx *Context) orphanAdminRound(args adminRoundArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roundID, err := strconv.ParseUint(args.id, 10, 64)
+		if err != nil {
+			ctx.writeErrorResponse(w, errRoundNotFound)
+			return
+		}
+
+		round, err := pooldb.GetRound(ctx.pooldb.Reader(), roundID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		} else if round == nil {
+			ctx.writeErrorResponse(w, errRoundNotFound)
+			return
+		}
+
+		target := fmt.Sprintf("round:%d", roundID)
+		err = ctx.auditAdminAction(r, "round.orphan", target, nil, func() error {
+			return credit.OrphanRound(pooldb.NewStore(ctx.pooldb), round)
+		})
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		ctx.getAdminRound(args).ServeHTTP(w, r)
+	})
+}
+
+type adminBatchArgs struct {
+	id string
+}
+
+func (ctx *Context) cancelAdminBatch(args adminBatchArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batchID, err := strconv.ParseUint(args.id, 10, 64)
+		if err != nil {
+			ctx.writeErrorResponse(w, errBatchNotFound)
+			return
+		}
+
+		batch, err := pooldb.GetExchangeBatch(ctx.pooldb.Reader(), batchID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		} else if batch == nil {
+			ctx.writeErrorResponse(w, errBatchNotFound)
+			return
+		}
+
+		target := fmt.Sprintf("batch:%d", batchID)
+		err = ctx.auditAdminAction(r, "batch.cancel", target, nil, func() error {
+			return trade.New(ctx.pooldb, ctx.redis, nil, nil, nil).CancelBatch(batchID)
+		})
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		ctx.writeOkResponse(w, nil)
+	})
+}
+
+type adminMinerBanArgs struct {
+	id     string
+	banned bool
+	Chain  string `json:"chain"`
+}
+
+func (ctx *Context) banAdminMiner(args adminMinerBanArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		minerID, err := strconv.ParseUint(args.id, 10, 64)
+		if err != nil {
+			ctx.writeErrorResponse(w, errMinerNotFound)
+			return
+		}
+
+		err = decodeJSONBody(w, r, &args)
+		if err != nil {
+			ctx.writeErrorResponse(w, errInvalidJSONBody)
+			return
+		}
+
+		chain := strings.ToUpper(args.Chain)
+		if !validateMiningChain(chain) {
+			ctx.writeErrorResponse(w, errChainNotFound)
+			return
+		}
+
+		miner, err := pooldb.GetMiner(ctx.pooldb.Reader(), minerID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		} else if miner == nil {
+			ctx.writeErrorResponse(w, errMinerNotFound)
+			return
+		}
+
+		action := "miner.ban"
+		if !args.banned {
+			action = "miner.unban"
+		}
+
+		target := fmt.Sprintf("miner:%d", minerID)
+		params := map[string]string{"chain": chain}
+		err = ctx.auditAdminAction(r, action, target, params, func() error {
+			return audit.SetMinerBanned(ctx.pooldb, minerID, chain, args.banned)
+		})
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		ctx.writeOkResponse(w, nil)
+	})
+}
+
+type adminMinerAuditArgs struct {
+	id    string
+	Chain string `json:"chain"`
+}
+
+func (ctx *Context) resetAdminMinerAudit(args adminMinerAuditArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		minerID, err := strconv.ParseUint(args.id, 10, 64)
+		if err != nil {
+			ctx.writeErrorResponse(w, errMinerNotFound)
+			return
+		}
+
+		err = decodeJSONBody(w, r, &args)
+		if err != nil {
+			ctx.writeErrorResponse(w, errInvalidJSONBody)
+			return
+		}
+
+		chain := strings.ToUpper(args.Chain)
+		if !validateMiningChain(chain) {
+			ctx.writeErrorResponse(w, errChainNotFound)
+			return
+		}
+
+		target := fmt.Sprintf("miner:%d", minerID)
+		params := map[string]string{"chain": chain}
+		err = ctx.auditAdminAction(r, "miner.audit.reset", target, params, func() error {
+			return audit.ResetShareAudit(ctx.pooldb, minerID, chain)
+		})
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		ctx.writeOkResponse(w, nil)
+	})
+}
+
+/* treasurer handlers */
+
+type adminAdjustArgs struct {
+	Chain   string `json:"chain"`
+	MinerID uint64 `json:"minerID"`
+	Value   string `json:"value"`
+	Reason  string `json:"reason"`
+}
+
+func (ctx *Context) adjustAdminBalance() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var args adminAdjustArgs
+		err := decodeJSONBody(w, r, &args)
+		if err != nil {
+			ctx.writeErrorResponse(w, errInvalidJSONBody)
+			return
+		}
+
+		chain := strings.ToUpper(args.Chain)
+		if !validatePayoutChain(chain) {
+			ctx.writeErrorResponse(w, errChainNotFound)
+			return
+		}
+
+		// values are always in the chain's base units, negative values debit the balance.
+		// the reason is required since it is the only record of why the balance changed.
+		value, ok := new(big.Int).SetString(args.Value, 10)
+		if !ok || value.Sign() == 0 || len(strings.TrimSpace(args.Reason)) == 0 {
+			ctx.writeErrorResponse(w, errInvalidParameters)
+			return
+		}
+
+		target := fmt.Sprintf("miner:%d", args.MinerID)
+		err = ctx.auditAdminAction(r, "balance.adjust", target, args, func() error {
+			return credit.AdjustBalance(pooldb.NewStore(ctx.pooldb), chain, args.MinerID, value)
+		})
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		ctx.writeOkResponse(w, nil)
+	})
+}
+
+type adminRecipientsArgs struct {
+	Recipients []*AdminRecipient `json:"recipients"`
+}
+
+func (ctx *Context) updateAdminRecipients() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var args adminRecipientsArgs
+		err := decodeJSONBody(w, r, &args)
+		if err != nil {
+			ctx.writeErrorResponse(w, errInvalidJSONBody)
+			return
+		}
+
+		percentIdx := make(map[uint64]uint64)
+		for _, recipient := range args.Recipients {
+			if _, ok := percentIdx[recipient.MinerID]; ok {
+				ctx.writeErrorResponse(w, errInvalidParameters)
+				return
+			}
+			percentIdx[recipient.MinerID] = recipient.FeePercent
+		}
+
+		err = ctx.auditAdminAction(r, "recipients.update", "recipients", percentIdx, func() error {
+			return credit.SetRecipients(pooldb.NewStore(ctx.pooldb), percentIdx)
+		})
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		ctx.getAdminRecipients().ServeHTTP(w, r)
+	})
+}
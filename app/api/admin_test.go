@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/magicpool-co/pool/internal/log"
+)
+
+// serveAdminRequest returns the status of the request, or 0 if the handler panicked.
+// the context has no databases, so any request that gets past the role check and
+// the input validation panics, which still means the role was allowed.
+func serveAdminRequest(handler http.Handler, method, path, body, token string) (status int) {
+	defer func() {
+		if recover() != nil {
+			status = 0
+		}
+	}()
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w.Code
+}
+
+func TestAdminRoles(t *testing.T) {
+	ctx := &Context{logger: &log.Logger{MainLog: zerolog.Nop()}}
+	err := ctx.SetAdminTokens("v:viewer:viewer-token,o:operator:operator-token,t:treasurer:treasurer-token")
+	if err != nil {
+		t.Fatalf("failed to set admin tokens: %v", err)
+	}
+	handler := newRouter(ctx)
+
+	tokens := []struct {
+		token string
+		role  adminRole
+	}{
+		{"", 0},
+		{"invalid-token", 0},
+		{"viewer-token", adminRoleViewer},
+		{"operator-token", adminRoleOperator},
+		{"treasurer-token", adminRoleTreasurer},
+	}
+
+	// the inputs are invalid wherever possible, so allowed requests fail before the databases
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		role   adminRole
+	}{
+		{"GET", "/admin/audit?page=x", "", adminRoleViewer},
+		{"GET", "/admin/rounds/x", "", adminRoleViewer},
+		{"GET", "/admin/batches?page=x", "", adminRoleViewer},
+		{"GET", "/admin/miners/flagged", "", adminRoleViewer},
+		{"GET", "/admin/recipients", "", adminRoleViewer},
+		{"POST", "/admin/rounds/x/credit", "", adminRoleOperator},
+		{"POST", "/admin/rounds/x/orphan", "", adminRoleOperator},
+		{"POST", "/admin/batches/x/cancel", "", adminRoleOperator},
+		{"POST", "/admin/miners/x/audit/reset", "", adminRoleOperator},
+		{"POST", "/admin/miners/x/ban", "", adminRoleOperator},
+		{"POST", "/admin/miners/x/unban", "", adminRoleOperator},
+		{"POST", "/admin/balances/adjust", "{", adminRoleTreasurer},
+		{"POST", "/admin/recipients", "{", adminRoleTreasurer},
+	}
+
+	for i, tt := range tests {
+		for _, user := range tokens {
+			status := serveAdminRequest(handler, tt.method, tt.path, tt.body, user.token)
+			switch {
+			case user.role == 0:
+				if status != http.StatusUnauthorized {
+					t.Errorf("failed on %d: %s %s: status mismatch for %q: have %d, want %d",
+						i, tt.method, tt.path, user.token, status, http.StatusUnauthorized)
+				}
+			case user.role < tt.role:
+				if status != http.StatusForbidden {
+					t.Errorf("failed on %d: %s %s: status mismatch for %s: have %d, want %d",
+						i, tt.method, tt.path, user.role, status, http.StatusForbidden)
+				}
+			default:
+				if status == http.StatusUnauthorized || status == http.StatusForbidden {
+					t.Errorf("failed on %d: %s %s: %s not allowed: have %d",
+						i, tt.method, tt.path, user.role, status)
+				}
+			}
+		}
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"strings"
 
+	btcMining "github.com/magicpool-co/pool/internal/node/mining/btc"
 	"github.com/magicpool-co/pool/internal/node/mining/cfx"
 	"github.com/magicpool-co/pool/internal/node/mining/erg"
 	"github.com/magicpool-co/pool/internal/node/mining/etc"
@@ -17,7 +18,7 @@ import (
 
 func validateMiningChain(chain string) bool {
 	switch strings.ToUpper(chain) {
-	case "CFX", "ERG", "ETC", "ETHW", "FIRO", "FLUX", "KAS", "NEXA", "RVN":
+	case "BCH", "BTC", "CFX", "ERG", "ETC", "ETHW", "FIRO", "FLUX", "KAS", "KLS", "NEXA", "RVN", "ZEN":
 		return true
 	default:
 		return false
@@ -44,6 +45,9 @@ func parseMiner(miner string) (string, string, error) {
 	}
 
 	switch strings.ToLower(parts[0]) {
+	case "bitcoincash":
+		parts[0] = "BCH"
+		parts[1] = miner
 	case "cfx":
 		parts[0] = "CFX"
 		parts[1] = miner
@@ -52,6 +56,9 @@ func parseMiner(miner string) (string, string, error) {
 	case "kaspa":
 		parts[0] = "KAS"
 		parts[1] = miner
+	case "karlsen":
+		parts[0] = "KLS"
+		parts[1] = miner
 	case "nexa":
 		parts[0] = "NEXA"
 		parts[1] = miner
@@ -66,6 +73,8 @@ func parseMiner(miner string) (string, string, error) {
 
 func ValidateAddress(chain, address string) bool {
 	switch strings.ToUpper(chain) {
+	case "BCH":
+		return btcMining.ValidateAddress(btcMining.BCH, address)
 	case "BTC":
 		return btc.ValidateAddress(address)
 	case "CFX":
@@ -79,13 +88,17 @@ func ValidateAddress(chain, address string) bool {
 	case "FIRO":
 		return firo.ValidateAddress(address)
 	case "FLUX":
-		return flux.ValidateAddress(address)
+		return flux.ValidateAddress(flux.FLUX, address)
 	case "KAS":
-		return kas.ValidateAddress(address)
+		return kas.ValidateAddress(kas.KAS, address)
+	case "KLS":
+		return kas.ValidateAddress(kas.KLS, address)
 	case "NEXA":
 		return nexa.ValidateAddress(address)
 	case "RVN":
 		return rvn.ValidateAddress(address)
+	case "ZEN":
+		return flux.ValidateAddress(flux.ZEN, address)
 	default:
 		return false
 	}
@@ -7,6 +7,7 @@ import (
 	"net/mail"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/goccy/go-json"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/magicpool-co/pool/internal/metrics"
 	"github.com/magicpool-co/pool/internal/pooldb"
 	"github.com/magicpool-co/pool/internal/redis"
+	"github.com/magicpool-co/pool/internal/tsdb"
 	"github.com/magicpool-co/pool/pkg/common"
 	"github.com/magicpool-co/pool/pkg/dbcl"
 	"github.com/magicpool-co/pool/types"
@@ -31,25 +33,39 @@ type Context struct {
 	stats         *stats.Client
 	nodes         []types.MiningNode
 	streamManager *stream.Manager
+
+	poolFeeBasisPoints uint64
+	adminUsers         []*adminUser
 }
 
 func NewContext(
 	logger *log.Logger,
 	metricsClient *metrics.Client,
 	pooldbClient, tsdbClient *dbcl.Client,
+	shareStore tsdb.ShareStore,
 	redisClient *redis.Client,
 	nodes []types.MiningNode,
 	cacheEnabled bool,
+	poolFeeBasisPoints uint64,
 ) *Context {
 	statsChains := []string{
+		"BCH",
+		"BTC",
 		"CFX",
 		"ERG",
 		"ETC",
 		"FIRO",
 		"FLUX",
 		"KAS",
+		"KLS",
 		"NEXA",
 		"RVN",
+		"ZEN",
+	}
+
+	statsClient := stats.New(pooldbClient, tsdbClient, shareStore, redisClient, statsChains, cacheEnabled)
+	if poolFeeBasisPoints > 0 {
+		statsClient.SetPoolFee(poolFeeBasisPoints)
 	}
 
 	ctx := &Context{
@@ -58,9 +74,11 @@ func NewContext(
 		pooldb:        pooldbClient,
 		tsdb:          tsdbClient,
 		redis:         redisClient,
-		stats:         stats.New(pooldbClient, tsdbClient, redisClient, statsChains, cacheEnabled),
+		stats:         statsClient,
 		nodes:         nodes,
 		streamManager: stream.NewManager(logger, redisClient),
+
+		poolFeeBasisPoints: poolFeeBasisPoints,
 	}
 
 	return ctx
@@ -101,6 +119,12 @@ func (ctx *Context) writeCSVResponse(w http.ResponseWriter, body []byte) {
 	w.Write(body)
 }
 
+func (ctx *Context) writeJSONResponse(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(body)
+}
+
 func (ctx *Context) parsePageSize(rawPage, rawSize string) (uint64, uint64, error) {
 	page, err := strconv.ParseUint(rawPage, 10, 64)
 	if err != nil {
@@ -710,6 +734,78 @@ func (ctx *Context) getRounds(args roundArgs) http.Handler {
 	})
 }
 
+type roundDetailsArgs struct {
+	id string
+}
+
+func (ctx *Context) getRound(args roundDetailsArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roundID, err := strconv.ParseUint(args.id, 10, 64)
+		if err != nil {
+			ctx.writeErrorResponse(w, errRoundNotFound)
+			return
+		}
+
+		details, err := ctx.stats.GetRoundDetails(roundID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		} else if details == nil {
+			ctx.writeErrorResponse(w, errRoundNotFound)
+			return
+		}
+
+		ctx.writeOkResponse(w, details)
+	})
+}
+
+type roundSharesArgs struct {
+	id         string
+	exportType string
+}
+
+func (ctx *Context) getRoundShares(args roundSharesArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roundID, err := strconv.ParseUint(args.id, 10, 64)
+		if err != nil {
+			ctx.writeErrorResponse(w, errRoundNotFound)
+			return
+		}
+
+		details, shares, err := ctx.stats.GetRoundShares(roundID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		} else if details == nil {
+			ctx.writeErrorResponse(w, errRoundNotFound)
+			return
+		}
+
+		switch args.exportType {
+		case "":
+			ctx.writeOkResponse(w, shares)
+		case "csv":
+			exported, err := export.ExportRoundSharesAsCSV(shares)
+			if err != nil {
+				ctx.writeErrorResponse(w, err)
+				return
+			}
+
+			ctx.writeCSVResponse(w, exported)
+		case "json":
+			exported, err := export.ExportRoundSharesAsJSON(details, shares)
+			if err != nil {
+				ctx.writeErrorResponse(w, err)
+				return
+			}
+
+			ctx.writeJSONResponse(w, exported)
+		default:
+			ctx.writeErrorResponse(w, errInvalidParameters)
+		}
+	})
+}
+
 type thresholdBoundsArgs struct {
 	chain string
 }
@@ -741,6 +837,40 @@ func (ctx *Context) getThresholdBounds(args thresholdBoundsArgs) http.Handler {
 	})
 }
 
+func (ctx *Context) getReserveProofs() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proofs, err := ctx.stats.GetReserveProofs()
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		ctx.writeOkResponse(w, proofs)
+	})
+}
+
+type minerReserveProofsArgs struct {
+	miner string
+}
+
+func (ctx *Context) getMinerReserveProofs(args minerReserveProofsArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		minerIDs, _, err := ctx.getMinerIDs(args.miner)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		proofs, err := ctx.stats.GetMinerReserveProofs(minerIDs)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		ctx.writeOkResponse(w, proofs)
+	})
+}
+
 type minerSettingsArgs struct {
 	miner string
 }
@@ -820,6 +950,11 @@ func (ctx *Context) getMinerSettings(args minerSettingsArgs) http.Handler {
 			"worker":                     workerName,
 			"email":                      obscuredEmail,
 			"threshold":                  threshold,
+			"payoutSchedule":             types.PayoutSchedule(miner.PayoutSchedule).String(),
+			"payoutHour":                 miner.PayoutHour,
+			"payoutWeekday":              miner.PayoutWeekday,
+			"payoutTime":                 miner.PayoutTime,
+			"payoutRequested":            miner.PayoutRequested,
 			"enabledWorkerNotifications": miner.EnabledWorkerNotifications,
 			"enabledPayoutNotifications": miner.EnabledPayoutNotifications,
 			"ipHint":                     ipHint,
@@ -836,6 +971,10 @@ type updateMinerSettingsArgs struct {
 	EnableWorkerNotifications *bool   `json:"enableWorkerNotifications"`
 	EnablePayoutNotifications *bool   `json:"enablePayoutNotifications"`
 	Threshold                 *string `json:"threshold"`
+	PayoutSchedule            *string `json:"payoutSchedule"`
+	PayoutHour                *int    `json:"payoutHour"`
+	PayoutWeekday             *int    `json:"payoutWeekday"`
+	PayoutTime                *string `json:"payoutTime"`
 }
 
 func (ctx *Context) updateMinerSettings(args updateMinerSettingsArgs) http.Handler {
@@ -919,8 +1058,54 @@ func (ctx *Context) updateMinerSettings(args updateMinerSettingsArgs) http.Handl
 			miner.Threshold = dbcl.NullBigInt{Valid: true, BigInt: threshold}
 		}
 
-		cols := []string{"email", "threshold",
-			"enabled_worker_notifications", "enabled_payout_notifications"}
+		if args.PayoutSchedule != nil {
+			schedule, err := types.ParsePayoutSchedule(types.StringValue(args.PayoutSchedule))
+			if err != nil {
+				ctx.writeErrorResponse(w, errInvalidPayoutSchedule)
+				return
+			}
+
+			miner.PayoutSchedule = int(schedule)
+		}
+
+		if args.PayoutHour != nil {
+			hour := *args.PayoutHour
+			if hour < 0 || hour > 23 {
+				ctx.writeErrorResponse(w, errInvalidPayoutSchedule)
+				return
+			}
+
+			miner.PayoutHour = hour
+		}
+
+		if args.PayoutWeekday != nil {
+			weekday := *args.PayoutWeekday
+			if weekday < 0 || weekday > 6 {
+				ctx.writeErrorResponse(w, errInvalidPayoutSchedule)
+				return
+			}
+
+			miner.PayoutWeekday = weekday
+		}
+
+		if args.PayoutTime != nil {
+			payoutTime, err := time.Parse(time.RFC3339, types.StringValue(args.PayoutTime))
+			if err != nil {
+				ctx.writeErrorResponse(w, errInvalidPayoutSchedule)
+				return
+			}
+
+			payoutTime = payoutTime.UTC()
+			miner.PayoutTime = &payoutTime
+		}
+
+		if types.PayoutSchedule(miner.PayoutSchedule) == types.PayoutFixed && miner.PayoutTime == nil {
+			ctx.writeErrorResponse(w, errInvalidPayoutSchedule)
+			return
+		}
+
+		cols := []string{"email", "threshold", "payout_schedule", "payout_hour", "payout_weekday",
+			"payout_time", "enabled_worker_notifications", "enabled_payout_notifications"}
 		err = pooldb.UpdateMiner(ctx.pooldb.Writer(), miner, cols)
 		if err != nil {
 			ctx.writeErrorResponse(w, err)
@@ -931,6 +1116,94 @@ func (ctx *Context) updateMinerSettings(args updateMinerSettingsArgs) http.Handl
 	})
 }
 
+type requestMinerPayoutArgs struct {
+	miner string
+	IP    string `json:"ip"`
+}
+
+func (ctx *Context) requestMinerPayout(args requestMinerPayoutArgs) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		minerID, _, err := ctx.getMinerID(args.miner)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		ip, err := ctx.getMinerIPAddress(minerID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		} else if ip == nil || ip.IPAddress != args.IP {
+			ctx.writeErrorResponse(w, errIncorrectIPAddress)
+			return
+		}
+
+		miner, err := pooldb.GetMiner(ctx.pooldb.Reader(), minerID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		} else if miner.PayoutRequested {
+			ctx.writeErrorResponse(w, errPayoutAlreadyPending)
+			return
+		}
+
+		payoutBound, err := common.GetDefaultPayoutBounds(miner.ChainID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		balanceSums, err := pooldb.GetBalanceSumsByMinerIDs(ctx.pooldb.Reader(), []uint64{minerID})
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		balance := new(big.Int)
+		for _, balanceSum := range balanceSums {
+			if balanceSum.ChainID == miner.ChainID && balanceSum.MatureValue.Valid {
+				balance.Add(balance, balanceSum.MatureValue.BigInt)
+			}
+		}
+
+		if balance.Cmp(payoutBound.Min) < 0 {
+			ctx.writeErrorResponse(w, errBalanceTooSmall)
+			return
+		}
+
+		miner.PayoutRequested = true
+		err = pooldb.UpdateMiner(ctx.pooldb.Writer(), miner, []string{"payout_requested"})
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		units, err := common.GetDefaultUnits(miner.ChainID)
+		if err != nil {
+			ctx.writeErrorResponse(w, err)
+			return
+		}
+
+		var fee float64
+		threshold := payoutBound.Default
+		if miner.Threshold.Valid && miner.Threshold.BigInt.Cmp(common.Big0) > 0 {
+			threshold = miner.Threshold.BigInt
+		}
+
+		if balance.Cmp(threshold) < 0 {
+			fee = common.BigIntToFloat64(payoutBound.EarlyPayoutFee(balance), units)
+		}
+
+		data := map[string]interface{}{
+			"chain":   miner.ChainID,
+			"balance": common.BigIntToFloat64(balance, units),
+			"fee":     fee,
+		}
+
+		ctx.writeOkResponse(w, data)
+	})
+}
+
 type getMinerStreamArgs struct {
 	miner string
 }
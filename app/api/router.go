@@ -61,7 +61,7 @@ func (rtr router) match(path, pattern string, vars ...interface{}) bool {
 func (rtr router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var handler http.Handler
 	var method string
-	var miner, worker, metric string
+	var miner, worker, metric, id string
 
 	path := r.URL.Path
 	switch {
@@ -118,6 +118,18 @@ func (rtr router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			page:  page,
 			size:  size,
 		})
+	case rtr.match(path, "/global/rounds/+", &id):
+		method = "GET"
+		handler = rtr.ctx.getRound(roundDetailsArgs{
+			id: id,
+		})
+	case rtr.match(path, "/global/rounds/+/shares", &id):
+		method = "GET"
+		exportType := r.URL.Query().Get("type")
+		handler = rtr.ctx.getRoundShares(roundSharesArgs{
+			id:         id,
+			exportType: exportType,
+		})
 	case rtr.match(path, "/global/payouts"):
 		method = "GET"
 		page, size := r.URL.Query().Get("page"), r.URL.Query().Get("size")
@@ -134,6 +146,9 @@ func (rtr router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			page:  page,
 			size:  size,
 		})
+	case rtr.match(path, "/global/reserves"):
+		method = "GET"
+		handler = rtr.ctx.getReserveProofs()
 	case rtr.match(path, "/config/threshold"):
 		method = "GET"
 		chain := r.URL.Query().Get("chain")
@@ -203,6 +218,24 @@ func (rtr router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			exportType: exportType,
 			miner:      miner,
 		})
+	case rtr.match(path, "/miner/+/payouts/request", &miner):
+		method = "POST"
+		if r.Method == "POST" {
+			args := requestMinerPayoutArgs{
+				miner: miner,
+			}
+			err := decodeJSONBody(w, r, &args)
+			if err != nil {
+				rtr.ctx.writeErrorResponse(w, errInvalidJSONBody)
+				return
+			}
+			handler = rtr.ctx.requestMinerPayout(args)
+		}
+	case rtr.match(path, "/miner/+/reserves", &miner):
+		method = "GET"
+		handler = rtr.ctx.getMinerReserveProofs(minerReserveProofsArgs{
+			miner: miner,
+		})
 	case rtr.match(path, "/miner/+/workers", &miner):
 		method = "GET"
 		handler = rtr.ctx.getWorkers(workersArgs{
@@ -263,6 +296,72 @@ func (rtr router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			miner:  miner,
 			worker: worker,
 		})
+	case rtr.match(path, "/admin/audit"):
+		method = "GET"
+		page, size := r.URL.Query().Get("page"), r.URL.Query().Get("size")
+		handler = rtr.ctx.requireAdmin(adminRoleViewer, rtr.ctx.getAdminAuditLogs(adminAuditArgs{
+			page: page,
+			size: size,
+		}))
+	case rtr.match(path, "/admin/rounds/+", &id):
+		method = "GET"
+		handler = rtr.ctx.requireAdmin(adminRoleViewer, rtr.ctx.getAdminRound(adminRoundArgs{
+			id: id,
+		}))
+	case rtr.match(path, "/admin/rounds/+/credit", &id):
+		method = "POST"
+		handler = rtr.ctx.requireAdmin(adminRoleOperator, rtr.ctx.recreditAdminRound(adminRoundArgs{
+			id: id,
+		}))
+	case rtr.match(path, "/admin/rounds/+/orphan", &id):
+		method = "POST"
+		handler = rtr.ctx.requireAdmin(adminRoleOperator, rtr.ctx.orphanAdminRound(adminRoundArgs{
+			id: id,
+		}))
+	case rtr.match(path, "/admin/batches"):
+		method = "GET"
+		page, size := r.URL.Query().Get("page"), r.URL.Query().Get("size")
+		handler = rtr.ctx.requireAdmin(adminRoleViewer, rtr.ctx.getAdminBatches(adminBatchesArgs{
+			page: page,
+			size: size,
+		}))
+	case rtr.match(path, "/admin/batches/+/cancel", &id):
+		method = "POST"
+		handler = rtr.ctx.requireAdmin(adminRoleOperator, rtr.ctx.cancelAdminBatch(adminBatchArgs{
+			id: id,
+		}))
+	case rtr.match(path, "/admin/miners/flagged"):
+		method = "GET"
+		handler = rtr.ctx.requireAdmin(adminRoleViewer, rtr.ctx.getAdminFlaggedMiners())
+	case rtr.match(path, "/admin/miners/+/audit/reset", &id):
+		method = "POST"
+		handler = rtr.ctx.requireAdmin(adminRoleOperator, rtr.ctx.resetAdminMinerAudit(adminMinerAuditArgs{
+			id: id,
+		}))
+	case rtr.match(path, "/admin/miners/+/ban", &id):
+		method = "POST"
+		handler = rtr.ctx.requireAdmin(adminRoleOperator, rtr.ctx.banAdminMiner(adminMinerBanArgs{
+			id:     id,
+			banned: true,
+		}))
+	case rtr.match(path, "/admin/miners/+/unban", &id):
+		method = "POST"
+		handler = rtr.ctx.requireAdmin(adminRoleOperator, rtr.ctx.banAdminMiner(adminMinerBanArgs{
+			id:     id,
+			banned: false,
+		}))
+	case rtr.match(path, "/admin/balances/adjust"):
+		method = "POST"
+		handler = rtr.ctx.requireAdmin(adminRoleTreasurer, rtr.ctx.adjustAdminBalance())
+	case rtr.match(path, "/admin/recipients"):
+		switch r.Method {
+		case "GET":
+			method = "GET"
+			handler = rtr.ctx.requireAdmin(adminRoleViewer, rtr.ctx.getAdminRecipients())
+		case "POST":
+			method = "POST"
+			handler = rtr.ctx.requireAdmin(adminRoleTreasurer, rtr.ctx.updateAdminRecipients())
+		}
 	default:
 		rtr.ctx.writeErrorResponse(w, errRouteNotFound)
 		return
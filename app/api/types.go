@@ -16,6 +16,9 @@ var (
 	errThresholdTooSmall     = newHttpError(400, "ThresholdTooSmall", "Threshold too small", true)
 	errThresholdTooBig       = newHttpError(400, "ThresholdTooBig", "Threshold too big", true)
 	errThresholdTooPrecise   = newHttpError(400, "ThresholdTooPrecise", "Threshold too precise", true)
+	errInvalidPayoutSchedule = newHttpError(400, "InvalidPayoutSchedule", "Invalid payout schedule", true)
+	errBalanceTooSmall       = newHttpError(400, "BalanceTooSmall", "Balance too small for payout", true)
+	errPayoutAlreadyPending  = newHttpError(400, "PayoutAlreadyPending", "Payout already pending", true)
 	errIncorrectIPAddress    = newHttpError(403, "IncorrectIPAddress", "Incorrect IP address", true)
 	errRouteNotFound         = newHttpError(404, "RouteNotFound", "Route not found", false)
 	errChainNotFound         = newHttpError(404, "ChainNotFound", "Chain not found", false)
@@ -23,6 +26,7 @@ var (
 	errMetricNotFound        = newHttpError(404, "MetricNotFound", "Metric not found", false)
 	errMinerNotFound         = newHttpError(404, "MinerNotFound", "Miner not found", false)
 	errWorkerNotFound        = newHttpError(404, "WorkerNotFound", "Worker not found", false)
+	errRoundNotFound         = newHttpError(404, "RoundNotFound", "Round not found", false)
 	errMethodNotAllowed      = newHttpError(405, "MethodNotAllowed", "Method not allowed", false)
 	errInternalServerError   = newHttpError(500, "InternalServerError", "Internal server error", true)
 )
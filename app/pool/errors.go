@@ -35,3 +35,8 @@ func errWorkerNameTooLong(id json.RawMessage) []interface{} {
 	err := rpc.NewResponseWithError(id, 6, "worker name too long, max 32 characters")
 	return []interface{}{err}
 }
+
+func errMinerBanned(id json.RawMessage) []interface{} {
+	err := rpc.NewResponseWithError(id, 7, "miner banned")
+	return []interface{}{err}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/goccy/go-json"
 
@@ -141,6 +143,12 @@ func (l *JobList) Oldest() *types.StratumJob {
 	return l.index[l.order[len(l.order)-1]]
 }
 
+type soloSubscription struct {
+	clientType int
+	diffFactor int
+	ch         chan []byte
+}
+
 type JobManager struct {
 	ctx             context.Context
 	node            types.MiningNode
@@ -149,33 +157,70 @@ type JobManager struct {
 	subscriptions   map[int]map[int]map[uint64]chan []byte
 	subscriptionsMu sync.RWMutex
 	jobList         *JobList
+	lastJobAt       int64
+
+	// true solo (self-paid) miners each get their own jobs, built
+	// off of the latest job with a coinbase paying their address
+	soloNode          types.SoloJobNode
+	soloFeeShare      uint64
+	soloSubscriptions map[string]map[uint64]*soloSubscription
+	soloJobLists      map[string]*JobList
+	soloJobListsMu    sync.RWMutex
 }
 
 func newJobManager(
 	ctx context.Context,
 	node types.MiningNode,
+	soloNode types.SoloJobNode,
 	logger *log.Logger,
 	streamWriter *stream.Writer,
 	size, ageLimit int,
+	soloFeeShare uint64,
 ) *JobManager {
 	manager := &JobManager{
-		ctx:           ctx,
-		node:          node,
-		logger:        logger,
-		streamWriter:  streamWriter,
-		subscriptions: make(map[int]map[int]map[uint64]chan []byte),
-		jobList:       newJobList(size, ageLimit),
+		ctx:               ctx,
+		node:              node,
+		logger:            logger,
+		streamWriter:      streamWriter,
+		subscriptions:     make(map[int]map[int]map[uint64]chan []byte),
+		jobList:           newJobList(size, ageLimit),
+		soloNode:          soloNode,
+		soloFeeShare:      soloFeeShare,
+		soloSubscriptions: make(map[string]map[uint64]*soloSubscription),
+		soloJobLists:      make(map[string]*JobList),
 	}
 
 	return manager
 }
 
+func sendJob(ch chan []byte, data []byte) {
+	// thanks FlexPool :P
+	select {
+	case <-ch:
+	default:
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
+
+func isClosed(ch chan []byte) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
 func (m *JobManager) update(job *types.StratumJob) (bool, error) {
 	if job == nil {
 		return false, nil
 	}
 
 	cleanJobs := m.jobList.Append(job)
+	atomic.StoreInt64(&m.lastJobAt, time.Now().UnixNano())
 
 	m.subscriptionsMu.Lock()
 	defer m.subscriptionsMu.Unlock()
@@ -196,32 +241,95 @@ func (m *JobManager) update(job *types.StratumJob) (bool, error) {
 				return cleanJobs, err
 			}
 
-			// thanks FlexPool :P
 			for _, ch := range clientSubscriptions {
-				select {
-				case <-ch:
-				default:
-					select {
-					case ch <- data:
-					default:
-					}
-				}
+				sendJob(ch, data)
 			}
 
 			// garbage collect old subscriptions
 			for id, ch := range clientSubscriptions {
-				select {
-				case <-ch:
+				if isClosed(ch) {
 					delete(clientSubscriptions, id)
-				default:
 				}
 			}
 		}
 	}
 
+	m.updateSolo(job)
+
 	return cleanJobs, nil
 }
 
+func (m *JobManager) getSoloJobList(address string, create bool) *JobList {
+	m.soloJobListsMu.Lock()
+	defer m.soloJobListsMu.Unlock()
+
+	list, ok := m.soloJobLists[address]
+	if !ok && create {
+		list = newJobList(m.jobList.size, m.jobList.ageLimit)
+		m.soloJobLists[address] = list
+	}
+
+	return list
+}
+
+func (m *JobManager) appendSoloJob(address string, job *types.StratumJob) (*types.StratumJob, bool, error) {
+	soloJob, err := m.soloNode.GetSoloJob(job, address, m.soloFeeShare)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cleanJobs := m.getSoloJobList(address, true).Append(soloJob)
+
+	return soloJob, cleanJobs, nil
+}
+
+// updateSolo builds a new job for every subscribed true solo miner. the
+// caller is expected to be holding the subscriptions lock.
+func (m *JobManager) updateSolo(job *types.StratumJob) {
+	if m.soloNode == nil {
+		return
+	}
+
+	for address, soloSubscriptions := range m.soloSubscriptions {
+		// garbage collect old subscriptions
+		for id, sub := range soloSubscriptions {
+			if isClosed(sub.ch) {
+				delete(soloSubscriptions, id)
+			}
+		}
+
+		if len(soloSubscriptions) == 0 {
+			delete(m.soloSubscriptions, address)
+			m.soloJobListsMu.Lock()
+			delete(m.soloJobLists, address)
+			m.soloJobListsMu.Unlock()
+			continue
+		}
+
+		soloJob, cleanJobs, err := m.appendSoloJob(address, job)
+		if err != nil {
+			m.logger.Error(err)
+			continue
+		}
+
+		for _, sub := range soloSubscriptions {
+			msg, err := m.node.MarshalJob(0, soloJob, cleanJobs, sub.clientType, sub.diffFactor)
+			if err != nil {
+				m.logger.Error(err)
+				continue
+			}
+
+			data, err := json.Marshal(msg)
+			if err != nil {
+				m.logger.Error(err)
+				continue
+			}
+
+			sendJob(sub.ch, data)
+		}
+	}
+}
+
 func (m *JobManager) isExpiredHeight(height uint64) bool {
 	indexDepth := 3
 	if m.jobList.ageLimit > 0 {
@@ -250,13 +358,24 @@ func (m *JobManager) AddConn(c *stratum.Conn) {
 	m.subscriptionsMu.Lock()
 	clientType := c.GetClientType()
 	diffFactor := c.GetDiffFactor()
-	if _, ok := m.subscriptions[clientType]; !ok {
-		m.subscriptions[clientType] = make(map[int]map[uint64]chan []byte)
-	}
-	if _, ok := m.subscriptions[clientType][diffFactor]; !ok {
-		m.subscriptions[clientType][diffFactor] = make(map[uint64]chan []byte)
+	if address := c.GetSoloAddress(); address != "" && m.soloNode != nil {
+		if _, ok := m.soloSubscriptions[address]; !ok {
+			m.soloSubscriptions[address] = make(map[uint64]*soloSubscription)
+		}
+		m.soloSubscriptions[address][c.GetID()] = &soloSubscription{
+			clientType: clientType,
+			diffFactor: diffFactor,
+			ch:         jobs,
+		}
+	} else {
+		if _, ok := m.subscriptions[clientType]; !ok {
+			m.subscriptions[clientType] = make(map[int]map[uint64]chan []byte)
+		}
+		if _, ok := m.subscriptions[clientType][diffFactor]; !ok {
+			m.subscriptions[clientType][diffFactor] = make(map[uint64]chan []byte)
+		}
+		m.subscriptions[clientType][diffFactor][c.GetID()] = jobs
 	}
-	m.subscriptions[clientType][diffFactor][c.GetID()] = jobs
 	m.subscriptionsMu.Unlock()
 
 	for {
@@ -295,6 +414,12 @@ func (m *JobManager) RemoveConn(id uint64) {
 			break
 		}
 	}
+
+	for _, soloSubscriptions := range m.soloSubscriptions {
+		if sub, ok := soloSubscriptions[id]; ok {
+			close(sub.ch)
+		}
+	}
 }
 
 func (m *JobManager) GetJob(id string) (*types.StratumJob, bool) {
@@ -308,3 +433,41 @@ func (m *JobManager) GetPriorJob(id string) (*types.StratumJob, bool) {
 func (m *JobManager) LatestJob() *types.StratumJob {
 	return m.jobList.Latest()
 }
+
+// LastJobAt returns the time the latest job was broadcast.
+func (m *JobManager) LastJobAt() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&m.lastJobAt))
+}
+
+func (m *JobManager) GetSoloJob(address, id string) (*types.StratumJob, bool) {
+	list := m.getSoloJobList(address, false)
+	if list == nil {
+		return nil, false
+	}
+
+	return list.Get(id)
+}
+
+// LatestSoloJob returns the latest job for a true solo miner, building
+// one off of the latest job if the miner doesn't have one yet.
+func (m *JobManager) LatestSoloJob(address string) *types.StratumJob {
+	job := m.jobList.Latest()
+	if job == nil || m.soloNode == nil {
+		return nil
+	}
+
+	if list := m.getSoloJobList(address, false); list != nil {
+		soloJob := list.Latest()
+		if soloJob != nil && soloJob.Height.Value() == job.Height.Value() {
+			return soloJob
+		}
+	}
+
+	soloJob, _, err := m.appendSoloJob(address, job)
+	if err != nil {
+		m.logger.Error(err)
+		return nil
+	}
+
+	return soloJob
+}
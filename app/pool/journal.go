@@ -0,0 +1,161 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bsm/redislock"
+
+	"github.com/magicpool-co/pool/internal/journal"
+)
+
+const (
+	journalSyncPeriod  = time.Second
+	journalCheckPeriod = time.Second * 5
+	journalPrunePeriod = time.Hour
+)
+
+// checkShares rebuilds the redis share state from the journals once redis has lost it. that
+// is the case if the marker set by the last rebuild is gone (e.g. after a failover to an empty
+// replica) or if the window got shorter without a rebuild (e.g. after a failover to a lagging
+// replica). the pools keep refilling the window in the meantime, which the rebuild replaces,
+// since their shares have been journaled as well.
+func (p *Pool) checkShares() error {
+	marker, err := p.redis.GetRoundSharesMarker(p.chain)
+	if err != nil {
+		return err
+	}
+
+	length, err := p.redis.GetRoundShareListLength(p.chain)
+	if err != nil {
+		return err
+	}
+
+	if marker == "" || (marker == p.journalMarker && length < p.journalWindowLength) {
+		err := p.rebuildShares(marker)
+		if err != nil {
+			return err
+		}
+
+		marker, err = p.redis.GetRoundSharesMarker(p.chain)
+		if err != nil {
+			return err
+		}
+
+		length, err = p.redis.GetRoundShareListLength(p.chain)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.journalMarker = marker
+	p.journalWindowLength = length
+
+	return nil
+}
+
+// rebuildShares restores the redis share state (the PPLNS window, the round counters and
+// the open interval) from the journals of every instance and sets a new marker. it runs
+// under a lock and only if the marker is still the one seen by checkShares, so only the
+// first instance to notice a loss rebuilds.
+func (p *Pool) rebuildShares(seen string) error {
+	ctx := context.Background()
+	lock, err := p.locker.Obtain(ctx, "pool:"+strings.ToLower(p.chain)+":rebuild", time.Minute*5, nil)
+	if err == redislock.ErrNotObtained {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer lock.Release(ctx)
+
+	marker, err := p.redis.GetRoundSharesMarker(p.chain)
+	if err != nil {
+		return err
+	} else if marker != seen {
+		return nil
+	} else if err := p.journal.Sync(); err != nil {
+		return err
+	}
+
+	dirs, err := journal.InstanceDirs(p.journalRoot)
+	if err != nil {
+		return err
+	}
+
+	chains := []string{p.chain}
+	if p.soloEnabled {
+		chains = append(chains, p.soloChain)
+	}
+
+	for _, chain := range chains {
+		records, err := journal.ReadRecords(dirs, chain, time.Time{})
+		if err != nil {
+			return err
+		} else if len(records) == 0 {
+			continue
+		}
+
+		// closed intervals have usually been processed by the chart job already,
+		// so only the open interval is rebuilt
+		state := journal.Replay(records, chain, p.windowSize, nil, time.Now())
+
+		// a journal that was only enabled recently holds less than the window
+		// redis has kept, which is never replaced by a shorter one
+		length, err := p.redis.GetRoundShareListLength(chain)
+		if err != nil {
+			return err
+		} else if int64(len(state.Window)) < length {
+			p.logger.Info(fmt.Sprintf("journal: skipped rebuilding %s: %d window shares, redis holds %d",
+				chain, len(state.Window), length))
+			continue
+		} else if err := journal.Restore(p.redis, chain, state); err != nil {
+			return err
+		}
+
+		p.logger.Info(fmt.Sprintf("journal: rebuilt %s from %d records: %d window shares, %d round shares (complete: %t)",
+			chain, len(records), len(state.Window), state.RoundAccepted, state.RoundComplete))
+	}
+
+	return p.redis.SetRoundSharesMarker(p.chain, strconv.FormatInt(time.Now().UnixNano(), 10))
+}
+
+// pruneJournals removes the journal directories of dead instances under the
+// journal root, once the live instances have journaled a window without them.
+func (p *Pool) pruneJournals() {
+	dirs, err := journal.PruneInstanceDirs(p.journalRoot, p.journalDir, p.journalWindows, p.journalMinAge, time.Now())
+	if err != nil {
+		p.logger.Error(fmt.Errorf("journal: prune: %v", err))
+	}
+
+	for _, dir := range dirs {
+		p.logger.Info(fmt.Sprintf("journal: removed the journal of dead instance %s", dir))
+	}
+}
+
+func (p *Pool) startJournalSync() {
+	// runs as goroutine
+	defer p.logger.RecoverPanic()
+
+	syncTicker := time.NewTicker(journalSyncPeriod)
+	checkTicker := time.NewTicker(journalCheckPeriod)
+	pruneTicker := time.NewTicker(journalPrunePeriod)
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-syncTicker.C:
+			if err := p.journal.Sync(); err != nil {
+				p.logger.Error(fmt.Errorf("journal: sync: %v", err))
+			}
+		case <-checkTicker.C:
+			if err := p.checkShares(); err != nil {
+				p.logger.Error(fmt.Errorf("journal: check: %v", err))
+			}
+		case <-pruneTicker.C:
+			p.pruneJournals()
+		}
+	}
+}
@@ -3,17 +3,22 @@ package pool
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/bsm/redislock"
 	"github.com/goccy/go-json"
 
 	"github.com/magicpool-co/pool/core/stream"
+	"github.com/magicpool-co/pool/internal/accounting"
+	"github.com/magicpool-co/pool/internal/journal"
 	"github.com/magicpool-co/pool/internal/log"
 	"github.com/magicpool-co/pool/internal/metrics"
+	"github.com/magicpool-co/pool/internal/pooldb"
 	"github.com/magicpool-co/pool/internal/redis"
 	"github.com/magicpool-co/pool/internal/telegram"
 	"github.com/magicpool-co/pool/pkg/common"
@@ -27,16 +32,26 @@ type Options struct {
 	PortDiffIdx          map[int]int
 	WindowSize           int
 	ExtraNonceSize       int
+	VersionMask          uint32
 	JobListSize          int
 	JobListAgeLimit      int
 	SoloEnabled          bool
+	TrueSoloEnabled      bool
 	VarDiffEnabled       bool
+	VarDiffOptions       stratum.VarDiffOptions
+	BanList              []string
+	PoolFeeBasisPoints   uint64
 	StreamEnabled        bool
 	ForceErrorOnResponse bool
 	Flush                bool
 	PollingPeriod        time.Duration
 	PingingPeriod        time.Duration
 	Metrics              *metrics.Client
+	// the share journal, disabled if JournalDir is empty
+	JournalDir         string
+	JournalInstance    string
+	JournalSegmentSize int64
+	JournalMinAge      time.Duration
 }
 
 type Pool struct {
@@ -50,7 +65,9 @@ type Pool struct {
 	portDiffIdx          map[int]int
 	windowSize           int64
 	extraNonce1Size      int
+	versionMask          uint32
 	soloEnabled          bool
+	trueSoloEnabled      bool
 	varDiffEnabled       bool
 	forceErrorOnResponse bool
 	node                 types.MiningNode
@@ -71,9 +88,28 @@ type Pool struct {
 	lastDiffIndex     map[string]int64
 	latencyValueIndex map[string]int64
 	latencyCountIndex map[string]int64
-
-	db       *dbcl.Client
-	redis    *redis.Client
+	shareAuditIndex   map[uint64]*pooldb.MinerShareAudit
+
+	bannedMu     sync.RWMutex
+	bannedMiners map[uint64]bool
+
+	// journalRoot holds the journal of every instance of the chain, which rebuildShares
+	// and pruneJournals assume is shared by all of them. with a root local to each
+	// instance, the rebuild only sees its own shares (and is skipped whenever redis
+	// holds a longer window) and there are never any dead instances to prune.
+	journalRoot    string
+	journal        *journal.Writer
+	journalDir     string
+	journalWindows map[string]int64
+	journalMinAge  time.Duration
+	locker         *redislock.Client
+
+	// the share state last seen by checkShares
+	journalMarker       string
+	journalWindowLength int64
+
+	db       pooldb.Store
+	redis    redis.ShareStore
 	logger   *log.Logger
 	telegram *telegram.Client
 	metrics  *metrics.Client
@@ -88,6 +124,21 @@ func New(
 	metricsClient *metrics.Client,
 	opt *Options,
 ) (*Pool, error) {
+	// true solo (self-paid) mining requires the node to build a coinbase per miner
+	var soloNode types.SoloJobNode
+	if opt.TrueSoloEnabled {
+		var ok bool
+		soloNode, ok = node.(types.SoloJobNode)
+		if !ok {
+			return nil, fmt.Errorf("%s does not support true solo mining", opt.Chain)
+		}
+	}
+
+	// version rolling changes the header version, which only sha256d chains allow
+	if opt.VersionMask != 0 && !supportsVersionRolling(opt.Chain) {
+		return nil, fmt.Errorf("%s does not support version rolling", opt.Chain)
+	}
+
 	ports := make([]int, 0)
 	for port := range opt.PortDiffIdx {
 		ports = append(ports, port)
@@ -98,6 +149,16 @@ func New(
 	if err != nil {
 		return nil, err
 	}
+	server.SetVarDiffOptions(opt.VarDiffOptions)
+	if err := server.SetBanList(opt.BanList); err != nil {
+		cancelFunc()
+		return nil, err
+	}
+
+	poolFeeBasisPoints := opt.PoolFeeBasisPoints
+	if poolFeeBasisPoints == 0 {
+		poolFeeBasisPoints = accounting.DefaultPoolFeeBasisPoints
+	}
 
 	logger.LabelKeys = []string{"miner"}
 
@@ -109,6 +170,28 @@ func New(
 		}
 	}
 
+	// solo shares are added to the solo window with the same size
+	journalWindows := map[string]int64{strings.ToUpper(opt.Chain): int64(opt.WindowSize)}
+	if opt.SoloEnabled {
+		journalWindows["S"+strings.ToUpper(opt.Chain)] = int64(opt.WindowSize)
+	}
+
+	var journalWriter *journal.Writer
+	var journalRoot, journalDir string
+	if len(opt.JournalDir) > 0 {
+		journalRoot = filepath.Join(opt.JournalDir, strings.ToUpper(opt.Chain))
+		journalDir = filepath.Join(journalRoot, opt.JournalInstance)
+		journalWriter, err = journal.Open(journalDir, journal.Options{
+			SegmentSize: opt.JournalSegmentSize,
+			MinAge:      opt.JournalMinAge,
+			Windows:     journalWindows,
+		})
+		if err != nil {
+			cancelFunc()
+			return nil, fmt.Errorf("failed to open share journal: %v", err)
+		}
+	}
+
 	pool := &Pool{
 		ctx:        ctx,
 		cancelFunc: cancelFunc,
@@ -119,7 +202,9 @@ func New(
 		portDiffIdx:          opt.PortDiffIdx,
 		windowSize:           int64(opt.WindowSize),
 		extraNonce1Size:      opt.ExtraNonceSize,
+		versionMask:          opt.VersionMask,
 		soloEnabled:          opt.SoloEnabled,
+		trueSoloEnabled:      opt.TrueSoloEnabled,
 		varDiffEnabled:       opt.VarDiffEnabled,
 		forceErrorOnResponse: opt.ForceErrorOnResponse,
 		node:                 node,
@@ -128,14 +213,25 @@ func New(
 		pollingPeriod: opt.PollingPeriod,
 		pingingPeriod: opt.PingingPeriod,
 
-		jobManager: newJobManager(ctx, node, logger, streamWriter, opt.JobListSize, opt.JobListAgeLimit),
+		jobManager: newJobManager(ctx, node, soloNode, logger, streamWriter,
+			opt.JobListSize, opt.JobListAgeLimit, poolFeeBasisPoints),
 
 		lastShareIndex:    make(map[string]int64),
 		lastDiffIndex:     make(map[string]int64),
 		latencyValueIndex: make(map[string]int64),
 		latencyCountIndex: make(map[string]int64),
+		shareAuditIndex:   make(map[uint64]*pooldb.MinerShareAudit),
+
+		bannedMiners: make(map[uint64]bool),
+
+		journal:        journalWriter,
+		journalRoot:    journalRoot,
+		journalDir:     journalDir,
+		journalWindows: journalWindows,
+		journalMinAge:  opt.JournalMinAge,
+		locker:         redisClient.NewLocker(),
 
-		db:       dbClient,
+		db:       pooldb.NewStore(dbClient),
 		redis:    redisClient,
 		logger:   logger,
 		telegram: telegramClient,
@@ -145,6 +241,43 @@ func New(
 	return pool, nil
 }
 
+// SetVarDiffOptions replaces the vardiff options for every miner, safe to call while running.
+func (p *Pool) SetVarDiffOptions(opts stratum.VarDiffOptions) {
+	p.server.SetVarDiffOptions(opts)
+}
+
+// SetBanList replaces the banned IPs and CIDR ranges, safe to call while running.
+func (p *Pool) SetBanList(entries []string) error {
+	return p.server.SetBanList(entries)
+}
+
+// refreshBannedMiners replaces the set of miners banned through the admin api,
+// which is checked on every login and share submission.
+func (p *Pool) refreshBannedMiners() error {
+	shareAudits, err := p.db.Miners().GetBannedMinerShareAuditsByChain(p.chain)
+	if err != nil {
+		return err
+	}
+
+	bannedMiners := make(map[uint64]bool, len(shareAudits))
+	for _, shareAudit := range shareAudits {
+		bannedMiners[shareAudit.MinerID] = true
+	}
+
+	p.bannedMu.Lock()
+	p.bannedMiners = bannedMiners
+	p.bannedMu.Unlock()
+
+	return nil
+}
+
+func (p *Pool) isMinerBanned(minerID uint64) bool {
+	p.bannedMu.RLock()
+	defer p.bannedMu.RUnlock()
+
+	return p.bannedMiners[minerID]
+}
+
 func (p *Pool) writeToConn(c *stratum.Conn, msg interface{}) error {
 	data, err := json.Marshal(msg)
 	if err != nil {
@@ -317,6 +450,11 @@ func (p *Pool) startMinerStatsPusher() {
 			// force interval addition
 			p.getCurrentInterval(true)
 
+			// pick up bans (and unbans) from the admin api
+			if err := p.refreshBannedMiners(); err != nil {
+				p.logger.Error(err)
+			}
+
 			// copy and replace last share and latency index
 			p.minerStatsMu.Lock()
 
@@ -329,8 +467,24 @@ func (p *Pool) startMinerStatsPusher() {
 			latencyValueIndex, latencyCountIndex := p.latencyValueIndex, p.latencyCountIndex
 			p.latencyValueIndex, p.latencyCountIndex = make(map[string]int64), make(map[string]int64)
 
+			shareAuditIndex := p.shareAuditIndex
+			p.shareAuditIndex = make(map[uint64]*pooldb.MinerShareAudit)
+
 			p.minerStatsMu.Unlock()
 
+			// process share audits in bulk
+			if len(shareAuditIndex) > 0 {
+				shareAudits := make([]*pooldb.MinerShareAudit, 0, len(shareAuditIndex))
+				for _, shareAudit := range shareAuditIndex {
+					shareAudits = append(shareAudits, shareAudit)
+				}
+
+				err := p.db.Miners().InsertAddMinerShareAudits(shareAudits...)
+				if err != nil {
+					p.logger.Error(err)
+				}
+			}
+
 			// process set ip address in bulk
 			err := p.redis.SetMinerIPAddressesBulk(p.chain, lastShareIndex)
 			if err != nil {
@@ -441,6 +595,18 @@ func (p *Pool) Port(idx int) int {
 }
 
 func (p *Pool) Serve() {
+	if p.journal != nil {
+		if err := p.checkShares(); err != nil {
+			p.logger.Error(fmt.Errorf("journal: check: %v", err))
+		}
+		p.pruneJournals()
+		go p.startJournalSync()
+	}
+
+	if err := p.refreshBannedMiners(); err != nil {
+		p.logger.Error(fmt.Errorf("banned miners: %v", err))
+	}
+
 	go p.startPingHosts()
 	go p.startJobNotify()
 	go p.startShareIndexClearer()
@@ -457,4 +623,10 @@ func (p *Pool) Stop() {
 	p.cancelFunc()
 	p.wg.Wait()
 	p.server.Wait()
+
+	if p.journal != nil {
+		if err := p.journal.Close(); err != nil {
+			p.logger.Error(fmt.Errorf("journal: close: %v", err))
+		}
+	}
 }
@@ -2,7 +2,9 @@ package pool
 
 import (
 	"fmt"
+	"math/bits"
 	"strconv"
+	"strings"
 
 	"github.com/goccy/go-json"
 
@@ -16,8 +18,13 @@ type ProtocolHandler func(*stratum.Conn, *rpc.Request) error
 /* router */
 
 func (p *Pool) routeRequest(req *rpc.Request) ProtocolHandler {
+	// version rolling is negotiated independently of the chain's protocol
+	if req.Method == "mining.configure" {
+		return p.configure
+	}
+
 	switch p.chain {
-	case "ERG", "FIRO", "FLUX", "KAS", "NEXA", "RVN":
+	case "BCH", "BTC", "ERG", "FIRO", "FLUX", "KAS", "KLS", "NEXA", "RVN", "ZEN":
 		switch req.Method {
 		case "mining.subscribe":
 			return p.subscribe
@@ -39,8 +46,16 @@ func (p *Pool) routeRequest(req *rpc.Request) ProtocolHandler {
 		case "mining.extranonce.subscribe":
 			return p.subscribeExtraNonce
 		}
-	case "ETH", "ETC":
+	case "ETH", "ETC", "ETHW":
 		switch req.Method {
+		case "mining.subscribe":
+			return p.subscribe
+		case "mining.authorize":
+			return p.login
+		case "mining.submit":
+			return p.submit
+		case "mining.extranonce.subscribe":
+			return p.subscribeExtraNonce
 		case "eth_submitLogin":
 			return p.login
 		case "eth_submitWork":
@@ -62,8 +77,18 @@ func (p *Pool) subscribe(c *stratum.Conn, req *rpc.Request) error {
 		var minerClient string
 		err := json.Unmarshal(req.Params[0], &minerClient)
 		if err == nil {
+			// some protocols (EthereumStratum/1.0.0) are only identified
+			// by the second parameter, so include it for the client type
+			clientType := minerClient
+			if len(req.Params) > 1 {
+				var minerProtocol string
+				if err := json.Unmarshal(req.Params[1], &minerProtocol); err == nil {
+					clientType += " " + minerProtocol
+				}
+			}
+
 			c.SetClient(minerClient)
-			c.SetClientType(p.node.GetClientType(minerClient))
+			c.SetClientType(p.node.GetClientType(clientType))
 		}
 	}
 
@@ -88,6 +113,80 @@ func (p *Pool) subscribe(c *stratum.Conn, req *rpc.Request) error {
 	return nil
 }
 
+// supportsVersionRolling returns whether miners are allowed to roll the block header version
+// (BIP310) for the chain. only sha256d chains are covered, any other chain (e.g. a scrypt
+// chain) has to be added explicitly once its job building and validation support it.
+func supportsVersionRolling(chain string) bool {
+	switch strings.ToUpper(chain) {
+	case "BCH", "BTC":
+		return true
+	default:
+		return false
+	}
+}
+
+// configure handles BIP310 extension negotiation. the only supported extension is version
+// rolling, for which the mask is the intersection of the pool's mask and the miner's mask.
+func (p *Pool) configure(c *stratum.Conn, req *rpc.Request) error {
+	var extensions []string
+	if len(req.Params) < 1 {
+		return p.writeToConn(c, errInvalidRequest(req.ID))
+	} else if err := json.Unmarshal(req.Params[0], &extensions); err != nil {
+		return p.writeToConn(c, errInvalidRequest(req.ID))
+	}
+
+	extensionParams := make(map[string]interface{})
+	if len(req.Params) > 1 {
+		if err := json.Unmarshal(req.Params[1], &extensionParams); err != nil {
+			return p.writeToConn(c, errInvalidRequest(req.ID))
+		}
+	}
+
+	result := make(map[string]interface{})
+	for _, extension := range extensions {
+		switch extension {
+		case "version-rolling":
+			if !supportsVersionRolling(p.chain) {
+				result[extension] = false
+				continue
+			}
+
+			minerMask := uint32(0xffffffff)
+			if rawMask, ok := extensionParams["version-rolling.mask"].(string); ok {
+				parsedMask, err := strconv.ParseUint(rawMask, 16, 32)
+				if err != nil {
+					return p.writeToConn(c, errInvalidRequest(req.ID))
+				}
+				minerMask = uint32(parsedMask)
+			}
+
+			var minBitCount int
+			if rawCount, ok := extensionParams["version-rolling.min-bit-count"].(float64); ok {
+				minBitCount = int(rawCount)
+			}
+
+			mask := p.versionMask & minerMask
+			if mask == 0 || bits.OnesCount32(mask) < minBitCount {
+				result[extension] = false
+				continue
+			}
+
+			c.SetVersionMask(mask)
+			result[extension] = true
+			result["version-rolling.mask"] = fmt.Sprintf("%08x", mask)
+		default:
+			result[extension] = false
+		}
+	}
+
+	res, err := rpc.NewResponse(req.ID, result)
+	if err != nil {
+		return err
+	}
+
+	return p.writeToConn(c, res)
+}
+
 func (p *Pool) subscribeExtraNonce(c *stratum.Conn, req *rpc.Request) error {
 	if !c.GetExtraNonceSubscribed() {
 		c.SetExtraNonceSubscribed(true)
@@ -144,6 +243,12 @@ func (p *Pool) submit(c *stratum.Conn, req *rpc.Request) error {
 	var validShare bool
 	var err error
 	if c.GetAuthorized() {
+		// miners banned after logging in are dropped on their next share
+		if p.isMinerBanned(c.GetMinerID()) {
+			c.Close()
+			return nil
+		}
+
 		validShare, err = p.handleSubmit(c, req)
 		if err != nil {
 			p.logger.Error(err, c.GetCompoundID())
@@ -10,8 +10,10 @@ import (
 
 	"github.com/goccy/go-json"
 
+	"github.com/magicpool-co/pool/core/audit"
 	"github.com/magicpool-co/pool/internal/pooldb"
 	"github.com/magicpool-co/pool/pkg/common"
+	"github.com/magicpool-co/pool/pkg/crypto/blkbuilder"
 	"github.com/magicpool-co/pool/pkg/crypto/tx/btctx"
 	"github.com/magicpool-co/pool/pkg/stratum"
 	"github.com/magicpool-co/pool/pkg/stratum/rpc"
@@ -36,6 +38,23 @@ func generateExtraNonce(size int, mocked bool) string {
 	return hex.EncodeToString(extraNonce1)
 }
 
+// rollVersion replaces the version bits submitted by the miner with the full
+// rolled version of the job, as long as the bits are within the negotiated mask.
+func rollVersion(c *stratum.Conn, job *types.StratumJob, work *types.StratumWork) error {
+	if job.Version == nil {
+		return fmt.Errorf("job %s does not support version rolling", job.ID)
+	}
+
+	version, err := blkbuilder.RollBitcoinVersion(uint32(job.Version.Value()),
+		uint32(work.Version.Value()), c.GetVersionMask())
+	if err != nil {
+		return err
+	}
+	work.Version = new(types.Number).SetFromValue(uint64(version))
+
+	return nil
+}
+
 func (p *Pool) validateAddress(chain, address string) (bool, bool) {
 	var ethRegex = regexp.MustCompile("^0x[0-9a-fA-F]{40}$")
 
@@ -61,7 +80,7 @@ func (p *Pool) getMinerID(compoundName, chain, address string) uint64 {
 		}
 
 		// check the writer db directly
-		minerID, err = pooldb.GetMinerIDByChainAddress(p.db.Writer(), chain, address)
+		minerID, err = p.db.Miners().GetMinerIDByChainAddress(chain, address)
 		if err != nil || minerID == 0 {
 			if err != nil {
 				p.logger.Error(err)
@@ -74,7 +93,7 @@ func (p *Pool) getMinerID(compoundName, chain, address string) uint64 {
 			}
 
 			// attempt to insert the minerID
-			minerID, err = pooldb.InsertMiner(p.db.Writer(), miner)
+			minerID, err = p.db.Miners().InsertMiner(miner)
 			if err != nil {
 				p.logger.Error(err)
 				return 0
@@ -98,7 +117,7 @@ func (p *Pool) getWorkerID(compoundName string, minerID uint64, workerName strin
 		}
 
 		// check the writer db directly
-		workerID, err = pooldb.GetWorkerID(p.db.Writer(), minerID, workerName)
+		workerID, err = p.db.Miners().GetWorkerID(minerID, workerName)
 		if err != nil || workerID == 0 {
 			if err != nil {
 				p.logger.Error(err, compoundName)
@@ -111,7 +130,7 @@ func (p *Pool) getWorkerID(compoundName string, minerID uint64, workerName strin
 			}
 
 			// attempt to insert the workerID
-			workerID, err = pooldb.InsertWorker(p.db.Writer(), worker)
+			workerID, err = p.db.Miners().InsertWorker(worker)
 			if err != nil {
 				p.logger.Error(err, compoundName)
 				return 0
@@ -136,7 +155,10 @@ func (p *Pool) submitRound(c *stratum.Conn, chain string, soloMinerID uint64, ro
 
 	compoundID := c.GetCompoundID()
 	round.Solo = c.GetIsSolo()
-	if round.Solo {
+	round.SelfPaid = c.GetSoloAddress() != ""
+	if round.SelfPaid {
+		p.logger.Info("found valid self-paid solo block")
+	} else if round.Solo {
 		p.logger.Info("found valid solo block")
 	} else {
 		p.logger.Info("found valid block")
@@ -161,6 +183,12 @@ func (p *Pool) submitRound(c *stratum.Conn, chain string, soloMinerID uint64, ro
 		return
 	}
 
+	if p.journal != nil {
+		if err := p.journal.AppendRoundReset(chain, soloMinerID); err != nil {
+			p.logger.Error(fmt.Errorf("journal: %v", err), compoundID)
+		}
+	}
+
 	shareDiff := float64(p.node.GetShareDifficulty(1).Value())
 	if p.chain == "NEXA" {
 		shareDiff = 0.2
@@ -176,7 +204,7 @@ func (p *Pool) submitRound(c *stratum.Conn, chain string, soloMinerID uint64, ro
 	minedDiff := shareDiff * float64(round.AcceptedShares+1)
 	round.Luck = 100 * (float64(roundDiff) / float64(minedDiff))
 	round.MinerID = c.GetMinerID()
-	roundID, err := pooldb.InsertRound(p.db.Writer(), round)
+	roundID, err := p.db.Rounds().InsertRound(round)
 	if err != nil {
 		p.logger.Error(err, compoundID)
 		return
@@ -193,7 +221,7 @@ func (p *Pool) submitRound(c *stratum.Conn, chain string, soloMinerID uint64, ro
 		shares = append(shares, share)
 	}
 
-	if err := pooldb.InsertShares(p.db.Writer(), shares...); err != nil {
+	if err := p.db.Shares().InsertShares(shares...); err != nil {
 		p.logger.Error(err, compoundID)
 		return
 	}
@@ -244,7 +272,7 @@ func (p *Pool) retargetVardiff(c *stratum.Conn, submitTime time.Time) {
 		return
 	}
 
-	diffResponse, err := p.node.GetSetDifficultyResponse(newDiffFactor)
+	diffResponse, err := p.node.GetSetDifficultyResponse(c.GetClientType(), newDiffFactor)
 	if err != nil {
 		p.logger.Error(err)
 		return
@@ -286,6 +314,14 @@ func (p *Pool) submitShare(
 	interval := p.getCurrentInterval(false)
 	switch shareStatus {
 	case types.AcceptedShare:
+		// the journal is written first, so shares accepted while redis is down are kept
+		if p.journal != nil {
+			err := p.journal.AppendShare(chain, interval, c.GetCompoundID(), soloMinerID, activeDiffFactor)
+			if err != nil {
+				p.logger.Error(fmt.Errorf("journal: %v", err), c.GetCompoundID())
+			}
+		}
+
 		err := p.redis.AddAcceptedShare(chain, interval, c.GetCompoundID(), soloMinerID, activeDiffFactor, p.windowSize)
 		if err != nil {
 			p.logger.Error(err, c.GetCompoundID())
@@ -326,6 +362,39 @@ func (p *Pool) submitShare(
 	}
 }
 
+func (p *Pool) auditShare(
+	c *stratum.Conn,
+	job *types.StratumJob,
+	hash *types.Hash,
+	foundBlock bool,
+	submitTime time.Time,
+	activeDiffFactor int,
+) {
+	if job.Difficulty == nil {
+		return
+	}
+
+	shareDiff := float64(hash.Difficulty(p.node.GetMaxDifficulty()))
+	targetDiff := float64(p.node.GetShareDifficulty(activeDiffFactor).Value())
+	blockDiff := float64(job.Difficulty.Value())
+	sinceJob := submitTime.Sub(p.jobManager.LastJobAt())
+
+	p.minerStatsMu.Lock()
+	defer p.minerStatsMu.Unlock()
+
+	minerID := c.GetMinerID()
+	shareAudit, ok := p.shareAuditIndex[minerID]
+	if !ok {
+		shareAudit = &pooldb.MinerShareAudit{
+			MinerID: minerID,
+			ChainID: p.chain,
+		}
+		p.shareAuditIndex[minerID] = shareAudit
+	}
+
+	audit.RecordShare(shareAudit, shareDiff, targetDiff, blockDiff, sinceJob, foundBlock)
+}
+
 /* actual handlers */
 
 func (p *Pool) handleLogin(c *stratum.Conn, req *rpc.Request) []interface{} {
@@ -349,14 +418,23 @@ func (p *Pool) handleLogin(c *stratum.Conn, req *rpc.Request) []interface{} {
 	}
 
 	// address formatting is chain:address for standard,
-	// solo:chain:address for solo mining (if enabled)
-	var isSolo bool
+	// solo:chain:address for solo mining (if enabled), and
+	// truesolo:chain:address for self-paid solo mining (if enabled)
+	var isSolo, isSelfPaid bool
 	compoundName := args[0]
 	partial := strings.Split(compoundName, ":")
 	switch len(partial) {
 	case 2:
 	case 3:
-		if !p.soloEnabled || strings.ToLower(partial[0]) != "solo" {
+		switch strings.ToLower(partial[0]) {
+		case "solo":
+		case "truesolo":
+			isSelfPaid = true
+		default:
+			return errInvalidAddressFormatting(req.ID)
+		}
+
+		if !p.soloEnabled || (isSelfPaid && !p.trueSoloEnabled) {
 			return errInvalidAddressFormatting(req.ID)
 		}
 		isSolo = true
@@ -386,6 +464,10 @@ func (p *Pool) handleLogin(c *stratum.Conn, req *rpc.Request) []interface{} {
 	} else if !validAddress {
 		p.logger.Debug(fmt.Sprintf("invalid address: %s", username))
 		return errInvalidAddress(req.ID)
+	} else if isSelfPaid && chain != p.node.Chain() {
+		// the coinbase can only pay out in the native chain
+		p.logger.Debug(fmt.Sprintf("invalid self-paid chain: %s", username))
+		return errInvalidChain(req.ID)
 	} else if len(workerName) > 32 {
 		return errWorkerNameTooLong(req.ID)
 	}
@@ -393,6 +475,9 @@ func (p *Pool) handleLogin(c *stratum.Conn, req *rpc.Request) []interface{} {
 	minerID := p.getMinerID(compoundName, chain, address)
 	if minerID == 0 {
 		return nil
+	} else if p.isMinerBanned(minerID) {
+		p.logger.Debug(fmt.Sprintf("banned miner: %s", username))
+		return errMinerBanned(req.ID)
 	}
 
 	workerID := p.getWorkerID(compoundName, minerID, workerName)
@@ -412,6 +497,9 @@ func (p *Pool) handleLogin(c *stratum.Conn, req *rpc.Request) []interface{} {
 	c.SetAuthorized(true)
 	c.SetDiffFactor(diffFactor)
 	c.SetIsSolo(isSolo)
+	if isSelfPaid {
+		c.SetSoloAddress(address)
+	}
 	c.SetReadDeadline(time.Time{})
 	c.SetWorker(workerName)
 	c.SetWorkerID(workerID)
@@ -428,7 +516,7 @@ func (p *Pool) handleLogin(c *stratum.Conn, req *rpc.Request) []interface{} {
 		msgs = []interface{}{rpc.NewResponseFromJSON(req.ID, common.JsonTrue)}
 	}
 
-	authResponses, err := p.node.GetAuthorizeResponses(diffFactor)
+	authResponses, err := p.node.GetAuthorizeResponses(c.GetClientType(), diffFactor)
 	if err != nil {
 		p.logger.Error(err, c.GetCompoundID())
 		return msgs
@@ -436,6 +524,10 @@ func (p *Pool) handleLogin(c *stratum.Conn, req *rpc.Request) []interface{} {
 	msgs = append(msgs, authResponses...)
 
 	job := p.jobManager.LatestJob()
+	if isSelfPaid {
+		job = p.jobManager.LatestSoloJob(address)
+	}
+
 	if job != nil {
 		msg, err := p.node.MarshalJob(0, job, true, c.GetClientType(), c.GetDiffFactor())
 		if err != nil {
@@ -479,7 +571,18 @@ func (p *Pool) handleSubmit(c *stratum.Conn, req *rpc.Request) (bool, error) {
 	var hash *types.Hash
 	var round *pooldb.Round
 	job, activeShare := p.jobManager.GetJob(work.JobID)
-	if job != nil && activeShare {
+	if address := c.GetSoloAddress(); address != "" {
+		job, activeShare = p.jobManager.GetSoloJob(address, work.JobID)
+	}
+	if job != nil && activeShare && work.Version != nil {
+		err = rollVersion(c, job, work)
+		if err != nil {
+			p.logger.Debug(fmt.Sprintf("invalid version rolling: %v", err), c.GetCompoundID())
+			shareStatus = types.InvalidShare
+		}
+	}
+
+	if job != nil && activeShare && shareStatus != types.InvalidShare {
 		shareStatus, hash, round, err = p.node.SubmitWork(job, work, activeDiffFactor)
 		if err != nil {
 			return false, err
@@ -501,7 +604,7 @@ func (p *Pool) handleSubmit(c *stratum.Conn, req *rpc.Request) (bool, error) {
 
 		// special handing for IceRiver ASICs since sometimes they submit
 		// a solution for the prior job instead of the job ID that is sent
-		if p.chain == "KAS" && shareStatus == types.RejectedShare {
+		if (p.chain == "KAS" || p.chain == "KLS") && shareStatus == types.RejectedShare {
 			jobID := work.JobID
 			for i := 0; i < 2; i++ {
 				job, activeShare = p.jobManager.GetPriorJob(jobID)
@@ -540,6 +643,11 @@ func (p *Pool) handleSubmit(c *stratum.Conn, req *rpc.Request) (bool, error) {
 		}
 	}
 
+	// handle share auditing (block withholding, share manipulation)
+	if shareStatus == types.AcceptedShare && hash != nil {
+		p.auditShare(c, job, hash, round != nil, submitTime, activeDiffFactor)
+	}
+
 	// handle share streaming
 	if p.streamWriter != nil {
 		targetDiff := uint64(p.node.GetAdjustedShareDifficulty() * float64(activeDiffFactor))
@@ -0,0 +1,102 @@
+package pool
+
+import (
+	"testing"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/internal/redis"
+)
+
+func TestGetMinerAndWorkerID(t *testing.T) {
+	store := pooldb.NewMemoryStore()
+	shareStore := redis.NewMemoryShareStore()
+	p := &Pool{db: store, redis: shareStore}
+
+	existingID, err := store.Miners().InsertMiner(&pooldb.Miner{ChainID: "ETC", Address: "0x01"})
+	if err != nil {
+		t.Fatalf("failed to insert miner: %v", err)
+	}
+
+	tests := []struct {
+		compoundName string
+		address      string
+		minerID      uint64
+	}{
+		{"ETC:0x01", "0x01", existingID},
+		{"ETC:0x02", "0x02", existingID + 1},
+		{"ETC:0x01", "0x01", existingID},
+	}
+
+	for i, tt := range tests {
+		minerID := p.getMinerID(tt.compoundName, "ETC", tt.address)
+		if minerID != tt.minerID {
+			t.Errorf("failed on %d: miner id mismatch: have %d, want %d", i, minerID, tt.minerID)
+		}
+
+		cachedID, err := shareStore.GetMinerID(tt.compoundName)
+		if err != nil {
+			t.Errorf("failed on %d: cached miner id: %v", i, err)
+		} else if cachedID != tt.minerID {
+			t.Errorf("failed on %d: cached miner id mismatch: have %d, want %d", i, cachedID, tt.minerID)
+		}
+	}
+
+	workerID := p.getWorkerID("ETC:0x01", existingID, "rig1")
+	if workerID == 0 {
+		t.Fatalf("worker id not set")
+	} else if cachedID := p.getWorkerID("ETC:0x01", existingID, "rig1"); cachedID != workerID {
+		t.Errorf("worker id mismatch: have %d, want %d", cachedID, workerID)
+	} else if otherID := p.getWorkerID("ETC:0x01", existingID, "rig2"); otherID == workerID {
+		t.Errorf("expected distinct worker id for rig2")
+	}
+}
+
+func TestRefreshBannedMiners(t *testing.T) {
+	store := pooldb.NewMemoryStore()
+	p := &Pool{chain: "ETC", db: store}
+
+	tests := []struct {
+		audits []*pooldb.MinerShareAudit
+		banned map[uint64]bool
+	}{
+		{
+			audits: nil,
+			banned: map[uint64]bool{},
+		},
+		{
+			audits: []*pooldb.MinerShareAudit{
+				{MinerID: 1, ChainID: "ETC", Banned: true},
+				{MinerID: 2, ChainID: "ETC", Excluded: true},
+				{MinerID: 3, ChainID: "RVN", Banned: true},
+			},
+			banned: map[uint64]bool{1: true},
+		},
+		{
+			// unbanned miners are removed on the next refresh
+			audits: []*pooldb.MinerShareAudit{
+				{MinerID: 1, ChainID: "ETC", Banned: false},
+				{MinerID: 2, ChainID: "ETC", Banned: true},
+			},
+			banned: map[uint64]bool{2: true},
+		},
+	}
+
+	for i, tt := range tests {
+		for _, shareAudit := range tt.audits {
+			store.SetMinerShareAudit(shareAudit)
+		}
+
+		err := p.refreshBannedMiners()
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		}
+
+		for minerID := uint64(1); minerID <= 3; minerID++ {
+			if banned := p.isMinerBanned(minerID); banned != tt.banned[minerID] {
+				t.Errorf("failed on %d: banned mismatch for %d: have %t, want %t",
+					i, minerID, banned, tt.banned[minerID])
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package pool
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/magicpool-co/pool/pkg/stratum"
+	"github.com/magicpool-co/pool/pkg/stratum/rpc"
+	"github.com/magicpool-co/pool/types"
+)
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		chain       string
+		versionMask uint32
+		params      []interface{}
+		result      map[string]interface{}
+		connMask    uint32
+	}{
+		{
+			chain:       "BTC",
+			versionMask: 0x1fffe000,
+			params: []interface{}{
+				[]string{"version-rolling"},
+				map[string]interface{}{"version-rolling.mask": "ffffffff", "version-rolling.min-bit-count": 2},
+			},
+			result:   map[string]interface{}{"version-rolling": true, "version-rolling.mask": "1fffe000"},
+			connMask: 0x1fffe000,
+		},
+		{
+			// the mask is the intersection of the pool's and the miner's masks
+			chain:       "BCH",
+			versionMask: 0x1fffe000,
+			params: []interface{}{
+				[]string{"version-rolling", "minimum-difficulty"},
+				map[string]interface{}{"version-rolling.mask": "00ffff00"},
+			},
+			result:   map[string]interface{}{"version-rolling": true, "version-rolling.mask": "00ffe000", "minimum-difficulty": false},
+			connMask: 0x00ffe000,
+		},
+		{
+			chain:       "BTC",
+			versionMask: 0x1fffe000,
+			params: []interface{}{
+				[]string{"version-rolling"},
+				map[string]interface{}{"version-rolling.mask": "00006000", "version-rolling.min-bit-count": 4},
+			},
+			result: map[string]interface{}{"version-rolling": false},
+		},
+		{
+			// chains without version rolling never negotiate it, even with a mask
+			chain:       "RVN",
+			versionMask: 0x1fffe000,
+			params: []interface{}{
+				[]string{"version-rolling"},
+				map[string]interface{}{"version-rolling.mask": "ffffffff"},
+			},
+			result: map[string]interface{}{"version-rolling": false},
+		},
+		{
+			chain:  "KAS",
+			params: []interface{}{[]string{"version-rolling"}},
+			result: map[string]interface{}{"version-rolling": false},
+		},
+	}
+
+	for i, tt := range tests {
+		p := &Pool{chain: tt.chain, versionMask: tt.versionMask}
+		serverConn, clientConn := net.Pipe()
+		c := stratum.NewConn(1, 0, "127.0.0.1", nil, serverConn)
+
+		req := rpc.MustNewRequest("mining.configure", tt.params...)
+		handler := p.routeRequest(req)
+		if handler == nil {
+			t.Errorf("failed on %d: no handler for mining.configure", i)
+			continue
+		}
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- handler(c, req) }()
+
+		clientConn.SetReadDeadline(time.Now().Add(time.Second))
+		scanner := bufio.NewScanner(clientConn)
+		if !scanner.Scan() {
+			t.Errorf("failed on %d: no response: %v", i, scanner.Err())
+			continue
+		} else if err := <-errCh; err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		}
+
+		res := new(rpc.Response)
+		if err := json.Unmarshal(scanner.Bytes(), res); err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if string(res.Result) != string(mustMarshal(t, tt.result)) {
+			t.Errorf("failed on %d: result mismatch: have %s, want %s", i, res.Result, mustMarshal(t, tt.result))
+		} else if mask := c.GetVersionMask(); mask != tt.connMask {
+			t.Errorf("failed on %d: mask mismatch: have %08x, want %08x", i, mask, tt.connMask)
+		}
+
+		serverConn.Close()
+		clientConn.Close()
+	}
+}
+
+func TestNewVersionMask(t *testing.T) {
+	// a version mask for a chain without version rolling is a config error
+	_, err := New(nil, nil, nil, nil, nil, nil, &Options{Chain: "RVN", VersionMask: 0x1fffe000})
+	if err == nil {
+		t.Errorf("expected error for a version mask on RVN")
+	}
+}
+
+func mustMarshal(t *testing.T, value interface{}) []byte {
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	return data
+}
+
+func TestRollVersion(t *testing.T) {
+	c := stratum.NewConn(1, 0, "127.0.0.1", nil, nil)
+	c.SetVersionMask(0x1fffe000)
+
+	tests := []struct {
+		jobVersion uint64
+		workBits   uint64
+		version    uint64
+		err        bool
+	}{
+		{0x20000000, 0x00000000, 0x20000000, false},
+		{0x20000000, 0x1fffe000, 0x3fffe000, false},
+		{0x20000004, 0x00002000, 0x20002004, false},
+		{0x20000000, 0x00001000, 0, true},
+	}
+
+	for i, tt := range tests {
+		job := &types.StratumJob{ID: "1", Version: new(types.Number).SetFromValue(tt.jobVersion)}
+		work := &types.StratumWork{Version: new(types.Number).SetFromValue(tt.workBits)}
+		err := rollVersion(c, job, work)
+		if tt.err {
+			if err == nil {
+				t.Errorf("failed on %d: expected error", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if work.Version.Value() != tt.version {
+			t.Errorf("failed on %d: version mismatch: have %08x, want %08x", i, work.Version.Value(), tt.version)
+		}
+	}
+
+	// jobs without a version (non sha256d chains) can't be rolled
+	job := &types.StratumJob{ID: "1"}
+	work := &types.StratumWork{Version: new(types.Number).SetFromValue(0x2000)}
+	if err := rollVersion(c, job, work); err == nil {
+		t.Errorf("expected error rolling a job without a version")
+	}
+}
@@ -0,0 +1,221 @@
+package worker
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	defaultJobRunLimit = 20
+	maxJobRunLimit     = 100
+)
+
+var (
+	adminJobRegex    = regexp.MustCompile(`^/jobs/([a-z_]+)$`)
+	adminActionRegex = regexp.MustCompile(`^/jobs/([a-z_]+)/(runs|trigger|pause|resume|schedule)$`)
+)
+
+type adminResponse struct {
+	Status  int         `json:"status"`
+	Message *string     `json:"message"`
+	Data    interface{} `json:"data"`
+}
+
+type scheduleArgs struct {
+	Schedule string `json:"schedule"`
+}
+
+// adminHandler is the operator endpoint of the worker. every request needs the admin
+// token as a bearer token, there are no roles since every action is an operator action.
+//
+//	GET  /jobs                  lists every job
+//	GET  /jobs/{name}           returns a single job
+//	GET  /jobs/{name}/runs      returns the latest runs of a job (?limit=, up to 100)
+//	POST /jobs/{name}/trigger   starts a run immediately
+//	POST /jobs/{name}/pause     pauses a job on every instance until it is resumed
+//	POST /jobs/{name}/resume    resumes a paused job
+//	POST /jobs/{name}/schedule  overrides the cron spec ({"schedule": "..."}, empty resets it)
+type adminHandler struct {
+	worker *Worker
+	token  string
+}
+
+func (h *adminHandler) writeError(w http.ResponseWriter, status int, err error) {
+	if status == http.StatusInternalServerError {
+		h.worker.logger.Error(fmt.Errorf("admin: %v", err))
+	}
+
+	msg := err.Error()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(adminResponse{Status: status, Message: &msg})
+}
+
+func (h *adminHandler) writeOk(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(adminResponse{Status: http.StatusOK, Data: data})
+}
+
+func (h *adminHandler) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+func (h *adminHandler) writeJobError(w http.ResponseWriter, err error) {
+	switch err {
+	case errJobNotFound:
+		h.writeError(w, http.StatusNotFound, err)
+	case errJobPaused, errJobLocked:
+		h.writeError(w, http.StatusConflict, err)
+	default:
+		h.writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (h *adminHandler) listJobs(w http.ResponseWriter) {
+	entries := h.worker.registry.list()
+	statuses := make([]*JobStatus, len(entries))
+	for i, entry := range entries {
+		status, err := h.worker.registry.status(entry.name)
+		if err != nil {
+			h.writeJobError(w, err)
+			return
+		}
+		statuses[i] = status
+	}
+
+	h.writeOk(w, statuses)
+}
+
+func (h *adminHandler) getJob(w http.ResponseWriter, name string) {
+	status, err := h.worker.registry.status(name)
+	if err != nil {
+		h.writeJobError(w, err)
+		return
+	}
+
+	h.writeOk(w, status)
+}
+
+func (h *adminHandler) getJobRuns(w http.ResponseWriter, r *http.Request, name string) {
+	limit := uint64(defaultJobRunLimit)
+	if rawLimit := r.URL.Query().Get("limit"); len(rawLimit) > 0 {
+		var err error
+		limit, err = strconv.ParseUint(rawLimit, 10, 64)
+		if err != nil || limit == 0 || limit > maxJobRunLimit {
+			h.writeError(w, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxJobRunLimit))
+			return
+		}
+	}
+
+	runs, err := h.worker.registry.runs(name, limit)
+	if err != nil {
+		h.writeJobError(w, err)
+		return
+	}
+
+	h.writeOk(w, runs)
+}
+
+func (h *adminHandler) handleAction(w http.ResponseWriter, r *http.Request, name, action string) {
+	var err error
+	switch action {
+	case "trigger":
+		var run *JobRun
+		run, err = h.worker.registry.trigger(name)
+		if err == nil {
+			h.worker.logger.Info(fmt.Sprintf("admin: triggered %s (run %d)", name, run.ID))
+			h.writeOk(w, run)
+			return
+		}
+	case "pause", "resume":
+		err = h.worker.registry.pause(name, action == "pause")
+	case "schedule":
+		r.Body = http.MaxBytesReader(w, r.Body, 1024)
+
+		var args scheduleArgs
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&args); err != nil {
+			h.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json body"))
+			return
+		}
+
+		if len(args.Schedule) > 0 {
+			if _, err := cronParser.Parse(args.Schedule); err != nil {
+				h.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid schedule: %v", err))
+				return
+			}
+		}
+
+		err = h.worker.registry.setSchedule(name, args.Schedule)
+	}
+
+	if err != nil {
+		h.writeJobError(w, err)
+		return
+	}
+
+	h.worker.logger.Info(fmt.Sprintf("admin: %s %s", action, name))
+	h.getJob(w, name)
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		h.writeError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	var method string
+	var handler func()
+	if path == "/jobs" {
+		method = "GET"
+		handler = func() { h.listJobs(w) }
+	} else if matches := adminJobRegex.FindStringSubmatch(path); len(matches) == 2 {
+		method = "GET"
+		handler = func() { h.getJob(w, matches[1]) }
+	} else if matches := adminActionRegex.FindStringSubmatch(path); len(matches) == 3 {
+		if matches[2] == "runs" {
+			method = "GET"
+			handler = func() { h.getJobRuns(w, r, matches[1]) }
+		} else {
+			method = "POST"
+			handler = func() { h.handleAction(w, r, matches[1], matches[2]) }
+		}
+	} else {
+		h.writeError(w, http.StatusNotFound, fmt.Errorf("not found"))
+		return
+	}
+
+	if r.Method != method {
+		h.writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+		return
+	}
+
+	handler()
+}
+
+// NewAdminServer returns the operator endpoint of the worker, protected by the given token.
+func (w *Worker) NewAdminServer(port int, token string) (*http.Server, error) {
+	if len(token) == 0 {
+		return nil, fmt.Errorf("no admin token")
+	}
+
+	server := &http.Server{
+		Addr:           fmt.Sprintf(":%d", port),
+		Handler:        &adminHandler{worker: w, token: token},
+		ReadTimeout:    10 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+
+	return server, nil
+}
@@ -1,39 +1,42 @@
 package worker
 
 import (
-	"context"
 	"fmt"
-	"time"
-
-	"github.com/bsm/redislock"
 
 	"github.com/magicpool-co/pool/core/audit"
 	"github.com/magicpool-co/pool/internal/log"
+	"github.com/magicpool-co/pool/internal/telegram"
 	"github.com/magicpool-co/pool/pkg/dbcl"
 	"github.com/magicpool-co/pool/types"
 )
 
 type AuditJob struct {
-	locker *redislock.Client
 	logger *log.Logger
 	pooldb *dbcl.Client
 	nodes  []types.PayoutNode
 }
 
-func (j *AuditJob) Run() {
-	defer j.logger.RecoverPanic()
-	lock, err := retrieveLock("cron:audit", time.Minute*5, j.locker)
-	if lock == nil {
-		if err != nil {
-			j.logger.Error(err)
+func (j *AuditJob) run(r *jobRun) {
+	for _, node := range j.nodes {
+		if err := audit.CheckWallet(j.pooldb, node); err != nil {
+			r.Error(fmt.Errorf("audit: %s: %v", node.Chain(), err))
+			continue
 		}
-		return
 	}
-	defer lock.Release(context.Background())
+}
+
+type ShareAuditJob struct {
+	logger      *log.Logger
+	pooldb      *dbcl.Client
+	telegram    *telegram.Client
+	nodes       []types.MiningNode
+	autoExclude bool
+}
 
+func (j *ShareAuditJob) run(r *jobRun) {
 	for _, node := range j.nodes {
-		if err := audit.CheckWallet(j.pooldb, node); err != nil {
-			j.logger.Error(fmt.Errorf("audit: %s: %v", node.Chain(), err))
+		if err := audit.CheckShares(j.pooldb, j.telegram, node.Chain(), j.autoExclude); err != nil {
+			r.Error(fmt.Errorf("share audit: %s: %v", node.Chain(), err))
 			continue
 		}
 	}
@@ -1,11 +1,7 @@
 package worker
 
 import (
-	"context"
 	"fmt"
-	"time"
-
-	"github.com/bsm/redislock"
 
 	"github.com/magicpool-co/pool/core/bank"
 	"github.com/magicpool-co/pool/internal/log"
@@ -16,7 +12,6 @@ import (
 )
 
 type BankJob struct {
-	locker   *redislock.Client
 	logger   *log.Logger
 	pooldb   *dbcl.Client
 	redis    *redis.Client
@@ -24,27 +19,17 @@ type BankJob struct {
 	telegram *telegram.Client
 }
 
-func (j *BankJob) Run() {
-	defer j.logger.RecoverPanic()
-	lock, err := retrieveLock("cron:bank", time.Minute*5, j.locker)
-	if lock == nil {
-		if err != nil {
-			j.logger.Error(err)
-		}
-		return
-	}
-	defer lock.Release(context.Background())
-
+func (j *BankJob) run(r *jobRun) {
 	client := bank.New(j.pooldb, j.redis, j.telegram)
 	for _, node := range j.nodes {
-		err = client.BroadcastOutgoingTxs(node)
+		err := client.BroadcastOutgoingTxs(node)
 		if err != nil {
-			j.logger.Error(fmt.Errorf("bank: broadcast: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("bank: broadcast: %s: %v", node.Chain(), err))
 		}
 
 		err = client.ConfirmOutgoingTxs(node)
 		if err != nil {
-			j.logger.Error(fmt.Errorf("bank: confirm: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("bank: confirm: %s: %v", node.Chain(), err))
 		}
 	}
 }
@@ -1,14 +1,11 @@
 package worker
 
 import (
-	"context"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/bsm/redislock"
-
 	"github.com/magicpool-co/pool/core/mailer"
 	"github.com/magicpool-co/pool/internal/log"
 	"github.com/magicpool-co/pool/internal/pooldb"
@@ -41,44 +38,33 @@ func parseCompoundID(compoundID string) (uint64, uint64, string, error) {
 }
 
 type MinerJob struct {
-	locker *redislock.Client
 	logger *log.Logger
 	pooldb *dbcl.Client
 	redis  *redis.Client
 	nodes  []types.MiningNode
 }
 
-func (j *MinerJob) Run() {
-	defer j.logger.RecoverPanic()
-	lock, err := retrieveLock("cron:miner", time.Minute*5, j.locker)
-	if lock == nil {
-		if err != nil {
-			j.logger.Error(err)
-		}
-		return
-	}
-	defer lock.Release(context.Background())
-
+func (j *MinerJob) run(r *jobRun) {
 	for _, node := range j.nodes {
 		ipAddressIdx, err := j.redis.GetMinerIPAddresses(node.Chain())
 		if err != nil {
-			j.logger.Error(fmt.Errorf("ip: fetch ips: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("ip: fetch ips: %s: %v", node.Chain(), err))
 		}
 
 		inactiveIpAddressIdx, err := j.redis.GetMinerIPAddressesInactive(node.Chain())
 		if err != nil {
-			j.logger.Error(fmt.Errorf("ip: fetch inactive ips: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("ip: fetch inactive ips: %s: %v", node.Chain(), err))
 		}
 
 		diffCache := make(map[int]float64)
 		diffIdx, err := j.redis.GetMinerDifficulties(node.Chain())
 		if err != nil {
-			j.logger.Error(fmt.Errorf("ip: fetch difficulties: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("ip: fetch difficulties: %s: %v", node.Chain(), err))
 		}
 
 		rttIdx, err := j.redis.GetMinerLatencies(node.Chain())
 		if err != nil {
-			j.logger.Error(fmt.Errorf("ip: fetch latencies: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("ip: fetch latencies: %s: %v", node.Chain(), err))
 		}
 
 		// process the index into a slice of addresses
@@ -90,7 +76,7 @@ func (j *MinerJob) Run() {
 		for compoundID, timestamp := range ipAddressIdx {
 			minerID, workerID, ipAddress, err := parseCompoundID(compoundID)
 			if err != nil {
-				j.logger.Error(fmt.Errorf("ip: %v", err))
+				r.Error(fmt.Errorf("ip: %v", err))
 				continue
 			}
 
@@ -149,21 +135,20 @@ func (j *MinerJob) Run() {
 
 		// insert the ip addresses, set old addresses to inactive or expired, clear the redis sorted set
 		if err := pooldb.InsertIPAddresses(j.pooldb.Writer(), addresses...); err != nil {
-			j.logger.Error(fmt.Errorf("ip: insert: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("ip: insert: %s: %v", node.Chain(), err))
 		} else if err := pooldb.UpdateWorkerSetInactive(j.pooldb.Writer(), inactiveWorkers); err != nil {
-			j.logger.Error(fmt.Errorf("ip: set inactive: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("ip: set inactive: %s: %v", node.Chain(), err))
 		} else if err := j.redis.AddMinerIPAddressesInactive(node.Chain(), addToInactiveIPs); err != nil {
-			j.logger.Error(fmt.Errorf("ip: add inactive: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("ip: add inactive: %s: %v", node.Chain(), err))
 		} else if err := j.redis.RemoveMinerIPAddressesInactive(node.Chain(), removeFromInactiveIPs); err != nil {
-			j.logger.Error(fmt.Errorf("ip: remove inactive: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("ip: remove inactive: %s: %v", node.Chain(), err))
 		} else if err := j.redis.RemoveMinerIPAddresses(node.Chain(), removeFromActiveIPs); err != nil {
-			j.logger.Error(fmt.Errorf("ip: remove active: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("ip: remove active: %s: %v", node.Chain(), err))
 		}
 	}
 }
 
 type MinerNotifyJob struct {
-	locker   *redislock.Client
 	logger   *log.Logger
 	pooldb   *dbcl.Client
 	redis    *redis.Client
@@ -207,22 +192,12 @@ func (j *MinerNotifyJob) notifyMiner(miner *pooldb.Miner, workers []*pooldb.Work
 	return j.mailer.SendEmailForWorkers(types.StringValue(miner.Email), address, workerIdx)
 }
 
-func (j *MinerNotifyJob) Run() {
-	defer j.logger.RecoverPanic()
-	lock, err := retrieveLock("cron:minerntfy", time.Minute*5, j.locker)
-	if lock == nil {
-		if err != nil {
-			j.logger.Error(err)
-		}
-		return
-	}
-	defer lock.Release(context.Background())
-
+func (j *MinerNotifyJob) run(r *jobRun) {
 	inactiveIpAddressIdx := make(map[string]bool)
 	for _, node := range j.nodes {
 		partialInactiveIdx, err := j.redis.GetMinerIPAddressesInactive(node.Chain())
 		if err != nil {
-			j.logger.Error(fmt.Errorf("notify: fetch inactive ips: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("notify: fetch inactive ips: %s: %v", node.Chain(), err))
 			return
 		}
 
@@ -235,7 +210,7 @@ func (j *MinerNotifyJob) Run() {
 	for compoundID := range inactiveIpAddressIdx {
 		_, workerID, _, err := parseCompoundID(compoundID)
 		if err != nil {
-			j.logger.Error(fmt.Errorf("notify: %v", err))
+			r.Error(fmt.Errorf("notify: %v", err))
 			continue
 		}
 
@@ -249,7 +224,7 @@ func (j *MinerNotifyJob) Run() {
 
 	workers, err := pooldb.GetWorkersWithLastShares(j.pooldb.Reader(), workerIDs)
 	if err != nil {
-		j.logger.Error(fmt.Errorf("notify: get workers: %v", err))
+		r.Error(fmt.Errorf("notify: get workers: %v", err))
 		return
 	}
 
@@ -271,7 +246,7 @@ func (j *MinerNotifyJob) Run() {
 
 	miners, err := pooldb.GetMiners(j.pooldb.Reader(), minerIDs)
 	if err != nil {
-		j.logger.Error(fmt.Errorf("notify: get miners: %v", err))
+		r.Error(fmt.Errorf("notify: get miners: %v", err))
 	}
 
 	minerIdx := make(map[uint64]*pooldb.Miner, 0)
@@ -289,12 +264,12 @@ func (j *MinerNotifyJob) Run() {
 
 		err := j.notifyMiner(miner, workers)
 		if err != nil {
-			j.logger.Error(fmt.Errorf("notify: notify miner: %v", err))
+			r.Error(fmt.Errorf("notify: notify miner: %v", err))
 			continue
 		}
 	}
 
 	if err := pooldb.UpdateWorkerSetActive(j.pooldb.Writer()); err != nil {
-		j.logger.Error(fmt.Errorf("notify: set active: %v", err))
+		r.Error(fmt.Errorf("notify: set active: %v", err))
 	}
 }
@@ -1,14 +1,11 @@
 package worker
 
 import (
-	"context"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/bsm/redislock"
-
 	"github.com/magicpool-co/pool/internal/log"
 	"github.com/magicpool-co/pool/internal/metrics"
 	"github.com/magicpool-co/pool/internal/pooldb"
@@ -70,34 +67,20 @@ func getNodeContainer(awsClient *aws.Client, zoneID, cluster, url string) (strin
 }
 
 type NodeStatusJob struct {
-	locker  *redislock.Client
 	logger  *log.Logger
 	pooldb  *dbcl.Client
 	nodes   []types.MiningNode
 	metrics *metrics.Client
 }
 
-func (j *NodeStatusJob) Run() {
-	defer j.logger.RecoverPanic()
+func (j *NodeStatusJob) run(r *jobRun) {
 	// need to run even if locked since PingHosts sets the status of the hostpool.
 	// if it isn't locked, don't update the database though since that isn't critical
-	var didObtainLock bool
-	lock, err := retrieveLock("cron:nodestatus", time.Minute*5, j.locker)
-	if lock == nil {
-		if err != nil {
-			j.logger.Error(err)
-			return
-		}
-	} else {
-		defer lock.Release(context.Background())
-		didObtainLock = true
-	}
-
 	for _, node := range j.nodes {
 		hostIDs, heights, syncings, errs := node.PingHosts()
 		for i := range hostIDs {
 			if errs[i] != nil {
-				j.logger.Error(errs[i])
+				r.Error(errs[i])
 				continue
 			}
 
@@ -107,7 +90,7 @@ func (j *NodeStatusJob) Run() {
 				region = parts[2]
 			}
 
-			if didObtainLock {
+			if r.locked {
 				poolNode := &pooldb.Node{
 					URL:    hostIDs[i],
 					Active: true,
@@ -118,7 +101,7 @@ func (j *NodeStatusJob) Run() {
 				cols := []string{"active", "synced", "height"}
 				err := pooldb.UpdateNode(j.pooldb.Writer(), poolNode, cols)
 				if err != nil {
-					j.logger.Error(err)
+					r.Error(err)
 				}
 			}
 
@@ -132,23 +115,12 @@ func (j *NodeStatusJob) Run() {
 type NodeInstanceChangeJob struct {
 	env      string
 	mainnet  bool
-	locker   *redislock.Client
 	logger   *log.Logger
 	aws      *aws.Client
 	telegram *telegram.Client
 }
 
-func (j *NodeInstanceChangeJob) Run() {
-	defer j.logger.RecoverPanic()
-	lock, err := retrieveLock("cron:nodeinstancechange", time.Minute*5, j.locker)
-	if lock == nil {
-		if err != nil {
-			j.logger.Error(err)
-		}
-		return
-	}
-	defer lock.Release(context.Background())
-
+func (j *NodeInstanceChangeJob) run(r *jobRun) {
 	prefix := "mainnet"
 	if !j.mainnet {
 		prefix = "testnet"
@@ -156,28 +128,28 @@ func (j *NodeInstanceChangeJob) Run() {
 
 	zoneID, err := route53.GetZoneIDByName(j.aws, zoneName)
 	if err != nil {
-		j.logger.Error(err)
+		r.Error(err)
 		return
 	}
 
 	for _, region := range []string{"eu-west-1", "eu-central-1", "us-east-1", "us-west-2"} {
 		client, err := aws.NewSession(region, "")
 		if err != nil {
-			j.logger.Error(err)
+			r.Error(err)
 			continue
 		}
 
 		queue := fmt.Sprintf("%s-full-node-asg-events-%s", prefix, region)
 		msgs, err := sqs.PopFromQueue(client, queue)
 		if err != nil {
-			j.logger.Error(err)
+			r.Error(err)
 			continue
 		}
 
 		for _, msg := range msgs {
 			asg, ok := msg.Attributes["AutoScalingGroupName"]
 			if !ok {
-				j.logger.Error(fmt.Errorf("no asg name for node instance event"))
+				r.Error(fmt.Errorf("no asg name for node instance event"))
 				continue
 			}
 
@@ -206,14 +178,14 @@ func (j *NodeInstanceChangeJob) Run() {
 				needsRebalance = true
 				j.telegram.NotifyNodeInstanceTerminated(chain, region, instanceIP)
 			default:
-				j.logger.Error(fmt.Errorf("unknown node instance event: %s", msg.Attributes["LifecycleTransition"]))
+				r.Error(fmt.Errorf("unknown node instance event: %s", msg.Attributes["LifecycleTransition"]))
 				continue
 			}
 
 			if needsRebalance && asg != "" && chain != "" {
 				ips, err := ec2.GetGroupInstanceIPs(client, asg)
 				if err != nil {
-					j.logger.Error(err)
+					r.Error(err)
 					continue
 				}
 
@@ -227,13 +199,13 @@ func (j *NodeInstanceChangeJob) Run() {
 
 				err = route53.UpdateARecords(j.aws, zoneID, records)
 				if err != nil {
-					j.logger.Error(err)
+					r.Error(err)
 				}
 			}
 
 			err := sqs.DeleteFromQueue(client, queue, msg.ID)
 			if err != nil {
-				j.logger.Error(err)
+				r.Error(err)
 			}
 		}
 	}
@@ -242,26 +214,15 @@ func (j *NodeInstanceChangeJob) Run() {
 type NodeCheckJob struct {
 	env     string
 	mainnet bool
-	locker  *redislock.Client
 	logger  *log.Logger
 	aws     *aws.Client
 	pooldb  *dbcl.Client
 }
 
-func (j *NodeCheckJob) Run() {
-	defer j.logger.RecoverPanic()
-	lock, err := retrieveLock("cron:nodecheck", time.Minute*5, j.locker)
-	if lock == nil {
-		if err != nil {
-			j.logger.Error(err)
-		}
-		return
-	}
-	defer lock.Release(context.Background())
-
+func (j *NodeCheckJob) run(r *jobRun) {
 	nodes, err := pooldb.GetEnabledNodes(j.pooldb.Reader(), j.mainnet)
 	if err != nil {
-		j.logger.Error(err)
+		r.Error(err)
 		return
 	}
 
@@ -272,7 +233,7 @@ func (j *NodeCheckJob) Run() {
 			needsBackup := node.BackupAt == nil
 			if !needsBackup {
 				switch node.ChainID {
-				case "KAS":
+				case "KAS", "KLS":
 					needsBackup = time.Since(types.TimeValue(node.BackupAt)) >= time.Hour*24*2
 				default:
 					needsBackup = time.Since(types.TimeValue(node.BackupAt)) >= backupPeriod
@@ -288,7 +249,7 @@ func (j *NodeCheckJob) Run() {
 		cols := []string{"needs_backup", "pending_backup"}
 		err = pooldb.UpdateNode(j.pooldb.Writer(), node, cols)
 		if err != nil {
-			j.logger.Error(err)
+			r.Error(err)
 			continue
 		}
 	}
@@ -297,32 +258,21 @@ func (j *NodeCheckJob) Run() {
 type NodeBackupJob struct {
 	env     string
 	mainnet bool
-	locker  *redislock.Client
 	logger  *log.Logger
 	aws     *aws.Client
 	pooldb  *dbcl.Client
 }
 
-func (j *NodeBackupJob) Run() {
-	defer j.logger.RecoverPanic()
-	lock, err := retrieveLock("cron:nodebackup", time.Hour*4, j.locker)
-	if lock == nil {
-		if err != nil {
-			j.logger.Error(err)
-		}
-		return
-	}
-	defer lock.Release(context.Background())
-
+func (j *NodeBackupJob) run(r *jobRun) {
 	pendingNodes, err := pooldb.GetPendingBackupNodes(j.pooldb.Reader(), j.mainnet)
 	if err != nil {
-		j.logger.Error(err)
+		r.Error(err)
 		return
 	}
 
 	zoneID, err := route53.GetZoneIDByName(j.aws, zoneName)
 	if err != nil {
-		j.logger.Error(err)
+		r.Error(err)
 		return
 	}
 
@@ -333,13 +283,13 @@ func (j *NodeBackupJob) Run() {
 
 		instanceID, containerID, err := getNodeContainer(j.aws, zoneID, cluster, node.URL)
 		if err != nil {
-			j.logger.Error(err)
+			r.Error(err)
 			continue
 		}
 
 		err = ecs.DrainClusterContainerInstance(j.aws, cluster, containerID)
 		if err != nil {
-			j.logger.Error(err)
+			r.Error(err)
 			continue
 		}
 
@@ -347,19 +297,19 @@ func (j *NodeBackupJob) Run() {
 
 		commandID, err := ec2.SendCommandToInstance(j.aws, instanceID, cmds)
 		if err != nil {
-			j.logger.Error(err)
+			r.Error(err)
 			continue
 		}
 
 		_, err = ec2.WaitForCommand(j.aws, instanceID, commandID)
 		if err != nil {
-			j.logger.Error(err)
+			r.Error(err)
 			continue
 		}
 
 		err = ecs.ActivateClusterContainerInstance(j.aws, cluster, containerID)
 		if err != nil {
-			j.logger.Error(err)
+			r.Error(err)
 			continue
 		}
 
@@ -370,7 +320,7 @@ func (j *NodeBackupJob) Run() {
 		cols := []string{"needs_backup", "pending_backup", "backup_at"}
 		err = pooldb.UpdateNode(j.pooldb.Writer(), node, cols)
 		if err != nil {
-			j.logger.Error(err)
+			r.Error(err)
 			continue
 		}
 	}
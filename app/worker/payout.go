@@ -1,11 +1,7 @@
 package worker
 
 import (
-	"context"
 	"fmt"
-	"time"
-
-	"github.com/bsm/redislock"
 
 	"github.com/magicpool-co/pool/core/mailer"
 	"github.com/magicpool-co/pool/core/payout"
@@ -17,7 +13,6 @@ import (
 )
 
 type PayoutJob struct {
-	locker   *redislock.Client
 	logger   *log.Logger
 	pooldb   *dbcl.Client
 	redis    *redis.Client
@@ -26,26 +21,14 @@ type PayoutJob struct {
 	telegram *telegram.Client
 }
 
-func (j *PayoutJob) Run() {
-	defer j.logger.RecoverPanic()
-
-	ctx := context.Background()
-	lock, err := j.locker.Obtain(ctx, "cron:payout", time.Minute*5, nil)
-	if err != nil {
-		if err != redislock.ErrNotObtained {
-			j.logger.Error(err)
-		}
-		return
-	}
-	defer lock.Release(ctx)
-
+func (j *PayoutJob) run(r *jobRun) {
 	client := payout.New(j.pooldb, j.redis, j.telegram, j.mailer)
 
 	for _, node := range j.nodes {
 		if err := client.InitiatePayouts(node); err != nil {
-			j.logger.Error(fmt.Errorf("payout: initiate: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("payout: initiate: %s: %v", node.Chain(), err))
 		} else if err := client.FinalizePayouts(node); err != nil {
-			j.logger.Error(fmt.Errorf("payout: finalize: %s: %v", node.Chain(), err))
+			r.Error(fmt.Errorf("payout: finalize: %s: %v", node.Chain(), err))
 		}
 	}
 }
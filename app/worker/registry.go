@@ -0,0 +1,573 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bsm/redislock"
+	"github.com/robfig/cron/v3"
+
+	"github.com/magicpool-co/pool/internal/log"
+	"github.com/magicpool-co/pool/internal/metrics"
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+const (
+	jobStatusRunning = "running"
+	jobStatusSuccess = "success"
+	jobStatusFailed  = "failed"
+	jobStatusPanic   = "panic"
+
+	// only the first few errors of a run are stored, the rest are only logged
+	maxRunErrors = 10
+	// job runs older than the retention are pruned hourly
+	jobRunRetention = time.Hour * 24 * 30
+	// pause and schedule changes made by other instances are picked up within the sync interval
+	jobSyncSpec  = "*/30 * * * * *"
+	jobPruneSpec = "0 * * * *"
+)
+
+var (
+	errJobNotFound = fmt.Errorf("job not found")
+	errJobPaused   = fmt.Errorf("job is paused")
+	errJobLocked   = fmt.Errorf("job is already running")
+
+	cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+)
+
+// job is implemented by every worker job. locking, panic recovery and run history are
+// handled by the registry, so jobs only have to report their errors to the run.
+type job interface {
+	run(r *jobRun)
+}
+
+// jobRun is a single run of a job. errors are logged as they are reported and
+// stored with the run, any reported error marks the run as failed.
+type jobRun struct {
+	logger *log.Logger
+	locked bool
+	record *pooldb.WorkerJobRun
+
+	mu     sync.Mutex
+	errors []string
+	count  uint64
+}
+
+func (r *jobRun) Error(err error) {
+	r.logger.Error(err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.count++
+	if len(r.errors) < maxRunErrors {
+		r.errors = append(r.errors, err.Error())
+	}
+}
+
+type registeredJob struct {
+	name            string
+	job             job
+	lockName        string
+	lockTimeout     time.Duration
+	lockOptional    bool
+	defaultSchedule string
+
+	mu       sync.Mutex
+	entryID  cron.EntryID
+	schedule string
+	paused   bool
+}
+
+// JobRun is a recorded run of a job, as returned by the operator endpoint.
+type JobRun struct {
+	ID         uint64     `json:"id"`
+	Manual     bool       `json:"manual"`
+	Status     string     `json:"status"`
+	ErrorCount uint64     `json:"errorCount"`
+	Errors     []string   `json:"errors"`
+	LockHolder *string    `json:"lockHolder"`
+	StartedAt  time.Time  `json:"startedAt"`
+	EndedAt    *time.Time `json:"endedAt"`
+}
+
+func newJobRun(record *pooldb.WorkerJobRun) *JobRun {
+	run := &JobRun{
+		ID:         record.ID,
+		Manual:     record.Manual,
+		Status:     record.Status,
+		ErrorCount: record.ErrorCount,
+		Errors:     make([]string, 0),
+		LockHolder: record.LockHolder,
+		StartedAt:  record.StartedAt,
+		EndedAt:    record.EndedAt,
+	}
+	if record.ErrorMessage != nil {
+		run.Errors = strings.Split(types.StringValue(record.ErrorMessage), "\n")
+	}
+
+	return run
+}
+
+// JobStatus is the current state of a job, as returned by the operator endpoint.
+type JobStatus struct {
+	Name            string     `json:"name"`
+	Schedule        string     `json:"schedule"`
+	DefaultSchedule string     `json:"defaultSchedule"`
+	Paused          bool       `json:"paused"`
+	NextRun         *time.Time `json:"nextRun"`
+	LastRun         *JobRun    `json:"lastRun"`
+}
+
+// registry schedules the worker jobs and records every run in pooldb. the paused state and
+// schedule overrides are stored in pooldb as well, so they survive restarts and are shared
+// between every worker instance.
+type registry struct {
+	cron    *cron.Cron
+	locker  *redislock.Client
+	pooldb  *dbcl.Client
+	logger  *log.Logger
+	metrics *metrics.Client
+	holder  string
+	wg      sync.WaitGroup
+
+	mu    sync.RWMutex
+	jobs  map[string]*registeredJob
+	names []string
+}
+
+func newRegistry(
+	cronClient *cron.Cron,
+	locker *redislock.Client,
+	pooldbClient *dbcl.Client,
+	logger *log.Logger,
+	metricsClient *metrics.Client,
+) *registry {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	reg := &registry{
+		cron:    cronClient,
+		locker:  locker,
+		pooldb:  pooldbClient,
+		logger:  logger,
+		metrics: metricsClient,
+		holder:  fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+		jobs:    make(map[string]*registeredJob),
+		names:   make([]string, 0),
+	}
+
+	return reg
+}
+
+// add registers a job under the given lock. jobs with an optional lock run even
+// if another instance holds it, jobRun.locked tells the job whether it holds the lock.
+func (r *registry) add(
+	name, schedule, lockName string,
+	lockTimeout time.Duration,
+	lockOptional bool,
+	j job,
+) error {
+	entry := &registeredJob{
+		name:            name,
+		job:             j,
+		lockName:        lockName,
+		lockTimeout:     lockTimeout,
+		lockOptional:    lockOptional,
+		defaultSchedule: schedule,
+		schedule:        schedule,
+	}
+
+	entryID, err := r.cron.AddFunc(schedule, func() { r.runScheduled(entry) })
+	if err != nil {
+		return err
+	}
+	entry.entryID = entryID
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs[name] = entry
+	r.names = append(r.names, name)
+	sort.Strings(r.names)
+
+	return nil
+}
+
+func (r *registry) get(name string) (*registeredJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.jobs[name]
+	if !ok {
+		return nil, errJobNotFound
+	}
+
+	return entry, nil
+}
+
+func (r *registry) list() []*registeredJob {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*registeredJob, len(r.names))
+	for i, name := range r.names {
+		entries[i] = r.jobs[name]
+	}
+
+	return entries
+}
+
+func (r *registry) start() {
+	r.sync()
+
+	_, err := r.cron.AddFunc(jobSyncSpec, r.sync)
+	if err != nil {
+		r.logger.Error(fmt.Errorf("registry: sync: %v", err))
+	}
+
+	_, err = r.cron.AddFunc(jobPruneSpec, r.prune)
+	if err != nil {
+		r.logger.Error(fmt.Errorf("registry: prune: %v", err))
+	}
+}
+
+// wait blocks until every manually triggered run is done.
+func (r *registry) wait() {
+	r.wg.Wait()
+}
+
+/* state */
+
+func (r *registry) setPausedState(entry *registeredJob, paused bool) {
+	entry.mu.Lock()
+	entry.paused = paused
+	entry.mu.Unlock()
+
+	if r.metrics != nil {
+		var value float64
+		if paused {
+			value = 1
+		}
+		r.metrics.SetGauge("job_paused", value, entry.name)
+	}
+}
+
+func (r *registry) setScheduleState(entry *registeredJob, schedule string) error {
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if schedule == entry.schedule {
+		return nil
+	}
+
+	entryID, err := r.cron.AddFunc(schedule, func() { r.runScheduled(entry) })
+	if err != nil {
+		return err
+	}
+
+	r.cron.Remove(entry.entryID)
+	entry.entryID = entryID
+	entry.schedule = schedule
+
+	return nil
+}
+
+// sync applies the paused state and schedule overrides stored in pooldb.
+func (r *registry) sync() {
+	defer r.logger.RecoverPanic()
+
+	dbJobs, err := pooldb.GetWorkerJobs(r.pooldb.Reader())
+	if err != nil {
+		r.logger.Error(fmt.Errorf("registry: sync: %v", err))
+		return
+	}
+
+	idx := make(map[string]*pooldb.WorkerJob, len(dbJobs))
+	for _, dbJob := range dbJobs {
+		idx[dbJob.Name] = dbJob
+	}
+
+	for _, entry := range r.list() {
+		paused, schedule := false, entry.defaultSchedule
+		if dbJob, ok := idx[entry.name]; ok {
+			paused = dbJob.Paused
+			if dbJob.Schedule != nil {
+				schedule = types.StringValue(dbJob.Schedule)
+			}
+		}
+
+		r.setPausedState(entry, paused)
+		if err := r.setScheduleState(entry, schedule); err != nil {
+			r.logger.Error(fmt.Errorf("registry: sync: %s: %v", entry.name, err))
+		}
+	}
+}
+
+func (r *registry) prune() {
+	defer r.logger.RecoverPanic()
+
+	cutoff := time.Now().Add(jobRunRetention * -1)
+	err := pooldb.DeleteWorkerJobRunsBefore(r.pooldb.Writer(), cutoff)
+	if err != nil {
+		r.logger.Error(fmt.Errorf("registry: prune: %v", err))
+	}
+}
+
+func (r *registry) pause(name string, paused bool) error {
+	entry, err := r.get(name)
+	if err != nil {
+		return err
+	}
+
+	dbJob := &pooldb.WorkerJob{Name: name, Paused: paused}
+	err = pooldb.InsertUpdateWorkerJob(r.pooldb.Writer(), dbJob, []string{"paused"})
+	if err != nil {
+		return err
+	}
+	r.setPausedState(entry, paused)
+
+	return nil
+}
+
+// setSchedule overrides the schedule of a job, an empty schedule resets it to the default.
+func (r *registry) setSchedule(name, schedule string) error {
+	entry, err := r.get(name)
+	if err != nil {
+		return err
+	}
+
+	dbJob := &pooldb.WorkerJob{Name: name}
+	if len(schedule) == 0 {
+		schedule = entry.defaultSchedule
+	} else {
+		if _, err := cronParser.Parse(schedule); err != nil {
+			return err
+		}
+		dbJob.Schedule = types.StringPtr(schedule)
+	}
+
+	err = pooldb.InsertUpdateWorkerJob(r.pooldb.Writer(), dbJob, []string{"schedule"})
+	if err != nil {
+		return err
+	}
+
+	return r.setScheduleState(entry, schedule)
+}
+
+func (r *registry) status(name string) (*JobStatus, error) {
+	entry, err := r.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.mu.Lock()
+	status := &JobStatus{
+		Name:            entry.name,
+		Schedule:        entry.schedule,
+		DefaultSchedule: entry.defaultSchedule,
+		Paused:          entry.paused,
+	}
+	entryID := entry.entryID
+	entry.mu.Unlock()
+
+	if next := r.cron.Entry(entryID).Next; !next.IsZero() {
+		status.NextRun = types.TimePtr(next)
+	}
+
+	runs, err := pooldb.GetWorkerJobRuns(r.pooldb.Reader(), name, 1)
+	if err != nil {
+		return nil, err
+	} else if len(runs) > 0 {
+		status.LastRun = newJobRun(runs[0])
+	}
+
+	return status, nil
+}
+
+func (r *registry) runs(name string, limit uint64) ([]*JobRun, error) {
+	if _, err := r.get(name); err != nil {
+		return nil, err
+	}
+
+	records, err := pooldb.GetWorkerJobRuns(r.pooldb.Reader(), name, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]*JobRun, len(records))
+	for i, record := range records {
+		runs[i] = newJobRun(record)
+	}
+
+	return runs, nil
+}
+
+/* runs */
+
+// begin obtains the lock of the job and records the start of the run. nil is returned if the
+// run should be skipped, either since the job is paused or another instance holds the lock.
+func (r *registry) begin(entry *registeredJob, manual bool) (*jobRun, *redislock.Lock, error) {
+	entry.mu.Lock()
+	paused := entry.paused
+	entry.mu.Unlock()
+
+	if paused {
+		return nil, nil, errJobPaused
+	}
+
+	lock, err := retrieveLock(entry.lockName, entry.lockTimeout, r.locker)
+	if err != nil {
+		return nil, nil, err
+	} else if lock == nil && !entry.lockOptional {
+		return nil, nil, errJobLocked
+	}
+
+	// the paused state is only synced periodically, so it is re-read from the writer
+	// once the lock is held. this way a job paused by another instance (or operator)
+	// never starts a new run after the pause has been committed.
+	dbJob, err := pooldb.GetWorkerJob(r.pooldb.Writer(), entry.name)
+	if err != nil || (dbJob != nil && dbJob.Paused) {
+		if lock != nil {
+			lock.Release(context.Background())
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r.setPausedState(entry, true)
+
+		return nil, nil, errJobPaused
+	}
+
+	run := &jobRun{
+		logger: r.logger,
+		locked: lock != nil,
+		record: &pooldb.WorkerJobRun{
+			JobName:   entry.name,
+			Manual:    manual,
+			Status:    jobStatusRunning,
+			StartedAt: time.Now().UTC(),
+		},
+	}
+	if run.locked {
+		run.record.LockHolder = types.StringPtr(r.holder)
+	}
+
+	// the run history is best effort, a failed insert shouldn't stop the job
+	run.record.ID, err = pooldb.InsertWorkerJobRun(r.pooldb.Writer(), run.record)
+	if err != nil {
+		r.logger.Error(fmt.Errorf("registry: %s: insert run: %v", entry.name, err))
+	}
+
+	return run, lock, nil
+}
+
+// execute runs the job and records the outcome of the run. panics are recorded
+// before being passed on to the logger, which stops the worker.
+func (r *registry) execute(entry *registeredJob, run *jobRun, lock *redislock.Lock) {
+	if lock != nil {
+		defer lock.Release(context.Background())
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			run.Error(fmt.Errorf("panic: %v", rec))
+			r.finish(entry, run, true)
+			r.logger.Panic(rec, string(debug.Stack()))
+			return
+		}
+
+		r.finish(entry, run, false)
+	}()
+
+	entry.job.run(run)
+}
+
+func (r *registry) finish(entry *registeredJob, run *jobRun, panicked bool) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	status := jobStatusSuccess
+	if panicked {
+		status = jobStatusPanic
+	} else if run.count > 0 {
+		status = jobStatusFailed
+	}
+
+	endedAt := time.Now().UTC()
+	run.record.Status = status
+	run.record.EndedAt = types.TimePtr(endedAt)
+	run.record.ErrorCount = run.count
+	if len(run.errors) > 0 {
+		run.record.ErrorMessage = types.StringPtr(strings.Join(run.errors, "\n"))
+	}
+
+	if run.record.ID != 0 {
+		cols := []string{"status", "error_count", "error_message", "ended_at"}
+		err := pooldb.UpdateWorkerJobRun(r.pooldb.Writer(), run.record, cols)
+		if err != nil {
+			r.logger.Error(fmt.Errorf("registry: %s: update run: %v", entry.name, err))
+		}
+	}
+
+	if r.metrics != nil {
+		duration := endedAt.Sub(run.record.StartedAt).Seconds()
+		r.metrics.IncrementCounter("job_runs_total", entry.name, status)
+		r.metrics.ObserveHistogram("job_duration_seconds", duration, entry.name)
+		if status == jobStatusSuccess {
+			r.metrics.SetGauge("job_last_success_timestamp", float64(endedAt.Unix()), entry.name)
+		}
+	}
+}
+
+func (r *registry) runScheduled(entry *registeredJob) {
+	defer r.logger.RecoverPanic()
+
+	run, lock, err := r.begin(entry, false)
+	if err == errJobPaused || err == errJobLocked {
+		return
+	} else if err != nil {
+		r.logger.Error(err)
+		return
+	}
+
+	r.execute(entry, run, lock)
+}
+
+// trigger starts a run immediately, returning once the run has been recorded. paused jobs
+// can't be triggered, the job has to be resumed first.
+func (r *registry) trigger(name string) (*JobRun, error) {
+	entry, err := r.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	run, lock, err := r.begin(entry, true)
+	if err != nil {
+		return nil, err
+	}
+
+	triggered := newJobRun(run.record)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer r.logger.RecoverPanic()
+
+		r.execute(entry, run, lock)
+	}()
+
+	return triggered, nil
+}
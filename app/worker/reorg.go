@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"fmt"
+
+	"github.com/magicpool-co/pool/core/reorg"
+	"github.com/magicpool-co/pool/internal/log"
+	"github.com/magicpool-co/pool/internal/redis"
+	"github.com/magicpool-co/pool/internal/telegram"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+type ReorgJob struct {
+	logger   *log.Logger
+	pooldb   *dbcl.Client
+	redis    *redis.Client
+	nodes    []types.MiningNode
+	telegram *telegram.Client
+	alerts   *alertThresholds
+}
+
+func (j *ReorgJob) run(r *jobRun) {
+	client := reorg.New(j.pooldb, j.redis, j.telegram, j.alerts.getReorgDepths())
+	for _, node := range j.nodes {
+		if err := client.CheckChain(node); err != nil {
+			r.Error(fmt.Errorf("reorg: %s: %v", node.Chain(), err))
+		}
+	}
+}
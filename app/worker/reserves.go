@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"fmt"
+
+	"github.com/magicpool-co/pool/core/audit"
+	"github.com/magicpool-co/pool/internal/log"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+type ReserveProofJob struct {
+	logger *log.Logger
+	pooldb *dbcl.Client
+	nodes  []types.PayoutNode
+}
+
+func (j *ReserveProofJob) run(r *jobRun) {
+	for _, node := range j.nodes {
+		if err := audit.PublishReserveProof(j.pooldb, node); err != nil {
+			r.Error(fmt.Errorf("reserves: %s: %v", node.Chain(), err))
+			continue
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/journal"
+	"github.com/magicpool-co/pool/internal/log"
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/internal/redis"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+// windowSnapshotRetention is how long PPLNS window snapshots are kept in pooldb.
+const windowSnapshotRetention = time.Hour * 24
+
+type WindowSnapshotJob struct {
+	logger *log.Logger
+	redis  *redis.Client
+	pooldb *dbcl.Client
+	nodes  []types.MiningNode
+}
+
+func (j *WindowSnapshotJob) run(r *jobRun) {
+	for _, node := range j.nodes {
+		chain := node.Chain()
+		createdAt := time.Now().UTC()
+		window, err := j.redis.GetRoundShareList(chain)
+		if err != nil {
+			r.Error(fmt.Errorf("snapshot: %s: %v", chain, err))
+			continue
+		} else if len(window) == 0 {
+			// never replace the last snapshot by an empty window (e.g. after a redis loss)
+			continue
+		}
+
+		data, err := journal.EncodeWindow(window)
+		if err != nil {
+			r.Error(fmt.Errorf("snapshot: encode: %s: %v", chain, err))
+			continue
+		}
+
+		snapshot := &pooldb.WindowSnapshot{
+			ChainID:    chain,
+			ShareCount: uint64(len(window)),
+			Data:       data,
+			CreatedAt:  createdAt,
+		}
+
+		_, err = pooldb.InsertWindowSnapshot(j.pooldb.Writer(), snapshot)
+		if err != nil {
+			r.Error(fmt.Errorf("snapshot: insert: %s: %v", chain, err))
+			continue
+		}
+
+		err = pooldb.DeleteWindowSnapshotsBefore(j.pooldb.Writer(), chain, createdAt.Add(-windowSnapshotRetention))
+		if err != nil {
+			r.Error(fmt.Errorf("snapshot: delete: %s: %v", chain, err))
+		}
+	}
+}
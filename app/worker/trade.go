@@ -1,11 +1,7 @@
 package worker
 
 import (
-	"context"
 	"fmt"
-	"time"
-
-	"github.com/bsm/redislock"
 
 	"github.com/magicpool-co/pool/core/trade"
 	"github.com/magicpool-co/pool/internal/log"
@@ -17,7 +13,6 @@ import (
 )
 
 type TradeJob struct {
-	locker    *redislock.Client
 	logger    *log.Logger
 	pooldb    *dbcl.Client
 	redis     *redis.Client
@@ -26,33 +21,21 @@ type TradeJob struct {
 	telegram  *telegram.Client
 }
 
-func (j *TradeJob) Run() {
-	defer j.logger.RecoverPanic()
-
-	ctx := context.Background()
-	lock, err := j.locker.Obtain(ctx, "cron:trade", time.Minute*5, nil)
-	if err != nil {
-		if err != redislock.ErrNotObtained {
-			j.logger.Error(err)
-		}
-		return
-	}
-	defer lock.Release(ctx)
-
+func (j *TradeJob) run(r *jobRun) {
 	client := trade.New(j.pooldb, j.redis, j.nodes, j.exchanges, j.telegram)
 	// if err := client.CheckForNewBatches(); err != nil {
-	// 	j.logger.Error(fmt.Errorf("check: %v", err))
+	// 	r.Error(fmt.Errorf("check: %v", err))
 	// }
 
 	for _, exchangeID := range []types.ExchangeID{types.KucoinID, types.MEXCGlobalID} {
 		batches, err := pooldb.GetActiveExchangeBatches(j.pooldb.Reader(), uint64(exchangeID))
 		if err != nil {
-			j.logger.Error(fmt.Errorf("fetch: %v", err))
+			r.Error(fmt.Errorf("fetch: %v", err))
 		}
 
 		for _, batch := range batches {
 			if err := client.ProcessBatch(batch.ID); err != nil {
-				j.logger.Error(fmt.Errorf("process: %d: %v", batch.ID, err))
+				r.Error(fmt.Errorf("process: %d: %v", batch.ID, err))
 			}
 		}
 	}
@@ -2,16 +2,20 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/bsm/redislock"
 	"github.com/robfig/cron/v3"
 
 	"github.com/magicpool-co/pool/core/mailer"
+	"github.com/magicpool-co/pool/internal/accounting"
 	"github.com/magicpool-co/pool/internal/log"
 	"github.com/magicpool-co/pool/internal/metrics"
 	"github.com/magicpool-co/pool/internal/redis"
 	"github.com/magicpool-co/pool/internal/telegram"
+	"github.com/magicpool-co/pool/internal/tsdb"
 	"github.com/magicpool-co/pool/pkg/aws"
 	"github.com/magicpool-co/pool/pkg/dbcl"
 	"github.com/magicpool-co/pool/types"
@@ -34,15 +38,72 @@ func retrieveLock(
 	return lock, nil
 }
 
+// Options specifies the worker configuration. Schedules overrides the cron spec of a job by
+// name (see defaultSchedules), PoolFeeBasisPoints defaults to accounting.DefaultPoolFeeBasisPoints
+// and ReorgAlertDepths overrides the minimum reorg depth to alert on per chain.
+type Options struct {
+	AutoExclude        bool
+	PoolFeeBasisPoints uint64
+	ReorgAlertDepths   map[string]uint64
+	Schedules          map[string]string
+	// the share rollups, stored in tsdb if nil
+	ShareStore tsdb.ShareStore
+}
+
+// the default cron spec for every job
+var defaultSchedules = map[string]string{
+	"node_status":   "* * * * *",
+	"node_check":    "*/5 * * * *",
+	"node_backup":   "*/5 * * * *",
+	"block_unlock":  "* * * * *",
+	"reorg":         "* * * * *",
+	"audit":         "*/5 * * * *",
+	"share_audit":   "*/15 * * * *",
+	"reserve_proof": "0 * * * *",
+	"miner":         "*/2 * * * *",
+	"miner_notify":  "*/15 * * * *",
+	"trade":         "*/5 * * * *",
+	"payout":        "*/5 * * * *",
+	"bank":          "*/5 * * * *",
+	"chart":         "* * * * *",
+	"snapshot":      "*/10 * * * *",
+}
+
+// alertThresholds are shared with the jobs, since they can be reloaded while the worker is running.
+type alertThresholds struct {
+	mu          sync.RWMutex
+	reorgDepths map[string]uint64
+}
+
+func (t *alertThresholds) getReorgDepths() map[string]uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.reorgDepths
+}
+
+func (t *alertThresholds) setReorgDepths(depths map[string]uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.reorgDepths = depths
+}
+
 type Worker struct {
 	env         string
 	mainnet     bool
+	autoExclude bool
+	poolFee     uint64
+	schedules   map[string]string
+	alerts      *alertThresholds
 	cron        *cron.Cron
+	registry    *registry
 	logger      *log.Logger
 	miningNodes []types.MiningNode
 	payoutNodes []types.PayoutNode
 	pooldb      *dbcl.Client
 	tsdb        *dbcl.Client
+	shares      tsdb.ShareStore
 	redis       *redis.Client
 	exchanges   []types.Exchange
 	aws         *aws.Client
@@ -54,6 +115,7 @@ type Worker struct {
 func NewWorker(
 	env string,
 	mainnet bool,
+	opts *Options,
 	logger *log.Logger,
 	miningNodes []types.MiningNode,
 	payoutNodes []types.PayoutNode,
@@ -74,16 +136,49 @@ func NewWorker(
 		return nil, err
 	}
 
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	poolFee := opts.PoolFeeBasisPoints
+	if poolFee == 0 {
+		poolFee = accounting.DefaultPoolFeeBasisPoints
+	}
+
+	schedules := make(map[string]string, len(defaultSchedules))
+	for name, spec := range defaultSchedules {
+		schedules[name] = spec
+	}
+	for name, spec := range opts.Schedules {
+		if _, ok := schedules[name]; !ok {
+			return nil, fmt.Errorf("unknown job %s", name)
+		}
+		schedules[name] = spec
+	}
+
+	shareStore := opts.ShareStore
+	if shareStore == nil {
+		shareStore = tsdb.NewShareStore(tsdbClient)
+	}
+
+	reg := newRegistry(cronClient, redisClient.NewLocker(), pooldbClient, logger, metricsClient)
+
 	worker := &Worker{
 		env:         env,
 		mainnet:     mainnet,
+		autoExclude: opts.AutoExclude,
+		poolFee:     poolFee,
+		schedules:   schedules,
+		alerts:      &alertThresholds{reorgDepths: opts.ReorgAlertDepths},
 		cron:        cronClient,
+		registry:    reg,
 		logger:      logger,
 		miningNodes: miningNodes,
 		payoutNodes: payoutNodes,
 		redis:       redisClient,
 		pooldb:      pooldbClient,
 		tsdb:        tsdbClient,
+		shares:      shareStore,
 		exchanges:   exchanges,
 		aws:         awsClient,
 		mailer:      mailerClient,
@@ -94,70 +189,104 @@ func NewWorker(
 	return worker, nil
 }
 
-func (w *Worker) Start() {
-	locker := w.redis.NewLocker()
+// SetReorgAlertDepths replaces the minimum reorg depth to alert on per chain, safe to call while running.
+func (w *Worker) SetReorgAlertDepths(depths map[string]uint64) {
+	w.alerts.setReorgDepths(depths)
+}
+
+// jobLock is the redis lock a job runs under, so that only one instance runs
+// the job at a time. jobs with an optional lock run even if it is held elsewhere.
+type jobLock struct {
+	name     string
+	timeout  time.Duration
+	optional bool
+}
 
+func (w *Worker) addJob(name string, lock jobLock, j job) {
+	err := w.registry.add(name, w.schedules[name], lock.name, lock.timeout, lock.optional, j)
+	if err != nil {
+		w.logger.Error(fmt.Errorf("cron: %s: %v", name, err))
+	}
+}
+
+func (w *Worker) Start() {
 	if w.env != "local" {
-		w.cron.AddJob("* * * * *", &NodeStatusJob{
-			locker:  locker,
+		w.addJob("node_status", jobLock{name: "cron:nodestatus", timeout: time.Minute * 5, optional: true}, &NodeStatusJob{
 			logger:  w.logger,
 			nodes:   w.miningNodes,
 			pooldb:  w.pooldb,
 			metrics: w.metrics,
 		})
 
-		// w.cron.AddJob("* * * * *", &NodeInstanceChangeJob{
+		// w.addJob("node_instance_change", jobLock{name: "cron:nodeinstancechange", timeout: time.Minute * 5}, &NodeInstanceChangeJob{
 		// 	env:      w.env,
 		// 	mainnet:  w.mainnet,
-		// 	locker:   locker,
 		// 	logger:   w.logger,
 		// 	aws:      w.aws,
 		// 	telegram: w.telegram,
 		// })
 
-		w.cron.AddJob("*/5 * * * *", &NodeCheckJob{
+		w.addJob("node_check", jobLock{name: "cron:nodecheck", timeout: time.Minute * 5}, &NodeCheckJob{
 			env:     w.env,
 			mainnet: w.mainnet,
-			locker:  locker,
 			logger:  w.logger,
 			aws:     w.aws,
 			pooldb:  w.pooldb,
 		})
 
-		w.cron.AddJob("*/5 * * * *", &NodeBackupJob{
+		w.addJob("node_backup", jobLock{name: "cron:nodebackup", timeout: time.Hour * 4}, &NodeBackupJob{
 			env:     w.env,
 			mainnet: w.mainnet,
-			locker:  locker,
 			logger:  w.logger,
 			aws:     w.aws,
 			pooldb:  w.pooldb,
 		})
 	}
 
-	w.cron.AddJob("* * * * *", &BlockUnlockJob{
-		locker: locker,
+	w.addJob("block_unlock", jobLock{name: "cron:blkunlock", timeout: time.Minute * 5}, &BlockUnlockJob{
+		logger:  w.logger,
+		pooldb:  w.pooldb,
+		nodes:   w.miningNodes,
+		poolFee: w.poolFee,
+	})
+
+	w.addJob("reorg", jobLock{name: "cron:reorg", timeout: time.Minute * 5}, &ReorgJob{
+		logger:   w.logger,
+		pooldb:   w.pooldb,
+		redis:    w.redis,
+		nodes:    w.miningNodes,
+		telegram: w.telegram,
+		alerts:   w.alerts,
+	})
+
+	w.addJob("audit", jobLock{name: "cron:audit", timeout: time.Minute * 5}, &AuditJob{
 		logger: w.logger,
 		pooldb: w.pooldb,
-		nodes:  w.miningNodes,
+		nodes:  w.payoutNodes,
 	})
 
-	w.cron.AddJob("*/5 * * * *", &AuditJob{
-		locker: locker,
+	w.addJob("share_audit", jobLock{name: "cron:shareaudit", timeout: time.Minute * 5}, &ShareAuditJob{
+		logger:      w.logger,
+		pooldb:      w.pooldb,
+		telegram:    w.telegram,
+		nodes:       w.miningNodes,
+		autoExclude: w.autoExclude,
+	})
+
+	w.addJob("reserve_proof", jobLock{name: "cron:reserves", timeout: time.Minute * 30}, &ReserveProofJob{
 		logger: w.logger,
 		pooldb: w.pooldb,
 		nodes:  w.payoutNodes,
 	})
 
-	w.cron.AddJob("*/2 * * * *", &MinerJob{
-		locker: locker,
+	w.addJob("miner", jobLock{name: "cron:miner", timeout: time.Minute * 5}, &MinerJob{
 		logger: w.logger,
 		redis:  w.redis,
 		pooldb: w.pooldb,
 		nodes:  w.miningNodes,
 	})
 
-	w.cron.AddJob("*/15 * * * *", &MinerNotifyJob{
-		locker:   locker,
+	w.addJob("miner_notify", jobLock{name: "cron:minerntfy", timeout: time.Minute * 5}, &MinerNotifyJob{
 		logger:   w.logger,
 		redis:    w.redis,
 		pooldb:   w.pooldb,
@@ -166,8 +295,7 @@ func (w *Worker) Start() {
 		telegram: w.telegram,
 	})
 
-	w.cron.AddJob("*/5 * * * *", &TradeJob{
-		locker:    locker,
+	w.addJob("trade", jobLock{name: "cron:trade", timeout: time.Minute * 5}, &TradeJob{
 		logger:    w.logger,
 		pooldb:    w.pooldb,
 		redis:     w.redis,
@@ -176,8 +304,7 @@ func (w *Worker) Start() {
 		telegram:  w.telegram,
 	})
 
-	w.cron.AddJob("*/5 * * * *", &PayoutJob{
-		locker:   locker,
+	w.addJob("payout", jobLock{name: "cron:payout", timeout: time.Minute * 5}, &PayoutJob{
 		logger:   w.logger,
 		pooldb:   w.pooldb,
 		redis:    w.redis,
@@ -186,8 +313,7 @@ func (w *Worker) Start() {
 		telegram: w.telegram,
 	})
 
-	w.cron.AddJob("*/5 * * * *", &BankJob{
-		locker:   locker,
+	w.addJob("bank", jobLock{name: "cron:bank", timeout: time.Minute * 5}, &BankJob{
 		logger:   w.logger,
 		pooldb:   w.pooldb,
 		redis:    w.redis,
@@ -195,23 +321,29 @@ func (w *Worker) Start() {
 		telegram: w.telegram,
 	})
 
-	w.cron.AddJob("* * * * *", &ChartJob{
-		locker: locker,
+	w.addJob("chart", jobLock{name: "cron:chart", timeout: time.Minute * 30}, &ChartJob{
 		logger: w.logger,
 		redis:  w.redis,
 		pooldb: w.pooldb,
 		tsdb:   w.tsdb,
+		shares: w.shares,
 		nodes:  w.miningNodes,
 	})
 
+	w.addJob("snapshot", jobLock{name: "cron:snapshot", timeout: time.Minute * 5}, &WindowSnapshotJob{
+		logger: w.logger,
+		redis:  w.redis,
+		pooldb: w.pooldb,
+		nodes:  w.miningNodes,
+	})
+
+	w.registry.start()
 	w.cron.Start()
 }
 
 func (w *Worker) Stop() {
 	ctx := w.cron.Stop()
+	<-ctx.Done()
 
-	select {
-	case <-ctx.Done():
-		return
-	}
+	w.registry.wait()
 }
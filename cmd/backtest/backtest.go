@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/accounting"
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/internal/tsdb"
+	"github.com/magicpool-co/pool/pkg/common"
+)
+
+type scenario struct {
+	actual     bool
+	windowSize uint64
+	decay      float64
+	feeBps     uint64
+}
+
+func (s scenario) String() string {
+	if s.actual {
+		return "actual"
+	}
+
+	return fmt.Sprintf("window=%d,decay=%g,fee=%d", s.windowSize, s.decay, s.feeBps)
+}
+
+// interval is a single tsdb share period with the accepted shares for each miner.
+type interval struct {
+	endTime time.Time
+	shares  map[uint64]uint64
+}
+
+type backtestRound struct {
+	round     *pooldb.Round
+	startTime time.Time
+	shares    map[uint64]uint64
+}
+
+type minerResult struct {
+	minerID  uint64
+	shares   uint64
+	values   []float64
+	total    *big.Int
+	baseline *big.Int
+	poolFees *big.Int
+}
+
+type scenarioResult struct {
+	scenario scenario
+	rounds   int
+	skipped  int
+	total    *big.Int
+	poolFees *big.Int
+	hopSum   float64
+	miners   map[uint64]*minerResult
+}
+
+func newScenarioResult(s scenario) *scenarioResult {
+	result := &scenarioResult{
+		scenario: s,
+		total:    new(big.Int),
+		poolFees: new(big.Int),
+		miners:   make(map[uint64]*minerResult),
+	}
+
+	return result
+}
+
+func (r *scenarioResult) getMiner(minerID uint64) *minerResult {
+	if _, ok := r.miners[minerID]; !ok {
+		r.miners[minerID] = &minerResult{
+			minerID:  minerID,
+			total:    new(big.Int),
+			baseline: new(big.Int),
+			poolFees: new(big.Int),
+		}
+	}
+
+	return r.miners[minerID]
+}
+
+func buildIntervals(dbShares []*tsdb.Share) []*interval {
+	intervalIdx := make(map[int64]*interval)
+	for _, dbShare := range dbShares {
+		if dbShare.MinerID == nil || dbShare.AcceptedShares == 0 {
+			continue
+		}
+
+		key := dbShare.EndTime.Unix()
+		if _, ok := intervalIdx[key]; !ok {
+			intervalIdx[key] = &interval{
+				endTime: dbShare.EndTime,
+				shares:  make(map[uint64]uint64),
+			}
+		}
+		intervalIdx[key].shares[*dbShare.MinerID] += dbShare.AcceptedShares
+	}
+
+	intervals := make([]*interval, 0, len(intervalIdx))
+	for _, item := range intervalIdx {
+		intervals = append(intervals, item)
+	}
+
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].endTime.Before(intervals[j].endTime)
+	})
+
+	return intervals
+}
+
+// buildWindow walks back from the round's timestamp through the share intervals until the window
+// is filled, weighting each interval by decay^n (n being the number of intervals back). the last
+// interval is only partially counted if it overfills the window. it also returns the portion of
+// the weights that came from shares submitted during the round itself, used as a hopping metric.
+func buildWindow(
+	intervals []*interval,
+	round *backtestRound,
+	windowSize uint64,
+	decay float64,
+) (map[uint64]uint64, float64) {
+	end := sort.Search(len(intervals), func(i int) bool {
+		return intervals[i].endTime.After(round.round.CreatedAt)
+	})
+
+	weights := make(map[uint64]float64)
+	var used uint64
+	var totalWeight, roundWeight float64
+	factor := 1.0
+	for i := end - 1; i >= 0 && used < windowSize; i-- {
+		var intervalShares uint64
+		for _, count := range intervals[i].shares {
+			intervalShares += count
+		}
+		if intervalShares == 0 {
+			continue
+		}
+
+		portion := 1.0
+		if used+intervalShares > windowSize {
+			portion = float64(windowSize-used) / float64(intervalShares)
+			used = windowSize
+		} else {
+			used += intervalShares
+		}
+
+		for minerID, count := range intervals[i].shares {
+			weight := float64(count) * portion * factor
+			weights[minerID] += weight
+			totalWeight += weight
+			if intervals[i].endTime.After(round.startTime) {
+				roundWeight += weight
+			}
+		}
+
+		factor *= decay
+	}
+
+	minerIdx := make(map[uint64]uint64)
+	for minerID, weight := range weights {
+		if count := uint64(math.Round(weight)); count > 0 {
+			minerIdx[minerID] = count
+		}
+	}
+
+	var hop float64
+	if totalWeight > 0 {
+		hop = roundWeight / totalWeight
+	}
+
+	return minerIdx, hop
+}
+
+func runScenario(
+	chain string,
+	s scenario,
+	rounds []*backtestRound,
+	intervals []*interval,
+	recipientIdx map[uint64]uint64,
+	baseline *scenarioResult,
+) (*scenarioResult, error) {
+	units, err := common.GetDefaultUnits(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newScenarioResult(s)
+	for _, round := range rounds {
+		minerIdx, hop := round.shares, 0.0
+		if !s.actual {
+			minerIdx, hop = buildWindow(intervals, round, s.windowSize, s.decay)
+		}
+
+		if len(minerIdx) == 0 {
+			result.skipped++
+			continue
+		}
+
+		values, fees, err := accounting.CreditRoundWithFee(round.round.Value.BigInt, s.feeBps, minerIdx, recipientIdx)
+		if err != nil {
+			return nil, fmt.Errorf("round %d: %v", round.round.ID, err)
+		}
+
+		result.rounds++
+		result.hopSum += hop
+		for minerID, value := range values {
+			if _, ok := recipientIdx[minerID]; ok {
+				if _, ok := minerIdx[minerID]; !ok {
+					result.poolFees.Add(result.poolFees, value)
+					continue
+				}
+			}
+
+			miner := result.getMiner(minerID)
+			miner.total.Add(miner.total, value)
+			miner.values = append(miner.values, common.BigIntToFloat64(value, units))
+			result.total.Add(result.total, value)
+		}
+
+		for minerID, fee := range fees {
+			miner := result.getMiner(minerID)
+			miner.poolFees.Add(miner.poolFees, fee)
+		}
+	}
+
+	if baseline != nil {
+		for minerID, baselineMiner := range baseline.miners {
+			miner := result.getMiner(minerID)
+			miner.baseline.Set(baselineMiner.total)
+		}
+	}
+
+	for _, item := range intervals {
+		for minerID, count := range item.shares {
+			if miner, ok := result.miners[minerID]; ok {
+				miner.shares += count
+			}
+		}
+	}
+
+	return result, nil
+}
+
+/* metrics */
+
+// minerVariance returns the mean and standard deviation of a miner's per round
+// rewards, counting the rounds the miner wasn't credited in as zero.
+func minerVariance(values []float64, rounds int) (float64, float64) {
+	if rounds == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(rounds)
+
+	var variance float64
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+	variance += float64(rounds-len(values)) * mean * mean
+	variance /= float64(rounds)
+
+	return mean, math.Sqrt(variance)
+}
+
+// minerEfficiency returns the miner's share of the rewards relative to its share of the submitted
+// shares. a miner consistently above 1 is being rewarded more than its work, which is what
+// a pool hopper would see under a scheme with poor hop resistance.
+func minerEfficiency(miner *minerResult, result *scenarioResult, totalShares uint64) float64 {
+	if totalShares == 0 || miner.shares == 0 || result.total.Cmp(common.Big0) == 0 {
+		return 0
+	}
+
+	rewardRatio, _ := new(big.Float).Quo(new(big.Float).SetInt(miner.total), new(big.Float).SetInt(result.total)).Float64()
+	shareRatio := float64(miner.shares) / float64(totalShares)
+
+	return rewardRatio / shareRatio
+}
+
+// distributionDifference returns the value moved between miners compared to the baseline,
+// which is half of the sum of the absolute differences.
+func distributionDifference(result *scenarioResult) *big.Int {
+	moved := new(big.Int)
+	for _, miner := range result.miners {
+		diff := new(big.Int).Sub(miner.total, miner.baseline)
+		moved.Add(moved, diff.Abs(diff))
+	}
+
+	return moved.Div(moved, big.NewInt(2))
+}
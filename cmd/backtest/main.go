@@ -0,0 +1,203 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/accounting"
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/internal/tsdb"
+	"github.com/magicpool-co/pool/svc"
+	"github.com/magicpool-co/pool/types"
+)
+
+func parseUint64List(raw string) ([]uint64, error) {
+	values := make([]uint64, 0)
+	for _, part := range strings.Split(raw, ",") {
+		value, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func parseFloat64List(raw string) ([]float64, error) {
+	values := make([]float64, 0)
+	for _, part := range strings.Split(raw, ",") {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func main() {
+	argChain := flag.String("chain", "ETC", "The chain to backtest")
+	argStart := flag.String("start", "", "The start date (YYYY-MM-DD)")
+	argEnd := flag.String("end", "", "The end date (YYYY-MM-DD), defaults to now")
+	argLookback := flag.Duration("lookback", time.Hour*24, "How far before the start to load shares to fill the window")
+	argWindows := flag.String("windows", "100000", "Comma separated PPLNS window sizes")
+	argDecays := flag.String("decays", "1", "Comma separated per interval decay factors (1 is no decay)")
+	argFees := flag.String("fees", strconv.Itoa(accounting.DefaultPoolFeeBasisPoints), "Comma separated pool fees (in bps)")
+	argReport := flag.String("report", "summary", "The report to generate (summary, miners)")
+	argOut := flag.String("out", "", "The output file, defaults to stdout")
+
+	flag.Parse()
+
+	chain := strings.ToUpper(*argChain)
+	start, err := time.Parse("2006-01-02", *argStart)
+	if err != nil {
+		log.Fatalf("start: %v", err)
+	}
+
+	end := time.Now()
+	if *argEnd != "" {
+		end, err = time.Parse("2006-01-02", *argEnd)
+		if err != nil {
+			log.Fatalf("end: %v", err)
+		}
+	}
+
+	windows, err := parseUint64List(*argWindows)
+	if err != nil {
+		log.Fatalf("windows: %v", err)
+	}
+
+	decays, err := parseFloat64List(*argDecays)
+	if err != nil {
+		log.Fatalf("decays: %v", err)
+	}
+
+	fees, err := parseUint64List(*argFees)
+	if err != nil {
+		log.Fatalf("fees: %v", err)
+	}
+
+	secrets, err := svc.ParseSecrets("")
+	if err != nil {
+		log.Fatalf("failed to fetch secrets: %v", err)
+	}
+
+	pooldbClient, err := pooldb.New(secrets)
+	if err != nil {
+		log.Fatalf("pooldb: %v", err)
+	}
+
+	tsdbClient, err := tsdb.New(secrets)
+	if err != nil {
+		log.Fatalf("tsdb: %v", err)
+	}
+
+	dbRounds, err := pooldb.GetRoundsByChainAndTimeRange(pooldbClient.Reader(), chain, start.Add(-*argLookback), end)
+	if err != nil {
+		log.Fatalf("rounds: %v", err)
+	}
+
+	dbShares, err := tsdb.GetMinerSharesByTimeRange(tsdbClient.Reader(), chain,
+		int(types.Period15m), start.Add(-*argLookback), end)
+	if err != nil {
+		log.Fatalf("shares: %v", err)
+	}
+
+	dbRecipients, err := pooldb.GetRecipients(pooldbClient.Reader())
+	if err != nil {
+		log.Fatalf("recipients: %v", err)
+	}
+
+	recipientIdx := make(map[uint64]uint64)
+	for _, dbRecipient := range dbRecipients {
+		recipientIdx[dbRecipient.ID] += types.Uint64Value(dbRecipient.RecipientFeePercent)
+	}
+
+	// rounds from the lookback are only used to find the start of the first round
+	rounds := make([]*backtestRound, 0)
+	startTime := start.Add(-*argLookback)
+	for _, dbRound := range dbRounds {
+		if dbRound.CreatedAt.Before(start) {
+			startTime = dbRound.CreatedAt
+			continue
+		}
+
+		dbRoundShares, err := pooldb.GetSharesByRound(pooldbClient.Reader(), dbRound.ID)
+		if err != nil {
+			log.Fatalf("round shares: %v", err)
+		}
+
+		shareIdx := make(map[uint64]uint64)
+		for _, dbShare := range dbRoundShares {
+			shareIdx[dbShare.MinerID] += dbShare.Count
+		}
+
+		rounds = append(rounds, &backtestRound{
+			round:     dbRound,
+			startTime: startTime,
+			shares:    shareIdx,
+		})
+		startTime = dbRound.CreatedAt
+	}
+
+	intervals := buildIntervals(dbShares)
+	var totalShares uint64
+	for _, item := range intervals {
+		for _, count := range item.shares {
+			totalShares += count
+		}
+	}
+
+	baseline, err := runScenario(chain, scenario{actual: true, feeBps: accounting.DefaultPoolFeeBasisPoints},
+		rounds, intervals, recipientIdx, nil)
+	if err != nil {
+		log.Fatalf("baseline: %v", err)
+	}
+	for _, miner := range baseline.miners {
+		miner.baseline.Set(miner.total)
+	}
+
+	results := []*scenarioResult{baseline}
+	for _, windowSize := range windows {
+		for _, decay := range decays {
+			for _, feeBps := range fees {
+				s := scenario{windowSize: windowSize, decay: decay, feeBps: feeBps}
+				result, err := runScenario(chain, s, rounds, intervals, recipientIdx, baseline)
+				if err != nil {
+					log.Fatalf("scenario %s: %v", s, err)
+				}
+				results = append(results, result)
+			}
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *argOut != "" {
+		file, err := os.Create(*argOut)
+		if err != nil {
+			log.Fatalf("output: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch *argReport {
+	case "summary":
+		err = writeSummaryReport(out, chain, results, totalShares)
+	case "miners":
+		err = writeMinerReport(out, chain, results, totalShares)
+	default:
+		log.Fatalf("report: unsupported report %s", *argReport)
+	}
+
+	if err != nil {
+		log.Fatalf("report: %v", err)
+	}
+}
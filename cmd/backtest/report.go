@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/magicpool-co/pool/pkg/common"
+)
+
+func formatFloat64(value float64) string {
+	return strconv.FormatFloat(value, 'f', 8, 64)
+}
+
+func writeSummaryReport(
+	w io.Writer,
+	chain string,
+	results []*scenarioResult,
+	totalShares uint64,
+) error {
+	units, err := common.GetDefaultUnits(chain)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	err = writer.Write([]string{
+		"Scenario",
+		"Window Size",
+		"Decay",
+		"Fee (bps)",
+		"Rounds",
+		"Skipped Rounds",
+		"Miners",
+		"Miner Value",
+		"Pool Fees",
+		"Moved Value",
+		"Moved Percentage",
+		"Avg In-Round Weight",
+		"Avg Miner CV",
+		"Max Miner Efficiency",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		var cvSum, maxEfficiency float64
+		var cvCount int
+		for _, miner := range result.miners {
+			mean, stddev := minerVariance(miner.values, result.rounds)
+			if mean > 0 {
+				cvSum += stddev / mean
+				cvCount++
+			}
+
+			if efficiency := minerEfficiency(miner, result, totalShares); efficiency > maxEfficiency {
+				maxEfficiency = efficiency
+			}
+		}
+
+		var avgCV, avgHop float64
+		if cvCount > 0 {
+			avgCV = cvSum / float64(cvCount)
+		}
+		if result.rounds > 0 {
+			avgHop = result.hopSum / float64(result.rounds)
+		}
+
+		moved := distributionDifference(result)
+		var movedPercentage float64
+		if result.total.Cmp(common.Big0) > 0 {
+			movedPercentage = 100 * common.BigIntToFloat64(moved, units) / common.BigIntToFloat64(result.total, units)
+		}
+
+		// the actual distribution has no interval data attached, so
+		// the in-round weight can only be calculated for the scenarios
+		var windowSize, decay, hop string
+		if !result.scenario.actual {
+			windowSize = strconv.FormatUint(result.scenario.windowSize, 10)
+			decay = formatFloat64(result.scenario.decay)
+			hop = formatFloat64(avgHop)
+		}
+
+		err = writer.Write([]string{
+			result.scenario.String(),
+			windowSize,
+			decay,
+			strconv.FormatUint(result.scenario.feeBps, 10),
+			strconv.Itoa(result.rounds),
+			strconv.Itoa(result.skipped),
+			strconv.Itoa(cvCount),
+			formatFloat64(common.BigIntToFloat64(result.total, units)),
+			formatFloat64(common.BigIntToFloat64(result.poolFees, units)),
+			formatFloat64(common.BigIntToFloat64(moved, units)),
+			formatFloat64(movedPercentage),
+			hop,
+			formatFloat64(avgCV),
+			formatFloat64(maxEfficiency),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func writeMinerReport(
+	w io.Writer,
+	chain string,
+	results []*scenarioResult,
+	totalShares uint64,
+) error {
+	units, err := common.GetDefaultUnits(chain)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	err = writer.Write([]string{
+		"Scenario",
+		"Miner ID",
+		"Shares",
+		"Rounds Credited",
+		"Value",
+		"Baseline Value",
+		"Difference",
+		"Pool Fees",
+		"Mean",
+		"Std Dev",
+		"CV",
+		"Efficiency",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		minerIDs := make([]uint64, 0, len(result.miners))
+		for minerID := range result.miners {
+			minerIDs = append(minerIDs, minerID)
+		}
+		sort.Slice(minerIDs, func(i, j int) bool { return minerIDs[i] < minerIDs[j] })
+
+		for _, minerID := range minerIDs {
+			miner := result.miners[minerID]
+			mean, stddev := minerVariance(miner.values, result.rounds)
+			var cv float64
+			if mean > 0 {
+				cv = stddev / mean
+			}
+
+			value := common.BigIntToFloat64(miner.total, units)
+			baseline := common.BigIntToFloat64(miner.baseline, units)
+			err = writer.Write([]string{
+				result.scenario.String(),
+				strconv.FormatUint(minerID, 10),
+				strconv.FormatUint(miner.shares, 10),
+				strconv.Itoa(len(miner.values)),
+				formatFloat64(value),
+				formatFloat64(baseline),
+				formatFloat64(value - baseline),
+				formatFloat64(common.BigIntToFloat64(miner.poolFees, units)),
+				formatFloat64(mean),
+				formatFloat64(stddev),
+				formatFloat64(cv),
+				formatFloat64(minerEfficiency(miner, result, totalShares)),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
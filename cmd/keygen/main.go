@@ -35,7 +35,7 @@ func main() {
 	var rawPriv []byte
 	var err error
 	switch chain {
-	case "BTC", "ERG", "FIRO", "FLUX", "KAS", "NEXA", "RVN":
+	case "BCH", "BTC", "ERG", "FIRO", "FLUX", "KAS", "KLS", "NEXA", "RVN", "ZEN":
 		rawPriv, err = generateSecp256k1Priv(*argObscure)
 	case "BSC", "ETC", "ETH":
 		rawPriv, err = generateSecp256k1Priv(*argObscure)
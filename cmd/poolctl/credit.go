@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/magicpool-co/pool/core/credit"
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+)
+
+type creditKey struct {
+	minerID    uint64
+	outChainID string
+}
+
+// sumBalanceInputs sums the balance inputs of a round by miner and output chain.
+func sumBalanceInputs(q dbcl.Querier, roundID uint64) (map[creditKey]*big.Int, error) {
+	balanceInputs, err := pooldb.GetBalanceInputsByRound(q, roundID)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[creditKey]*big.Int)
+	for _, balanceInput := range balanceInputs {
+		key := creditKey{balanceInput.MinerID, balanceInput.OutChainID}
+		if _, ok := sums[key]; !ok {
+			sums[key] = new(big.Int)
+		}
+		sums[key].Add(sums[key], balanceInput.Value.BigInt)
+	}
+
+	return sums, nil
+}
+
+func printCreditDiff(before, after map[creditKey]*big.Int) int {
+	keys := make([]creditKey, 0)
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].minerID != keys[j].minerID {
+			return keys[i].minerID < keys[j].minerID
+		}
+		return keys[i].outChainID < keys[j].outChainID
+	})
+
+	var changes int
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MINER\tOUT CHAIN\tBEFORE\tAFTER\tDIFF")
+	for _, key := range keys {
+		beforeValue, afterValue := new(big.Int), new(big.Int)
+		if value, ok := before[key]; ok {
+			beforeValue = value
+		}
+		if value, ok := after[key]; ok {
+			afterValue = value
+		}
+
+		diff := new(big.Int).Sub(afterValue, beforeValue)
+		if diff.Sign() != 0 {
+			changes++
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", key.minerID, key.outChainID, beforeValue, afterValue, diff)
+	}
+	w.Flush()
+
+	return changes
+}
+
+func runCredit(env *environment, args []string) {
+	fs := newFlagSet("credit")
+	argRound := fs.Uint64("round", 0, "The round to re-credit")
+	argYes := fs.Bool("yes", false, "Commit without asking for confirmation")
+	fs.Parse(args)
+
+	cfg := env.config()
+	pooldbClient := env.pooldb()
+
+	round, err := pooldb.GetRound(pooldbClient.Reader(), *argRound)
+	if err != nil {
+		log.Fatalf("credit: round: %v", err)
+	} else if round == nil {
+		log.Fatalf("credit: round %d not found", *argRound)
+	}
+
+	shares, err := pooldb.GetSharesByRound(pooldbClient.Reader(), round.ID)
+	if err != nil {
+		log.Fatalf("credit: shares: %v", err)
+	}
+
+	tx, err := pooldbClient.Begin()
+	if err != nil {
+		log.Fatalf("credit: tx: %v", err)
+	}
+	defer tx.SafeRollback()
+
+	before, err := sumBalanceInputs(tx, round.ID)
+	if err != nil {
+		log.Fatalf("credit: balance inputs: %v", err)
+	}
+
+	err = credit.RecreditRound(pooldb.NewTxStore(tx), round, shares, cfg.Fees.PoolFeeBasisPoints)
+	if err != nil {
+		log.Fatalf("credit: %v", err)
+	}
+
+	after, err := sumBalanceInputs(tx, round.ID)
+	if err != nil {
+		log.Fatalf("credit: balance inputs: %v", err)
+	}
+
+	fmt.Printf("round %d (%s %d)\n", round.ID, round.ChainID, round.Height)
+	changes := printCreditDiff(before, after)
+	if changes == 0 {
+		fmt.Println("no changes")
+		return
+	} else if dryRun {
+		fmt.Printf("dry run: %d changes rolled back\n", changes)
+		return
+	} else if !confirm(fmt.Sprintf("commit %d changes?", changes), *argYes) {
+		fmt.Println("rolled back")
+		return
+	}
+
+	err = tx.SafeCommit()
+	if err != nil {
+		log.Fatalf("credit: commit: %v", err)
+	}
+	fmt.Println("committed")
+}
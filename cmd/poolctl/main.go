@@ -0,0 +1,140 @@
+// poolctl runs maintenance operations against the pool databases. every command
+// that writes supports -dry-run, which prints the changes without applying them.
+//
+//	poolctl [-secret VAR] [-config FILE] <command> [flags]
+//
+//	migrate status|plan|up|down     shows, plans or applies the migrations (-db pooldb|tsdb)
+//	migrate baseline -version N     marks the migrations up to N as applied on an existing database
+//	credit                          re-credits a round and shows a diff before committing
+//	recompute-sums                  recomputes balance_sums from the balance inputs and outputs
+//	check-wallet                    replays the wallet check against a given wallet balance
+//	pplns                           inspects the redis PPLNS window
+//	rebuild-shares                  rebuilds the redis PPLNS window from the share journals
+//	export-settings                 exports the miner settings of a chain as JSON
+//	import-settings                 imports miner settings exported by export-settings
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/magicpool-co/pool/internal/config"
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/internal/redis"
+	"github.com/magicpool-co/pool/internal/tsdb"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/svc"
+)
+
+var dryRun bool
+
+// newFlagSet returns the flag set for a command, every command accepts -dry-run
+// both before and after the command name.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.BoolVar(&dryRun, "dry-run", dryRun, "Print the changes without applying them")
+
+	return fs
+}
+
+// confirm asks for confirmation on stdin, skipped if yes is set.
+func confirm(msg string, yes bool) bool {
+	if yes {
+		return true
+	}
+
+	fmt.Printf("%s [y/N]: ", msg)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	return answer == "y" || answer == "yes"
+}
+
+type environment struct {
+	secrets    map[string]string
+	configPath string
+}
+
+func (e *environment) config() *config.Config {
+	cfg, err := svc.LoadConfig(e.configPath, false, e.secrets)
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	return cfg
+}
+
+func (e *environment) pooldb() *dbcl.Client {
+	client, err := pooldb.New(e.secrets)
+	if err != nil {
+		log.Fatalf("pooldb: %v", err)
+	}
+
+	return client
+}
+
+func (e *environment) tsdb() *dbcl.Client {
+	client, err := tsdb.New(e.secrets)
+	if err != nil {
+		log.Fatalf("tsdb: %v", err)
+	}
+
+	return client
+}
+
+func (e *environment) redis() *redis.Client {
+	client, err := redis.New(e.secrets)
+	if err != nil {
+		log.Fatalf("redis: %v", err)
+	}
+
+	return client
+}
+
+func main() {
+	argSecretVar := flag.String("secret", "", "ENV variable defined by ECS")
+	argConfig := flag.String("config", "", "The config file to use (the default config if empty)")
+	flag.BoolVar(&dryRun, "dry-run", false, "Print the changes without applying them")
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		log.Fatalf("no command given")
+	}
+
+	secrets, err := svc.ParseSecrets(*argSecretVar)
+	if err != nil {
+		log.Fatalf("failed to fetch secrets: %v", err)
+	}
+
+	env := &environment{
+		secrets:    secrets,
+		configPath: *argConfig,
+	}
+
+	command, args := flag.Arg(0), flag.Args()[1:]
+	switch command {
+	case "migrate":
+		runMigrate(env, args)
+	case "credit":
+		runCredit(env, args)
+	case "recompute-sums":
+		runRecomputeSums(env, args)
+	case "check-wallet":
+		runCheckWallet(env, args)
+	case "pplns":
+		runPPLNS(env, args)
+	case "rebuild-shares":
+		runRebuildShares(env, args)
+	case "export-settings":
+		runExportSettings(env, args)
+	case "import-settings":
+		runImportSettings(env, args)
+	default:
+		log.Fatalf("unknown command %s", command)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+func printMigrationStep(step *dbcl.MigrationStep) {
+	fmt.Printf("-- %s (%d statements", step.Name, len(step.Statements))
+	if step.Resume > 0 {
+		fmt.Printf(", resuming at %d", step.Resume+1)
+	}
+	fmt.Println(")")
+
+	for _, statement := range step.Pending() {
+		fmt.Printf("%s;\n", statement.SQL())
+	}
+}
+
+func runMigrate(env *environment, args []string) {
+	if len(args) == 0 {
+		log.Fatalf("migrate: no action given (status, plan, up, down, baseline)")
+	}
+
+	action := args[0]
+	fs := newFlagSet("migrate")
+	argDB := fs.String("db", "pooldb", "The database to migrate (pooldb, tsdb)")
+	argVersion := fs.Int("version", -1, "The version to baseline the database at")
+	fs.Parse(args[1:])
+
+	var client *dbcl.Client
+	switch *argDB {
+	case "pooldb":
+		client = env.pooldb()
+	case "tsdb":
+		client = env.tsdb()
+	default:
+		log.Fatalf("migrate: unknown database %s", *argDB)
+	}
+
+	switch action {
+	case "status":
+		records, err := client.Migrations()
+		if err != nil {
+			log.Fatalf("migrate: status: %v", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MIGRATION\tCHECKSUM\tSTATEMENTS\tSTATE\tAPPLIED AT\tERROR")
+		for _, record := range records {
+			state := "applied"
+			if record.Dirty {
+				state = "dirty"
+			} else if record.Baseline {
+				state = "baseline"
+			}
+
+			fmt.Fprintf(w, "%s\t%.12s\t%d/%d\t%s\t%s\t%s\n", record.Name, record.Checksum,
+				record.AppliedStatements, record.Statements, state,
+				record.AppliedAt.Format("2006-01-02 15:04:05"), types.StringValue(record.ErrorMessage))
+		}
+		w.Flush()
+
+		steps, err := client.Plan()
+		if err != nil {
+			log.Fatalf("migrate: plan: %v", err)
+		}
+
+		fmt.Printf("%s: %d pending\n", *argDB, len(steps))
+		for _, step := range steps {
+			fmt.Printf("  pending: %s\n", step.Name)
+		}
+
+	case "plan", "dry-run", "up":
+		steps, err := client.Plan()
+		if err != nil {
+			log.Fatalf("migrate: plan: %v", err)
+		} else if len(steps) == 0 {
+			fmt.Printf("%s: up to date\n", *argDB)
+			return
+		} else if dryRun || action != "up" {
+			for _, step := range steps {
+				printMigrationStep(step)
+			}
+			return
+		}
+
+		err = client.UpgradeMigrations()
+		if err != nil {
+			log.Fatalf("migrate: up: %v", err)
+		}
+		fmt.Printf("%s: applied %d migrations\n", *argDB, len(steps))
+
+	case "down":
+		step, err := client.DowngradePlan()
+		if err != nil {
+			log.Fatalf("migrate: plan: %v", err)
+		} else if step == nil {
+			fmt.Printf("%s: no migrations applied\n", *argDB)
+			return
+		} else if dryRun {
+			printMigrationStep(step)
+			return
+		}
+
+		err = client.DowngradeMigration()
+		if err != nil {
+			log.Fatalf("migrate: down: %v", err)
+		}
+		fmt.Printf("%s: reverted %s\n", *argDB, step.Name)
+
+	case "baseline":
+		if *argVersion < 0 {
+			log.Fatalf("migrate: baseline: no version given")
+		} else if dryRun {
+			fmt.Printf("dry run: would baseline %s at %03d\n", *argDB, *argVersion)
+			return
+		}
+
+		err := client.Baseline(*argVersion)
+		if err != nil {
+			log.Fatalf("migrate: baseline: %v", err)
+		}
+		fmt.Printf("%s: baselined at %03d\n", *argDB, *argVersion)
+
+	default:
+		log.Fatalf("migrate: unknown action %s", action)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+)
+
+// runPPLNS prints the current PPLNS window of a chain by miner. it only reads,
+// so -dry-run has no effect.
+func runPPLNS(env *environment, args []string) {
+	fs := newFlagSet("pplns")
+	argChain := fs.String("chain", "", "The chain to inspect")
+	argTop := fs.Int("top", 25, "The number of miners to print (0 for all)")
+	fs.Parse(args)
+
+	chain := strings.ToUpper(*argChain)
+	if len(chain) == 0 {
+		log.Fatalf("pplns: no chain given")
+	}
+
+	shares, err := env.redis().GetRoundShares(chain)
+	if err != nil {
+		log.Fatalf("pplns: %v", err)
+	} else if len(shares) == 0 {
+		fmt.Printf("%s: empty window\n", chain)
+		return
+	}
+
+	var total uint64
+	minerIDs := make([]uint64, 0, len(shares))
+	for minerID, count := range shares {
+		minerIDs = append(minerIDs, minerID)
+		total += count
+	}
+
+	sort.Slice(minerIDs, func(i, j int) bool {
+		if shares[minerIDs[i]] != shares[minerIDs[j]] {
+			return shares[minerIDs[i]] > shares[minerIDs[j]]
+		}
+		return minerIDs[i] < minerIDs[j]
+	})
+
+	if *argTop > 0 && len(minerIDs) > *argTop {
+		minerIDs = minerIDs[:*argTop]
+	}
+
+	pooldbClient := env.pooldb()
+	miners, err := pooldb.GetMiners(pooldbClient.Reader(), minerIDs)
+	if err != nil {
+		log.Fatalf("pplns: miners: %v", err)
+	}
+
+	addressIdx := make(map[uint64]string)
+	for _, miner := range miners {
+		addressIdx[miner.ID] = miner.ChainID + ":" + miner.Address
+	}
+
+	excludedAudits, err := pooldb.GetExcludedMinerShareAuditsByChain(pooldbClient.Reader(), chain)
+	if err != nil {
+		log.Fatalf("pplns: excluded miners: %v", err)
+	}
+
+	excludedIdx := make(map[uint64]bool)
+	for _, excludedAudit := range excludedAudits {
+		excludedIdx[excludedAudit.MinerID] = true
+	}
+
+	fmt.Printf("%s: %d shares from %d miners\n", chain, total, len(shares))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MINER\tADDRESS\tSHARES\tPERCENT\tEXCLUDED")
+	for _, minerID := range minerIDs {
+		count := shares[minerID]
+		percent := float64(count) / float64(total) * 100
+		fmt.Fprintf(w, "%d\t%s\t%d\t%.2f%%\t%t\n", minerID, addressIdx[minerID], count, percent, excludedIdx[minerID])
+	}
+	w.Flush()
+}
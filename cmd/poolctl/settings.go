@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/common"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+var minerSettingsCols = []string{
+	"email", "threshold", "payout_schedule", "payout_hour", "payout_weekday",
+	"payout_time", "enabled_worker_notifications", "enabled_payout_notifications",
+}
+
+// minerSettings are the user editable settings of a miner, miners
+// are matched on chain and address since ids differ across databases.
+type minerSettings struct {
+	Chain   string  `json:"chain"`
+	Address string  `json:"address"`
+	Email   *string `json:"email"`
+	// the threshold is in base units
+	Threshold                  *string    `json:"threshold"`
+	PayoutSchedule             int        `json:"payoutSchedule"`
+	PayoutHour                 int        `json:"payoutHour"`
+	PayoutWeekday              int        `json:"payoutWeekday"`
+	PayoutTime                 *time.Time `json:"payoutTime"`
+	EnabledWorkerNotifications bool       `json:"enabledWorkerNotifications"`
+	EnabledPayoutNotifications bool       `json:"enabledPayoutNotifications"`
+}
+
+func (s *minerSettings) apply(miner *pooldb.Miner) error {
+	if s.Email != nil {
+		_, err := mail.ParseAddress(types.StringValue(s.Email))
+		if err != nil {
+			return fmt.Errorf("invalid email")
+		}
+	}
+
+	threshold := dbcl.NullBigInt{}
+	if s.Threshold != nil {
+		value, ok := new(big.Int).SetString(types.StringValue(s.Threshold), 10)
+		if !ok {
+			return fmt.Errorf("invalid threshold")
+		}
+
+		payoutBound, err := common.GetDefaultPayoutBounds(miner.ChainID)
+		if err != nil {
+			return err
+		} else if value.Cmp(payoutBound.Min) < 0 || value.Cmp(payoutBound.Max) > 0 {
+			return fmt.Errorf("threshold out of bounds")
+		}
+		threshold = dbcl.NullBigInt{Valid: true, BigInt: value}
+	}
+
+	if s.PayoutHour < 0 || s.PayoutHour > 23 {
+		return fmt.Errorf("invalid payout hour")
+	} else if s.PayoutWeekday < 0 || s.PayoutWeekday > 6 {
+		return fmt.Errorf("invalid payout weekday")
+	} else if types.PayoutSchedule(s.PayoutSchedule) == types.PayoutFixed && s.PayoutTime == nil {
+		return fmt.Errorf("no payout time for fixed payout schedule")
+	}
+
+	miner.Email = s.Email
+	miner.Threshold = threshold
+	miner.PayoutSchedule = s.PayoutSchedule
+	miner.PayoutHour = s.PayoutHour
+	miner.PayoutWeekday = s.PayoutWeekday
+	miner.PayoutTime = s.PayoutTime
+	miner.EnabledWorkerNotifications = s.EnabledWorkerNotifications
+	miner.EnabledPayoutNotifications = s.EnabledPayoutNotifications
+
+	return nil
+}
+
+func newMinerSettings(miner *pooldb.Miner) *minerSettings {
+	settings := &minerSettings{
+		Chain:                      miner.ChainID,
+		Address:                    miner.Address,
+		Email:                      miner.Email,
+		PayoutSchedule:             miner.PayoutSchedule,
+		PayoutHour:                 miner.PayoutHour,
+		PayoutWeekday:              miner.PayoutWeekday,
+		PayoutTime:                 miner.PayoutTime,
+		EnabledWorkerNotifications: miner.EnabledWorkerNotifications,
+		EnabledPayoutNotifications: miner.EnabledPayoutNotifications,
+	}
+
+	if miner.Threshold.Valid && miner.Threshold.BigInt != nil {
+		settings.Threshold = types.StringPtr(miner.Threshold.BigInt.String())
+	}
+
+	return settings
+}
+
+// runExportSettings writes the settings of every miner of a chain as JSON. it
+// only reads, so -dry-run has no effect.
+func runExportSettings(env *environment, args []string) {
+	fs := newFlagSet("export-settings")
+	argChain := fs.String("chain", "", "The chain to export the miner settings for")
+	argOut := fs.String("out", "", "The output file, defaults to stdout")
+	fs.Parse(args)
+
+	chain := strings.ToUpper(*argChain)
+	if len(chain) == 0 {
+		log.Fatalf("export-settings: no chain given")
+	}
+
+	miners, err := pooldb.GetMinersByChain(env.pooldb().Reader(), chain)
+	if err != nil {
+		log.Fatalf("export-settings: %v", err)
+	}
+
+	settings := make([]*minerSettings, len(miners))
+	for i, miner := range miners {
+		settings[i] = newMinerSettings(miner)
+	}
+
+	var out io.Writer = os.Stdout
+	if *argOut != "" {
+		f, err := os.Create(*argOut)
+		if err != nil {
+			log.Fatalf("export-settings: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(settings)
+	if err != nil {
+		log.Fatalf("export-settings: %v", err)
+	}
+}
+
+func runImportSettings(env *environment, args []string) {
+	fs := newFlagSet("import-settings")
+	argIn := fs.String("in", "", "The file to import, exported by export-settings")
+	argYes := fs.Bool("yes", false, "Commit without asking for confirmation")
+	fs.Parse(args)
+
+	data, err := os.ReadFile(*argIn)
+	if err != nil {
+		log.Fatalf("import-settings: %v", err)
+	}
+
+	var settings []*minerSettings
+	err = json.Unmarshal(data, &settings)
+	if err != nil {
+		log.Fatalf("import-settings: %v", err)
+	}
+
+	pooldbClient := env.pooldb()
+	tx, err := pooldbClient.Begin()
+	if err != nil {
+		log.Fatalf("import-settings: tx: %v", err)
+	}
+	defer tx.SafeRollback()
+
+	var updated, skipped int
+	for _, setting := range settings {
+		chain := strings.ToUpper(setting.Chain)
+		minerID, err := pooldb.GetMinerIDByChainAddress(tx, chain, setting.Address)
+		if err != nil {
+			log.Fatalf("import-settings: %s:%s: %v", chain, setting.Address, err)
+		} else if minerID == 0 {
+			fmt.Printf("skipping %s:%s: miner not found\n", chain, setting.Address)
+			skipped++
+			continue
+		}
+
+		miner, err := pooldb.GetMiner(tx, minerID)
+		if err != nil {
+			log.Fatalf("import-settings: %s:%s: %v", chain, setting.Address, err)
+		}
+
+		err = setting.apply(miner)
+		if err != nil {
+			fmt.Printf("skipping %s:%s: %v\n", chain, setting.Address, err)
+			skipped++
+			continue
+		}
+
+		err = pooldb.UpdateMiner(tx, miner, minerSettingsCols)
+		if err != nil {
+			log.Fatalf("import-settings: %s:%s: %v", chain, setting.Address, err)
+		}
+		updated++
+	}
+
+	fmt.Printf("%d miners to update, %d skipped\n", updated, skipped)
+	if updated == 0 {
+		return
+	} else if dryRun {
+		fmt.Println("dry run: rolled back")
+		return
+	} else if !confirm(fmt.Sprintf("update %d miners?", updated), *argYes) {
+		fmt.Println("rolled back")
+		return
+	}
+
+	err = tx.SafeCommit()
+	if err != nil {
+		log.Fatalf("import-settings: commit: %v", err)
+	}
+	fmt.Printf("updated %d miners\n", updated)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/journal"
+	"github.com/magicpool-co/pool/internal/pooldb"
+)
+
+// runRebuildShares rebuilds the redis share state of a chain (and its solo chain) from the
+// share journals of every pool instance, optionally starting from the last pooldb snapshot
+// of the PPLNS window if the journals no longer hold a full window.
+func runRebuildShares(env *environment, args []string) {
+	fs := newFlagSet("rebuild-shares")
+	argChain := fs.String("chain", "", "The chain to rebuild")
+	argJournalDir := fs.String("journal-dir", "", "The journal directory (pool.journal.dir if empty)")
+	argSnapshot := fs.Bool("snapshot", false, "Start the window from the last pooldb snapshot")
+	argIntervalsSince := fs.Duration("intervals-since", 0, "Also rebuild the intervals that ended within this duration")
+	argYes := fs.Bool("yes", false, "Commit without asking for confirmation")
+	fs.Parse(args)
+
+	chain := strings.ToUpper(*argChain)
+	if len(chain) == 0 {
+		log.Fatalf("rebuild-shares: no chain given")
+	}
+
+	cfg := env.config()
+	chainCfg, err := cfg.GetChain(chain)
+	if err != nil {
+		log.Fatalf("rebuild-shares: %v", err)
+	}
+
+	journalDir := *argJournalDir
+	if len(journalDir) == 0 {
+		journalDir = cfg.Pool.Journal.Dir
+	}
+	if len(journalDir) == 0 {
+		log.Fatalf("rebuild-shares: no journal dir given")
+	}
+
+	dirs, err := journal.InstanceDirs(filepath.Join(journalDir, chain))
+	if err != nil {
+		log.Fatalf("rebuild-shares: journal: %v", err)
+	}
+
+	var base []string
+	var snapshotTime time.Time
+	if *argSnapshot {
+		snapshot, err := pooldb.GetLastWindowSnapshot(env.pooldb().Reader(), chain)
+		if err != nil {
+			log.Fatalf("rebuild-shares: snapshot: %v", err)
+		} else if snapshot == nil {
+			log.Fatalf("rebuild-shares: no snapshot for %s", chain)
+		}
+
+		base, err = journal.DecodeWindow(snapshot.Data)
+		if err != nil {
+			log.Fatalf("rebuild-shares: snapshot: %v", err)
+		}
+		snapshotTime = snapshot.CreatedAt
+		fmt.Printf("%s: snapshot of %d shares at %s\n", chain, len(base), snapshotTime.Format(time.RFC3339))
+	}
+
+	chains := []string{chain}
+	if chainCfg.Solo {
+		chains = append(chains, "S"+chain)
+	}
+
+	window := int64(chainCfg.WindowSize)
+	intervalsSince := time.Now().Add(-*argIntervalsSince)
+	states := make(map[string]*journal.State)
+	for i, chain := range chains {
+		records, err := journal.ReadRecords(dirs, chain, time.Time{})
+		if err != nil {
+			log.Fatalf("rebuild-shares: journal: %s: %v", chain, err)
+		}
+
+		state := journal.Replay(records, chain, window, nil, intervalsSince)
+		if i == 0 && base != nil && int64(len(state.Window)) < window {
+			// the window is the snapshot plus the records written after it (the
+			// snapshot only holds the window of the chain, not of the solo chain)
+			newRecords := make([]*journal.Record, 0)
+			for _, record := range records {
+				if record.Time.After(snapshotTime) {
+					newRecords = append(newRecords, record)
+				}
+			}
+
+			state.Window = journal.Replay(newRecords, chain, window, base, intervalsSince).Window
+		}
+		states[chain] = state
+
+		fmt.Printf("%s: %d records, %d of %d window shares, %d round shares (complete: %t), %d solo miners, %d intervals\n",
+			chain, len(records), len(state.Window), window, state.RoundAccepted, state.RoundComplete,
+			len(state.SoloAccepted), len(state.Intervals))
+	}
+
+	current, err := env.redis().GetRoundShareList(chain)
+	if err != nil {
+		log.Fatalf("rebuild-shares: redis: %v", err)
+	} else if len(current) > 0 {
+		fmt.Printf("%s: the current window of %d shares will be replaced\n", chain, len(current))
+	}
+
+	if dryRun {
+		fmt.Println("dry run: redis is unchanged")
+		return
+	} else if !confirm(fmt.Sprintf("rebuild the %s share state?", chain), *argYes) {
+		fmt.Println("aborted")
+		return
+	}
+
+	redisClient := env.redis()
+	for _, chain := range chains {
+		if err := journal.Restore(redisClient, chain, states[chain]); err != nil {
+			log.Fatalf("rebuild-shares: restore: %s: %v", chain, err)
+		}
+	}
+
+	// a new marker keeps the pools from taking the restored window for a lost one
+	err = redisClient.SetRoundSharesMarker(chain, strconv.FormatInt(time.Now().UnixNano(), 10))
+	if err != nil {
+		log.Fatalf("rebuild-shares: marker: %v", err)
+	}
+	fmt.Printf("%s: rebuilt the share state\n", chain)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+)
+
+func nullBigIntValue(value dbcl.NullBigInt) *big.Int {
+	if !value.Valid || value.BigInt == nil {
+		return new(big.Int)
+	}
+
+	return value.BigInt
+}
+
+func runRecomputeSums(env *environment, args []string) {
+	fs := newFlagSet("recompute-sums")
+	argChain := fs.String("chain", "", "The chain to recompute the balance sums for")
+	argYes := fs.Bool("yes", false, "Commit without asking for confirmation")
+	fs.Parse(args)
+
+	chain := strings.ToUpper(*argChain)
+	if len(chain) == 0 {
+		log.Fatalf("recompute-sums: no chain given")
+	}
+
+	pooldbClient := env.pooldb()
+	tx, err := pooldbClient.Begin()
+	if err != nil {
+		log.Fatalf("recompute-sums: tx: %v", err)
+	}
+	defer tx.SafeRollback()
+
+	currentSums, err := pooldb.GetBalanceSumsByChain(tx, chain)
+	if err != nil {
+		log.Fatalf("recompute-sums: current: %v", err)
+	}
+
+	computedSums, err := pooldb.GetComputedBalanceSumsByChain(tx, chain)
+	if err != nil {
+		log.Fatalf("recompute-sums: computed: %v", err)
+	}
+
+	computedIdx := make(map[uint64]*pooldb.BalanceSum)
+	for _, computedSum := range computedSums {
+		computedIdx[computedSum.MinerID] = computedSum
+	}
+
+	// miners without any inputs or outputs left are reset to zero
+	for _, currentSum := range currentSums {
+		if _, ok := computedIdx[currentSum.MinerID]; !ok {
+			computedIdx[currentSum.MinerID] = &pooldb.BalanceSum{
+				MinerID: currentSum.MinerID,
+				ChainID: chain,
+			}
+		}
+	}
+
+	currentIdx := make(map[uint64]*pooldb.BalanceSum)
+	for _, currentSum := range currentSums {
+		currentIdx[currentSum.MinerID] = currentSum
+	}
+
+	minerIDs := make([]uint64, 0, len(computedIdx))
+	for minerID := range computedIdx {
+		minerIDs = append(minerIDs, minerID)
+	}
+	sort.Slice(minerIDs, func(i, j int) bool { return minerIDs[i] < minerIDs[j] })
+
+	changedSums := make([]*pooldb.BalanceSum, 0)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MINER\tIMMATURE\tRECOMPUTED\tMATURE\tRECOMPUTED")
+	for _, minerID := range minerIDs {
+		computedSum := computedIdx[minerID]
+		currentImmature, currentMature := new(big.Int), new(big.Int)
+		if currentSum, ok := currentIdx[minerID]; ok {
+			currentImmature = nullBigIntValue(currentSum.ImmatureValue)
+			currentMature = nullBigIntValue(currentSum.MatureValue)
+		}
+
+		computedImmature := nullBigIntValue(computedSum.ImmatureValue)
+		computedMature := nullBigIntValue(computedSum.MatureValue)
+		if currentImmature.Cmp(computedImmature) == 0 && currentMature.Cmp(computedMature) == 0 {
+			continue
+		}
+
+		changedSums = append(changedSums, computedSum)
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", minerID,
+			currentImmature, computedImmature, currentMature, computedMature)
+	}
+	w.Flush()
+
+	if len(changedSums) == 0 {
+		fmt.Printf("%s: balance sums are consistent\n", chain)
+		return
+	} else if dryRun {
+		fmt.Printf("dry run: %d balance sums differ\n", len(changedSums))
+		return
+	} else if !confirm(fmt.Sprintf("overwrite %d balance sums?", len(changedSums)), *argYes) {
+		fmt.Println("rolled back")
+		return
+	}
+
+	err = pooldb.InsertOverwriteBalanceSums(tx, changedSums...)
+	if err != nil {
+		log.Fatalf("recompute-sums: overwrite: %v", err)
+	}
+
+	err = tx.SafeCommit()
+	if err != nil {
+		log.Fatalf("recompute-sums: commit: %v", err)
+	}
+	fmt.Printf("%s: overwrote %d balance sums\n", chain, len(changedSums))
+}
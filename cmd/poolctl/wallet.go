@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/magicpool-co/pool/core/audit"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+)
+
+// runCheckWallet replays the wallet check with a wallet balance given by the operator (from
+// a block explorer at a given height, for example). utxos, balances and payouts only keep their
+// current state, so to replay the check at a past point, -snapshot-dsn points it at a snapshot
+// of pooldb restored from a backup taken at that point instead of the live database.
+func runCheckWallet(env *environment, args []string) {
+	fs := newFlagSet("check-wallet")
+	argChain := fs.String("chain", "", "The chain to check")
+	argBalance := fs.String("balance", "", "The wallet balance to check against (in base units)")
+	argSnapshotDSN := fs.String("snapshot-dsn", "", "The pooldb snapshot to replay the check on "+
+		"(user:pass@tcp(host:port)/name), the live database if empty")
+	fs.Parse(args)
+
+	chain := strings.ToUpper(*argChain)
+	if len(chain) == 0 {
+		log.Fatalf("check-wallet: no chain given")
+	}
+
+	walletBalance, ok := new(big.Int).SetString(*argBalance, 10)
+	if !ok {
+		log.Fatalf("check-wallet: invalid balance %s", *argBalance)
+	}
+
+	var pooldbClient *dbcl.Client
+	if len(*argSnapshotDSN) > 0 {
+		var err error
+		pooldbClient, err = dbcl.NewFromDSN(*argSnapshotDSN, nil)
+		if err != nil {
+			log.Fatalf("check-wallet: snapshot: %v", err)
+		}
+		defer pooldbClient.Close()
+	} else {
+		pooldbClient = env.pooldb()
+	}
+
+	check, err := audit.GetWalletCheck(pooldbClient, chain, walletBalance)
+	if err != nil {
+		log.Fatalf("check-wallet: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "wallet balance\t%s\n", check.WalletBalance)
+	fmt.Fprintf(w, "unspent utxos\t%s\n", check.UTXOBalance)
+	fmt.Fprintf(w, "immature inputs\t%s\n", check.ImmatureBalance)
+	fmt.Fprintf(w, "pending inputs\t%s\n", check.PendingBalance)
+	fmt.Fprintf(w, "unpaid outputs\t%s\n", check.UnpaidBalance)
+	fmt.Fprintf(w, "unconfirmed txs\t%s\n", check.UnconfirmedTxValue)
+	fmt.Fprintf(w, "unconfirmed payouts\t%s\n", check.UnconfirmedPayoutValue)
+	w.Flush()
+
+	err = check.Verify()
+	if err != nil {
+		log.Fatalf("check-wallet: %s: %v", chain, err)
+	}
+	fmt.Printf("%s: ok\n", chain)
+}
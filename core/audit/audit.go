@@ -18,61 +18,88 @@ func chainIncludesImmature(chain string) bool {
 	}
 }
 
-func CheckWallet(pooldbClient *dbcl.Client, node types.PayoutNode) error {
-	chain := node.Chain()
-	walletBalance, err := node.GetBalance()
-	if err != nil {
-		return err
-	}
+// WalletCheck holds every component of the wallet check for a single chain.
+type WalletCheck struct {
+	Chain                  string
+	WalletBalance          *big.Int
+	UTXOBalance            *big.Int
+	ImmatureBalance        *big.Int
+	PendingBalance         *big.Int
+	UnpaidBalance          *big.Int
+	UnconfirmedTxValue     *big.Int
+	UnconfirmedPayoutValue *big.Int
+}
 
+// GetWalletCheck fetches the database side of the wallet check, the wallet balance is
+// passed in so that the check can be replayed against a balance from a past point.
+func GetWalletCheck(pooldbClient *dbcl.Client, chain string, walletBalance *big.Int) (*WalletCheck, error) {
 	utxoBalance, err := pooldb.GetSumUnspentUTXOValueByChain(pooldbClient.Reader(), chain)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	immatureBalance, err := pooldb.GetImmatureBalanceInputSumByChain(pooldbClient.Reader(), chain)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	pendingBalance, err := pooldb.GetPendingBalanceInputSumByChain(pooldbClient.Reader(), chain)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	unpaidBalance, err := pooldb.GetUnpaidBalanceOutputSumByChain(pooldbClient.Reader(), chain)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	check := &WalletCheck{
+		Chain:                  chain,
+		WalletBalance:          walletBalance,
+		UTXOBalance:            utxoBalance,
+		ImmatureBalance:        immatureBalance,
+		PendingBalance:         pendingBalance,
+		UnpaidBalance:          unpaidBalance,
+		UnconfirmedTxValue:     new(big.Int),
+		UnconfirmedPayoutValue: new(big.Int),
 	}
 
-	// add immature round sum to UTXOs since they're only added at the point of maturation
-	// (if the chain shows blocks in the wallet balance before they're mature)
 	if chainIncludesImmature(chain) {
-		unconfirmedTxValue, err := pooldb.GetUnconfirmedTransactionSum(pooldbClient.Reader(), chain)
+		check.UnconfirmedTxValue, err = pooldb.GetUnconfirmedTransactionSum(pooldbClient.Reader(), chain)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		utxoBalance.Add(utxoBalance, immatureBalance)
-		utxoBalance.Add(utxoBalance, unconfirmedTxValue)
+		check.UnconfirmedPayoutValue, err = pooldb.GetUnconfirmedPayoutSum(pooldbClient.Reader(), chain)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if walletBalance.Cmp(utxoBalance) != 0 {
-		return fmt.Errorf("mismatch for utxo and wallet: have %s, want %s", utxoBalance, walletBalance)
+	return check, nil
+}
+
+func (c *WalletCheck) Verify() error {
+	utxoBalance := new(big.Int).Set(c.UTXOBalance)
+
+	// add immature round sum to UTXOs since they're only added at the point of maturation
+	// (if the chain shows blocks in the wallet balance before they're mature)
+	if chainIncludesImmature(c.Chain) {
+		utxoBalance.Add(utxoBalance, c.ImmatureBalance)
+		utxoBalance.Add(utxoBalance, c.UnconfirmedTxValue)
+	}
+
+	if c.WalletBalance.Cmp(utxoBalance) != 0 {
+		return fmt.Errorf("mismatch for utxo and wallet: have %s, want %s", utxoBalance, c.WalletBalance)
 	}
 
-	sumMinerBalance := new(big.Int).Add(pendingBalance, unpaidBalance)
+	sumMinerBalance := new(big.Int).Add(c.PendingBalance, c.UnpaidBalance)
 
 	// add immature round sum to sum miner balance since they're only added at the point
 	// of maturation (if the immature round sum is included beforehand too)
-	if chainIncludesImmature(chain) {
-		unconfirmedPayoutValue, err := pooldb.GetUnconfirmedPayoutSum(pooldbClient.Reader(), chain)
-		if err != nil {
-			return err
-		}
-
-		sumMinerBalance.Add(sumMinerBalance, immatureBalance)
-		sumMinerBalance.Sub(sumMinerBalance, unconfirmedPayoutValue)
+	if chainIncludesImmature(c.Chain) {
+		sumMinerBalance.Add(sumMinerBalance, c.ImmatureBalance)
+		sumMinerBalance.Sub(sumMinerBalance, c.UnconfirmedPayoutValue)
 	}
 
 	if utxoBalance.Cmp(sumMinerBalance) != 0 {
@@ -81,3 +108,17 @@ func CheckWallet(pooldbClient *dbcl.Client, node types.PayoutNode) error {
 
 	return nil
 }
+
+func CheckWallet(pooldbClient *dbcl.Client, node types.PayoutNode) error {
+	walletBalance, err := node.GetBalance()
+	if err != nil {
+		return err
+	}
+
+	check, err := GetWalletCheck(pooldbClient, node.Chain(), walletBalance)
+	if err != nil {
+		return err
+	}
+
+	return check.Verify()
+}
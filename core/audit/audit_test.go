@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestWalletCheckVerify(t *testing.T) {
+	tests := []struct {
+		check *WalletCheck
+		valid bool
+	}{
+		{
+			check: &WalletCheck{
+				Chain:                  "ETC",
+				WalletBalance:          new(big.Int).SetUint64(150),
+				UTXOBalance:            new(big.Int).SetUint64(100),
+				ImmatureBalance:        new(big.Int).SetUint64(40),
+				PendingBalance:         new(big.Int).SetUint64(30),
+				UnpaidBalance:          new(big.Int).SetUint64(90),
+				UnconfirmedTxValue:     new(big.Int).SetUint64(10),
+				UnconfirmedPayoutValue: new(big.Int).SetUint64(10),
+			},
+			valid: true,
+		},
+		{
+			check: &WalletCheck{
+				Chain:                  "ERG",
+				WalletBalance:          new(big.Int).SetUint64(100),
+				UTXOBalance:            new(big.Int).SetUint64(100),
+				ImmatureBalance:        new(big.Int).SetUint64(40),
+				PendingBalance:         new(big.Int).SetUint64(30),
+				UnpaidBalance:          new(big.Int).SetUint64(70),
+				UnconfirmedTxValue:     new(big.Int),
+				UnconfirmedPayoutValue: new(big.Int),
+			},
+			valid: true,
+		},
+		{
+			check: &WalletCheck{
+				Chain:                  "ERG",
+				WalletBalance:          new(big.Int).SetUint64(100),
+				UTXOBalance:            new(big.Int).SetUint64(100),
+				ImmatureBalance:        new(big.Int),
+				PendingBalance:         new(big.Int),
+				UnpaidBalance:          new(big.Int).SetUint64(99),
+				UnconfirmedTxValue:     new(big.Int),
+				UnconfirmedPayoutValue: new(big.Int),
+			},
+			valid: false,
+		},
+		{
+			check: &WalletCheck{
+				Chain:                  "ETC",
+				WalletBalance:          new(big.Int).SetUint64(100),
+				UTXOBalance:            new(big.Int).SetUint64(100),
+				ImmatureBalance:        new(big.Int).SetUint64(40),
+				PendingBalance:         new(big.Int),
+				UnpaidBalance:          new(big.Int).SetUint64(100),
+				UnconfirmedTxValue:     new(big.Int),
+				UnconfirmedPayoutValue: new(big.Int),
+			},
+			valid: false,
+		},
+	}
+
+	for i, tt := range tests {
+		err := tt.check.Verify()
+		if tt.valid && err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("failed on %d: expected mismatch", i)
+		}
+	}
+}
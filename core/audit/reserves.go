@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/common"
+	"github.com/magicpool-co/pool/pkg/crypto/merkle"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+func NewReserveProofMessage(chain, address, merkleRoot string, timestamp time.Time) string {
+	return fmt.Sprintf("magicpool proof of reserves for %s: address %s, root %s, timestamp %d",
+		chain, address, merkleRoot, timestamp.Unix())
+}
+
+// PublishReserveProof builds a Merkle-sum tree over all miner balances (immature and mature)
+// for the node's chain and stores the root along with the wallet balance and, if the node
+// supports it, a signature from the wallet key proving ownership of the wallet.
+func PublishReserveProof(pooldbClient *dbcl.Client, node types.PayoutNode) error {
+	chain := node.Chain()
+	balanceSums, err := pooldb.GetBalanceSumsByChain(pooldbClient.Reader(), chain)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	leaves := make([]merkle.SumNode, 0)
+	proofLeaves := make([]*pooldb.ReserveProofLeaf, 0)
+	for _, balanceSum := range balanceSums {
+		value := new(big.Int)
+		if balanceSum.ImmatureValue.Valid {
+			value.Add(value, balanceSum.ImmatureValue.BigInt)
+		}
+		if balanceSum.MatureValue.Valid {
+			value.Add(value, balanceSum.MatureValue.BigInt)
+		}
+
+		if value.Cmp(common.Big0) < 0 {
+			return fmt.Errorf("negative balance for miner %d", balanceSum.MinerID)
+		} else if value.Cmp(common.Big0) == 0 {
+			continue
+		}
+
+		proofLeaves = append(proofLeaves, &pooldb.ReserveProofLeaf{
+			MinerID: balanceSum.MinerID,
+			Idx:     uint64(len(leaves)),
+			Value:   dbcl.NullBigInt{Valid: true, BigInt: value},
+		})
+		leaves = append(leaves, merkle.NewSumLeaf(balanceSum.MinerID, value, nonce))
+	}
+
+	walletBalance, err := node.GetBalance()
+	if err != nil {
+		return err
+	}
+
+	root := merkle.CalculateSumRoot(leaves)
+	merkleRoot := hex.EncodeToString(root.Hash)
+	message := NewReserveProofMessage(chain, node.Address(), merkleRoot, time.Now())
+
+	// KAS, KLS and NEXA have no standard message signing format for their addresses and the ERG wallet
+	// is managed by the node, so their proofs are published unsigned (and marked as such by the api)
+	var signature *string
+	if signingNode, ok := node.(types.MessageSigningNode); ok {
+		rawSignature, err := signingNode.SignMessage(message)
+		if err != nil {
+			return err
+		}
+		signature = types.StringPtr(rawSignature)
+	}
+
+	proof := &pooldb.ReserveProof{
+		ChainID: chain,
+
+		MerkleRoot:  merkleRoot,
+		Nonce:       hex.EncodeToString(nonce),
+		LeafCount:   uint64(len(leaves)),
+		Liabilities: dbcl.NullBigInt{Valid: true, BigInt: root.Sum},
+
+		WalletAddress: node.Address(),
+		WalletBalance: dbcl.NullBigInt{Valid: true, BigInt: walletBalance},
+		Message:       message,
+		Signature:     signature,
+	}
+
+	dbTx, err := pooldbClient.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.SafeRollback()
+
+	proof.ID, err = pooldb.InsertReserveProof(dbTx, proof)
+	if err != nil {
+		return err
+	}
+
+	for _, leaf := range proofLeaves {
+		leaf.ReserveProofID = proof.ID
+	}
+
+	err = pooldb.InsertReserveProofLeaves(dbTx, proofLeaves...)
+	if err != nil {
+		return err
+	}
+
+	return dbTx.SafeCommit()
+}
+
+// RebuildReserveProofLeaves recreates the tree leaves for a published reserve proof.
+func RebuildReserveProofLeaves(
+	proof *pooldb.ReserveProof,
+	proofLeaves []*pooldb.ReserveProofLeaf,
+) ([]merkle.SumNode, error) {
+	nonce, err := hex.DecodeString(proof.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := make([]merkle.SumNode, len(proofLeaves))
+	for i, proofLeaf := range proofLeaves {
+		if proofLeaf.Idx != uint64(i) {
+			return nil, fmt.Errorf("leaf index mismatch for reserve proof %d: %d != %d",
+				proof.ID, proofLeaf.Idx, i)
+		} else if !proofLeaf.Value.Valid {
+			return nil, fmt.Errorf("no value for reserve proof %d leaf %d", proof.ID, i)
+		}
+
+		leaves[i] = merkle.NewSumLeaf(proofLeaf.MinerID, proofLeaf.Value.BigInt, nonce)
+	}
+
+	root := merkle.CalculateSumRoot(leaves)
+	if hex.EncodeToString(root.Hash) != proof.MerkleRoot {
+		return nil, fmt.Errorf("merkle root mismatch for reserve proof %d", proof.ID)
+	}
+
+	return leaves, nil
+}
@@ -0,0 +1,238 @@
+package audit
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/internal/telegram"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+const (
+	// shares within 1/NearBlockShareFactor of the block difficulty are "near block" shares
+	NearBlockShareFactor = 16
+	// shares at HighDiffShareFactor times the share difficulty are "high diff" shares
+	HighDiffShareFactor = 8
+	// shares submitted within EarlyShareWindow of a job change are "early" shares
+	EarlyShareWindow = time.Second * 2
+
+	// minimum number of expected events before a poisson test is run
+	minExpectedEvents = 3
+	// minimum number of accepted shares before a z-score test is run
+	minAuditShares = 10000
+	// maximum probability of an honest miner being flagged by a poisson test
+	poissonThreshold = 1e-4
+	// minimum (absolute) z-score for an honest miner being flagged by a z-score test
+	zScoreThreshold = 5
+)
+
+// RecordShare adds a single accepted share to the audit. every share difficulty
+// is expressed in the same (raw) units, sinceJob is the time since the last job change.
+func RecordShare(
+	obj *pooldb.MinerShareAudit,
+	shareDiff, targetDiff, blockDiff float64,
+	sinceJob time.Duration,
+	foundBlock bool,
+) {
+	if targetDiff <= 0 || blockDiff <= 0 {
+		return
+	}
+
+	obj.AcceptedShares++
+	obj.ExpectedBlocks += math.Min(1, targetDiff/blockDiff)
+	obj.ExpectedNearBlockShares += math.Min(1, targetDiff*NearBlockShareFactor/blockDiff)
+
+	if shareDiff >= targetDiff*HighDiffShareFactor {
+		obj.HighDiffShares++
+	}
+
+	if shareDiff*NearBlockShareFactor >= blockDiff {
+		obj.NearBlockShares++
+	}
+
+	if sinceJob >= 0 && sinceJob < EarlyShareWindow {
+		obj.EarlyShares++
+	}
+
+	if foundBlock {
+		obj.FoundBlocks++
+	}
+}
+
+// poissonCDF returns P(X <= k) for X ~ Poisson(lambda), computed
+// in log space to avoid underflow for large values of lambda.
+func poissonCDF(k uint64, lambda float64) float64 {
+	if lambda <= 0 {
+		return 1
+	}
+
+	var sum float64
+	logLambda := math.Log(lambda)
+	for i := uint64(0); i <= k; i++ {
+		logFactorial, _ := math.Lgamma(float64(i) + 1)
+		sum += math.Exp(float64(i)*logLambda - lambda - logFactorial)
+	}
+
+	return math.Min(1, sum)
+}
+
+// zScore returns the normal approximation of the binomial z-score
+// for the observed count out of n trials with probability p.
+func zScore(observed, n uint64, p float64) float64 {
+	if n == 0 || p <= 0 || p >= 1 {
+		return 0
+	}
+
+	expected := float64(n) * p
+	stdDev := math.Sqrt(expected * (1 - p))
+
+	return (float64(observed) - expected) / stdDev
+}
+
+// EvaluateShareAudit runs every statistical test against the audit, returning
+// the reason for every failed test. poolEarlyRate is the rate of early shares
+// for the pool as a whole, since it depends on the chain's job interval.
+func EvaluateShareAudit(obj *pooldb.MinerShareAudit, poolEarlyRate float64) []string {
+	reasons := make([]string, 0)
+
+	// blocks found versus the blocks expected from the miner's total work
+	if obj.ExpectedBlocks >= minExpectedEvents {
+		if poissonCDF(obj.FoundBlocks, obj.ExpectedBlocks) < poissonThreshold {
+			reasons = append(reasons, fmt.Sprintf("found %d of %.1f expected blocks",
+				obj.FoundBlocks, obj.ExpectedBlocks))
+		}
+	}
+
+	// near block shares versus the near block shares expected from the miner's total work
+	if obj.ExpectedNearBlockShares >= minExpectedEvents {
+		if poissonCDF(obj.NearBlockShares, obj.ExpectedNearBlockShares) < poissonThreshold {
+			reasons = append(reasons, fmt.Sprintf("submitted %d of %.1f expected near block shares",
+				obj.NearBlockShares, obj.ExpectedNearBlockShares))
+		}
+	}
+
+	// blocks found versus the blocks expected from the miner's near block shares
+	expectedFromNearBlock := float64(obj.NearBlockShares) / NearBlockShareFactor
+	if expectedFromNearBlock >= minExpectedEvents {
+		if poissonCDF(obj.FoundBlocks, expectedFromNearBlock) < poissonThreshold {
+			reasons = append(reasons, fmt.Sprintf("found %d blocks from %d near block shares",
+				obj.FoundBlocks, obj.NearBlockShares))
+		}
+	}
+
+	if obj.AcceptedShares >= minAuditShares {
+		// share difficulty distribution versus the expected distribution (in either direction)
+		z := zScore(obj.HighDiffShares, obj.AcceptedShares, 1.0/HighDiffShareFactor)
+		if math.Abs(z) >= zScoreThreshold {
+			reasons = append(reasons, fmt.Sprintf("high diff share distribution off by %.1f sigma", z))
+		}
+
+		// concentration of shares right after job changes versus the rest of the pool
+		z = zScore(obj.EarlyShares, obj.AcceptedShares, poolEarlyRate)
+		if z >= zScoreThreshold {
+			reasons = append(reasons, fmt.Sprintf("early shares concentrated by %.1f sigma", z))
+		}
+	}
+
+	return reasons
+}
+
+// CheckShares evaluates every miner share audit for the chain, flagging (and
+// optionally excluding from PPLNS) any miner that fails a test.
+func CheckShares(
+	pooldbClient *dbcl.Client,
+	telegramClient *telegram.Client,
+	chain string,
+	autoExclude bool,
+) error {
+	audits, err := pooldb.GetMinerShareAuditsByChain(pooldbClient.Reader(), chain)
+	if err != nil {
+		return err
+	}
+
+	var poolAcceptedShares, poolEarlyShares uint64
+	for _, obj := range audits {
+		poolAcceptedShares += obj.AcceptedShares
+		poolEarlyShares += obj.EarlyShares
+	}
+
+	var poolEarlyRate float64
+	if poolAcceptedShares > 0 {
+		poolEarlyRate = float64(poolEarlyShares) / float64(poolAcceptedShares)
+	}
+
+	for _, obj := range audits {
+		reasons := EvaluateShareAudit(obj, poolEarlyRate)
+		flagged := len(reasons) > 0
+		if flagged == obj.Flagged {
+			continue
+		}
+
+		var flagReason *string
+		if flagged {
+			reason := strings.Join(reasons, "; ")
+			if len(reason) > 255 {
+				reason = reason[:255]
+			}
+			flagReason = types.StringPtr(reason)
+		}
+
+		// operators can exclude a miner manually, so the exclusion
+		// is only ever removed when the flag itself is removed
+		obj.Flagged = flagged
+		obj.FlagReason = flagReason
+		obj.Excluded = flagged && (autoExclude || obj.Excluded)
+
+		cols := []string{"flagged", "excluded", "flag_reason"}
+		err = pooldb.UpdateMinerShareAudit(pooldbClient.Writer(), obj, cols)
+		if err != nil {
+			return err
+		}
+
+		if flagged {
+			telegramClient.NotifyMinerFlagged(chain, obj.MinerID, types.StringValue(flagReason), obj.Excluded)
+		}
+	}
+
+	return nil
+}
+
+// SetMinerBanned bans (or unbans) a miner on a chain. unlike exclusions, bans are only
+// ever set by operators, so they are never removed by CheckShares.
+func SetMinerBanned(pooldbClient *dbcl.Client, minerID uint64, chain string, banned bool) error {
+	obj := &pooldb.MinerShareAudit{
+		MinerID: minerID,
+		ChainID: chain,
+		Banned:  banned,
+	}
+
+	// make sure the audit exists without touching the counts
+	err := pooldb.InsertAddMinerShareAudits(pooldbClient.Writer(), obj)
+	if err != nil {
+		return err
+	}
+
+	return pooldb.UpdateMinerShareAudit(pooldbClient.Writer(), obj, []string{"banned"})
+}
+
+// ResetShareAudit zeroes the counters of a miner's audit on a chain, starting a new
+// window for the statistical tests. the flag (along with any exclusion) is removed
+// by the next CheckShares, since the tests no longer have enough events to fail.
+// bans are untouched.
+func ResetShareAudit(pooldbClient *dbcl.Client, minerID uint64, chain string) error {
+	obj := &pooldb.MinerShareAudit{
+		MinerID: minerID,
+		ChainID: chain,
+	}
+
+	cols := []string{
+		"accepted_shares", "high_diff_shares", "near_block_shares", "early_shares",
+		"found_blocks", "expected_blocks", "expected_near_block_shares",
+	}
+
+	return pooldb.UpdateMinerShareAudit(pooldbClient.Writer(), obj, cols)
+}
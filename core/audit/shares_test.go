@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+)
+
+func TestPoissonCDF(t *testing.T) {
+	tests := []struct {
+		k      uint64
+		lambda float64
+		cdf    float64
+	}{
+		{k: 0, lambda: 0, cdf: 1},
+		{k: 0, lambda: 1, cdf: 0.36787944},
+		{k: 2, lambda: 1, cdf: 0.91969860},
+		{k: 0, lambda: 10, cdf: 0.00004540},
+		{k: 900, lambda: 1000, cdf: 0.00069777},
+	}
+
+	for i, tt := range tests {
+		cdf := poissonCDF(tt.k, tt.lambda)
+		if math.Abs(cdf-tt.cdf) > 1e-6 {
+			t.Errorf("failed on %d: cdf mismatch: have %.8f, want %.8f", i, cdf, tt.cdf)
+		}
+	}
+}
+
+func TestRecordShare(t *testing.T) {
+	obj := new(pooldb.MinerShareAudit)
+	RecordShare(obj, 100, 10, 1000, time.Second, false)
+	RecordShare(obj, 10, 10, 1000, time.Second*10, false)
+	RecordShare(obj, 2000, 10, 1000, time.Second*10, true)
+	RecordShare(obj, 2000, 0, 1000, time.Second*10, true)
+
+	expected := &pooldb.MinerShareAudit{
+		AcceptedShares:          3,
+		HighDiffShares:          2,
+		NearBlockShares:         2,
+		EarlyShares:             1,
+		FoundBlocks:             1,
+		ExpectedBlocks:          0.03,
+		ExpectedNearBlockShares: 0.48,
+	}
+
+	if obj.AcceptedShares != expected.AcceptedShares {
+		t.Errorf("accepted shares mismatch: have %d, want %d", obj.AcceptedShares, expected.AcceptedShares)
+	} else if obj.HighDiffShares != expected.HighDiffShares {
+		t.Errorf("high diff shares mismatch: have %d, want %d", obj.HighDiffShares, expected.HighDiffShares)
+	} else if obj.NearBlockShares != expected.NearBlockShares {
+		t.Errorf("near block shares mismatch: have %d, want %d", obj.NearBlockShares, expected.NearBlockShares)
+	} else if obj.EarlyShares != expected.EarlyShares {
+		t.Errorf("early shares mismatch: have %d, want %d", obj.EarlyShares, expected.EarlyShares)
+	} else if obj.FoundBlocks != expected.FoundBlocks {
+		t.Errorf("found blocks mismatch: have %d, want %d", obj.FoundBlocks, expected.FoundBlocks)
+	} else if math.Abs(obj.ExpectedBlocks-expected.ExpectedBlocks) > 1e-9 {
+		t.Errorf("expected blocks mismatch: have %f, want %f", obj.ExpectedBlocks, expected.ExpectedBlocks)
+	} else if math.Abs(obj.ExpectedNearBlockShares-expected.ExpectedNearBlockShares) > 1e-9 {
+		t.Errorf("expected near block shares mismatch: have %f, want %f",
+			obj.ExpectedNearBlockShares, expected.ExpectedNearBlockShares)
+	}
+}
+
+func TestEvaluateShareAudit(t *testing.T) {
+	tests := []struct {
+		audit         *pooldb.MinerShareAudit
+		poolEarlyRate float64
+		reasons       int
+	}{
+		{
+			// honest miner
+			audit: &pooldb.MinerShareAudit{
+				AcceptedShares:          1000000,
+				HighDiffShares:          125000,
+				NearBlockShares:         320,
+				EarlyShares:             20000,
+				FoundBlocks:             19,
+				ExpectedBlocks:          20,
+				ExpectedNearBlockShares: 320,
+			},
+			poolEarlyRate: 0.02,
+			reasons:       0,
+		},
+		{
+			// too little data for any test
+			audit: &pooldb.MinerShareAudit{
+				AcceptedShares:          100,
+				HighDiffShares:          0,
+				NearBlockShares:         0,
+				FoundBlocks:             0,
+				ExpectedBlocks:          0.5,
+				ExpectedNearBlockShares: 2,
+			},
+			poolEarlyRate: 0.02,
+			reasons:       0,
+		},
+		{
+			// withholds blocks, submits near block shares
+			audit: &pooldb.MinerShareAudit{
+				AcceptedShares:          1000000,
+				HighDiffShares:          125000,
+				NearBlockShares:         320,
+				EarlyShares:             20000,
+				FoundBlocks:             0,
+				ExpectedBlocks:          20,
+				ExpectedNearBlockShares: 320,
+			},
+			poolEarlyRate: 0.02,
+			reasons:       2,
+		},
+		{
+			// withholds blocks and near block shares
+			audit: &pooldb.MinerShareAudit{
+				AcceptedShares:          1000000,
+				HighDiffShares:          125000,
+				NearBlockShares:         0,
+				EarlyShares:             20000,
+				FoundBlocks:             0,
+				ExpectedBlocks:          20,
+				ExpectedNearBlockShares: 320,
+			},
+			poolEarlyRate: 0.02,
+			reasons:       2,
+		},
+		{
+			// drops high difficulty shares, concentrated after job changes
+			audit: &pooldb.MinerShareAudit{
+				AcceptedShares:          1000000,
+				HighDiffShares:          100000,
+				NearBlockShares:         320,
+				EarlyShares:             40000,
+				FoundBlocks:             20,
+				ExpectedBlocks:          20,
+				ExpectedNearBlockShares: 320,
+			},
+			poolEarlyRate: 0.02,
+			reasons:       2,
+		},
+	}
+
+	for i, tt := range tests {
+		reasons := EvaluateShareAudit(tt.audit, tt.poolEarlyRate)
+		if len(reasons) != tt.reasons {
+			t.Errorf("failed on %d: reasons mismatch: have %v, want %d", i, reasons, tt.reasons)
+		}
+	}
+}
@@ -101,12 +101,19 @@ func (c *Client) PrepareOutgoingTxs(
 				return nil, err
 			}
 
-			// txOutputs count has to be non-zero since output
-			// sum has already been verified as non-zero
+			// txOutputs count has to be non-zero since output sum has already been
+			// verified as non-zero (multiple outputs are only used for batch payouts)
+			feeBalanceSum := new(big.Int)
+			for _, txOutput := range txOutputs {
+				if txOutput.FeeBalance != nil {
+					feeBalanceSum.Add(feeBalanceSum, txOutput.FeeBalance)
+				}
+			}
+
 			inputs = []*types.TxInput{
 				&types.TxInput{
-					Value:      txOutputs[0].Value,
-					FeeBalance: txOutputs[0].FeeBalance,
+					Value:      new(big.Int).Set(txOutputSum),
+					FeeBalance: feeBalanceSum,
 					Index:      uint32(count),
 				},
 			}
@@ -415,9 +422,24 @@ func (c *Client) spendTx(node types.PayoutNode, tx *pooldb.Transaction) error {
 				continue
 			}
 			balanceOutputSum.Add(balanceOutputSum, balanceOutput.Value.BigInt)
+			if balanceOutput.TxFees.Valid {
+				balanceOutputSum.Add(balanceOutputSum, balanceOutput.TxFees.BigInt)
+			}
+		}
+
+		// the payout tx fees hold the tx fee and any early payout fee,
+		// both of which are deducted from the balance of the miner
+		payouts, err := pooldb.GetPayoutsByTransaction(dbTx, tx.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, payout := range payouts {
+			if payout.TxFees.Valid {
+				balanceOutputSum.Sub(balanceOutputSum, payout.TxFees.BigInt)
+			}
 		}
 
-		balanceOutputSum.Sub(balanceOutputSum, tx.Fee.BigInt)
 		if balanceOutputSum.Cmp(tx.Value.BigInt) != 0 {
 			return fmt.Errorf("balance output sum and tx value mismatch: have %s, want %s",
 				balanceOutputSum, tx.Value.BigInt)
@@ -488,6 +510,12 @@ func (c *Client) ConfirmOutgoingTxs(node types.PayoutNode) error {
 			return err
 		} else if nodeTx == nil {
 			continue
+		} else if nodeTx.Failed {
+			err = c.failTx(node, tx, nodeTx)
+			if err != nil {
+				return err
+			}
+			continue
 		} else if !nodeTx.Confirmed {
 			if time.Since(tx.CreatedAt) > time.Hour*24 {
 				// @TODO: manage failed transactions
@@ -529,3 +557,84 @@ func (c *Client) ConfirmOutgoingTxs(node types.PayoutNode) error {
 
 	return nil
 }
+
+// failTx settles a tx that was mined but reverted (only possible on account-based chains).
+// The network fee is gone, but everything else stays in the wallet, so the unused gas is
+// returned as the fee balance (like a confirmed tx) and the value that never left the
+// wallet is returned as a second utxo. For payouts, the value is the sum of the values of
+// its payouts, since those are what the recipients would have received.
+func (c *Client) failTx(node types.PayoutNode, tx *pooldb.Transaction, nodeTx *types.TxResponse) error {
+	if nodeTx.Fee == nil {
+		return fmt.Errorf("no fee for tx %s", nodeTx.Hash)
+	} else if !tx.Value.Valid {
+		return fmt.Errorf("no value for tx %s", tx.TxID)
+	}
+
+	dbTx, err := c.pooldb.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.SafeRollback()
+
+	refundValue := new(big.Int).Set(tx.Value.BigInt)
+	if types.TransactionType(tx.Type) == types.PayoutTx {
+		payouts, err := pooldb.GetPayoutsByTransaction(dbTx, tx.ID)
+		if err != nil {
+			return err
+		}
+
+		refundValue = new(big.Int)
+		for _, payout := range payouts {
+			if !payout.Value.Valid {
+				return fmt.Errorf("no value for payout %d", payout.ID)
+			}
+			refundValue.Add(refundValue, payout.Value.BigInt)
+		}
+	}
+
+	feeBalance := nodeTx.FeeBalance
+	if feeBalance == nil {
+		feeBalance = new(big.Int)
+	}
+
+	tx.Height = types.Uint64Ptr(nodeTx.BlockNumber)
+	tx.Fee = dbcl.NullBigInt{Valid: true, BigInt: nodeTx.Fee}
+	tx.FeeBalance = dbcl.NullBigInt{Valid: true, BigInt: feeBalance}
+	tx.Confirmed = true
+	tx.Failed = true
+
+	utxos := make([]*pooldb.UTXO, 0, 2)
+	for i, value := range []*big.Int{feeBalance, refundValue} {
+		if value.Cmp(common.Big0) <= 0 {
+			continue
+		}
+
+		utxos = append(utxos, &pooldb.UTXO{
+			ChainID: node.Chain(),
+			TxID:    tx.TxID,
+			Index:   uint32(i),
+			Value:   dbcl.NullBigInt{Valid: true, BigInt: value},
+			Active:  true,
+			Spent:   false,
+		})
+	}
+
+	if len(utxos) > 0 {
+		err = pooldb.InsertUTXOs(dbTx, utxos...)
+		if err != nil {
+			return err
+		}
+	}
+
+	cols := []string{"height", "fee", "fee_balance", "confirmed", "failed"}
+	err = pooldb.UpdateTransaction(dbTx, tx, cols)
+	if err != nil {
+		return err
+	}
+
+	floatValue := common.BigIntToFloat64(refundValue, node.GetUnits().Big())
+	c.telegram.NotifyTransactionFailed(tx.ID, node.Chain(), tx.TxID,
+		node.GetTxExplorerURL(tx.TxID), floatValue)
+
+	return dbTx.SafeCommit()
+}
@@ -155,13 +155,12 @@ func (c *Client) truncateBlocks(node types.MiningNode, endTime time.Time) error
 func (c *Client) CollectBlocks(node types.MiningNode) error {
 	var blocks []*tsdb.RawBlock
 	switch node.Chain() {
-	case "KAS":
+	case "KAS", "KLS":
 		const limit = 5000
+		// an empty hash lets the node pick its own starting block
 		lastHash, err := tsdb.GetRawBlockMaxHashByHeight(c.tsdb.Reader(), node.Chain())
 		if err != nil {
 			return err
-		} else if lastHash == "" {
-			lastHash = "05a17c707b3277534f7125e095772051d8d65962219f7c6d8342a125a5c0effa"
 		}
 
 		blocks, err = node.GetBlocksByHash(lastHash, limit)
@@ -205,6 +204,10 @@ func (c *Client) CollectBlocks(node types.MiningNode) error {
 		}
 
 		switch node.Chain() {
+		case "BCH", "BTC":
+			if currentHeight-lastHeight > 100 {
+				currentHeight = lastHeight + 100
+			}
 		case "CFX":
 			if currentHeight-lastHeight > 2000 {
 				currentHeight = lastHeight + 2000
@@ -213,7 +216,7 @@ func (c *Client) CollectBlocks(node types.MiningNode) error {
 			if currentHeight-lastHeight > 250 {
 				currentHeight = lastHeight + 250
 			}
-		case "ERG", "FLUX", "FIRO", "NEXA", "RVN":
+		case "ERG", "FLUX", "FIRO", "NEXA", "RVN", "ZEN":
 			if currentHeight-lastHeight > 500 {
 				currentHeight = lastHeight + 500
 			}
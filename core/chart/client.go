@@ -2,19 +2,26 @@ package chart
 
 import (
 	"github.com/magicpool-co/pool/internal/redis"
+	"github.com/magicpool-co/pool/internal/tsdb"
 	"github.com/magicpool-co/pool/pkg/dbcl"
 )
 
 type Client struct {
 	pooldb *dbcl.Client
 	tsdb   *dbcl.Client
+	shares tsdb.ShareStore
 	redis  *redis.Client
 }
 
-func New(pooldbClient, tsdbClient *dbcl.Client, redisClient *redis.Client) *Client {
+func New(
+	pooldbClient, tsdbClient *dbcl.Client,
+	shareStore tsdb.ShareStore,
+	redisClient *redis.Client,
+) *Client {
 	client := &Client{
 		pooldb: pooldbClient,
 		tsdb:   tsdbClient,
+		shares: shareStore,
 		redis:  redisClient,
 	}
 
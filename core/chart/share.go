@@ -9,7 +9,6 @@ import (
 
 	"github.com/magicpool-co/pool/internal/tsdb"
 	"github.com/magicpool-co/pool/pkg/common"
-	"github.com/magicpool-co/pool/pkg/dbcl"
 	"github.com/magicpool-co/pool/types"
 )
 
@@ -34,22 +33,22 @@ func parseInterval(interval string) (time.Time, error) {
 }
 
 func getInitialShareAverages(
-	q dbcl.Querier,
+	shares tsdb.ShareStore,
 	ts time.Time,
 	chain string,
 	period types.PeriodType,
 ) (float64, map[uint64]float64, map[uint64]float64, error) {
-	globalAvg, err := tsdb.GetGlobalSharesAverage(q, ts, chain, int(period), period.Average())
+	globalAvg, err := shares.GetGlobalSharesAverage(ts, chain, int(period), period.Average())
 	if err != nil {
 		return 0, nil, nil, err
 	}
 
-	minerAvg, err := tsdb.GetMinerSharesAverage(q, ts, chain, int(period), period.Average())
+	minerAvg, err := shares.GetMinerSharesAverage(ts, chain, int(period), period.Average())
 	if err != nil {
 		return 0, nil, nil, err
 	}
 
-	workerAvg, err := tsdb.GetWorkerSharesAverage(q, ts, chain, int(period), period.Average())
+	workerAvg, err := shares.GetWorkerSharesAverage(ts, chain, int(period), period.Average())
 	if err != nil {
 		return 0, nil, nil, err
 	}
@@ -83,7 +82,7 @@ func (c *Client) rollupShares(chain string, node types.MiningNode, interval stri
 	if err != nil {
 		return err
 	}
-	globalAvg, minerAvg, workerAvg, err := getInitialShareAverages(c.tsdb.Reader(),
+	globalAvg, minerAvg, workerAvg, err := getInitialShareAverages(c.shares,
 		endTime, chain, sharePeriod)
 	if err != nil {
 		return err
@@ -219,48 +218,45 @@ func (c *Client) rollupShares(chain string, node types.MiningNode, interval stri
 		globalShare.Miners = uint64(len(minerShares))
 		globalShare.Workers = uint64(len(workerShares))
 
-		tx, err := c.tsdb.Begin()
-		if err != nil {
-			return err
-		}
-		defer tx.SafeRollback()
-
-		if err := tsdb.InsertGlobalShares(tx, globalShare); err != nil {
-			return err
-		} else if err := tsdb.InsertMinerShares(tx, minerShares...); err != nil {
-			return err
-		} else if err := tsdb.InsertWorkerShares(tx, workerShares...); err != nil {
-			return err
-		}
-
-		fullShareList := [][]*tsdb.Share{[]*tsdb.Share{globalShare}, minerShares, workerShares}
-		for _, shareList := range fullShareList {
-			for _, share := range shareList {
-				share.Pending = true
-				share.Hashrate *= float64(share.Count)
-				share.AvgHashrate = 0
+		err := c.shares.Transact(func(tx tsdb.ShareStore) error {
+			if err := tx.InsertGlobalShares(globalShare); err != nil {
+				return err
+			} else if err := tx.InsertMinerShares(minerShares...); err != nil {
+				return err
+			} else if err := tx.InsertWorkerShares(workerShares...); err != nil {
+				return err
 			}
-		}
 
-		for _, rollupPeriod := range shareRollupPeriods {
+			fullShareList := [][]*tsdb.Share{[]*tsdb.Share{globalShare}, minerShares, workerShares}
 			for _, shareList := range fullShareList {
 				for _, share := range shareList {
-					share.Period = int(rollupPeriod)
-					share.StartTime = common.NormalizeDate(share.StartTime, rollupPeriod.Rollup(), true)
-					share.EndTime = common.NormalizeDate(share.StartTime, rollupPeriod.Rollup(), false)
+					share.Pending = true
+					share.Hashrate *= float64(share.Count)
+					share.AvgHashrate = 0
 				}
 			}
 
-			if err := tsdb.InsertPartialGlobalShares(tx, globalShare); err != nil {
-				return err
-			} else if err := tsdb.InsertPartialMinerShares(tx, minerShares...); err != nil {
-				return err
-			} else if err := tsdb.InsertPartialWorkerShares(tx, workerShares...); err != nil {
-				return err
+			for _, rollupPeriod := range shareRollupPeriods {
+				for _, shareList := range fullShareList {
+					for _, share := range shareList {
+						share.Period = int(rollupPeriod)
+						share.StartTime = common.NormalizeDate(share.StartTime, rollupPeriod.Rollup(), true)
+						share.EndTime = common.NormalizeDate(share.StartTime, rollupPeriod.Rollup(), false)
+					}
+				}
+
+				if err := tx.InsertPartialGlobalShares(globalShare); err != nil {
+					return err
+				} else if err := tx.InsertPartialMinerShares(minerShares...); err != nil {
+					return err
+				} else if err := tx.InsertPartialWorkerShares(workerShares...); err != nil {
+					return err
+				}
 			}
-		}
 
-		if err := tx.SafeCommit(); err != nil {
+			return nil
+		})
+		if err != nil {
 			return err
 		}
 
@@ -304,17 +300,17 @@ func finalizeShare(share *tsdb.Share) {
 func (c *Client) finalizeShares(chain string, endTime time.Time) error {
 	for _, rollupPeriod := range shareRollupPeriods {
 		// finalize summed statistics
-		globalShares, err := tsdb.GetPendingGlobalSharesByEndTime(c.tsdb.Reader(),
+		globalShares, err := c.shares.GetPendingGlobalSharesByEndTime(
 			endTime, chain, int(rollupPeriod))
 		if err != nil {
 			return err
 		}
-		minerShares, err := tsdb.GetPendingMinerSharesByEndTime(c.tsdb.Reader(),
+		minerShares, err := c.shares.GetPendingMinerSharesByEndTime(
 			endTime, chain, int(rollupPeriod))
 		if err != nil {
 			return err
 		}
-		workerShares, err := tsdb.GetPendingWorkerSharesByEndTime(c.tsdb.Reader(),
+		workerShares, err := c.shares.GetPendingWorkerSharesByEndTime(
 			endTime, chain, int(rollupPeriod))
 		if err != nil {
 			return err
@@ -330,21 +326,21 @@ func (c *Client) finalizeShares(chain string, endTime time.Time) error {
 			finalizeShare(workerShare)
 		}
 
-		err = tsdb.InsertFinalGlobalShares(c.tsdb.Writer(), globalShares...)
+		err = c.shares.InsertFinalGlobalShares(globalShares...)
 		if err != nil {
 			return err
 		}
-		err = tsdb.InsertFinalMinerShares(c.tsdb.Writer(), minerShares...)
+		err = c.shares.InsertFinalMinerShares(minerShares...)
 		if err != nil {
 			return err
 		}
-		err = tsdb.InsertFinalWorkerShares(c.tsdb.Writer(), workerShares...)
+		err = c.shares.InsertFinalWorkerShares(workerShares...)
 		if err != nil {
 			return err
 		}
 
 		// finalize averages after updated statistics
-		globalAvg, minerAvg, workerAvg, err := getInitialShareAverages(c.tsdb.Reader(),
+		globalAvg, minerAvg, workerAvg, err := getInitialShareAverages(c.shares,
 			endTime, chain, rollupPeriod)
 		if err != nil {
 			return err
@@ -361,15 +357,15 @@ func (c *Client) finalizeShares(chain string, endTime time.Time) error {
 
 		}
 
-		err = tsdb.InsertFinalGlobalShares(c.tsdb.Writer(), globalShares...)
+		err = c.shares.InsertFinalGlobalShares(globalShares...)
 		if err != nil {
 			return err
 		}
-		err = tsdb.InsertFinalMinerShares(c.tsdb.Writer(), minerShares...)
+		err = c.shares.InsertFinalMinerShares(minerShares...)
 		if err != nil {
 			return err
 		}
-		err = tsdb.InsertFinalWorkerShares(c.tsdb.Writer(), workerShares...)
+		err = c.shares.InsertFinalWorkerShares(workerShares...)
 		if err != nil {
 			return err
 		}
@@ -381,17 +377,17 @@ func (c *Client) finalizeShares(chain string, endTime time.Time) error {
 func (c *Client) truncateShares(chain string, endTime time.Time) error {
 	for _, rollupPeriod := range append([]types.PeriodType{sharePeriod}, shareRollupPeriods...) {
 		timestamp := endTime.Add(rollupPeriod.Retention() * -1)
-		err := tsdb.DeleteGlobalSharesBeforeEndTime(c.tsdb.Writer(),
+		err := c.shares.DeleteGlobalSharesBeforeEndTime(
 			timestamp, chain, int(rollupPeriod))
 		if err != nil {
 			return err
 		}
-		err = tsdb.DeleteMinerSharesBeforeEndTime(c.tsdb.Writer(),
+		err = c.shares.DeleteMinerSharesBeforeEndTime(
 			timestamp, chain, int(rollupPeriod))
 		if err != nil {
 			return err
 		}
-		err = tsdb.DeleteWorkerSharesBeforeEndTime(c.tsdb.Writer(),
+		err = c.shares.DeleteWorkerSharesBeforeEndTime(
 			timestamp, chain, int(rollupPeriod))
 		if err != nil {
 			return err
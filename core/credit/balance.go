@@ -0,0 +1,105 @@
+package credit
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/common"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+)
+
+// AdjustBalance credits (or, for a negative delta, debits) the mature, unpaid balance of a
+// miner. unlike everything else that touches balances, the adjustment is not backed by a
+// round, so the miner balance sum of the wallet check moves by delta. it is meant to
+// reconcile a wallet check mismatch (e.g. after a lost payout or a manual database edit),
+// a positive delta that doesn't correct a mismatch has to be funded from the pool wallet.
+func AdjustBalance(store pooldb.Store, chain string, minerID uint64, delta *big.Int) error {
+	if delta == nil || delta.Sign() == 0 {
+		return fmt.Errorf("adjustment must be non-zero")
+	}
+
+	miner, err := store.Miners().GetMiner(minerID)
+	if err != nil {
+		return err
+	} else if miner == nil {
+		return fmt.Errorf("unable to find miner %d", minerID)
+	} else if miner.ChainID != chain {
+		return fmt.Errorf("miner %d is not paid out in %s", minerID, chain)
+	}
+
+	if delta.Sign() < 0 {
+		heldMiners, err := store.Miners().GetMinersWithHeldBalanceByChain(chain)
+		if err != nil {
+			return err
+		}
+
+		for _, heldMiner := range heldMiners {
+			if heldMiner.ID == minerID {
+				return fmt.Errorf("miner %d has a held balance", minerID)
+			}
+		}
+	}
+
+	value := new(big.Int).Abs(delta)
+	balanceSum := &pooldb.BalanceSum{
+		MinerID: minerID,
+		ChainID: chain,
+
+		MatureValue: dbcl.NullBigInt{Valid: true, BigInt: value},
+	}
+
+	return store.Transact(func(tx pooldb.Store) error {
+		if delta.Sign() > 0 {
+			adjustmentOutput := &pooldb.BalanceOutput{
+				ChainID: chain,
+				MinerID: minerID,
+
+				Value:        dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).Set(value)},
+				PoolFees:     dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+				ExchangeFees: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+				Mature:       true,
+			}
+
+			err := tx.Balances().InsertBalanceOutputs(adjustmentOutput)
+			if err != nil {
+				return err
+			}
+
+			return tx.Balances().InsertAddBalanceSums(balanceSum)
+		}
+
+		balanceOutputs, err := tx.Balances().GetUnpaidBalanceOutputsByMiner(minerID, chain)
+		if err != nil {
+			return err
+		}
+
+		// subtract the value from the miner's balance outputs
+		remainder := new(big.Int).Set(value)
+		for _, balanceOutput := range balanceOutputs {
+			if remainder.Cmp(common.Big0) == 0 {
+				break
+			} else if balanceOutput.Spent || balanceOutput.OutMergeTransactionID != nil {
+				continue
+			}
+
+			deduction := new(big.Int).Set(remainder)
+			if deduction.Cmp(balanceOutput.Value.BigInt) > 0 {
+				deduction.Set(balanceOutput.Value.BigInt)
+			}
+
+			balanceOutput.Value.BigInt.Sub(balanceOutput.Value.BigInt, deduction)
+			remainder.Sub(remainder, deduction)
+			err := tx.Balances().UpdateBalanceOutput(balanceOutput, []string{"value"})
+			if err != nil {
+				return err
+			}
+		}
+
+		if remainder.Cmp(common.Big0) > 0 {
+			return fmt.Errorf("insufficient balance for miner %d: short by %s", minerID, remainder)
+		}
+
+		return tx.Balances().InsertSubtractBalanceSums(balanceSum)
+	})
+}
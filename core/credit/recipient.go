@@ -0,0 +1,60 @@
+package credit
+
+import (
+	"fmt"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/types"
+)
+
+// SetRecipients replaces the pool fee recipients. the fee is split proportionally
+// between the recipients, so the percentages are relative and do not need to sum to 100.
+func SetRecipients(store pooldb.Store, percentIdx map[uint64]uint64) error {
+	if len(percentIdx) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+
+	minerIDs := make([]uint64, 0, len(percentIdx))
+	for minerID, percent := range percentIdx {
+		if percent == 0 {
+			return fmt.Errorf("fee percent for recipient %d must be positive", minerID)
+		}
+		minerIDs = append(minerIDs, minerID)
+	}
+
+	miners, err := store.Miners().GetMiners(minerIDs)
+	if err != nil {
+		return err
+	} else if len(miners) != len(minerIDs) {
+		return fmt.Errorf("unable to find %d recipients", len(minerIDs)-len(miners))
+	}
+
+	recipients, err := store.Miners().GetRecipients()
+	if err != nil {
+		return err
+	}
+
+	return store.Transact(func(tx pooldb.Store) error {
+		for _, recipient := range recipients {
+			if _, ok := percentIdx[recipient.ID]; ok {
+				continue
+			}
+
+			recipient.RecipientFeePercent = nil
+			err := tx.Miners().UpdateMiner(recipient, []string{"recipient_fee_percent"})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, miner := range miners {
+			miner.RecipientFeePercent = types.Uint64Ptr(percentIdx[miner.ID])
+			err := tx.Miners().UpdateMiner(miner, []string{"recipient_fee_percent"})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -11,31 +11,98 @@ import (
 	"github.com/magicpool-co/pool/types"
 )
 
-func CreditRound(pooldbClient *dbcl.Client, round *pooldb.Round, shares []*pooldb.Share) error {
-	// create a miner index of proportional share values
+// roundCredit holds the balance inputs and sums for a single round distribution.
+type roundCredit struct {
+	pendingInputs   []*pooldb.BalanceInput
+	completedInputs []*pooldb.BalanceInput
+	balanceSums     []*pooldb.BalanceSum
+	recipients      []*pooldb.RoundRecipient
+}
+
+func CreditRound(
+	store pooldb.Store,
+	round *pooldb.Round,
+	shares []*pooldb.Share,
+	feeBasisPoints uint64,
+) error {
+	credit, err := calculateRoundCredit(store, round, shares, feeBasisPoints)
+	if err != nil {
+		return err
+	}
+
+	return store.Transact(func(tx pooldb.Store) error {
+		return writeRoundCredit(tx, round, credit)
+	})
+}
+
+func calculateRoundCredit(
+	store pooldb.Store,
+	round *pooldb.Round,
+	shares []*pooldb.Share,
+	feeBasisPoints uint64,
+) (*roundCredit, error) {
+	// exclude miners flagged for block withholding or share manipulation (solo rounds
+	// only have a single miner, so there is nobody to redistribute the shares to)
+	excludedIdx := make(map[uint64]bool)
+	if !round.Solo && !round.SelfPaid {
+		excludedAudits, err := store.Miners().GetExcludedMinerShareAuditsByChain(round.ChainID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, excludedAudit := range excludedAudits {
+			excludedIdx[excludedAudit.MinerID] = true
+		}
+	}
+
+	// create a miner index of proportional share values (self-paid rounds already paid
+	// the miner in the coinbase, the round value is only the fee output)
 	minerIdx := make(map[uint64]uint64)
 	for _, share := range shares {
-		minerIdx[share.MinerID] += share.Count
+		if !excludedIdx[share.MinerID] && !round.SelfPaid {
+			minerIdx[share.MinerID] += share.Count
+		}
+	}
+
+	if len(minerIdx) == 0 && len(shares) > 0 && !round.SelfPaid {
+		return nil, fmt.Errorf("every miner is excluded for round %d", round.ID)
 	}
 
 	// fetch the recipients and create a recipient index of proportional fee values
-	recipients, err := pooldb.GetRecipients(pooldbClient.Reader())
+	recipients, err := store.Miners().GetRecipients()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	recipientIdx := make(map[uint64]uint64)
-	for _, recipient := range recipients {
+	roundRecipients := make([]*pooldb.RoundRecipient, len(recipients))
+	for i, recipient := range recipients {
 		if recipient.RecipientFeePercent == nil {
-			return fmt.Errorf("no recipient fee set for %d", recipient.ID)
+			return nil, fmt.Errorf("no recipient fee set for %d", recipient.ID)
 		}
 		recipientIdx[recipient.ID] += types.Uint64Value(recipient.RecipientFeePercent)
+
+		// store the recipients as they are now, so the round can
+		// still be checked after the recipients have changed
+		roundRecipients[i] = &pooldb.RoundRecipient{
+			RoundID:    round.ID,
+			MinerID:    recipient.ID,
+			FeePercent: types.Uint64Value(recipient.RecipientFeePercent),
+		}
 	}
 
-	// distribute the proceeds to miners and recipients
-	compoundValues, minerFees, err := accounting.CreditRound(round.Value.BigInt, minerIdx, recipientIdx)
+	// distribute the proceeds to miners and recipients, the fee output of self-paid
+	// rounds is split between the recipients the same way the pool fee is
+	var compoundValues, minerFees map[uint64]*big.Int
+	if round.SelfPaid {
+		compoundValues, err = accounting.SplitFee(round.Value.BigInt, recipientIdx)
+		minerFees = make(map[uint64]*big.Int)
+	} else {
+		compoundValues, minerFees, err = accounting.CreditRoundWithFee(round.Value.BigInt,
+			feeBasisPoints, minerIdx, recipientIdx)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// fetch miners and recipients to check their payout chain
@@ -44,9 +111,9 @@ func CreditRound(pooldbClient *dbcl.Client, round *pooldb.Round, shares []*poold
 		compoundIDs = append(compoundIDs, compoundID)
 	}
 
-	miners, err := pooldb.GetMiners(pooldbClient.Reader(), compoundIDs)
+	miners, err := store.Miners().GetMiners(compoundIDs)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// create compound index for miners and recipients
@@ -63,7 +130,7 @@ func CreditRound(pooldbClient *dbcl.Client, round *pooldb.Round, shares []*poold
 	for minerID, value := range compoundValues {
 		miner, ok := compoundIdx[minerID]
 		if !ok {
-			return fmt.Errorf("no miner found for %d", minerID)
+			return nil, fmt.Errorf("no miner found for %d", minerID)
 		} else if value == nil || value.Cmp(common.Big0) == 0 {
 			continue
 		}
@@ -117,22 +184,27 @@ func CreditRound(pooldbClient *dbcl.Client, round *pooldb.Round, shares []*poold
 	}
 
 	if len(compoundIdx) > 0 {
-		return fmt.Errorf("unable to find %d miners in idx", len(compoundIdx))
+		return nil, fmt.Errorf("unable to find %d miners in idx", len(compoundIdx))
 	} else if len(compoundValues) > 0 {
-		return fmt.Errorf("unable to find %d miners in values", len(compoundValues))
+		return nil, fmt.Errorf("unable to find %d miners in values", len(compoundValues))
 	} else if usedValue.Cmp(round.Value.BigInt) != 0 {
-		return fmt.Errorf("crediting mismatch: have %s, want %s", usedValue, round.Value.BigInt)
+		return nil, fmt.Errorf("crediting mismatch: have %s, want %s", usedValue, round.Value.BigInt)
 	}
 
-	tx, err := pooldbClient.Begin()
-	if err != nil {
-		return err
+	credit := &roundCredit{
+		pendingInputs:   pendingInputs,
+		completedInputs: completedInputs,
+		balanceSums:     balanceSums,
+		recipients:      roundRecipients,
 	}
-	defer tx.SafeRollback()
 
+	return credit, nil
+}
+
+func writeRoundCredit(tx pooldb.Store, round *pooldb.Round, credit *roundCredit) error {
 	// insert balance outputs for inputs that are already completed
 	// (they do not need to be exchanged)
-	for _, completedInput := range completedInputs {
+	for _, completedInput := range credit.completedInputs {
 		completedOutput := &pooldb.BalanceOutput{
 			ChainID: completedInput.OutChainID,
 			MinerID: completedInput.MinerID,
@@ -143,7 +215,7 @@ func CreditRound(pooldbClient *dbcl.Client, round *pooldb.Round, shares []*poold
 			Mature:       round.Mature,
 		}
 
-		outputID, err := pooldb.InsertBalanceOutput(tx, completedOutput)
+		outputID, err := tx.Balances().InsertBalanceOutput(completedOutput)
 		if err != nil {
 			return err
 		}
@@ -152,19 +224,174 @@ func CreditRound(pooldbClient *dbcl.Client, round *pooldb.Round, shares []*poold
 	}
 
 	// insert pending and completed inputs
-	if err := pooldb.InsertBalanceInputs(tx, pendingInputs...); err != nil {
+	if err := tx.Balances().InsertBalanceInputs(credit.pendingInputs...); err != nil {
+		return err
+	} else if err := tx.Balances().InsertBalanceInputs(credit.completedInputs...); err != nil {
 		return err
-	} else if err := pooldb.InsertBalanceInputs(tx, completedInputs...); err != nil {
+	} else if err := tx.Balances().InsertAddBalanceSums(credit.balanceSums...); err != nil {
 		return err
-	} else if err := pooldb.InsertAddBalanceSums(tx, balanceSums...); err != nil {
+	} else if err := tx.Rounds().InsertRoundRecipients(credit.recipients...); err != nil {
 		return err
 	}
 
 	// mark the round as spent
 	round.Spent = true
-	if pooldb.UpdateRound(tx, round, []string{"spent"}); err != nil {
+	return tx.Rounds().UpdateRound(round, []string{"spent"})
+}
+
+// uncreditRound reverses writeRoundCredit for a round. it only works as long as none of
+// the round's balance has moved yet: once an input is in an exchange batch or an output
+// has been paid, merged or spent, the round can no longer be safely uncredited.
+func uncreditRound(tx pooldb.Store, round *pooldb.Round) error {
+	balanceInputs, err := tx.Balances().GetBalanceInputsByRound(round.ID)
+	if err != nil {
+		return err
+	}
+
+	balanceOutputs, err := tx.Balances().GetBalanceOutputsByRound(round.ID)
+	if err != nil {
+		return err
+	}
+
+	inputValues := make(map[uint64]*big.Int)
+	balanceSums := make([]*pooldb.BalanceSum, len(balanceInputs))
+	for i, balanceInput := range balanceInputs {
+		if balanceInput.BatchID != nil {
+			return fmt.Errorf("round %d has inputs in batch %d", round.ID, types.Uint64Value(balanceInput.BatchID))
+		} else if balanceInput.BalanceOutputID != nil {
+			inputValues[types.Uint64Value(balanceInput.BalanceOutputID)] = balanceInput.Value.BigInt
+		}
+
+		balanceSum := &pooldb.BalanceSum{
+			MinerID: balanceInput.MinerID,
+			ChainID: balanceInput.ChainID,
+		}
+		if balanceInput.Mature {
+			balanceSum.MatureValue = balanceInput.Value
+		} else {
+			balanceSum.ImmatureValue = balanceInput.Value
+		}
+		balanceSums[i] = balanceSum
+	}
+
+	balanceOutputIDs := make([]uint64, len(balanceOutputs))
+	for i, balanceOutput := range balanceOutputs {
+		if balanceOutput.Spent || balanceOutput.OutPayoutID != nil || balanceOutput.OutMergeTransactionID != nil {
+			return fmt.Errorf("round %d has paid output %d", round.ID, balanceOutput.ID)
+		} else if inputValue, ok := inputValues[balanceOutput.ID]; !ok || inputValue.Cmp(balanceOutput.Value.BigInt) != 0 {
+			// outputs are only ever reduced by fees or transfers after the round was credited
+			return fmt.Errorf("round %d has modified output %d", round.ID, balanceOutput.ID)
+		}
+		balanceOutputIDs[i] = balanceOutput.ID
+	}
+
+	if err := tx.Balances().DeleteBalanceInputsByRound(round.ID); err != nil {
+		return err
+	} else if err := tx.Balances().DeleteBalanceOutputsByID(balanceOutputIDs...); err != nil {
+		return err
+	} else if err := tx.Balances().InsertSubtractBalanceSums(balanceSums...); err != nil {
+		return err
+	} else if err := tx.Rounds().DeleteRoundRecipientsByRound(round.ID); err != nil {
 		return err
 	}
 
-	return tx.SafeCommit()
+	round.Spent = false
+	return tx.Rounds().UpdateRound(round, []string{"spent"})
+}
+
+// RecreditRound removes the existing distribution of a round (if there is one) and
+// credits it again with the current exclusions and recipients, in a single tx. with a
+// store bound to a tx owned by the caller (pooldb.NewTxStore), the tx is left open so
+// the result can be inspected before it is committed.
+func RecreditRound(
+	store pooldb.Store,
+	round *pooldb.Round,
+	shares []*pooldb.Share,
+	feeBasisPoints uint64,
+) error {
+	if round.Pending || round.Orphan {
+		return fmt.Errorf("round %d is not creditable", round.ID)
+	} else if round.Held {
+		return fmt.Errorf("round %d is held", round.ID)
+	}
+
+	return store.Transact(func(tx pooldb.Store) error {
+		credit, err := calculateRoundCredit(tx, round, shares, feeBasisPoints)
+		if err != nil {
+			return err
+		}
+
+		if round.Spent {
+			err = uncreditRound(tx, round)
+			if err != nil {
+				return err
+			}
+		}
+
+		return writeRoundCredit(tx, round, credit)
+	})
+}
+
+// OrphanRound marks a round as orphaned after it has been credited, removing its
+// distribution. if the round is already mature, the coinbase UTXOs are deactivated
+// as well so the wallet and miner balances still match.
+func OrphanRound(store pooldb.Store, round *pooldb.Round) error {
+	if round.Pending {
+		return fmt.Errorf("round %d is still pending", round.ID)
+	} else if round.Orphan {
+		return fmt.Errorf("round %d is already an orphan", round.ID)
+	}
+
+	return store.Transact(func(tx pooldb.Store) error {
+		if round.Spent {
+			err := uncreditRound(tx, round)
+			if err != nil {
+				return err
+			}
+		}
+
+		if round.Mature {
+			// account based chains use the block hash as the txid
+			txids := []string{round.Hash}
+			if round.CoinbaseTxID != nil {
+				txids = append(txids, types.StringValue(round.CoinbaseTxID))
+			}
+
+			utxos, err := tx.Payouts().GetUTXOsByTxIDs(round.ChainID, txids)
+			if err != nil {
+				return err
+			} else if len(utxos) == 0 {
+				return fmt.Errorf("no utxos found for round %d", round.ID)
+			}
+
+			utxoSum := new(big.Int)
+			for _, utxo := range utxos {
+				if utxo.Spent || utxo.TransactionID != nil {
+					return fmt.Errorf("round %d has spent utxo %d", round.ID, utxo.ID)
+				} else if utxo.Active {
+					utxoSum.Add(utxoSum, utxo.Value.BigInt)
+				}
+			}
+
+			if utxoSum.Cmp(round.Value.BigInt) != 0 {
+				return fmt.Errorf("utxo mismatch for round %d: have %s, want %s", round.ID, utxoSum, round.Value.BigInt)
+			}
+
+			for _, utxo := range utxos {
+				utxo.Active = false
+				err = tx.Payouts().UpdateUTXO(utxo, []string{"active"})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		round.Orphan = true
+		round.Mature = false
+		round.Held = false
+		round.Spent = false
+		cols := []string{"orphan", "mature", "held", "spent"}
+
+		return tx.Rounds().UpdateRound(round, cols)
+	})
 }
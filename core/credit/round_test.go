@@ -0,0 +1,275 @@
+package credit
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+const testFeeBasisPoints = 100
+
+// newTestRound creates a store with two miners with equal shares and a single
+// recipient, along with an uncredited ETC round worth 10,000.
+func newTestRound(t *testing.T, mature bool) (*pooldb.MemoryStore, *pooldb.Round, []*pooldb.Share) {
+	store := pooldb.NewMemoryStore()
+	for _, address := range []string{"0x01", "0x02", "0x03"} {
+		_, err := store.Miners().InsertMiner(&pooldb.Miner{ChainID: "ETC", Address: address})
+		if err != nil {
+			t.Fatalf("failed to insert miner: %v", err)
+		}
+	}
+
+	recipient := &pooldb.Miner{ID: 3, RecipientFeePercent: types.Uint64Ptr(100)}
+	err := store.Miners().UpdateMiner(recipient, []string{"recipient_fee_percent"})
+	if err != nil {
+		t.Fatalf("failed to set recipient: %v", err)
+	}
+
+	round := &pooldb.Round{
+		ChainID: "ETC",
+		Height:  100,
+		Hash:    "0xabc",
+		Value:   dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(10_000)},
+		Mature:  mature,
+	}
+	round.ID, err = store.Rounds().InsertRound(round)
+	if err != nil {
+		t.Fatalf("failed to insert round: %v", err)
+	}
+
+	shares := []*pooldb.Share{
+		&pooldb.Share{RoundID: round.ID, MinerID: 1, Count: 10},
+		&pooldb.Share{RoundID: round.ID, MinerID: 2, Count: 10},
+	}
+
+	return store, round, shares
+}
+
+func getBalanceSumIdx(t *testing.T, store pooldb.Store, mature bool) map[uint64]uint64 {
+	balanceSums, err := store.Balances().GetBalanceSumsByChain("ETC")
+	if err != nil {
+		t.Fatalf("failed to fetch balance sums: %v", err)
+	}
+
+	balanceSumIdx := make(map[uint64]uint64)
+	for _, balanceSum := range balanceSums {
+		value := balanceSum.ImmatureValue
+		if mature {
+			value = balanceSum.MatureValue
+		}
+
+		if value.Valid {
+			balanceSumIdx[balanceSum.MinerID] = value.BigInt.Uint64()
+		}
+	}
+
+	return balanceSumIdx
+}
+
+func checkBalanceSums(t *testing.T, store pooldb.Store, mature bool, expected map[uint64]uint64) {
+	balanceSumIdx := getBalanceSumIdx(t, store, mature)
+	for minerID, value := range expected {
+		if balanceSumIdx[minerID] != value {
+			t.Errorf("balance sum mismatch for miner %d: have %d, want %d", minerID, balanceSumIdx[minerID], value)
+		}
+	}
+}
+
+func TestCreditRound(t *testing.T) {
+	store, round, shares := newTestRound(t, false)
+
+	err := CreditRound(store, round, shares, testFeeBasisPoints)
+	if err != nil {
+		t.Fatalf("failed to credit round: %v", err)
+	}
+
+	checkBalanceSums(t, store, false, map[uint64]uint64{1: 4_950, 2: 4_950, 3: 100})
+
+	balanceInputs, err := store.Balances().GetBalanceInputsByRound(round.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch balance inputs: %v", err)
+	} else if len(balanceInputs) != 3 {
+		t.Errorf("balance input length mismatch: have %d, want 3", len(balanceInputs))
+	}
+
+	storedRound, err := store.Rounds().GetRound(round.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch round: %v", err)
+	} else if !storedRound.Spent {
+		t.Errorf("round not marked as spent")
+	}
+
+	roundRecipients, err := store.Rounds().GetRoundRecipients(round.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch round recipients: %v", err)
+	} else if len(roundRecipients) != 1 {
+		t.Fatalf("round recipient length mismatch: have %d, want 1", len(roundRecipients))
+	} else if roundRecipients[0].MinerID != 3 || roundRecipients[0].FeePercent != 100 {
+		t.Errorf("round recipient mismatch: have %+v", roundRecipients[0])
+	}
+}
+
+func TestCreditSelfPaidRound(t *testing.T) {
+	store, round, shares := newTestRound(t, false)
+
+	// the round value of a self-paid round is only the fee output
+	round.SelfPaid = true
+	round.Value = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(100)}
+	err := CreditRound(store, round, shares[:1], testFeeBasisPoints)
+	if err != nil {
+		t.Fatalf("failed to credit round: %v", err)
+	}
+
+	checkBalanceSums(t, store, false, map[uint64]uint64{1: 0, 2: 0, 3: 100})
+
+	balanceInputs, err := store.Balances().GetBalanceInputsByRound(round.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch balance inputs: %v", err)
+	} else if len(balanceInputs) != 1 {
+		t.Fatalf("balance input length mismatch: have %d, want 1", len(balanceInputs))
+	} else if balanceInputs[0].MinerID != 3 || balanceInputs[0].Value.BigInt.Uint64() != 100 {
+		t.Errorf("balance input mismatch: have %+v", balanceInputs[0])
+	}
+
+	storedRound, err := store.Rounds().GetRound(round.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch round: %v", err)
+	} else if !storedRound.Spent {
+		t.Errorf("round not marked as spent")
+	}
+
+	err = OrphanRound(store, round)
+	if err != nil {
+		t.Fatalf("failed to orphan round: %v", err)
+	}
+
+	checkBalanceSums(t, store, false, map[uint64]uint64{3: 0})
+}
+
+func TestRecreditRound(t *testing.T) {
+	store, round, shares := newTestRound(t, false)
+
+	err := CreditRound(store, round, shares, testFeeBasisPoints)
+	if err != nil {
+		t.Fatalf("failed to credit round: %v", err)
+	}
+
+	store.SetMinerShareAudit(&pooldb.MinerShareAudit{MinerID: 2, ChainID: "ETC", Excluded: true})
+
+	err = RecreditRound(store, round, shares, testFeeBasisPoints)
+	if err != nil {
+		t.Fatalf("failed to recredit round: %v", err)
+	}
+
+	checkBalanceSums(t, store, false, map[uint64]uint64{1: 9_900, 2: 0, 3: 100})
+
+	balanceOutputs, err := store.Balances().GetBalanceOutputsByRound(round.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch balance outputs: %v", err)
+	} else if len(balanceOutputs) != 2 {
+		t.Errorf("balance output length mismatch: have %d, want 2", len(balanceOutputs))
+	}
+
+	// every miner being excluded should fail and leave the credit untouched
+	store.SetMinerShareAudit(&pooldb.MinerShareAudit{MinerID: 1, ChainID: "ETC", Excluded: true})
+
+	err = RecreditRound(store, round, shares, testFeeBasisPoints)
+	if err == nil {
+		t.Fatalf("expected recredit error")
+	}
+
+	checkBalanceSums(t, store, false, map[uint64]uint64{1: 9_900, 2: 0, 3: 100})
+}
+
+func TestOrphanRound(t *testing.T) {
+	store, round, shares := newTestRound(t, false)
+
+	err := CreditRound(store, round, shares, testFeeBasisPoints)
+	if err != nil {
+		t.Fatalf("failed to credit round: %v", err)
+	}
+
+	err = OrphanRound(store, round)
+	if err != nil {
+		t.Fatalf("failed to orphan round: %v", err)
+	}
+
+	checkBalanceSums(t, store, false, map[uint64]uint64{1: 0, 2: 0, 3: 0})
+
+	balanceInputs, err := store.Balances().GetBalanceInputsByRound(round.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch balance inputs: %v", err)
+	} else if len(balanceInputs) != 0 {
+		t.Errorf("balance input length mismatch: have %d, want 0", len(balanceInputs))
+	}
+
+	storedRound, err := store.Rounds().GetRound(round.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch round: %v", err)
+	} else if !storedRound.Orphan || storedRound.Spent {
+		t.Errorf("round state mismatch: have orphan %t spent %t", storedRound.Orphan, storedRound.Spent)
+	}
+
+	roundRecipients, err := store.Rounds().GetRoundRecipients(round.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch round recipients: %v", err)
+	} else if len(roundRecipients) != 0 {
+		t.Errorf("round recipient length mismatch: have %d, want 0", len(roundRecipients))
+	}
+
+	if err := OrphanRound(store, round); err == nil {
+		t.Errorf("expected error orphaning an orphan")
+	}
+}
+
+func TestAdjustBalance(t *testing.T) {
+	store, round, shares := newTestRound(t, true)
+
+	err := CreditRound(store, round, shares, testFeeBasisPoints)
+	if err != nil {
+		t.Fatalf("failed to credit round: %v", err)
+	}
+
+	err = AdjustBalance(store, "ETC", 1, new(big.Int).SetInt64(1_000))
+	if err != nil {
+		t.Fatalf("failed to credit balance: %v", err)
+	}
+
+	checkBalanceSums(t, store, true, map[uint64]uint64{1: 5_950, 2: 4_950, 3: 100})
+
+	err = AdjustBalance(store, "ETC", 2, new(big.Int).SetInt64(-2_000))
+	if err != nil {
+		t.Fatalf("failed to debit balance: %v", err)
+	}
+
+	checkBalanceSums(t, store, true, map[uint64]uint64{1: 5_950, 2: 2_950, 3: 100})
+
+	balanceOutputs, err := store.Balances().GetUnpaidBalanceOutputsByMiner(2, "ETC")
+	if err != nil {
+		t.Fatalf("failed to fetch balance outputs: %v", err)
+	}
+
+	var unpaid uint64
+	for _, balanceOutput := range balanceOutputs {
+		unpaid += balanceOutput.Value.BigInt.Uint64()
+	}
+	if unpaid != 2_950 {
+		t.Errorf("unpaid balance mismatch: have %d, want 2950", unpaid)
+	}
+
+	err = AdjustBalance(store, "ETC", 2, new(big.Int).SetInt64(-10_000))
+	if err == nil {
+		t.Fatalf("expected insufficient balance error")
+	}
+
+	checkBalanceSums(t, store, true, map[uint64]uint64{1: 5_950, 2: 2_950, 3: 100})
+
+	if err := AdjustBalance(store, "ETC", 1, new(big.Int)); err == nil {
+		t.Errorf("expected error for a zero adjustment")
+	} else if err := AdjustBalance(store, "BTC", 1, new(big.Int).SetInt64(1)); err == nil {
+		t.Errorf("expected error for a different payout chain")
+	}
+}
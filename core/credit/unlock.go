@@ -4,102 +4,94 @@ import (
 	"fmt"
 
 	"github.com/magicpool-co/pool/internal/pooldb"
-	"github.com/magicpool-co/pool/pkg/dbcl"
 	"github.com/magicpool-co/pool/types"
 )
 
-func matureRound(node types.MiningNode, pooldbClient *dbcl.Client, round *pooldb.Round) error {
-	tx, err := pooldbClient.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.SafeRollback()
-
+func matureRound(node types.MiningNode, store pooldb.Store, round *pooldb.Round) error {
 	utxos, err := node.MatureRound(round)
 	if err != nil {
 		return err
 	}
 
-	cols := []string{"uncle", "orphan", "pending", "mature", "spent", "height",
-		"epoch_height", "uncle_height", "hash", "coinbase_txid", "value", "created_at"}
-	err = pooldb.UpdateRound(tx, round, cols)
-	if err != nil {
-		return err
-	}
+	return store.Transact(func(tx pooldb.Store) error {
+		cols := []string{"uncle", "orphan", "pending", "mature", "spent", "height",
+			"epoch_height", "uncle_height", "hash", "coinbase_txid", "value", "tx_fees", "created_at"}
+		err := tx.Rounds().UpdateRound(round, cols)
+		if err != nil {
+			return err
+		}
+
+		for _, utxo := range utxos {
+			err = tx.Payouts().InsertUTXOs(utxo)
+			if err != nil {
+				return fmt.Errorf("failed on round: %d: %s: %v", round.ID, utxo.TxID, err)
+			}
+		}
 
-	for _, utxo := range utxos {
-		err = pooldb.InsertUTXOs(tx, utxo)
+		// we can use only balance inputs, since the balance output sum will be identical
+		// to the balance input sum for the given round
+		balanceInputs, err := tx.Balances().GetBalanceInputsByRound(round.ID)
 		if err != nil {
-			return fmt.Errorf("failed on round: %d: %s: %v", round.ID, utxo.TxID, err)
+			return err
 		}
-	}
 
-	// we can use only balance inputs, since the balance output sum will be identical
-	// to the balance input sum for the given round
-	balanceInputs, err := pooldb.GetBalanceInputsByRound(tx, round.ID)
-	if err != nil {
-		return err
-	}
+		// subtract the immature balance, add the mature balance
+		balanceSumsToAdd := make([]*pooldb.BalanceSum, len(balanceInputs))
+		balanceSumsToSubtract := make([]*pooldb.BalanceSum, len(balanceInputs))
+		balanceOutputIDs := make([]uint64, 0)
+		for i, balanceInput := range balanceInputs {
+			// if the round is marked as immature, this means it is an orphan, so
+			// we just want to subtract the balance inputs and ignore the rest
+			if round.Mature && !round.Orphan {
+				balanceSumsToAdd[i] = &pooldb.BalanceSum{
+					MinerID: balanceInput.MinerID,
+					ChainID: balanceInput.ChainID,
+
+					MatureValue: balanceInput.Value,
+				}
+			}
 
-	// subtract the immature balance, add the mature balance
-	balanceSumsToAdd := make([]*pooldb.BalanceSum, len(balanceInputs))
-	balanceSumsToSubtract := make([]*pooldb.BalanceSum, len(balanceInputs))
-	balanceOutputIDs := make([]uint64, 0)
-	for i, balanceInput := range balanceInputs {
-		// if the round is marked as immature, this means it is an orphan, so
-		// we just want to subtract the balance inputs and ignore the rest
-		if round.Mature && !round.Orphan {
-			balanceSumsToAdd[i] = &pooldb.BalanceSum{
+			balanceSumsToSubtract[i] = &pooldb.BalanceSum{
 				MinerID: balanceInput.MinerID,
 				ChainID: balanceInput.ChainID,
 
-				MatureValue: balanceInput.Value,
+				ImmatureValue: balanceInput.Value,
 			}
-		}
 
-		balanceSumsToSubtract[i] = &pooldb.BalanceSum{
-			MinerID: balanceInput.MinerID,
-			ChainID: balanceInput.ChainID,
-
-			ImmatureValue: balanceInput.Value,
+			if balanceInput.BalanceOutputID != nil {
+				balanceOutputIDs = append(balanceOutputIDs, types.Uint64Value(balanceInput.BalanceOutputID))
+			}
 		}
 
-		if balanceInput.BalanceOutputID != nil {
-			balanceOutputIDs = append(balanceOutputIDs, types.Uint64Value(balanceInput.BalanceOutputID))
+		if round.Orphan {
+			if err := tx.Balances().DeleteBalanceInputsByRound(round.ID); err != nil {
+				return err
+			} else if err := tx.Balances().DeleteBalanceOutputsByID(balanceOutputIDs...); err != nil {
+				return err
+			}
+		} else if round.Mature {
+			if err := tx.Balances().UpdateBalanceInputsSetMatureByRound(round.ID); err != nil {
+				return err
+			} else if err := tx.Balances().UpdateBalanceOutputsSetMatureByRound(round.ID); err != nil {
+				return err
+			}
 		}
-	}
 
-	if round.Orphan {
-		if err := pooldb.DeleteBalanceInputsByRound(tx, round.ID); err != nil {
-			return err
-		} else if err := pooldb.DeleteBalanceOutputsByID(tx, balanceOutputIDs...); err != nil {
+		if err := tx.Balances().InsertAddBalanceSums(balanceSumsToAdd...); err != nil {
 			return err
 		}
-	} else if round.Mature {
-		if err := pooldb.UpdateBalanceInputsSetMatureByRound(tx, round.ID); err != nil {
-			return err
-		} else if err := pooldb.UpdateBalanceOutputsSetMatureByRound(tx, round.ID); err != nil {
-			return err
-		}
-	}
-
-	if pooldb.InsertAddBalanceSums(tx, balanceSumsToAdd...); err != nil {
-		return err
-	} else if pooldb.InsertSubtractBalanceSums(tx, balanceSumsToSubtract...); err != nil {
-		return err
-	}
 
-	return tx.SafeCommit()
+		return tx.Balances().InsertSubtractBalanceSums(balanceSumsToSubtract...)
+	})
 }
 
-func UnlockRounds(node types.MiningNode, pooldbClient *dbcl.Client) error {
+func UnlockRounds(node types.MiningNode, store pooldb.Store) error {
 	height, _, err := node.GetStatus()
 	if err != nil {
 		return err
 	}
 
-	pendingRounds, err := pooldb.GetPendingRoundsByChain(pooldbClient.Reader(),
-		node.Chain(), height-node.GetImmatureDepth())
+	pendingRounds, err := store.Rounds().GetPendingRoundsByChain(node.Chain(), height-node.GetImmatureDepth())
 	if err != nil {
 		return err
 	}
@@ -112,22 +104,21 @@ func UnlockRounds(node types.MiningNode, pooldbClient *dbcl.Client) error {
 
 		cols := []string{
 			"uncle", "orphan", "pending", "mature", "spent", "height", "epoch_height",
-			"uncle_height", "hash", "coinbase_txid", "value", "created_at",
+			"uncle_height", "hash", "coinbase_txid", "value", "tx_fees", "created_at",
 		}
-		err = pooldb.UpdateRound(pooldbClient.Writer(), round, cols)
+		err = store.Rounds().UpdateRound(round, cols)
 		if err != nil {
 			return err
 		}
 	}
 
-	immatureRounds, err := pooldb.GetImmatureRoundsByChain(pooldbClient.Reader(),
-		node.Chain(), height-node.GetMatureDepth())
+	immatureRounds, err := store.Rounds().GetImmatureRoundsByChain(node.Chain(), height-node.GetMatureDepth())
 	if err != nil {
 		return err
 	}
 
 	for _, round := range immatureRounds {
-		err := matureRound(node, pooldbClient, round)
+		err := matureRound(node, store, round)
 		if err != nil {
 			return err
 		}
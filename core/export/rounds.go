@@ -0,0 +1,55 @@
+package export
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/magicpool-co/pool/core/stats"
+)
+
+var roundShareCols = []string{
+	"Miner",
+	"Recipient",
+	"Shares",
+	"Share Percentage",
+	"Value",
+	"Raw Value",
+	"Pool Fees",
+	"Raw Pool Fees",
+}
+
+func generateRoundShareRow(share *stats.RoundShare) []string {
+	row := []string{
+		share.Miner,
+		formatBool(share.Recipient),
+		strconv.FormatUint(share.Shares, 10),
+		formatFloat64(share.SharePercentage.Value),
+		formatFloat64(share.Value.Value),
+		share.RawValue,
+		formatFloat64(share.PoolFees.Value),
+		share.RawPoolFees,
+	}
+
+	return row
+}
+
+func ExportRoundSharesAsCSV(shares []*stats.RoundShare) ([]byte, error) {
+	rows := make([][]string, len(shares))
+	for i, share := range shares {
+		rows[i] = generateRoundShareRow(share)
+	}
+
+	return writeAsCSV(roundShareCols, rows)
+}
+
+func ExportRoundSharesAsJSON(details *stats.RoundDetails, shares []*stats.RoundShare) ([]byte, error) {
+	report := struct {
+		*stats.RoundDetails
+		Shares []*stats.RoundShare `json:"shares"`
+	}{
+		RoundDetails: details,
+		Shares:       shares,
+	}
+
+	return json.Marshal(report)
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/magicpool-co/pool/core/bank"
 	"github.com/magicpool-co/pool/core/mailer"
+	"github.com/magicpool-co/pool/internal/accounting"
 	"github.com/magicpool-co/pool/internal/pooldb"
 	"github.com/magicpool-co/pool/internal/redis"
 	"github.com/magicpool-co/pool/internal/telegram"
@@ -19,11 +22,13 @@ import (
 )
 
 const (
-	maxBatchSize = 100
+	maxBatchSize      = 100
+	maxAccountTxCount = 15
 )
 
 type Client struct {
 	pooldb   *dbcl.Client
+	store    pooldb.Store
 	redis    *redis.Client
 	telegram *telegram.Client
 	bank     *bank.Client
@@ -38,6 +43,7 @@ func New(
 ) *Client {
 	client := &Client{
 		pooldb:   pooldbClient,
+		store:    pooldb.NewStore(pooldbClient),
 		redis:    redisClient,
 		telegram: telegramClient,
 		bank:     bank.New(pooldbClient, redisClient, telegramClient),
@@ -54,19 +60,15 @@ func (c *Client) InitiatePayouts(node types.PayoutNode) error {
 	}
 	defer dbTx.SafeRollback()
 
+	// the outgoing txs are prepared by the bank within the same transaction
+	store := pooldb.NewTxStore(dbTx)
+
 	payoutBound, err := common.GetDefaultPayoutBounds(node.Chain())
 	if err != nil {
 		return err
 	}
 
-	payoutBoundStr := "1"
-	if node.Chain() == "ETH" {
-		payoutBoundStr = "2500000000000000"
-	} else if node.Chain() == "BTC" {
-		payoutBoundStr = "1000"
-	}
-
-	miners, err := pooldb.GetMinersWithBalanceAboveThresholdByChain(dbTx, node.Chain(), payoutBoundStr)
+	miners, scheduledIdx, err := getPayoutMiners(store, node.Chain(), payoutBound, time.Now())
 	if err != nil {
 		return err
 	} else if len(miners) == 0 {
@@ -75,34 +77,11 @@ func (c *Client) InitiatePayouts(node types.PayoutNode) error {
 
 	balanceOutputSums := make([]*pooldb.BalanceOutput, len(miners))
 	balanceOutputIdx := make(map[uint64][]*pooldb.BalanceOutput, len(miners))
+	earlyPayoutFeeIdx := make(map[uint64]*big.Int, len(miners))
 	for i, miner := range miners {
-		balanceOutputs, err := pooldb.GetUnpaidBalanceOutputsByMiner(dbTx, miner.ID, node.Chain())
+		balanceOutputs, err := store.Balances().GetUnpaidBalanceOutputsByMiner(miner.ID, node.Chain())
 		if err != nil {
 			return err
-		} else if len(balanceOutputs) == 0 {
-			return fmt.Errorf("no balance outputs found for miner %d", miner.ID)
-		}
-
-		valueSum, poolFeesSum := new(big.Int), new(big.Int)
-		exchangeFeesSum, txFeesSum := new(big.Int), new(big.Int)
-		balanceOutputIdx[miner.ID] = make([]*pooldb.BalanceOutput, 0)
-		for _, balanceOutput := range balanceOutputs {
-			if !balanceOutput.Value.Valid {
-				return fmt.Errorf("no value for balance output %d", balanceOutput.ID)
-			} else if !balanceOutput.PoolFees.Valid {
-				return fmt.Errorf("no pool fees for balance output %d", balanceOutput.ID)
-			} else if !balanceOutput.ExchangeFees.Valid {
-				return fmt.Errorf("no exchange fees for balance output %d", balanceOutput.ID)
-			}
-
-			valueSum.Add(valueSum, balanceOutput.Value.BigInt)
-			poolFeesSum.Add(poolFeesSum, balanceOutput.PoolFees.BigInt)
-			exchangeFeesSum.Add(exchangeFeesSum, balanceOutput.ExchangeFees.BigInt)
-			if balanceOutput.TxFees.Valid {
-				txFeesSum.Add(txFeesSum, balanceOutput.TxFees.BigInt)
-			}
-
-			balanceOutputIdx[miner.ID] = append(balanceOutputIdx[miner.ID], balanceOutput)
 		}
 
 		threshold := payoutBound.Default
@@ -110,25 +89,18 @@ func (c *Client) InitiatePayouts(node types.PayoutNode) error {
 			threshold = miner.Threshold.BigInt
 		}
 
-		if valueSum.Cmp(threshold) < 0 {
-			return fmt.Errorf("miner %d not actually above threshold: %s < %s",
-				miner.ID, valueSum, threshold)
-		}
-
-		balanceOutputSums[i] = &pooldb.BalanceOutput{
-			MinerID: miner.ID,
-			ChainID: node.Chain(),
-
-			Value:        dbcl.NullBigInt{Valid: true, BigInt: valueSum},
-			PoolFees:     dbcl.NullBigInt{Valid: true, BigInt: poolFeesSum},
-			ExchangeFees: dbcl.NullBigInt{Valid: true, BigInt: exchangeFeesSum},
-			TxFees:       dbcl.NullBigInt{Valid: true, BigInt: txFeesSum},
+		_, scheduled := scheduledIdx[miner.ID]
+		balanceOutputSums[i], earlyPayoutFeeIdx[miner.ID], err = sumBalanceOutputs(miner.ID,
+			balanceOutputs, threshold, payoutBound, scheduled)
+		if err != nil {
+			return err
 		}
+		balanceOutputIdx[miner.ID] = balanceOutputs
 	}
 
 	payouts := make([]*pooldb.Payout, len(balanceOutputSums))
 	for i, balanceOutput := range balanceOutputSums {
-		address, err := pooldb.GetMinerAddress(dbTx, balanceOutput.MinerID)
+		address, err := store.Miners().GetMinerAddress(balanceOutput.MinerID)
 		if err != nil {
 			return err
 		}
@@ -143,6 +115,7 @@ func (c *Client) InitiatePayouts(node types.PayoutNode) error {
 			PoolFees:     balanceOutput.PoolFees,
 			ExchangeFees: balanceOutput.ExchangeFees,
 			TxFees:       balanceOutput.TxFees,
+			EarlyFees:    dbcl.NullBigInt{Valid: true, BigInt: earlyPayoutFeeIdx[balanceOutput.MinerID]},
 
 			Pending: true,
 		}
@@ -156,55 +129,58 @@ func (c *Client) InitiatePayouts(node types.PayoutNode) error {
 
 	switch node.GetAccountingType() {
 	case types.AccountStructure:
-		if len(payouts) > 15 {
-			payouts = payouts[:15]
+		batch := true
+		if batchNode, ok := node.(types.BatchPayoutNode); ok {
+			batch, err = batchNode.ApproveBatchPayouts()
+			if err != nil {
+				return err
+			}
 		}
 
-		outputList := make([][]*types.TxOutput, len(payouts))
-		for i, payout := range payouts {
-			if !payout.Value.Valid {
-				return fmt.Errorf("no value for payout %d", payout.ID)
-			} else if !payout.FeeBalance.Valid {
-				return fmt.Errorf("no fee balance for payout %d", payout.ID)
-			}
+		// miners that are being refunded from a reverted tx are paid individually,
+		// since a batch including them would most likely revert again
+		individualIdx := make(map[uint64]bool)
+		for _, payout := range payouts {
+			for _, balanceOutput := range balanceOutputIdx[payout.MinerID] {
+				if balanceOutput.InPayoutID == nil {
+					continue
+				}
 
-			outputList[i] = []*types.TxOutput{
-				&types.TxOutput{
-					Address:    payout.Address,
-					Value:      payout.Value.BigInt,
-					FeeBalance: payout.FeeBalance.BigInt,
-				},
+				inPayout, err := store.Payouts().GetPayout(types.Uint64Value(balanceOutput.InPayoutID))
+				if err != nil {
+					return err
+				} else if inPayout != nil && inPayout.Failed {
+					individualIdx[payout.MinerID] = true
+				}
 			}
 		}
 
+		payouts, outputList, outputIdx, err := buildAccountOutputList(node, payouts, batch, individualIdx)
+		if err != nil {
+			return err
+		}
+
 		txs, err := c.bank.PrepareOutgoingTxs(dbTx, node, types.PayoutTx, outputList...)
 		if err != nil {
 			return err
-		} else if len(txs) != len(payouts) {
-			return fmt.Errorf("tx and payout count mismatch: %d and %d", len(txs), len(payouts))
+		} else if len(txs) != len(outputList) {
+			return fmt.Errorf("tx and output list count mismatch: %d and %d", len(txs), len(outputList))
 		}
 
 		for i, payout := range payouts {
-			if txs[i] == nil {
+			txIdx, outIdx := outputIdx[i][0], outputIdx[i][1]
+			if txs[txIdx] == nil {
 				continue
 			}
 
-			payout.TransactionID = types.Uint64Ptr(txs[i].ID)
-			payout.TxID = txs[i].TxID
-			payout.TxFees.BigInt.Add(payout.TxFees.BigInt, outputList[i][0].Fee)
-			payoutID, err := pooldb.InsertPayout(dbTx, payout)
+			payout.TransactionID = types.Uint64Ptr(txs[txIdx].ID)
+			payout.TxID = txs[txIdx].TxID
+			setPayoutOutput(payout, outputList[txIdx][outIdx])
+			err = recordPayout(store, payout, balanceOutputIdx[payout.MinerID], scheduledIdx[payout.MinerID])
 			if err != nil {
 				return err
 			}
 
-			for _, balanceOutput := range balanceOutputIdx[payout.MinerID] {
-				balanceOutput.OutPayoutID = types.Uint64Ptr(payoutID)
-				err = pooldb.UpdateBalanceOutput(dbTx, balanceOutput, []string{"out_payout_id"})
-				if err != nil {
-					return err
-				}
-			}
-
 			explorerURL := node.GetAddressExplorerURL(payout.Address)
 			floatValue := common.BigIntToFloat64(payout.Value.BigInt, node.GetUnits().Big())
 			c.telegram.NotifyInitiatePayout(payout.ID,
@@ -223,7 +199,7 @@ func (c *Client) InitiatePayouts(node types.PayoutNode) error {
 
 			outputs[i] = &types.TxOutput{
 				Address:  payout.Address,
-				Value:    payout.Value.BigInt,
+				Value:    new(big.Int).Set(payout.Value.BigInt),
 				SplitFee: true,
 			}
 		}
@@ -240,32 +216,24 @@ func (c *Client) InitiatePayouts(node types.PayoutNode) error {
 		}
 		tx := txs[0]
 
-		feeIdx := make(map[string]*big.Int)
+		outputIdx := make(map[string]*types.TxOutput)
 		for _, output := range outputs {
-			feeIdx[output.Address] = output.Fee
+			outputIdx[output.Address] = output
 		}
 
 		for _, payout := range payouts {
 			payout.TransactionID = types.Uint64Ptr(tx.ID)
 			payout.TxID = tx.TxID
-			payout.TxFees = dbcl.NullBigInt{Valid: true, BigInt: feeIdx[payout.Address]}
-			payoutID, err := pooldb.InsertPayout(dbTx, payout)
+			deductPayoutTxFee(payout, outputIdx[payout.Address])
+			err = recordPayout(store, payout, balanceOutputIdx[payout.MinerID], scheduledIdx[payout.MinerID])
 			if err != nil {
 				return err
 			}
 
-			for _, balanceOutput := range balanceOutputIdx[payout.MinerID] {
-				balanceOutput.OutPayoutID = types.Uint64Ptr(payoutID)
-				err = pooldb.UpdateBalanceOutput(dbTx, balanceOutput, []string{"out_payout_id"})
-				if err != nil {
-					return err
-				}
-			}
-
 			explorerURL := node.GetAddressExplorerURL(payout.Address)
 			floatValue := common.BigIntToFloat64(payout.Value.BigInt, node.GetUnits().Big())
 			if len(payouts) != maxBatchSize && false {
-				c.telegram.NotifyInitiatePayout(payoutID,
+				c.telegram.NotifyInitiatePayout(payout.ID,
 					payout.ChainID, payout.Address, explorerURL, floatValue)
 			}
 		}
@@ -276,115 +244,572 @@ func (c *Client) InitiatePayouts(node types.PayoutNode) error {
 	return dbTx.SafeCommit()
 }
 
-func (c *Client) finalizePayout(
-	node types.PayoutNode,
+// getPayoutMiners returns the miners to pay out on a chain: those above their threshold (or the default
+// payout bound) along with those that have a scheduled payout due or have requested a payout, regardless
+// of their threshold. the miners that were included because of their schedule or request are also indexed.
+func getPayoutMiners(
+	store pooldb.Store,
+	chain string,
+	payoutBound *common.PayoutBounds,
+	now time.Time,
+) ([]*pooldb.Miner, map[uint64]*pooldb.Miner, error) {
+	// miners without a threshold of their own use the default payout bound
+	miners, err := store.Miners().GetMinersWithBalanceAboveThresholdByChain(chain, payoutBound.Default.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scheduledMiners, err := store.Miners().GetMinersWithPayoutScheduleByChain(chain, payoutBound.Min.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	minerIdx := make(map[uint64]bool, len(miners))
+	for _, miner := range miners {
+		minerIdx[miner.ID] = true
+	}
+
+	scheduledIdx := make(map[uint64]*pooldb.Miner)
+	for _, miner := range scheduledMiners {
+		schedule := types.PayoutSchedule(miner.PayoutSchedule)
+		if !miner.PayoutRequested && !schedule.IsDue(miner.PayoutHour, miner.PayoutWeekday, miner.PayoutTime, miner.LastPayout, now) {
+			continue
+		}
+
+		scheduledIdx[miner.ID] = miner
+		if !minerIdx[miner.ID] {
+			minerIdx[miner.ID] = true
+			miners = append(miners, miner)
+		}
+	}
+
+	// skip miners with balances derived from rounds that are held after a reorg,
+	// regardless of whether or not the rest of their balance is unaffected
+	heldMiners, err := store.Miners().GetMinersWithHeldBalanceByChain(chain)
+	if err != nil {
+		return nil, nil, err
+	} else if len(heldMiners) > 0 {
+		heldIdx := make(map[uint64]bool, len(heldMiners))
+		for _, miner := range heldMiners {
+			heldIdx[miner.ID] = true
+		}
+
+		unheldMiners := make([]*pooldb.Miner, 0, len(miners))
+		for _, miner := range miners {
+			if !heldIdx[miner.ID] {
+				unheldMiners = append(unheldMiners, miner)
+			}
+		}
+		miners = unheldMiners
+	}
+
+	return miners, scheduledIdx, nil
+}
+
+// recordPayout inserts a payout that has been added to an outgoing tx, marks the balance
+// outputs it pays out and processes the schedule (or request) that included it, if any.
+func recordPayout(
+	dbTx pooldb.Store,
 	payout *pooldb.Payout,
-	miner, emailAddress string,
+	balanceOutputs []*pooldb.BalanceOutput,
+	scheduledMiner *pooldb.Miner,
 ) error {
-	if payout.TransactionID == nil {
-		return fmt.Errorf("no transaction id for payout %d", payout.ID)
+	payoutID, err := dbTx.Payouts().InsertPayout(payout)
+	if err != nil {
+		return err
 	}
+	payout.ID = payoutID
 
-	tx, err := pooldb.GetTransaction(c.pooldb.Reader(), types.Uint64Value(payout.TransactionID))
+	err = processScheduledPayout(dbTx, scheduledMiner)
 	if err != nil {
 		return err
-	} else if !tx.Spent || !tx.Confirmed {
-		if payout.Pending {
-			payout.Pending = false
-			err = pooldb.UpdatePayout(c.pooldb.Writer(), payout, []string{"pending"})
+	}
+
+	for _, balanceOutput := range balanceOutputs {
+		balanceOutput.OutPayoutID = types.Uint64Ptr(payoutID)
+		err = dbTx.Balances().UpdateBalanceOutput(balanceOutput, []string{"out_payout_id"})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildAccountOutputList groups the payouts into tx output lists for account-based chains. If batching
+// is enabled and the node supports it, all non-contract recipients (that aren't in individualIdx) are paid
+// in a single batch tx, which is always first, while the rest are paid individually. The payouts that were
+// included are returned, along with their [tx, output] indexes.
+func buildAccountOutputList(
+	node types.PayoutNode,
+	payouts []*pooldb.Payout,
+	batch bool,
+	individualIdx map[uint64]bool,
+) ([]*pooldb.Payout, [][]*types.TxOutput, [][2]int, error) {
+	for _, payout := range payouts {
+		if !payout.Value.Valid {
+			return nil, nil, nil, fmt.Errorf("no value for payout %d", payout.ID)
+		} else if !payout.FeeBalance.Valid {
+			return nil, nil, nil, fmt.Errorf("no fee balance for payout %d", payout.ID)
+		}
+	}
+
+	newOutput := func(payout *pooldb.Payout) *types.TxOutput {
+		return &types.TxOutput{
+			Address:    payout.Address,
+			Value:      new(big.Int).Set(payout.Value.BigInt),
+			FeeBalance: payout.FeeBalance.BigInt,
+		}
+	}
+
+	batchNode, ok := node.(types.BatchPayoutNode)
+	if !batch || !ok {
+		if len(payouts) > maxAccountTxCount {
+			payouts = payouts[:maxAccountTxCount]
+		}
+
+		outputList := make([][]*types.TxOutput, len(payouts))
+		outputIdx := make([][2]int, len(payouts))
+		for i, payout := range payouts {
+			outputList[i] = []*types.TxOutput{newOutput(payout)}
+			outputIdx[i] = [2]int{i, 0}
+		}
+
+		return payouts, outputList, outputIdx, nil
+	}
+
+	batchPayouts := make([]*pooldb.Payout, 0)
+	individualPayouts := make([]*pooldb.Payout, 0)
+	for _, payout := range payouts {
+		individual := individualIdx[payout.MinerID]
+		if !individual {
+			var err error
+			individual, err = batchNode.IsContractAddress(payout.Address)
 			if err != nil {
-				return err
+				return nil, nil, nil, err
 			}
 		}
 
+		if individual {
+			if len(individualPayouts) < maxAccountTxCount-1 {
+				individualPayouts = append(individualPayouts, payout)
+			}
+		} else if len(batchPayouts) < batchNode.GetBatchSize() {
+			batchPayouts = append(batchPayouts, payout)
+		}
+	}
+
+	includedPayouts := make([]*pooldb.Payout, 0, len(batchPayouts)+len(individualPayouts))
+	outputList := make([][]*types.TxOutput, 0, len(individualPayouts)+1)
+	outputIdx := make([][2]int, 0, len(batchPayouts)+len(individualPayouts))
+	if len(batchPayouts) > 0 {
+		batchOutputs := make([]*types.TxOutput, len(batchPayouts))
+		for i, payout := range batchPayouts {
+			batchOutputs[i] = newOutput(payout)
+			outputIdx = append(outputIdx, [2]int{0, i})
+		}
+
+		includedPayouts = append(includedPayouts, batchPayouts...)
+		outputList = append(outputList, batchOutputs)
+	}
+
+	for _, payout := range individualPayouts {
+		outputIdx = append(outputIdx, [2]int{len(outputList), 0})
+		includedPayouts = append(includedPayouts, payout)
+		outputList = append(outputList, []*types.TxOutput{newOutput(payout)})
+	}
+
+	return includedPayouts, outputList, outputIdx, nil
+}
+
+// sumBalanceOutputs sums a miner's unpaid balance outputs into the values of their payout. Scheduled
+// and requested payouts below the threshold are allowed, but are charged an early payout fee. The fee
+// is deducted from the value and added to the tx fees, so the payout's value and tx fees always add up
+// to the sum of its balance outputs (plus the tx fees already recorded on the balance outputs).
+func sumBalanceOutputs(
+	minerID uint64,
+	balanceOutputs []*pooldb.BalanceOutput,
+	threshold *big.Int,
+	payoutBound *common.PayoutBounds,
+	scheduled bool,
+) (*pooldb.BalanceOutput, *big.Int, error) {
+	if len(balanceOutputs) == 0 {
+		return nil, nil, fmt.Errorf("no balance outputs found for miner %d", minerID)
+	}
+
+	valueSum, poolFeesSum := new(big.Int), new(big.Int)
+	exchangeFeesSum, txFeesSum := new(big.Int), new(big.Int)
+	for _, balanceOutput := range balanceOutputs {
+		if !balanceOutput.Value.Valid {
+			return nil, nil, fmt.Errorf("no value for balance output %d", balanceOutput.ID)
+		} else if !balanceOutput.PoolFees.Valid {
+			return nil, nil, fmt.Errorf("no pool fees for balance output %d", balanceOutput.ID)
+		} else if !balanceOutput.ExchangeFees.Valid {
+			return nil, nil, fmt.Errorf("no exchange fees for balance output %d", balanceOutput.ID)
+		}
+
+		valueSum.Add(valueSum, balanceOutput.Value.BigInt)
+		poolFeesSum.Add(poolFeesSum, balanceOutput.PoolFees.BigInt)
+		exchangeFeesSum.Add(exchangeFeesSum, balanceOutput.ExchangeFees.BigInt)
+		if balanceOutput.TxFees.Valid {
+			txFeesSum.Add(txFeesSum, balanceOutput.TxFees.BigInt)
+		}
+	}
+
+	earlyPayoutFee := new(big.Int)
+	if valueSum.Cmp(threshold) < 0 {
+		if !scheduled {
+			return nil, nil, fmt.Errorf("miner %d not actually above threshold: %s < %s",
+				minerID, valueSum, threshold)
+		}
+
+		earlyPayoutFee = payoutBound.EarlyPayoutFee(valueSum)
+		valueSum.Sub(valueSum, earlyPayoutFee)
+		txFeesSum.Add(txFeesSum, earlyPayoutFee)
+	}
+
+	balanceOutputSum := &pooldb.BalanceOutput{
+		MinerID: minerID,
+		ChainID: balanceOutputs[0].ChainID,
+
+		Value:        dbcl.NullBigInt{Valid: true, BigInt: valueSum},
+		PoolFees:     dbcl.NullBigInt{Valid: true, BigInt: poolFeesSum},
+		ExchangeFees: dbcl.NullBigInt{Valid: true, BigInt: exchangeFeesSum},
+		TxFees:       dbcl.NullBigInt{Valid: true, BigInt: txFeesSum},
+	}
+
+	return balanceOutputSum, earlyPayoutFee, nil
+}
+
+// setPayoutOutput sets the payout's value to the value of its (account) tx output, from which the
+// tx builder deducted the network fee (except for tokens), and adds the output's fee to the tx fees.
+func setPayoutOutput(payout *pooldb.Payout, output *types.TxOutput) {
+	payout.Value = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).Set(output.Value)}
+	addPayoutTxFee(payout, output.Fee)
+}
+
+// addPayoutTxFee adds the network fee of a payout's tx output to the payout's tx fees.
+func addPayoutTxFee(payout *pooldb.Payout, fee *big.Int) {
+	if fee == nil {
+		return
+	} else if !payout.TxFees.Valid || payout.TxFees.BigInt == nil {
+		payout.TxFees = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)}
+	}
+
+	payout.TxFees.BigInt.Add(payout.TxFees.BigInt, fee)
+}
+
+// deductPayoutTxFee sets the payout's value to the value of its (utxo) tx output and adds the difference
+// to the payout's tx fees. The difference is used instead of the output's fee since the rounding remainder
+// of the network fee is deducted from the output values without being recorded in the output fees.
+func deductPayoutTxFee(payout *pooldb.Payout, output *types.TxOutput) {
+	if output == nil {
+		return
+	}
+
+	addPayoutTxFee(payout, new(big.Int).Sub(payout.Value.BigInt, output.Value))
+	payout.Value = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).Set(output.Value)}
+}
+
+// newEarlyPayoutFeeCredits splits an early payout fee between the fee recipients. The fee never
+// leaves the wallet, so it is credited to them as new mature balance outputs (as pool fees) to keep
+// the wallet and the balances in sync. The miner's balance outputs are left as is, since the fee
+// is already part of the payout's tx fees and the full balance is spent once the payout finalizes.
+func newEarlyPayoutFeeCredits(
+	chain string,
+	recipients []*pooldb.Miner,
+	earlyPayoutFee *big.Int,
+) ([]*pooldb.BalanceOutput, []*pooldb.BalanceSum, error) {
+	recipientIdx := make(map[uint64]uint64)
+	for _, recipient := range recipients {
+		if recipient.RecipientFeePercent == nil {
+			return nil, nil, fmt.Errorf("no recipient fee set for %d", recipient.ID)
+		}
+		recipientIdx[recipient.ID] += types.Uint64Value(recipient.RecipientFeePercent)
+	}
+
+	recipientValues, err := accounting.SplitFee(earlyPayoutFee, recipientIdx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recipientIDs := make([]uint64, 0, len(recipientValues))
+	for recipientID := range recipientValues {
+		recipientIDs = append(recipientIDs, recipientID)
+	}
+	sort.Slice(recipientIDs, func(i, j int) bool {
+		return recipientIDs[i] < recipientIDs[j]
+	})
+
+	balanceOutputs := make([]*pooldb.BalanceOutput, 0)
+	balanceSums := make([]*pooldb.BalanceSum, 0)
+	for _, recipientID := range recipientIDs {
+		value := recipientValues[recipientID]
+		if value.Cmp(common.Big0) <= 0 {
+			continue
+		}
+
+		balanceOutputs = append(balanceOutputs, &pooldb.BalanceOutput{
+			ChainID: chain,
+			MinerID: recipientID,
+
+			Value:        dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).Set(value)},
+			PoolFees:     dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).Set(value)},
+			ExchangeFees: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+			Mature:       true,
+		})
+
+		balanceSums = append(balanceSums, &pooldb.BalanceSum{
+			MinerID: recipientID,
+			ChainID: chain,
+
+			MatureValue: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).Set(value)},
+		})
+	}
+
+	return balanceOutputs, balanceSums, nil
+}
+
+// processScheduledPayout resets the payout request of a miner that was included because of their
+// payout schedule or request.
+func processScheduledPayout(dbTx pooldb.Store, miner *pooldb.Miner) error {
+	if miner == nil || !miner.PayoutRequested {
+		return nil
+	}
+
+	miner.PayoutRequested = false
+
+	return dbTx.Miners().UpdateMiner(miner, []string{"payout_requested"})
+}
+
+// creditEarlyPayoutFee credits the early payout fee (if there is one) of a confirmed payout to the fee
+// recipients. It is only credited once the payout confirms, since a failed payout refunds it to the miner.
+func creditEarlyPayoutFee(dbTx pooldb.Store, payout *pooldb.Payout) error {
+	if !payout.EarlyFees.Valid || payout.EarlyFees.BigInt.Cmp(common.Big0) <= 0 {
 		return nil
-	} else if !tx.Value.Valid {
-		return fmt.Errorf("no value for tx %s", tx.TxID)
-	} else if !tx.Fee.Valid {
-		return fmt.Errorf("no fee for tx %s", tx.TxID)
 	}
 
-	balanceOutputs, err := pooldb.GetBalanceOutputsByPayout(c.pooldb.Reader(), payout.ID)
+	recipients, err := dbTx.Miners().GetRecipients()
 	if err != nil {
 		return err
+	} else if len(recipients) == 0 {
+		return fmt.Errorf("no recipients found")
 	}
 
-	dbTx, err := c.pooldb.Begin()
+	balanceOutputs, balanceSums, err := newEarlyPayoutFeeCredits(payout.ChainID, recipients, payout.EarlyFees.BigInt)
 	if err != nil {
 		return err
 	}
-	defer dbTx.SafeRollback()
 
-	sumBalanceToSubtract := new(big.Int)
-	for _, balanceOutput := range balanceOutputs {
-		if !balanceOutput.Value.Valid {
-			return fmt.Errorf("no value for balance output %d", balanceOutput.ID)
+	err = dbTx.Balances().InsertBalanceOutputs(balanceOutputs...)
+	if err != nil {
+		return err
+	}
+
+	return dbTx.Balances().InsertAddBalanceSums(balanceSums...)
+}
+
+// splitFeeBalance returns the share of a tx's fee balance that belongs to the payout. The network fee of a
+// batch tx is split between the payouts proportional to their values, so the fee balance (the unused part of
+// it) is split the same way, with any rounding remainder going to the first payout that can cover it.
+func splitFeeBalance(feeBalance *big.Int, payout *pooldb.Payout, txPayouts []*pooldb.Payout) (*big.Int, error) {
+	if len(txPayouts) <= 1 {
+		return new(big.Int).Set(feeBalance), nil
+	} else if !feeBalance.IsUint64() {
+		return nil, fmt.Errorf("fee balance too large: %s", feeBalance)
+	}
+
+	index := -1
+	outputs := make([]*types.TxOutput, len(txPayouts))
+	for i, txPayout := range txPayouts {
+		if !txPayout.Value.Valid {
+			return nil, fmt.Errorf("no value for payout %d", txPayout.ID)
+		} else if txPayout.ID == payout.ID {
+			index = i
 		}
 
-		balanceOutput.Spent = true
-		err := pooldb.UpdateBalanceOutput(dbTx, balanceOutput, []string{"spent"})
-		if err != nil {
-			return err
+		outputs[i] = &types.TxOutput{
+			Value:    new(big.Int).Set(txPayout.Value.BigInt),
+			SplitFee: true,
 		}
+	}
+
+	if index < 0 {
+		return nil, fmt.Errorf("payout %d not found in tx payouts", payout.ID)
+	}
+
+	err := txCommon.DistributeFees(nil, outputs, feeBalance.Uint64(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	// the rounding remainder is only deducted from the value, so the share is the difference
+	share := new(big.Int).Sub(txPayouts[index].Value.BigInt, outputs[index].Value)
+
+	return share, nil
+}
+
+// creditPayoutFeeBalance credits the payout's share of the tx's fee balance (the unused gas) back
+// to the miner and removes it from the payout's tx fees.
+func creditPayoutFeeBalance(
+	dbTx pooldb.Store,
+	tx *pooldb.Transaction,
+	payout *pooldb.Payout,
+) error {
+	if !tx.FeeBalance.Valid || tx.FeeBalance.BigInt.Cmp(common.Big0) <= 0 {
+		return nil
+	}
+
+	// batch payouts share a single tx, so the fee balance is split between them
+	txPayouts, err := dbTx.Payouts().GetPayoutsByTransaction(tx.ID)
+	if err != nil {
+		return err
+	}
+
+	feeBalance, err := splitFeeBalance(tx.FeeBalance.BigInt, payout, txPayouts)
+	if err != nil {
+		return err
+	}
+
+	payout.FeeBalance = dbcl.NullBigInt{Valid: true, BigInt: feeBalance}
+	payout.TxFees.BigInt.Sub(payout.TxFees.BigInt, feeBalance)
+
+	return creditPayoutBalance(dbTx, payout, feeBalance)
+}
+
+// creditPayoutBalance adds a mature balance output, that originates from the payout, to the miner.
+func creditPayoutBalance(dbTx pooldb.Store, payout *pooldb.Payout, value *big.Int) error {
+	if value.Cmp(common.Big0) <= 0 {
+		return nil
+	}
+
+	balanceOutput := &pooldb.BalanceOutput{
+		ChainID:    payout.ChainID,
+		MinerID:    payout.MinerID,
+		InPayoutID: types.Uint64Ptr(payout.ID),
+
+		Value:        dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).Set(value)},
+		PoolFees:     dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+		ExchangeFees: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+		Mature:       true,
+	}
 
-		sumBalanceToSubtract.Add(sumBalanceToSubtract, balanceOutput.Value.BigInt)
+	err := dbTx.Balances().InsertBalanceOutputs(balanceOutput)
+	if err != nil {
+		return err
 	}
 
-	// subtract sum value for balance outputs spent in the payout
-	err = pooldb.InsertSubtractBalanceSums(dbTx, &pooldb.BalanceSum{
+	return dbTx.Balances().InsertAddBalanceSums(&pooldb.BalanceSum{
 		MinerID: payout.MinerID,
 		ChainID: payout.ChainID,
 
-		MatureValue: dbcl.NullBigInt{Valid: true, BigInt: sumBalanceToSubtract},
+		MatureValue: balanceOutput.Value,
 	})
+}
+
+func (c *Client) finalizePayout(
+	node types.PayoutNode,
+	payout *pooldb.Payout,
+	miner, emailAddress string,
+) error {
+	if payout.TransactionID == nil {
+		return fmt.Errorf("no transaction id for payout %d", payout.ID)
+	}
+
+	tx, err := c.store.Payouts().GetTransaction(types.Uint64Value(payout.TransactionID))
 	if err != nil {
 		return err
+	} else if !tx.Spent || !tx.Confirmed {
+		if payout.Pending {
+			payout.Pending = false
+			err = c.store.Payouts().UpdatePayout(payout, []string{"pending"})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	} else if !tx.Value.Valid {
+		return fmt.Errorf("no value for tx %s", tx.TxID)
+	} else if !tx.Fee.Valid {
+		return fmt.Errorf("no fee for tx %s", tx.TxID)
 	}
 
-	if tx.FeeBalance.Valid && tx.FeeBalance.BigInt.Cmp(common.Big0) > 0 {
-		payout.FeeBalance = tx.FeeBalance
-		payout.TxFees.BigInt.Sub(payout.TxFees.BigInt, tx.FeeBalance.BigInt)
+	balanceOutputs, err := c.store.Balances().GetBalanceOutputsByPayout(payout.ID)
+	if err != nil {
+		return err
+	}
 
-		balanceOutput := &pooldb.BalanceOutput{
-			ChainID:    payout.ChainID,
-			MinerID:    payout.MinerID,
-			InPayoutID: types.Uint64Ptr(payout.ID),
+	err = c.store.Transact(func(dbTx pooldb.Store) error {
+		sumBalanceToSubtract := new(big.Int)
+		for _, balanceOutput := range balanceOutputs {
+			if !balanceOutput.Value.Valid {
+				return fmt.Errorf("no value for balance output %d", balanceOutput.ID)
+			}
 
-			Value:        payout.FeeBalance,
-			PoolFees:     dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
-			ExchangeFees: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
-			Mature:       true,
-		}
+			balanceOutput.Spent = true
+			err := dbTx.Balances().UpdateBalanceOutput(balanceOutput, []string{"spent"})
+			if err != nil {
+				return err
+			}
 
-		err = pooldb.InsertBalanceOutputs(dbTx, balanceOutput)
-		if err != nil {
-			return err
+			sumBalanceToSubtract.Add(sumBalanceToSubtract, balanceOutput.Value.BigInt)
 		}
 
-		// add balance sum for fee balance
-		err = pooldb.InsertAddBalanceSums(dbTx, &pooldb.BalanceSum{
+		// subtract sum value for balance outputs spent in the payout
+		err := dbTx.Balances().InsertSubtractBalanceSums(&pooldb.BalanceSum{
 			MinerID: payout.MinerID,
 			ChainID: payout.ChainID,
 
-			MatureValue: payout.FeeBalance,
+			MatureValue: dbcl.NullBigInt{Valid: true, BigInt: sumBalanceToSubtract},
 		})
 		if err != nil {
 			return err
 		}
-	}
 
-	payout.Height = tx.Height
-	payout.Confirmed = true
+		err = creditPayoutFeeBalance(dbTx, tx, payout)
+		if err != nil {
+			return err
+		}
+
+		if tx.Failed {
+			// the tx was reverted, so the value and the early payout fee (which was never
+			// credited to the recipients) are refunded to the miner. the network fee is not,
+			// since it has already been spent. the refund is paid out with the next payouts,
+			// individually.
+			payout.Height = tx.Height
+			payout.Failed = true
+
+			refund := new(big.Int).Set(payout.Value.BigInt)
+			if payout.EarlyFees.Valid {
+				refund.Add(refund, payout.EarlyFees.BigInt)
+				payout.TxFees.BigInt.Sub(payout.TxFees.BigInt, payout.EarlyFees.BigInt)
+			}
 
-	cols := []string{"height", "tx_fees", "fee_balance", "confirmed"}
-	err = pooldb.UpdatePayout(dbTx, payout, cols)
-	if err != nil {
-		return err
-	}
+			err = creditPayoutBalance(dbTx, payout, refund)
+			if err != nil {
+				return err
+			}
 
-	err = dbTx.SafeCommit()
+			cols := []string{"height", "tx_fees", "fee_balance", "failed"}
+			return dbTx.Payouts().UpdatePayout(payout, cols)
+		}
+
+		err = creditEarlyPayoutFee(dbTx, payout)
+		if err != nil {
+			return err
+		}
+
+		payout.Height = tx.Height
+		payout.Confirmed = true
+
+		cols := []string{"height", "tx_fees", "fee_balance", "confirmed"}
+		return dbTx.Payouts().UpdatePayout(payout, cols)
+	})
 	if err != nil {
 		return err
+	} else if tx.Failed {
+		return nil
 	}
 
 	c.telegram.NotifyConfirmPayout(payout.ID)
@@ -399,7 +824,7 @@ func (c *Client) finalizePayout(
 		switch payout.ChainID {
 		case "NEXA":
 			decimals = 1
-		case "KAS", "USDC", "USDT":
+		case "KAS", "KLS", "USDC", "USDT":
 			decimals = 2
 		case "BTC":
 			decimals = 6
@@ -420,7 +845,7 @@ func (c *Client) finalizePayout(
 }
 
 func (c *Client) FinalizePayouts(node types.PayoutNode) error {
-	payouts, err := pooldb.GetUnconfirmedPayouts(c.pooldb.Reader(), node.Chain())
+	payouts, err := c.store.Payouts().GetUnconfirmedPayouts(node.Chain())
 	if err != nil {
 		return err
 	}
@@ -435,7 +860,7 @@ func (c *Client) FinalizePayouts(node types.PayoutNode) error {
 		minerIDs = append(minerIDs, minerID)
 	}
 
-	miners, err := pooldb.GetMiners(c.pooldb.Reader(), minerIDs)
+	miners, err := c.store.Miners().GetMiners(minerIDs)
 	if err != nil {
 		return err
 	}
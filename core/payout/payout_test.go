@@ -0,0 +1,813 @@
+package payout
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/node/mining/etc"
+	"github.com/magicpool-co/pool/internal/node/payout/bsc"
+	"github.com/magicpool-co/pool/internal/node/payout/eth"
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/common"
+	txCommon "github.com/magicpool-co/pool/pkg/crypto/tx"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+type testBatchNode struct {
+	types.PayoutNode
+	batchSize int
+	contracts map[string]bool
+}
+
+func (node testBatchNode) GetBatchSize() int { return node.batchSize }
+func (node testBatchNode) IsContractAddress(address string) (bool, error) {
+	return node.contracts[address], nil
+}
+func (node testBatchNode) ApproveBatchPayouts() (bool, error) { return true, nil }
+func (node testBatchNode) SetDisperseAddress(string) error    { return nil }
+
+// every account-based node with a disperse contract pays out in batches
+var (
+	_ types.BatchPayoutNode = &eth.Node{}
+	_ types.BatchPayoutNode = &etc.Node{}
+	_ types.BatchPayoutNode = &bsc.Node{}
+)
+
+func newTestBalanceOutput(minerID uint64, value uint64) *pooldb.BalanceOutput {
+	return &pooldb.BalanceOutput{
+		ChainID: "ETH",
+		MinerID: minerID,
+
+		Value:        dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(value)},
+		PoolFees:     dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+		ExchangeFees: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+		Mature:       true,
+	}
+}
+
+// TestPayoutLedger checks that, for both the account and the utxo path, the balance that
+// leaves the miners equals the value paid out plus the network and early payout fees, and
+// that the early payout fee (the only part that stays in the wallet) is fully credited to
+// the fee recipients.
+func TestPayoutLedger(t *testing.T) {
+	recipients := []*pooldb.Miner{
+		{ID: 100, RecipientFeePercent: types.Uint64Ptr(60)},
+		{ID: 101, RecipientFeePercent: types.Uint64Ptr(40)},
+	}
+
+	payoutBound := &common.PayoutBounds{EarlyFee: 100}
+	threshold := new(big.Int).SetUint64(1_000_000)
+
+	tests := []struct {
+		accounting     types.AccountingType
+		balanceOutputs map[uint64][]uint64
+		networkFee     uint64
+		earlyFees      map[uint64]uint64
+	}{
+		{
+			accounting:     types.AccountStructure,
+			balanceOutputs: map[uint64][]uint64{1: {600_000, 600_000}},
+			networkFee:     21_000,
+			earlyFees:      map[uint64]uint64{1: 0},
+		},
+		{
+			accounting:     types.AccountStructure,
+			balanceOutputs: map[uint64][]uint64{1: {250_000, 250_001}, 2: {1_500_000}},
+			networkFee:     21_000,
+			earlyFees:      map[uint64]uint64{1: 5_000, 2: 0},
+		},
+		{
+			accounting:     types.UTXOStructure,
+			balanceOutputs: map[uint64][]uint64{1: {2_000_000}},
+			networkFee:     1_337,
+			earlyFees:      map[uint64]uint64{1: 0},
+		},
+		{
+			accounting:     types.UTXOStructure,
+			balanceOutputs: map[uint64][]uint64{1: {333_333}, 2: {1_000_001}, 3: {99_999, 1}},
+			networkFee:     1_337,
+			earlyFees:      map[uint64]uint64{1: 3_333, 2: 0, 3: 1_000},
+		},
+	}
+
+	for i, tt := range tests {
+		minerIDs := make([]uint64, 0, len(tt.balanceOutputs))
+		for minerID := 1; minerID <= len(tt.balanceOutputs); minerID++ {
+			minerIDs = append(minerIDs, uint64(minerID))
+		}
+
+		balanceSum := new(big.Int)
+		earlyFeeSum := new(big.Int)
+		payouts := make([]*pooldb.Payout, len(minerIDs))
+		for j, minerID := range minerIDs {
+			balanceOutputs := make([]*pooldb.BalanceOutput, len(tt.balanceOutputs[minerID]))
+			for k, value := range tt.balanceOutputs[minerID] {
+				balanceOutputs[k] = newTestBalanceOutput(minerID, value)
+				balanceSum.Add(balanceSum, balanceOutputs[k].Value.BigInt)
+			}
+
+			sum, earlyFee, err := sumBalanceOutputs(minerID, balanceOutputs, threshold, payoutBound, true)
+			if err != nil {
+				t.Errorf("failed on %d: miner %d: sum: %v", i, minerID, err)
+				continue
+			} else if earlyFee.Uint64() != tt.earlyFees[minerID] {
+				t.Errorf("failed on %d: miner %d: early fee mismatch: have %s, want %d",
+					i, minerID, earlyFee, tt.earlyFees[minerID])
+			}
+			earlyFeeSum.Add(earlyFeeSum, earlyFee)
+
+			payouts[j] = &pooldb.Payout{
+				ID:         minerID,
+				MinerID:    minerID,
+				Address:    string(rune('a' + j)),
+				Value:      sum.Value,
+				FeeBalance: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+				TxFees:     sum.TxFees,
+			}
+		}
+
+		// mimic the tx builders, which deduct the fee from the (shared) output values
+		switch tt.accounting {
+		case types.AccountStructure:
+			included, outputList, outputIdx, err := buildAccountOutputList(nil, payouts, true, nil)
+			if err != nil {
+				t.Errorf("failed on %d: build output list: %v", i, err)
+				continue
+			}
+
+			for j, payout := range included {
+				output := outputList[outputIdx[j][0]][outputIdx[j][1]]
+				output.Fee = new(big.Int).SetUint64(tt.networkFee)
+				output.Value.Sub(output.Value, output.Fee)
+				setPayoutOutput(payout, output)
+			}
+		case types.UTXOStructure:
+			outputs := make([]*types.TxOutput, len(payouts))
+			for j, payout := range payouts {
+				outputs[j] = &types.TxOutput{
+					Address:  payout.Address,
+					Value:    new(big.Int).Set(payout.Value.BigInt),
+					SplitFee: true,
+				}
+			}
+
+			err := txCommon.DistributeFees(nil, outputs, tt.networkFee, false)
+			if err != nil {
+				t.Errorf("failed on %d: distribute fees: %v", i, err)
+				continue
+			}
+
+			for j, payout := range payouts {
+				deductPayoutTxFee(payout, outputs[j])
+			}
+		}
+
+		paidSum, txFeesSum := new(big.Int), new(big.Int)
+		for _, payout := range payouts {
+			paidSum.Add(paidSum, payout.Value.BigInt)
+			txFeesSum.Add(txFeesSum, payout.TxFees.BigInt)
+		}
+
+		// the payouts account for the full balance of the miners
+		if total := new(big.Int).Add(paidSum, txFeesSum); total.Cmp(balanceSum) != 0 {
+			t.Errorf("failed on %d: payout total mismatch: have %s, want %s", i, total, balanceSum)
+		}
+
+		// the wallet keeps exactly the early payout fees
+		networkFees := new(big.Int).Sub(txFeesSum, earlyFeeSum)
+		walletRetained := new(big.Int).Sub(balanceSum, paidSum)
+		walletRetained.Sub(walletRetained, networkFees)
+		if walletRetained.Cmp(earlyFeeSum) != 0 {
+			t.Errorf("failed on %d: wallet retained mismatch: have %s, want %s", i, walletRetained, earlyFeeSum)
+		}
+
+		// which are credited to the recipients in full
+		creditedSum, creditedBalanceSum := new(big.Int), new(big.Int)
+		for _, minerID := range minerIDs {
+			earlyFee := new(big.Int).SetUint64(tt.earlyFees[minerID])
+			if earlyFee.Cmp(common.Big0) == 0 {
+				continue
+			}
+
+			balanceOutputs, balanceSums, err := newEarlyPayoutFeeCredits("ETH", recipients, earlyFee)
+			if err != nil {
+				t.Errorf("failed on %d: credits: %v", i, err)
+				continue
+			}
+
+			for _, balanceOutput := range balanceOutputs {
+				creditedSum.Add(creditedSum, balanceOutput.Value.BigInt)
+				if balanceOutput.PoolFees.BigInt.Cmp(balanceOutput.Value.BigInt) != 0 {
+					t.Errorf("failed on %d: credit pool fees mismatch: have %s, want %s",
+						i, balanceOutput.PoolFees.BigInt, balanceOutput.Value.BigInt)
+				}
+			}
+
+			for _, balanceSum := range balanceSums {
+				creditedBalanceSum.Add(creditedBalanceSum, balanceSum.MatureValue.BigInt)
+			}
+		}
+
+		if creditedSum.Cmp(earlyFeeSum) != 0 {
+			t.Errorf("failed on %d: credited mismatch: have %s, want %s", i, creditedSum, earlyFeeSum)
+		} else if creditedBalanceSum.Cmp(earlyFeeSum) != 0 {
+			t.Errorf("failed on %d: credited balance sum mismatch: have %s, want %s",
+				i, creditedBalanceSum, earlyFeeSum)
+		}
+	}
+}
+
+func TestSumBalanceOutputs(t *testing.T) {
+	payoutBound := &common.PayoutBounds{EarlyFee: 250}
+	threshold := new(big.Int).SetUint64(10_000)
+
+	tests := []struct {
+		values    []uint64
+		scheduled bool
+		value     uint64
+		earlyFee  uint64
+		err       bool
+	}{
+		{values: []uint64{10_000}, scheduled: false, value: 10_000, earlyFee: 0},
+		{values: []uint64{6_000, 4_000}, scheduled: true, value: 10_000, earlyFee: 0},
+		{values: []uint64{4_000}, scheduled: true, value: 3_900, earlyFee: 100},
+		{values: []uint64{4_000}, scheduled: false, err: true},
+		{values: []uint64{}, scheduled: true, err: true},
+	}
+
+	for i, tt := range tests {
+		balanceOutputs := make([]*pooldb.BalanceOutput, len(tt.values))
+		for j, value := range tt.values {
+			balanceOutputs[j] = newTestBalanceOutput(1, value)
+		}
+
+		sum, earlyFee, err := sumBalanceOutputs(1, balanceOutputs, threshold, payoutBound, tt.scheduled)
+		if tt.err {
+			if err == nil {
+				t.Errorf("failed on %d: expected error", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if sum.Value.BigInt.Uint64() != tt.value {
+			t.Errorf("failed on %d: value mismatch: have %s, want %d", i, sum.Value.BigInt, tt.value)
+		} else if earlyFee.Uint64() != tt.earlyFee {
+			t.Errorf("failed on %d: early fee mismatch: have %s, want %d", i, earlyFee, tt.earlyFee)
+		} else if sum.TxFees.BigInt.Uint64() != tt.earlyFee {
+			t.Errorf("failed on %d: tx fees mismatch: have %s, want %d", i, sum.TxFees.BigInt, tt.earlyFee)
+		}
+
+		// the balance outputs themselves are never changed
+		for j, value := range tt.values {
+			if balanceOutputs[j].Value.BigInt.Uint64() != value {
+				t.Errorf("failed on %d: balance output %d changed: have %s, want %d",
+					i, j, balanceOutputs[j].Value.BigInt, value)
+			}
+		}
+	}
+}
+
+func TestSplitFeeBalance(t *testing.T) {
+	newPayouts := func(values map[uint64]uint64, ids ...uint64) []*pooldb.Payout {
+		payouts := make([]*pooldb.Payout, len(ids))
+		for i, id := range ids {
+			payouts[i] = &pooldb.Payout{
+				ID:    id,
+				Value: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(values[id])},
+			}
+		}
+		return payouts
+	}
+
+	even := map[uint64]uint64{1: 1000, 2: 1000, 3: 1000, 4: 1000, 5: 1000}
+	uneven := map[uint64]uint64{3: 1000, 4: 3000, 5: 6000}
+
+	tests := []struct {
+		feeBalance uint64
+		payoutID   uint64
+		txPayouts  []*pooldb.Payout
+		share      uint64
+		err        bool
+	}{
+		{feeBalance: 1000, payoutID: 1, txPayouts: newPayouts(even, 1), share: 1000},
+		{feeBalance: 1000, payoutID: 2, txPayouts: newPayouts(even, 1, 2), share: 500},
+		{feeBalance: 1000, payoutID: 3, txPayouts: newPayouts(even, 3, 4, 5), share: 334},
+		{feeBalance: 1000, payoutID: 4, txPayouts: newPayouts(even, 3, 4, 5), share: 333},
+		{feeBalance: 1000, payoutID: 5, txPayouts: newPayouts(even, 5, 4, 3), share: 334},
+		{feeBalance: 1000, payoutID: 3, txPayouts: newPayouts(uneven, 3, 4, 5), share: 100},
+		{feeBalance: 1000, payoutID: 5, txPayouts: newPayouts(uneven, 3, 4, 5), share: 600},
+		{feeBalance: 1001, payoutID: 3, txPayouts: newPayouts(uneven, 3, 4, 5), share: 101},
+		{feeBalance: 2, payoutID: 4, txPayouts: newPayouts(even, 3, 4, 5), share: 0},
+		{feeBalance: 1000, payoutID: 6, txPayouts: newPayouts(even, 3, 4, 5), err: true},
+	}
+
+	for i, tt := range tests {
+		share, err := splitFeeBalance(new(big.Int).SetUint64(tt.feeBalance), &pooldb.Payout{ID: tt.payoutID}, tt.txPayouts)
+		if tt.err {
+			if err == nil {
+				t.Errorf("failed on %d: expected error", i)
+			}
+		} else if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if share.Uint64() != tt.share {
+			t.Errorf("failed on %d: share mismatch: have %s, want %d", i, share, tt.share)
+		}
+	}
+}
+
+func TestBuildAccountOutputList(t *testing.T) {
+	newPayouts := func(count int) []*pooldb.Payout {
+		payouts := make([]*pooldb.Payout, count)
+		for i := range payouts {
+			payouts[i] = &pooldb.Payout{
+				MinerID:    uint64(i + 1),
+				Address:    string(rune('a' + i)),
+				Value:      dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(1000)},
+				FeeBalance: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+			}
+		}
+		return payouts
+	}
+
+	node := testBatchNode{batchSize: 3, contracts: map[string]bool{"b": true}}
+
+	tests := []struct {
+		node          types.PayoutNode
+		count         int
+		batch         bool
+		individualIdx map[uint64]bool
+		outputList    [][]string
+	}{
+		{
+			node:       nil,
+			count:      3,
+			batch:      true,
+			outputList: [][]string{{"a"}, {"b"}, {"c"}},
+		},
+		{
+			node:       node,
+			count:      3,
+			batch:      false,
+			outputList: [][]string{{"a"}, {"b"}, {"c"}},
+		},
+		{
+			node:       node,
+			count:      4,
+			batch:      true,
+			outputList: [][]string{{"a", "c", "d"}, {"b"}},
+		},
+		{
+			node:       node,
+			count:      6,
+			batch:      true,
+			outputList: [][]string{{"a", "c", "d"}, {"b"}},
+		},
+		{
+			node:          node,
+			count:         5,
+			batch:         true,
+			individualIdx: map[uint64]bool{3: true},
+			outputList:    [][]string{{"a", "d", "e"}, {"b"}, {"c"}},
+		},
+		{
+			node:          node,
+			count:         2,
+			batch:         true,
+			individualIdx: map[uint64]bool{1: true},
+			outputList:    [][]string{{"a"}, {"b"}},
+		},
+		{
+			node:       nil,
+			count:      maxAccountTxCount + 2,
+			batch:      true,
+			outputList: nil,
+		},
+	}
+
+	for i, tt := range tests {
+		payouts := newPayouts(tt.count)
+		included, outputList, outputIdx, err := buildAccountOutputList(tt.node, payouts, tt.batch, tt.individualIdx)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		} else if len(included) != len(outputIdx) {
+			t.Errorf("failed on %d: index length mismatch: have %d, want %d", i, len(outputIdx), len(included))
+			continue
+		}
+
+		// every included payout points to its own output
+		for j, payout := range included {
+			output := outputList[outputIdx[j][0]][outputIdx[j][1]]
+			if output.Address != payout.Address {
+				t.Errorf("failed on %d: output address mismatch: have %s, want %s", i, output.Address, payout.Address)
+			}
+		}
+
+		if tt.outputList == nil {
+			if len(outputList) != maxAccountTxCount {
+				t.Errorf("failed on %d: output list length mismatch: have %d, want %d",
+					i, len(outputList), maxAccountTxCount)
+			}
+			continue
+		}
+
+		addresses := make([][]string, len(outputList))
+		for j, outputs := range outputList {
+			addresses[j] = make([]string, len(outputs))
+			for k, output := range outputs {
+				addresses[j][k] = output.Address
+			}
+		}
+
+		if !reflect.DeepEqual(addresses, tt.outputList) {
+			t.Errorf("failed on %d: output list mismatch: have %v, want %v", i, addresses, tt.outputList)
+		}
+	}
+}
+
+// TestFinalizeFailedPayout checks that the payouts of a reverted batch tx are marked as
+// failed, and that the miners are refunded their value and early payout fee plus their
+// share of the unused gas.
+func TestFinalizeFailedPayout(t *testing.T) {
+	store := pooldb.NewMemoryStore()
+	client := &Client{store: store}
+
+	txID, err := store.InsertTransaction(&pooldb.Transaction{
+		ChainID:    "ETH",
+		TxID:       "0xabc",
+		Height:     types.Uint64Ptr(100),
+		Value:      dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(2_000_000)},
+		Fee:        dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(30_000)},
+		FeeBalance: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(11_001)},
+		Spent:      true,
+		Confirmed:  true,
+		Failed:     true,
+	})
+	if err != nil {
+		t.Fatalf("failed to insert tx: %v", err)
+	}
+
+	values := map[uint64]uint64{1: 1_000_000, 2: 970_000}
+	txFees := map[uint64]uint64{1: 20_000, 2: 30_000}
+	earlyFees := map[uint64]uint64{1: 0, 2: 10_000}
+	payouts := make([]*pooldb.Payout, 0, len(values))
+	for minerID := uint64(1); minerID <= 2; minerID++ {
+		payout := &pooldb.Payout{
+			ChainID:       "ETH",
+			MinerID:       minerID,
+			TransactionID: types.Uint64Ptr(txID),
+			TxID:          "0xabc",
+			Value:         dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(values[minerID])},
+			FeeBalance:    dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+			PoolFees:      dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+			ExchangeFees:  dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+			TxFees:        dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(txFees[minerID])},
+			EarlyFees:     dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(earlyFees[minerID])},
+			Pending:       true,
+		}
+
+		payout.ID, err = store.InsertPayout(payout)
+		if err != nil {
+			t.Fatalf("failed to insert payout: %v", err)
+		}
+		payouts = append(payouts, payout)
+
+		balanceOutput := newTestBalanceOutput(minerID, values[minerID]+txFees[minerID])
+		balanceOutput.OutPayoutID = types.Uint64Ptr(payout.ID)
+		if err := store.InsertBalanceOutputs(balanceOutput); err != nil {
+			t.Fatalf("failed to insert balance output: %v", err)
+		}
+
+		err = store.InsertAddBalanceSums(&pooldb.BalanceSum{
+			MinerID:     minerID,
+			ChainID:     "ETH",
+			MatureValue: balanceOutput.Value,
+		})
+		if err != nil {
+			t.Fatalf("failed to insert balance sum: %v", err)
+		}
+	}
+
+	for _, payout := range payouts {
+		if err := client.finalizePayout(nil, payout, "", ""); err != nil {
+			t.Fatalf("failed to finalize payout %d: %v", payout.ID, err)
+		}
+	}
+
+	unconfirmed, err := store.GetUnconfirmedPayouts("ETH")
+	if err != nil {
+		t.Fatalf("failed to get unconfirmed payouts: %v", err)
+	} else if len(unconfirmed) != 0 {
+		t.Errorf("unconfirmed payout mismatch: have %d, want 0", len(unconfirmed))
+	}
+
+	sums, err := store.GetBalanceSumsByChain("ETH")
+	if err != nil {
+		t.Fatalf("failed to get balance sums: %v", err)
+	}
+
+	// the unused gas is split proportional to the values, with the remainder going to the first payout
+	wantSums := map[uint64]uint64{1: 1_000_000 + 5_585, 2: 970_000 + 10_000 + 5_416}
+	wantTxFees := map[uint64]uint64{1: 20_000 - 5_585, 2: 30_000 - 10_000 - 5_416}
+	for _, sum := range sums {
+		if sum.MatureValue.BigInt.Uint64() != wantSums[sum.MinerID] {
+			t.Errorf("miner %d: balance sum mismatch: have %s, want %d",
+				sum.MinerID, sum.MatureValue.BigInt, wantSums[sum.MinerID])
+		}
+	}
+
+	for _, payout := range payouts {
+		stored, err := store.GetPayout(payout.ID)
+		if err != nil {
+			t.Fatalf("failed to get payout %d: %v", payout.ID, err)
+		} else if !stored.Failed || stored.Confirmed {
+			t.Errorf("payout %d: state mismatch: have failed %t, confirmed %t", payout.ID, stored.Failed, stored.Confirmed)
+		} else if stored.TxFees.BigInt.Uint64() != wantTxFees[payout.MinerID] {
+			t.Errorf("payout %d: tx fees mismatch: have %s, want %d", payout.ID, stored.TxFees.BigInt, wantTxFees[payout.MinerID])
+		}
+
+		balanceOutputs, err := store.GetUnpaidBalanceOutputsByMiner(payout.MinerID, "ETH")
+		if err != nil {
+			t.Fatalf("failed to get balance outputs: %v", err)
+		}
+
+		refund := new(big.Int)
+		for _, balanceOutput := range balanceOutputs {
+			if types.Uint64Value(balanceOutput.InPayoutID) != payout.ID {
+				t.Errorf("payout %d: balance output not from payout", payout.ID)
+			}
+			refund.Add(refund, balanceOutput.Value.BigInt)
+		}
+
+		if refund.Uint64() != wantSums[payout.MinerID] {
+			t.Errorf("payout %d: refund mismatch: have %s, want %d", payout.ID, refund, wantSums[payout.MinerID])
+		}
+	}
+}
+
+func TestGetPayoutMiners(t *testing.T) {
+	store := pooldb.NewMemoryStore()
+	now := time.Date(2024, 6, 12, 12, 0, 0, 0, time.UTC)
+	payoutBound := &common.PayoutBounds{
+		Min:     new(big.Int).SetUint64(100_000_000_000_000),
+		Default: new(big.Int).SetUint64(2_500_000_000_000_000),
+	}
+
+	miners := []struct {
+		balance     uint64
+		threshold   uint64
+		schedule    types.PayoutSchedule
+		payoutTime  *time.Time
+		requested   bool
+		unconfirmed bool
+		held        bool
+	}{
+		// above the threshold
+		{balance: 3_000_000_000_000_000},
+		// below the threshold, but due for a daily payout
+		{balance: 1_000_000_000_000_000, schedule: types.PayoutDaily},
+		// below the threshold, but requested a payout
+		{balance: 1_000_000_000_000_000, requested: true},
+		// below the threshold with a fixed payout that isn't due yet
+		{balance: 1_000_000_000_000_000, schedule: types.PayoutFixed, payoutTime: types.TimePtr(now.Add(time.Hour))},
+		// above the threshold, but with an unconfirmed payout
+		{balance: 3_000_000_000_000_000, unconfirmed: true},
+		// above the threshold, but with a held balance
+		{balance: 3_000_000_000_000_000, held: true},
+		// requested a payout, but below the minimum payout
+		{balance: 10_000_000_000_000, requested: true},
+		// below the default threshold, but above their own
+		{balance: 1_000_000_000_000_000, threshold: 500_000_000_000_000},
+		// above the default threshold, but below their own
+		{balance: 3_000_000_000_000_000, threshold: 5_000_000_000_000_000},
+	}
+
+	for i, tt := range miners {
+		miner := &pooldb.Miner{ChainID: "ETH", Address: fmt.Sprintf("0x%040d", i)}
+		minerID, err := store.Miners().InsertMiner(miner)
+		if err != nil {
+			t.Fatalf("failed on %d: insert miner: %v", i, err)
+		}
+
+		miner.ID = minerID
+		miner.PayoutSchedule = int(tt.schedule)
+		miner.PayoutTime = tt.payoutTime
+		miner.PayoutRequested = tt.requested
+		if tt.threshold > 0 {
+			miner.Threshold = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(tt.threshold)}
+		}
+		err = store.Miners().UpdateMiner(miner, []string{"threshold", "payout_schedule", "payout_time", "payout_requested"})
+		if err != nil {
+			t.Fatalf("failed on %d: update miner: %v", i, err)
+		}
+
+		err = store.Balances().InsertAddBalanceSums(&pooldb.BalanceSum{
+			MinerID:     minerID,
+			ChainID:     "ETH",
+			MatureValue: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(tt.balance)},
+		})
+		if err != nil {
+			t.Fatalf("failed on %d: insert balance sum: %v", i, err)
+		}
+
+		if tt.unconfirmed {
+			_, err = store.Payouts().InsertPayout(&pooldb.Payout{
+				ChainID:      "ETH",
+				MinerID:      minerID,
+				Value:        dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(tt.balance)},
+				FeeBalance:   dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+				PoolFees:     dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+				ExchangeFees: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+				TxFees:       dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+				Pending:      true,
+			})
+			if err != nil {
+				t.Fatalf("failed on %d: insert payout: %v", i, err)
+			}
+		}
+
+		if tt.held {
+			round := &pooldb.Round{ChainID: "ETH", Height: uint64(100 + i), Mature: true}
+			round.ID, err = store.Rounds().InsertRound(round)
+			if err != nil {
+				t.Fatalf("failed on %d: insert round: %v", i, err)
+			}
+
+			round.Held = true
+			if err := store.Rounds().UpdateRound(round, []string{"held"}); err != nil {
+				t.Fatalf("failed on %d: update round: %v", i, err)
+			}
+
+			err = store.Balances().InsertBalanceInputs(&pooldb.BalanceInput{
+				RoundID:    round.ID,
+				ChainID:    "ETH",
+				MinerID:    minerID,
+				OutChainID: "ETH",
+				Value:      dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(tt.balance)},
+				PoolFees:   dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+				Mature:     true,
+			})
+			if err != nil {
+				t.Fatalf("failed on %d: insert balance input: %v", i, err)
+			}
+		}
+	}
+
+	payoutMiners, scheduledIdx, err := getPayoutMiners(store, "ETH", payoutBound, now)
+	if err != nil {
+		t.Fatalf("failed to get payout miners: %v", err)
+	}
+
+	minerIDs := make([]uint64, len(payoutMiners))
+	for i, miner := range payoutMiners {
+		minerIDs[i] = miner.ID
+	}
+	sort.Slice(minerIDs, func(i, j int) bool { return minerIDs[i] < minerIDs[j] })
+
+	scheduledIDs := make([]uint64, 0, len(scheduledIdx))
+	for minerID := range scheduledIdx {
+		scheduledIDs = append(scheduledIDs, minerID)
+	}
+	sort.Slice(scheduledIDs, func(i, j int) bool { return scheduledIDs[i] < scheduledIDs[j] })
+
+	if want := []uint64{1, 2, 3, 8}; !reflect.DeepEqual(minerIDs, want) {
+		t.Errorf("payout miners mismatch: have %v, want %v", minerIDs, want)
+	}
+	if want := []uint64{2, 3}; !reflect.DeepEqual(scheduledIDs, want) {
+		t.Errorf("scheduled miners mismatch: have %v, want %v", scheduledIDs, want)
+	}
+}
+
+func TestRecordPayout(t *testing.T) {
+	store := pooldb.NewMemoryStore()
+
+	recipientFees := []uint64{60, 40}
+	for i, feePercent := range recipientFees {
+		recipient := &pooldb.Miner{ChainID: "ETH", Address: fmt.Sprintf("0x%040d", i)}
+		recipientID, err := store.Miners().InsertMiner(recipient)
+		if err != nil {
+			t.Fatalf("failed to insert recipient: %v", err)
+		}
+
+		recipient.ID = recipientID
+		recipient.RecipientFeePercent = types.Uint64Ptr(feePercent)
+		if err := store.Miners().UpdateMiner(recipient, []string{"recipient_fee_percent"}); err != nil {
+			t.Fatalf("failed to update recipient: %v", err)
+		}
+	}
+
+	miner := &pooldb.Miner{ChainID: "ETH", Address: fmt.Sprintf("0x%040d", len(recipientFees))}
+	minerID, err := store.Miners().InsertMiner(miner)
+	if err != nil {
+		t.Fatalf("failed to insert miner: %v", err)
+	}
+
+	miner.ID = minerID
+	miner.PayoutRequested = true
+	if err := store.Miners().UpdateMiner(miner, []string{"payout_requested"}); err != nil {
+		t.Fatalf("failed to update miner: %v", err)
+	}
+
+	for _, value := range []uint64{400_000, 600_000} {
+		if err := store.Balances().InsertBalanceOutputs(newTestBalanceOutput(minerID, value)); err != nil {
+			t.Fatalf("failed to insert balance output: %v", err)
+		}
+	}
+
+	balanceOutputs, err := store.Balances().GetUnpaidBalanceOutputsByMiner(minerID, "ETH")
+	if err != nil {
+		t.Fatalf("failed to get balance outputs: %v", err)
+	}
+
+	earlyPayoutFee := new(big.Int).SetUint64(10_001)
+	payout := &pooldb.Payout{
+		ChainID:      "ETH",
+		MinerID:      minerID,
+		TxID:         "0xabc",
+		Value:        dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(968_999)},
+		FeeBalance:   dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+		PoolFees:     dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+		ExchangeFees: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+		TxFees:       dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(31_001)},
+		EarlyFees:    dbcl.NullBigInt{Valid: true, BigInt: earlyPayoutFee},
+		Pending:      true,
+	}
+
+	err = store.Transact(func(tx pooldb.Store) error {
+		return recordPayout(tx, payout, balanceOutputs, miner)
+	})
+	if err != nil {
+		t.Fatalf("failed to record payout: %v", err)
+	} else if payout.ID == 0 {
+		t.Fatalf("payout id mismatch: have 0")
+	}
+
+	stored, err := store.Payouts().GetPayout(payout.ID)
+	if err != nil {
+		t.Fatalf("failed to get payout: %v", err)
+	} else if stored.MinerID != minerID || stored.Value.BigInt.Cmp(payout.Value.BigInt) != 0 {
+		t.Errorf("payout mismatch: have miner %d value %s, want miner %d value %s",
+			stored.MinerID, stored.Value.BigInt, minerID, payout.Value.BigInt)
+	} else if stored.EarlyFees.BigInt.Cmp(earlyPayoutFee) != 0 {
+		t.Errorf("early fees mismatch: have %s, want %s", stored.EarlyFees.BigInt, earlyPayoutFee)
+	}
+
+	paidOutputs, err := store.Balances().GetBalanceOutputsByPayout(payout.ID)
+	if err != nil {
+		t.Fatalf("failed to get paid balance outputs: %v", err)
+	} else if len(paidOutputs) != len(balanceOutputs) {
+		t.Errorf("paid balance output mismatch: have %d, want %d", len(paidOutputs), len(balanceOutputs))
+	}
+
+	unpaidOutputs, err := store.Balances().GetUnpaidBalanceOutputsByMiner(minerID, "ETH")
+	if err != nil {
+		t.Fatalf("failed to get unpaid balance outputs: %v", err)
+	} else if len(unpaidOutputs) != 0 {
+		t.Errorf("unpaid balance output mismatch: have %d, want 0", len(unpaidOutputs))
+	}
+
+	storedMiner, err := store.Miners().GetMiner(minerID)
+	if err != nil {
+		t.Fatalf("failed to get miner: %v", err)
+	} else if storedMiner.PayoutRequested {
+		t.Errorf("payout requested mismatch: have true, want false")
+	}
+
+	// the early payout fee is only credited to the recipients once the payout confirms
+	sums, err := store.Balances().GetBalanceSumsByChain("ETH")
+	if err != nil {
+		t.Fatalf("failed to get balance sums: %v", err)
+	} else if len(sums) != 0 {
+		t.Errorf("balance sum mismatch: have %d, want 0", len(sums))
+	}
+
+	err = store.Transact(func(tx pooldb.Store) error {
+		return creditEarlyPayoutFee(tx, stored)
+	})
+	if err != nil {
+		t.Fatalf("failed to credit early payout fee: %v", err)
+	}
+
+	sums, err = store.Balances().GetBalanceSumsByChain("ETH")
+	if err != nil {
+		t.Fatalf("failed to get balance sums: %v", err)
+	}
+
+	credited := new(big.Int)
+	for _, sum := range sums {
+		if sum.MinerID == minerID {
+			t.Errorf("miner %d: unexpected balance sum %s", minerID, sum.MatureValue.BigInt)
+			continue
+		}
+		credited.Add(credited, sum.MatureValue.BigInt)
+	}
+
+	if credited.Cmp(earlyPayoutFee) != 0 {
+		t.Errorf("early payout fee credit mismatch: have %s, want %s", credited, earlyPayoutFee)
+	}
+}
@@ -0,0 +1,219 @@
+package reorg
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/internal/redis"
+	"github.com/magicpool-co/pool/internal/telegram"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+const (
+	// minimum number of recent blocks to track tips for
+	minTipWindow = 32
+	// rounds are revalidated until they are this many mature depths deep
+	revalidationFactor = 4
+)
+
+type Client struct {
+	store       pooldb.Store
+	tips        redis.TipStore
+	telegram    *telegram.Client
+	alertDepths map[string]uint64
+}
+
+// New creates a new reorg client. alertDepths overrides the minimum reorg
+// depth to alert on per chain, which otherwise defaults to the immature depth.
+func New(
+	pooldbClient *dbcl.Client,
+	redisClient *redis.Client,
+	telegramClient *telegram.Client,
+	alertDepths map[string]uint64,
+) *Client {
+	client := &Client{
+		store:       pooldb.NewStore(pooldbClient),
+		tips:        redisClient,
+		telegram:    telegramClient,
+		alertDepths: alertDepths,
+	}
+
+	return client
+}
+
+func (c *Client) getAlertDepth(node types.MiningNode) uint64 {
+	if depth, ok := c.alertDepths[node.Chain()]; ok && depth > 0 {
+		return depth
+	}
+
+	return node.GetImmatureDepth()
+}
+
+// checkTips compares the latest blocks against the tips stored on the previous run
+// and returns the lowest height that was reorged (or zero if there was no reorg).
+func (c *Client) checkTips(node types.ChainTipNode, height uint64) (uint64, error) {
+	window := node.GetImmatureDepth() * 2
+	if window < minTipWindow {
+		window = minTipWindow
+	}
+
+	var start uint64 = 1
+	if height > window {
+		start = height - window + 1
+	}
+
+	hashes, err := node.GetBlockHashes(start, height)
+	if err != nil {
+		return 0, err
+	}
+
+	tips := make(map[uint64]string, len(hashes))
+	for i, hash := range hashes {
+		tips[start+uint64(i)] = hash
+	}
+
+	prevTips, err := c.tips.GetReorgChainTips(node.Chain())
+	if err != nil {
+		return 0, err
+	}
+
+	var forkHeight, tipHeight uint64
+	for prevHeight, prevHash := range prevTips {
+		if prevHeight > tipHeight {
+			tipHeight = prevHeight
+		}
+
+		hash, ok := tips[prevHeight]
+		if ok && hash != prevHash && (forkHeight == 0 || prevHeight < forkHeight) {
+			forkHeight = prevHeight
+		}
+	}
+
+	// anything at or beyond the immature depth has already been
+	// unlocked, so the reorg invalidates the unlock assumptions
+	if forkHeight > 0 {
+		depth := tipHeight - forkHeight + 1
+		if depth >= c.getAlertDepth(node) {
+			c.telegram.NotifyChainReorg(node.Chain(), forkHeight, depth)
+		}
+	}
+
+	err = c.tips.SetReorgChainTips(node.Chain(), tips)
+	if err != nil {
+		return 0, err
+	}
+
+	return forkHeight, nil
+}
+
+func copyValue[T any](src *T) *T {
+	if src == nil {
+		return nil
+	}
+	dst := *src
+
+	return &dst
+}
+
+func copyBigInt(src dbcl.NullBigInt) dbcl.NullBigInt {
+	if src.BigInt != nil {
+		src.BigInt = new(big.Int).Set(src.BigInt)
+	}
+
+	return src
+}
+
+// copyRound deep copies the round, so the node's unlock logic can't
+// modify the stored round through any of the pointer fields.
+func copyRound(round *pooldb.Round) *pooldb.Round {
+	check := *round
+	check.Miner = copyValue(round.Miner)
+	check.UncleHeight = copyValue(round.UncleHeight)
+	check.EpochHeight = copyValue(round.EpochHeight)
+	check.Nonce = copyValue(round.Nonce)
+	check.MixDigest = copyValue(round.MixDigest)
+	check.Solution = copyValue(round.Solution)
+	check.CoinbaseTxID = copyValue(round.CoinbaseTxID)
+	check.Value = copyBigInt(round.Value)
+	check.TxFees = copyBigInt(round.TxFees)
+	check.MinerValue = copyBigInt(round.MinerValue)
+
+	return &check
+}
+
+// revalidateRound checks if the round is still part of the main chain (or the blue set)
+// by running the node's unlock logic against a deep copy of the round.
+func revalidateRound(node types.MiningNode, round *pooldb.Round) (bool, error) {
+	check := copyRound(round)
+	err := node.UnlockRound(check)
+	if err != nil {
+		return false, err
+	}
+
+	valid := !check.Orphan && check.Hash == round.Hash
+
+	return valid, nil
+}
+
+func (c *Client) revalidateRounds(node types.MiningNode, minHeight uint64) error {
+	rounds, err := c.store.Rounds().GetRevalidationRoundsByChain(node.Chain(), minHeight)
+	if err != nil {
+		return err
+	}
+
+	for _, round := range rounds {
+		valid, err := revalidateRound(node, round)
+		if err != nil {
+			return fmt.Errorf("round %d: %v", round.ID, err)
+		} else if valid == !round.Held {
+			continue
+		}
+
+		// the hold is placed on the round itself, every balance derived from it
+		// is skipped by payouts until the round is either released or resolved manually
+		round.Held = !valid
+		err = c.store.Rounds().UpdateRound(round, []string{"held"})
+		if err != nil {
+			return err
+		}
+
+		if round.Held {
+			c.telegram.NotifyRoundHeld(node.Chain(), round.Height, round.Hash)
+		} else {
+			c.telegram.NotifyRoundReleased(node.Chain(), round.Height, round.Hash)
+		}
+	}
+
+	return nil
+}
+
+// CheckChain detects reorgs on the recent chain tips and revalidates every unlocked round
+// that is either recent enough to be affected by a reorg or is currently held.
+func (c *Client) CheckChain(node types.MiningNode) error {
+	height, syncing, err := node.GetStatus()
+	if err != nil {
+		return err
+	} else if syncing {
+		return nil
+	}
+
+	var minHeight uint64
+	if depth := node.GetMatureDepth() * revalidationFactor; height > depth {
+		minHeight = height - depth
+	}
+
+	// DAG chains don't have a single block per height to track tips
+	// for, their rounds are still revalidated against the blue set
+	if tipNode, ok := node.(types.ChainTipNode); ok {
+		forkHeight, err := c.checkTips(tipNode, height)
+		if err != nil {
+			return err
+		} else if forkHeight > 0 && forkHeight < minHeight {
+			minHeight = forkHeight
+		}
+	}
+
+	return c.revalidateRounds(node, minHeight)
+}
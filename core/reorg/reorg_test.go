@@ -0,0 +1,259 @@
+package reorg
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/internal/redis"
+	"github.com/magicpool-co/pool/internal/telegram"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+// testNode is a mining node with a fixed main chain, where orphans
+// holds the heights of rounds that are no longer in the main chain.
+type testNode struct {
+	types.MiningNode
+	hashes  map[uint64]string
+	orphans map[uint64]bool
+}
+
+func (n *testNode) Chain() string                    { return "ETC" }
+func (n *testNode) GetImmatureDepth() uint64         { return 8 }
+func (n *testNode) GetMatureDepth() uint64           { return 16 }
+func (n *testNode) GetStatus() (uint64, bool, error) { return 0, false, nil }
+
+func (n *testNode) GetBlockHashes(start, end uint64) ([]string, error) {
+	hashes := make([]string, 0, end-start+1)
+	for height := start; height <= end; height++ {
+		hashes = append(hashes, n.hashes[height])
+	}
+
+	return hashes, nil
+}
+
+// UnlockRound also overwrites the pointer fields, the same
+// way a node's unlock logic does when it fills in a round.
+func (n *testNode) UnlockRound(round *pooldb.Round) error {
+	if n.orphans[round.Height] {
+		round.Orphan = true
+	}
+
+	if round.Nonce != nil {
+		*round.Nonce = 0
+	}
+	if round.Value.Valid {
+		round.Value.BigInt.SetUint64(0)
+	}
+
+	return nil
+}
+
+func newTestClient() *Client {
+	client := &Client{
+		store:    pooldb.NewMemoryStore(),
+		tips:     redis.NewMemoryTipStore(),
+		telegram: &telegram.Client{},
+	}
+
+	return client
+}
+
+func newTestChain(start, end uint64, fork uint64) map[uint64]string {
+	hashes := make(map[uint64]string)
+	for height := start; height <= end; height++ {
+		hashes[height] = fmt.Sprintf("0x%d", height)
+		if fork > 0 && height >= fork {
+			hashes[height] += "b"
+		}
+	}
+
+	return hashes
+}
+
+func TestCheckTips(t *testing.T) {
+	tests := []struct {
+		prevHashes map[uint64]string
+		hashes     map[uint64]string
+		height     uint64
+		forkHeight uint64
+	}{
+		{
+			prevHashes: nil,
+			hashes:     newTestChain(1, 100, 0),
+			height:     100,
+			forkHeight: 0,
+		},
+		{
+			prevHashes: newTestChain(1, 100, 0),
+			hashes:     newTestChain(1, 101, 0),
+			height:     101,
+			forkHeight: 0,
+		},
+		{
+			prevHashes: newTestChain(1, 100, 0),
+			hashes:     newTestChain(1, 101, 95),
+			height:     101,
+			forkHeight: 95,
+		},
+		{
+			prevHashes: newTestChain(1, 100, 90),
+			hashes:     newTestChain(1, 100, 0),
+			height:     100,
+			forkHeight: 90,
+		},
+		{
+			// forks below the tip window are reported at the bottom of the window
+			prevHashes: newTestChain(1, 100, 0),
+			hashes:     newTestChain(1, 100, 50),
+			height:     100,
+			forkHeight: 69,
+		},
+	}
+
+	for i, tt := range tests {
+		client := newTestClient()
+		if tt.prevHashes != nil {
+			node := &testNode{hashes: tt.prevHashes}
+			_, err := client.checkTips(node, 100)
+			if err != nil {
+				t.Errorf("failed on %d: prev tips: %v", i, err)
+				continue
+			}
+		}
+
+		node := &testNode{hashes: tt.hashes}
+		forkHeight, err := client.checkTips(node, tt.height)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if forkHeight != tt.forkHeight {
+			t.Errorf("failed on %d: fork height mismatch: have %d, want %d", i, forkHeight, tt.forkHeight)
+		}
+
+		tips, err := client.tips.GetReorgChainTips("ETC")
+		if err != nil {
+			t.Errorf("failed on %d: get tips: %v", i, err)
+		} else if len(tips) != int(minTipWindow) {
+			t.Errorf("failed on %d: tip length mismatch: have %d, want %d", i, len(tips), minTipWindow)
+		} else if tips[tt.height] != tt.hashes[tt.height] {
+			t.Errorf("failed on %d: tip mismatch: have %s, want %s", i, tips[tt.height], tt.hashes[tt.height])
+		}
+	}
+}
+
+func TestRevalidateRound(t *testing.T) {
+	nonce := uint64(42)
+	round := &pooldb.Round{
+		Height: 90,
+		Hash:   "0x90",
+		Nonce:  &nonce,
+		Value:  dbcl.NullBigInt{BigInt: big.NewInt(1000), Valid: true},
+	}
+
+	node := &testNode{orphans: map[uint64]bool{90: true}}
+	valid, err := revalidateRound(node, round)
+	if err != nil {
+		t.Errorf("failed: %v", err)
+	} else if valid {
+		t.Errorf("failed: valid mismatch: have %t, want %t", valid, false)
+	} else if round.Orphan {
+		t.Errorf("failed: orphan mismatch: have %t, want %t", round.Orphan, false)
+	} else if *round.Nonce != 42 {
+		t.Errorf("failed: nonce mismatch: have %d, want %d", *round.Nonce, 42)
+	} else if round.Value.BigInt.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("failed: value mismatch: have %s, want %d", round.Value.BigInt, 1000)
+	}
+}
+
+func TestRevalidateRounds(t *testing.T) {
+	type testRound struct {
+		height  uint64
+		pending bool
+		held    bool
+	}
+
+	tests := []struct {
+		rounds    []testRound
+		orphans   map[uint64]bool
+		minHeight uint64
+		held      map[uint64]bool
+	}{
+		{
+			rounds:    []testRound{{height: 90}, {height: 95}},
+			orphans:   nil,
+			minHeight: 80,
+			held:      map[uint64]bool{},
+		},
+		{
+			rounds:    []testRound{{height: 90}, {height: 95}},
+			orphans:   map[uint64]bool{95: true},
+			minHeight: 80,
+			held:      map[uint64]bool{95: true},
+		},
+		{
+			// rounds below the minimum height are not revalidated
+			rounds:    []testRound{{height: 70}, {height: 95}},
+			orphans:   map[uint64]bool{70: true, 95: true},
+			minHeight: 80,
+			held:      map[uint64]bool{95: true},
+		},
+		{
+			// pending rounds are left to the unlocker
+			rounds:    []testRound{{height: 90, pending: true}},
+			orphans:   map[uint64]bool{90: true},
+			minHeight: 80,
+			held:      map[uint64]bool{},
+		},
+		{
+			// held rounds are revalidated regardless of height
+			rounds:    []testRound{{height: 50, held: true}, {height: 60, held: true}},
+			orphans:   map[uint64]bool{60: true},
+			minHeight: 80,
+			held:      map[uint64]bool{60: true},
+		},
+	}
+
+	for i, tt := range tests {
+		client := newTestClient()
+		for _, testRound := range tt.rounds {
+			round := &pooldb.Round{
+				ChainID: "ETC",
+				Height:  testRound.height,
+				Hash:    fmt.Sprintf("0x%d", testRound.height),
+				Pending: testRound.pending,
+				Mature:  !testRound.pending,
+			}
+
+			var err error
+			round.ID, err = client.store.Rounds().InsertRound(round)
+			if err != nil {
+				t.Fatalf("failed on %d: insert round: %v", i, err)
+			}
+
+			round.Held = testRound.held
+			err = client.store.Rounds().UpdateRound(round, []string{"held"})
+			if err != nil {
+				t.Fatalf("failed on %d: update round: %v", i, err)
+			}
+		}
+
+		node := &testNode{orphans: tt.orphans}
+		err := client.revalidateRounds(node, tt.minHeight)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		}
+
+		for id := range tt.rounds {
+			round, err := client.store.Rounds().GetRound(uint64(id + 1))
+			if err != nil {
+				t.Errorf("failed on %d: get round: %v", i, err)
+			} else if round.Held != tt.held[round.Height] {
+				t.Errorf("failed on %d: held mismatch for %d: have %t, want %t",
+					i, round.Height, round.Held, tt.held[round.Height])
+			}
+		}
+	}
+}
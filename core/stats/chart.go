@@ -408,7 +408,7 @@ func (c *Client) GetGlobalShareChart(
 	chain string,
 	period types.PeriodType,
 ) (*ShareChart, error) {
-	items, err := tsdb.GetGlobalShares(c.tsdb.Reader(), chain, int(period))
+	items, err := c.shares.GetGlobalShares(chain, int(period))
 	if err != nil {
 		return nil, err
 	}
@@ -420,7 +420,7 @@ func (c *Client) GetGlobalShareSingleMetricChart(
 	metric types.ShareMetric,
 	period types.PeriodType,
 ) (*ChartSingle, error) {
-	items, err := tsdb.GetGlobalSharesSingleMetric(c.tsdb.Reader(), string(metric), int(period))
+	items, err := c.shares.GetGlobalSharesSingleMetric(string(metric), int(period))
 	if err != nil {
 		return nil, err
 	}
@@ -433,7 +433,7 @@ func (c *Client) GetMinerShareChart(
 	chain string,
 	period types.PeriodType,
 ) (*ShareChart, error) {
-	items, err := tsdb.GetMinerShares(c.tsdb.Reader(), minerIDs, chain, int(period))
+	items, err := c.shares.GetMinerShares(minerIDs, chain, int(period))
 	if err != nil {
 		return nil, err
 	}
@@ -451,7 +451,7 @@ func (c *Client) GetMinerShareSingleMetricChart(
 	metric types.ShareMetric,
 	period types.PeriodType,
 ) (*ChartSingle, error) {
-	items, err := tsdb.GetMinerSharesSingleMetric(c.tsdb.Reader(), minerIDs, string(metric), int(period))
+	items, err := c.shares.GetMinerSharesSingleMetric(minerIDs, string(metric), int(period))
 	if err != nil {
 		return nil, err
 	}
@@ -469,7 +469,7 @@ func (c *Client) GetWorkerShareChart(
 	chain string,
 	period types.PeriodType,
 ) (*ShareChart, error) {
-	items, err := tsdb.GetWorkerShares(c.tsdb.Reader(), workerID, chain, int(period))
+	items, err := c.shares.GetWorkerShares(workerID, chain, int(period))
 	if err != nil {
 		return nil, err
 	}
@@ -482,7 +482,7 @@ func (c *Client) GetWorkerShareSingleMetricChart(
 	metric types.ShareMetric,
 	period types.PeriodType,
 ) (*ChartSingle, error) {
-	items, err := tsdb.GetWorkerSharesSingleMetric(c.tsdb.Reader(), workerID, string(metric), int(period))
+	items, err := c.shares.GetWorkerSharesSingleMetric(workerID, string(metric), int(period))
 	if err != nil {
 		return nil, err
 	}
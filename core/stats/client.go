@@ -3,6 +3,7 @@ package stats
 import (
 	"time"
 
+	"github.com/magicpool-co/pool/internal/accounting"
 	"github.com/magicpool-co/pool/internal/pooldb"
 	"github.com/magicpool-co/pool/internal/redis"
 	"github.com/magicpool-co/pool/internal/tsdb"
@@ -16,14 +17,17 @@ const (
 
 type Client struct {
 	useCache bool
+	poolFee  uint64
 	pooldb   *dbcl.Client
 	tsdb     *dbcl.Client
+	shares   tsdb.ShareStore
 	redis    *redis.Client
 	chains   map[string]bool
 }
 
 func New(
 	pooldbClient, tsdbClient *dbcl.Client,
+	shareStore tsdb.ShareStore,
 	redisClient *redis.Client,
 	chains []string,
 	cacheEnabled bool,
@@ -35,8 +39,10 @@ func New(
 
 	client := &Client{
 		useCache: cacheEnabled,
+		poolFee:  accounting.DefaultPoolFeeBasisPoints,
 		pooldb:   pooldbClient,
 		tsdb:     tsdbClient,
+		shares:   shareStore,
 		redis:    redisClient,
 		chains:   chainIdx,
 	}
@@ -44,6 +50,12 @@ func New(
 	return client
 }
 
+// SetPoolFee sets the pool fee (in basis points) that is displayed
+// and used to verify round distributions.
+func (c *Client) SetPoolFee(feeBasisPoints uint64) {
+	c.poolFee = feeBasisPoints
+}
+
 func (c *Client) processChainID(chainID string) (string, bool) {
 	if c.chains[chainID] {
 		return chainID, true
@@ -62,7 +74,7 @@ func (c *Client) getGlobalSharesLast() ([]*tsdb.Share, error) {
 		}
 	}
 
-	shares, err := tsdb.GetGlobalSharesLast(c.tsdb.Reader(), int(types.Period15m))
+	shares, err := c.shares.GetGlobalSharesLast(int(types.Period15m))
 	if err != nil {
 		return nil, err
 	}
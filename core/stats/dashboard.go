@@ -28,7 +28,7 @@ func (c *Client) processHashrateInfo(shares []*tsdb.Share) map[string]*HashrateI
 
 		var units string
 		switch share.ChainID {
-		case "FLUX":
+		case "FLUX", "ZEN":
 			units = "Sol/s"
 		default:
 			units = "H/s"
@@ -81,13 +81,13 @@ func (c *Client) GetMinerDashboard(minerIdx map[uint64]string) (*Dashboard, erro
 	}
 
 	// fetch last shares
-	lastShares, err := tsdb.GetMinersSharesLast(c.tsdb.Reader(), minerIDs, dashboardAggPeriod)
+	lastShares, err := c.shares.GetMinersSharesLast(minerIDs, dashboardAggPeriod)
 	if err != nil {
 		return nil, err
 	}
 
 	// fetch sum shares
-	sumShares, err := tsdb.GetMinersSharesSum(c.tsdb.Reader(), minerIDs, dashboardAggPeriod, dashboardAggDuration)
+	sumShares, err := c.shares.GetMinersSharesSum(minerIDs, dashboardAggPeriod, dashboardAggDuration)
 	if err != nil {
 		return nil, err
 	}
@@ -220,13 +220,13 @@ func (c *Client) GetMinerDashboard(minerIdx map[uint64]string) (*Dashboard, erro
 }
 
 func (c *Client) GetWorkerDashboard(workerID uint64) (*Dashboard, error) {
-	sumShares, err := tsdb.GetWorkerSharesSum(c.tsdb.Reader(),
+	sumShares, err := c.shares.GetWorkerSharesSum(
 		[]uint64{workerID}, dashboardAggPeriod, dashboardAggDuration)
 	if err != nil {
 		return nil, err
 	}
 
-	lastShares, err := tsdb.GetWorkerSharesLast(c.tsdb.Reader(), workerID, dashboardAggPeriod)
+	lastShares, err := c.shares.GetWorkerSharesLast(workerID, dashboardAggPeriod)
 	if err != nil {
 		return nil, err
 	}
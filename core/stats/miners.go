@@ -32,14 +32,14 @@ func (c *Client) GetMiners(chain string, page, size uint64) ([]*Miner, uint64, e
 	}
 
 	minerIDs := topMinerIDs[offset:limit]
-	dbShares, err := tsdb.GetMinerSharesByEndTime(c.tsdb.Reader(),
+	dbShares, err := c.shares.GetMinerSharesByEndTime(
 		timestamp, minerIDs, chain, int(types.Period15m))
 	if err != nil {
 		return nil, 0, err
 	}
 
 	// fetch solo shares since they're not included
-	dbSoloShares, err := tsdb.GetMinerSharesByEndTime(c.tsdb.Reader(),
+	dbSoloShares, err := c.shares.GetMinerSharesByEndTime(
 		timestamp, minerIDs, "S"+chain, int(types.Period15m))
 	if err != nil {
 		return nil, 0, err
@@ -128,7 +128,7 @@ func (c *Client) GetWorkers(minerID uint64) (*WorkerList, error) {
 		return nil, fmt.Errorf("no share timestamp found")
 	}
 
-	dbShares, err := tsdb.GetWorkerSharesAllChainsByEndTime(c.tsdb.Reader(),
+	dbShares, err := c.shares.GetWorkerSharesAllChainsByEndTime(
 		timestamp, workerIDs, int(types.Period15m))
 	if err != nil {
 		return nil, err
@@ -143,7 +143,7 @@ func (c *Client) GetWorkers(minerID uint64) (*WorkerList, error) {
 		dbSharesIdx[workerID] = append(dbSharesIdx[workerID], dbShare)
 	}
 
-	sumShares, err := tsdb.GetWorkerSharesSum(c.tsdb.Reader(), workerIDs,
+	sumShares, err := c.shares.GetWorkerSharesSum(workerIDs,
 		dashboardAggPeriod, dashboardAggDuration)
 	if err != nil {
 		return nil, err
@@ -13,6 +13,8 @@ func getTxExplorerURL(chain, hash string) (string, error) {
 	var explorerURL string
 	var err error
 	switch chain {
+	case "BCH":
+		explorerURL = "https://blockchair.com/bitcoin-cash/transaction/" + hash
 	case "BTC":
 		explorerURL = "https://blockchair.com/bitcoin/transaction/" + hash
 	case "CFX":
@@ -29,10 +31,14 @@ func getTxExplorerURL(chain, hash string) (string, error) {
 		explorerURL = "https://explorer.runonflux.io/tx/" + hash
 	case "KAS":
 		explorerURL = "https://explorer.kaspa.org/txs/" + hash
+	case "KLS":
+		explorerURL = "https://explorer.karlsencoin.com/txs/" + hash
 	case "NEXA":
 		explorerURL = "https://explorer.nexa.org/tx/" + hash
 	case "RVN":
 		explorerURL = "https://ravencoin.network/tx/" + hash
+	case "ZEN":
+		explorerURL = "https://explorer.horizen.io/tx/" + hash
 	case "USDC":
 		explorerURL = "https://etherscan.io/tx/" + hash
 	default:
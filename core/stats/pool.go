@@ -90,7 +90,7 @@ func (c *Client) GetPoolSummary(nodes []types.MiningNode) ([]*PoolSummary, error
 		stats[i] = &PoolSummary{
 			Name:               node.Name(),
 			Symbol:             chain,
-			Fee:                newNumberFromFloat64WithPrecision(0.01, 2, "%", false),
+			Fee:                newNumberFromFloat64WithPrecision(float64(c.poolFee)/100, 2, "%", false),
 			Miners:             miners,
 			MinersSolo:         minersSolo,
 			Workers:            workers,
@@ -0,0 +1,130 @@
+package stats
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/magicpool-co/pool/core/audit"
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/crypto/merkle"
+)
+
+func newReserveProof(dbProof *pooldb.ReserveProof) (*ReserveProof, error) {
+	if !dbProof.Liabilities.Valid {
+		return nil, fmt.Errorf("no liabilities for reserve proof %d", dbProof.ID)
+	} else if !dbProof.WalletBalance.Valid {
+		return nil, fmt.Errorf("no wallet balance for reserve proof %d", dbProof.ID)
+	}
+
+	liabilities, err := newNumberFromBigInt(dbProof.Liabilities.BigInt, dbProof.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	walletBalance, err := newNumberFromBigInt(dbProof.WalletBalance.BigInt, dbProof.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &ReserveProof{
+		Chain:            dbProof.ChainID,
+		MerkleRoot:       dbProof.MerkleRoot,
+		LeafCount:        dbProof.LeafCount,
+		Liabilities:      liabilities,
+		RawLiabilities:   dbProof.Liabilities.BigInt.String(),
+		WalletAddress:    dbProof.WalletAddress,
+		WalletBalance:    walletBalance,
+		RawWalletBalance: dbProof.WalletBalance.BigInt.String(),
+		Message:          dbProof.Message,
+		Signed:           dbProof.Signature != nil,
+		Signature:        dbProof.Signature,
+		Timestamp:        dbProof.CreatedAt.Unix(),
+	}
+
+	return proof, nil
+}
+
+func (c *Client) GetReserveProofs() ([]*ReserveProof, error) {
+	dbProofs, err := pooldb.GetLastReserveProofs(c.pooldb.Reader())
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]*ReserveProof, len(dbProofs))
+	for i, dbProof := range dbProofs {
+		proofs[i], err = newReserveProof(dbProof)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return proofs, nil
+}
+
+func (c *Client) GetMinerReserveProofs(minerIDs []uint64) ([]*MinerReserveProof, error) {
+	dbProofs, err := pooldb.GetLastReserveProofs(c.pooldb.Reader())
+	if err != nil {
+		return nil, err
+	}
+
+	minerIdx := make(map[uint64]bool, len(minerIDs))
+	for _, minerID := range minerIDs {
+		minerIdx[minerID] = true
+	}
+
+	minerProofs := make([]*MinerReserveProof, 0)
+	for _, dbProof := range dbProofs {
+		dbLeaves, err := pooldb.GetReserveProofLeaves(c.pooldb.Reader(), dbProof.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		var leaves []merkle.SumNode
+		for _, dbLeaf := range dbLeaves {
+			if !minerIdx[dbLeaf.MinerID] {
+				continue
+			} else if leaves == nil {
+				leaves, err = audit.RebuildReserveProofLeaves(dbProof, dbLeaves)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			reserveProof, err := newReserveProof(dbProof)
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := newNumberFromBigInt(dbLeaf.Value.BigInt, dbProof.ChainID)
+			if err != nil {
+				return nil, err
+			}
+
+			steps, err := merkle.CalculateSumProof(leaves, int(dbLeaf.Idx))
+			if err != nil {
+				return nil, err
+			}
+
+			proof := make([]*ReserveProofStep, len(steps))
+			for i, step := range steps {
+				proof[i] = &ReserveProofStep{
+					Hash: hex.EncodeToString(step.Hash),
+					Sum:  step.Sum.String(),
+				}
+			}
+
+			minerProofs = append(minerProofs, &MinerReserveProof{
+				ReserveProof: reserveProof,
+				MinerID:      dbLeaf.MinerID,
+				Nonce:        dbProof.Nonce,
+				LeafIndex:    dbLeaf.Idx,
+				LeafHash:     hex.EncodeToString(leaves[dbLeaf.Idx].Hash),
+				Value:        value,
+				RawValue:     dbLeaf.Value.BigInt.String(),
+				Proof:        proof,
+			})
+		}
+	}
+
+	return minerProofs, nil
+}
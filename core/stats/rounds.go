@@ -1,13 +1,17 @@
 package stats
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/magicpool-co/pool/internal/accounting"
 	"github.com/magicpool-co/pool/internal/pooldb"
 	"github.com/magicpool-co/pool/pkg/common"
+	"github.com/magicpool-co/pool/pkg/crypto"
 	"github.com/magicpool-co/pool/types"
 )
 
@@ -17,6 +21,10 @@ func getBlockExplorerURL(chain, hash string, height uint64) (string, error) {
 	var explorerURL string
 	var err error
 	switch chain {
+	case "BCH":
+		explorerURL = "https://blockchair.com/bitcoin-cash/block/" + hash
+	case "BTC":
+		explorerURL = "https://mempool.space/block/" + hash
 	case "CFX":
 		explorerURL = "https://www.confluxscan.io/block/" + hash
 	case "ERG":
@@ -29,10 +37,14 @@ func getBlockExplorerURL(chain, hash string, height uint64) (string, error) {
 		explorerURL = "https://explorer.runonflux.io/block/" + hash
 	case "KAS":
 		explorerURL = "https://explorer.kaspa.org/blocks/" + hash
+	case "KLS":
+		explorerURL = "https://explorer.karlsencoin.com/blocks/" + hash
 	case "NEXA":
 		explorerURL = "https://explorer.nexa.org/block/" + hash
 	case "RVN":
 		explorerURL = "https://ravencoin.network/block/" + hash
+	case "ZEN":
+		explorerURL = "https://explorer.horizen.io/block/" + hash
 	default:
 		err = fmt.Errorf("no block explorer found for chain")
 	}
@@ -95,6 +107,7 @@ func newRound(dbRound *pooldb.Round) (*Round, error) {
 	}
 
 	round := &Round{
+		ID:              dbRound.ID,
 		Chain:           dbRound.ChainID,
 		Type:            roundType,
 		Pool:            poolType,
@@ -191,3 +204,332 @@ func (c *Client) GetMinerRounds(
 
 	return rounds, count, nil
 }
+
+func obscureAddress(address string) string {
+	if parts := strings.Split(address, ":"); len(parts) > 1 {
+		address = parts[len(parts)-1]
+	}
+
+	if len(address) <= 12 {
+		return address
+	}
+
+	return address[:6] + "..." + address[len(address)-6:]
+}
+
+// getRoundRewardSources splits the round's value into the block (or uncle) reward and
+// the transaction fees. tx fees are only stored for chains where the node reports them
+// separately, otherwise they are included in the block reward.
+func getRoundRewardSources(dbRound *pooldb.Round) ([]*RoundRewardSource, error) {
+	source := "block"
+	if dbRound.Uncle {
+		source = "uncle"
+	}
+
+	reward := new(big.Int)
+	if dbRound.Value.Valid {
+		reward.Set(dbRound.Value.BigInt)
+	}
+
+	txFees := new(big.Int)
+	if dbRound.TxFees.Valid {
+		txFees.Set(dbRound.TxFees.BigInt)
+	}
+
+	if txFees.Cmp(reward) > 0 {
+		return nil, fmt.Errorf("tx fees greater than value for round %d", dbRound.ID)
+	}
+	reward.Sub(reward, txFees)
+
+	values := []*big.Int{reward}
+	sources := []string{source}
+	if txFees.Cmp(common.Big0) > 0 {
+		values = append(values, txFees)
+		sources = append(sources, "fees")
+	}
+
+	rewardSources := make([]*RoundRewardSource, len(values))
+	for i, value := range values {
+		parsedValue, err := newNumberFromBigInt(value, dbRound.ChainID)
+		if err != nil {
+			return nil, err
+		}
+
+		rewardSources[i] = &RoundRewardSource{
+			Source:   sources[i],
+			Value:    parsedValue,
+			RawValue: value.String(),
+		}
+	}
+
+	return rewardSources, nil
+}
+
+// hashRoundShares creates a hash of the full distribution, built from the values as they are published so that
+// anyone is able to reproduce it: sha256 of "id,chain,value\n" followed by "miner,shares,value,poolFees\n" per row.
+func hashRoundShares(dbRound *pooldb.Round, shares []*RoundShare) string {
+	value := new(big.Int)
+	if dbRound.Value.Valid {
+		value.Set(dbRound.Value.BigInt)
+	}
+
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("%d,%s,%s\n", dbRound.ID, dbRound.ChainID, value))
+	for _, share := range shares {
+		buf.WriteString(fmt.Sprintf("%s,%d,%s,%s\n",
+			share.Miner, share.Shares, share.RawValue, share.RawPoolFees))
+	}
+
+	return hex.EncodeToString(crypto.Sha256([]byte(buf.String())))
+}
+
+// checkRoundCredit re-runs the crediting of the round with the stored shares, the current
+// pool fee and the fee recipients of the round, verifying that it reproduces the credited values.
+func checkRoundCredit(
+	dbRound *pooldb.Round,
+	feeBasisPoints uint64,
+	shareIdx map[uint64]uint64,
+	recipientIdx map[uint64]uint64,
+	creditedIdx map[uint64]*big.Int,
+) bool {
+	if !dbRound.Value.Valid || len(shareIdx) == 0 || len(creditedIdx) == 0 {
+		return false
+	}
+
+	// self-paid rounds only credit the fee output, split between the recipients
+	var values map[uint64]*big.Int
+	var err error
+	if dbRound.SelfPaid {
+		values, err = accounting.SplitFee(dbRound.Value.BigInt, recipientIdx)
+	} else {
+		values, _, err = accounting.CreditRoundWithFee(dbRound.Value.BigInt, feeBasisPoints, shareIdx, recipientIdx)
+	}
+	if err != nil {
+		return false
+	}
+
+	for minerID, value := range values {
+		if value.Cmp(common.Big0) == 0 {
+			continue
+		} else if credited, ok := creditedIdx[minerID]; !ok || credited.Cmp(value) != 0 {
+			return false
+		}
+	}
+
+	for minerID, credited := range creditedIdx {
+		if value, ok := values[minerID]; !ok || credited.Cmp(value) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getRoundRecipientIdx returns the fee percent of every fee recipient of the round, as
+// they were when the round was credited. rounds credited before the recipients were
+// stored (or not credited yet) fall back to the current recipients.
+func (c *Client) getRoundRecipientIdx(roundID uint64) (map[uint64]uint64, error) {
+	dbRoundRecipients, err := pooldb.GetRoundRecipients(c.pooldb.Reader(), roundID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipientIdx := make(map[uint64]uint64)
+	for _, dbRoundRecipient := range dbRoundRecipients {
+		recipientIdx[dbRoundRecipient.MinerID] += dbRoundRecipient.FeePercent
+	}
+
+	if len(recipientIdx) > 0 {
+		return recipientIdx, nil
+	}
+
+	dbRecipients, err := pooldb.GetRecipients(c.pooldb.Reader())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dbRecipient := range dbRecipients {
+		recipientIdx[dbRecipient.ID] += types.Uint64Value(dbRecipient.RecipientFeePercent)
+	}
+
+	return recipientIdx, nil
+}
+
+func (c *Client) getRoundDistribution(roundID uint64) (*pooldb.Round, []*RoundShare, bool, error) {
+	dbRound, err := pooldb.GetRound(c.pooldb.Reader(), roundID)
+	if err != nil || dbRound == nil {
+		return nil, nil, false, err
+	}
+
+	dbShares, err := pooldb.GetSharesByRound(c.pooldb.Reader(), roundID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	dbBalanceInputs, err := pooldb.GetBalanceInputsByRound(c.pooldb.Reader(), roundID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	recipientIdx, err := c.getRoundRecipientIdx(roundID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	var totalShares uint64
+	shareIdx := make(map[uint64]uint64)
+	minerIDIdx := make(map[uint64]bool)
+	for _, dbShare := range dbShares {
+		shareIdx[dbShare.MinerID] += dbShare.Count
+		minerIDIdx[dbShare.MinerID] = true
+		totalShares += dbShare.Count
+	}
+
+	valueIdx := make(map[uint64]*big.Int)
+	poolFeeIdx := make(map[uint64]*big.Int)
+	for _, dbBalanceInput := range dbBalanceInputs {
+		minerID := dbBalanceInput.MinerID
+		if _, ok := valueIdx[minerID]; !ok {
+			valueIdx[minerID] = new(big.Int)
+			poolFeeIdx[minerID] = new(big.Int)
+		}
+
+		if dbBalanceInput.Value.Valid {
+			valueIdx[minerID].Add(valueIdx[minerID], dbBalanceInput.Value.BigInt)
+		}
+		if dbBalanceInput.PoolFees.Valid {
+			poolFeeIdx[minerID].Add(poolFeeIdx[minerID], dbBalanceInput.PoolFees.BigInt)
+		}
+		minerIDIdx[minerID] = true
+	}
+
+	minerIDs := make([]uint64, 0, len(minerIDIdx))
+	for minerID := range minerIDIdx {
+		minerIDs = append(minerIDs, minerID)
+	}
+
+	dbMiners, err := pooldb.GetMiners(c.pooldb.Reader(), minerIDs)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	shares := make([]*RoundShare, 0, len(dbMiners))
+	for _, dbMiner := range dbMiners {
+		value, poolFees := valueIdx[dbMiner.ID], poolFeeIdx[dbMiner.ID]
+		if value == nil {
+			value, poolFees = new(big.Int), new(big.Int)
+		}
+
+		parsedValue, err := newNumberFromBigInt(value, dbRound.ChainID)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		parsedPoolFees, err := newNumberFromBigInt(poolFees, dbRound.ChainID)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		var sharePercentage float64
+		if totalShares > 0 {
+			sharePercentage = 100 * float64(shareIdx[dbMiner.ID]) / float64(totalShares)
+		}
+
+		shares = append(shares, &RoundShare{
+			Miner:           obscureAddress(dbMiner.Address),
+			Recipient:       recipientIdx[dbMiner.ID] > 0,
+			Shares:          shareIdx[dbMiner.ID],
+			SharePercentage: newNumberFromFloat64WithPrecision(sharePercentage, 4, "%", false),
+			Value:           parsedValue,
+			RawValue:        value.String(),
+			PoolFees:        parsedPoolFees,
+			RawPoolFees:     poolFees.String(),
+		})
+	}
+
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Shares != shares[j].Shares {
+			return shares[i].Shares > shares[j].Shares
+		} else if shares[i].Miner != shares[j].Miner {
+			return shares[i].Miner < shares[j].Miner
+		}
+		return shares[i].RawValue > shares[j].RawValue
+	})
+
+	reproducible := checkRoundCredit(dbRound, c.poolFee, shareIdx, recipientIdx, valueIdx)
+
+	return dbRound, shares, reproducible, nil
+}
+
+func newRoundDetails(dbRound *pooldb.Round, shares []*RoundShare, reproducible bool) (*RoundDetails, error) {
+	round, err := newRound(dbRound)
+	if err != nil {
+		return nil, err
+	}
+
+	rewardSources, err := getRoundRewardSources(dbRound)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalShares uint64
+	creditedValue, poolFees := new(big.Int), new(big.Int)
+	for _, share := range shares {
+		totalShares += share.Shares
+		value, _ := new(big.Int).SetString(share.RawValue, 10)
+		fees, _ := new(big.Int).SetString(share.RawPoolFees, 10)
+		creditedValue.Add(creditedValue, value)
+		poolFees.Add(poolFees, fees)
+	}
+
+	parsedCreditedValue, err := newNumberFromBigInt(creditedValue, dbRound.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedPoolFees, err := newNumberFromBigInt(poolFees, dbRound.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &RoundDetails{
+		Round:            round,
+		RewardSources:    rewardSources,
+		Participants:     len(shares),
+		TotalShares:      totalShares,
+		CreditedValue:    parsedCreditedValue,
+		RawCreditedValue: creditedValue.String(),
+		PoolFees:         parsedPoolFees,
+		RawPoolFees:      poolFees.String(),
+		Credited:         creditedValue.Cmp(common.Big0) > 0,
+		Reproducible:     reproducible,
+		DistributionHash: hashRoundShares(dbRound, shares),
+	}
+
+	return details, nil
+}
+
+func (c *Client) GetRoundDetails(roundID uint64) (*RoundDetails, error) {
+	dbRound, shares, reproducible, err := c.getRoundDistribution(roundID)
+	if err != nil || dbRound == nil {
+		return nil, err
+	}
+
+	return newRoundDetails(dbRound, shares, reproducible)
+}
+
+// GetRoundShares returns the details of the round along with its shares, computing
+// the distribution once for both.
+func (c *Client) GetRoundShares(roundID uint64) (*RoundDetails, []*RoundShare, error) {
+	dbRound, shares, reproducible, err := c.getRoundDistribution(roundID)
+	if err != nil || dbRound == nil {
+		return nil, nil, err
+	}
+
+	details, err := newRoundDetails(dbRound, shares, reproducible)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return details, shares, nil
+}
@@ -0,0 +1,183 @@
+package stats
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+)
+
+func newTestNullBigInt(value int64) dbcl.NullBigInt {
+	return dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetInt64(value)}
+}
+
+func TestGetRoundRewardSources(t *testing.T) {
+	tests := []struct {
+		round   *pooldb.Round
+		sources []string
+		values  []string
+		err     bool
+	}{
+		{
+			round:   &pooldb.Round{ChainID: "ETC", Value: newTestNullBigInt(2_100)},
+			sources: []string{"block"},
+			values:  []string{"2100"},
+		},
+		{
+			round:   &pooldb.Round{ChainID: "ETC", Value: newTestNullBigInt(2_100), TxFees: newTestNullBigInt(100)},
+			sources: []string{"block", "fees"},
+			values:  []string{"2000", "100"},
+		},
+		{
+			round:   &pooldb.Round{ChainID: "ETC", Uncle: true, Value: newTestNullBigInt(1_500), TxFees: newTestNullBigInt(0)},
+			sources: []string{"uncle"},
+			values:  []string{"1500"},
+		},
+		{
+			round:   &pooldb.Round{ChainID: "ETC", Pending: true},
+			sources: []string{"block"},
+			values:  []string{"0"},
+		},
+		{
+			round: &pooldb.Round{ChainID: "ETC", Value: newTestNullBigInt(100), TxFees: newTestNullBigInt(200)},
+			err:   true,
+		},
+	}
+
+	for i, tt := range tests {
+		rewardSources, err := getRoundRewardSources(tt.round)
+		if tt.err {
+			if err == nil {
+				t.Errorf("failed on %d: expected error", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		}
+
+		sources := make([]string, len(rewardSources))
+		values := make([]string, len(rewardSources))
+		for j, rewardSource := range rewardSources {
+			sources[j] = rewardSource.Source
+			values[j] = rewardSource.RawValue
+		}
+
+		if !reflect.DeepEqual(sources, tt.sources) {
+			t.Errorf("failed on %d: source mismatch: have %v, want %v", i, sources, tt.sources)
+		} else if !reflect.DeepEqual(values, tt.values) {
+			t.Errorf("failed on %d: value mismatch: have %v, want %v", i, values, tt.values)
+		}
+	}
+}
+
+func TestCheckRoundCredit(t *testing.T) {
+	round := &pooldb.Round{ID: 1, ChainID: "ETC", Value: newTestNullBigInt(10_000)}
+	shareIdx := map[uint64]uint64{1: 10, 2: 10}
+
+	// credited with a 1% fee, fully paid to recipient 3
+	creditedIdx := map[uint64]*big.Int{
+		1: new(big.Int).SetUint64(4_950),
+		2: new(big.Int).SetUint64(4_950),
+		3: new(big.Int).SetUint64(100),
+	}
+
+	tests := []struct {
+		selfPaid     bool
+		recipientIdx map[uint64]uint64
+		creditedIdx  map[uint64]*big.Int
+		reproducible bool
+	}{
+		{
+			recipientIdx: map[uint64]uint64{3: 100},
+			creditedIdx:  creditedIdx,
+			reproducible: true,
+		},
+		{
+			// the recipients changed after the round was credited
+			recipientIdx: map[uint64]uint64{3: 50, 4: 50},
+			creditedIdx:  creditedIdx,
+			reproducible: false,
+		},
+		{
+			recipientIdx: map[uint64]uint64{3: 100},
+			creditedIdx: map[uint64]*big.Int{
+				1: new(big.Int).SetUint64(5_000),
+				2: new(big.Int).SetUint64(4_900),
+				3: new(big.Int).SetUint64(100),
+			},
+			reproducible: false,
+		},
+		{
+			recipientIdx: map[uint64]uint64{3: 100},
+			creditedIdx:  map[uint64]*big.Int{},
+			reproducible: false,
+		},
+		{
+			// self-paid rounds credit the whole fee output to the recipients
+			selfPaid:     true,
+			recipientIdx: map[uint64]uint64{3: 50, 4: 50},
+			creditedIdx: map[uint64]*big.Int{
+				3: new(big.Int).SetUint64(5_000),
+				4: new(big.Int).SetUint64(5_000),
+			},
+			reproducible: true,
+		},
+		{
+			selfPaid:     true,
+			recipientIdx: map[uint64]uint64{3: 100},
+			creditedIdx:  creditedIdx,
+			reproducible: false,
+		},
+	}
+
+	for i, tt := range tests {
+		round.SelfPaid = tt.selfPaid
+		reproducible := checkRoundCredit(round, 100, shareIdx, tt.recipientIdx, tt.creditedIdx)
+		if reproducible != tt.reproducible {
+			t.Errorf("failed on %d: reproducible mismatch: have %t, want %t", i, reproducible, tt.reproducible)
+		}
+	}
+}
+
+func TestNewRoundDetails(t *testing.T) {
+	round := &pooldb.Round{
+		ID:      1,
+		ChainID: "ETC",
+		Value:   newTestNullBigInt(10_000),
+		TxFees:  newTestNullBigInt(250),
+	}
+
+	shares := []*RoundShare{
+		&RoundShare{Miner: "0x0101...010101", Shares: 10, RawValue: "4950", RawPoolFees: "50"},
+		&RoundShare{Miner: "0x0202...020202", Shares: 10, RawValue: "4950", RawPoolFees: "50"},
+		&RoundShare{Miner: "0x0303...030303", Recipient: true, RawValue: "100", RawPoolFees: "0"},
+	}
+
+	details, err := newRoundDetails(round, shares, true)
+	if err != nil {
+		t.Fatalf("failed to create details: %v", err)
+	}
+
+	if details.Participants != 3 || details.TotalShares != 20 {
+		t.Errorf("participant mismatch: have %d (%d shares)", details.Participants, details.TotalShares)
+	} else if details.RawCreditedValue != "10000" || details.RawPoolFees != "100" {
+		t.Errorf("credit mismatch: have %s (%s fees)", details.RawCreditedValue, details.RawPoolFees)
+	} else if !details.Credited || !details.Reproducible {
+		t.Errorf("state mismatch: have credited %t, reproducible %t", details.Credited, details.Reproducible)
+	} else if len(details.RewardSources) != 2 || details.RewardSources[1].RawValue != "250" {
+		t.Errorf("reward source mismatch: have %d sources", len(details.RewardSources))
+	}
+
+	// the hash only depends on the published values
+	if hash := hashRoundShares(round, shares); hash != details.DistributionHash {
+		t.Errorf("hash mismatch: have %s, want %s", hash, details.DistributionHash)
+	}
+
+	shares[0].RawValue = "4951"
+	if hash := hashRoundShares(round, shares); hash == details.DistributionHash {
+		t.Errorf("hash unchanged after modifying a share")
+	}
+}
@@ -18,7 +18,7 @@ func decimalsForChain(chain string) int {
 	switch chain {
 	case "NEXA":
 		return 1
-	case "KAS", "USD", "USDT", "BUSD":
+	case "KAS", "KLS", "USD", "USDT", "BUSD":
 		return 2
 	case "BTC":
 		return 6
@@ -204,6 +204,7 @@ type Dashboard struct {
 /* rounds */
 
 type Round struct {
+	ID              uint64  `json:"id"`
 	Chain           string  `json:"chain"`
 	Type            string  `json:"type"`
 	Pool            string  `json:"pool"`
@@ -222,6 +223,37 @@ type Round struct {
 	Timestamp       int64   `json:"timestamp"`
 }
 
+type RoundShare struct {
+	Miner           string `json:"miner"`
+	Recipient       bool   `json:"recipient"`
+	Shares          uint64 `json:"shares"`
+	SharePercentage Number `json:"sharePercentage"`
+	Value           Number `json:"value"`
+	RawValue        string `json:"rawValue"`
+	PoolFees        Number `json:"poolFees"`
+	RawPoolFees     string `json:"rawPoolFees"`
+}
+
+type RoundRewardSource struct {
+	Source   string `json:"source"`
+	Value    Number `json:"value"`
+	RawValue string `json:"rawValue"`
+}
+
+type RoundDetails struct {
+	Round            *Round               `json:"round"`
+	RewardSources    []*RoundRewardSource `json:"rewardSources"`
+	Participants     int                  `json:"participants"`
+	TotalShares      uint64               `json:"totalShares"`
+	CreditedValue    Number               `json:"creditedValue"`
+	RawCreditedValue string               `json:"rawCreditedValue"`
+	PoolFees         Number               `json:"poolFees"`
+	RawPoolFees      string               `json:"rawPoolFees"`
+	Credited         bool                 `json:"credited"`
+	Reproducible     bool                 `json:"reproducible"`
+	DistributionHash string               `json:"distributionHash"`
+}
+
 /* payouts */
 
 type Payout struct {
@@ -340,3 +372,38 @@ func (chart *ShareChart) AddPoint(share *tsdb.Share) {
 	chart.Hashrate = append(chart.Hashrate, common.SafeRoundedFloat(share.Hashrate, 3))
 	chart.AvgHashrate = append(chart.AvgHashrate, common.SafeRoundedFloat(share.AvgHashrate, 3))
 }
+
+/* reserves */
+
+// ReserveProof is a published proof of reserves. Signed is false for the wallets
+// that can't sign messages (KAS, KLS, NEXA and ERG), which have no signature.
+type ReserveProof struct {
+	Chain            string  `json:"chain"`
+	MerkleRoot       string  `json:"merkleRoot"`
+	LeafCount        uint64  `json:"leafCount"`
+	Liabilities      Number  `json:"liabilities"`
+	RawLiabilities   string  `json:"rawLiabilities"`
+	WalletAddress    string  `json:"walletAddress"`
+	WalletBalance    Number  `json:"walletBalance"`
+	RawWalletBalance string  `json:"rawWalletBalance"`
+	Message          string  `json:"message"`
+	Signed           bool    `json:"signed"`
+	Signature        *string `json:"signature"`
+	Timestamp        int64   `json:"timestamp"`
+}
+
+type ReserveProofStep struct {
+	Hash string `json:"hash"`
+	Sum  string `json:"sum"`
+}
+
+type MinerReserveProof struct {
+	ReserveProof *ReserveProof       `json:"reserveProof"`
+	MinerID      uint64              `json:"minerId"`
+	Nonce        string              `json:"nonce"`
+	LeafIndex    uint64              `json:"leafIndex"`
+	LeafHash     string              `json:"leafHash"`
+	Value        Number              `json:"value"`
+	RawValue     string              `json:"rawValue"`
+	Proof        []*ReserveProofStep `json:"proof"`
+}
@@ -83,11 +83,13 @@ const (
 	WithdrawalsActive
 	WithdrawalsComplete
 	BatchComplete
+	BatchCancelled
 )
 
 type Client struct {
 	exchanges map[int]types.Exchange
 	pooldb    *dbcl.Client
+	store     pooldb.Store
 	redis     *redis.Client
 	nodes     map[string]types.PayoutNode
 	telegram  *telegram.Client
@@ -114,6 +116,7 @@ func New(
 	client := &Client{
 		exchanges: exchangesIdx,
 		pooldb:    pooldbClient,
+		store:     pooldb.NewStore(pooldbClient),
 		redis:     redisClient,
 		nodes:     nodeIdx,
 		telegram:  telegramClient,
@@ -123,9 +126,9 @@ func New(
 	return client
 }
 
-func (c *Client) updateBatchStatus(q dbcl.Querier, batchID uint64, status Status) error {
+func newBatchStatusUpdate(batchID uint64, status Status) (*pooldb.ExchangeBatch, []string) {
 	var completedAt *time.Time
-	if status == BatchComplete {
+	if status == BatchComplete || status == BatchCancelled {
 		completedAt = types.TimePtr(time.Now())
 	}
 
@@ -136,7 +139,13 @@ func (c *Client) updateBatchStatus(q dbcl.Querier, batchID uint64, status Status
 	}
 	cols := []string{"status", "completed_at"}
 
-	return pooldb.UpdateExchangeBatch(q, batch, cols)
+	return batch, cols
+}
+
+func (c *Client) updateBatchStatus(store pooldb.Store, batchID uint64, status Status) error {
+	batch, cols := newBatchStatusUpdate(batchID, status)
+
+	return store.Exchanges().UpdateExchangeBatch(batch, cols)
 }
 
 /* core methods */
@@ -211,7 +220,7 @@ func (c *Client) calculateOutputPathsByBalanceInputs(
 }
 
 func (c *Client) checkForNewBatch(exchange types.Exchange) error {
-	activeBatches, err := pooldb.GetActiveExchangeBatches(c.pooldb.Reader(), uint64(exchange.ID()))
+	activeBatches, err := c.store.Exchanges().GetActiveExchangeBatches(uint64(exchange.ID()))
 	if err != nil {
 		return err
 	} else if len(activeBatches) > 0 {
@@ -221,8 +230,9 @@ func (c *Client) checkForNewBatch(exchange types.Exchange) error {
 	// first sum all balance inputs as a preliminary check to avoid database I/O.
 	// since it is just the sum, the same check has to be run again with all pending
 	// balance inputs, but this is a way to avoid having to receive thousands of balance
-	// inputs from the database every 5 minutes.
-	balanceInputSums, err := pooldb.GetPendingBalanceInputsSumWithoutBatch(c.pooldb.Reader())
+	// inputs from the database every 5 minutes. balance inputs from rounds that
+	// are held after a reorg are skipped until the hold is released.
+	balanceInputSums, err := c.store.Balances().GetPendingBalanceInputsSumWithoutBatch()
 	if err != nil {
 		return err
 	}
@@ -236,7 +246,7 @@ func (c *Client) checkForNewBatch(exchange types.Exchange) error {
 
 	// since at least some exchange paths are passing the thresholds, re-run the
 	// check with all of the balance inputs this time.
-	balanceInputs, err := pooldb.GetPendingBalanceInputsWithoutBatch(c.pooldb.Reader())
+	balanceInputs, err := c.store.Balances().GetPendingBalanceInputsWithoutBatch()
 	if err != nil {
 		return err
 	}
@@ -251,69 +261,67 @@ func (c *Client) checkForNewBatch(exchange types.Exchange) error {
 	// create a db tx to make sure the batch, all of the trade paths,
 	// and all of the balance inputs are inserted (or updated). if
 	// anything fails, rollback the tx
-	tx, err := c.pooldb.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.SafeRollback()
-
-	batch := &pooldb.ExchangeBatch{
-		ExchangeID: int(exchange.ID()),
-		Status:     int(BatchInactive),
-	}
-
-	batchID, err := pooldb.InsertExchangeBatch(tx, batch)
-	if err != nil {
-		return err
-	}
-
-	for _, balanceInput := range balanceInputs {
-		// verify that the path is actually in the batch, to avoid
-		// balance inputs that are not included as inputs having
-		// a batch ID set
-		if _, ok := outputPaths[balanceInput.ChainID]; !ok {
-			continue
-		} else if _, ok := outputPaths[balanceInput.ChainID][balanceInput.OutChainID]; !ok {
-			continue
+	var batchID uint64
+	err = c.store.Transact(func(tx pooldb.Store) error {
+		batch := &pooldb.ExchangeBatch{
+			ExchangeID: int(exchange.ID()),
+			Status:     int(BatchInactive),
 		}
 
-		balanceInput.BatchID = types.Uint64Ptr(batchID)
-		err = pooldb.UpdateBalanceInput(tx, balanceInput, []string{"batch_id"})
+		var err error
+		batchID, err = tx.Exchanges().InsertExchangeBatch(batch)
 		if err != nil {
 			return err
 		}
-	}
 
-	// calculate the exchange inputs based off of the output paths
-	// that meet both the input thresholds and output thresholds (these
-	// are held in the db to avoid having to recalculate the output paths,
-	// since it is an expensive calculation and prices could change).
-	exchangeInputs := make([]*pooldb.ExchangeInput, 0)
-	for inChainID, outputIdx := range outputPaths {
-		for outChainID, value := range outputIdx {
-			exchangeInput := &pooldb.ExchangeInput{
-				BatchID:    batchID,
-				InChainID:  inChainID,
-				OutChainID: outChainID,
-
-				Value: dbcl.NullBigInt{Valid: true, BigInt: value},
+		for _, balanceInput := range balanceInputs {
+			// verify that the path is actually in the batch, to avoid
+			// balance inputs that are not included as inputs having
+			// a batch ID set
+			if _, ok := outputPaths[balanceInput.ChainID]; !ok {
+				continue
+			} else if _, ok := outputPaths[balanceInput.ChainID][balanceInput.OutChainID]; !ok {
+				continue
+			}
+
+			balanceInput.BatchID = types.Uint64Ptr(batchID)
+			err = tx.Balances().UpdateBalanceInput(balanceInput, []string{"batch_id"})
+			if err != nil {
+				return err
 			}
-			exchangeInputs = append(exchangeInputs, exchangeInput)
 		}
-	}
 
-	err = pooldb.InsertExchangeInputs(tx, exchangeInputs...)
+		// calculate the exchange inputs based off of the output paths
+		// that meet both the input thresholds and output thresholds (these
+		// are held in the db to avoid having to recalculate the output paths,
+		// since it is an expensive calculation and prices could change).
+		exchangeInputs := make([]*pooldb.ExchangeInput, 0)
+		for inChainID, outputIdx := range outputPaths {
+			for outChainID, value := range outputIdx {
+				exchangeInput := &pooldb.ExchangeInput{
+					BatchID:    batchID,
+					InChainID:  inChainID,
+					OutChainID: outChainID,
+
+					Value: dbcl.NullBigInt{Valid: true, BigInt: value},
+				}
+				exchangeInputs = append(exchangeInputs, exchangeInput)
+			}
+		}
+
+		return tx.Exchanges().InsertExchangeInputs(exchangeInputs...)
+	})
 	if err != nil {
 		return err
 	}
 
 	c.telegram.NotifyInitiateExchangeBatch(batchID)
 
-	return tx.SafeCommit()
+	return nil
 }
 
 func (c *Client) ProcessBatch(batchID uint64) error {
-	batch, err := pooldb.GetExchangeBatch(c.pooldb.Reader(), batchID)
+	batch, err := c.store.Exchanges().GetExchangeBatch(batchID)
 	if err != nil {
 		return err
 	} else if batch.ID != batchID {
@@ -353,9 +361,39 @@ func (c *Client) ProcessBatch(batchID uint64) error {
 		return c.ConfirmWithdrawals(batchID, exchange)
 	case WithdrawalsComplete:
 		return c.CreditWithdrawals(batchID)
-	case BatchComplete:
+	case BatchComplete, BatchCancelled:
 		return nil
 	default:
 		return fmt.Errorf("unknown batch status %d", batch.Status)
 	}
 }
+
+// CancelBatch cancels a batch before any deposits have been made, releasing its
+// balance inputs so they are picked up by the next batch.
+func (c *Client) CancelBatch(batchID uint64) error {
+	return c.store.Transact(func(tx pooldb.Store) error {
+		batch, err := tx.Exchanges().GetExchangeBatch(batchID)
+		if err != nil {
+			return err
+		} else if batch == nil {
+			return fmt.Errorf("batch not found")
+		}
+
+		// the status is only updated if the batch is still inactive, since the
+		// trade job can initiate it between the read and the update
+		batch, _ = newBatchStatusUpdate(batchID, BatchCancelled)
+		updated, err := tx.Exchanges().UpdateExchangeBatchStatus(batch, int(BatchInactive))
+		if err != nil {
+			return err
+		} else if !updated {
+			return fmt.Errorf("batch %d has already been initiated", batchID)
+		}
+
+		err = tx.Balances().UpdateBalanceInputsUnsetBatch(batchID)
+		if err != nil {
+			return err
+		}
+
+		return tx.Exchanges().DeleteExchangeInputsByBatch(batchID)
+	})
+}
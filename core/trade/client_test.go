@@ -0,0 +1,122 @@
+package trade
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
+)
+
+func TestCancelBatch(t *testing.T) {
+	store := pooldb.NewMemoryStore()
+	client := &Client{store: store}
+
+	batchID, err := store.Exchanges().InsertExchangeBatch(&pooldb.ExchangeBatch{
+		ExchangeID: int(types.KucoinID),
+		Status:     int(BatchInactive),
+	})
+	if err != nil {
+		t.Fatalf("failed to insert batch: %v", err)
+	}
+
+	err = store.Balances().InsertBalanceInputs(&pooldb.BalanceInput{
+		RoundID:    1,
+		ChainID:    "ETC",
+		MinerID:    1,
+		OutChainID: "BTC",
+		BatchID:    types.Uint64Ptr(batchID),
+		Value:      dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(100)},
+		PoolFees:   dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+		Mature:     true,
+		Pending:    true,
+	})
+	if err != nil {
+		t.Fatalf("failed to insert balance input: %v", err)
+	}
+
+	err = store.Exchanges().InsertExchangeInputs(&pooldb.ExchangeInput{
+		BatchID:    batchID,
+		InChainID:  "ETC",
+		OutChainID: "BTC",
+		Value:      dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(100)},
+	})
+	if err != nil {
+		t.Fatalf("failed to insert exchange input: %v", err)
+	}
+
+	err = client.CancelBatch(batchID)
+	if err != nil {
+		t.Fatalf("failed to cancel batch: %v", err)
+	}
+
+	batch, err := store.Exchanges().GetExchangeBatch(batchID)
+	if err != nil {
+		t.Fatalf("failed to fetch batch: %v", err)
+	} else if Status(batch.Status) != BatchCancelled {
+		t.Errorf("batch status mismatch: have %d, want %d", batch.Status, BatchCancelled)
+	} else if batch.CompletedAt == nil {
+		t.Errorf("batch completed at not set")
+	}
+
+	balanceInputs, err := store.Balances().GetPendingBalanceInputsWithoutBatch()
+	if err != nil {
+		t.Fatalf("failed to fetch balance inputs: %v", err)
+	} else if len(balanceInputs) != 1 {
+		t.Errorf("released balance input length mismatch: have %d, want 1", len(balanceInputs))
+	}
+
+	exchangeInputs, err := store.Exchanges().GetExchangeInputs(batchID)
+	if err != nil {
+		t.Fatalf("failed to fetch exchange inputs: %v", err)
+	} else if len(exchangeInputs) != 0 {
+		t.Errorf("exchange input length mismatch: have %d, want 0", len(exchangeInputs))
+	}
+
+	// a cancelled batch is no longer inactive
+	if err := client.CancelBatch(batchID); err == nil {
+		t.Errorf("expected error cancelling a cancelled batch")
+	}
+}
+
+func TestCancelInitiatedBatch(t *testing.T) {
+	store := pooldb.NewMemoryStore()
+	client := &Client{store: store}
+
+	batchID, err := store.Exchanges().InsertExchangeBatch(&pooldb.ExchangeBatch{
+		ExchangeID: int(types.KucoinID),
+		Status:     int(DepositsActive),
+	})
+	if err != nil {
+		t.Fatalf("failed to insert batch: %v", err)
+	}
+
+	err = store.Exchanges().InsertExchangeInputs(&pooldb.ExchangeInput{
+		BatchID:    batchID,
+		InChainID:  "ETC",
+		OutChainID: "BTC",
+		Value:      dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(100)},
+	})
+	if err != nil {
+		t.Fatalf("failed to insert exchange input: %v", err)
+	}
+
+	if err := client.CancelBatch(batchID); err == nil {
+		t.Errorf("expected error cancelling an initiated batch")
+	}
+
+	batch, err := store.Exchanges().GetExchangeBatch(batchID)
+	if err != nil {
+		t.Fatalf("failed to fetch batch: %v", err)
+	} else if Status(batch.Status) != DepositsActive {
+		t.Errorf("batch status mismatch: have %d, want %d", batch.Status, DepositsActive)
+	}
+
+	exchangeInputs, err := store.Exchanges().GetExchangeInputs(batchID)
+	if err != nil {
+		t.Fatalf("failed to fetch exchange inputs: %v", err)
+	} else if len(exchangeInputs) != 1 {
+		t.Errorf("exchange input length mismatch: have %d, want 1", len(exchangeInputs))
+	}
+}
@@ -32,6 +32,7 @@ func (c *Client) executeAndMaybeSplitDeposit(
 		return err
 	}
 	defer dbTx.SafeRollback()
+	store := pooldb.NewTxStore(dbTx)
 
 	var outputs []*types.TxOutput
 	if split > 1 {
@@ -88,7 +89,7 @@ func (c *Client) executeAndMaybeSplitDeposit(
 			Value: dbcl.NullBigInt{Valid: true, BigInt: splitValue},
 		}
 
-		depositID, err := pooldb.InsertExchangeDeposit(c.pooldb.Writer(), deposit)
+		depositID, err := store.Exchanges().InsertExchangeDeposit(deposit)
 		if err != nil {
 			return err
 		}
@@ -101,7 +102,7 @@ func (c *Client) executeAndMaybeSplitDeposit(
 }
 
 func (c *Client) InitiateDeposits(batchID uint64, exchange types.Exchange) error {
-	exchangeInputs, err := pooldb.GetExchangeInputs(c.pooldb.Reader(), batchID)
+	exchangeInputs, err := c.store.Exchanges().GetExchangeInputs(batchID)
 	if err != nil {
 		return err
 	}
@@ -149,7 +150,7 @@ func (c *Client) InitiateDeposits(batchID uint64, exchange types.Exchange) error
 		}
 	}
 
-	deposits, err := pooldb.GetExchangeDeposits(c.pooldb.Reader(), batchID)
+	deposits, err := c.store.Exchanges().GetExchangeDeposits(batchID)
 	if err != nil {
 		return err
 	}
@@ -162,7 +163,7 @@ func (c *Client) InitiateDeposits(batchID uint64, exchange types.Exchange) error
 			depositValueIdx[deposit.ChainID] = new(big.Int)
 		}
 
-		tx, err := pooldb.GetTransactionByTxID(c.pooldb.Reader(), deposit.DepositTxID)
+		tx, err := c.store.Payouts().GetTransactionByTxID(deposit.DepositTxID)
 		if err != nil {
 			return err
 		} else if !tx.Fee.Valid {
@@ -190,14 +191,14 @@ func (c *Client) InitiateDeposits(batchID uint64, exchange types.Exchange) error
 	}
 
 	if initiatedAll {
-		return c.updateBatchStatus(c.pooldb.Writer(), batchID, DepositsActive)
+		return c.updateBatchStatus(c.store, batchID, DepositsActive)
 	}
 
 	return nil
 }
 
 func (c *Client) RegisterDeposits(batchID uint64, exchange types.Exchange) error {
-	deposits, err := pooldb.GetExchangeDeposits(c.pooldb.Reader(), batchID)
+	deposits, err := c.store.Exchanges().GetExchangeDeposits(batchID)
 	if err != nil {
 		return err
 	}
@@ -222,21 +223,21 @@ func (c *Client) RegisterDeposits(batchID uint64, exchange types.Exchange) error
 		deposit.ExchangeDepositID = types.StringPtr(parsedDeposit.ID)
 
 		cols := []string{"exchange_txid", "exchange_deposit_id", "registered"}
-		err = pooldb.UpdateExchangeDeposit(c.pooldb.Writer(), deposit, cols)
+		err = c.store.Exchanges().UpdateExchangeDeposit(deposit, cols)
 		if err != nil {
 			return err
 		}
 	}
 
 	if registeredAll {
-		return c.updateBatchStatus(c.pooldb.Writer(), batchID, DepositsRegistered)
+		return c.updateBatchStatus(c.store, batchID, DepositsRegistered)
 	}
 
 	return nil
 }
 
 func (c *Client) ConfirmDeposits(batchID uint64, exchange types.Exchange) error {
-	deposits, err := pooldb.GetExchangeDeposits(c.pooldb.Reader(), batchID)
+	deposits, err := c.store.Exchanges().GetExchangeDeposits(batchID)
 	if err != nil {
 		return err
 	}
@@ -286,7 +287,7 @@ func (c *Client) ConfirmDeposits(batchID uint64, exchange types.Exchange) error
 		deposit.Fees = dbcl.NullBigInt{Valid: true, BigInt: feesBig}
 
 		cols := []string{"value", "fees", "confirmed"}
-		err = pooldb.UpdateExchangeDeposit(c.pooldb.Writer(), deposit, cols)
+		err = c.store.Exchanges().UpdateExchangeDeposit(deposit, cols)
 		if err != nil {
 			return err
 		}
@@ -295,7 +296,7 @@ func (c *Client) ConfirmDeposits(batchID uint64, exchange types.Exchange) error
 	}
 
 	if confirmedAll {
-		return c.updateBatchStatus(c.pooldb.Writer(), batchID, DepositsComplete)
+		return c.updateBatchStatus(c.store, batchID, DepositsComplete)
 	}
 
 	return nil
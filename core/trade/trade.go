@@ -14,7 +14,7 @@ import (
 )
 
 func (c *Client) InitiateTrades(batchID uint64, exchange types.Exchange) error {
-	deposits, err := pooldb.GetExchangeDeposits(c.pooldb.Reader(), batchID)
+	deposits, err := c.store.Exchanges().GetExchangeDeposits(batchID)
 	if err != nil {
 		return err
 	}
@@ -37,7 +37,7 @@ func (c *Client) InitiateTrades(batchID uint64, exchange types.Exchange) error {
 	}
 
 	// fetch the balance inputs and convert them into the output paths
-	balanceInputs, err := pooldb.GetExchangeInputs(c.pooldb.Reader(), batchID)
+	balanceInputs, err := c.store.Exchanges().GetExchangeInputs(batchID)
 	if err != nil {
 		return err
 	}
@@ -105,12 +105,12 @@ func (c *Client) InitiateTrades(batchID uint64, exchange types.Exchange) error {
 		}
 	}
 
-	err = pooldb.InsertExchangeTrades(c.pooldb.Writer(), trades...)
+	err = c.store.Exchanges().InsertExchangeTrades(trades...)
 	if err != nil {
 		return err
 	}
 
-	return c.updateBatchStatus(c.pooldb.Writer(), batchID, TradesInactive)
+	return c.updateBatchStatus(c.store, batchID, TradesInactive)
 }
 
 func (c *Client) InitiateTradeStage(batchID uint64, exchange types.Exchange, stage int) error {
@@ -126,7 +126,7 @@ func (c *Client) InitiateTradeStage(batchID uint64, exchange types.Exchange, sta
 		return fmt.Errorf("unsupported trade stage %d", stage)
 	}
 
-	trades, err := pooldb.GetExchangeTradesByStage(c.pooldb.Writer(), batchID, stage)
+	trades, err := c.store.Exchanges().GetExchangeTradesByStage(batchID, stage)
 	if err != nil {
 		return err
 	}
@@ -163,7 +163,7 @@ func (c *Client) InitiateTradeStage(batchID uint64, exchange types.Exchange, sta
 		trade.Confirmed = false
 
 		cols := []string{"exchange_trade_id", "order_price", "initiated", "confirmed"}
-		err = pooldb.UpdateExchangeTrade(c.pooldb.Writer(), trade, cols)
+		err = c.store.Exchanges().UpdateExchangeTrade(trade, cols)
 		if err != nil {
 			return err
 		}
@@ -171,7 +171,7 @@ func (c *Client) InitiateTradeStage(batchID uint64, exchange types.Exchange, sta
 		c.telegram.NotifyInitiateTrade(trade.ID, trade.PathID, trade.StageID, trade.Market, direction.String(), floatValue)
 	}
 
-	return c.updateBatchStatus(c.pooldb.Writer(), batchID, tradeStageStatus)
+	return c.updateBatchStatus(c.store, batchID, tradeStageStatus)
 }
 
 func (c *Client) confirmTrade(batchID uint64, exchange types.Exchange, stage int, trade *pooldb.ExchangeTrade) (bool, error) {
@@ -209,11 +209,12 @@ func (c *Client) confirmTrade(batchID uint64, exchange types.Exchange, stage int
 		return completedTrade, err
 	}
 
-	tx, err := c.pooldb.Begin()
+	dbTx, err := c.pooldb.Begin()
 	if err != nil {
 		return false, err
 	}
-	defer tx.SafeRollback()
+	defer dbTx.SafeRollback()
+	tx := pooldb.NewTxStore(dbTx)
 
 	// process the trade value as a float and fetch the trade from the exchange
 	tradeID := types.StringValue(trade.ExchangeTradeID)
@@ -281,7 +282,7 @@ func (c *Client) confirmTrade(batchID uint64, exchange types.Exchange, stage int
 			CumulativeDepositFees: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
 		}
 
-		err = pooldb.InsertExchangeTrades(tx, nextTrade)
+		err = tx.Exchanges().InsertExchangeTrades(nextTrade)
 		if err != nil {
 			return completedTrade, err
 		}
@@ -312,7 +313,7 @@ func (c *Client) confirmTrade(batchID uint64, exchange types.Exchange, stage int
 	// check for the previous trade to collect cumulative deposit and trade fees. if no
 	// previous trade exists, collect the initial deposit fees from the current trade
 	var cumulativeFillPrice, cumulativeDepositFees, cumulativeTradeFees float64
-	prevTrade, err := pooldb.GetExchangeTradeByPathAndStage(tx, batchID, trade.PathID, trade.StageID-1)
+	prevTrade, err := tx.Exchanges().GetExchangeTradeByPathAndStage(batchID, trade.PathID, trade.StageID-1)
 	if err != nil {
 		return completedTrade, err
 	} else if prevTrade != nil && completedTrade {
@@ -382,7 +383,7 @@ func (c *Client) confirmTrade(batchID uint64, exchange types.Exchange, stage int
 	// the balance from the trade account to the main account (kucoin only, empty method otherwise).
 	// if there are multiple trade steps, this will just partially update the next trade's value
 	// sequentially.
-	nextTrade, err := pooldb.GetExchangeTradeByPathAndStage(tx, batchID, trade.PathID, trade.StageID+1)
+	nextTrade, err := tx.Exchanges().GetExchangeTradeByPathAndStage(batchID, trade.PathID, trade.StageID+1)
 	if err != nil {
 		return completedTrade, err
 	} else if nextTrade == nil {
@@ -399,7 +400,7 @@ func (c *Client) confirmTrade(batchID uint64, exchange types.Exchange, stage int
 		}
 
 		cols := []string{"value"}
-		err = pooldb.UpdateExchangeTrade(tx, nextTrade, cols)
+		err = tx.Exchanges().UpdateExchangeTrade(nextTrade, cols)
 		if err != nil {
 			return completedTrade, err
 		}
@@ -429,13 +430,13 @@ func (c *Client) confirmTrade(batchID uint64, exchange types.Exchange, stage int
 	cols := []string{"value", "proceeds", "trade_fees", "cumulative_deposit_fees",
 		"cumulative_trade_fees", "fill_price", "cumulative_fill_price",
 		"slippage", "confirmed"}
-	err = pooldb.UpdateExchangeTrade(tx, trade, cols)
+	err = tx.Exchanges().UpdateExchangeTrade(trade, cols)
 	if err != nil {
 		return completedTrade, err
 	}
 
 	c.telegram.NotifyFinalizeTrade(trade.ID)
-	err = tx.SafeCommit()
+	err = dbTx.SafeCommit()
 
 	return completedTrade, err
 }
@@ -453,7 +454,7 @@ func (c *Client) ConfirmTradeStage(batchID uint64, exchange types.Exchange, stag
 		return fmt.Errorf("unsupported trade stage %d", stage)
 	}
 
-	trades, err := pooldb.GetExchangeTradesByStage(c.pooldb.Reader(), batchID, stage)
+	trades, err := c.store.Exchanges().GetExchangeTradesByStage(batchID, stage)
 	if err != nil {
 		return err
 	}
@@ -469,7 +470,7 @@ func (c *Client) ConfirmTradeStage(batchID uint64, exchange types.Exchange, stag
 	}
 
 	if completedAll {
-		return c.updateBatchStatus(c.pooldb.Writer(), batchID, tradeStageStatus)
+		return c.updateBatchStatus(c.store, batchID, tradeStageStatus)
 	}
 
 	return nil
@@ -12,7 +12,7 @@ import (
 )
 
 func (c *Client) InitiateWithdrawals(batchID uint64, exchange types.Exchange) error {
-	allTrades, err := pooldb.GetExchangeTrades(c.pooldb.Reader(), batchID)
+	allTrades, err := c.store.Exchanges().GetExchangeTrades(batchID)
 	if err != nil {
 		return err
 	}
@@ -27,7 +27,7 @@ func (c *Client) InitiateWithdrawals(batchID uint64, exchange types.Exchange) er
 		}
 	}
 
-	trades, err := pooldb.GetFinalExchangeTrades(c.pooldb.Reader(), batchID)
+	trades, err := c.store.Exchanges().GetFinalExchangeTrades(batchID)
 	if err != nil {
 		return err
 	} else if len(trades) == 0 {
@@ -80,7 +80,7 @@ func (c *Client) InitiateWithdrawals(batchID uint64, exchange types.Exchange) er
 	}
 
 	// make sure any withdrawals don't end up going through twice
-	withdrawals, err := pooldb.GetExchangeWithdrawals(c.pooldb.Reader(), batchID)
+	withdrawals, err := c.store.Exchanges().GetExchangeWithdrawals(batchID)
 	if err != nil {
 		return err
 	}
@@ -112,7 +112,7 @@ func (c *Client) InitiateWithdrawals(batchID uint64, exchange types.Exchange) er
 			Spent:       false,
 		}
 
-		withdrawalID, err := pooldb.InsertExchangeWithdrawal(c.pooldb.Writer(), withdrawal)
+		withdrawalID, err := c.store.Exchanges().InsertExchangeWithdrawal(withdrawal)
 		if err != nil {
 			return err
 		}
@@ -120,11 +120,11 @@ func (c *Client) InitiateWithdrawals(batchID uint64, exchange types.Exchange) er
 		c.telegram.NotifyInitiateWithdrawal(withdrawalID, chain, floatValue)
 	}
 
-	return c.updateBatchStatus(c.pooldb.Writer(), batchID, WithdrawalsActive)
+	return c.updateBatchStatus(c.store, batchID, WithdrawalsActive)
 }
 
 func (c *Client) ConfirmWithdrawals(batchID uint64, exchange types.Exchange) error {
-	withdrawals, err := pooldb.GetExchangeWithdrawals(c.pooldb.Reader(), batchID)
+	withdrawals, err := c.store.Exchanges().GetExchangeWithdrawals(batchID)
 	if err != nil {
 		return err
 	} else if len(withdrawals) == 0 {
@@ -194,7 +194,7 @@ func (c *Client) ConfirmWithdrawals(batchID uint64, exchange types.Exchange) err
 		}
 
 		cols := []string{"exchange_txid", "value", "withdrawal_fees", "cumulative_fees", "confirmed"}
-		err = pooldb.UpdateExchangeWithdrawal(c.pooldb.Writer(), withdrawal, cols)
+		err = c.store.Exchanges().UpdateExchangeWithdrawal(withdrawal, cols)
 		if err != nil {
 			return err
 		}
@@ -203,7 +203,7 @@ func (c *Client) ConfirmWithdrawals(batchID uint64, exchange types.Exchange) err
 	}
 
 	if completedAll {
-		return c.updateBatchStatus(c.pooldb.Writer(), batchID, WithdrawalsComplete)
+		return c.updateBatchStatus(c.store, batchID, WithdrawalsComplete)
 	}
 
 	return nil
@@ -211,7 +211,7 @@ func (c *Client) ConfirmWithdrawals(batchID uint64, exchange types.Exchange) err
 
 func (c *Client) CreditWithdrawals(batchID uint64) error {
 	// fetch all miner balance inputs for the batch
-	balanceInputs, err := pooldb.GetBalanceInputsByBatch(c.pooldb.Reader(), batchID)
+	balanceInputs, err := c.store.Balances().GetBalanceInputsByBatch(batchID)
 	if err != nil {
 		return err
 	}
@@ -224,7 +224,7 @@ func (c *Client) CreditWithdrawals(batchID uint64) error {
 	}
 
 	// fetch the final exchange trades across every path the batch
-	finalTrades, err := pooldb.GetFinalExchangeTrades(c.pooldb.Reader(), batchID)
+	finalTrades, err := c.store.Exchanges().GetFinalExchangeTrades(batchID)
 	if err != nil {
 		return err
 	}
@@ -243,7 +243,7 @@ func (c *Client) CreditWithdrawals(batchID uint64) error {
 	}
 
 	// fetch all withdrawals for the batch
-	withdrawals, err := pooldb.GetExchangeWithdrawals(c.pooldb.Reader(), batchID)
+	withdrawals, err := c.store.Exchanges().GetExchangeWithdrawals(batchID)
 	if err != nil {
 		return err
 	}
@@ -382,101 +382,92 @@ func (c *Client) CreditWithdrawals(batchID uint64) error {
 	// inserted, all of the balance inputs are marked as not pending
 	// with the corresponding balance output, and all of the withdrawals
 	// are marked as spent
-	tx, err := c.pooldb.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.SafeRollback()
+	err = c.store.Transact(func(tx pooldb.Store) error {
+		// insert all of the newly created balance outputs
+		err := tx.Balances().InsertBalanceOutputs(balanceOutputs...)
+		if err != nil {
+			return err
+		}
 
-	// insert all of the newly created balance outputs
-	err = pooldb.InsertBalanceOutputs(tx, balanceOutputs...)
-	if err != nil {
-		return err
-	}
+		// re-fetch balance outputs with their new ids
+		balanceOutputs, err = tx.Balances().GetBalanceOutputsByBatch(batchID)
+		if err != nil {
+			return err
+		}
 
-	// re-fetch balance outputs with their new ids
-	balanceOutputs, err = pooldb.GetBalanceOutputsByBatch(tx, batchID)
-	if err != nil {
-		return err
-	}
+		// create an index for all balance output ids by miner and chain
+		balanceOutputIdx := make(map[uint64]map[string]uint64)
+		balanceSumsToAdd := make([]*pooldb.BalanceSum, len(balanceOutputs))
+		for i, balanceOutput := range balanceOutputs {
+			minerID := balanceOutput.MinerID
+			chainID := balanceOutput.ChainID
 
-	// create an index for all balance output ids by miner and chain
-	balanceOutputIdx := make(map[uint64]map[string]uint64)
-	balanceSumsToAdd := make([]*pooldb.BalanceSum, len(balanceOutputs))
-	for i, balanceOutput := range balanceOutputs {
-		minerID := balanceOutput.MinerID
-		chainID := balanceOutput.ChainID
+			if _, ok := balanceOutputIdx[minerID]; !ok {
+				balanceOutputIdx[minerID] = make(map[string]uint64)
+			}
+
+			balanceOutputIdx[minerID][chainID] = balanceOutput.ID
 
-		if _, ok := balanceOutputIdx[minerID]; !ok {
-			balanceOutputIdx[minerID] = make(map[string]uint64)
+			// create a balance sum to add the new balance outputs
+			balanceSumsToAdd[i] = &pooldb.BalanceSum{
+				MinerID:     balanceOutput.MinerID,
+				ChainID:     balanceOutput.ChainID,
+				MatureValue: balanceOutput.Value,
+			}
 		}
 
-		balanceOutputIdx[minerID][chainID] = balanceOutput.ID
+		// update every balance input to be marked as not pending and set
+		// with the corresponding balance output id
+		balanceSumsToSubtract := make([]*pooldb.BalanceSum, len(balanceInputs))
+		for i, balanceInput := range balanceInputs {
+			minerID := balanceInput.MinerID
+			chainID := balanceInput.OutChainID
+			if _, ok := balanceOutputIdx[minerID]; !ok {
+				return fmt.Errorf("no balance output found for miner %d", minerID)
+			} else if _, ok := balanceOutputIdx[minerID][chainID]; !ok {
+				return fmt.Errorf("no balance output found for miner %d and chain %s", minerID, chainID)
+			}
 
-		// create a balance sum to add the new balance outputs
-		balanceSumsToAdd[i] = &pooldb.BalanceSum{
-			MinerID:     balanceOutput.MinerID,
-			ChainID:     balanceOutput.ChainID,
-			MatureValue: balanceOutput.Value,
-		}
-	}
+			balanceInput.BalanceOutputID = types.Uint64Ptr(balanceOutputIdx[minerID][chainID])
+			balanceInput.Pending = false
 
-	// update every balance input to be marked as not pending and set
-	// with the corresponding balance output id
-	balanceSumsToSubtract := make([]*pooldb.BalanceSum, len(balanceInputs))
-	for i, balanceInput := range balanceInputs {
-		minerID := balanceInput.MinerID
-		chainID := balanceInput.OutChainID
-		if _, ok := balanceOutputIdx[minerID]; !ok {
-			return fmt.Errorf("no balance output found for miner %d", minerID)
-		} else if _, ok := balanceOutputIdx[minerID][chainID]; !ok {
-			return fmt.Errorf("no balance output found for miner %d and chain %s", minerID, chainID)
-		}
+			cols := []string{"balance_output_id", "pending"}
+			err = tx.Balances().UpdateBalanceInput(balanceInput, cols)
+			if err != nil {
+				return err
+			}
 
-		balanceInput.BalanceOutputID = types.Uint64Ptr(balanceOutputIdx[minerID][chainID])
-		balanceInput.Pending = false
+			// create a balance sum to add the used balance inputs
+			balanceSumsToSubtract[i] = &pooldb.BalanceSum{
+				MinerID:     minerID,
+				ChainID:     balanceInput.ChainID,
+				MatureValue: balanceInput.Value,
+			}
+		}
 
-		cols := []string{"balance_output_id", "pending"}
-		err = pooldb.UpdateBalanceInput(tx, balanceInput, cols)
+		// add the new balance sums
+		err = tx.Balances().InsertAddBalanceSums(balanceSumsToAdd...)
 		if err != nil {
 			return err
 		}
 
-		// create a balance sum to add the used balance inputs
-		balanceSumsToSubtract[i] = &pooldb.BalanceSum{
-			MinerID:     minerID,
-			ChainID:     balanceInput.ChainID,
-			MatureValue: balanceInput.Value,
-		}
-	}
-
-	// add the new balance sums
-	err = pooldb.InsertAddBalanceSums(tx, balanceSumsToAdd...)
-	if err != nil {
-		return err
-	}
-
-	// subtract the new balance sums
-	err = pooldb.InsertSubtractBalanceSums(tx, balanceSumsToSubtract...)
-	if err != nil {
-		return err
-	}
-
-	// mark all of the withdrawals as spent
-	for _, withdrawal := range withdrawals {
-		withdrawal.Spent = true
-		err = pooldb.UpdateExchangeWithdrawal(tx, withdrawal, []string{"spent"})
+		// subtract the new balance sums
+		err = tx.Balances().InsertSubtractBalanceSums(balanceSumsToSubtract...)
 		if err != nil {
 			return err
 		}
-	}
 
-	err = c.updateBatchStatus(tx, batchID, BatchComplete)
-	if err != nil {
-		return err
-	}
+		// mark all of the withdrawals as spent
+		for _, withdrawal := range withdrawals {
+			withdrawal.Spent = true
+			err = tx.Exchanges().UpdateExchangeWithdrawal(withdrawal, []string{"spent"})
+			if err != nil {
+				return err
+			}
+		}
 
-	err = tx.SafeCommit()
+		return c.updateBatchStatus(tx, batchID, BatchComplete)
+	})
 	if err != nil {
 		return err
 	}
@@ -41,11 +41,23 @@ func splitValue(
 	return values, remainder, nil
 }
 
+// the pool fee taken from every round, in basis points (0.01%)
+const DefaultPoolFeeBasisPoints = 1
+
 // credits a round based off of the share index and the fee recipient distributions. the output is a merged
 // map of miners and recipients since we can safely assume that miner ids and recipient ids are globally unique.
 func CreditRound(
 	roundValue *big.Int,
 	minerIdx, recipientIdx map[uint64]uint64,
+) (map[uint64]*big.Int, map[uint64]*big.Int, error) {
+	return CreditRoundWithFee(roundValue, DefaultPoolFeeBasisPoints, minerIdx, recipientIdx)
+}
+
+// credits a round the same way as CreditRound, but with a custom pool fee (in basis points)
+func CreditRoundWithFee(
+	roundValue *big.Int,
+	feeBasisPoints uint64,
+	minerIdx, recipientIdx map[uint64]uint64,
 ) (map[uint64]*big.Int, map[uint64]*big.Int, error) {
 	if roundValue == nil {
 		return nil, nil, fmt.Errorf("empty round value")
@@ -58,8 +70,8 @@ func CreditRound(
 	// copy value to avoid overwriting it elsewhere
 	roundValue = new(big.Int).Set(roundValue)
 
-	// takes a 0.01% fee from the value as the pool fees
-	feeValue := common.SplitBigPercentage(roundValue, 1, 10000)
+	// takes the fee from the value as the pool fees
+	feeValue := common.SplitBigPercentage(roundValue, feeBasisPoints, 10000)
 	adjustedRoundValue := new(big.Int).Sub(roundValue, feeValue)
 
 	// calculate the miner distributions and remainder
@@ -81,10 +93,41 @@ func CreditRound(
 		minerFees[minerID] = new(big.Int).Sub(initialValue, minerValues[minerID])
 	}
 
+	// calculate the fee recipients distributions
+	recipientValues, err := SplitFee(feeValue, recipientIdx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	compoundValues := minerValues
+	for recipientID, value := range recipientValues {
+		if _, ok := compoundValues[recipientID]; ok {
+			compoundValues[recipientID].Add(compoundValues[recipientID], value)
+		} else {
+			compoundValues[recipientID] = value
+		}
+	}
+
+	return compoundValues, minerFees, nil
+}
+
+// splits a fee value between the fee recipients based off of the fee recipient distributions
+func SplitFee(
+	feeValue *big.Int,
+	recipientIdx map[uint64]uint64,
+) (map[uint64]*big.Int, error) {
+	if feeValue == nil {
+		return nil, fmt.Errorf("empty fee value")
+	} else if len(recipientIdx) == 0 {
+		return nil, fmt.Errorf("empty recipient index")
+	}
+
 	// calculate the fee recipients distributions and remainder
 	recipientValues, remainder, err := splitValue(feeValue, recipientIdx)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	} else if len(recipientValues) == 0 {
+		return make(map[uint64]*big.Int), nil
 	}
 
 	// add the remainder to the fee recipient recieving the lowest quantity
@@ -111,16 +154,7 @@ func CreditRound(
 	}
 	lowestValue.Add(lowestValue, remainder)
 
-	compoundValues := minerValues
-	for recipientID, value := range recipientValues {
-		if _, ok := compoundValues[recipientID]; ok {
-			compoundValues[recipientID].Add(compoundValues[recipientID], value)
-		} else {
-			compoundValues[recipientID] = value
-		}
-	}
-
-	return compoundValues, minerFees, nil
+	return recipientValues, nil
 }
 
 // given a miner's round distributions, check to see if any fee balance is needed and, if so,
@@ -320,6 +320,52 @@ func TestCreditRound(t *testing.T) {
 	}
 }
 
+func TestSplitFee(t *testing.T) {
+	tests := []struct {
+		feeValue     *big.Int
+		recipientIdx map[uint64]uint64
+		outputValues map[uint64]*big.Int
+	}{
+		{
+			feeValue:     new(big.Int).SetUint64(0),
+			recipientIdx: map[uint64]uint64{1: 50, 2: 50},
+			outputValues: map[uint64]*big.Int{},
+		},
+		{
+			feeValue:     new(big.Int).SetUint64(1000),
+			recipientIdx: map[uint64]uint64{1: 100},
+			outputValues: map[uint64]*big.Int{1: new(big.Int).SetUint64(1000)},
+		},
+		{
+			feeValue:     new(big.Int).SetUint64(1001),
+			recipientIdx: map[uint64]uint64{1: 50, 2: 50},
+			outputValues: map[uint64]*big.Int{
+				1: new(big.Int).SetUint64(501),
+				2: new(big.Int).SetUint64(500),
+			},
+		},
+		{
+			feeValue:     new(big.Int).SetUint64(1000),
+			recipientIdx: map[uint64]uint64{1: 1, 2: 1, 3: 1},
+			outputValues: map[uint64]*big.Int{
+				1: new(big.Int).SetUint64(334),
+				2: new(big.Int).SetUint64(333),
+				3: new(big.Int).SetUint64(333),
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		outputValues, err := SplitFee(tt.feeValue, tt.recipientIdx)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if !reflect.DeepEqual(outputValues, tt.outputValues) {
+			t.Errorf("failed on %d: output values mismatch: have %v, want %v",
+				i, outputValues, tt.outputValues)
+		}
+	}
+}
+
 func TestProcessFeeBalance(t *testing.T) {
 	tests := []struct {
 		roundChain  string
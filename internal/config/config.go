@@ -0,0 +1,281 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed default.yaml
+var defaultConfig []byte
+
+type Config struct {
+	Fees    FeesConfig              `yaml:"fees"`
+	Alerts  AlertsConfig            `yaml:"alerts"`
+	BanList []string                `yaml:"ban_list"`
+	Pool    PoolConfig              `yaml:"pool"`
+	Chains  map[string]*ChainConfig `yaml:"chains"`
+	Worker  WorkerConfig            `yaml:"worker"`
+	API     APIConfig               `yaml:"api"`
+	TSDB    TSDBConfig              `yaml:"tsdb"`
+}
+
+type FeesConfig struct {
+	PoolFeeBasisPoints uint64 `yaml:"pool_fee_basis_points"`
+}
+
+type AlertsConfig struct {
+	ReorgDepth map[string]uint64 `yaml:"reorg_depth"`
+}
+
+type PortConfig struct {
+	Port       int `yaml:"port"`
+	Difficulty int `yaml:"difficulty"`
+}
+
+type PoolConfig struct {
+	MetricsPort int           `yaml:"metrics_port"`
+	Ports       []PortConfig  `yaml:"ports"`
+	Journal     JournalConfig `yaml:"journal"`
+}
+
+// JournalConfig specifies the local share journal, disabled if Dir is empty. every pool
+// instance writes to Dir/Instance (the hostname if empty), and Dir has to be shared by the
+// instances of a chain to rebuild the redis window from all of them and to prune the
+// journals of instances that have stopped.
+type JournalConfig struct {
+	Dir         string        `yaml:"dir"`
+	Instance    string        `yaml:"instance"`
+	SegmentSize int64         `yaml:"segment_size"`
+	MinAge      time.Duration `yaml:"min_age"`
+}
+
+// VarDiffConfig specifies the vardiff settings for a chain, every zero
+// value falls back to the default (see stratum.DefaultVarDiffOptions).
+type VarDiffConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	TargetTime    time.Duration `yaml:"target_time"`
+	RetargetDelay time.Duration `yaml:"retarget_delay"`
+	Variance      float64       `yaml:"variance"`
+	MinDiff       int           `yaml:"min_diff"`
+	MaxDiff       int           `yaml:"max_diff"`
+	BoundFactor   int           `yaml:"bound_factor"`
+}
+
+type ChainConfig struct {
+	WindowSize           int           `yaml:"window_size"`
+	ExtraNonceSize       int           `yaml:"extranonce_size"`
+	VersionMask          uint32        `yaml:"version_mask"`
+	JobListSize          int           `yaml:"job_list_size"`
+	JobListAgeLimit      int           `yaml:"job_list_age_limit"`
+	Solo                 bool          `yaml:"solo"`
+	TrueSolo             bool          `yaml:"true_solo"`
+	Stream               bool          `yaml:"stream"`
+	ForceErrorOnResponse bool          `yaml:"force_error_on_response"`
+	PollingPeriod        time.Duration `yaml:"polling_period"`
+	PingingPeriod        time.Duration `yaml:"pinging_period"`
+	VarDiff              VarDiffConfig `yaml:"vardiff"`
+	Ports                []PortConfig  `yaml:"ports"`
+	Nodes                []string      `yaml:"nodes"`
+}
+
+type ExchangeConfig struct {
+	ID            string `yaml:"id"`
+	APIKey        string `yaml:"api_key"`
+	APISecret     string `yaml:"api_secret"`
+	APIPassphrase string `yaml:"api_passphrase"`
+}
+
+type ShareAuditConfig struct {
+	AutoExclude bool `yaml:"auto_exclude"`
+}
+
+type WorkerConfig struct {
+	MetricsPort       int               `yaml:"metrics_port"`
+	AdminPort         int               `yaml:"admin_port"`
+	MiningChains      []string          `yaml:"mining_chains"`
+	PayoutChains      []string          `yaml:"payout_chains"`
+	DisperseAddresses map[string]string `yaml:"disperse_addresses"`
+	Exchanges         []ExchangeConfig  `yaml:"exchanges"`
+	ShareAudit        ShareAuditConfig  `yaml:"share_audit"`
+	Schedules         map[string]string `yaml:"schedules"`
+}
+
+type APIConfig struct {
+	Port         int      `yaml:"port"`
+	Chains       []string `yaml:"chains"`
+	CacheEnabled bool     `yaml:"cache_enabled"`
+}
+
+// TSDBConfig selects the backend of the share rollups (tsdb.ShareStore). the columnar
+// backend keeps them in ShareDir, which the api has to share with the worker.
+type TSDBConfig struct {
+	ShareBackend string `yaml:"share_backend"`
+	ShareDir     string `yaml:"share_dir"`
+}
+
+// Load reads, interpolates and validates the config file at path. secrets (from svc.ParseSecrets)
+// take precedence over environment variables during interpolation. If path is empty, the
+// embedded default config is used.
+func Load(path string, secrets map[string]string) (*Config, error) {
+	if len(path) == 0 {
+		return Parse(defaultConfig, secrets)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := Parse(data, secrets)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Parse interpolates, decodes and validates a config. Unknown fields are rejected.
+func Parse(data []byte, secrets map[string]string) (*Config, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	} else if root.Kind == 0 {
+		return nil, fmt.Errorf("empty config")
+	} else if err := interpolateNode(&root, secrets); err != nil {
+		return nil, err
+	}
+
+	cfg := new(Config)
+	if err := checkKnownFields(&root, reflect.TypeOf(cfg)); err != nil {
+		return nil, err
+	} else if err := root.Decode(cfg); err != nil {
+		return nil, err
+	} else if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// checkKnownFields walks the tree alongside the type it is decoded into, rejecting any
+// unknown field (node decoding, unlike the decoder, has no option to reject them).
+func checkKnownFields(node *yaml.Node, t reflect.Type) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if err := checkKnownFields(child, t); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		if t.Kind() == reflect.Slice {
+			for _, child := range node.Content {
+				if err := checkKnownFields(child, t.Elem()); err != nil {
+					return err
+				}
+			}
+		}
+	case yaml.MappingNode:
+		switch t.Kind() {
+		case reflect.Map:
+			for i := 1; i < len(node.Content); i += 2 {
+				if err := checkKnownFields(node.Content[i], t.Elem()); err != nil {
+					return err
+				}
+			}
+		case reflect.Struct:
+			fields := make(map[string]reflect.Type, t.NumField())
+			for i := 0; i < t.NumField(); i++ {
+				field := t.Field(i)
+				name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+				fields[name] = field.Type
+			}
+
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i]
+				fieldType, ok := fields[key.Value]
+				if !ok {
+					return fmt.Errorf("line %d: field %s not found", key.Line, key.Value)
+				} else if err := checkKnownFields(node.Content[i+1], fieldType); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetChain returns the config for the chain.
+func (c *Config) GetChain(chain string) (*ChainConfig, error) {
+	chainCfg, ok := c.Chains[chain]
+	if !ok || chainCfg == nil {
+		return nil, fmt.Errorf("no config for chain %s", chain)
+	}
+
+	return chainCfg, nil
+}
+
+// GetPorts returns the stratum ports for the chain, keyed by port with the difficulty factor as the value.
+func (c *Config) GetPorts(chain string) map[int]int {
+	ports := c.Pool.Ports
+	if chainCfg, ok := c.Chains[chain]; ok && chainCfg != nil && len(chainCfg.Ports) > 0 {
+		ports = chainCfg.Ports
+	}
+
+	portDiffIdx := make(map[int]int, len(ports))
+	for _, port := range ports {
+		portDiffIdx[port.Port] = port.Difficulty
+	}
+
+	return portDiffIdx
+}
+
+// GetNodes returns the node hosts for the chain (if any).
+func (c *Config) GetNodes(chain string) []string {
+	chainCfg, ok := c.Chains[chain]
+	if !ok || chainCfg == nil {
+		return nil
+	}
+
+	return chainCfg.Nodes
+}
+
+// String returns the config as YAML, with the exchange credentials redacted.
+func (c *Config) String() string {
+	redacted := *c
+	redacted.Worker.Exchanges = make([]ExchangeConfig, len(c.Worker.Exchanges))
+	for i, exchange := range c.Worker.Exchanges {
+		redacted.Worker.Exchanges[i] = ExchangeConfig{
+			ID:            exchange.ID,
+			APIKey:        redact(exchange.APIKey),
+			APISecret:     redact(exchange.APISecret),
+			APIPassphrase: redact(exchange.APIPassphrase),
+		}
+	}
+
+	data, err := yaml.Marshal(&redacted)
+	if err != nil {
+		return err.Error()
+	}
+
+	return string(data)
+}
+
+func redact(value string) string {
+	if len(value) == 0 {
+		return ""
+	}
+
+	return "********"
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	cfg, err := Load("", map[string]string{"KUCOIN_API_KEY": "key"})
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+
+	chain, err := cfg.GetChain("ETC")
+	if err != nil {
+		t.Fatalf("failed to get chain: %v", err)
+	} else if chain.WindowSize != 100000 || chain.JobListAgeLimit != 7 {
+		t.Errorf("chain mismatch: have %+v", chain)
+	} else if chain.PollingPeriod != time.Millisecond*100 {
+		t.Errorf("polling period mismatch: have %s, want %s", chain.PollingPeriod, time.Millisecond*100)
+	}
+
+	if mask := cfg.Chains["BTC"].VersionMask; mask != 0x1fffe000 {
+		t.Errorf("version mask mismatch: have %x, want %x", mask, 0x1fffe000)
+	}
+
+	miningChains := []string{"BCH", "BTC", "ERG", "ETC", "FIRO", "FLUX", "KAS", "KLS", "NEXA", "RVN", "ZEN"}
+	if !reflect.DeepEqual(cfg.Worker.MiningChains, miningChains) {
+		t.Errorf("mining chains mismatch: have %v, want %v", cfg.Worker.MiningChains, miningChains)
+	}
+
+	ports := cfg.GetPorts("ETC")
+	if !reflect.DeepEqual(ports, map[int]int{3333: 1}) {
+		t.Errorf("ports mismatch: have %v", ports)
+	}
+
+	if key := cfg.Worker.Exchanges[0].APIKey; key != "key" {
+		t.Errorf("api key mismatch: have %s, want %s", key, "key")
+	} else if strings.Contains(cfg.String(), "key: key") {
+		t.Errorf("api key not redacted")
+	}
+}
+
+func TestInterpolation(t *testing.T) {
+	secrets := map[string]string{
+		"PORT":     "4444",
+		"PASSWORD": "p#ss: word",
+	}
+
+	tests := []struct {
+		value string
+		valid bool
+		want  string
+	}{
+		{value: "${PORT}", valid: true, want: "4444"},
+		{value: "host:${PORT}", valid: true, want: "host:4444"},
+		{value: "${MISSING:-fallback}", valid: true, want: "fallback"},
+		{value: "${MISSING:-}", valid: true, want: ""},
+		{value: "${PASSWORD}", valid: true, want: "p#ss: word"},
+		{value: "${MISSING}", valid: false},
+		{value: "${PORT} ${MISSING}", valid: false},
+	}
+
+	for i, tt := range tests {
+		value, err := interpolate(tt.value, secrets)
+		if valid := err == nil; valid != tt.valid {
+			t.Errorf("failed on %d: valid mismatch: have %t, want %t: %v", i, valid, tt.valid, err)
+		} else if valid && value != tt.want {
+			t.Errorf("failed on %d: value mismatch: have %s, want %s", i, value, tt.want)
+		}
+	}
+
+	data := strings.Replace(string(defaultConfig), "- port: 3333", "- port: ${PORT}", 1)
+	data = strings.Replace(data, "api_key: ${KUCOIN_API_KEY:-}", "api_key: ${PASSWORD}", 1)
+	cfg, err := Parse([]byte(data), secrets)
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	} else if port := cfg.Pool.Ports[0].Port; port != 4444 {
+		t.Errorf("port mismatch: have %d, want %d", port, 4444)
+	} else if key := cfg.Worker.Exchanges[0].APIKey; key != secrets["PASSWORD"] {
+		t.Errorf("api key mismatch: have %s, want %s", key, secrets["PASSWORD"])
+	}
+}
+
+func TestValidation(t *testing.T) {
+	tests := []struct {
+		old    string
+		new    string
+		errors []string
+	}{
+		{
+			old:    "window_size: 2000000",
+			new:    "window_size: 0",
+			errors: []string{"chains.ETHW.window_size"},
+		},
+		{
+			old:    "window_size: 2000000",
+			new:    "window_sise: 2000000",
+			errors: []string{"field window_sise not found"},
+		},
+		{
+			old:    "ban_list: []",
+			new:    "ban_list: [10.0.0.1, 10.0.0.0/8, 10.0.0]",
+			errors: []string{"ban_list[2]"},
+		},
+		{
+			old:    "pool_fee_basis_points: 1",
+			new:    "pool_fee_basis_points: 0",
+			errors: []string{"fees.pool_fee_basis_points"},
+		},
+		{
+			old:    `chart: "* * * * *"`,
+			new:    `chrat: "* * * *"`,
+			errors: []string{"worker.schedules.chrat: unknown job", "worker.schedules.chrat: invalid cron spec"},
+		},
+		{
+			old:    "- id: mexc",
+			new:    "- id: kucoin",
+			errors: []string{"worker.exchanges[1].id: duplicate exchange"},
+		},
+		{
+			old:    "admin_port: 6061",
+			new:    "admin_port: 6060",
+			errors: []string{"worker.admin_port: must differ from the metrics port"},
+		},
+		{
+			old:    "polling_period: 1s",
+			new:    "polling_period: 1",
+			errors: []string{"line 41: cannot unmarshal"},
+		},
+		{
+			old:    "share_backend: ${TSDB_SHARE_BACKEND:-mysql}",
+			new:    "share_backend: columnar",
+			errors: []string{"tsdb.share_dir: required by the columnar backend"},
+		},
+		{
+			old:    "mining_chains: [BCH,",
+			new:    "mining_chains: [BCH, BCH,",
+			errors: []string{"worker.mining_chains[1]: duplicate chain BCH"},
+		},
+		{
+			old:    `ETC: "0xD152f549545093347A162Dce210e7293f1452150"`,
+			new:    `ETC: "0xD152f549"`,
+			errors: []string{`worker.disperse_addresses.ETC: invalid contract address "0xD152f549"`},
+		},
+		{
+			old:    "instance: ${POOL_JOURNAL_INSTANCE:-}",
+			new:    "instance: ../pool",
+			errors: []string{`pool.journal.instance: must not contain a path separator, have "../pool"`},
+		},
+	}
+
+	for i, tt := range tests {
+		data := strings.Replace(string(defaultConfig), tt.old, tt.new, 1)
+		_, err := Parse([]byte(data), nil)
+		if err == nil {
+			t.Errorf("failed on %d: expected error", i)
+			continue
+		}
+
+		for _, want := range tt.errors {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("failed on %d: error mismatch: have %v, want %s", i, err, want)
+			}
+		}
+	}
+}
+
+func TestMergeReloadable(t *testing.T) {
+	current, err := Parse(defaultConfig, nil)
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	data := strings.Replace(string(defaultConfig), "ban_list: []", "ban_list: [10.0.0.1]", 1)
+	data = strings.Replace(data, "reorg_depth: {}", "reorg_depth: {ETC: 5}", 1)
+	data = strings.Replace(data, "window_size: 2000000", "window_size: 1000000", 1)
+	data = strings.Replace(data, `    pinging_period: 10s
+    vardiff:
+      enabled: true
+  NEXA:`, `    pinging_period: 10s
+    vardiff:
+      enabled: true
+      target_time: 5s
+  NEXA:`, 1)
+	next, err := Parse([]byte(data), nil)
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+
+	merged, restartFields := mergeReloadable(current, next)
+	if !reflect.DeepEqual(restartFields, []string{"chains.ETHW"}) {
+		t.Errorf("restart fields mismatch: have %v, want %v", restartFields, []string{"chains.ETHW"})
+	} else if !reflect.DeepEqual(merged.BanList, []string{"10.0.0.1"}) {
+		t.Errorf("ban list mismatch: have %v", merged.BanList)
+	} else if merged.Alerts.ReorgDepth["ETC"] != 5 {
+		t.Errorf("reorg depth mismatch: have %d, want %d", merged.Alerts.ReorgDepth["ETC"], 5)
+	} else if merged.Chains["KLS"].VarDiff.TargetTime != time.Second*5 {
+		t.Errorf("target time mismatch: have %s, want %s", merged.Chains["KLS"].VarDiff.TargetTime, time.Second*5)
+	} else if merged.Chains["ETHW"].WindowSize != 2000000 {
+		t.Errorf("window size mismatch: have %d, want %d", merged.Chains["ETHW"].WindowSize, 2000000)
+	} else if current.Chains["KLS"].VarDiff.TargetTime != 0 {
+		t.Errorf("current config modified")
+	}
+}
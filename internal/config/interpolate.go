@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// matches ${NAME} and ${NAME:-default}
+var interpolationRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// interpolate replaces every ${NAME} reference with the secret (or environment
+// variable) of the same name. References without a default have to be set.
+func interpolate(value string, secrets map[string]string) (string, error) {
+	var missing []string
+	value = interpolationRegex.ReplaceAllStringFunc(value, func(match string) string {
+		parts := interpolationRegex.FindStringSubmatch(match)
+		name, hasDefault, defaultValue := parts[1], len(parts[2]) > 0, parts[3]
+		if secret, ok := secrets[name]; ok {
+			return secret
+		} else if env, ok := os.LookupEnv(name); ok {
+			return env
+		} else if hasDefault {
+			return defaultValue
+		}
+
+		missing = append(missing, name)
+		return match
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variable %s", strings.Join(missing, ", "))
+	}
+
+	return value, nil
+}
+
+// interpolateNode interpolates every scalar in the tree. since the resolved tag of an interpolated
+// plain scalar is always a string, it is cleared to let the value resolve to its actual type.
+func interpolateNode(node *yaml.Node, secrets map[string]string) error {
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "${") {
+		value, err := interpolate(node.Value, secrets)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", node.Line, err)
+		}
+
+		node.Value = value
+		if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+	}
+
+	for _, child := range node.Content {
+		if err := interpolateNode(child, secrets); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/magicpool-co/pool/types"
+)
+
+var (
+	chainRegex = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+	contractRegex = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+	// the same parser options as the worker's cron
+	cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+	exchangeIDs = map[string]types.ExchangeID{
+		"binance": types.BinanceID,
+		"kucoin":  types.KucoinID,
+		"bittrex": types.BittrexID,
+		"mexc":    types.MEXCGlobalID,
+	}
+)
+
+// GetExchangeID returns the exchange ID for the exchange name.
+func GetExchangeID(name string) (types.ExchangeID, error) {
+	id, ok := exchangeIDs[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown exchange %s", name)
+	}
+
+	return id, nil
+}
+
+// knownJobs returns the name of every worker job, which
+// is every job with a schedule in the default config.
+func knownJobs() map[string]bool {
+	var cfg struct {
+		Worker struct {
+			Schedules map[string]string `yaml:"schedules"`
+		} `yaml:"worker"`
+	}
+
+	jobs := make(map[string]bool)
+	if err := yaml.Unmarshal(defaultConfig, &cfg); err == nil {
+		for name := range cfg.Worker.Schedules {
+			jobs[name] = true
+		}
+	}
+
+	return jobs
+}
+
+// ValidationError holds every invalid field in the config.
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  " + strings.Join(e.Errors, "\n  ")
+}
+
+type validator struct {
+	errors []string
+}
+
+func (v *validator) check(valid bool, path, format string, args ...interface{}) {
+	if !valid {
+		v.errors = append(v.errors, path+": "+fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) checkPort(port int, path string) {
+	v.check(port > 0 && port < 65536, path, "invalid port %d", port)
+}
+
+func (v *validator) checkChains(chains []string, path string) {
+	seen := make(map[string]bool)
+	for i, chain := range chains {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		v.check(chainRegex.MatchString(chain), itemPath, "invalid chain %q (chains are uppercase)", chain)
+		v.check(!seen[chain], itemPath, "duplicate chain %s", chain)
+		seen[chain] = true
+	}
+}
+
+func (v *validator) checkStratumPorts(ports []PortConfig, path string) {
+	seen := make(map[int]bool)
+	for i, port := range ports {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		// stratum ports are kept under 10000 to stay clear of the ephemeral range
+		v.check(port.Port > 0 && port.Port < 10000, itemPath+".port", "port must be between 1 and 9999, have %d", port.Port)
+		v.check(port.Difficulty > 0, itemPath+".difficulty", "difficulty must be positive, have %d", port.Difficulty)
+		v.check(!seen[port.Port], itemPath+".port", "duplicate port %d", port.Port)
+		seen[port.Port] = true
+	}
+}
+
+func (v *validator) checkBanList(entries []string, path string) {
+	for i, entry := range entries {
+		var valid bool
+		if strings.Contains(entry, "/") {
+			_, _, err := net.ParseCIDR(entry)
+			valid = err == nil
+		} else {
+			valid = net.ParseIP(entry) != nil
+		}
+		v.check(valid, fmt.Sprintf("%s[%d]", path, i), "invalid IP or CIDR range %q", entry)
+	}
+}
+
+func (v *validator) checkVarDiff(cfg VarDiffConfig, path string) {
+	v.check(cfg.TargetTime >= 0, path+".target_time", "must not be negative")
+	v.check(cfg.RetargetDelay >= 0, path+".retarget_delay", "must not be negative")
+	if cfg.TargetTime > 0 && cfg.RetargetDelay > 0 {
+		v.check(cfg.RetargetDelay >= cfg.TargetTime, path+".retarget_delay",
+			"must be at least the target time (%s)", cfg.TargetTime)
+	}
+	v.check(cfg.Variance >= 0 && cfg.Variance < 1, path+".variance", "must be between 0 and 1, have %g", cfg.Variance)
+	v.check(cfg.MinDiff >= 0, path+".min_diff", "must not be negative")
+	v.check(cfg.MaxDiff >= 0, path+".max_diff", "must not be negative")
+	if cfg.MinDiff > 0 && cfg.MaxDiff > 0 {
+		v.check(cfg.MaxDiff >= cfg.MinDiff, path+".max_diff", "must be at least min_diff (%d)", cfg.MinDiff)
+	}
+	v.check(cfg.BoundFactor >= 0, path+".bound_factor", "must not be negative")
+}
+
+func (v *validator) checkChain(chain string, cfg *ChainConfig) {
+	path := "chains." + chain
+	v.check(chainRegex.MatchString(chain), path, "invalid chain %q (chains are uppercase)", chain)
+	if cfg == nil {
+		v.check(false, path, "empty chain config")
+		return
+	}
+
+	v.check(cfg.WindowSize > 0, path+".window_size", "must be positive, have %d", cfg.WindowSize)
+	v.check(cfg.ExtraNonceSize >= 0 && cfg.ExtraNonceSize <= 8, path+".extranonce_size",
+		"must be between 0 and 8, have %d", cfg.ExtraNonceSize)
+	v.check(cfg.JobListSize > 0, path+".job_list_size", "must be positive, have %d", cfg.JobListSize)
+	v.check(cfg.JobListAgeLimit >= -1, path+".job_list_age_limit", "must be at least -1, have %d", cfg.JobListAgeLimit)
+	v.check(!cfg.TrueSolo || cfg.Solo, path+".true_solo", "requires solo")
+	v.check(cfg.PollingPeriod > 0, path+".polling_period", "must be positive")
+	v.check(cfg.PingingPeriod >= 0, path+".pinging_period", "must not be negative")
+	v.checkVarDiff(cfg.VarDiff, path+".vardiff")
+	v.checkStratumPorts(cfg.Ports, path+".ports")
+
+	for i, node := range cfg.Nodes {
+		_, _, err := net.SplitHostPort(node)
+		v.check(err == nil && !strings.Contains(node, "://"), fmt.Sprintf("%s.nodes[%d]", path, i),
+			"invalid node host %q (expected host:port)", node)
+	}
+}
+
+func (v *validator) checkWorker(cfg WorkerConfig) {
+	v.checkPort(cfg.MetricsPort, "worker.metrics_port")
+	v.checkPort(cfg.AdminPort, "worker.admin_port")
+	v.check(cfg.AdminPort != cfg.MetricsPort, "worker.admin_port", "must differ from the metrics port")
+	v.checkChains(cfg.MiningChains, "worker.mining_chains")
+	v.checkChains(cfg.PayoutChains, "worker.payout_chains")
+	for chain, address := range cfg.DisperseAddresses {
+		path := "worker.disperse_addresses." + chain
+		v.check(chainRegex.MatchString(chain), path, "invalid chain %q (chains are uppercase)", chain)
+		v.check(contractRegex.MatchString(address), path, "invalid contract address %q", address)
+	}
+
+	seen := make(map[string]bool)
+	for i, exchange := range cfg.Exchanges {
+		path := fmt.Sprintf("worker.exchanges[%d].id", i)
+		_, err := GetExchangeID(exchange.ID)
+		v.check(err == nil, path, "unknown exchange %q", exchange.ID)
+		v.check(!seen[strings.ToLower(exchange.ID)], path, "duplicate exchange %s", exchange.ID)
+		seen[strings.ToLower(exchange.ID)] = true
+	}
+
+	jobs := knownJobs()
+	names := make([]string, 0, len(cfg.Schedules))
+	for name := range cfg.Schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := "worker.schedules." + name
+		_, err := cronParser.Parse(cfg.Schedules[name])
+		v.check(jobs[name], path, "unknown job")
+		v.check(err == nil, path, "invalid cron spec %q: %v", cfg.Schedules[name], err)
+	}
+}
+
+// Validate checks every field of the config, returning a ValidationError with every invalid field.
+func (c *Config) Validate() error {
+	v := new(validator)
+
+	v.check(c.Fees.PoolFeeBasisPoints > 0 && c.Fees.PoolFeeBasisPoints <= 10000, "fees.pool_fee_basis_points",
+		"must be between 1 and 10000, have %d", c.Fees.PoolFeeBasisPoints)
+	for chain := range c.Alerts.ReorgDepth {
+		v.check(chainRegex.MatchString(chain), "alerts.reorg_depth."+chain, "invalid chain %q (chains are uppercase)", chain)
+	}
+	v.checkBanList(c.BanList, "ban_list")
+
+	v.checkPort(c.Pool.MetricsPort, "pool.metrics_port")
+	v.check(len(c.Pool.Ports) > 0, "pool.ports", "at least one port is required")
+	v.checkStratumPorts(c.Pool.Ports, "pool.ports")
+	v.check(!strings.ContainsAny(c.Pool.Journal.Instance, `/\`), "pool.journal.instance",
+		"must not contain a path separator, have %q", c.Pool.Journal.Instance)
+	v.check(c.Pool.Journal.SegmentSize >= 0, "pool.journal.segment_size", "must not be negative")
+	v.check(c.Pool.Journal.MinAge >= 0, "pool.journal.min_age", "must not be negative")
+
+	chains := make([]string, 0, len(c.Chains))
+	for chain := range c.Chains {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+
+	for _, chain := range chains {
+		v.checkChain(chain, c.Chains[chain])
+	}
+
+	v.checkWorker(c.Worker)
+
+	v.checkPort(c.API.Port, "api.port")
+	v.checkChains(c.API.Chains, "api.chains")
+
+	switch c.TSDB.ShareBackend {
+	case "mysql":
+	case "columnar":
+		v.check(len(c.TSDB.ShareDir) > 0, "tsdb.share_dir", "required by the columnar backend")
+	default:
+		v.check(false, "tsdb.share_backend", "unknown backend %q (mysql or columnar)", c.TSDB.ShareBackend)
+	}
+
+	if len(v.errors) > 0 {
+		return &ValidationError{Errors: v.errors}
+	}
+
+	return nil
+}
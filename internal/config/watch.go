@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/log"
+)
+
+// ReloadHandler is called with the updated config whenever the reloadable subset changes.
+type ReloadHandler func(*Config)
+
+// mergeReloadable returns a copy of current with the reloadable subset (the ban list, the
+// alert thresholds and the vardiff settings of existing chains) replaced by the values in next,
+// along with every changed field that can't be reloaded and requires a restart.
+func mergeReloadable(current, next *Config) (*Config, []string) {
+	merged := *current
+	merged.BanList = next.BanList
+	merged.Alerts = next.Alerts
+	merged.Chains = make(map[string]*ChainConfig, len(current.Chains))
+
+	restartFields := make([]string, 0)
+	if !reflect.DeepEqual(current.Fees, next.Fees) {
+		restartFields = append(restartFields, "fees")
+	}
+	if !reflect.DeepEqual(current.Pool, next.Pool) {
+		restartFields = append(restartFields, "pool")
+	}
+	if !reflect.DeepEqual(current.Worker, next.Worker) {
+		restartFields = append(restartFields, "worker")
+	}
+	if !reflect.DeepEqual(current.API, next.API) {
+		restartFields = append(restartFields, "api")
+	}
+	if !reflect.DeepEqual(current.TSDB, next.TSDB) {
+		restartFields = append(restartFields, "tsdb")
+	}
+
+	chains := make([]string, 0)
+	for chain := range current.Chains {
+		chains = append(chains, chain)
+	}
+	for chain := range next.Chains {
+		if _, ok := current.Chains[chain]; !ok {
+			chains = append(chains, chain)
+		}
+	}
+	sort.Strings(chains)
+
+	for _, chain := range chains {
+		currentChain, nextChain := current.Chains[chain], next.Chains[chain]
+		if currentChain == nil || nextChain == nil {
+			if currentChain != nil {
+				merged.Chains[chain] = currentChain
+			}
+			restartFields = append(restartFields, "chains."+chain)
+			continue
+		}
+
+		mergedChain := *currentChain
+		mergedChain.VarDiff = nextChain.VarDiff
+		merged.Chains[chain] = &mergedChain
+
+		// toggling vardiff changes how new conns are created, so only the settings are reloadable
+		if currentChain.VarDiff.Enabled != nextChain.VarDiff.Enabled {
+			mergedChain.VarDiff.Enabled = currentChain.VarDiff.Enabled
+			restartFields = append(restartFields, "chains."+chain+".vardiff.enabled")
+		}
+
+		comparableChain := *nextChain
+		comparableChain.VarDiff = currentChain.VarDiff
+		if !reflect.DeepEqual(*currentChain, comparableChain) {
+			restartFields = append(restartFields, "chains."+chain)
+		}
+	}
+
+	return &merged, restartFields
+}
+
+// Watcher polls the config file for changes. Only the reloadable subset (see mergeReloadable)
+// is applied while running, any other change is logged and only applied after a restart.
+// An invalid config is logged and ignored, the previous config stays in place.
+type Watcher struct {
+	path     string
+	secrets  map[string]string
+	interval time.Duration
+	logger   *log.Logger
+	handler  ReloadHandler
+
+	mu      sync.Mutex
+	current *Config
+	modTime time.Time
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+func NewWatcher(
+	path string,
+	secrets map[string]string,
+	current *Config,
+	interval time.Duration,
+	logger *log.Logger,
+	handler ReloadHandler,
+) (*Watcher, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("no config path")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher := &Watcher{
+		path:     path,
+		secrets:  secrets,
+		interval: interval,
+		logger:   logger,
+		handler:  handler,
+		current:  current,
+		modTime:  info.ModTime(),
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	return watcher, nil
+}
+
+// Current returns the config currently in effect.
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+// Reload reads the config file and applies the reloadable subset, returning every
+// changed field that requires a restart.
+func (w *Watcher) Reload() ([]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return nil, err
+	}
+	w.modTime = info.ModTime()
+
+	next, err := Load(w.path, w.secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, restartFields := mergeReloadable(w.current, next)
+	w.current = merged
+	if w.handler != nil {
+		w.handler(merged)
+	}
+
+	return restartFields, nil
+}
+
+func (w *Watcher) changed() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		w.logger.Error(fmt.Errorf("config: %v", err))
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return !info.ModTime().Equal(w.modTime)
+}
+
+func (w *Watcher) Start() {
+	go func() {
+		defer w.logger.RecoverPanic()
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.quit:
+				return
+			case <-ticker.C:
+				if !w.changed() {
+					continue
+				}
+
+				restartFields, err := w.Reload()
+				if err != nil {
+					w.logger.Error(fmt.Errorf("config: reload: %v", err))
+				} else if len(restartFields) > 0 {
+					w.logger.Info("config: reloaded, restart required to apply changes to " +
+						strings.Join(restartFields, ", "))
+				} else {
+					w.logger.Info("config: reloaded")
+				}
+			}
+		}
+	}()
+}
+
+func (w *Watcher) Stop() {
+	close(w.quit)
+	<-w.done
+}
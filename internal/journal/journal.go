@@ -0,0 +1,552 @@
+package journal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* segments */
+
+const (
+	segmentMagic = "SJL1"
+	segmentExt   = ".wal"
+
+	defaultSegmentSize = 64 << 20
+	defaultMinAge      = time.Hour * 24
+
+	// heartbeatPeriod is how often Sync touches the open segment of an idle writer.
+	heartbeatPeriod = time.Hour
+)
+
+// segment is a closed or open journal file. units is the number of window
+// shares (non-solo share records, weighted by count) per chain, used for retention.
+type segment struct {
+	seq      uint64
+	path     string
+	lastTime time.Time
+	units    map[string]uint64
+}
+
+func segmentPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%016d%s", seq, segmentExt))
+}
+
+// listSegments returns the segment files of dir, oldest first.
+func listSegments(dir string) ([]*segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]*segment, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		segments = append(segments, &segment{
+			seq:   seq,
+			path:  filepath.Join(dir, name),
+			units: make(map[string]uint64),
+		})
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].seq < segments[j].seq
+	})
+
+	return segments, nil
+}
+
+// readSegment calls fn for every record of the segment at path. a torn or corrupt
+// record ends the segment, since a crash can only leave a partial write at the tail.
+func readSegment(path string, fn func(*Record) error) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		// removed by the retention of its writer
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r := bufio.NewReaderSize(file, 1<<16)
+	magic := make([]byte, len(segmentMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil
+	} else if string(magic) != segmentMagic {
+		return fmt.Errorf("%s: invalid segment header", path)
+	}
+
+	for {
+		record, err := decodeRecord(r)
+		if err == io.EOF || err == errCorruptRecord {
+			return nil
+		} else if err != nil {
+			return err
+		} else if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *segment) add(record *Record) {
+	if record.Time.After(s.lastTime) {
+		s.lastTime = record.Time
+	}
+	if record.Type == ShareRecord && record.SoloMinerID == 0 {
+		s.units[record.Chain] += record.Count
+	}
+}
+
+/* writer */
+
+type Options struct {
+	// SegmentSize is the size a segment is rotated at (64MB by default).
+	SegmentSize int64
+	// MinAge is the minimum age of the last record of a removed segment (24h by default).
+	MinAge time.Duration
+	// Windows is the PPLNS window size per chain. a segment is only removed once
+	// the newer segments hold a full window of every chain.
+	Windows map[string]int64
+}
+
+// Writer appends records to the journal of a single pool instance. records are
+// buffered until Sync, so a crash loses at most the records since the last Sync.
+type Writer struct {
+	mu       sync.Mutex
+	dir      string
+	opts     Options
+	segments []*segment
+	file     *os.File
+	buf      *bufio.Writer
+	size     int64
+	scratch  []byte
+	touched  time.Time
+}
+
+// Open opens the journal in dir, creating the directory if needed. existing segments
+// are kept (and scanned for retention) and writes always start a new segment.
+func Open(dir string, opts Options) (*Writer, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = defaultSegmentSize
+	}
+	if opts.MinAge <= 0 {
+		opts.MinAge = defaultMinAge
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, seg := range segments {
+		err := readSegment(seg.path, func(record *Record) error {
+			seg.add(record)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if seg.lastTime.IsZero() {
+			info, err := os.Stat(seg.path)
+			if err != nil {
+				return nil, err
+			}
+			seg.lastTime = info.ModTime()
+		}
+	}
+
+	w := &Writer{
+		dir:      dir,
+		opts:     opts,
+		segments: segments,
+	}
+
+	var seq uint64 = 1
+	if len(segments) > 0 {
+		seq = segments[len(segments)-1].seq + 1
+	}
+
+	if err := w.openSegment(seq); err != nil {
+		return nil, err
+	} else if err := w.prune(time.Now()); err != nil {
+		w.file.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Writer) openSegment(seq uint64) error {
+	path := segmentPath(w.dir, seq)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	w.file = file
+	w.buf = bufio.NewWriterSize(file, 1<<16)
+	w.size = int64(len(segmentMagic))
+	w.touched = time.Now()
+	w.segments = append(w.segments, &segment{
+		seq:      seq,
+		path:     path,
+		lastTime: time.Now(),
+		units:    make(map[string]uint64),
+	})
+
+	_, err = w.buf.WriteString(segmentMagic)
+
+	return err
+}
+
+func (w *Writer) sync() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+
+	return w.file.Sync()
+}
+
+func (w *Writer) rotate() error {
+	if err := w.sync(); err != nil {
+		return err
+	} else if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	seq := w.segments[len(w.segments)-1].seq + 1
+	if err := w.openSegment(seq); err != nil {
+		return err
+	}
+
+	return w.prune(time.Now())
+}
+
+// covers returns true if units holds a full window of every chain.
+func (w *Writer) covers(units map[string]uint64) bool {
+	for chain, window := range w.opts.Windows {
+		if units[chain] < uint64(window) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// prune removes the closed segments that are older than MinAge and only hold
+// shares that have left the window of every chain.
+func (w *Writer) prune(now time.Time) error {
+	units := make(map[string]uint64)
+	cutoff := -1
+	for i := len(w.segments) - 1; i >= 0; i-- {
+		seg := w.segments[i]
+		if i < len(w.segments)-1 && w.covers(units) && now.Sub(seg.lastTime) >= w.opts.MinAge {
+			cutoff = i
+			break
+		}
+
+		for chain, count := range seg.units {
+			units[chain] += count
+		}
+	}
+
+	for i := 0; i <= cutoff; i++ {
+		if err := os.Remove(w.segments[i].path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	w.segments = w.segments[cutoff+1:]
+
+	return nil
+}
+
+func (w *Writer) append(record *Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("journal is closed")
+	}
+
+	w.scratch = encodeRecord(w.scratch[:0], record)
+	if _, err := w.buf.Write(w.scratch); err != nil {
+		return err
+	}
+	w.size += int64(len(w.scratch))
+	w.segments[len(w.segments)-1].add(record)
+
+	if w.size >= w.opts.SegmentSize {
+		return w.rotate()
+	}
+
+	return nil
+}
+
+func (w *Writer) AppendShare(chain, interval, compoundID string, soloMinerID uint64, count int) error {
+	if count <= 0 {
+		return nil
+	}
+
+	record := &Record{
+		Type:        ShareRecord,
+		Time:        time.Now(),
+		Chain:       chain,
+		Interval:    interval,
+		CompoundID:  compoundID,
+		SoloMinerID: soloMinerID,
+		Count:       uint64(count),
+	}
+
+	return w.append(record)
+}
+
+func (w *Writer) AppendRoundReset(chain string, soloMinerID uint64) error {
+	record := &Record{
+		Type:        RoundResetRecord,
+		Time:        time.Now(),
+		Chain:       chain,
+		SoloMinerID: soloMinerID,
+	}
+
+	return w.append(record)
+}
+
+// Sync flushes the buffered records and syncs the open segment to disk. the open segment
+// of an idle writer is touched every hour, so PruneInstanceDirs never takes it for dead.
+func (w *Writer) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	} else if err := w.sync(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if now.Sub(w.touched) < heartbeatPeriod {
+		return nil
+	} else if err := os.Chtimes(w.segments[len(w.segments)-1].path, now, now); err != nil {
+		return err
+	}
+	w.touched = now
+
+	return nil
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.sync()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.file = nil
+
+	return err
+}
+
+/* reader */
+
+// InstanceDirs returns the journal directories of every pool instance under root.
+func InstanceDirs(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(root, entry.Name()))
+		}
+	}
+
+	return dirs, nil
+}
+
+// ReadRecords reads the records of chain after since from the journals in dirs
+// (one per pool instance) and merges them in time order.
+func ReadRecords(dirs []string, chain string, since time.Time) ([]*Record, error) {
+	records := make([]*Record, 0)
+	for _, dir := range dirs {
+		segments, err := listSegments(dir)
+		if os.IsNotExist(err) {
+			// removed by PruneInstanceDirs
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, seg := range segments {
+			err := readSegment(seg.path, func(record *Record) error {
+				if record.Chain == chain && record.Time.After(since) {
+					records = append(records, record)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+
+	return records, nil
+}
+
+/* pruning */
+
+// lastWrite returns the last time dir or any of its segments was written to.
+func lastWrite(dir string) (time.Time, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	last := info.ModTime()
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, seg := range segments {
+		info, err := os.Stat(seg.path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return time.Time{}, err
+		} else if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+
+	return last, nil
+}
+
+// coversWindows returns true if the journals in dirs hold a full window
+// of every chain in share records written after since.
+func coversWindows(dirs []string, windows map[string]int64, since time.Time) (bool, error) {
+	units := make(map[string]uint64)
+	for _, dir := range dirs {
+		segments, err := listSegments(dir)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+
+		for _, seg := range segments {
+			info, err := os.Stat(seg.path)
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return false, err
+			} else if !info.ModTime().After(since) {
+				// every record of the segment is older
+				continue
+			}
+
+			err = readSegment(seg.path, func(record *Record) error {
+				if record.Type == ShareRecord && record.SoloMinerID == 0 && record.Time.After(since) {
+					units[record.Chain] += record.Count
+				}
+				return nil
+			})
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+
+	for chain, window := range windows {
+		if units[chain] < uint64(window) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// PruneInstanceDirs removes the journal directories of dead pool instances under root,
+// other than keep (the directory of the calling instance), and returns the removed
+// directories. an instance is dead once its journal has not been written to for minAge
+// (24h by default), and like the retention of a writer, its directory is only removed
+// once the other journals hold a full window of every chain that is newer than it.
+func PruneInstanceDirs(root, keep string, windows map[string]int64, minAge time.Duration, now time.Time) ([]string, error) {
+	if minAge <= 0 {
+		minAge = defaultMinAge
+	}
+
+	dirs, err := InstanceDirs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, 0)
+	removedIdx := make(map[string]bool)
+	for _, dir := range dirs {
+		if filepath.Clean(dir) == filepath.Clean(keep) {
+			continue
+		}
+
+		last, err := lastWrite(dir)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return removed, err
+		} else if now.Sub(last) < minAge {
+			continue
+		}
+
+		others := make([]string, 0, len(dirs))
+		for _, other := range dirs {
+			if other != dir && !removedIdx[other] {
+				others = append(others, other)
+			}
+		}
+
+		covered, err := coversWindows(others, windows, last)
+		if err != nil {
+			return removed, err
+		} else if !covered {
+			continue
+		} else if err := os.RemoveAll(dir); err != nil {
+			return removed, err
+		}
+
+		removed = append(removed, dir)
+		removedIdx[dir] = true
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,371 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/redis"
+)
+
+func TestWriterReadRecords(t *testing.T) {
+	root := t.TempDir()
+	writers := make([]*Writer, 2)
+	for i := range writers {
+		var err error
+		writers[i], err = Open(filepath.Join(root, []string{"a", "b"}[i]), Options{})
+		if err != nil {
+			t.Fatalf("failed to open writer %d: %v", i, err)
+		}
+	}
+
+	appends := []struct {
+		writer     int
+		chain      string
+		compoundID string
+		count      int
+	}{
+		{0, "ETC", "1:1", 1},
+		{1, "ETC", "2:1", 2},
+		{0, "SETC", "3:1", 1},
+		{1, "ETC", "1:1", 0},
+		{0, "ETC", "2:2", 1},
+	}
+
+	for i, tt := range appends {
+		err := writers[tt.writer].AppendShare(tt.chain, "1700000100", tt.compoundID, 0, tt.count)
+		if err != nil {
+			t.Errorf("failed on %d: append: %v", i, err)
+		}
+	}
+
+	for i, w := range writers {
+		if err := w.Close(); err != nil {
+			t.Errorf("failed on %d: close: %v", i, err)
+		}
+	}
+
+	// a torn write at the tail of a segment ends it
+	segments, err := listSegments(filepath.Join(root, "a"))
+	if err != nil || len(segments) != 1 {
+		t.Fatalf("segment mismatch: have %d (%v)", len(segments), err)
+	}
+
+	file, err := os.OpenFile(segments[0].path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open segment: %v", err)
+	}
+	record := encodeRecord(nil, &Record{Type: ShareRecord, Time: time.Now(), Chain: "ETC", Count: 1})
+	file.Write(record[:len(record)-2])
+	file.Close()
+
+	dirs, err := InstanceDirs(root)
+	if err != nil {
+		t.Fatalf("failed to list instances: %v", err)
+	}
+
+	records, err := ReadRecords(dirs, "ETC", time.Time{})
+	if err != nil {
+		t.Fatalf("failed to read records: %v", err)
+	}
+
+	compoundIDs := make([]string, len(records))
+	for i, record := range records {
+		compoundIDs[i] = record.CompoundID
+	}
+	if want := []string{"1:1", "2:1", "2:2"}; !reflect.DeepEqual(compoundIDs, want) {
+		t.Errorf("record mismatch: have %v, want %v", compoundIDs, want)
+	} else if records[1].Count != 2 || records[1].Interval != "1700000100" {
+		t.Errorf("record mismatch: have %+v", records[1])
+	}
+}
+
+func TestWriterRetention(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{
+		SegmentSize: 64,
+		MinAge:      time.Hour,
+		Windows:     map[string]int64{"ETC": 10},
+	}
+
+	w, err := Open(dir, opts)
+	if err != nil {
+		t.Fatalf("failed to open writer: %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 30; i++ {
+		if err := w.AppendShare("ETC", "1700000100", "1:1", 0, 1); err != nil {
+			t.Fatalf("failed to append %d: %v", i, err)
+		}
+	}
+
+	// nothing is removed before min age
+	count := len(w.segments)
+	if count < 4 {
+		t.Fatalf("expected rotation, have %d segments", count)
+	} else if err := w.prune(time.Now()); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	} else if len(w.segments) != count {
+		t.Errorf("segment mismatch before min age: have %d, want %d", len(w.segments), count)
+	}
+
+	// afterwards only a window of shares is kept
+	if err := w.prune(time.Now().Add(time.Hour * 2)); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+
+	var units uint64
+	for _, seg := range w.segments[1:] {
+		units += seg.units["ETC"]
+	}
+	if units >= 10 {
+		t.Errorf("expected oldest kept segment to be needed, newer segments hold %d shares", units)
+	} else if units+w.segments[0].units["ETC"] < 10 {
+		t.Errorf("kept segments do not hold a window: have %d shares", units+w.segments[0].units["ETC"])
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatalf("failed to list segments: %v", err)
+	} else if len(files) != len(w.segments) {
+		t.Errorf("segment file mismatch: have %d, want %d", len(files), len(w.segments))
+	}
+
+	// a reopened writer keeps the retained segments and starts a new one
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close writer: %v", err)
+	}
+
+	reopened, err := Open(dir, opts)
+	if err != nil {
+		t.Fatalf("failed to reopen writer: %v", err)
+	}
+	defer reopened.Close()
+
+	if len(reopened.segments) != len(files)+1 {
+		t.Errorf("reopened segment mismatch: have %d, want %d", len(reopened.segments), len(files)+1)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	newRecord := func(offset int, typ RecordType, interval, compoundID string, soloMinerID, count uint64) *Record {
+		return &Record{
+			Type:        typ,
+			Time:        start.Add(time.Duration(offset) * time.Second),
+			Chain:       "ETC",
+			Interval:    interval,
+			CompoundID:  compoundID,
+			SoloMinerID: soloMinerID,
+			Count:       count,
+		}
+	}
+
+	records := []*Record{
+		newRecord(0, ShareRecord, "1700000100", "1:1", 0, 2),
+		newRecord(1, ShareRecord, "1700000100", "7:1", 7, 3),
+		newRecord(2, RoundResetRecord, "", "", 0, 0),
+		newRecord(3, ShareRecord, "1700001000", "2:1", 0, 1),
+		newRecord(4, ShareRecord, "1700001000", "1:1", 0, 2),
+		{Type: ShareRecord, Time: start.Add(time.Second * 5), Chain: "SETC", CompoundID: "9:1", Count: 1},
+	}
+
+	tests := []struct {
+		window         int64
+		base           []string
+		intervalsSince time.Time
+		state          *State
+	}{
+		{
+			window:         4,
+			intervalsSince: time.Unix(1700000500, 0),
+			state: &State{
+				Window:        []string{"1:1", "1:1", "2:1", "1:1"},
+				RoundAccepted: 3,
+				RoundComplete: true,
+				SoloAccepted:  map[uint64]uint64{7: 3},
+				Intervals: map[string]*IntervalShares{
+					"1700001000": {
+						Accepted:         map[string]uint64{"1:1": 2, "2:1": 1},
+						AcceptedAdjusted: map[string]uint64{"1:1": 1, "2:1": 1},
+					},
+				},
+			},
+		},
+		{
+			window:         8,
+			base:           []string{"3:1", "3:2", "3:3"},
+			intervalsSince: time.Unix(1700002000, 0),
+			state: &State{
+				Window:        []string{"1:1", "1:1", "2:1", "1:1", "1:1", "3:1", "3:2", "3:3"},
+				RoundAccepted: 3,
+				RoundComplete: true,
+				SoloAccepted:  map[uint64]uint64{7: 3},
+				Intervals:     map[string]*IntervalShares{},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		state := Replay(records, "ETC", tt.window, tt.base, tt.intervalsSince)
+		if !reflect.DeepEqual(state, tt.state) {
+			t.Errorf("failed on %d: state mismatch: have %+v, want %+v", i, state, tt.state)
+		}
+	}
+
+	// without a reset marker the round is partial
+	state := Replay(records[3:], "ETC", 4, nil, start)
+	if state.RoundComplete || state.RoundAccepted != 3 {
+		t.Errorf("partial round mismatch: have %d (complete: %t)", state.RoundAccepted, state.RoundComplete)
+	}
+
+	store := redis.NewMemoryShareStore()
+	store.AddAcceptedShare("ETC", "1700001000", "5:1", 0, 5, 4)
+	if err := Restore(store, "ETC", Replay(records, "ETC", 4, nil, time.Unix(1700000500, 0))); err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+
+	window, err := store.GetRoundShareList("ETC")
+	if err != nil {
+		t.Fatalf("failed to get window: %v", err)
+	} else if want := []string{"1:1", "1:1", "2:1", "1:1"}; !reflect.DeepEqual(window, want) {
+		t.Errorf("restored window mismatch: have %v, want %v", window, want)
+	}
+
+	accepted, adjusted, err := store.GetIntervalAcceptedShares("ETC", "1700001000")
+	if err != nil {
+		t.Fatalf("failed to get interval: %v", err)
+	} else if want := map[string]uint64{"1:1": 2, "2:1": 1}; !reflect.DeepEqual(accepted, want) {
+		t.Errorf("restored interval mismatch: have %v, want %v", accepted, want)
+	} else if adjusted["1:1"] != 1 {
+		t.Errorf("restored adjusted interval mismatch: have %v", adjusted)
+	}
+
+	roundAccepted, _, _, err := store.GetRoundShareCounts("ETC", 0)
+	if err != nil {
+		t.Fatalf("failed to get round counts: %v", err)
+	} else if roundAccepted != 3 {
+		t.Errorf("restored round mismatch: have %d, want 3", roundAccepted)
+	}
+}
+
+func TestEncodeWindow(t *testing.T) {
+	tests := [][]string{
+		{},
+		{"1:1"},
+		{"1:1", "1:1", "2:1", "1:1", "3:12", "3:12"},
+	}
+
+	for i, tt := range tests {
+		data, err := EncodeWindow(tt)
+		if err != nil {
+			t.Errorf("failed on %d: encode: %v", i, err)
+			continue
+		}
+
+		window, err := DecodeWindow(data)
+		if err != nil {
+			t.Errorf("failed on %d: decode: %v", i, err)
+		} else if !reflect.DeepEqual(window, tt) {
+			t.Errorf("failed on %d: window mismatch: have %v, want %v", i, window, tt)
+		}
+	}
+}
+
+func TestPruneInstanceDirs(t *testing.T) {
+	root := t.TempDir()
+	opts := Options{Windows: map[string]int64{"ETC": 10}}
+
+	// the dead instance stopped writing two days ago, the others are live
+	shares := map[string]int{"dead": 5, "live": 10, "idle": 0}
+	for instance, count := range shares {
+		w, err := Open(filepath.Join(root, instance), opts)
+		if err != nil {
+			t.Fatalf("%s: failed to open writer: %v", instance, err)
+		}
+
+		for i := 0; i < count; i++ {
+			if err := w.AppendShare("ETC", "1700000100", "1:1", 0, 1); err != nil {
+				t.Fatalf("%s: failed to append %d: %v", instance, i, err)
+			}
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s: failed to close writer: %v", instance, err)
+		}
+	}
+
+	old := time.Now().Add(-time.Hour * 48)
+	deadDir := filepath.Join(root, "dead")
+	files, err := filepath.Glob(filepath.Join(deadDir, "*"+segmentExt))
+	if err != nil {
+		t.Fatalf("failed to list segments: %v", err)
+	}
+
+	for _, path := range append(files, deadDir) {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("failed to age %s: %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		window  int64
+		removed []string
+	}{
+		// the live instances have not journaled a full window since
+		{window: 20, removed: []string{}},
+		{window: 10, removed: []string{deadDir}},
+		{window: 10, removed: []string{}},
+	}
+
+	for i, tt := range tests {
+		windows := map[string]int64{"ETC": tt.window}
+		removed, err := PruneInstanceDirs(root, filepath.Join(root, "live"), windows, time.Hour*24, time.Now())
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if !reflect.DeepEqual(removed, tt.removed) {
+			t.Errorf("failed on %d: removed mismatch: have %v, want %v", i, removed, tt.removed)
+		}
+	}
+
+	dirs, err := InstanceDirs(root)
+	if err != nil {
+		t.Fatalf("failed to list instance dirs: %v", err)
+	} else if len(dirs) != 2 {
+		t.Errorf("instance dir mismatch: have %v, want live and idle", dirs)
+	}
+}
+
+func TestWriterHeartbeat(t *testing.T) {
+	w, err := Open(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("failed to open writer: %v", err)
+	}
+	defer w.Close()
+
+	// an idle writer touches its open segment once the heartbeat period has passed
+	old := time.Now().Add(-time.Hour * 48)
+	path := w.segments[len(w.segments)-1].path
+	if err := w.Sync(); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	} else if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("failed to age segment: %v", err)
+	}
+
+	for i, touched := range []time.Time{time.Now(), old} {
+		w.touched = touched
+		if err := w.Sync(); err != nil {
+			t.Fatalf("failed on %d: sync: %v", i, err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("failed on %d: stat: %v", i, err)
+		} else if aged := info.ModTime().Before(time.Now().Add(-time.Hour)); aged != (i == 0) {
+			t.Errorf("failed on %d: segment age mismatch: have %s", i, info.ModTime())
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+type RecordType byte
+
+const (
+	// ShareRecord is an accepted share, as written to redis by AddAcceptedShare.
+	ShareRecord RecordType = iota + 1
+	// RoundResetRecord marks the reset of the round share counters after a block
+	// was found (GetRoundShareCounts). the soloMinerID is set for solo rounds.
+	RoundResetRecord
+)
+
+// maxRecordSize bounds the payload length read from a segment, so that a corrupt
+// length can not allocate an arbitrary amount of memory.
+const maxRecordSize = 1 << 16
+
+var errCorruptRecord = fmt.Errorf("corrupt journal record")
+
+type Record struct {
+	Type        RecordType
+	Time        time.Time
+	Chain       string
+	Interval    string
+	CompoundID  string
+	SoloMinerID uint64
+	Count       uint64
+}
+
+/* encoding */
+
+func appendString(buf []byte, value string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
+
+func readString(data []byte) (string, []byte, error) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < length {
+		return "", nil, errCorruptRecord
+	}
+	data = data[n:]
+
+	return string(data[:length]), data[length:], nil
+}
+
+func readUvarint(data []byte) (uint64, []byte, error) {
+	value, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, errCorruptRecord
+	}
+
+	return value, data[n:], nil
+}
+
+// encodeRecord appends a record frame to buf: the payload length, the payload
+// and the crc32 of the payload.
+func encodeRecord(buf []byte, record *Record) []byte {
+	payload := []byte{byte(record.Type)}
+	payload = binary.AppendVarint(payload, record.Time.UnixNano())
+	payload = appendString(payload, record.Chain)
+	payload = appendString(payload, record.Interval)
+	payload = appendString(payload, record.CompoundID)
+	payload = binary.AppendUvarint(payload, record.SoloMinerID)
+	payload = binary.AppendUvarint(payload, record.Count)
+
+	buf = binary.AppendUvarint(buf, uint64(len(payload)))
+	buf = append(buf, payload...)
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(payload))
+
+	return buf
+}
+
+func decodePayload(payload []byte) (*Record, error) {
+	if len(payload) < 1 {
+		return nil, errCorruptRecord
+	}
+
+	record := &Record{Type: RecordType(payload[0])}
+	if record.Type != ShareRecord && record.Type != RoundResetRecord {
+		return nil, errCorruptRecord
+	}
+
+	nanos, n := binary.Varint(payload[1:])
+	if n <= 0 {
+		return nil, errCorruptRecord
+	}
+	record.Time = time.Unix(0, nanos).UTC()
+	data := payload[1+n:]
+
+	var err error
+	if record.Chain, data, err = readString(data); err != nil {
+		return nil, err
+	} else if record.Interval, data, err = readString(data); err != nil {
+		return nil, err
+	} else if record.CompoundID, data, err = readString(data); err != nil {
+		return nil, err
+	} else if record.SoloMinerID, data, err = readUvarint(data); err != nil {
+		return nil, err
+	} else if record.Count, data, err = readUvarint(data); err != nil {
+		return nil, err
+	} else if len(data) != 0 {
+		return nil, errCorruptRecord
+	}
+
+	return record, nil
+}
+
+// decodeRecord reads the next record frame from r. it returns io.EOF at the end of
+// r and errCorruptRecord for a torn or corrupt frame.
+func decodeRecord(r *bufio.Reader) (*Record, error) {
+	length, err := binary.ReadUvarint(r)
+	if err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil || length > maxRecordSize {
+		return nil, errCorruptRecord
+	}
+
+	frame := make([]byte, length+4)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, errCorruptRecord
+	}
+
+	payload := frame[:length]
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(frame[length:]) {
+		return nil, errCorruptRecord
+	}
+
+	return decodePayload(payload)
+}
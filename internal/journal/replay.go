@@ -0,0 +1,124 @@
+package journal
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/redis"
+)
+
+type IntervalShares struct {
+	Accepted         map[string]uint64
+	AcceptedAdjusted map[string]uint64
+}
+
+// State is the redis share state of a chain rebuilt from the journal.
+type State struct {
+	// Window is the PPLNS window as compound IDs, newest share first.
+	Window []string
+	// RoundAccepted is the number of accepted shares since the last block. it is only
+	// exact if RoundComplete is set, otherwise the round started before the oldest
+	// retained record and RoundAccepted only counts the journaled shares.
+	RoundAccepted uint64
+	RoundComplete bool
+	SoloAccepted  map[uint64]uint64
+	Intervals     map[string]*IntervalShares
+}
+
+// Replay rebuilds the share state of chain from records, ordered by time. base is an
+// older window (newest share first, e.g. a pooldb snapshot) that the records are
+// applied on top of. only the intervals ending after intervalsSince are rebuilt,
+// since older intervals have usually been processed by the chart job already.
+func Replay(records []*Record, chain string, window int64, base []string, intervalsSince time.Time) *State {
+	state := &State{
+		Window:       make([]string, 0),
+		SoloAccepted: make(map[uint64]uint64),
+		Intervals:    make(map[string]*IntervalShares),
+	}
+
+	for _, record := range records {
+		if record.Chain != chain {
+			continue
+		}
+
+		switch record.Type {
+		case RoundResetRecord:
+			if record.SoloMinerID == 0 {
+				state.RoundAccepted = 0
+				state.RoundComplete = true
+			} else {
+				state.SoloAccepted[record.SoloMinerID] = 0
+			}
+		case ShareRecord:
+			if record.SoloMinerID == 0 {
+				state.RoundAccepted += record.Count
+			} else {
+				state.SoloAccepted[record.SoloMinerID] += record.Count
+			}
+
+			interval, err := strconv.ParseInt(record.Interval, 10, 64)
+			if err != nil || interval <= intervalsSince.Unix() {
+				continue
+			}
+
+			shares, ok := state.Intervals[record.Interval]
+			if !ok {
+				shares = &IntervalShares{
+					Accepted:         make(map[string]uint64),
+					AcceptedAdjusted: make(map[string]uint64),
+				}
+				state.Intervals[record.Interval] = shares
+			}
+			shares.Accepted[record.CompoundID] += record.Count
+			shares.AcceptedAdjusted[record.CompoundID]++
+		}
+	}
+
+	// the window is filled from the newest record backwards, the same way
+	// AddAcceptedShare pushes every share count times and trims the list
+	for i := len(records) - 1; i >= 0 && int64(len(state.Window)) < window; i-- {
+		record := records[i]
+		if record.Chain != chain || record.Type != ShareRecord || record.SoloMinerID != 0 {
+			continue
+		}
+
+		for j := uint64(0); j < record.Count && int64(len(state.Window)) < window; j++ {
+			state.Window = append(state.Window, record.CompoundID)
+		}
+	}
+
+	for _, compoundID := range base {
+		if int64(len(state.Window)) >= window {
+			break
+		}
+		state.Window = append(state.Window, compoundID)
+	}
+
+	return state
+}
+
+// Restore writes state to the share store, replacing the window, the round counters
+// and the counters of the rebuilt intervals of chain.
+func Restore(store redis.ShareStore, chain string, state *State) error {
+	err := store.RestoreRoundShares(chain, state.Window, state.RoundAccepted)
+	if err != nil {
+		return err
+	}
+
+	for minerID, accepted := range state.SoloAccepted {
+		err := store.RestoreRoundSoloShares(chain, minerID, accepted)
+		if err != nil {
+			return err
+		}
+	}
+
+	for interval, shares := range state.Intervals {
+		err := store.RestoreIntervalAcceptedShares(chain, interval,
+			shares.Accepted, shares.AcceptedAdjusted)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
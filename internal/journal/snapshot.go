@@ -0,0 +1,75 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// EncodeWindow encodes a PPLNS window (newest share first) for a pooldb snapshot.
+// consecutive shares of a compound ID are run-length encoded and the result is
+// gzipped, since a window is mostly a few thousand workers repeated.
+func EncodeWindow(window []string) ([]byte, error) {
+	var raw []byte
+	for i := 0; i < len(window); {
+		j := i + 1
+		for j < len(window) && window[j] == window[i] {
+			j++
+		}
+
+		raw = appendString(raw, window[i])
+		raw = binary.AppendUvarint(raw, uint64(j-i))
+		i = j
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(raw); err != nil {
+		return nil, err
+	} else if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func DecodeWindow(data []byte) ([]string, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	br := bufio.NewReader(r)
+	window := make([]string, 0)
+	for {
+		length, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		} else if length > maxRecordSize {
+			return nil, fmt.Errorf("invalid window snapshot")
+		}
+
+		compoundID := make([]byte, length)
+		if _, err := io.ReadFull(br, compoundID); err != nil {
+			return nil, err
+		}
+
+		count, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+
+		id := string(compoundID)
+		for i := uint64(0); i < count; i++ {
+			window = append(window, id)
+		}
+	}
+
+	return window, nil
+}
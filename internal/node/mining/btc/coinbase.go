@@ -0,0 +1,82 @@
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/magicpool-co/pool/pkg/crypto"
+	"github.com/magicpool-co/pool/pkg/crypto/tx/btctx"
+)
+
+const (
+	txVersion = 0x1
+
+	// the coinbase scriptSig has a consensus limit of 100 bytes
+	maxCoinbaseScriptSize = 100
+)
+
+// GenerateCoinbase builds the coinbase with an empty extranonce of the given size placed
+// directly after the block height in the scriptSig. it returns the serialized coinbase split
+// around the extranonce (coinbase1 and coinbase2) so that it can be sent to miners as is.
+func GenerateCoinbase(
+	outputScripts [][]byte,
+	amounts []uint64,
+	blockHeight uint64,
+	extraNonceSize int,
+	extraData, defaultWitness string,
+) ([]byte, []byte, error) {
+	if len(outputScripts) != len(amounts) {
+		return nil, nil, fmt.Errorf("output and amount length mismatch")
+	} else if len(outputScripts) == 0 {
+		return nil, nil, fmt.Errorf("cannot send transaction without recipients")
+	}
+
+	tx := btctx.NewTransaction(txVersion, 0, nil, nil, false)
+
+	blockHeightSerialBytes, lengthBytes, err := crypto.SerializeBlockHeight(blockHeight)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	heightScript := bytes.Join([][]byte{
+		lengthBytes,
+		crypto.ReverseBytes(blockHeightSerialBytes),
+	}, nil)
+
+	scriptSig := bytes.Join([][]byte{
+		heightScript,
+		make([]byte, extraNonceSize),
+		[]byte(extraData),
+	}, nil)
+	if len(scriptSig) > maxCoinbaseScriptSize {
+		return nil, nil, fmt.Errorf("coinbase script too large: %d", len(scriptSig))
+	}
+
+	prevTx := "0000000000000000000000000000000000000000000000000000000000000000"
+	tx.AddInput(prevTx, 0xFFFFFFFF, 0xFFFFFFFF, scriptSig)
+	for i, outputScript := range outputScripts {
+		tx.AddOutput(outputScript, amounts[i])
+	}
+
+	if len(defaultWitness) > 0 {
+		witness, err := hex.DecodeString(defaultWitness)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		tx.AddOutput(witness, 0)
+	}
+
+	serialized, err := tx.Serialize(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// version (4) + input count (1) + prev hash (32) + prev index (4) + script length (1)
+	extraNonceIndex := 4 + 1 + 32 + 4 + 1 + len(heightScript)
+	coinbase1 := serialized[:extraNonceIndex]
+	coinbase2 := serialized[extraNonceIndex+extraNonceSize:]
+
+	return coinbase1, coinbase2, nil
+}
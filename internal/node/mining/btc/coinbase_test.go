@@ -0,0 +1,78 @@
+package btc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenerateCoinbase(t *testing.T) {
+	tests := []struct {
+		outputScript   []byte
+		amount         uint64
+		height         uint64
+		extraNonceSize int
+		extraData      string
+		defaultWitness string
+		coinbase1      []byte
+		coinbase2      []byte
+	}{
+		{
+			outputScript: []byte{
+				0x76, 0xa9, 0x14, 0x62, 0xe9, 0x07, 0xb1, 0x5c, 0xbf, 0x27, 0xd5, 0x42, 0x53, 0x99, 0xeb, 0xf6,
+				0xf0, 0xfb, 0x50, 0xeb, 0xb8, 0x8f, 0x18, 0x88, 0xac,
+			},
+			amount:         625000000,
+			height:         739165,
+			extraNonceSize: 8,
+			extraData:      "",
+			defaultWitness: "6a24aa21a9ede2f61c3f71d1defd3fa999dfa36953755c690689799962b48bebd836974e8cf9",
+			coinbase1: []byte{
+				0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0x0c, 0x03, 0x5d, 0x47, 0x0b,
+			},
+			coinbase2: []byte{
+				0xff, 0xff, 0xff, 0xff, 0x02, 0x40, 0xbe, 0x40, 0x25, 0x00, 0x00, 0x00, 0x00, 0x19, 0x76, 0xa9,
+				0x14, 0x62, 0xe9, 0x07, 0xb1, 0x5c, 0xbf, 0x27, 0xd5, 0x42, 0x53, 0x99, 0xeb, 0xf6, 0xf0, 0xfb,
+				0x50, 0xeb, 0xb8, 0x8f, 0x18, 0x88, 0xac, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x26,
+				0x6a, 0x24, 0xaa, 0x21, 0xa9, 0xed, 0xe2, 0xf6, 0x1c, 0x3f, 0x71, 0xd1, 0xde, 0xfd, 0x3f, 0xa9,
+				0x99, 0xdf, 0xa3, 0x69, 0x53, 0x75, 0x5c, 0x69, 0x06, 0x89, 0x79, 0x99, 0x62, 0xb4, 0x8b, 0xeb,
+				0xd8, 0x36, 0x97, 0x4e, 0x8c, 0xf9, 0x00, 0x00, 0x00, 0x00,
+			},
+		},
+		{
+			outputScript: []byte{
+				0x76, 0xa9, 0x14, 0x62, 0xe9, 0x07, 0xb1, 0x5c, 0xbf, 0x27, 0xd5, 0x42, 0x53, 0x99, 0xeb, 0xf6,
+				0xf0, 0xfb, 0x50, 0xeb, 0xb8, 0x8f, 0x18, 0x88, 0xac,
+			},
+			amount:         625001234,
+			height:         750000,
+			extraNonceSize: 8,
+			extraData:      "/magicpool/",
+			defaultWitness: "",
+			coinbase1: []byte{
+				0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0x17, 0x03, 0xb0, 0x71, 0x0b,
+			},
+			coinbase2: []byte{
+				0x2f, 0x6d, 0x61, 0x67, 0x69, 0x63, 0x70, 0x6f, 0x6f, 0x6c, 0x2f, 0xff, 0xff, 0xff, 0xff, 0x01,
+				0x12, 0xc3, 0x40, 0x25, 0x00, 0x00, 0x00, 0x00, 0x19, 0x76, 0xa9, 0x14, 0x62, 0xe9, 0x07, 0xb1,
+				0x5c, 0xbf, 0x27, 0xd5, 0x42, 0x53, 0x99, 0xeb, 0xf6, 0xf0, 0xfb, 0x50, 0xeb, 0xb8, 0x8f, 0x18,
+				0x88, 0xac, 0x00, 0x00, 0x00, 0x00,
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		coinbase1, coinbase2, err := GenerateCoinbase([][]byte{tt.outputScript}, []uint64{tt.amount}, tt.height,
+			tt.extraNonceSize, tt.extraData, tt.defaultWitness)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if bytes.Compare(coinbase1, tt.coinbase1) != 0 {
+			t.Errorf("failed on %d: coinbase1 mismatch: have %x, want %x", i, coinbase1, tt.coinbase1)
+		} else if bytes.Compare(coinbase2, tt.coinbase2) != 0 {
+			t.Errorf("failed on %d: coinbase2 mismatch: have %x, want %x", i, coinbase2, tt.coinbase2)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package btc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/magicpool-co/pool/pkg/common"
+	"github.com/magicpool-co/pool/pkg/crypto/bech32"
+	"github.com/magicpool-co/pool/pkg/crypto/tx/btctx"
+	"github.com/magicpool-co/pool/types"
+)
+
+const (
+	cashAddrCharset       = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	mainnetCashAddrPrefix = "bitcoincash"
+	testnetCashAddrPrefix = "bchtest"
+
+	cashAddrP2PKHID = 0x00
+	cashAddrP2SHID  = 0x08
+
+	globalDiffFactor = 4294967296
+	extraNonce1Size  = 4
+	extraNonce2Size  = 4
+)
+
+var (
+	maxDiffBig   = common.MustParseBigHex("00000000ffff0000000000000000000000000000000000000000000000000000")
+	shareDiffBig = common.MustParseBigHex("000000000000ffff000000000000000000000000000000000000000000000000") // 65536
+	shareDiff    = new(types.Difficulty).SetFromBig(shareDiffBig, maxDiffBig)
+	units        = new(types.Number).SetFromValue(1e8)
+)
+
+func (node Node) Name() string {
+	switch node.bitcoinType {
+	case BTC:
+		return "Bitcoin"
+	case BCH:
+		return "Bitcoin Cash"
+	default:
+		return ""
+	}
+}
+
+func (node Node) Chain() string {
+	switch node.bitcoinType {
+	case BTC:
+		return "BTC"
+	case BCH:
+		return "BCH"
+	default:
+		return ""
+	}
+}
+
+func (node Node) Address() string {
+	return node.address
+}
+
+func (node Node) GetAccountingType() types.AccountingType {
+	return types.UTXOStructure
+}
+
+func (node Node) GetAddressPrefix() string {
+	switch node.bitcoinType {
+	case BCH:
+		return node.cashAddrPrefix
+	default:
+		return ""
+	}
+}
+
+func (node Node) Mocked() bool {
+	return node.mocked
+}
+
+func (node Node) GetUnits() *types.Number {
+	return units
+}
+
+func (node Node) GetShareDifficulty(diffFactor int) *types.Difficulty {
+	if diffFactor > 1 {
+		return shareDiff.Mul(int64(diffFactor))
+	}
+	return shareDiff
+}
+
+func (node Node) GetAdjustedShareDifficulty() float64 {
+	return float64(shareDiff.Value()) * globalDiffFactor
+}
+
+func (node Node) GetMaxDifficulty() *big.Int {
+	return maxDiffBig
+}
+
+func (node Node) GetImmatureDepth() uint64 {
+	return 10
+}
+
+func (node Node) GetMatureDepth() uint64 {
+	return 100
+}
+
+func (node Node) ShouldMergeUTXOs() bool {
+	return false
+}
+
+func (node Node) CalculateHashrate(blockTime, difficulty float64) float64 {
+	if blockTime == 0 || difficulty == 0 {
+		return 0
+	}
+	return difficulty * (globalDiffFactor / blockTime)
+}
+
+func cashAddrToScript(address, prefix string) ([]byte, error) {
+	decodedPrefix, version, decoded, err := bech32.DecodeBCH(cashAddrCharset, address)
+	if err != nil {
+		return nil, err
+	} else if decodedPrefix != prefix {
+		return nil, fmt.Errorf("prefix mismatch")
+	} else if len(decoded) != 20 {
+		return nil, fmt.Errorf("length mismatch")
+	}
+
+	switch version {
+	case cashAddrP2PKHID:
+		return btctx.CompileP2PKH(decoded), nil
+	case cashAddrP2SHID:
+		return btctx.CompileP2SH(decoded), nil
+	default:
+		return nil, fmt.Errorf("unknown address version")
+	}
+}
+
+func (node Node) addressToScript(address string) ([]byte, error) {
+	switch node.bitcoinType {
+	case BCH:
+		return cashAddrToScript(address, node.cashAddrPrefix)
+	default:
+		return btctx.AddressToScript(address, node.prefixP2PKH, node.prefixP2SH, true)
+	}
+}
+
+func ValidateAddress(bitcoinType BitcoinType, address string) bool {
+	var err error
+	switch bitcoinType {
+	case BTC:
+		_, err = btctx.AddressToScript(address, mainnetPrefixP2PKH, mainnetPrefixP2SH, true)
+	case BCH:
+		_, err = cashAddrToScript(address, mainnetCashAddrPrefix)
+	default:
+		return false
+	}
+
+	return err == nil
+}
+
+func (node Node) ValidateAddress(address string) bool {
+	_, err := node.addressToScript(address)
+
+	return err == nil
+}
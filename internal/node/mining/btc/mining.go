@@ -0,0 +1,542 @@
+package btc
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/magicpool-co/pool/internal/pooldb"
+	"github.com/magicpool-co/pool/internal/tsdb"
+	"github.com/magicpool-co/pool/pkg/common"
+	"github.com/magicpool-co/pool/pkg/crypto/blkbuilder"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/pkg/stratum/rpc"
+	"github.com/magicpool-co/pool/types"
+)
+
+func (node Node) GetBlockExplorerURL(round *pooldb.Round) string {
+	switch node.bitcoinType {
+	case BCH:
+		if node.mainnet {
+			return fmt.Sprintf("https://blockchair.com/bitcoin-cash/block/%s", round.Hash)
+		}
+		return fmt.Sprintf("https://tbch.loping.net/block/%s", round.Hash)
+	default:
+		if node.mainnet {
+			return fmt.Sprintf("https://mempool.space/block/%s", round.Hash)
+		}
+		return fmt.Sprintf("https://mempool.space/testnet/block/%s", round.Hash)
+	}
+}
+
+func (node Node) getStatusByHost(hostID string) (uint64, bool, error) {
+	info, err := node.getBlockchainInfo(hostID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	height := info.Blocks
+	syncing := info.VerificationProgress < 0.9999 || info.Blocks != info.Headers
+	node.rpcHost.SetHostSyncStatus(hostID, !syncing)
+
+	return height, syncing, nil
+}
+
+func (node Node) GetStatus() (uint64, bool, error) {
+	return node.getStatusByHost("")
+}
+
+func (node Node) PingHosts() ([]string, []uint64, []bool, []error) {
+	hostIDs := node.rpcHost.GetAllHosts()
+	heights := make([]uint64, len(hostIDs))
+	statuses := make([]bool, len(hostIDs))
+	errs := make([]error, len(hostIDs))
+
+	for i, hostID := range hostIDs {
+		heights[i], statuses[i], errs[i] = node.getStatusByHost(hostID)
+	}
+
+	return hostIDs, heights, statuses, errs
+}
+
+func (node Node) GetBlockHashes(start, end uint64) ([]string, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid range")
+	}
+
+	heights := make([]uint64, end-start+1)
+	for i := range heights {
+		heights[i] = start + uint64(i)
+	}
+
+	return node.getBlockHashMany(heights)
+}
+
+func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid range")
+	}
+
+	heights := make([]uint64, end-start+1)
+	for i := range heights {
+		heights[i] = start + uint64(i)
+	}
+
+	hashes, err := node.getBlockHashMany(heights)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*tsdb.RawBlock, len(hashes))
+	for i := 0; i < len(hashes); i += 5 {
+		limit := i + 5
+		if len(hashes) < limit {
+			limit = len(hashes)
+		}
+
+		rawBlocks, err := node.getBlockMany(hashes[i:limit])
+		if err != nil {
+			return nil, err
+		}
+
+		for j, block := range rawBlocks {
+			if len(block.Transactions) == 0 {
+				return nil, fmt.Errorf("no transactions in block")
+			}
+
+			value, err := node.getRewardsFromTX(block.Transactions[0])
+			if err != nil {
+				return nil, err
+			}
+			valueBig := new(big.Int).SetUint64(value)
+
+			blocks[i+j] = &tsdb.RawBlock{
+				ChainID:    node.Chain(),
+				Hash:       block.Hash,
+				Height:     start + uint64(i+j),
+				Value:      common.BigIntToFloat64(valueBig, node.GetUnits().Big()),
+				Difficulty: block.Difficulty,
+				TxCount:    uint64(len(block.Transactions)),
+				Timestamp:  time.Unix(block.Time, 0),
+			}
+		}
+	}
+
+	return blocks, nil
+}
+
+func (node Node) GetBlocksByHash(startHash string, limit uint64) ([]*tsdb.RawBlock, error) {
+	return nil, fmt.Errorf("GetBlocks: not implemented")
+}
+
+func (node Node) getRewardsFromTX(tx *Transaction) (uint64, error) {
+	// extra outputs from the coinbase policy never reach the pool wallet, they're
+	// matched by script since addresses aren't consistently returned by the node
+	extraScripts := make(map[string]bool)
+	if node.coinbasePolicy != nil {
+		for _, output := range node.coinbasePolicy.ExtraOutputs {
+			script, err := node.addressToScript(output.Address)
+			if err != nil {
+				return 0, err
+			}
+			extraScripts[hex.EncodeToString(script)] = true
+		}
+	}
+
+	var amount uint64
+	for _, input := range tx.Inputs {
+		if len(input.Coinbase) > 0 {
+			for _, out := range tx.Outputs {
+				if extraScripts[out.ScriptPubKey.Hex] {
+					continue
+				}
+
+				valBig, err := common.StringDecimalToBigint(out.Value.String(), node.GetUnits().Big())
+				if err != nil {
+					return amount, err
+				}
+				amount += valBig.Uint64()
+			}
+		}
+	}
+
+	return amount, nil
+}
+
+func (node *Node) SetCoinbasePolicy(policy *types.CoinbasePolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	for _, output := range policy.ExtraOutputs {
+		if !node.ValidateAddress(output.Address) {
+			return fmt.Errorf("invalid coinbase output address: %s", output.Address)
+		}
+	}
+
+	node.coinbasePolicy = policy
+
+	return nil
+}
+
+func (node Node) parseBlockTemplate(template *BlockTemplate) (*types.StratumJob, error) {
+	// bch has no witness commitment, so segwit is only
+	// enabled when the template provides one
+	segwit := len(template.DefaultWitnessCommitment) > 0
+
+	outputScript := node.outputScript
+	if payoutAddress := node.coinbasePolicy.GetPayoutAddress(""); payoutAddress != "" {
+		var err error
+		outputScript, err = node.addressToScript(payoutAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	minerReward, extraAddresses, extraAmounts := node.coinbasePolicy.SplitReward(template.CoinbaseValue)
+	outputScripts := [][]byte{outputScript}
+	amounts := append([]uint64{minerReward}, extraAmounts...)
+	for _, address := range extraAddresses {
+		script, err := node.addressToScript(address)
+		if err != nil {
+			return nil, err
+		}
+		outputScripts = append(outputScripts, script)
+	}
+
+	coinbase1, coinbase2, err := GenerateCoinbase(outputScripts, amounts, template.Height,
+		extraNonce1Size+extraNonce2Size, node.coinbasePolicy.GetSignature(), template.DefaultWitnessCommitment)
+	if err != nil {
+		return nil, err
+	}
+
+	txHashes := make([][]byte, len(template.Transactions))
+	txHexes := make([][]byte, len(template.Transactions))
+	for i, tx := range template.Transactions {
+		txid := tx.TxID
+		if txid == "" {
+			txid = tx.Hash
+		}
+
+		txHashes[i], err = hex.DecodeString(txid)
+		if err != nil {
+			return nil, err
+		}
+
+		txHexes[i], err = hex.DecodeString(tx.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	builder, err := blkbuilder.NewBitcoinBuilder(template.Version, template.CurTime, template.Bits,
+		template.PreviousBlockHash, coinbase1, coinbase2, segwit, txHashes, txHexes)
+	if err != nil {
+		return nil, err
+	}
+
+	bits, err := strconv.ParseUint(template.Bits, 16, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &types.StratumJob{
+		Height:       new(types.Number).SetFromValue(template.Height),
+		Difficulty:   new(types.Difficulty).SetFromBits(uint32(bits), node.GetMaxDifficulty()),
+		Timestamp:    time.Unix(int64(template.CurTime), 0),
+		Version:      new(types.Number).SetFromValue(uint64(template.Version)),
+		BlockBuilder: builder,
+	}
+
+	return job, nil
+}
+
+func (node Node) JobNotify(ctx context.Context, interval time.Duration) chan *types.StratumJob {
+	jobCh := make(chan *types.StratumJob)
+	ticker := time.NewTicker(interval)
+	staticInterval := time.Minute
+
+	go func() {
+		defer node.logger.RecoverPanic()
+
+		var lastHeight uint64
+		var lastJob time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				now := time.Now()
+				hostID, template, err := node.getBlockTemplate()
+				if err != nil {
+					node.logger.Error(err)
+				} else if lastHeight != template.Height || now.After(lastJob.Add(staticInterval)) {
+					job, err := node.parseBlockTemplate(template)
+					if err != nil {
+						node.logger.Error(err)
+					} else {
+						job.HostID = hostID
+						lastHeight = job.Height.Value()
+						lastJob = now
+						jobCh <- job
+					}
+				}
+			}
+		}
+	}()
+
+	return jobCh
+}
+
+func (node Node) SubmitWork(
+	job *types.StratumJob,
+	work *types.StratumWork,
+	diffFactor int,
+) (types.ShareStatus, *types.Hash, *pooldb.Round, error) {
+	builder, ok := job.BlockBuilder.(*blkbuilder.BitcoinBuilder)
+	if !ok {
+		return types.InvalidShare, nil, nil, fmt.Errorf("invalid block builder for job %s", job.ID)
+	}
+
+	// the ntime can only be rolled forward from the template
+	// time, and never more than two hours into the future
+	if work.Time != nil {
+		nTime := time.Unix(int64(work.Time.Value()), 0)
+		if nTime.Before(job.Timestamp) || nTime.After(time.Now().Add(time.Hour*2)) {
+			return types.InvalidShare, nil, nil, nil
+		}
+	}
+
+	_, headerHash, err := builder.SerializeHeader(work)
+	if err != nil {
+		return types.InvalidShare, nil, nil, err
+	}
+
+	hash := new(types.Hash).SetFromBytes(headerHash)
+	if !hash.MeetsDifficulty(node.GetShareDifficulty(diffFactor)) {
+		return types.RejectedShare, hash, nil, nil
+	} else if !hash.MeetsDifficulty(job.Difficulty) {
+		return types.AcceptedShare, hash, nil, nil
+	}
+
+	_, coinbaseHash, err := builder.SerializeCoinbase(work)
+	if err != nil {
+		return types.AcceptedShare, hash, nil, err
+	}
+
+	serializedBlock, err := builder.SerializeBlock(work)
+	if err != nil {
+		return types.AcceptedShare, hash, nil, err
+	}
+
+	err = node.submitBlock(job.HostID, hex.EncodeToString(serializedBlock))
+	if err != nil {
+		return types.AcceptedShare, hash, nil, err
+	}
+
+	round := &pooldb.Round{
+		ChainID:      node.Chain(),
+		Height:       job.Height.Value(),
+		Hash:         hash.Hex(),
+		Nonce:        types.Uint64Ptr(work.Nonce.Value()),
+		CoinbaseTxID: types.StringPtr(hex.EncodeToString(coinbaseHash)),
+		Difficulty:   job.Difficulty.Value(),
+		Pending:      true,
+		Mature:       false,
+		Uncle:        false,
+		Orphan:       false,
+	}
+
+	return types.AcceptedShare, hash, round, nil
+}
+
+func (node Node) ParseWork(data []json.RawMessage, extraNonce string) (*types.StratumWork, error) {
+	// the sixth parameter is only sent if version rolling is enabled
+	if len(data) != 5 && len(data) != 6 {
+		return nil, fmt.Errorf("incorrect work array length")
+	}
+
+	var worker, jobID string
+	if err := json.Unmarshal(data[0], &worker); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(data[1], &jobID); err != nil {
+		return nil, err
+	}
+
+	var extraNonce2, nTime, nonce string
+	if err := json.Unmarshal(data[2], &extraNonce2); err != nil || len(extraNonce2) != extraNonce2Size*2 {
+		return nil, fmt.Errorf("invalid extranonce2 parameter")
+	} else if err := json.Unmarshal(data[3], &nTime); err != nil || len(nTime) != 8 {
+		return nil, fmt.Errorf("invalid ntime parameter")
+	} else if err := json.Unmarshal(data[4], &nonce); err != nil || len(nonce) != 8 {
+		return nil, fmt.Errorf("invalid nonce parameter")
+	}
+
+	extraNonceBytes, err := hex.DecodeString(extraNonce + extraNonce2)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extranonce2 parameter: %v", err)
+	} else if len(extraNonceBytes) != extraNonce1Size+extraNonce2Size {
+		return nil, fmt.Errorf("invalid extranonce length")
+	}
+
+	var nonceVal, timeVal *types.Number
+	if nonceVal, err = new(types.Number).SetFromHex(nonce); err != nil {
+		return nil, err
+	} else if timeVal, err = new(types.Number).SetFromHex(nTime); err != nil {
+		return nil, err
+	}
+
+	work := &types.StratumWork{
+		WorkerID:   worker,
+		JobID:      jobID,
+		Nonce:      nonceVal,
+		Time:       timeVal,
+		ExtraNonce: extraNonceBytes,
+	}
+
+	if len(data) == 6 {
+		var versionBits string
+		if err := json.Unmarshal(data[5], &versionBits); err != nil || len(versionBits) != 8 {
+			return nil, fmt.Errorf("invalid version bits parameter")
+		} else if work.Version, err = new(types.Number).SetFromHex(versionBits); err != nil {
+			return nil, err
+		}
+	}
+
+	return work, nil
+}
+
+func (node Node) MarshalJob(
+	id interface{},
+	job *types.StratumJob,
+	cleanJobs bool,
+	clientType, diffFactor int,
+) (interface{}, error) {
+	partialJob := job.BlockBuilder.PartialJob()
+	result := append([]interface{}{job.ID}, partialJob...)
+	result = append(result, cleanJobs)
+
+	return rpc.NewRequestWithID(id, "mining.notify", result...)
+}
+
+func (node Node) GetClientType(minerClient string) int {
+	return 0
+}
+
+func (node Node) GetSubscribeResponses(id []byte, clientID, extraNonce string) ([]interface{}, error) {
+	var subscriptions = []interface{}{
+		[]interface{}{"mining.set_difficulty", clientID},
+		[]interface{}{"mining.notify", clientID},
+	}
+
+	res, err := rpc.NewResponse(id, []interface{}{subscriptions, extraNonce, extraNonce2Size})
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{res}, nil
+}
+
+func (node Node) GetAuthorizeResponses(clientType, diffFactor int) ([]interface{}, error) {
+	res, err := node.GetSetDifficultyResponse(clientType, diffFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{res}, nil
+}
+
+func (node Node) GetSetDifficultyResponse(clientType, diffFactor int) (interface{}, error) {
+	return rpc.NewRequest("mining.set_difficulty", node.GetShareDifficulty(diffFactor).Value())
+}
+
+func (node Node) UnlockRound(round *pooldb.Round) error {
+	if round.CoinbaseTxID == nil {
+		return fmt.Errorf("block %d has no coinbase txid", round.Height)
+	}
+
+	blockHash, err := node.getBlockHash(round.Height)
+	if err != nil {
+		return err
+	}
+
+	block, err := node.getBlock(blockHash)
+	if err != nil {
+		return err
+	}
+
+	round.Uncle = false
+	round.Orphan = true
+	round.Pending = false
+	round.Mature = false
+	round.Spent = false
+
+	if block.Confirmations == -1 {
+		round.Orphan = true
+		return nil
+	} else if uint64(block.Confirmations) < node.GetImmatureDepth() {
+		round.Pending = true
+		round.Orphan = false
+		return nil
+	} else if block.Hash == round.Hash {
+		coinbaseTxID := types.StringValue(round.CoinbaseTxID)
+		if len(block.Transactions) == 0 {
+			return nil
+		} else if tx := block.Transactions[0]; tx.TxID != coinbaseTxID {
+			return nil
+		}
+
+		value, err := node.getRewardsFromTX(block.Transactions[0])
+		if err != nil {
+			return err
+		}
+
+		round.Value = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(value)}
+		round.Orphan = false
+		round.CreatedAt = time.Unix(block.Time, 0)
+	}
+
+	return nil
+}
+
+func (node Node) MatureRound(round *pooldb.Round) ([]*pooldb.UTXO, error) {
+	if round.Pending || round.Orphan || round.Mature {
+		return nil, nil
+	} else if !round.Value.Valid {
+		return nil, fmt.Errorf("no value for round %d", round.ID)
+	} else if round.CoinbaseTxID == nil {
+		return nil, fmt.Errorf("no coinbase txid for round %d", round.ID)
+	}
+
+	block, err := node.getBlock(round.Hash)
+	if err != nil {
+		return nil, err
+	} else if block.Height != round.Height {
+		return nil, fmt.Errorf("mismatch on round and block height for round %d", round.ID)
+	} else if block.Confirmations == -1 {
+		round.Orphan = true
+		return nil, nil
+	} else if uint64(block.Confirmations) < node.GetMatureDepth() {
+		return nil, nil
+	}
+
+	round.Mature = true
+
+	utxos := []*pooldb.UTXO{
+		&pooldb.UTXO{
+			ChainID: round.ChainID,
+			Value:   round.Value,
+			TxID:    types.StringValue(round.CoinbaseTxID),
+			Index:   0,
+			Active:  true,
+			Spent:   false,
+		},
+	}
+
+	return utxos, nil
+}
@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"github.com/magicpool-co/pool/pkg/stratum/rpc"
+)
+
+func GetBlockchainInfo() *rpc.Response {
+	return rpc.NewResponseFromJSON(nil, []byte(`{"chain":"main","blocks":739164,"headers":739164,"bestblockhash":"0000000000000000000812e3fbb6ee9d13bd83a070e360db9d4ffa727fbd31ea","difficulty":30283293547736.8,"time":1654279320,"mediantime":1654276913,"verificationprogress":0.9999993966830962,"initialblockdownload":false,"chainwork":"00000000000000000000000000000000000000002ad8d3fd9d69f3c8d2b6a8b8","size_on_disk":440127645913,"pruned":false,"warnings":""}`))
+}
+
+func GetRawTransaction(txid string) *rpc.Response {
+	return nil
+}
+
+func GetBlockHash(height uint64) *rpc.Response {
+	return nil
+}
+
+func GetBlockHashMany(heights []uint64) []*rpc.Response {
+	return nil
+}
+
+func GetBlock(hash string) *rpc.Response {
+	return nil
+}
+
+func GetBlockMany(hashes []string) []*rpc.Response {
+	return nil
+}
+
+func GetBlockTemplate() *rpc.Response {
+	return rpc.NewResponseFromJSON(nil, []byte(`{"capabilities":["proposal"],"version":536870916,"rules":["csv","!segwit","taproot"],"vbavailable":{},"vbrequired":0,"previousblockhash":"0000000000000000000812e3fbb6ee9d13bd83a070e360db9d4ffa727fbd31ea","transactions":[],"coinbaseaux":{},"coinbasevalue":625000000,"longpollid":"0000000000000000000812e3fbb6ee9d13bd83a070e360db9d4ffa727fbd31ea3","target":"000000000000000000096a200000000000000000000000000000000000000000","mintime":1654276914,"mutable":["time","transactions","prevblock"],"noncerange":"00000000ffffffff","sigoplimit":80000,"sizelimit":4000000,"weightlimit":4000000,"curtime":1654279674,"bits":"17096a20","height":739165,"default_witness_commitment":"6a24aa21a9ede2f61c3f71d1defd3fa999dfa36953755c690689799962b48bebd836974e8cf9"}`))
+}
+
+func SubmitBlock(hostID, block string) *rpc.Response {
+	return rpc.NewResponseFromJSON(nil, []byte(`null`))
+}
+
+func SendRawTransaction(tx string) *rpc.Response {
+	return nil
+}
+
+func EstimateSmartFee(blocks int) *rpc.Response {
+	return rpc.NewResponseFromJSON(nil, []byte(`{"feerate":0.00012,"blocks":6}`))
+}
+
+func ScanTxOutSet(address string) *rpc.Response {
+	return rpc.NewResponseFromJSON(nil, []byte(`{"success":true,"txouts":0,"height":739164,"bestblock":"0000000000000000000812e3fbb6ee9d13bd83a070e360db9d4ffa727fbd31ea","unspents":[],"total_amount":0.00000000}`))
+}
@@ -0,0 +1,286 @@
+package btc
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-json"
+
+	"github.com/magicpool-co/pool/internal/node/mining/btc/mock"
+	"github.com/magicpool-co/pool/pkg/stratum/rpc"
+)
+
+func (node Node) getBlockchainInfo(hostID string) (*BlockchainInfo, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.GetBlockchainInfo()
+	} else {
+		if hostID == "" {
+			res, err = node.rpcHost.ExecRPCFromArgsSynced("getblockchaininfo")
+		} else {
+			req, err := rpc.NewRequestWithHostID(hostID, "getblockchaininfo")
+			if err != nil {
+				return nil, err
+			}
+
+			res, err = node.rpcHost.ExecRPC(req)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	info := new(BlockchainInfo)
+	if err := json.Unmarshal(res.Result, info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func (node Node) getRawTransaction(txid string) (*Transaction, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.GetRawTransaction(txid)
+	} else {
+		res, err = node.rpcHost.ExecRPCFromArgsSynced("getrawtransaction", txid, 1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx := new(Transaction)
+	if err := json.Unmarshal(res.Result, tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+func (node Node) getBlockHash(height uint64) (string, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.GetBlockHash(height)
+	} else {
+		res, err = node.rpcHost.ExecRPCFromArgsSynced("getblockhash", height)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var hash string
+	if err := json.Unmarshal(res.Result, &hash); err != nil {
+		return "", err
+	}
+
+	return hash, nil
+}
+
+func (node Node) getBlockHashMany(heights []uint64) ([]string, error) {
+	var responses []*rpc.Response
+	if node.mocked {
+		responses = mock.GetBlockHashMany(heights)
+	} else {
+		reqs := make([]*rpc.Request, len(heights))
+		var err error
+		for i, height := range heights {
+			reqs[i], err = rpc.NewRequestWithID(i, "getblockhash", height)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		responses, err = node.rpcHost.ExecRPCBulk(reqs)
+		if err != nil {
+			return nil, err
+		} else if len(responses) != len(reqs) {
+			return nil, fmt.Errorf("req and res length mismatch: %d and %d", len(responses), len(reqs))
+		}
+	}
+
+	hashes := make([]string, len(responses))
+	for i, res := range responses {
+		err := json.Unmarshal(res.Result, &hashes[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return hashes, nil
+}
+
+func (node Node) getBlock(hash string) (*Block, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.GetBlock(hash)
+	} else {
+		res, err = node.rpcHost.ExecRPCFromArgsSynced("getblock", hash, 2)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	block := new(Block)
+	if err := json.Unmarshal(res.Result, block); err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+func (node Node) getBlockMany(hashes []string) ([]*Block, error) {
+	var responses []*rpc.Response
+	if node.mocked {
+		responses = mock.GetBlockMany(hashes)
+	} else {
+		reqs := make([]*rpc.Request, len(hashes))
+		var err error
+		for i, hash := range hashes {
+			reqs[i], err = rpc.NewRequestWithID(i, "getblock", hash, 2)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		responses, err = node.rpcHost.ExecRPCBulk(reqs)
+		if err != nil {
+			return nil, err
+		} else if len(responses) != len(reqs) {
+			return nil, fmt.Errorf("req and res length mismatch: %d and %d", len(responses), len(reqs))
+		}
+	}
+
+	blocks := make([]*Block, len(responses))
+	for i, res := range responses {
+		err := json.Unmarshal(res.Result, &blocks[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return blocks, nil
+}
+
+func (node Node) getBlockTemplate() (string, *BlockTemplate, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.GetBlockTemplate()
+	} else {
+		capabilities := map[string]interface{}{
+			"capabilities": []string{"coinbasetx", "workid", "coinbase/append"},
+		}
+		if node.bitcoinType == BTC {
+			capabilities["rules"] = []string{"segwit"}
+		}
+		res, err = node.rpcHost.ExecRPCFromArgsSynced("getblocktemplate", capabilities)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	template := new(BlockTemplate)
+	if err := json.Unmarshal(res.Result, template); err != nil {
+		return "", nil, err
+	} else if len(template.PreviousBlockHash) == 0 {
+		return "", nil, fmt.Errorf("invalid getblocktemplate response")
+	}
+
+	return res.HostID, template, nil
+}
+
+func validateSubmitBlock(res *rpc.Response) error {
+	var result string
+	if err := json.Unmarshal(res.Result, &result); err != nil {
+		return err
+	} else if len(result) > 0 && result != "duplicate" {
+		return fmt.Errorf("submit block error: %s", result)
+	}
+
+	return nil
+}
+
+// submitBlock broadcasts the block to every synced host and the host that created
+// the template in parallel, the first host to accept the block wins.
+func (node Node) submitBlock(hostID, block string) error {
+	if node.mocked {
+		return validateSubmitBlock(mock.SubmitBlock(hostID, block))
+	}
+
+	req, err := rpc.NewRequestWithHostID(hostID, "submitblock", block)
+	if err != nil {
+		return err
+	}
+
+	_, err = node.rpcHost.ExecRPCBroadcast(req, validateSubmitBlock)
+
+	return err
+}
+
+func (node Node) sendRawTransaction(tx string) (string, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.SendRawTransaction(tx)
+	} else {
+		res, err = node.rpcHost.ExecRPCFromArgsSynced("sendrawtransaction", tx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var txid string
+	if err := json.Unmarshal(res.Result, &txid); err != nil {
+		return "", err
+	}
+
+	return txid, nil
+}
+
+func (node Node) estimateSmartFee(blocks int) (*SmartFee, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.EstimateSmartFee(blocks)
+	} else {
+		res, err = node.rpcHost.ExecRPCFromArgsSynced("estimatesmartfee", blocks)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fee := new(SmartFee)
+	if err := json.Unmarshal(res.Result, fee); err != nil {
+		return nil, err
+	} else if len(fee.Errors) > 0 {
+		return nil, fmt.Errorf("estimate fee error: %s", fee.Errors[0])
+	}
+
+	return fee, nil
+}
+
+func (node Node) scanTxOutSet(address string) (*UTXOSet, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.ScanTxOutSet(address)
+	} else {
+		descriptors := []string{"addr(" + address + ")"}
+		res, err = node.rpcHost.ExecRPCFromArgsSynced("scantxoutset", "start", descriptors)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	utxoSet := new(UTXOSet)
+	if err := json.Unmarshal(res.Result, utxoSet); err != nil {
+		return nil, err
+	} else if !utxoSet.Success {
+		return nil, fmt.Errorf("scantxoutset failed for %s", address)
+	}
+
+	return utxoSet, nil
+}
@@ -0,0 +1,111 @@
+package btc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/magicpool-co/pool/pkg/common"
+	"github.com/magicpool-co/pool/pkg/crypto/tx/btctx"
+	"github.com/magicpool-co/pool/types"
+)
+
+func (node Node) GetTxExplorerURL(txid string) string {
+	switch node.bitcoinType {
+	case BCH:
+		return "https://blockchair.com/bitcoin-cash/transaction/" + txid
+	default:
+		return "https://mempool.space/tx/" + txid
+	}
+}
+
+func (node Node) GetAddressExplorerURL(address string) string {
+	switch node.bitcoinType {
+	case BCH:
+		return "https://blockchair.com/bitcoin-cash/address/" + address
+	default:
+		return "https://mempool.space/address/" + address
+	}
+}
+
+func (node Node) GetBalance() (*big.Int, error) {
+	utxoSet, err := node.scanTxOutSet(node.address)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.StringDecimalToBigint(utxoSet.TotalAmount.String(), node.GetUnits().Big())
+}
+
+func (node Node) GetTx(txid string) (*types.TxResponse, error) {
+	tx, err := node.getRawTransaction(txid)
+	if err != nil {
+		return nil, err
+	}
+
+	var height uint64
+	var confirmed bool
+	if tx.BlockHash != "" && tx.Confirmations > 0 {
+		block, err := node.getBlock(tx.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+
+		confirmed = true
+		height = block.Height
+	}
+
+	res := &types.TxResponse{
+		Hash:        txid,
+		BlockNumber: height,
+		Confirmed:   confirmed,
+	}
+
+	return res, nil
+}
+
+func (node Node) getFeeRate() (uint64, error) {
+	fee, err := node.estimateSmartFee(6)
+	if err != nil {
+		return 0, err
+	}
+
+	// the fee rate is returned in BTC/kvB, convert it to sat/vB
+	feeRate := uint64(math.Ceil(fee.FeeRate * 1e8 / 1000))
+	if feeRate == 0 {
+		feeRate = 1
+	}
+
+	return feeRate, nil
+}
+
+func (node Node) CreateTx(inputs []*types.TxInput, outputs []*types.TxOutput) (string, string, error) {
+	// bch signatures require SIGHASH_FORKID, which btctx does not support
+	if node.bitcoinType == BCH {
+		return "", "", fmt.Errorf("CreateTx: not implemented")
+	}
+
+	feeRate, err := node.getFeeRate()
+	if err != nil {
+		return "", "", err
+	}
+
+	baseTx := btctx.NewTransaction(txVersion, 0, node.prefixP2PKH, node.prefixP2SH, true)
+	rawTx, err := btctx.GenerateTx(node.privKey, baseTx, inputs, outputs, feeRate)
+	if err != nil {
+		return "", "", err
+	}
+	tx := hex.EncodeToString(rawTx)
+	txid := btctx.CalculateTxID(tx)
+
+	return txid, tx, nil
+}
+
+func (node Node) BroadcastTx(tx string) (string, error) {
+	return node.sendRawTransaction(tx)
+}
+
+func (node Node) SignMessage(message string) (string, error) {
+	return btctx.SignMessage(node.privKey, message)
+}
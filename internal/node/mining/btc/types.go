@@ -0,0 +1,277 @@
+package btc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/goccy/go-json"
+
+	"github.com/magicpool-co/pool/internal/log"
+	"github.com/magicpool-co/pool/pkg/crypto"
+	"github.com/magicpool-co/pool/pkg/crypto/bech32"
+	"github.com/magicpool-co/pool/pkg/crypto/tx/btctx"
+	"github.com/magicpool-co/pool/pkg/hostpool"
+	"github.com/magicpool-co/pool/pkg/sshtunnel"
+	"github.com/magicpool-co/pool/pkg/stratum/rpc"
+	"github.com/magicpool-co/pool/types"
+)
+
+type BitcoinType int
+
+const (
+	BTC BitcoinType = iota
+	BCH
+)
+
+// general constants
+var (
+	mainnetPrefixP2PKH = []byte{0x00}
+	mainnetPrefixP2SH  = []byte{0x05}
+
+	testnetPrefixP2PKH = []byte{0x6f}
+	testnetPrefixP2SH  = []byte{0xc4}
+)
+
+func generateHost(
+	bitcoinType BitcoinType,
+	urls []string,
+	logger *log.Logger,
+	tunnel *sshtunnel.SSHTunnel,
+) (*hostpool.HTTPPool, error) {
+	var port int
+	switch bitcoinType {
+	case BTC:
+		port = 8332
+	case BCH:
+		port = 8432
+	}
+
+	var (
+		hostOptions = &hostpool.HTTPHostOptions{
+			Username: "rpc",
+			Password: "rpc",
+		}
+		hostHealthCheck = &hostpool.HTTPHealthCheck{
+			RPCRequest: &rpc.Request{
+				JSONRPC: "2.0",
+				Method:  "getbestblockhash",
+			},
+		}
+		hostRequestPolicy = &hostpool.RequestPolicy{
+			MethodTimeouts: map[string]time.Duration{
+				"getblocktemplate": time.Second * 5,
+				"submitblock":      time.Second * 30,
+			},
+		}
+	)
+
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	host := hostpool.NewHTTPPool(context.Background(), logger, hostHealthCheck, tunnel)
+	host.SetRequestPolicy(hostRequestPolicy)
+	for _, url := range urls {
+		err := host.AddHost(url, port, hostOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return host, nil
+}
+
+func New(
+	bitcoinType BitcoinType,
+	mainnet bool,
+	urls []string,
+	rawPriv string,
+	logger *log.Logger,
+	tunnel *sshtunnel.SSHTunnel,
+) (*Node, error) {
+	switch bitcoinType {
+	case BTC, BCH:
+	default:
+		return nil, fmt.Errorf("unknown bitcoin type")
+	}
+
+	prefixP2PKH := mainnetPrefixP2PKH
+	prefixP2SH := mainnetPrefixP2SH
+	cashAddrPrefix := mainnetCashAddrPrefix
+	if !mainnet {
+		prefixP2PKH = testnetPrefixP2PKH
+		prefixP2SH = testnetPrefixP2SH
+		cashAddrPrefix = testnetCashAddrPrefix
+	}
+
+	host, err := generateHost(bitcoinType, urls, logger, tunnel)
+	if err != nil {
+		return nil, err
+	}
+
+	obscuredPriv, err := crypto.ObscureHex(rawPriv)
+	if err != nil {
+		return nil, err
+	}
+
+	privKey := secp256k1.PrivKeyFromBytes(obscuredPriv)
+	address := btctx.PrivKeyToAddress(privKey, prefixP2PKH)
+	if bitcoinType == BCH {
+		pubKeyBytes := privKey.PubKey().SerializeUncompressed()
+		pubKeyHash := crypto.Ripemd160(crypto.Sha256(pubKeyBytes))
+		address, err = bech32.EncodeBCH(cashAddrCharset, cashAddrPrefix, cashAddrP2PKHID, pubKeyHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	node := &Node{
+		bitcoinType:    bitcoinType,
+		mocked:         host == nil,
+		mainnet:        mainnet,
+		prefixP2PKH:    prefixP2PKH,
+		prefixP2SH:     prefixP2SH,
+		cashAddrPrefix: cashAddrPrefix,
+		address:        address,
+		privKey:        privKey,
+		rpcHost:        host,
+		logger:         logger,
+	}
+
+	node.outputScript, err = node.addressToScript(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+type Node struct {
+	bitcoinType    BitcoinType
+	mocked         bool
+	mainnet        bool
+	prefixP2PKH    []byte
+	prefixP2SH     []byte
+	cashAddrPrefix string
+	address        string
+	outputScript   []byte
+	privKey        *secp256k1.PrivateKey
+	rpcHost        *hostpool.HTTPPool
+	coinbasePolicy *types.CoinbasePolicy
+	logger         *log.Logger
+}
+
+func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Request) {
+	if node.rpcHost == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(400)
+		w.Write([]byte(`{"status": 400, "error": "NoHostPool"}`))
+		return
+	}
+
+	node.rpcHost.HandleInfoRequest(w, r)
+}
+
+func (node *Node) SetRequestHandler(handler hostpool.RequestHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetRequestHandler(handler)
+	}
+}
+
+func (node *Node) SetBroadcastHandler(handler hostpool.BroadcastHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetBroadcastHandler(handler)
+	}
+}
+
+type BlockchainInfo struct {
+	Chain                string  `json:"chain"`
+	Blocks               uint64  `json:"blocks"`
+	Headers              uint64  `json:"headers"`
+	BestBlockHash        string  `json:"bestblockhash"`
+	Difficulty           float64 `json:"difficulty"`
+	MedianTime           int64   `json:"mediantime"`
+	VerificationProgress float64 `json:"verificationprogress"`
+	ChainWork            string  `json:"chainwork"`
+	Pruned               bool    `json:"pruned"`
+}
+
+type Transaction struct {
+	Data          string `json:"data"`
+	TxID          string `json:"txid"`
+	Hash          string `json:"hash"`
+	Fee           uint64 `json:"fee"`
+	SigOps        int    `json:"sigops"`
+	Weight        int    `json:"weight"`
+	Height        int64  `json:"height"`
+	BlockHash     string `json:"blockhash"`
+	Confirmations int64  `json:"confirmations"`
+	Inputs        []struct {
+		Coinbase string `json:"coinbase"`
+	} `json:"vin"`
+	Outputs []struct {
+		Value        json.Number `json:"value"`
+		ScriptPubKey struct {
+			Hex string `json:"hex"`
+		} `json:"scriptPubKey"`
+	} `json:"vout"`
+}
+
+type BlockTemplate struct {
+	Capabilities      []string       `json:"capabilities"`
+	Version           uint32         `json:"version"`
+	Rules             []string       `json:"rules"`
+	VBRequired        int            `json:"vbrequired"`
+	PreviousBlockHash string         `json:"previousblockhash"`
+	Transactions      []*Transaction `json:"transactions"`
+	CoinbaseAux       struct {
+		Flags string `json:"flags"`
+	} `json:"coinbaseaux"`
+	CoinbaseValue            uint64   `json:"coinbasevalue"`
+	LongPollID               string   `json:"longpollid"`
+	Target                   string   `json:"target"`
+	MinTime                  int      `json:"mintime"`
+	Mutable                  []string `json:"mutable"`
+	NonceRange               string   `json:"noncerange"`
+	SigOpLimit               int      `json:"sigoplimit"`
+	WeightLimit              int      `json:"weightlimit"`
+	CurTime                  uint32   `json:"curtime"`
+	Bits                     string   `json:"bits"`
+	Height                   uint64   `json:"height"`
+	DefaultWitnessCommitment string   `json:"default_witness_commitment"`
+}
+
+type Block struct {
+	Hash              string         `json:"hash"`
+	Confirmations     int64          `json:"confirmations"`
+	StrippedSize      uint64         `json:"strippedsize"`
+	Size              uint64         `json:"size"`
+	Weight            uint64         `json:"weight"`
+	Height            uint64         `json:"height"`
+	Version           uint64         `json:"version"`
+	VersionHex        string         `json:"versionHex"`
+	MerkleRoot        string         `json:"merkleroot"`
+	Transactions      []*Transaction `json:"tx"`
+	Time              int64          `json:"time"`
+	MedianTime        int64          `json:"mediantime"`
+	Nonce             uint64         `json:"nonce"`
+	Bits              string         `json:"bits"`
+	Difficulty        float64        `json:"difficulty"`
+	Chainwork         string         `json:"chainwork"`
+	PreviousBlockHash string         `json:"previousblockhash"`
+	NextBlockHash     string         `json:"nextblockhash"`
+}
+
+type SmartFee struct {
+	FeeRate float64  `json:"feerate"`
+	Errors  []string `json:"errors"`
+	Blocks  int      `json:"blocks"`
+}
+
+type UTXOSet struct {
+	Success     bool        `json:"success"`
+	TotalAmount json.Number `json:"total_amount"`
+}
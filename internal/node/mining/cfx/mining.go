@@ -75,6 +75,42 @@ func (node Node) PingHosts() ([]string, []uint64, []bool, []error) {
 	return hostIDs, heights, statuses, errs
 }
 
+// GetBlockHashes returns the pivot block hash for each epoch in the range,
+// which is the last hash returned by cfx_getBlocksByEpoch.
+func (node Node) GetBlockHashes(start, end uint64) ([]string, error) {
+	const batchSize = 125
+	if start > end {
+		return nil, fmt.Errorf("invalid range")
+	}
+
+	heights := make([]uint64, end-start+1)
+	for i := range heights {
+		heights[i] = start + uint64(i)
+	}
+
+	hashes := make([]string, len(heights))
+	for i := 0; i < len(heights); i += batchSize {
+		limit := i + batchSize
+		if len(heights) < limit {
+			limit = len(heights)
+		}
+
+		epochHashesList, err := node.getBlocksByEpochMany(heights[i:limit])
+		if err != nil {
+			return nil, err
+		}
+
+		for j, epochHashes := range epochHashesList {
+			if len(epochHashes) == 0 {
+				return nil, fmt.Errorf("empty epoch %d", heights[i+j])
+			}
+			hashes[i+j] = epochHashes[len(epochHashes)-1]
+		}
+	}
+
+	return hashes, nil
+}
+
 func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 	const batchSize = 125
 	if start > end {
@@ -361,11 +397,11 @@ func (node Node) GetSubscribeResponses(id []byte, clientID, extraNonce string) (
 	return nil, nil
 }
 
-func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
+func (node Node) GetAuthorizeResponses(clientType, diffFactor int) ([]interface{}, error) {
 	return nil, nil
 }
 
-func (node Node) GetSetDifficultyResponse(diffFactor int) (interface{}, error) {
+func (node Node) GetSetDifficultyResponse(clientType, diffFactor int) (interface{}, error) {
 	return nil, nil
 }
 
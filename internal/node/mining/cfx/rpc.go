@@ -394,26 +394,11 @@ func (node Node) miningSubscribe() chan *rpc.Request {
 	return node.tcpHost.Subscribe("mining.notify")
 }
 
-func (node Node) submitBlock(hostID, nonce, hash string) (bool, error) {
-	var res *rpc.Response
-	if node.mocked {
-		res = mock.SubmitBlock(hostID, nonce, hash)
-	} else {
-		req, err := rpc.NewRequestWithHostID(hostID, "mining.submit", "x", hash, nonce, hash)
-		if err != nil {
-			return false, err
-		}
-
-		res, err = node.tcpHost.Exec(req)
-		if err != nil {
-			return false, err
-		}
-	}
-
+func validateSubmitBlock(res *rpc.Response) error {
 	var output []interface{}
 	err := json.Unmarshal(res.Result, &output)
 	if err != nil {
-		return false, err
+		return err
 	}
 
 	var accepted bool
@@ -422,14 +407,39 @@ func (node Node) submitBlock(hostID, nonce, hash string) (bool, error) {
 	}
 
 	if accepted {
-		return true, nil
+		return nil
 	} else if len(output) > 1 {
 		msg, ok := output[1].(string)
 		if ok && strings.Index(msg, "Solution for a stale job!") != -1 {
-			return false, fmt.Errorf("stale share: not found")
+			return fmt.Errorf("stale share: not found")
 		}
-		return false, fmt.Errorf("block not accepted: %v", output[1])
+		return fmt.Errorf("block not accepted: %v", output[1])
+	}
+
+	return fmt.Errorf("block not accepted")
+}
+
+// submitBlock broadcasts the solution to every synced host and the host that created
+// the job in parallel, the first host to accept it wins. jobs are host specific, so
+// hosts that don't know the job reject it (which is only recorded).
+func (node Node) submitBlock(hostID, nonce, hash string) (bool, error) {
+	if node.mocked {
+		if err := validateSubmitBlock(mock.SubmitBlock(hostID, nonce, hash)); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	req, err := rpc.NewRequestWithHostID(hostID, "mining.submit", "x", hash, nonce, hash)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = node.tcpHost.ExecBroadcast(req, validateSubmitBlock)
+	if err != nil {
+		return false, err
 	}
 
-	return false, fmt.Errorf("block not accepted")
+	return true, nil
 }
@@ -106,3 +106,7 @@ func (node Node) CreateTx(inputs []*types.TxInput, outputs []*types.TxOutput) (s
 func (node Node) BroadcastTx(tx string) (string, error) {
 	return node.sendRawTransaction(tx)
 }
+
+func (node Node) SignMessage(message string) (string, error) {
+	return cfxtx.SignMessage(node.privKey, message)
+}
@@ -156,6 +156,21 @@ func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Reque
 	node.tcpHost.HandleInfoRequest(w, r)
 }
 
+func (node *Node) SetRequestHandler(handler hostpool.RequestHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetRequestHandler(handler)
+	}
+	if node.tcpHost != nil {
+		node.tcpHost.SetRequestHandler(handler)
+	}
+}
+
+func (node *Node) SetBroadcastHandler(handler hostpool.BroadcastHandler) {
+	if node.tcpHost != nil {
+		node.tcpHost.SetBroadcastHandler(handler)
+	}
+}
+
 func (node Node) execRPCfromFallback(req *rpc.Request, target interface{}) error {
 	res, err := rpc.ExecRPC(node.fallbackURL, req)
 	if err != nil {
@@ -30,6 +30,23 @@ func (node Node) GetBlockExplorerURL(round *pooldb.Round) string {
 	return ""
 }
 
+func (node Node) GetBlockHashes(start, end uint64) ([]string, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid range")
+	}
+
+	hashes := make([]string, 0, end-start+1)
+	for height := start; height <= end; height++ {
+		headers, err := node.getBlocksAtHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, headers[0])
+	}
+
+	return hashes, nil
+}
+
 func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 	if start > end {
 		return nil, fmt.Errorf("invalid range")
@@ -245,6 +262,10 @@ func (node Node) SubmitWork(
 		return types.AcceptedShare, hash, nil, err
 	}
 
+	if err := node.relayBlock(job.HostID, job.Height.Value(), work.Nonce.Hex()); err != nil {
+		node.logger.Error(fmt.Errorf("relay block: %v", err))
+	}
+
 	round := &pooldb.Round{
 		ChainID:    node.Chain(),
 		Height:     job.Height.Value(),
@@ -325,8 +346,8 @@ func (node Node) GetSubscribeResponses(id []byte, clientID, extraNonce string) (
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
-	res, err := node.GetSetDifficultyResponse(diffFactor)
+func (node Node) GetAuthorizeResponses(clientType, diffFactor int) ([]interface{}, error) {
+	res, err := node.GetSetDifficultyResponse(clientType, diffFactor)
 	if err != nil {
 		return nil, err
 	}
@@ -334,7 +355,7 @@ func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetSetDifficultyResponse(diffFactor int) (interface{}, error) {
+func (node Node) GetSetDifficultyResponse(clientType, diffFactor int) (interface{}, error) {
 	return rpc.NewRequest("mining.set_difficulty", diffFactor)
 }
 
@@ -3,6 +3,7 @@ package erg
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/goccy/go-json"
 
@@ -152,6 +153,45 @@ func (node Node) postMiningSolution(hostID, nonce string) error {
 	return nil
 }
 
+// relayBlock fetches the full block with the given nonce at height from the host that
+// accepted the mining solution and relays it to every other synced host. mining
+// candidates are specific to the host that created them, so the solution itself
+// can't be broadcast.
+func (node Node) relayBlock(hostID string, height uint64, nonce string) error {
+	if node.mocked {
+		return nil
+	}
+
+	var headers []string
+	_, err := node.httpHost.ExecHTTPSticky(hostID, "GET", "/blocks/at/"+strconv.FormatUint(height, 10), nil, &headers)
+	if err != nil {
+		return err
+	}
+
+	for _, header := range headers {
+		var rawBlock json.RawMessage
+		_, err := node.httpHost.ExecHTTPSticky(hostID, "GET", "/blocks/"+header, nil, &rawBlock)
+		if err != nil {
+			return err
+		}
+
+		var block *Block
+		if err := json.Unmarshal(rawBlock, &block); err != nil {
+			return err
+		} else if block.Header == nil || block.Header.PowSolutions == nil {
+			continue
+		} else if !strings.EqualFold(block.Header.PowSolutions.N, nonce) {
+			continue
+		}
+
+		node.httpHost.RelayHTTP("POST", "/blocks", rawBlock, hostID)
+
+		return nil
+	}
+
+	return fmt.Errorf("block with nonce %s not found at height %d", nonce, height)
+}
+
 func (node Node) getWalletStatus(hostID string) (*WalletStatus, error) {
 	var status *WalletStatus
 	var err error
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/brianium/mnemonic"
 	"github.com/goccy/go-json"
@@ -33,6 +34,12 @@ func generateHost(
 			HTTPMethod: "GET",
 			HTTPPath:   "/info",
 		}
+		hostRequestPolicy = &hostpool.RequestPolicy{
+			MethodTimeouts: map[string]time.Duration{
+				"/mining/candidate": time.Second * 5,
+				"/mining/solution":  time.Second * 30,
+			},
+		}
 	)
 
 	if len(urls) == 0 {
@@ -40,6 +47,7 @@ func generateHost(
 	}
 
 	host := hostpool.NewHTTPPool(context.Background(), logger, hostHealthCheck, tunnel)
+	host.SetRequestPolicy(hostRequestPolicy)
 	for _, url := range urls {
 		err := host.AddHost(url, port, hostOptions)
 		if err != nil {
@@ -143,6 +151,18 @@ func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Reque
 	node.httpHost.HandleInfoRequest(w, r)
 }
 
+func (node *Node) SetRequestHandler(handler hostpool.RequestHandler) {
+	if node.httpHost != nil {
+		node.httpHost.SetRequestHandler(handler)
+	}
+}
+
+func (node *Node) SetBroadcastHandler(handler hostpool.BroadcastHandler) {
+	if node.httpHost != nil {
+		node.httpHost.SetBroadcastHandler(handler)
+	}
+}
+
 type NodeInfo struct {
 	Name          string `json:"name"`
 	AppVersion    string `json:"appVersion"`
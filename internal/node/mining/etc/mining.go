@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"regexp"
 	"strings"
 	"time"
 
@@ -18,6 +19,21 @@ import (
 	"github.com/magicpool-co/pool/types"
 )
 
+const (
+	ethProxyClientID   = 0
+	ethStratumClientID = 1
+)
+
+var (
+	isEthStratum = regexp.MustCompile("(?i).*EthereumStratum/1\\.0\\.0.*")
+
+	// EthereumStratum/1.0.0 difficulty 1 is a target of 0x00000000ffff0000...
+	ethStratumBaseDiff = float64(new(types.Difficulty).SetFromBig(
+		common.MustParseBigHex("00000000ffff0000000000000000000000000000000000000000000000000000"),
+		maxDiffBig,
+	).Value())
+)
+
 func stringsEqualInsensitive(a, b string) bool {
 	return strings.ToLower(a) == strings.ToLower(b)
 }
@@ -61,6 +77,34 @@ func (node Node) PingHosts() ([]string, []uint64, []bool, []error) {
 	return hostIDs, heights, statuses, errs
 }
 
+func (node Node) GetBlockHashes(start, end uint64) ([]string, error) {
+	const batchSize = 25
+	if start > end {
+		return nil, fmt.Errorf("invalid range")
+	}
+
+	hashes := make([]string, 0, end-start+1)
+	for height := start; height <= end; height += batchSize {
+		limit := height + batchSize - 1
+		if limit > end {
+			limit = end
+		}
+
+		heights := make([]uint64, limit-height+1)
+		for i := range heights {
+			heights[i] = height + uint64(i)
+		}
+
+		batchHashes, err := node.getBlockHashMany(heights)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, batchHashes...)
+	}
+
+	return hashes, nil
+}
+
 func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 	const batchSize = 5
 	if start > end {
@@ -101,7 +145,7 @@ func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 				return nil, err
 			}
 
-			blockReward, err := node.calculateBlockReward(height, block)
+			blockReward, _, err := node.calculateBlockReward(height, block)
 			if err != nil {
 				return nil, err
 			}
@@ -201,11 +245,19 @@ func (node Node) SubmitWork(
 	work *types.StratumWork,
 	diffFactor int,
 ) (types.ShareStatus, *types.Hash, *pooldb.Round, error) {
+	// EthereumStratum/1.0.0 submissions only contain the nonce, so the
+	// header hash comes from the job and the mix digest is recomputed
+	if work.Hash == nil {
+		work.Hash = job.Header
+	}
+
 	mixDigest, digest, err := node.pow.Compute(work.Hash.Bytes(), job.Height.Value(), work.Nonce.Value())
 	if err != nil {
 		return types.InvalidShare, nil, nil, err
 	} else if job.Header.Hex() != work.Hash.Hex() {
 		return types.InvalidShare, nil, nil, nil
+	} else if work.MixDigest == nil {
+		work.MixDigest = new(types.Hash).SetFromBytes(mixDigest)
 	} else if bytes.Compare(mixDigest, work.MixDigest.Bytes()) != 0 {
 		return types.InvalidShare, nil, nil, nil
 	}
@@ -241,7 +293,47 @@ func (node Node) SubmitWork(
 	return types.AcceptedShare, hash, round, nil
 }
 
+// parseStratumWork parses an EthereumStratum/1.0.0 submission, which is
+// the worker, the job ID and the nonce without the extranonce prefix.
+func parseStratumWork(data []json.RawMessage, extraNonce string) (*types.StratumWork, error) {
+	if len(data) != 3 {
+		return nil, fmt.Errorf("incorrect work array length")
+	}
+
+	var worker, jobID, nonceSuffix string
+	if err := json.Unmarshal(data[0], &worker); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(data[1], &jobID); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(data[2], &nonceSuffix); err != nil {
+		return nil, fmt.Errorf("invalid nonce parameter")
+	}
+
+	nonce := extraNonce + strings.TrimPrefix(nonceSuffix, "0x")
+	if len(nonce) != 16 {
+		return nil, fmt.Errorf("invalid nonce parameter")
+	}
+
+	nonceVal, err := new(types.Number).SetFromHex(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	work := &types.StratumWork{
+		WorkerID: worker,
+		JobID:    "0x" + strings.TrimPrefix(jobID, "0x"),
+		Nonce:    nonceVal,
+	}
+
+	return work, nil
+}
+
 func (node Node) ParseWork(data []json.RawMessage, extraNonce string) (*types.StratumWork, error) {
+	// only EthereumStratum/1.0.0 clients subscribe and receive an extranonce
+	if extraNonce != "" {
+		return parseStratumWork(data, extraNonce)
+	}
+
 	if len(data) != 3 {
 		return nil, fmt.Errorf("incorrect work array length")
 	}
@@ -282,6 +374,11 @@ func (node Node) MarshalJob(
 	cleanJobs bool,
 	clientType, diffFactor int,
 ) (interface{}, error) {
+	if clientType == ethStratumClientID {
+		return rpc.NewRequestWithID(rawID, "mining.notify",
+			job.Header.Hex(), job.Seed.Hex(), job.Header.Hex(), cleanJobs) // no 0x prefix
+	}
+
 	id, err := json.Marshal(rawID)
 	if err != nil {
 		return nil, err
@@ -298,22 +395,53 @@ func (node Node) MarshalJob(
 }
 
 func (node Node) GetClientType(minerClient string) int {
-	return 0
+	if isEthStratum.MatchString(minerClient) {
+		return ethStratumClientID
+	}
+
+	return ethProxyClientID
 }
 
 func (node Node) GetSubscribeResponses(id []byte, clientID, extraNonce string) ([]interface{}, error) {
-	return nil, nil
+	result := []interface{}{
+		[]interface{}{"mining.notify", clientID, "EthereumStratum/1.0.0"},
+		extraNonce,
+	}
+
+	res, err := rpc.NewResponse(id, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{res}, nil
 }
 
-func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
-	return nil, nil
+func (node Node) GetAuthorizeResponses(clientType, diffFactor int) ([]interface{}, error) {
+	if clientType != ethStratumClientID {
+		return nil, nil
+	}
+
+	res, err := node.GetSetDifficultyResponse(clientType, diffFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{res}, nil
 }
 
-func (node Node) GetSetDifficultyResponse(diffFactor int) (interface{}, error) {
-	return nil, nil
+func (node Node) GetSetDifficultyResponse(clientType, diffFactor int) (interface{}, error) {
+	if clientType != ethStratumClientID {
+		return nil, nil
+	}
+
+	diff := float64(node.GetShareDifficulty(diffFactor).Value()) / ethStratumBaseDiff
+
+	return rpc.NewRequest("mining.set_difficulty", diff)
 }
 
-func (node Node) calculateBlockReward(height uint64, block *Block) (*big.Int, error) {
+// calculateBlockReward returns the full reward of the block (including transaction fees)
+// along with the part of it that comes from transaction fees.
+func (node Node) calculateBlockReward(height uint64, block *Block) (*big.Int, *big.Int, error) {
 	var blockReward *big.Int
 	switch node.ethType {
 	case ETC:
@@ -329,7 +457,7 @@ func (node Node) calculateBlockReward(height uint64, block *Block) (*big.Int, er
 
 	receipts, err := node.getTransactionReceiptMany(txids)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	txFees := new(big.Int)
@@ -340,14 +468,14 @@ func (node Node) calculateBlockReward(height uint64, block *Block) (*big.Int, er
 
 		gasUsed, err := common.HexToBig(receipts[i].GasUsed)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		var gasPrice *big.Int
 		if receipts[i].EffectiveGasPrice != "" {
 			gasPrice, err = common.HexToBig(receipts[i].EffectiveGasPrice)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		} else {
 			var ok bool
@@ -355,7 +483,7 @@ func (node Node) calculateBlockReward(height uint64, block *Block) (*big.Int, er
 			// @NOTE: it actually works, lets figure out why SetString works w/ leading 0x
 			gasPrice, ok = new(big.Int).SetString(tx.GasPrice, 0)
 			if !ok {
-				return nil, fmt.Errorf("unable to parse gasPrice for tx %s", tx.Hash)
+				return nil, nil, fmt.Errorf("unable to parse gasPrice for tx %s", tx.Hash)
 			}
 		}
 
@@ -366,12 +494,12 @@ func (node Node) calculateBlockReward(height uint64, block *Block) (*big.Int, er
 	if block.BaseFee != "" {
 		baseFeePerGas, err := common.HexToBig(block.BaseFee)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		gasUsed, err := common.HexToBig(block.GasUsed)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		txFees.Sub(txFees, new(big.Int).Mul(baseFeePerGas, gasUsed))
@@ -379,7 +507,7 @@ func (node Node) calculateBlockReward(height uint64, block *Block) (*big.Int, er
 
 	blockReward.Add(blockReward, txFees)
 
-	return blockReward, nil
+	return blockReward, txFees, nil
 }
 
 func (node Node) UnlockRound(round *pooldb.Round) error {
@@ -404,7 +532,7 @@ func (node Node) UnlockRound(round *pooldb.Round) error {
 			if err != nil {
 				return err
 			} else if nonce == types.Uint64Value(round.Nonce) {
-				blockReward, err := node.calculateBlockReward(checkHeight, block)
+				blockReward, txFees, err := node.calculateBlockReward(checkHeight, block)
 				if err != nil {
 					return err
 				}
@@ -416,6 +544,7 @@ func (node Node) UnlockRound(round *pooldb.Round) error {
 
 				round.Height = checkHeight
 				round.Value = dbcl.NullBigInt{Valid: true, BigInt: blockReward}
+				round.TxFees = dbcl.NullBigInt{Valid: true, BigInt: txFees}
 				round.Hash = block.Hash
 				round.Orphan = false
 				round.CreatedAt = time.Unix(int64(rawTimestamp), 0)
@@ -448,6 +577,7 @@ func (node Node) UnlockRound(round *pooldb.Round) error {
 					}
 
 					round.Value = dbcl.NullBigInt{Valid: true, BigInt: uncleReward}
+					round.TxFees = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)}
 					round.Hash = uncle.Hash
 					round.UncleHeight = types.Uint64Ptr(checkHeight)
 					round.Orphan = false
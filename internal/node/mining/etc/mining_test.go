@@ -0,0 +1,254 @@
+package etc
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+
+	"github.com/magicpool-co/pool/pkg/common"
+	"github.com/magicpool-co/pool/pkg/stratum/rpc"
+	"github.com/magicpool-co/pool/types"
+)
+
+func mustMarshalParams(params ...interface{}) []json.RawMessage {
+	data := make([]json.RawMessage, len(params))
+	for i, param := range params {
+		data[i] = common.MustMarshalJSON(param)
+	}
+
+	return data
+}
+
+func mustParseHash(value string) *types.Hash {
+	hash, err := new(types.Hash).SetFromHex(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return hash
+}
+
+func TestGetClientType(t *testing.T) {
+	tests := []struct {
+		client     string
+		clientType int
+	}{
+		{"", ethProxyClientID},
+		{"lolMiner 1.76", ethProxyClientID},
+		{"Claymore/15.0", ethProxyClientID},
+		{"ethminer/0.19.0 EthereumStratum/1.0.0", ethStratumClientID},
+		{"NBMiner/42.3 ethereumstratum/1.0.0", ethStratumClientID},
+		{"EthereumStratum/2.0.0", ethProxyClientID},
+	}
+
+	node := Node{ethType: ETC}
+	for i, tt := range tests {
+		clientType := node.GetClientType(tt.client)
+		if clientType != tt.clientType {
+			t.Errorf("failed on %d: client type mismatch: have %d, want %d", i, clientType, tt.clientType)
+		}
+	}
+}
+
+func TestParseStratumWork(t *testing.T) {
+	tests := []struct {
+		data       []json.RawMessage
+		extraNonce string
+		work       *types.StratumWork
+		err        bool
+	}{
+		{
+			data:       mustMarshalParams("0x0000000000000000000000000000000000000000.worker", "48b9e256", "25a6eeb65f92"),
+			extraNonce: "ffff",
+			work: &types.StratumWork{
+				WorkerID: "0x0000000000000000000000000000000000000000.worker",
+				JobID:    "0x48b9e256",
+				Nonce:    new(types.Number).SetFromValue(0xffff25a6eeb65f92),
+			},
+		},
+		{
+			data:       mustMarshalParams("worker", "0x48b9e256", "0x6eeb65f927"),
+			extraNonce: "ff25a6",
+			work: &types.StratumWork{
+				WorkerID: "worker",
+				JobID:    "0x48b9e256",
+				Nonce:    new(types.Number).SetFromValue(0xff25a66eeb65f927),
+			},
+		},
+		{
+			// the nonce does not fill the remaining bytes
+			data:       mustMarshalParams("worker", "48b9e256", "25a6eeb6"),
+			extraNonce: "ffff",
+			err:        true,
+		},
+		{
+			data:       mustMarshalParams("worker", "48b9e256", 123456),
+			extraNonce: "ffff",
+			err:        true,
+		},
+		{
+			data:       mustMarshalParams("worker", "48b9e256"),
+			extraNonce: "ffff",
+			err:        true,
+		},
+	}
+
+	for i, tt := range tests {
+		work, err := parseStratumWork(tt.data, tt.extraNonce)
+		if tt.err {
+			if err == nil {
+				t.Errorf("failed on %d: expected error", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		}
+
+		if work.WorkerID != tt.work.WorkerID {
+			t.Errorf("failed on %d: worker mismatch: have %s, want %s", i, work.WorkerID, tt.work.WorkerID)
+		} else if work.JobID != tt.work.JobID {
+			t.Errorf("failed on %d: job id mismatch: have %s, want %s", i, work.JobID, tt.work.JobID)
+		} else if work.Nonce.Value() != tt.work.Nonce.Value() {
+			t.Errorf("failed on %d: nonce mismatch: have %x, want %x", i, work.Nonce.Value(), tt.work.Nonce.Value())
+		} else if work.Hash != nil || work.MixDigest != nil {
+			t.Errorf("failed on %d: expected no hash or mix digest", i)
+		}
+	}
+}
+
+func TestParseWork(t *testing.T) {
+	node := Node{ethType: ETC}
+
+	// without an extranonce the work is parsed as eth_submitWork
+	proxyData := mustMarshalParams(
+		"0x25a6eeb65f927295",
+		"0x48b9e2560c8263614076d943d2c848044604b6e43c2423ed670ea5cc18b6edd8",
+		"0x645fda1ed38a9884029f0533a68363447f7dc62916e19ea42a1259a44ce3017b",
+	)
+
+	work, err := node.ParseWork(proxyData, "")
+	if err != nil {
+		t.Fatalf("failed to parse proxy work: %v", err)
+	} else if work.Hash == nil || work.MixDigest == nil || work.Nonce.Value() != 0x25a6eeb65f927295 {
+		t.Errorf("proxy work mismatch: have %+v", work)
+	}
+
+	// with an extranonce the work is parsed as mining.submit
+	if _, err := node.ParseWork(proxyData, "ffff"); err == nil {
+		t.Errorf("expected error parsing proxy work as stratum work")
+	}
+
+	stratumData := mustMarshalParams("worker", "48b9e256", "25a6eeb65f92")
+	work, err = node.ParseWork(stratumData, "ffff")
+	if err != nil {
+		t.Fatalf("failed to parse stratum work: %v", err)
+	} else if work.Hash != nil || work.Nonce.Value() != 0xffff25a6eeb65f92 {
+		t.Errorf("stratum work mismatch: have %+v", work)
+	}
+}
+
+func TestStratumMessages(t *testing.T) {
+	node := Node{ethType: ETC}
+	job := &types.StratumJob{
+		Header: mustParseHash("0x48b9e2560c8263614076d943d2c848044604b6e43c2423ed670ea5cc18b6edd8"),
+		Seed:   mustParseHash("0xb883d93ea88f16bb9d1318c55fb480d7df858b6dbb77c26804cb7a51030050b8"),
+		Height: new(types.Number).SetFromValue(0xeba1d6),
+	}
+
+	// EthereumStratum/1.0.0 jobs are sent as mining.notify without 0x prefixes
+	msg, err := node.MarshalJob(0, job, true, ethStratumClientID, 1)
+	if err != nil {
+		t.Fatalf("failed to marshal stratum job: %v", err)
+	}
+
+	req, ok := msg.(*rpc.Request)
+	if !ok {
+		t.Fatalf("stratum job type mismatch: have %T", msg)
+	} else if req.Method != "mining.notify" {
+		t.Errorf("stratum job method mismatch: have %s, want mining.notify", req.Method)
+	}
+
+	params := mustMarshalParams(job.Header.Hex(), job.Seed.Hex(), job.Header.Hex(), true)
+	if string(common.MustMarshalJSON(req.Params)) != string(common.MustMarshalJSON(params)) {
+		t.Errorf("stratum job params mismatch: have %s, want %s", req.Params, params)
+	}
+
+	// proxy jobs are sent as a response with the share target
+	msg, err = node.MarshalJob(0, job, true, ethProxyClientID, 1)
+	if err != nil {
+		t.Fatalf("failed to marshal proxy job: %v", err)
+	} else if res, ok := msg.(*rpc.Response); !ok {
+		t.Errorf("proxy job type mismatch: have %T", msg)
+	} else {
+		result := common.MustMarshalJSON([]interface{}{
+			job.Header.PrefixedHex(),
+			job.Seed.PrefixedHex(),
+			shareDiff.TargetPrefixedHex(),
+			job.Height.PrefixedHex(),
+		})
+		if string(res.Result) != string(result) {
+			t.Errorf("proxy job result mismatch: have %s, want %s", res.Result, result)
+		}
+	}
+
+	// only EthereumStratum/1.0.0 clients receive mining.set_difficulty, once after
+	// authorizing and on every difficulty change (relative to a target of 0x00000000ffff)
+	responses, err := node.GetAuthorizeResponses(ethProxyClientID, 1)
+	if err != nil {
+		t.Fatalf("failed to get proxy authorize responses: %v", err)
+	} else if len(responses) != 0 {
+		t.Errorf("proxy authorize response length mismatch: have %d, want 0", len(responses))
+	}
+
+	responses, err = node.GetAuthorizeResponses(ethStratumClientID, 1)
+	if err != nil {
+		t.Fatalf("failed to get stratum authorize responses: %v", err)
+	} else if len(responses) != 1 {
+		t.Fatalf("stratum authorize response length mismatch: have %d, want 1", len(responses))
+	}
+
+	for i, diffFactor := range []int{1, 4} {
+		msg, err := node.GetSetDifficultyResponse(ethStratumClientID, diffFactor)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		}
+
+		req, ok := msg.(*rpc.Request)
+		if !ok {
+			t.Errorf("failed on %d: set difficulty type mismatch: have %T", i, msg)
+			continue
+		} else if req.Method != "mining.set_difficulty" || len(req.Params) != 1 {
+			t.Errorf("failed on %d: set difficulty mismatch: have %s %s", i, req.Method, req.Params)
+			continue
+		}
+
+		var diff float64
+		if err := json.Unmarshal(req.Params[0], &diff); err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if want := float64(node.GetShareDifficulty(diffFactor).Value()) / ethStratumBaseDiff; diff != want {
+			t.Errorf("failed on %d: difficulty mismatch: have %f, want %f", i, diff, want)
+		} else if diff < 2*float64(diffFactor) || diff > 2.1*float64(diffFactor) {
+			t.Errorf("failed on %d: difficulty out of range: have %f", i, diff)
+		}
+	}
+
+	if msg, err := node.GetSetDifficultyResponse(ethProxyClientID, 1); err != nil || msg != nil {
+		t.Errorf("proxy set difficulty mismatch: have %v (%v)", msg, err)
+	}
+
+	// the subscribe response carries the extranonce
+	responses, err = node.GetSubscribeResponses([]byte("1"), "a1", "ffff")
+	if err != nil {
+		t.Fatalf("failed to get subscribe responses: %v", err)
+	} else if len(responses) != 1 {
+		t.Fatalf("subscribe response length mismatch: have %d, want 1", len(responses))
+	}
+
+	res := responses[0].(*rpc.Response)
+	result := `[["mining.notify","a1","EthereumStratum/1.0.0"],"ffff"]`
+	if string(res.Result) != result {
+		t.Errorf("subscribe result mismatch: have %s, want %s", res.Result, result)
+	}
+}
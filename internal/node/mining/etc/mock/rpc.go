@@ -18,15 +18,15 @@ func GetBalance(address string) *rpc.Response {
 }
 
 func GetChainID() *rpc.Response {
-	return nil
+	return rpc.NewResponseFromJSON(nil, []byte(`"0x3d"`))
 }
 
 func GetGasPrice() *rpc.Response {
-	return nil
+	return rpc.NewResponseFromJSON(nil, []byte(`"0x3b9aca00"`))
 }
 
 func GetPendingNonce(address string) *rpc.Response {
-	return nil
+	return rpc.NewResponseFromJSON(nil, []byte(`"0x2a"`))
 }
 
 func GetBlockNumber() *rpc.Response {
@@ -53,6 +53,14 @@ func SendEstimateGas(from, to string) *rpc.Response {
 	return nil
 }
 
+func GetCode(address string) *rpc.Response {
+	return rpc.NewResponseFromJSON(nil, []byte(`"0x"`))
+}
+
+func SendEstimateGasWithData(from, to, value, data string) *rpc.Response {
+	return rpc.NewResponseFromJSON(nil, []byte(`"0x186a0"`))
+}
+
 func SendSubmitWork(nonce, hash, mixDigest string) *rpc.Response {
 	return rpc.NewResponseFromJSON(nil, common.JsonTrue)
 }
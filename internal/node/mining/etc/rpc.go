@@ -105,6 +105,46 @@ func (node Node) getBlockByNumberMany(heights []uint64) ([]*Block, error) {
 	return blocks, nil
 }
 
+func (node Node) getBlockHashMany(heights []uint64) ([]string, error) {
+	var responses []*rpc.Response
+	if node.mocked {
+		responses = mock.GetBlockByNumberMany(heights)
+	} else {
+		reqs := make([]*rpc.Request, len(heights))
+		var err error
+		for i, height := range heights {
+			// only the header is needed, so skip the transaction bodies
+			reqs[i], err = rpc.NewRequestWithID(i, "eth_getBlockByNumber", common.Uint64ToHex(height), false)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		responses, err = node.rpcHost.ExecRPCBulk(reqs)
+		if err != nil {
+			return nil, err
+		} else if len(responses) != len(reqs) {
+			return nil, fmt.Errorf("request and response length mismatch: %d and %d", len(responses), len(reqs))
+		}
+	}
+
+	hashes := make([]string, len(responses))
+	for i, res := range responses {
+		var header struct {
+			Hash string `json:"hash"`
+		}
+		err := json.Unmarshal(res.Result, &header)
+		if err != nil {
+			return nil, err
+		} else if header.Hash == "" {
+			return nil, fmt.Errorf("block %d not found", heights[i])
+		}
+		hashes[i] = header.Hash
+	}
+
+	return hashes, nil
+}
+
 func (node Node) getBalance(address string) (*big.Int, error) {
 	var res *rpc.Response
 	var err error
@@ -325,6 +365,62 @@ func (node Node) sendEstimateGas(from, to string) (uint64, error) {
 	return common.HexToUint64(hexEstimate)
 }
 
+func (node Node) sendEstimateGasWithData(from, to, value, data string) (uint64, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.SendEstimateGasWithData(from, to, value, data)
+	} else {
+		tx := map[string]interface{}{"from": from, "to": to, "value": value, "data": data}
+		res, err = node.rpcHost.ExecRPCFromArgsSynced("eth_estimateGas", tx)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var hexEstimate string
+	err = json.Unmarshal(res.Result, &hexEstimate)
+	if err != nil {
+		return 0, err
+	}
+
+	return common.HexToUint64(hexEstimate)
+}
+
+func (node Node) getCode(address string) (string, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.GetCode(address)
+	} else {
+		res, err = node.rpcHost.ExecRPCFromArgsSynced("eth_getCode", address, "latest")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var code string
+	if err := json.Unmarshal(res.Result, &code); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+func validateSubmitWork(res *rpc.Response) error {
+	var accepted bool
+	if err := json.Unmarshal(res.Result, &accepted); err != nil {
+		return err
+	} else if !accepted {
+		return fmt.Errorf("work not accepted")
+	}
+
+	return nil
+}
+
+// sendSubmitWork broadcasts the work to every synced host and the host that created
+// the work in parallel, the first host to accept it wins. work packages are host
+// specific, so hosts that don't know the work reject it (which is only recorded).
 func (node Node) sendSubmitWork(hostID, nonce, hash, mixDigest string) (bool, error) {
 	var res *rpc.Response
 	if node.mocked {
@@ -335,7 +431,7 @@ func (node Node) sendSubmitWork(hostID, nonce, hash, mixDigest string) (bool, er
 			return false, err
 		}
 
-		res, err = node.rpcHost.ExecRPC(req)
+		res, err = node.rpcHost.ExecRPCBroadcast(req, validateSubmitWork)
 		if err != nil {
 			return false, err
 		}
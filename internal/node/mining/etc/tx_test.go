@@ -0,0 +1,147 @@
+package etc
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/magicpool-co/pool/types"
+)
+
+const disperseABI = `[{"name":"disperseEther","type":"function","inputs":[` +
+	`{"name":"recipients","type":"address[]"},{"name":"values","type":"uint256[]"}]}]`
+
+func TestCreateBatchTx(t *testing.T) {
+	privBytes, err := hex.DecodeString("ab3f4f0d4b1a5b8ad9b8f4d5e0e7d9a9a44ff0b7f3c9cf86a0f5b9a1c6a3e2d1")
+	if err != nil {
+		t.Fatalf("failed to decode priv key: %v", err)
+	}
+
+	disperse, err := abi.JSON(strings.NewReader(disperseABI))
+	if err != nil {
+		t.Fatalf("failed to parse abi: %v", err)
+	}
+
+	const disperseAddress = "0xD152f549545093347A162Dce210e7293f1452150"
+	node := &Node{
+		ethType: ETC,
+		mocked:  true,
+		address: "0xae8c89152d34206b5bbaaebee2a50e163466f73d",
+		privKey: secp256k1.PrivKeyFromBytes(privBytes),
+	}
+
+	if _, _, err := node.CreateTx([]*types.TxInput{{Value: big.NewInt(3)}},
+		[]*types.TxOutput{{Value: big.NewInt(1)}, {Value: big.NewInt(2)}}); err == nil {
+		t.Errorf("expected error without a disperse address")
+	}
+
+	if err := node.SetDisperseAddress(disperseAddress); err != nil {
+		t.Fatalf("failed to set disperse address: %v", err)
+	}
+
+	tests := []struct {
+		addresses []string
+		values    []uint64
+	}{
+		{
+			addresses: []string{
+				"0x8ba1f109551bd432803012645ac136ddd64dba72",
+				"0x0000000000000000000000000000000000000001",
+			},
+			values: []uint64{1_000_000_000_000_000_000, 250_000_000_000_000_000},
+		},
+		{
+			addresses: []string{
+				"0x8ba1f109551bd432803012645ac136ddd64dba72",
+				"0x0000000000000000000000000000000000000001",
+				"0x0000000000000000000000000000000000000002",
+			},
+			values: []uint64{333_333_333_333_333_333, 10_000_000_000_000_001, 7_777_777_777_777_777},
+		},
+	}
+
+	for i, tt := range tests {
+		inputSum := new(big.Int)
+		outputs := make([]*types.TxOutput, len(tt.values))
+		for j, value := range tt.values {
+			outputs[j] = &types.TxOutput{
+				Address: tt.addresses[j],
+				Value:   new(big.Int).SetUint64(value),
+			}
+			inputSum.Add(inputSum, outputs[j].Value)
+		}
+
+		_, txHex, err := node.CreateTx([]*types.TxInput{{Value: inputSum}}, outputs)
+		if err != nil {
+			t.Errorf("failed on %d: create tx: %v", i, err)
+			continue
+		}
+
+		txBytes, err := hex.DecodeString(strings.TrimPrefix(txHex, "0x"))
+		if err != nil {
+			t.Errorf("failed on %d: decode tx hex: %v", i, err)
+			continue
+		}
+
+		tx := new(ethTypes.Transaction)
+		if err := tx.UnmarshalBinary(txBytes); err != nil {
+			t.Errorf("failed on %d: unmarshal tx: %v", i, err)
+			continue
+		} else if tx.To() == nil || *tx.To() != ethCommon.HexToAddress(disperseAddress) {
+			t.Errorf("failed on %d: to mismatch: have %v, want %s", i, tx.To(), disperseAddress)
+		}
+
+		method, err := disperse.MethodById(tx.Data()[:4])
+		if err != nil {
+			t.Errorf("failed on %d: method: %v", i, err)
+			continue
+		}
+
+		args, err := method.Inputs.Unpack(tx.Data()[4:])
+		if err != nil {
+			t.Errorf("failed on %d: unpack calldata: %v", i, err)
+			continue
+		}
+		recipients := args[0].([]ethCommon.Address)
+		values := args[1].([]*big.Int)
+
+		if len(recipients) != len(outputs) || len(values) != len(outputs) {
+			t.Errorf("failed on %d: calldata length mismatch: have %d and %d, want %d",
+				i, len(recipients), len(values), len(outputs))
+			continue
+		}
+
+		// the calldata has to pay the outputs after the fee split, which
+		// add up to the tx value and, with the fees, to the input
+		valueSum, feeSum := new(big.Int), new(big.Int)
+		for j, output := range outputs {
+			if recipients[j] != ethCommon.HexToAddress(output.Address) {
+				t.Errorf("failed on %d: output %d: recipient mismatch: have %s, want %s",
+					i, j, recipients[j].Hex(), output.Address)
+			} else if values[j].Cmp(output.Value) != 0 {
+				t.Errorf("failed on %d: output %d: value mismatch: have %s, want %s",
+					i, j, values[j], output.Value)
+			} else if output.Fee == nil || output.Fee.Sign() <= 0 {
+				t.Errorf("failed on %d: output %d: no fee", i, j)
+				continue
+			}
+
+			valueSum.Add(valueSum, values[j])
+			feeSum.Add(feeSum, output.Fee)
+		}
+
+		if tx.Value().Cmp(valueSum) != 0 {
+			t.Errorf("failed on %d: tx value mismatch: have %s, want %s", i, tx.Value(), valueSum)
+		} else if total := new(big.Int).Add(valueSum, feeSum); total.Cmp(inputSum) != 0 {
+			t.Errorf("failed on %d: total mismatch: have %s, want %s", i, total, inputSum)
+		} else if fee := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas())); fee.Cmp(feeSum) != 0 {
+			t.Errorf("failed on %d: fee mismatch: have %s, want %s", i, feeSum, fee)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/sencha-dev/powkit/ethash"
@@ -44,6 +45,12 @@ func generateHost(
 				Method:  "eth_syncing",
 			},
 		}
+		hostRequestPolicy = &hostpool.RequestPolicy{
+			MethodTimeouts: map[string]time.Duration{
+				"eth_getWork":    time.Second * 5,
+				"eth_submitWork": time.Second * 30,
+			},
+		}
 	)
 
 	if len(urls) == 0 {
@@ -51,6 +58,7 @@ func generateHost(
 	}
 
 	host := hostpool.NewHTTPPool(context.Background(), logger, hostHealthCheck, tunnel)
+	host.SetRequestPolicy(hostRequestPolicy)
 	for _, url := range urls {
 		err := host.AddHost(url, port, nil)
 		if err != nil {
@@ -112,6 +120,8 @@ type Node struct {
 	rpcHost *hostpool.HTTPPool
 	pow     *ethash.Client
 	logger  *log.Logger
+	// the disperse contract used for batch payouts (see SetDisperseAddress)
+	disperseAddress string
 }
 
 func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Request) {
@@ -125,6 +135,18 @@ func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Reque
 	node.rpcHost.HandleInfoRequest(w, r)
 }
 
+func (node *Node) SetRequestHandler(handler hostpool.RequestHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetRequestHandler(handler)
+	}
+}
+
+func (node *Node) SetBroadcastHandler(handler hostpool.BroadcastHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetBroadcastHandler(handler)
+	}
+}
+
 type Block struct {
 	Number           string         `json:"number"`
 	Hash             string         `json:"hash"`
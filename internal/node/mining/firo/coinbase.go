@@ -17,6 +17,7 @@ func GenerateCoinbase(
 	addresses []string,
 	amounts []uint64,
 	blockHeight, nTime uint64,
+	extraNonceSize byte,
 	extraData []byte,
 	extraPayload string,
 	prefixP2PKH, prefixP2SH []byte,
@@ -29,11 +30,6 @@ func GenerateCoinbase(
 
 	tx := btctx.NewTransaction(txVersion, 0, prefixP2PKH, prefixP2SH, false)
 
-	var extraNonceSize byte = 0x04
-	if addresses[0] == "a8ULhhDgfdSiXJhSZVdhb8EuDc6R3ogsaM" {
-		extraNonceSize = 0x08
-	}
-
 	var buf bytes.Buffer
 	var order = binary.LittleEndian
 	if err := wire.WriteSerializedNumber(&buf, order, blockHeight); err != nil {
@@ -7,16 +7,17 @@ import (
 
 func TestGenerateCoinbase(t *testing.T) {
 	tests := []struct {
-		addresses    []string
-		amounts      []uint64
-		height       uint64
-		nTime        uint64
-		extraData    []byte
-		extraPayload string
-		prefixP2PKH  []byte
-		prefixP2SH   []byte
-		coinbaseHex  []byte
-		coinbaseHash []byte
+		addresses      []string
+		amounts        []uint64
+		height         uint64
+		nTime          uint64
+		extraNonceSize byte
+		extraData      []byte
+		extraPayload   string
+		prefixP2PKH    []byte
+		prefixP2SH     []byte
+		coinbaseHex    []byte
+		coinbaseHash   []byte
 	}{
 
 		{
@@ -25,9 +26,10 @@ func TestGenerateCoinbase(t *testing.T) {
 				"a7MaoPUu68cUg6SHjSXHzz4bJoPa4Fr9wp",
 				"a4C7eg6NGtsR4d16cACCTEbJNVJMDJQWMx",
 			},
-			amounts: []uint64{187500000, 437500000, 625000000},
-			height:  481899,
-			nTime:   1654104368,
+			amounts:        []uint64{187500000, 437500000, 625000000},
+			height:         481899,
+			nTime:          1654104368,
+			extraNonceSize: 0x04,
 			// extraData:    "/WoolyPooly/",
 			// 2f576f6f6c79506f6f6c792f
 			extraData: []byte{
@@ -67,9 +69,10 @@ func TestGenerateCoinbase(t *testing.T) {
 				"a2QhvbeFrui4bwhwTX931YcYb9cFK674gn",
 				"aFrAVZFr8pva5mG8XKaUH8EXcFVVNxLiuB",
 			},
-			amounts: []uint64{625000226, 437500000, 187500000},
-			height:  482105,
-			nTime:   1654168961,
+			amounts:        []uint64{625000226, 437500000, 187500000},
+			height:         482105,
+			nTime:          1654168961,
+			extraNonceSize: 0x08,
 			// extraData:    "2Miners https://2miners.com",
 			// 324d696e6572732068747470733a2f2f326d696e6572732e636f6d
 			extraData: []byte{
@@ -109,7 +112,7 @@ func TestGenerateCoinbase(t *testing.T) {
 
 	for i, tt := range tests {
 		coinbaseHex, coinbaseHash, err := GenerateCoinbase(tt.addresses, tt.amounts, tt.height,
-			tt.nTime, tt.extraData, tt.extraPayload, tt.prefixP2PKH, tt.prefixP2SH)
+			tt.nTime, tt.extraNonceSize, tt.extraData, tt.extraPayload, tt.prefixP2PKH, tt.prefixP2SH)
 		if err != nil {
 			t.Errorf("failed on %d: GenerateCoinbase: %v", i, err)
 		} else if bytes.Compare(coinbaseHex, tt.coinbaseHex) != 0 {
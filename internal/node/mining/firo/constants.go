@@ -11,6 +11,7 @@ import (
 const (
 	globalDiffFactor = 4294967296
 	epochLength      = 1300
+	messagePrefix    = "Zcoin Signed Message:\n"
 )
 
 var (
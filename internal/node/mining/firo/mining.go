@@ -16,6 +16,7 @@ import (
 	"github.com/magicpool-co/pool/pkg/common"
 	"github.com/magicpool-co/pool/pkg/crypto"
 	"github.com/magicpool-co/pool/pkg/crypto/blkbuilder"
+	"github.com/magicpool-co/pool/pkg/crypto/tx/btctx"
 	"github.com/magicpool-co/pool/pkg/dbcl"
 	"github.com/magicpool-co/pool/pkg/stratum/rpc"
 	"github.com/magicpool-co/pool/types"
@@ -66,6 +67,19 @@ func (node Node) PingHosts() ([]string, []uint64, []bool, []error) {
 	return hostIDs, heights, statuses, errs
 }
 
+func (node Node) GetBlockHashes(start, end uint64) ([]string, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid range")
+	}
+
+	heights := make([]uint64, end-start+1)
+	for i := range heights {
+		heights[i] = start + uint64(i)
+	}
+
+	return node.getBlockHashMany(heights)
+}
+
 func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 	if start > end {
 		return nil, fmt.Errorf("invalid range")
@@ -104,7 +118,7 @@ func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 				return nil, err
 			}
 
-			value, err := node.getRewardsFromTX(coinbaseTx, devRewards)
+			value, err := node.getRewardsFromTX(coinbaseTx, devRewards, false)
 			if err != nil {
 				return nil, err
 			}
@@ -145,7 +159,9 @@ func (node Node) getCurrentDevRewards() ([]uint64, error) {
 	return devRewards, nil
 }
 
-func (node Node) getRewardsFromTX(tx *Transaction, devRewards []uint64) (uint64, error) {
+// getRewardsFromTX sums the coinbase outputs that reach the pool wallet. for self-paid
+// (true solo) rounds only the fee output paid to the pool address is counted.
+func (node Node) getRewardsFromTX(tx *Transaction, devRewards []uint64, selfPaid bool) (uint64, error) {
 	// copy dev rewards to avoid overwriting the slice
 	devRewardsCopy := make([]uint64, len(devRewards))
 	for i, devReward := range devRewards {
@@ -157,9 +173,24 @@ func (node Node) getRewardsFromTX(tx *Transaction, devRewards []uint64) (uint64,
 	for _, input := range tx.Inputs {
 		if len(input.Coinbase) > 0 {
 			for _, out := range tx.Outputs {
+				var address string
+				if len(out.ScriptPubKey.Addresses) > 0 {
+					address = out.ScriptPubKey.Addresses[0]
+				}
+
+				// extra outputs from the coinbase policy never reach the pool wallet
+				if selfPaid && address != node.address {
+					continue
+				} else if !selfPaid && node.coinbasePolicy.IsExtraAddress(address) {
+					continue
+				}
+
 				valBig, err := common.StringDecimalToBigint(out.Value.String(), node.GetUnits().Big())
 				if err != nil {
 					return amount, err
+				} else if selfPaid {
+					amount += valBig.Uint64()
+					continue
 				}
 
 				var isReward bool
@@ -181,16 +212,60 @@ func (node Node) getRewardsFromTX(tx *Transaction, devRewards []uint64) (uint64,
 	return amount, nil
 }
 
-func (node Node) parseBlockTemplate(template *BlockTemplate) (*types.StratumJob, error) {
-	addresses := append([]string{node.address}, node.devWalletAddresses...)
-	amounts := append([]uint64{template.CoinbaseValue}, node.devWalletAmounts...)
+func (node *Node) SetCoinbasePolicy(policy *types.CoinbasePolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	for _, output := range policy.ExtraOutputs {
+		if !node.ValidateAddress(output.Address) {
+			return fmt.Errorf("invalid coinbase output address: %s", output.Address)
+		}
+	}
+
+	node.coinbasePolicy = policy
+
+	return nil
+}
+
+func (node Node) GetSoloJob(job *types.StratumJob, address string, feeShare uint64) (*types.StratumJob, error) {
+	template, ok := job.Data.(*BlockTemplate)
+	if !ok {
+		return nil, fmt.Errorf("no block template for job %s", job.ID)
+	}
+
+	policy := node.coinbasePolicy.ForSoloMiner(address, node.address, feeShare)
+	soloJob, err := node.parseBlockTemplate(template, policy)
+	if err != nil {
+		return nil, err
+	}
+	soloJob.HostID = job.HostID
+
+	return soloJob, nil
+}
+
+func (node Node) parseBlockTemplate(
+	template *BlockTemplate,
+	policy *types.CoinbasePolicy,
+) (*types.StratumJob, error) {
+	minerReward, extraAddresses, extraAmounts := policy.SplitReward(template.CoinbaseValue)
+	addresses := append([]string{policy.GetPayoutAddress(node.address)}, node.devWalletAddresses...)
+	amounts := append([]uint64{minerReward}, node.devWalletAmounts...)
 	for _, znode := range template.ZNode {
 		addresses = append(addresses, znode.Payee)
 		amounts = append(amounts, znode.Amount)
 	}
+	addresses = append(addresses, extraAddresses...)
+	amounts = append(amounts, extraAmounts...)
+
+	// the extra nonce is always zeroed, the signature (if any) is pushed after it
+	var extraData []byte
+	if signature := policy.GetSignature(); signature != "" {
+		extraData = append(make([]byte, 4), btctx.EncodeScriptData([]byte(signature))...)
+	}
 
 	coinbaseHex, coinbaseHash, err := GenerateCoinbase(addresses, amounts, template.Height,
-		uint64(template.CurTime), nil, template.CoinbasePayload, node.prefixP2PKH, node.prefixP2SH)
+		uint64(template.CurTime), 0x04, extraData, template.CoinbasePayload, node.prefixP2PKH, node.prefixP2SH)
 	if err != nil {
 		return nil, err
 	}
@@ -249,6 +324,7 @@ func (node Node) parseBlockTemplate(template *BlockTemplate) (*types.StratumJob,
 		Height:       new(types.Number).SetFromValue(template.Height),
 		Difficulty:   new(types.Difficulty).SetFromBits(uint32(bits), node.GetMaxDifficulty()),
 		BlockBuilder: builder,
+		Data:         template,
 	}
 
 	return job, nil
@@ -274,7 +350,7 @@ func (node Node) JobNotify(ctx context.Context, interval time.Duration) chan *ty
 				if err != nil {
 					node.logger.Error(err)
 				} else if lastHeight != template.Height || now.After(lastJob.Add(staticInterval)) {
-					job, err := node.parseBlockTemplate(template)
+					job, err := node.parseBlockTemplate(template, node.coinbasePolicy)
 					if err != nil {
 						node.logger.Error(err)
 					} else {
@@ -414,8 +490,8 @@ func (node Node) GetSubscribeResponses(id []byte, clientID, extraNonce string) (
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
-	res, err := node.GetSetDifficultyResponse(diffFactor)
+func (node Node) GetAuthorizeResponses(clientType, diffFactor int) ([]interface{}, error) {
+	res, err := node.GetSetDifficultyResponse(clientType, diffFactor)
 	if err != nil {
 		return nil, err
 	}
@@ -423,7 +499,7 @@ func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetSetDifficultyResponse(diffFactor int) (interface{}, error) {
+func (node Node) GetSetDifficultyResponse(clientType, diffFactor int) (interface{}, error) {
 	return rpc.NewRequest("mining.set_target", node.GetShareDifficulty(diffFactor).TargetHex())
 }
 
@@ -465,7 +541,7 @@ func (node Node) UnlockRound(round *pooldb.Round) error {
 			return err
 		}
 
-		value, err := node.getRewardsFromTX(coinbaseTx, devRewards)
+		value, err := node.getRewardsFromTX(coinbaseTx, devRewards, round.SelfPaid)
 		if err != nil {
 			return err
 		}
@@ -479,6 +555,18 @@ func (node Node) UnlockRound(round *pooldb.Round) error {
 	return nil
 }
 
+// getFeeOutputIndex finds the index of the coinbase output paying the pool
+// address, which for self-paid (true solo) rounds is the pool fee output.
+func (node Node) getFeeOutputIndex(tx *Transaction) (uint32, error) {
+	for i, out := range tx.Outputs {
+		if len(out.ScriptPubKey.Addresses) > 0 && out.ScriptPubKey.Addresses[0] == node.address {
+			return uint32(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("no fee output in coinbase %s", tx.TxID)
+}
+
 func (node Node) MatureRound(round *pooldb.Round) ([]*pooldb.UTXO, error) {
 	if round.Pending || round.Orphan || round.Mature {
 		return nil, nil
@@ -500,6 +588,19 @@ func (node Node) MatureRound(round *pooldb.Round) ([]*pooldb.UTXO, error) {
 		return nil, nil
 	}
 
+	var index uint32
+	if round.SelfPaid {
+		coinbaseTx, err := node.getSpecialTxesCoinbase(block.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		index, err = node.getFeeOutputIndex(coinbaseTx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	round.Mature = true
 
 	utxos := []*pooldb.UTXO{
@@ -507,7 +608,7 @@ func (node Node) MatureRound(round *pooldb.Round) ([]*pooldb.UTXO, error) {
 			ChainID: round.ChainID,
 			Value:   round.Value,
 			TxID:    types.StringValue(round.CoinbaseTxID),
-			Index:   0,
+			Index:   index,
 			Active:  true,
 			Spent:   false,
 		},
@@ -212,32 +212,34 @@ func (node Node) getBlockTemplate() (string, *BlockTemplate, error) {
 	return res.HostID, template, nil
 }
 
-func (node Node) submitBlock(hostID, block string) error {
-	var res *rpc.Response
-	if node.mocked {
-		res = mock.SubmitBlock(hostID, block)
-	} else {
-		req, err := rpc.NewRequestWithHostID(hostID, "submitblock", block)
-		if err != nil {
-			return err
-		}
-
-		res, err = node.rpcHost.ExecRPC(req)
-		if err != nil {
-			return err
-		}
-	}
-
+func validateSubmitBlock(res *rpc.Response) error {
 	var result string
 	if err := json.Unmarshal(res.Result, &result); err != nil {
 		return err
-	} else if len(result) > 0 {
+	} else if len(result) > 0 && result != "duplicate" {
 		return fmt.Errorf("submit block error: %s", result)
 	}
 
 	return nil
 }
 
+// submitBlock broadcasts the block to every synced host and the host that created
+// the template in parallel, the first host to accept the block wins.
+func (node Node) submitBlock(hostID, block string) error {
+	if node.mocked {
+		return validateSubmitBlock(mock.SubmitBlock(hostID, block))
+	}
+
+	req, err := rpc.NewRequestWithHostID(hostID, "submitblock", block)
+	if err != nil {
+		return err
+	}
+
+	_, err = node.rpcHost.ExecRPCBroadcast(req, validateSubmitBlock)
+
+	return err
+}
+
 func (node Node) sendRawTransaction(tx string) (string, error) {
 	var res *rpc.Response
 	var err error
@@ -257,3 +259,23 @@ func (node Node) sendRawTransaction(tx string) (string, error) {
 
 	return txid, nil
 }
+
+func (node Node) verifyMessage(address, signature, message string) (bool, error) {
+	var res *rpc.Response
+	var err error
+	if node.mocked {
+		res = mock.VerifyMessage(address, signature, message)
+	} else {
+		res, err = node.rpcHost.ExecRPCFromArgsSynced("verifymessage", address, signature, message)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	var valid bool
+	if err := json.Unmarshal(res.Result, &valid); err != nil {
+		return false, err
+	}
+
+	return valid, nil
+}
@@ -98,3 +98,21 @@ func (node Node) BroadcastTx(tx string) (string, error) {
 
 	return txid, nil
 }
+
+// SignMessage signs the message in the "signmessage" format. The message magic differs between
+// forks, so the signature is verified by the node before it is published.
+func (node Node) SignMessage(message string) (string, error) {
+	signature, err := btctx.SignMessageWithPrefix(node.privKey, messagePrefix, message)
+	if err != nil {
+		return "", err
+	}
+
+	valid, err := node.verifyMessage(node.address, signature, message)
+	if err != nil {
+		return "", err
+	} else if !valid {
+		return "", fmt.Errorf("message signature rejected by node")
+	}
+
+	return signature, nil
+}
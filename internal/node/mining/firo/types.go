@@ -3,6 +3,7 @@ package firo
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/goccy/go-json"
@@ -14,6 +15,7 @@ import (
 	"github.com/magicpool-co/pool/pkg/hostpool"
 	"github.com/magicpool-co/pool/pkg/sshtunnel"
 	"github.com/magicpool-co/pool/pkg/stratum/rpc"
+	"github.com/magicpool-co/pool/types"
 )
 
 var (
@@ -45,6 +47,12 @@ func generateHost(
 				Method:  "getbestblockhash",
 			},
 		}
+		hostRequestPolicy = &hostpool.RequestPolicy{
+			MethodTimeouts: map[string]time.Duration{
+				"getblocktemplate": time.Second * 5,
+				"submitblock":      time.Second * 30,
+			},
+		}
 	)
 
 	if len(urls) == 0 {
@@ -52,6 +60,7 @@ func generateHost(
 	}
 
 	host := hostpool.NewHTTPPool(context.Background(), logger, hostHealthCheck, tunnel)
+	host.SetRequestPolicy(hostRequestPolicy)
 	for _, url := range urls {
 		err := host.AddHost(url, port, hostOptions)
 		if err != nil {
@@ -121,6 +130,7 @@ type Node struct {
 	privKey            *secp256k1.PrivateKey
 	rpcHost            *hostpool.HTTPPool
 	pow                *firopow.Client
+	coinbasePolicy     *types.CoinbasePolicy
 	logger             *log.Logger
 }
 
@@ -135,6 +145,18 @@ func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Reque
 	node.rpcHost.HandleInfoRequest(w, r)
 }
 
+func (node *Node) SetRequestHandler(handler hostpool.RequestHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetRequestHandler(handler)
+	}
+}
+
+func (node *Node) SetBroadcastHandler(handler hostpool.BroadcastHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetBroadcastHandler(handler)
+	}
+}
+
 type BlockchainInfo struct {
 	Chain                string  `json:"chain"`
 	Blocks               uint64  `json:"blocks"`
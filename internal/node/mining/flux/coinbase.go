@@ -2,10 +2,13 @@ package flux
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 
 	"github.com/magicpool-co/pool/pkg/crypto"
 	"github.com/magicpool-co/pool/pkg/crypto/tx/btctx"
+	"github.com/magicpool-co/pool/pkg/crypto/wire"
 )
 
 const (
@@ -13,6 +16,9 @@ const (
 	versionMask    uint32 = 0x80000000
 	versionGroupID uint32 = 0x892f2085
 	expiryHeight   uint32 = 0
+
+	opCheckBlockAtHeight  = 0xb4
+	replayProtectionDepth = 300
 )
 
 func GenerateCoinbase(
@@ -66,3 +72,506 @@ func GenerateCoinbase(
 
 	return serialized, txHash, nil
 }
+
+// encodeScriptNum pushes n the same way CScript's operator<< does for integers.
+func encodeScriptNum(n uint64) []byte {
+	if n == 0 {
+		return btctx.EncodeOpCode(btctx.OP_0)
+	} else if n <= 16 {
+		return btctx.EncodeOpCode(btctx.OP_1 - 1 + int(n))
+	}
+
+	data := make([]byte, 0, 8)
+	for ; n > 0; n >>= 8 {
+		data = append(data, byte(n))
+	}
+
+	// the most significant bit is the sign bit
+	if data[len(data)-1]&0x80 != 0 {
+		data = append(data, 0x00)
+	}
+
+	return btctx.EncodeScriptData(data)
+}
+
+// CompileReplayProtection returns the "<block hash> <height> OP_CHECKBLOCKATHEIGHT" suffix
+// zend requires on transparent output scripts, blockHash being the hex hash of the block at height.
+func CompileReplayProtection(blockHash string, height uint64) ([]byte, error) {
+	hashBytes, err := hex.DecodeString(blockHash)
+	if err != nil {
+		return nil, err
+	} else if len(hashBytes) != 32 {
+		return nil, fmt.Errorf("invalid block hash %s", blockHash)
+	}
+
+	script := bytes.Join([][]byte{
+		btctx.EncodeScriptData(crypto.ReverseBytes(hashBytes)),
+		encodeScriptNum(height),
+		btctx.EncodeOpCode(opCheckBlockAtHeight),
+	}, nil)
+
+	return script, nil
+}
+
+// ParseCoinbaseOutput returns the value and script of the first transparent output
+// of a (v1-v5) zcash transaction. shielded components are never parsed since
+// they always come after the transparent bundle.
+func ParseCoinbaseOutput(txHex string) (uint64, []byte, error) {
+	txBytes, err := hex.DecodeString(txHex)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var order = binary.LittleEndian
+	var r = bytes.NewReader(txBytes)
+	var header uint32
+	if err := wire.ReadElement(r, order, &header); err != nil {
+		return 0, nil, err
+	}
+
+	overwintered := header>>31 == 1
+	version := header & 0x7FFFFFFF
+	if overwintered {
+		var versionGroupID uint32
+		if err := wire.ReadElement(r, order, &versionGroupID); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if version >= 5 {
+		// consensus branch id, lock time, expiry height
+		var skip [12]byte
+		if err := wire.ReadElement(r, order, skip[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	inputCount, err := wire.ReadVarInt(r, order)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for i := uint64(0); i < inputCount; i++ {
+		var prevOut [36]byte
+		var sequence uint32
+		if err := wire.ReadElement(r, order, prevOut[:]); err != nil {
+			return 0, nil, err
+		} else if _, err := wire.ReadVarBytes(r, order); err != nil {
+			return 0, nil, err
+		} else if err := wire.ReadElement(r, order, &sequence); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	outputCount, err := wire.ReadVarInt(r, order)
+	if err != nil {
+		return 0, nil, err
+	} else if outputCount == 0 {
+		return 0, nil, fmt.Errorf("no transparent outputs")
+	}
+
+	var value uint64
+	if err := wire.ReadElement(r, order, &value); err != nil {
+		return 0, nil, err
+	}
+
+	script, err := wire.ReadVarBytes(r, order)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return value, script, nil
+}
+
+type zcashInput struct {
+	prevOut  []byte
+	script   []byte
+	sequence uint32
+}
+
+type zcashOutput struct {
+	value  uint64
+	script []byte
+}
+
+// zcashCoinbase is a transparent only (v1-v5) zcash transaction. anything
+// after the transparent bundle has to be an empty shielded bundle, otherwise
+// the outputs can't be changed without invalidating the binding signature.
+type zcashCoinbase struct {
+	header         uint32
+	versionGroupID uint32
+	branchID       uint32
+	lockTime       uint32
+	expiryHeight   uint32
+	inputs         []*zcashInput
+	outputs        []*zcashOutput
+}
+
+func (tx *zcashCoinbase) version() uint32 {
+	return tx.header & 0x7FFFFFFF
+}
+
+func (tx *zcashCoinbase) overwintered() bool {
+	return tx.header>>31 == 1
+}
+
+func readEmptyBundle(r *bytes.Reader, order binary.ByteOrder, name string) error {
+	count, err := wire.ReadVarInt(r, order)
+	if err != nil {
+		return err
+	} else if count != 0 {
+		return fmt.Errorf("coinbase has %d %s", count, name)
+	}
+
+	return nil
+}
+
+func parseZcashCoinbase(txBytes []byte) (*zcashCoinbase, error) {
+	var order = binary.LittleEndian
+	var r = bytes.NewReader(txBytes)
+	var tx = new(zcashCoinbase)
+	if err := wire.ReadElement(r, order, &tx.header); err != nil {
+		return nil, err
+	}
+
+	version := tx.version()
+	if tx.overwintered() && (version < 3 || version > 5) {
+		return nil, fmt.Errorf("unsupported overwintered coinbase version %d", version)
+	} else if !tx.overwintered() && (version < 1 || version > 2) {
+		return nil, fmt.Errorf("unsupported coinbase version %d", version)
+	}
+
+	if tx.overwintered() {
+		if err := wire.ReadElement(r, order, &tx.versionGroupID); err != nil {
+			return nil, err
+		}
+	}
+
+	if version >= 5 {
+		if err := wire.ReadElement(r, order, &tx.branchID); err != nil {
+			return nil, err
+		} else if err := wire.ReadElement(r, order, &tx.lockTime); err != nil {
+			return nil, err
+		} else if err := wire.ReadElement(r, order, &tx.expiryHeight); err != nil {
+			return nil, err
+		}
+	}
+
+	inputCount, err := wire.ReadVarInt(r, order)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.inputs = make([]*zcashInput, inputCount)
+	for i := range tx.inputs {
+		input := &zcashInput{prevOut: make([]byte, 36)}
+		if err := wire.ReadElement(r, order, input.prevOut); err != nil {
+			return nil, err
+		} else if input.script, err = wire.ReadVarBytes(r, order); err != nil {
+			return nil, err
+		} else if err := wire.ReadElement(r, order, &input.sequence); err != nil {
+			return nil, err
+		}
+		tx.inputs[i] = input
+	}
+
+	outputCount, err := wire.ReadVarInt(r, order)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.outputs = make([]*zcashOutput, outputCount)
+	for i := range tx.outputs {
+		output := new(zcashOutput)
+		if err := wire.ReadElement(r, order, &output.value); err != nil {
+			return nil, err
+		} else if output.script, err = wire.ReadVarBytes(r, order); err != nil {
+			return nil, err
+		}
+		tx.outputs[i] = output
+	}
+
+	if version >= 5 {
+		if err := readEmptyBundle(r, order, "sapling spends"); err != nil {
+			return nil, err
+		} else if err := readEmptyBundle(r, order, "sapling outputs"); err != nil {
+			return nil, err
+		} else if err := readEmptyBundle(r, order, "orchard actions"); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := wire.ReadElement(r, order, &tx.lockTime); err != nil {
+			return nil, err
+		}
+
+		if version >= 3 {
+			if err := wire.ReadElement(r, order, &tx.expiryHeight); err != nil {
+				return nil, err
+			}
+		}
+
+		if version >= 4 {
+			var valueBalance int64
+			if err := wire.ReadElement(r, order, &valueBalance); err != nil {
+				return nil, err
+			} else if valueBalance != 0 {
+				return nil, fmt.Errorf("coinbase has a sapling value balance of %d", valueBalance)
+			} else if err := readEmptyBundle(r, order, "sapling spends"); err != nil {
+				return nil, err
+			} else if err := readEmptyBundle(r, order, "sapling outputs"); err != nil {
+				return nil, err
+			}
+		}
+
+		if version >= 2 {
+			if err := readEmptyBundle(r, order, "joinsplits"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if r.Len() > 0 {
+		return nil, fmt.Errorf("coinbase has %d trailing bytes", r.Len())
+	}
+
+	return tx, nil
+}
+
+func (tx *zcashCoinbase) serializeTransparent(buf *bytes.Buffer, order binary.ByteOrder) error {
+	if err := wire.WriteVarInt(buf, order, uint64(len(tx.inputs))); err != nil {
+		return err
+	}
+
+	for _, input := range tx.inputs {
+		if err := wire.WriteElement(buf, order, input.prevOut); err != nil {
+			return err
+		} else if err := wire.WriteVarBytes(buf, order, input.script); err != nil {
+			return err
+		} else if err := wire.WriteElement(buf, order, input.sequence); err != nil {
+			return err
+		}
+	}
+
+	if err := wire.WriteVarInt(buf, order, uint64(len(tx.outputs))); err != nil {
+		return err
+	}
+
+	for _, output := range tx.outputs {
+		if err := wire.WriteElement(buf, order, output.value); err != nil {
+			return err
+		} else if err := wire.WriteVarBytes(buf, order, output.script); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (tx *zcashCoinbase) serialize() ([]byte, error) {
+	var order = binary.LittleEndian
+	var buf bytes.Buffer
+
+	version := tx.version()
+	if err := wire.WriteElement(&buf, order, tx.header); err != nil {
+		return nil, err
+	}
+
+	if tx.overwintered() {
+		if err := wire.WriteElement(&buf, order, tx.versionGroupID); err != nil {
+			return nil, err
+		}
+	}
+
+	if version >= 5 {
+		err := wire.WriteElements(&buf, order, tx.branchID, tx.lockTime, tx.expiryHeight)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.serializeTransparent(&buf, order); err != nil {
+		return nil, err
+	}
+
+	// every shielded bundle is empty, so only the counts are written
+	if version >= 5 {
+		buf.Write([]byte{0x00, 0x00, 0x00})
+	} else {
+		if err := wire.WriteElement(&buf, order, tx.lockTime); err != nil {
+			return nil, err
+		}
+
+		if version >= 3 {
+			if err := wire.WriteElement(&buf, order, tx.expiryHeight); err != nil {
+				return nil, err
+			}
+		}
+
+		if version >= 4 {
+			// value balance, sapling spends and sapling outputs
+			buf.Write(make([]byte, 10))
+		}
+
+		if version >= 2 {
+			buf.Write([]byte{0x00})
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func blake2bPersonal(personal string, data ...[]byte) ([]byte, error) {
+	return crypto.Blake2b256Personal(bytes.Join(data, nil), []byte(personal))
+}
+
+// txid returns the (reversed) txid, which for v5 transactions is the
+// ZIP-244 digest instead of the double sha256 of the transaction.
+func (tx *zcashCoinbase) txid() ([]byte, error) {
+	serialized, err := tx.serialize()
+	if err != nil {
+		return nil, err
+	} else if tx.version() < 5 {
+		return crypto.ReverseBytes(crypto.Sha256d(serialized)), nil
+	}
+
+	var order = binary.LittleEndian
+	var header bytes.Buffer
+	err = wire.WriteElements(&header, order, tx.header, tx.versionGroupID, tx.branchID, tx.lockTime, tx.expiryHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	var prevOuts, sequences, outputs bytes.Buffer
+	for _, input := range tx.inputs {
+		prevOuts.Write(input.prevOut)
+		if err := wire.WriteElement(&sequences, order, input.sequence); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, output := range tx.outputs {
+		if err := wire.WriteElement(&outputs, order, output.value); err != nil {
+			return nil, err
+		} else if err := wire.WriteVarBytes(&outputs, order, output.script); err != nil {
+			return nil, err
+		}
+	}
+
+	headerDigest, err := blake2bPersonal("ZTxIdHeadersHash", header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	var transparentDigest []byte
+	if len(tx.inputs) == 0 && len(tx.outputs) == 0 {
+		transparentDigest, err = blake2bPersonal("ZTxIdTranspaHash")
+	} else {
+		var prevOutsDigest, sequencesDigest, outputsDigest []byte
+		if prevOutsDigest, err = blake2bPersonal("ZTxIdPrevoutHash", prevOuts.Bytes()); err != nil {
+			return nil, err
+		} else if sequencesDigest, err = blake2bPersonal("ZTxIdSequencHash", sequences.Bytes()); err != nil {
+			return nil, err
+		} else if outputsDigest, err = blake2bPersonal("ZTxIdOutputsHash", outputs.Bytes()); err != nil {
+			return nil, err
+		}
+		transparentDigest, err = blake2bPersonal("ZTxIdTranspaHash", prevOutsDigest, sequencesDigest, outputsDigest)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	saplingDigest, err := blake2bPersonal("ZTxIdSaplingHash")
+	if err != nil {
+		return nil, err
+	}
+
+	orchardDigest, err := blake2bPersonal("ZTxIdOrchardHash")
+	if err != nil {
+		return nil, err
+	}
+
+	personal := make([]byte, 16)
+	copy(personal, "ZcashTxHash_")
+	order.PutUint32(personal[12:], tx.branchID)
+	digest, err := blake2bPersonal(string(personal), headerDigest, transparentDigest, saplingDigest, orchardDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.ReverseBytes(digest), nil
+}
+
+// RewriteCoinbase replaces the miner output (the first transparent output) of a node built
+// coinbase with outputs to addresses and appends extraData to the coinbase script. The first
+// address takes the place of the miner output, the rest are added after the existing outputs.
+// Any script data after minerScript in the original miner output (zend's replay protection)
+// is kept on every new output. The amounts have to add up to the original miner reward.
+func RewriteCoinbase(
+	txHex string,
+	minerScript []byte,
+	addresses []string,
+	amounts []uint64,
+	extraData string,
+	prefixP2PKH, prefixP2SH []byte,
+) ([]byte, []byte, error) {
+	if len(addresses) != len(amounts) {
+		return nil, nil, fmt.Errorf("address and amount length mismatch")
+	} else if len(addresses) == 0 {
+		return nil, nil, fmt.Errorf("cannot send transaction without recipients")
+	}
+
+	txBytes, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := parseZcashCoinbase(txBytes)
+	if err != nil {
+		return nil, nil, err
+	} else if len(tx.inputs) != 1 || len(tx.outputs) == 0 {
+		return nil, nil, fmt.Errorf("invalid coinbase: %d inputs, %d outputs", len(tx.inputs), len(tx.outputs))
+	} else if !bytes.HasPrefix(tx.outputs[0].script, minerScript) {
+		return nil, nil, fmt.Errorf("first coinbase output is not the miner output")
+	}
+
+	var total uint64
+	for _, amount := range amounts {
+		total += amount
+	}
+
+	if total != tx.outputs[0].value {
+		return nil, nil, fmt.Errorf("coinbase amount mismatch: have %d, want %d", total, tx.outputs[0].value)
+	}
+
+	scriptSuffix := tx.outputs[0].script[len(minerScript):]
+	outputs := make([]*zcashOutput, len(addresses))
+	for i, address := range addresses {
+		script, err := btctx.AddressToScript(address, prefixP2PKH, prefixP2SH, false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		outputs[i] = &zcashOutput{
+			value:  amounts[i],
+			script: append(script, scriptSuffix...),
+		}
+	}
+
+	tx.outputs = append(append([]*zcashOutput{outputs[0]}, tx.outputs[1:]...), outputs[1:]...)
+	tx.inputs[0].script = append(tx.inputs[0].script, []byte(extraData)...)
+	if len(tx.inputs[0].script) > 100 {
+		return nil, nil, fmt.Errorf("coinbase script too long: %d bytes", len(tx.inputs[0].script))
+	}
+
+	serialized, err := tx.serialize()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txHash, err := tx.txid()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return serialized, txHash, nil
+}
@@ -2,7 +2,11 @@ package flux
 
 import (
 	"bytes"
+	"encoding/hex"
 	"testing"
+
+	"github.com/magicpool-co/pool/pkg/crypto"
+	"github.com/magicpool-co/pool/pkg/crypto/base58"
 )
 
 func TestGenerateCoinbase(t *testing.T) {
@@ -31,8 +35,8 @@ func TestGenerateCoinbase(t *testing.T) {
 			},
 			height:      1134187,
 			extraData:   "2Miners https://2miners.com",
-			prefixP2PKH: mainnetPrefixP2PKH,
-			prefixP2SH:  mainnetPrefixP2SH,
+			prefixP2PKH: FLUX.MainnetPrefixP2PKH,
+			prefixP2SH:  FLUX.MainnetPrefixP2SH,
 			coinbaseHex: []byte{
 				0x04, 0x00, 0x00, 0x80, 0x85, 0x20, 0x2f, 0x89, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
@@ -74,8 +78,8 @@ func TestGenerateCoinbase(t *testing.T) {
 			},
 			height:      1134309,
 			extraData:   "MinerPool https://flux.minerpool.org",
-			prefixP2PKH: mainnetPrefixP2PKH,
-			prefixP2SH:  mainnetPrefixP2SH,
+			prefixP2PKH: FLUX.MainnetPrefixP2PKH,
+			prefixP2SH:  FLUX.MainnetPrefixP2SH,
 			coinbaseHex: []byte{
 				0x04, 0x00, 0x00, 0x80, 0x85, 0x20, 0x2f, 0x89, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
@@ -117,3 +121,208 @@ func TestGenerateCoinbase(t *testing.T) {
 		}
 	}
 }
+
+func TestParseCoinbaseOutput(t *testing.T) {
+	tests := []struct {
+		txHex  string
+		value  uint64
+		script string
+	}{
+		{
+			// v4 (sapling) coinbase from a flux block template
+			txHex: "0400008085202f89010000000000000000000000000000000000000000000000000000000000000000ffffffff" +
+				"0503d4481200ffffffff04807584df000000001976a914cb27f1b9a165bdcfca1ffb2ba5fa6e2c43eba02c88aca0" +
+				"118721000000001976a9141aacb774eb225461ce8bf37e7843f8e3858923cf88ac601de137000000001976a914d2" +
+				"40cdcf21eed393ddec1ffe3b50f36aa05ab10b88ac80461c86000000001976a914064fe4624c2e8e50f78a241545" +
+				"46bfce9860125f88ac00000000000000000000000000000000000000",
+			value:  3750000000,
+			script: "76a914cb27f1b9a165bdcfca1ffb2ba5fa6e2c43eba02c88ac",
+		},
+		{
+			// v5 (nu5) coinbase with an empty sapling and orchard bundle
+			txHex: "050000800a27a726b4d0d6c200000000a0bb2a00" +
+				"010000000000000000000000000000000000000000000000000000000000000000ffffffff0403a0bb2affffffff" +
+				"0240be4025000000001976a914cb27f1b9a165bdcfca1ffb2ba5fa6e2c43eba02c88ac" +
+				"c0d8a7000000000017a914d45cb1adffb5215a42720532a076f02c7c778c9087" +
+				"000000",
+			value:  625000000,
+			script: "76a914cb27f1b9a165bdcfca1ffb2ba5fa6e2c43eba02c88ac",
+		},
+	}
+
+	for i, tt := range tests {
+		value, script, err := ParseCoinbaseOutput(tt.txHex)
+		if err != nil {
+			t.Errorf("failed on %d: ParseCoinbaseOutput: %v", i, err)
+		} else if value != tt.value {
+			t.Errorf("failed on %d: value mismatch: have %d, want %d", i, value, tt.value)
+		} else if hex.EncodeToString(script) != tt.script {
+			t.Errorf("failed on %d: script mismatch: have %x, want %s", i, script, tt.script)
+		}
+	}
+}
+
+func TestRewriteCoinbase(t *testing.T) {
+	const (
+		v4Coinbase = "0400008085202f89010000000000000000000000000000000000000000000000000000000000000000ffffffff" +
+			"0503d4481200ffffffff04807584df000000001976a914cb27f1b9a165bdcfca1ffb2ba5fa6e2c43eba02c88aca0" +
+			"118721000000001976a9141aacb774eb225461ce8bf37e7843f8e3858923cf88ac601de137000000001976a914d2" +
+			"40cdcf21eed393ddec1ffe3b50f36aa05ab10b88ac80461c86000000001976a914064fe4624c2e8e50f78a241545" +
+			"46bfce9860125f88ac00000000000000000000000000000000000000"
+		v5Coinbase = "050000800a27a726b4d0d6c200000000a0bb2a00" +
+			"010000000000000000000000000000000000000000000000000000000000000000ffffffff0403a0bb2affffffff" +
+			"0240be4025000000001976a914cb27f1b9a165bdcfca1ffb2ba5fa6e2c43eba02c88ac" +
+			"c0d8a7000000000017a914d45cb1adffb5215a42720532a076f02c7c778c9087" +
+			"000000"
+	)
+
+	minerScript := mustDecodeHex("76a914cb27f1b9a165bdcfca1ffb2ba5fa6e2c43eba02c88ac")
+	minerAddress := base58.CheckEncode(FLUX.MainnetPrefixP2PKH, minerScript[3:23])
+	soloAddress := "t1JKRwXGfKTGfPV1z48rvoLyabk31z3xwHa"
+
+	tests := []struct {
+		txHex       string
+		addresses   []string
+		amounts     []uint64
+		extraData   string
+		outputCount int
+		coinbaseHex string
+		txid        string
+		valid       bool
+	}{
+		{
+			// unchanged outputs reproduce the node's coinbase
+			txHex:       v4Coinbase,
+			addresses:   []string{minerAddress},
+			amounts:     []uint64{3750000000},
+			outputCount: 4,
+			coinbaseHex: v4Coinbase,
+			txid:        "49cff17b2aed596003c4dc0d1196525740dc8d6ade9996ff35bc24b88e4562de",
+			valid:       true,
+		},
+		{
+			txHex:       v5Coinbase,
+			addresses:   []string{minerAddress},
+			amounts:     []uint64{625000000},
+			outputCount: 2,
+			coinbaseHex: v5Coinbase,
+			valid:       true,
+		},
+		{
+			// solo miner output with the pool fee appended
+			txHex:       v4Coinbase,
+			addresses:   []string{soloAddress, minerAddress},
+			amounts:     []uint64{3712500000, 37500000},
+			extraData:   "magicpool",
+			outputCount: 5,
+			valid:       true,
+		},
+		{
+			txHex:       v5Coinbase,
+			addresses:   []string{soloAddress, minerAddress},
+			amounts:     []uint64{618750000, 6250000},
+			extraData:   "magicpool",
+			outputCount: 3,
+			valid:       true,
+		},
+		{
+			// amounts have to add up to the miner reward
+			txHex:     v4Coinbase,
+			addresses: []string{soloAddress, minerAddress},
+			amounts:   []uint64{3712500000, 37500001},
+			valid:     false,
+		},
+		{
+			// sapling spends can't be rewritten
+			txHex:     v5Coinbase[:len(v5Coinbase)-6] + "010000",
+			addresses: []string{minerAddress},
+			amounts:   []uint64{625000000},
+			valid:     false,
+		},
+	}
+
+	for i, tt := range tests {
+		coinbaseHex, coinbaseHash, err := RewriteCoinbase(tt.txHex, minerScript, tt.addresses, tt.amounts,
+			tt.extraData, FLUX.MainnetPrefixP2PKH, FLUX.MainnetPrefixP2SH)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("failed on %d: expected error", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("failed on %d: RewriteCoinbase: %v", i, err)
+			continue
+		}
+
+		if tt.coinbaseHex != "" && hex.EncodeToString(coinbaseHex) != tt.coinbaseHex {
+			t.Errorf("failed on %d: coinbase mismatch: have %x, want %s", i, coinbaseHex, tt.coinbaseHex)
+		} else if tt.txid != "" && hex.EncodeToString(coinbaseHash) != tt.txid {
+			t.Errorf("failed on %d: txid mismatch: have %x, want %s", i, coinbaseHash, tt.txid)
+		}
+
+		tx, err := parseZcashCoinbase(coinbaseHex)
+		if err != nil {
+			t.Errorf("failed on %d: parse: %v", i, err)
+			continue
+		} else if len(tx.outputs) != tt.outputCount {
+			t.Errorf("failed on %d: output count mismatch: have %d, want %d", i, len(tx.outputs), tt.outputCount)
+		} else if !bytes.HasSuffix(tx.inputs[0].script, []byte(tt.extraData)) {
+			t.Errorf("failed on %d: coinbase script mismatch: have %x", i, tx.inputs[0].script)
+		}
+
+		if tx.version() < 5 {
+			if txid := crypto.ReverseBytes(crypto.Sha256d(coinbaseHex)); !bytes.Equal(txid, coinbaseHash) {
+				t.Errorf("failed on %d: txid mismatch: have %x, want %x", i, coinbaseHash, txid)
+			}
+		}
+
+		for j, address := range tt.addresses {
+			output := tx.outputs[0]
+			if j > 0 {
+				output = tx.outputs[len(tx.outputs)-len(tt.addresses)+j]
+			}
+
+			if output.value != tt.amounts[j] {
+				t.Errorf("failed on %d: output %d value mismatch: have %d, want %d", i, j, output.value, tt.amounts[j])
+			} else if j == 0 && address == minerAddress && !bytes.Equal(output.script, minerScript) {
+				t.Errorf("failed on %d: output %d script mismatch: have %x, want %x", i, j, output.script, minerScript)
+			}
+		}
+	}
+}
+
+func TestCompileReplayProtection(t *testing.T) {
+	const blockHash = "0000000000b0ab4fb0b1bb89a8f7e2ba0f1ad5ac7d0e3c5e02da3e4d6f8e2a01"
+
+	tests := []struct {
+		height uint64
+		script string
+	}{
+		{height: 0, script: "00"},
+		{height: 16, script: "60"},
+		{height: 200, script: "02c800"},
+		{height: 1234567, script: "0387d612"},
+	}
+
+	for i, tt := range tests {
+		script, err := CompileReplayProtection(blockHash, tt.height)
+		if err != nil {
+			t.Errorf("failed on %d: CompileReplayProtection: %v", i, err)
+			continue
+		}
+
+		want := "20" + hex.EncodeToString(crypto.ReverseBytes(mustDecodeHex(blockHash))) + tt.script + "b4"
+		if hex.EncodeToString(script) != want {
+			t.Errorf("failed on %d: script mismatch: have %x, want %s", i, script, want)
+		}
+	}
+}
+
+func mustDecodeHex(data string) []byte {
+	decoded, err := hex.DecodeString(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return decoded
+}
@@ -20,11 +20,11 @@ var (
 )
 
 func (node Node) Name() string {
-	return "Flux"
+	return node.params.Name
 }
 
 func (node Node) Chain() string {
-	return "FLUX"
+	return node.params.Chain
 }
 
 func (node Node) Address() string {
@@ -81,8 +81,8 @@ func (node Node) CalculateHashrate(blockTime, difficulty float64) float64 {
 	return difficulty * (globalDiffFactor / blockTime)
 }
 
-func ValidateAddress(address string) bool {
-	_, err := btctx.AddressToScript(address, mainnetPrefixP2PKH, mainnetPrefixP2SH, false)
+func ValidateAddress(params *Params, address string) bool {
+	_, err := btctx.AddressToScript(address, params.MainnetPrefixP2PKH, params.MainnetPrefixP2SH, false)
 
 	return err == nil
 }
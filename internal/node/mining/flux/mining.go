@@ -1,6 +1,7 @@
 package flux
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"fmt"
@@ -20,11 +21,15 @@ import (
 	"github.com/magicpool-co/pool/types"
 )
 
+var (
+	emptyCommitment = "0000000000000000000000000000000000000000000000000000000000000000"
+)
+
 func (node Node) GetBlockExplorerURL(round *pooldb.Round) string {
 	if node.mainnet {
-		return fmt.Sprintf("https://explorer.runonflux.io/block/%s", round.Hash)
+		return fmt.Sprintf(node.params.BlockExplorerURL, round.Hash)
 	}
-	return fmt.Sprintf("https://testnet.runonflux.io/block/%s", round.Hash)
+	return fmt.Sprintf(node.params.TestnetBlockExplorerURL, round.Hash)
 }
 
 func (node Node) getStatusByHost(hostID string) (uint64, bool, error) {
@@ -57,6 +62,19 @@ func (node Node) PingHosts() ([]string, []uint64, []bool, []error) {
 	return hostIDs, heights, statuses, errs
 }
 
+func (node Node) GetBlockHashes(start, end uint64) ([]string, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid range")
+	}
+
+	heights := make([]uint64, end-start+1)
+	for i := range heights {
+		heights[i] = start + uint64(i)
+	}
+
+	return node.getBlockHashMany(heights)
+}
+
 func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 	if start > end {
 		return nil, fmt.Errorf("invalid range")
@@ -72,7 +90,7 @@ func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 		return nil, err
 	}
 
-	devRewards, err := node.getCurrentDevRewards()
+	fundingRewards, err := node.getCurrentFundingRewards()
 	if err != nil {
 		return nil, err
 	}
@@ -94,7 +112,7 @@ func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 				return nil, fmt.Errorf("no transactions in block")
 			}
 
-			value, err := node.getRewardsFromTX(block.Transactions[0], devRewards)
+			value, err := node.getRewardsFromTX(block.Transactions[0], fundingRewards, false)
 			if err != nil {
 				return nil, err
 			}
@@ -119,40 +137,54 @@ func (node Node) GetBlocksByHash(startHash string, limit uint64) ([]*tsdb.RawBlo
 	return nil, fmt.Errorf("GetBlocks: not implemented")
 }
 
-func (node Node) getCurrentDevRewards() ([]uint64, error) {
-	devRewards := []uint64{}
+func (node Node) getCurrentFundingRewards() ([]uint64, error) {
+	// node built coinbases always put the miner output first,
+	// so there is no need to know the funding amounts
+	if node.params.TemplateCoinbase {
+		return nil, nil
+	}
+
 	_, template, err := node.getBlockTemplate()
 	if err != nil {
 		return nil, err
 	}
-	if template.CumulusFluxnodeAddress != "" && template.CumulusFluxnodePayout != 0 {
-		devRewards = append(devRewards, template.CumulusFluxnodePayout)
-	}
-	if template.NimbusFluxnodeAddress != "" && template.NimbusFluxnodePayout != 0 {
-		devRewards = append(devRewards, template.NimbusFluxnodePayout)
-	}
-	if template.StratusFluxnodeAddress != "" && template.StratusFluxnodePayout != 0 {
-		devRewards = append(devRewards, template.StratusFluxnodePayout)
-	}
 
-	sort.Slice(devRewards, func(i, j int) bool {
-		return devRewards[i] < devRewards[j]
+	_, fundingRewards := node.params.FundingOutputs(template)
+	sort.Slice(fundingRewards, func(i, j int) bool {
+		return fundingRewards[i] < fundingRewards[j]
 	})
 
-	if len(devRewards) != len(node.devWalletAmounts) {
-		return nil, fmt.Errorf("dev rewards mismatch: have %v, want %v", devRewards, node.devWalletAmounts)
+	if len(fundingRewards) != len(node.fundingAmounts) {
+		return nil, fmt.Errorf("funding rewards mismatch: have %v, want %v", fundingRewards, node.fundingAmounts)
 	}
 
-	for i, devReward := range devRewards {
-		if devReward != node.devWalletAmounts[i] {
-			return nil, fmt.Errorf("dev rewards mismatch: have %v, want %v", devRewards, node.devWalletAmounts)
+	for i, fundingReward := range fundingRewards {
+		if fundingReward != node.fundingAmounts[i] {
+			return nil, fmt.Errorf("funding rewards mismatch: have %v, want %v", fundingRewards, node.fundingAmounts)
 		}
 	}
 
-	return devRewards, nil
+	return fundingRewards, nil
 }
 
-func (node Node) getRewardsFromTX(tx *Transaction, devRewards []uint64) (uint64, error) {
+// getRewardsFromTX sums the coinbase outputs that reach the pool wallet. for self-paid
+// (true solo) rounds only the fee output paid to the pool address is counted.
+func (node Node) getRewardsFromTX(tx *Transaction, devRewards []uint64, selfPaid bool) (uint64, error) {
+	// the miner output is always first in node built coinbases, self-paid
+	// rounds fall through to summing the outputs paid to the pool address
+	if node.params.TemplateCoinbase && !selfPaid {
+		if len(tx.Outputs) == 0 {
+			return 0, fmt.Errorf("no outputs in coinbase %s", tx.TxID)
+		}
+
+		valBig, err := common.StringDecimalToBigint(tx.Outputs[0].Value.String(), node.GetUnits().Big())
+		if err != nil {
+			return 0, err
+		}
+
+		return valBig.Uint64(), nil
+	}
+
 	// copy dev rewards to avoid overwriting the slice
 	devRewardsCopy := make([]uint64, len(devRewards))
 	for i, devReward := range devRewards {
@@ -164,9 +196,24 @@ func (node Node) getRewardsFromTX(tx *Transaction, devRewards []uint64) (uint64,
 	for _, input := range tx.Inputs {
 		if len(input.Coinbase) > 0 {
 			for _, out := range tx.Outputs {
+				var address string
+				if len(out.ScriptPubKey.Addresses) > 0 {
+					address = out.ScriptPubKey.Addresses[0]
+				}
+
+				// extra outputs from the coinbase policy never reach the pool wallet
+				if selfPaid && address != node.address {
+					continue
+				} else if !selfPaid && node.coinbasePolicy.IsExtraAddress(address) {
+					continue
+				}
+
 				valBig, err := common.StringDecimalToBigint(out.Value.String(), node.GetUnits().Big())
 				if err != nil {
 					return amount, err
+				} else if selfPaid {
+					amount += valBig.Uint64()
+					continue
 				}
 
 				var isReward bool
@@ -188,31 +235,118 @@ func (node Node) getRewardsFromTX(tx *Transaction, devRewards []uint64) (uint64,
 	return amount, nil
 }
 
-func (node Node) parseBlockTemplate(template *BlockTemplate) (*types.StratumJob, error) {
-	addresses := []string{node.address}
-	amounts := []uint64{template.MinerReward}
+// getTemplateCoinbase returns the coinbase built by the node, with the miner output
+// split and the signature added according to the coinbase policy.
+func (node Node) getTemplateCoinbase(
+	template *BlockTemplate,
+	policy *types.CoinbasePolicy,
+) ([]byte, []byte, error) {
+	if template.CoinbaseTxn == nil {
+		return nil, nil, fmt.Errorf("no coinbasetxn in block template")
+	}
+
+	// the pool can't rebuild a shielded coinbase, so the node has to be
+	// configured with the pool's transparent address as its mineraddress
+	minerReward, script, err := ParseCoinbaseOutput(template.CoinbaseTxn.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid coinbasetxn: %v", err)
+	} else if !bytes.HasPrefix(script, node.outputScript) {
+		return nil, nil, fmt.Errorf("coinbasetxn does not pay to %s, set it as the transparent mineraddress", node.address)
+	}
+
+	if policy.GetSignature() != "" || len(policy.ExtraOutputs) > 0 || policy.GetPayoutAddress(node.address) != node.address {
+		minerReward, extraAddresses, extraAmounts := policy.SplitReward(minerReward)
+		addresses := append([]string{policy.GetPayoutAddress(node.address)}, extraAddresses...)
+		amounts := append([]uint64{minerReward}, extraAmounts...)
+		coinbaseHex, coinbaseHash, err := RewriteCoinbase(template.CoinbaseTxn.Data, node.outputScript,
+			addresses, amounts, policy.GetSignature(), node.prefixP2PKH, node.prefixP2SH)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid coinbasetxn: %v", err)
+		}
+
+		return coinbaseHex, coinbaseHash, nil
+	}
+
+	coinbaseHex, err := hex.DecodeString(template.CoinbaseTxn.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txid := template.CoinbaseTxn.TxID
+	if txid == "" {
+		txid = template.CoinbaseTxn.Hash
+	}
+
+	coinbaseHash, err := hex.DecodeString(txid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return coinbaseHex, coinbaseHash, nil
+}
+
+// SetCoinbasePolicy sets the coinbase policy for pool generated coinbases. Chains
+// using the node's template coinbase apply it by rewriting the miner output.
+func (node *Node) SetCoinbasePolicy(policy *types.CoinbasePolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	for _, output := range policy.ExtraOutputs {
+		if !node.ValidateAddress(output.Address) {
+			return fmt.Errorf("invalid coinbase output address: %s", output.Address)
+		}
+	}
+
+	node.coinbasePolicy = policy
+
+	return nil
+}
 
-	if template.CumulusFluxnodeAddress != "" && template.CumulusFluxnodePayout != 0 {
-		addresses = append(addresses, template.CumulusFluxnodeAddress)
-		amounts = append(amounts, template.CumulusFluxnodePayout)
+func (node Node) GetSoloJob(job *types.StratumJob, address string, feeShare uint64) (*types.StratumJob, error) {
+	template, ok := job.Data.(*BlockTemplate)
+	if !ok {
+		return nil, fmt.Errorf("no block template for job %s", job.ID)
 	}
 
-	if template.NimbusFluxnodeAddress != "" && template.NimbusFluxnodePayout != 0 {
-		addresses = append(addresses, template.NimbusFluxnodeAddress)
-		amounts = append(amounts, template.NimbusFluxnodePayout)
+	policy := node.coinbasePolicy.ForSoloMiner(address, node.address, feeShare)
+	soloJob, err := node.parseBlockTemplate(template, policy)
+	if err != nil {
+		return nil, err
 	}
+	soloJob.HostID = job.HostID
+
+	return soloJob, nil
+}
 
-	if template.StratusFluxnodeAddress != "" && template.StratusFluxnodePayout != 0 {
-		addresses = append(addresses, template.StratusFluxnodeAddress)
-		amounts = append(amounts, template.StratusFluxnodePayout)
+func (node Node) parseBlockTemplate(
+	template *BlockTemplate,
+	policy *types.CoinbasePolicy,
+) (*types.StratumJob, error) {
+	var coinbaseHex, coinbaseHash []byte
+	var err error
+	if node.params.TemplateCoinbase {
+		coinbaseHex, coinbaseHash, err = node.getTemplateCoinbase(template, policy)
+	} else {
+		minerReward, extraAddresses, extraAmounts := policy.SplitReward(template.MinerReward)
+		addresses, amounts := node.params.FundingOutputs(template)
+		addresses = append([]string{policy.GetPayoutAddress(node.address)}, addresses...)
+		amounts = append([]uint64{minerReward}, amounts...)
+		addresses = append(addresses, extraAddresses...)
+		amounts = append(amounts, extraAmounts...)
+		coinbaseHex, coinbaseHash, err = GenerateCoinbase(addresses, amounts, template.Height,
+			policy.GetSignature(), node.prefixP2PKH, node.prefixP2SH)
 	}
 
-	coinbaseHex, coinbaseHash, err := GenerateCoinbase(addresses, amounts, template.Height,
-		"", node.prefixP2PKH, node.prefixP2SH)
 	if err != nil {
 		return nil, err
 	}
 
+	commitment := node.params.HeaderCommitment(template)
+	if commitment == "" {
+		commitment = emptyCommitment
+	}
+
 	txHashes := [][]byte{coinbaseHash}
 	txHexes := [][]byte{coinbaseHex}
 	for _, tx := range template.Transactions {
@@ -236,7 +370,7 @@ func (node Node) parseBlockTemplate(template *BlockTemplate) (*types.StratumJob,
 	}
 
 	builder, err := blkbuilder.NewEquihashBuilder(template.Version, template.CurTime, template.Bits,
-		template.PreviousBlockHash, template.FinalSaplingRootHash, txHashes, txHexes)
+		template.PreviousBlockHash, commitment, txHashes, txHexes)
 	if err != nil {
 		return nil, err
 	}
@@ -251,6 +385,7 @@ func (node Node) parseBlockTemplate(template *BlockTemplate) (*types.StratumJob,
 		Height:       new(types.Number).SetFromValue(template.Height),
 		Difficulty:   new(types.Difficulty).SetFromBits(uint32(bits), node.GetMaxDifficulty()),
 		BlockBuilder: builder,
+		Data:         template,
 	}
 
 	return job, nil
@@ -276,7 +411,7 @@ func (node Node) JobNotify(ctx context.Context, interval time.Duration) chan *ty
 				if err != nil {
 					node.logger.Error(err)
 				} else if lastHeight != template.Height || now.After(lastJob.Add(staticInterval)) {
-					job, err := node.parseBlockTemplate(template)
+					job, err := node.parseBlockTemplate(template, node.coinbasePolicy)
 					if err != nil {
 						node.logger.Error(err)
 					} else {
@@ -303,7 +438,7 @@ func (node Node) SubmitWork(
 		return types.InvalidShare, nil, nil, err
 	}
 
-	validSolution, err := node.pow.Verify(header, work.EquihashSolution[1:])
+	validSolution, err := node.pow.Verify(header, work.EquihashSolution[node.params.solutionPrefixSize():])
 	if err != nil {
 		return types.InvalidShare, nil, nil, err
 	} else if !validSolution {
@@ -356,10 +491,11 @@ func (node Node) ParseWork(data []json.RawMessage, extraNonce string) (*types.St
 		return nil, err
 	}
 
+	solnLength := (node.params.solutionPrefixSize() + node.params.solutionSize()) * 2
 	var nonce, soln string
-	if err := json.Unmarshal(data[3], &nonce); err != nil || len(nonce) != 56 {
+	if err := json.Unmarshal(data[3], &nonce); err != nil || len(extraNonce)+len(nonce) != 64 {
 		return nil, fmt.Errorf("invalid nonce parameter")
-	} else if err := json.Unmarshal(data[4], &soln); err != nil || len(soln) != 106 {
+	} else if err := json.Unmarshal(data[4], &soln); err != nil || len(soln) != solnLength {
 		return nil, fmt.Errorf("invalid hash parameter")
 	}
 
@@ -391,7 +527,8 @@ func (node Node) MarshalJob(
 ) (interface{}, error) {
 	partialJob := job.BlockBuilder.PartialJob()
 	result := append([]interface{}{job.ID}, partialJob...)
-	result = append(result, cleanJobs, "125_4", "ZelProof")
+	result = append(result, cleanJobs)
+	result = append(result, node.params.NotifyAlgo...)
 
 	return rpc.NewRequestWithID(id, "mining.notify", result...)
 }
@@ -409,8 +546,8 @@ func (node Node) GetSubscribeResponses(id []byte, clientID, extraNonce string) (
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
-	res, err := node.GetSetDifficultyResponse(diffFactor)
+func (node Node) GetAuthorizeResponses(clientType, diffFactor int) ([]interface{}, error) {
+	res, err := node.GetSetDifficultyResponse(clientType, diffFactor)
 	if err != nil {
 		return nil, err
 	}
@@ -418,7 +555,7 @@ func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetSetDifficultyResponse(diffFactor int) (interface{}, error) {
+func (node Node) GetSetDifficultyResponse(clientType, diffFactor int) (interface{}, error) {
 	return rpc.NewRequest("mining.set_target", node.GetShareDifficulty(diffFactor).TargetHex())
 }
 
@@ -456,12 +593,12 @@ func (node Node) UnlockRound(round *pooldb.Round) error {
 			return nil
 		}
 
-		devRewards, err := node.getCurrentDevRewards()
+		fundingRewards, err := node.getCurrentFundingRewards()
 		if err != nil {
 			return err
 		}
 
-		value, err := node.getRewardsFromTX(block.Transactions[0], devRewards)
+		value, err := node.getRewardsFromTX(block.Transactions[0], fundingRewards, round.SelfPaid)
 		if err != nil {
 			return err
 		}
@@ -474,6 +611,18 @@ func (node Node) UnlockRound(round *pooldb.Round) error {
 	return nil
 }
 
+// getFeeOutputIndex finds the index of the coinbase output paying the pool
+// address, which for self-paid (true solo) rounds is the pool fee output.
+func (node Node) getFeeOutputIndex(tx *Transaction) (uint32, error) {
+	for i, out := range tx.Outputs {
+		if len(out.ScriptPubKey.Addresses) > 0 && out.ScriptPubKey.Addresses[0] == node.address {
+			return uint32(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("no fee output in coinbase %s", tx.TxID)
+}
+
 func (node Node) MatureRound(round *pooldb.Round) ([]*pooldb.UTXO, error) {
 	if round.Pending || round.Orphan || round.Mature {
 		return nil, nil
@@ -495,6 +644,18 @@ func (node Node) MatureRound(round *pooldb.Round) ([]*pooldb.UTXO, error) {
 		return nil, nil
 	}
 
+	var index uint32
+	if round.SelfPaid {
+		if len(block.Transactions) == 0 {
+			return nil, fmt.Errorf("no transactions in block for round %d", round.ID)
+		}
+
+		index, err = node.getFeeOutputIndex(block.Transactions[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	round.Mature = true
 
 	utxos := []*pooldb.UTXO{
@@ -502,7 +663,7 @@ func (node Node) MatureRound(round *pooldb.Round) ([]*pooldb.UTXO, error) {
 			ChainID: round.ChainID,
 			Value:   round.Value,
 			TxID:    types.StringValue(round.CoinbaseTxID),
-			Index:   0,
+			Index:   index,
 			Active:  true,
 			Spent:   false,
 		},
@@ -35,3 +35,7 @@ func SubmitBlock(hostID, block string) *rpc.Response {
 func SendRawTransaction(tx string) *rpc.Response {
 	return nil
 }
+
+func VerifyMessage(address, signature, message string) *rpc.Response {
+	return rpc.NewResponseFromJSON(nil, []byte(`true`))
+}
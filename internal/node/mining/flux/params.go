@@ -0,0 +1,63 @@
+package flux
+
+// Params describes an Equihash (Zcash-family) network. Flux builds its own
+// coinbase from the fluxnode payouts in the block template, zcashd derived
+// nodes are expected to build the coinbase themselves (paying to a transparent
+// -mineraddress) since funding streams can't be reproduced by the pool. The
+// pool only rewrites the miner output of those coinbases.
+type Params struct {
+	Name  string
+	Chain string
+	Port  int
+
+	// equihash parameters
+	N               uint32
+	K               uint32
+	Personalization string
+	Twist           bool
+	// algo identifiers appended to mining.notify (only used by flux miners)
+	NotifyAlgo []interface{}
+
+	MainnetPrefixP2PKH []byte
+	MainnetPrefixP2SH  []byte
+	TestnetPrefixP2PKH []byte
+	TestnetPrefixP2SH  []byte
+
+	// when set, the coinbase provided by the node (coinbasetxn) is used with only
+	// the miner output rewritten, otherwise the coinbase is generated from FundingOutputs
+	TemplateCoinbase bool
+	// funding outputs (dev fund, treasury, node payouts) that have to be
+	// included in a pool generated coinbase
+	FundingOutputs func(*BlockTemplate) ([]string, []uint64)
+	// expected funding output amounts, used to separate the miner reward
+	// from the funding outputs in historical blocks
+	MainnetFundingAmounts []uint64
+	TestnetFundingAmounts []uint64
+	// the header field between the merkle root and the timestamp
+	// (final sapling root, block commitments hash, etc.)
+	HeaderCommitment func(*BlockTemplate) string
+
+	// transparent outputs require replay protection (OP_CHECKBLOCKATHEIGHT)
+	ReplayProtection bool
+	// the message magic used by signmessage
+	MessagePrefix string
+
+	BlockExplorerURL        string
+	TestnetBlockExplorerURL string
+	TxExplorerURL           string
+	AddressExplorerURL      string
+	// insight api used for balance lookups
+	InsightAPIURL string
+}
+
+func (p *Params) solutionSize() int {
+	size := (1 << p.K) * (int(p.N)/(int(p.K)+1) + 1) / 8
+	return size
+}
+
+func (p *Params) solutionPrefixSize() int {
+	if p.solutionSize() < 0xfd {
+		return 1
+	}
+	return 3
+}
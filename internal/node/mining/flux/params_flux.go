@@ -0,0 +1,51 @@
+package flux
+
+var FLUX = &Params{
+	Name:  "Flux",
+	Chain: "FLUX",
+	Port:  16124,
+
+	N:               125,
+	K:               4,
+	Personalization: "ZelProof",
+	Twist:           true,
+	NotifyAlgo:      []interface{}{"125_4", "ZelProof"},
+
+	MainnetPrefixP2PKH: []byte{0x1C, 0xB8},
+	MainnetPrefixP2SH:  []byte{0x1C, 0xBD},
+	TestnetPrefixP2PKH: []byte{0x1D, 0x25},
+	TestnetPrefixP2SH:  []byte{0x1C, 0xBA},
+
+	FundingOutputs: func(template *BlockTemplate) ([]string, []uint64) {
+		var addresses []string
+		var amounts []uint64
+		if template.CumulusFluxnodeAddress != "" && template.CumulusFluxnodePayout != 0 {
+			addresses = append(addresses, template.CumulusFluxnodeAddress)
+			amounts = append(amounts, template.CumulusFluxnodePayout)
+		}
+
+		if template.NimbusFluxnodeAddress != "" && template.NimbusFluxnodePayout != 0 {
+			addresses = append(addresses, template.NimbusFluxnodeAddress)
+			amounts = append(amounts, template.NimbusFluxnodePayout)
+		}
+
+		if template.StratusFluxnodeAddress != "" && template.StratusFluxnodePayout != 0 {
+			addresses = append(addresses, template.StratusFluxnodeAddress)
+			amounts = append(amounts, template.StratusFluxnodePayout)
+		}
+
+		return addresses, amounts
+	},
+	MainnetFundingAmounts: []uint64{281250000, 468750000, 1125000000},
+	TestnetFundingAmounts: []uint64{562500000, 937500000, 2250000000},
+	HeaderCommitment: func(template *BlockTemplate) string {
+		return template.FinalSaplingRootHash
+	},
+	MessagePrefix: "Zelcash Signed Message:\n",
+
+	BlockExplorerURL:        "https://explorer.runonflux.io/block/%s",
+	TestnetBlockExplorerURL: "https://testnet.runonflux.io/block/%s",
+	TxExplorerURL:           "https://explorer.runonflux.io/tx/%s",
+	AddressExplorerURL:      "https://explorer.runonflux.io/address/%s",
+	InsightAPIURL:           "https://explorer.runonflux.io/api",
+}
@@ -0,0 +1,30 @@
+package flux
+
+var ZEN = &Params{
+	Name:  "Horizen",
+	Chain: "ZEN",
+	Port:  8231,
+
+	N:               200,
+	K:               9,
+	Personalization: "ZcashPoW",
+
+	MainnetPrefixP2PKH: []byte{0x20, 0x89},
+	MainnetPrefixP2SH:  []byte{0x20, 0x96},
+	TestnetPrefixP2PKH: []byte{0x20, 0x98},
+	TestnetPrefixP2SH:  []byte{0x20, 0x92},
+
+	// treasury and secure/super node outputs are added by zend
+	TemplateCoinbase: true,
+	HeaderCommitment: func(template *BlockTemplate) string {
+		return template.ScTxsCommitment
+	},
+	ReplayProtection: true,
+	MessagePrefix:    "Zcash Signed Message:\n",
+
+	BlockExplorerURL:        "https://explorer.horizen.io/block/%s",
+	TestnetBlockExplorerURL: "https://explorer-testnet.horizen.io/block/%s",
+	TxExplorerURL:           "https://explorer.horizen.io/tx/%s",
+	AddressExplorerURL:      "https://explorer.horizen.io/address/%s",
+	InsightAPIURL:           "https://explorer.horizen.io/api",
+}
@@ -13,11 +13,11 @@ import (
 )
 
 func (node Node) GetTxExplorerURL(txid string) string {
-	return "https://explorer.runonflux.io/tx/" + txid
+	return fmt.Sprintf(node.params.TxExplorerURL, txid)
 }
 
 func (node Node) GetAddressExplorerURL(address string) string {
-	return "https://explorer.runonflux.io/address/" + address
+	return fmt.Sprintf(node.params.AddressExplorerURL, address)
 }
 
 func (node Node) GetBalance() (*big.Int, error) {
@@ -25,7 +25,7 @@ func (node Node) GetBalance() (*big.Int, error) {
 		Balance uint64 `json:"balanceSat"`
 	}
 
-	url := "https://explorer.runonflux.io/api/addr/" + node.address + "/?noTxList=1"
+	url := node.params.InsightAPIURL + "/addr/" + node.address + "/?noTxList=1"
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -79,16 +79,33 @@ func (node Node) CreateTx(inputs []*types.TxInput, outputs []*types.TxOutput) (s
 		return "", "", err
 	}
 
-	baseTx := btctx.NewTransaction(txVersion, 0, node.prefixP2PKH, node.prefixP2SH, false)
-	baseTx.SetVersionMask(versionMask)
-	baseTx.SetVersionGroupID(versionGroupID)
-	baseTx.SetExpiryHeight(uint32(height + expiryHeight))
+	var baseTx *btctx.Transaction
+	if node.params.ReplayProtection {
+		// zend never adopted overwinter, transparent transactions are still v1
+		baseTx = btctx.NewTransaction(1, 0, node.prefixP2PKH, node.prefixP2SH, false)
+	} else {
+		baseTx = btctx.NewTransaction(txVersion, 0, node.prefixP2PKH, node.prefixP2SH, false)
+		baseTx.SetVersionMask(versionMask)
+		baseTx.SetVersionGroupID(versionGroupID)
+		baseTx.SetExpiryHeight(uint32(height + expiryHeight))
+	}
 
 	rawTx, err := btctx.GenerateRawTx(baseTx, inputs, outputs, feeRate)
 	if err != nil {
 		return "", "", err
 	}
 
+	if node.params.ReplayProtection {
+		scriptSuffix, err := node.getReplayProtectionScript(height)
+		if err != nil {
+			return "", "", err
+		}
+
+		for _, output := range rawTx.Outputs {
+			output.Script = append(output.Script, scriptSuffix...)
+		}
+	}
+
 	rawTxSerialized, err := rawTx.Serialize(nil)
 	if err != nil {
 		return "", "", err
@@ -103,6 +120,40 @@ func (node Node) CreateTx(inputs []*types.TxInput, outputs []*types.TxOutput) (s
 	return txid, tx, nil
 }
 
+// getReplayProtectionScript returns the OP_CHECKBLOCKATHEIGHT suffix for output scripts,
+// referencing the block replayProtectionDepth blocks below the tip like zend's wallet does.
+func (node Node) getReplayProtectionScript(height uint64) ([]byte, error) {
+	var refHeight uint64
+	if height > replayProtectionDepth {
+		refHeight = height - replayProtectionDepth
+	}
+
+	refHash, err := node.getBlockHash(refHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompileReplayProtection(refHash, refHeight)
+}
+
 func (node Node) BroadcastTx(tx string) (string, error) {
 	return node.sendRawTransaction(tx)
 }
+
+// SignMessage signs the message in the "signmessage" format. The message magic differs between
+// forks, so the signature is verified by the node before it is published.
+func (node Node) SignMessage(message string) (string, error) {
+	signature, err := btctx.SignMessageWithPrefix(node.privKey, node.params.MessagePrefix, message)
+	if err != nil {
+		return "", err
+	}
+
+	valid, err := node.verifyMessage(node.address, signature, message)
+	if err != nil {
+		return "", err
+	} else if !valid {
+		return "", fmt.Errorf("message signature rejected by node")
+	}
+
+	return signature, nil
+}
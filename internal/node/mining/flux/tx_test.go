@@ -0,0 +1,50 @@
+package flux
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/magicpool-co/pool/pkg/crypto/tx/btctx"
+)
+
+func TestSignMessage(t *testing.T) {
+	privBytes, err := hex.DecodeString("ab3f4f0d4b1a5b8ad9b8f4d5e0e7d9a9a44ff0b7f3c9cf86a0f5b9a1c6a3e2d1")
+	if err != nil {
+		t.Fatalf("failed to decode priv key: %v", err)
+	}
+	privKey := secp256k1.PrivKeyFromBytes(privBytes)
+
+	tests := []struct {
+		params *Params
+		prefix string
+	}{
+		{params: FLUX, prefix: "Zelcash Signed Message:\n"},
+		{params: ZEN, prefix: "Zcash Signed Message:\n"},
+	}
+
+	for i, tt := range tests {
+		message := "magicpool proof of reserves for " + tt.params.Chain
+		node := Node{
+			params:  tt.params,
+			mocked:  true,
+			address: btctx.PrivKeyToAddress(privKey, tt.params.MainnetPrefixP2PKH),
+			privKey: privKey,
+		}
+
+		sig, err := node.SignMessage(message)
+		if err != nil {
+			t.Errorf("failed on %d: sign: %v", i, err)
+			continue
+		}
+
+		address, err := btctx.RecoverMessageAddressWithPrefix(tt.prefix,
+			message, sig, tt.params.MainnetPrefixP2PKH)
+		if err != nil {
+			t.Errorf("failed on %d: recover: %v", i, err)
+		} else if address != node.address {
+			t.Errorf("failed on %d: address mismatch: have %s, want %s", i, address, node.address)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/goccy/go-json"
@@ -16,16 +17,7 @@ import (
 	"github.com/magicpool-co/pool/pkg/hostpool"
 	"github.com/magicpool-co/pool/pkg/sshtunnel"
 	"github.com/magicpool-co/pool/pkg/stratum/rpc"
-)
-
-var (
-	mainnetPrefixP2PKH      = []byte{0x1C, 0xB8}
-	mainnetPrefixP2SH       = []byte{0x1C, 0xBD}
-	mainnetDevWalletAmounts = []uint64{281250000, 468750000, 1125000000}
-
-	testnetPrefixP2PKH      = []byte{0x1D, 0x25}
-	testnetPrefixP2SH       = []byte{0x1C, 0xBA}
-	testnetDevWalletAmounts = []uint64{562500000, 937500000, 2250000000}
+	"github.com/magicpool-co/pool/types"
 )
 
 func privKeyToWIFUncompressed(privKey *secp256k1.PrivateKey) string {
@@ -37,12 +29,13 @@ func privKeyToWIFUncompressed(privKey *secp256k1.PrivateKey) string {
 }
 
 func generateHost(
+	params *Params,
 	urls []string,
 	logger *log.Logger,
 	tunnel *sshtunnel.SSHTunnel,
 ) (*hostpool.HTTPPool, error) {
 	var (
-		port        = 16124
+		port        = params.Port
 		hostOptions = &hostpool.HTTPHostOptions{
 			Username: "rpc",
 			Password: "rpc",
@@ -53,6 +46,12 @@ func generateHost(
 				Method:  "getbestblockhash",
 			},
 		}
+		hostRequestPolicy = &hostpool.RequestPolicy{
+			MethodTimeouts: map[string]time.Duration{
+				"getblocktemplate": time.Second * 5,
+				"submitblock":      time.Second * 30,
+			},
+		}
 	)
 
 	if len(urls) == 0 {
@@ -60,6 +59,7 @@ func generateHost(
 	}
 
 	host := hostpool.NewHTTPPool(context.Background(), logger, hostHealthCheck, tunnel)
+	host.SetRequestPolicy(hostRequestPolicy)
 	for _, url := range urls {
 		err := host.AddHost(url, port, hostOptions)
 		if err != nil {
@@ -71,22 +71,23 @@ func generateHost(
 }
 
 func New(
+	params *Params,
 	mainnet bool,
 	urls []string,
 	rawPriv string,
 	logger *log.Logger,
 	tunnel *sshtunnel.SSHTunnel,
 ) (*Node, error) {
-	prefixP2PKH := mainnetPrefixP2PKH
-	prefixP2SH := mainnetPrefixP2SH
-	devWalletAmounts := mainnetDevWalletAmounts
+	prefixP2PKH := params.MainnetPrefixP2PKH
+	prefixP2SH := params.MainnetPrefixP2SH
+	fundingAmounts := params.MainnetFundingAmounts
 	if !mainnet {
-		prefixP2PKH = testnetPrefixP2PKH
-		prefixP2SH = testnetPrefixP2SH
-		devWalletAmounts = testnetDevWalletAmounts
+		prefixP2PKH = params.TestnetPrefixP2PKH
+		prefixP2SH = params.TestnetPrefixP2SH
+		fundingAmounts = params.TestnetFundingAmounts
 	}
 
-	host, err := generateHost(urls, logger, tunnel)
+	host, err := generateHost(params, urls, logger, tunnel)
 	if err != nil {
 		return nil, err
 	}
@@ -98,37 +99,46 @@ func New(
 
 	privKey := secp256k1.PrivKeyFromBytes(obscuredPriv)
 	address := btctx.PrivKeyToAddress(privKey, prefixP2PKH)
+	outputScript, err := btctx.AddressToScript(address, prefixP2PKH, prefixP2SH, false)
+	if err != nil {
+		return nil, err
+	}
 	wif := privKeyToWIFUncompressed(privKey)
 
 	node := &Node{
-		mocked:           host == nil,
-		mainnet:          mainnet,
-		prefixP2PKH:      prefixP2PKH,
-		prefixP2SH:       prefixP2SH,
-		devWalletAmounts: devWalletAmounts,
-		address:          address,
-		wif:              wif,
-		privKey:          privKey,
-		rpcHost:          host,
-		pow:              equihash.NewFlux(),
-		logger:           logger,
+		params:         params,
+		mocked:         host == nil,
+		mainnet:        mainnet,
+		prefixP2PKH:    prefixP2PKH,
+		prefixP2SH:     prefixP2SH,
+		fundingAmounts: fundingAmounts,
+		address:        address,
+		outputScript:   outputScript,
+		wif:            wif,
+		privKey:        privKey,
+		rpcHost:        host,
+		pow:            equihash.New(params.N, params.K, params.Personalization, params.Twist),
+		logger:         logger,
 	}
 
 	return node, nil
 }
 
 type Node struct {
-	mocked           bool
-	mainnet          bool
-	prefixP2PKH      []byte
-	prefixP2SH       []byte
-	devWalletAmounts []uint64
-	address          string
-	wif              string
-	privKey          *secp256k1.PrivateKey
-	rpcHost          *hostpool.HTTPPool
-	pow              *equihash.Client
-	logger           *log.Logger
+	params         *Params
+	mocked         bool
+	mainnet        bool
+	prefixP2PKH    []byte
+	prefixP2SH     []byte
+	fundingAmounts []uint64
+	address        string
+	outputScript   []byte
+	wif            string
+	privKey        *secp256k1.PrivateKey
+	rpcHost        *hostpool.HTTPPool
+	pow            *equihash.Client
+	coinbasePolicy *types.CoinbasePolicy
+	logger         *log.Logger
 }
 
 func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Request) {
@@ -142,6 +152,18 @@ func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Reque
 	node.rpcHost.HandleInfoRequest(w, r)
 }
 
+func (node *Node) SetRequestHandler(handler hostpool.RequestHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetRequestHandler(handler)
+	}
+}
+
+func (node *Node) SetBroadcastHandler(handler hostpool.BroadcastHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetBroadcastHandler(handler)
+	}
+}
+
 type BlockchainInfo struct {
 	Chain                string  `json:"chain"`
 	Blocks               uint64  `json:"blocks"`
@@ -179,7 +201,9 @@ type BlockTemplate struct {
 	VBRequired           int            `json:"vbrequired"`
 	PreviousBlockHash    string         `json:"previousblockhash"`
 	FinalSaplingRootHash string         `json:"finalsaplingroothash"`
+	ScTxsCommitment      string         `json:"scTxsCommitment"`
 	Transactions         []*Transaction `json:"transactions"`
+	CoinbaseTxn          *Transaction   `json:"coinbasetxn"`
 	CoinbaseAux          struct {
 		Flags string `json:"flags"`
 	} `json:"coinbaseaux"`
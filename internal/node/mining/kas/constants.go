@@ -26,11 +26,11 @@ var (
 )
 
 func (node Node) Name() string {
-	return "Kaspa"
+	return node.params.Name
 }
 
 func (node Node) Chain() string {
-	return "KAS"
+	return node.params.Chain
 }
 
 func (node Node) Address() string {
@@ -42,7 +42,7 @@ func (node Node) GetAccountingType() types.AccountingType {
 }
 
 func (node Node) GetAddressPrefix() string {
-	return "kaspa"
+	return node.params.MainnetPrefix
 }
 
 func (node Node) Mocked() bool {
@@ -87,8 +87,8 @@ func (node Node) CalculateHashrate(blockTime, difficulty float64) float64 {
 	return (4 * difficulty) / blockTime
 }
 
-func ValidateAddress(address string) bool {
-	_, err := kastx.AddressToScript(address, mainnetPrefix)
+func ValidateAddress(params *Params, address string) bool {
+	_, err := kastx.AddressToScript(address, params.MainnetPrefix)
 
 	return err == nil
 }
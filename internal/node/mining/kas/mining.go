@@ -38,9 +38,9 @@ var (
 
 func (node Node) GetBlockExplorerURL(round *pooldb.Round) string {
 	if node.mainnet {
-		return fmt.Sprintf("https://kgi.kaspad.net/?hash=%s", round.Hash)
+		return fmt.Sprintf(node.params.BlockExplorerURL, round.Hash)
 	}
-	return fmt.Sprintf("https://kgi-testnet.kaspad.net/?hash=%s", round.Hash)
+	return fmt.Sprintf(node.params.TestnetBlockExplorerURL, round.Hash)
 }
 
 func (node Node) getStatusByHost(hostID string) (uint64, bool, error) {
@@ -199,6 +199,15 @@ func (node Node) GetBlocksByHash(startHash string, limit uint64) ([]*tsdb.RawBlo
 		return nil, err
 	}
 
+	// with no prior blocks, start from the configured start hash or,
+	// if the network has none, from the pruning point of the tip
+	if startHash == "" {
+		startHash = node.params.StartHash
+		if startHash == "" {
+			startHash = endBlock.PruningPoint
+		}
+	}
+
 	startBlock, err := node.getBlock("", startHash, true)
 	if err != nil {
 		return nil, err
@@ -336,7 +345,12 @@ func (node Node) SubmitWork(
 		return types.InvalidShare, nil, nil, fmt.Errorf("unable to cast job data as block")
 	}
 
-	digest, err := node.pow.Compute(job.Header.Bytes(), template.Timestamp, work.Nonce.Value())
+	pow := node.params.PoW
+	if template.Version >= 2 && node.params.PoWV2 != nil {
+		pow = node.params.PoWV2
+	}
+
+	digest, err := pow.Compute(job.Header.Bytes(), template.Timestamp, work.Nonce.Value())
 	if err != nil {
 		return types.InvalidShare, nil, nil, err
 	}
@@ -468,8 +482,8 @@ func (node Node) GetSubscribeResponses(id []byte, clientID, extraNonce string) (
 	return []interface{}{res, extraNonceRes}, nil
 }
 
-func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
-	res, err := node.GetSetDifficultyResponse(diffFactor)
+func (node Node) GetAuthorizeResponses(clientType, diffFactor int) ([]interface{}, error) {
+	res, err := node.GetSetDifficultyResponse(clientType, diffFactor)
 	if err != nil {
 		return nil, err
 	}
@@ -477,7 +491,7 @@ func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetSetDifficultyResponse(diffFactor int) (interface{}, error) {
+func (node Node) GetSetDifficultyResponse(clientType, diffFactor int) (interface{}, error) {
 	return rpc.NewRequest("mining.set_difficulty", 16*diffFactor)
 }
 
@@ -0,0 +1,31 @@
+package kas
+
+// Params describes a kHeavyHash-family network. The node logic (blue score
+// walking, coinbase merging, stratum) is identical across forks, so adding a
+// new fork should only require a new params file.
+type Params struct {
+	Name          string
+	Chain         string
+	MainnetPrefix string
+	TestnetPrefix string
+	Port          int
+
+	// block used as the starting point for block collection, if empty
+	// the pruning point of the selected tip is used instead.
+	StartHash string
+
+	BlockExplorerURL        string
+	TestnetBlockExplorerURL string
+	TxExplorerURL           string
+	AddressExplorerURL      string
+	APIURL                  string
+
+	PoW PoW
+	// PoWV2 replaces PoW for blocks with a header version of 2 or
+	// later, if set (Karlsen's switch to KarlsenHashV2)
+	PoWV2 PoW
+}
+
+type PoW interface {
+	Compute(hash []byte, timestamp int64, nonce uint64) ([]byte, error)
+}
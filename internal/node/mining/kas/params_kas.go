@@ -0,0 +1,23 @@
+package kas
+
+import (
+	"github.com/sencha-dev/powkit/heavyhash"
+)
+
+var KAS = &Params{
+	Name:          "Kaspa",
+	Chain:         "KAS",
+	MainnetPrefix: "kaspa",
+	TestnetPrefix: "kaspatest",
+	Port:          16110,
+
+	StartHash: "05a17c707b3277534f7125e095772051d8d65962219f7c6d8342a125a5c0effa",
+
+	BlockExplorerURL:        "https://kgi.kaspad.net/?hash=%s",
+	TestnetBlockExplorerURL: "https://kgi-testnet.kaspad.net/?hash=%s",
+	TxExplorerURL:           "https://explorer.kaspa.org/txs/%s",
+	AddressExplorerURL:      "https://explorer.kaspa.org/addresses/%s",
+	APIURL:                  "https://api.kaspa.org",
+
+	PoW: heavyhash.NewKaspa(),
+}
@@ -0,0 +1,22 @@
+package kas
+
+import (
+	"github.com/magicpool-co/pool/pkg/crypto/khash"
+)
+
+var KLS = &Params{
+	Name:          "Karlsen",
+	Chain:         "KLS",
+	MainnetPrefix: "karlsen",
+	TestnetPrefix: "karlsentest",
+	Port:          42110,
+
+	BlockExplorerURL:        "https://explorer.karlsencoin.com/blocks/%s",
+	TestnetBlockExplorerURL: "https://explorer.karlsencoin.com/blocks/%s",
+	TxExplorerURL:           "https://explorer.karlsencoin.com/txs/%s",
+	AddressExplorerURL:      "https://explorer.karlsencoin.com/addresses/%s",
+	APIURL:                  "https://api.karlsencoin.com",
+
+	PoW:   khash.NewKarlsen(),
+	PoWV2: khash.NewKarlsenV2(),
+}
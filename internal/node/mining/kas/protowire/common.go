@@ -41,6 +41,12 @@ var (
 	}
 )
 
+// Method returns the command of the message, used by the host pool
+// for per-method timeouts and metrics.
+func (m *KaspadMessage) Method() string {
+	return m.getCmd()
+}
+
 func (m *KaspadMessage) getCmd() string {
 	if m == nil {
 		return CmdUnknown
@@ -2,6 +2,7 @@ package kas
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/magicpool-co/pool/internal/node/mining/kas/mock"
@@ -208,11 +209,46 @@ func (node Node) getBlockTemplate(extraData string) (*Block, string, error) {
 	return protowireToBlock(obj.Block), hostID, nil
 }
 
+func (node Node) execAsGRPCBroadcast(
+	hostID, method string,
+	req interface{},
+	validate func(*protowire.KaspadMessage) error,
+) (*protowire.KaspadMessage, string, error) {
+	res, hostID, err := node.grpcHost.ExecBroadcast(hostID, req, func(res interface{}) error {
+		msg, ok := res.(*protowire.KaspadMessage)
+		if !ok {
+			return fmt.Errorf("%s: unable to cast as KaspadMessage", method)
+		}
+
+		return validate(msg)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return res.(*protowire.KaspadMessage), hostID, nil
+}
+
+// submitBlock broadcasts the block to every synced host and the host that created
+// the template in parallel, the first host to accept the block wins. the
+// broadcast is only retried if no host explicitly rejected the block.
 func (node Node) submitBlock(hostID string, block *Block) error {
 	const method = "submitBlock"
 
 	var err error
 	if !node.mocked {
+		var rejected atomic.Bool
+		validate := func(res *protowire.KaspadMessage) error {
+			if obj := res.GetSubmitBlockResponse(); obj != nil {
+				if err := handleRPCError(method, obj.Error); err != nil {
+					rejected.Store(true)
+					return fmt.Errorf("%v: %s", err, obj.RejectReason.String())
+				}
+			}
+
+			return nil
+		}
+
 		for i := 0; i < 5; i++ {
 			req := &protowire.KaspadMessage{
 				Payload: &protowire.KaspadMessage_SubmitBlockRequest{
@@ -223,20 +259,14 @@ func (node Node) submitBlock(hostID string, block *Block) error {
 				},
 			}
 
-			var res *protowire.KaspadMessage
-			res, _, err = node.execAsGRPCSticky(hostID, method, req)
-			if err != nil {
-				time.Sleep(time.Millisecond * 100)
-				continue
-			}
-
-			if obj := res.GetSubmitBlockResponse(); obj != nil {
-				if err := handleRPCError(method, obj.Error); err != nil {
-					return fmt.Errorf("%v: %s", err, obj.RejectReason.String())
-				}
+			_, _, err = node.execAsGRPCBroadcast(hostID, method, req, validate)
+			if err == nil {
+				return nil
+			} else if rejected.Load() {
+				return err
 			}
 
-			return nil
+			time.Sleep(time.Millisecond * 100)
 		}
 	}
 
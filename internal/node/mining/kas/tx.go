@@ -17,11 +17,11 @@ import (
 )
 
 func (node Node) GetTxExplorerURL(txid string) string {
-	return "https://explorer.kaspa.org/txs/" + txid
+	return fmt.Sprintf(node.params.TxExplorerURL, txid)
 }
 
 func (node Node) GetAddressExplorerURL(address string) string {
-	return "https://explorer.kaspa.org/addresses/" + address
+	return fmt.Sprintf(node.params.AddressExplorerURL, address)
 }
 
 func (node Node) GetBalance() (*big.Int, error) {
@@ -46,7 +46,7 @@ func (node Node) GetTx(txid string) (*types.TxResponse, error) {
 		AcceptingBlockBlueScore int64    `json:"accepting_block_blue_score"`
 	}
 
-	url := fmt.Sprintf("https://api.kaspa.org/transactions/%s?inputs=false&outputs=false", txid)
+	url := fmt.Sprintf("%s/transactions/%s?inputs=false&outputs=false", node.params.APIURL, txid)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
-	"github.com/sencha-dev/powkit/heavyhash"
 
 	"github.com/magicpool-co/pool/internal/log"
 	"github.com/magicpool-co/pool/internal/node/mining/kas/protowire"
@@ -17,18 +16,14 @@ import (
 	"github.com/magicpool-co/pool/pkg/sshtunnel"
 )
 
-var (
-	mainnetPrefix = "kaspa"
-	testnetPrefix = "kaspatest"
-)
-
 func generateHost(
+	params *Params,
 	urls []string,
 	logger *log.Logger,
 	tunnel *sshtunnel.SSHTunnel,
 ) (*hostpool.GRPCPool, error) {
 	var (
-		port            = 16110
+		port            = params.Port
 		hostHealthCheck = &hostpool.GRPCHealthCheck{
 			Request: &protowire.KaspadMessage{
 				Payload: &protowire.KaspadMessage_GetSelectedTipHashRequest{
@@ -36,6 +31,12 @@ func generateHost(
 				},
 			},
 		}
+		hostRequestPolicy = &hostpool.RequestPolicy{
+			MethodTimeouts: map[string]time.Duration{
+				protowire.CmdGetBlockTemplate: time.Second * 5,
+				protowire.CmdSubmitBlock:      time.Second * 30,
+			},
+		}
 	)
 
 	if len(urls) == 0 {
@@ -47,6 +48,7 @@ func generateHost(
 	}
 
 	host := hostpool.NewGRPCPool(context.Background(), factory, logger, hostHealthCheck, tunnel)
+	host.SetRequestPolicy(hostRequestPolicy)
 	for _, url := range urls {
 		err := host.AddHost(url, port)
 		if err != nil {
@@ -58,18 +60,19 @@ func generateHost(
 }
 
 func New(
+	params *Params,
 	mainnet bool,
 	urls []string,
 	rawPriv string,
 	logger *log.Logger,
 	tunnel *sshtunnel.SSHTunnel,
 ) (*Node, error) {
-	prefix := mainnetPrefix
+	prefix := params.MainnetPrefix
 	if !mainnet {
-		prefix = testnetPrefix
+		prefix = params.TestnetPrefix
 	}
 
-	grpcHost, err := generateHost(urls, logger, tunnel)
+	grpcHost, err := generateHost(params, urls, logger, tunnel)
 	if err != nil {
 		return nil, err
 	}
@@ -87,13 +90,13 @@ func New(
 	}
 
 	node := &Node{
+		params:   params,
 		mocked:   grpcHost == nil,
 		mainnet:  mainnet,
 		prefix:   prefix,
 		address:  address,
 		privKey:  privKey,
 		grpcHost: grpcHost,
-		pow:      heavyhash.NewKaspa(),
 		logger:   logger,
 	}
 
@@ -101,13 +104,13 @@ func New(
 }
 
 type Node struct {
+	params   *Params
 	mocked   bool
 	mainnet  bool
 	prefix   string
 	address  string
 	privKey  *secp256k1.PrivateKey
 	grpcHost *hostpool.GRPCPool
-	pow      *heavyhash.Client
 	logger   *log.Logger
 }
 
@@ -122,6 +125,18 @@ func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Reque
 	node.grpcHost.HandleInfoRequest(w, r)
 }
 
+func (node *Node) SetRequestHandler(handler hostpool.RequestHandler) {
+	if node.grpcHost != nil {
+		node.grpcHost.SetRequestHandler(handler)
+	}
+}
+
+func (node *Node) SetBroadcastHandler(handler hostpool.BroadcastHandler) {
+	if node.grpcHost != nil {
+		node.grpcHost.SetBroadcastHandler(handler)
+	}
+}
+
 type TransactionOutpoint struct {
 	TransactionId string `json:"TransactionId"`
 	Index         uint32 `json:"Index"`
@@ -73,6 +73,19 @@ func (node Node) PingHosts() ([]string, []uint64, []bool, []error) {
 	return hostIDs, heights, statuses, errs
 }
 
+func (node Node) GetBlockHashes(start, end uint64) ([]string, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid range")
+	}
+
+	heights := make([]uint64, end-start+1)
+	for i := range heights {
+		heights[i] = start + uint64(i)
+	}
+
+	return node.getBlockHashMany(heights)
+}
+
 func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 	if start > end {
 		return nil, fmt.Errorf("invalid range")
@@ -147,6 +160,10 @@ func (node Node) getRewardsFromTX(tx *Transaction) (string, uint64, error) {
 	return txid, amount, nil
 }
 
+// getBlockTemplate builds a job from the node's mining candidate. the candidate only has the
+// header commitment, which already commits to the coinbase built by the node, so nexa can't
+// rebuild the coinbase for a solo miner's address and doesn't implement types.SoloJobNode
+// (pool.New rejects true solo for it).
 func (node Node) getBlockTemplate() (*types.StratumJob, error) {
 	hostID, candidate, err := node.getMiningCandidate()
 	if err != nil {
@@ -259,6 +276,10 @@ func (node Node) SubmitWork(
 		return types.AcceptedShare, hash, nil, err
 	}
 
+	if err := node.relayBlock(job.HostID, blockHash); err != nil {
+		node.logger.Error(fmt.Errorf("relay block: %v", err))
+	}
+
 	round := &pooldb.Round{
 		ChainID:    node.Chain(),
 		Height:     height,
@@ -384,8 +405,8 @@ func (node Node) GetSubscribeResponses(id []byte, clientID, extraNonce string) (
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
-	res, err := node.GetSetDifficultyResponse(diffFactor)
+func (node Node) GetAuthorizeResponses(clientType, diffFactor int) ([]interface{}, error) {
+	res, err := node.GetSetDifficultyResponse(clientType, diffFactor)
 	if err != nil {
 		return nil, err
 	}
@@ -393,7 +414,7 @@ func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetSetDifficultyResponse(diffFactor int) (interface{}, error) {
+func (node Node) GetSetDifficultyResponse(clientType, diffFactor int) (interface{}, error) {
 	return rpc.NewRequest("mining.set_difficulty", shareFactor*float64(diffFactor))
 }
 
@@ -219,6 +219,49 @@ func (node Node) submitMiningSolution(hostID string, id uint64, nonce string) (u
 	return result.Height, result.Hash, nil
 }
 
+func validateSubmitBlock(res *rpc.Response) error {
+	var result string
+	if err := json.Unmarshal(res.Result, &result); err != nil {
+		return err
+	} else if len(result) > 0 && result != "duplicate" {
+		return fmt.Errorf("submit block error: %s", result)
+	}
+
+	return nil
+}
+
+// relayBlock fetches the raw block from the host that accepted the mining solution
+// and relays it to every other synced host. mining candidates are specific to the
+// host that created them, so the solution itself can't be broadcast.
+func (node Node) relayBlock(hostID, hash string) error {
+	if node.mocked {
+		return nil
+	}
+
+	req, err := rpc.NewRequestWithHostID(hostID, "getblock", hash, 0)
+	if err != nil {
+		return err
+	}
+
+	res, err := node.rpcHost.ExecRPC(req)
+	if err != nil {
+		return err
+	}
+
+	var block string
+	if err := json.Unmarshal(res.Result, &block); err != nil {
+		return err
+	}
+
+	req, err = rpc.NewRequest("submitblock", block)
+	if err != nil {
+		return err
+	}
+	node.rpcHost.RelayRPC(req, hostID, validateSubmitBlock)
+
+	return nil
+}
+
 func (node Node) sendRawTransaction(tx string) (string, error) {
 	var res *rpc.Response
 	var err error
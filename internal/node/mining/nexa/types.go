@@ -3,6 +3,7 @@ package nexa
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/goccy/go-json"
@@ -38,6 +39,12 @@ func generateHost(
 				Method:  "getbestblockhash",
 			},
 		}
+		hostRequestPolicy = &hostpool.RequestPolicy{
+			MethodTimeouts: map[string]time.Duration{
+				"getminingcandidate":   time.Second * 5,
+				"submitminingsolution": time.Second * 30,
+			},
+		}
 	)
 
 	if len(urls) == 0 {
@@ -45,6 +52,7 @@ func generateHost(
 	}
 
 	host := hostpool.NewHTTPPool(context.Background(), logger, hostHealthCheck, tunnel)
+	host.SetRequestPolicy(hostRequestPolicy)
 	for _, url := range urls {
 		err := host.AddHost(url, port, hostOptions)
 		if err != nil {
@@ -119,6 +127,18 @@ func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Reque
 	node.rpcHost.HandleInfoRequest(w, r)
 }
 
+func (node *Node) SetRequestHandler(handler hostpool.RequestHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetRequestHandler(handler)
+	}
+}
+
+func (node *Node) SetBroadcastHandler(handler hostpool.BroadcastHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetBroadcastHandler(handler)
+	}
+}
+
 type BlockchainInfo struct {
 	Chain                string  `json:"chain"`
 	Blocks               uint64  `json:"blocks"`
@@ -3,6 +3,7 @@ package rvn
 import (
 	"bytes"
 	"encoding/hex"
+	"fmt"
 
 	"github.com/magicpool-co/pool/pkg/crypto"
 	"github.com/magicpool-co/pool/pkg/crypto/tx/btctx"
@@ -11,11 +12,18 @@ import (
 const txVersion = 0x1
 
 func GenerateCoinbase(
-	address string,
-	blockReward, blockHeight uint64,
+	addresses []string,
+	amounts []uint64,
+	blockHeight uint64,
 	extraData, defaultWitness string,
 	prefixP2PKH []byte,
 ) ([]byte, []byte, error) {
+	if len(addresses) != len(amounts) {
+		return nil, nil, fmt.Errorf("address and amount length mismatch")
+	} else if len(addresses) == 0 {
+		return nil, nil, fmt.Errorf("cannot send transaction without recipients")
+	}
+
 	tx := btctx.NewTransaction(txVersion, 0, prefixP2PKH, nil, false)
 
 	blockHeightSerialBytes, lengthBytes, err := crypto.SerializeBlockHeight(blockHeight)
@@ -33,12 +41,14 @@ func GenerateCoinbase(
 	prevTx := "0000000000000000000000000000000000000000000000000000000000000000"
 	tx.AddInput(prevTx, 0xFFFFFFFF, 0xFFFFFFFF, serializedBlockHeight)
 
-	scriptPubKey, err := btctx.AddressToScript(address, prefixP2PKH, nil, false)
-	if err != nil {
-		return nil, nil, err
-	}
+	for i, address := range addresses {
+		scriptPubKey, err := btctx.AddressToScript(address, prefixP2PKH, nil, false)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	tx.AddOutput(scriptPubKey, blockReward)
+		tx.AddOutput(scriptPubKey, amounts[i])
+	}
 
 	if len(defaultWitness) > 0 {
 		witness, err := hex.DecodeString(defaultWitness)
@@ -11,6 +11,7 @@ import (
 const (
 	globalDiffFactor = 4294967296
 	epochLength      = 7500
+	messagePrefix    = "Raven Signed Message:\n"
 )
 
 var (
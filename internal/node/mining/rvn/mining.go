@@ -58,6 +58,19 @@ func (node Node) PingHosts() ([]string, []uint64, []bool, []error) {
 	return hostIDs, heights, statuses, errs
 }
 
+func (node Node) GetBlockHashes(start, end uint64) ([]string, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid range")
+	}
+
+	heights := make([]uint64, end-start+1)
+	for i := range heights {
+		heights[i] = start + uint64(i)
+	}
+
+	return node.getBlockHashMany(heights)
+}
+
 func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 	if start > end {
 		return nil, fmt.Errorf("invalid range")
@@ -90,7 +103,7 @@ func (node Node) GetBlocks(start, end uint64) ([]*tsdb.RawBlock, error) {
 				return nil, fmt.Errorf("no transactions in block")
 			}
 
-			value, err := node.getRewardsFromTX(block.Transactions[0])
+			value, err := node.getRewardsFromTX(block.Transactions[0], false)
 			if err != nil {
 				return nil, err
 			}
@@ -115,11 +128,25 @@ func (node Node) GetBlocksByHash(startHash string, limit uint64) ([]*tsdb.RawBlo
 	return nil, fmt.Errorf("GetBlocks: not implemented")
 }
 
-func (node Node) getRewardsFromTX(tx *Transaction) (uint64, error) {
+// getRewardsFromTX sums the coinbase outputs that reach the pool wallet. for self-paid
+// (true solo) rounds only the fee output paid to the pool address is counted.
+func (node Node) getRewardsFromTX(tx *Transaction, selfPaid bool) (uint64, error) {
 	var amount uint64
 	for _, input := range tx.Inputs {
 		if len(input.Coinbase) > 0 {
 			for _, out := range tx.Outputs {
+				var address string
+				if len(out.ScriptPubKey.Addresses) > 0 {
+					address = out.ScriptPubKey.Addresses[0]
+				}
+
+				// extra outputs from the coinbase policy never reach the pool wallet
+				if selfPaid && address != node.address {
+					continue
+				} else if !selfPaid && node.coinbasePolicy.IsExtraAddress(address) {
+					continue
+				}
+
 				valBig, err := common.StringDecimalToBigint(out.Value.String(), node.GetUnits().Big())
 				if err != nil {
 					return amount, err
@@ -132,9 +159,48 @@ func (node Node) getRewardsFromTX(tx *Transaction) (uint64, error) {
 	return amount, nil
 }
 
-func (node Node) parseBlockTemplate(template *BlockTemplate) (*types.StratumJob, error) {
-	coinbaseHex, coinbaseHash, err := GenerateCoinbase(node.address, template.CoinbaseValue,
-		template.Height, "", template.DefaultWitnessCommitment, node.prefixP2PKH)
+func (node *Node) SetCoinbasePolicy(policy *types.CoinbasePolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	for _, output := range policy.ExtraOutputs {
+		if !node.ValidateAddress(output.Address) {
+			return fmt.Errorf("invalid coinbase output address: %s", output.Address)
+		}
+	}
+
+	node.coinbasePolicy = policy
+
+	return nil
+}
+
+func (node Node) GetSoloJob(job *types.StratumJob, address string, feeShare uint64) (*types.StratumJob, error) {
+	template, ok := job.Data.(*BlockTemplate)
+	if !ok {
+		return nil, fmt.Errorf("no block template for job %s", job.ID)
+	}
+
+	policy := node.coinbasePolicy.ForSoloMiner(address, node.address, feeShare)
+	soloJob, err := node.parseBlockTemplate(template, policy)
+	if err != nil {
+		return nil, err
+	}
+	soloJob.HostID = job.HostID
+
+	return soloJob, nil
+}
+
+func (node Node) parseBlockTemplate(
+	template *BlockTemplate,
+	policy *types.CoinbasePolicy,
+) (*types.StratumJob, error) {
+	minerReward, extraAddresses, extraAmounts := policy.SplitReward(template.CoinbaseValue)
+	addresses := append([]string{policy.GetPayoutAddress(node.address)}, extraAddresses...)
+	amounts := append([]uint64{minerReward}, extraAmounts...)
+
+	coinbaseHex, coinbaseHash, err := GenerateCoinbase(addresses, amounts, template.Height,
+		policy.GetSignature(), template.DefaultWitnessCommitment, node.prefixP2PKH)
 	if err != nil {
 		return nil, err
 	}
@@ -187,6 +253,7 @@ func (node Node) parseBlockTemplate(template *BlockTemplate) (*types.StratumJob,
 		Height:       new(types.Number).SetFromValue(template.Height),
 		Difficulty:   new(types.Difficulty).SetFromBits(uint32(bits), node.GetMaxDifficulty()),
 		BlockBuilder: builder,
+		Data:         template,
 	}
 
 	return job, nil
@@ -212,7 +279,7 @@ func (node Node) JobNotify(ctx context.Context, interval time.Duration) chan *ty
 				if err != nil {
 					node.logger.Error(err)
 				} else if lastHeight != template.Height || now.After(lastJob.Add(staticInterval)) {
-					job, err := node.parseBlockTemplate(template)
+					job, err := node.parseBlockTemplate(template, node.coinbasePolicy)
 					if err != nil {
 						node.logger.Error(err)
 					} else {
@@ -352,8 +419,8 @@ func (node Node) GetSubscribeResponses(id []byte, clientID, extraNonce string) (
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
-	res, err := node.GetSetDifficultyResponse(diffFactor)
+func (node Node) GetAuthorizeResponses(clientType, diffFactor int) ([]interface{}, error) {
+	res, err := node.GetSetDifficultyResponse(clientType, diffFactor)
 	if err != nil {
 		return nil, err
 	}
@@ -361,7 +428,7 @@ func (node Node) GetAuthorizeResponses(diffFactor int) ([]interface{}, error) {
 	return []interface{}{res}, nil
 }
 
-func (node Node) GetSetDifficultyResponse(diffFactor int) (interface{}, error) {
+func (node Node) GetSetDifficultyResponse(clientType, diffFactor int) (interface{}, error) {
 	return rpc.NewRequest("mining.set_target", node.GetShareDifficulty(diffFactor).TargetHex())
 }
 
@@ -399,7 +466,7 @@ func (node Node) UnlockRound(round *pooldb.Round) error {
 			return nil
 		}
 
-		value, err := node.getRewardsFromTX(block.Transactions[0])
+		value, err := node.getRewardsFromTX(block.Transactions[0], round.SelfPaid)
 		if err != nil {
 			return err
 		}
@@ -412,6 +479,18 @@ func (node Node) UnlockRound(round *pooldb.Round) error {
 	return nil
 }
 
+// getFeeOutputIndex finds the index of the coinbase output paying the pool
+// address, which for self-paid (true solo) rounds is the pool fee output.
+func (node Node) getFeeOutputIndex(tx *Transaction) (uint32, error) {
+	for i, out := range tx.Outputs {
+		if len(out.ScriptPubKey.Addresses) > 0 && out.ScriptPubKey.Addresses[0] == node.address {
+			return uint32(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("no fee output in coinbase %s", tx.TxID)
+}
+
 func (node Node) MatureRound(round *pooldb.Round) ([]*pooldb.UTXO, error) {
 	if round.Pending || round.Orphan || round.Mature {
 		return nil, nil
@@ -433,6 +512,18 @@ func (node Node) MatureRound(round *pooldb.Round) ([]*pooldb.UTXO, error) {
 		return nil, nil
 	}
 
+	var index uint32
+	if round.SelfPaid {
+		if len(block.Transactions) == 0 {
+			return nil, fmt.Errorf("no transactions in block for round %d", round.ID)
+		}
+
+		index, err = node.getFeeOutputIndex(block.Transactions[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	round.Mature = true
 
 	utxos := []*pooldb.UTXO{
@@ -440,7 +531,7 @@ func (node Node) MatureRound(round *pooldb.Round) ([]*pooldb.UTXO, error) {
 			ChainID: round.ChainID,
 			Value:   round.Value,
 			TxID:    types.StringValue(round.CoinbaseTxID),
-			Index:   0,
+			Index:   index,
 			Active:  true,
 			Spent:   false,
 		},
@@ -87,3 +87,21 @@ func (node Node) CreateTx(inputs []*types.TxInput, outputs []*types.TxOutput) (s
 func (node Node) BroadcastTx(tx string) (string, error) {
 	return node.sendRawTransaction(tx)
 }
+
+// SignMessage signs the message in the "signmessage" format. The message magic differs between
+// forks, so the signature is verified by the node before it is published.
+func (node Node) SignMessage(message string) (string, error) {
+	signature, err := btctx.SignMessageWithPrefix(node.privKey, messagePrefix, message)
+	if err != nil {
+		return "", err
+	}
+
+	valid, err := node.verifyMessage(node.address, signature, message)
+	if err != nil {
+		return "", err
+	} else if !valid {
+		return "", fmt.Errorf("message signature rejected by node")
+	}
+
+	return signature, nil
+}
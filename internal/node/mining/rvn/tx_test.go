@@ -0,0 +1,51 @@
+package rvn
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/magicpool-co/pool/pkg/crypto/tx/btctx"
+)
+
+func TestSignMessage(t *testing.T) {
+	tests := []struct {
+		privKey string
+		message string
+	}{
+		{
+			privKey: "ab3f4f0d4b1a5b8ad9b8f4d5e0e7d9a9a44ff0b7f3c9cf86a0f5b9a1c6a3e2d1",
+			message: "magicpool proof of reserves for RVN",
+		},
+	}
+
+	for i, tt := range tests {
+		privBytes, err := hex.DecodeString(tt.privKey)
+		if err != nil {
+			t.Errorf("failed on %d: decode priv key: %v", i, err)
+			continue
+		}
+		privKey := secp256k1.PrivKeyFromBytes(privBytes)
+
+		node := Node{
+			mocked:  true,
+			address: btctx.PrivKeyToAddress(privKey, mainnetPrefixP2PKH),
+			privKey: privKey,
+		}
+
+		sig, err := node.SignMessage(tt.message)
+		if err != nil {
+			t.Errorf("failed on %d: sign: %v", i, err)
+			continue
+		}
+
+		address, err := btctx.RecoverMessageAddressWithPrefix("Raven Signed Message:\n",
+			tt.message, sig, mainnetPrefixP2PKH)
+		if err != nil {
+			t.Errorf("failed on %d: recover: %v", i, err)
+		} else if address != node.address {
+			t.Errorf("failed on %d: address mismatch: have %s, want %s", i, address, node.address)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package rvn
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/goccy/go-json"
@@ -14,6 +15,7 @@ import (
 	"github.com/magicpool-co/pool/pkg/hostpool"
 	"github.com/magicpool-co/pool/pkg/sshtunnel"
 	"github.com/magicpool-co/pool/pkg/stratum/rpc"
+	"github.com/magicpool-co/pool/types"
 )
 
 // general constants
@@ -42,6 +44,12 @@ func generateHost(
 				Method:  "getbestblockhash",
 			},
 		}
+		hostRequestPolicy = &hostpool.RequestPolicy{
+			MethodTimeouts: map[string]time.Duration{
+				"getblocktemplate": time.Second * 5,
+				"submitblock":      time.Second * 30,
+			},
+		}
 	)
 
 	if len(urls) == 0 {
@@ -49,6 +57,7 @@ func generateHost(
 	}
 
 	host := hostpool.NewHTTPPool(context.Background(), logger, hostHealthCheck, tunnel)
+	host.SetRequestPolicy(hostRequestPolicy)
 	for _, url := range urls {
 		err := host.AddHost(url, port, hostOptions)
 		if err != nil {
@@ -102,15 +111,16 @@ func New(
 }
 
 type Node struct {
-	mocked      bool
-	mainnet     bool
-	prefixP2PKH []byte
-	prefixP2SH  []byte
-	address     string
-	privKey     *secp256k1.PrivateKey
-	rpcHost     *hostpool.HTTPPool
-	pow         *kawpow.Client
-	logger      *log.Logger
+	mocked         bool
+	mainnet        bool
+	prefixP2PKH    []byte
+	prefixP2SH     []byte
+	address        string
+	privKey        *secp256k1.PrivateKey
+	rpcHost        *hostpool.HTTPPool
+	pow            *kawpow.Client
+	coinbasePolicy *types.CoinbasePolicy
+	logger         *log.Logger
 }
 
 func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Request) {
@@ -124,6 +134,18 @@ func (node *Node) HandleHostPoolInfoRequest(w http.ResponseWriter, r *http.Reque
 	node.rpcHost.HandleInfoRequest(w, r)
 }
 
+func (node *Node) SetRequestHandler(handler hostpool.RequestHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetRequestHandler(handler)
+	}
+}
+
+func (node *Node) SetBroadcastHandler(handler hostpool.BroadcastHandler) {
+	if node.rpcHost != nil {
+		node.rpcHost.SetBroadcastHandler(handler)
+	}
+}
+
 type BlockchainInfo struct {
 	Chain                string  `json:"chain"`
 	Blocks               uint64  `json:"blocks"`
@@ -149,7 +171,10 @@ type Transaction struct {
 		Coinbase string `json:"coinbase"`
 	} `json:"vin"`
 	Outputs []struct {
-		Value json.Number `json:"value"`
+		Value        json.Number `json:"value"`
+		ScriptPubKey struct {
+			Addresses []string `json:"addresses"`
+		} `json:"scriptPubKey"`
 	} `json:"vout"`
 }
 
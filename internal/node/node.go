@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/magicpool-co/pool/internal/log"
+	btcMining "github.com/magicpool-co/pool/internal/node/mining/btc"
 	"github.com/magicpool-co/pool/internal/node/mining/cfx"
 	"github.com/magicpool-co/pool/internal/node/mining/erg"
 	"github.com/magicpool-co/pool/internal/node/mining/etc"
@@ -32,6 +33,10 @@ func GetMiningNode(
 	tunnel *sshtunnel.SSHTunnel,
 ) (types.MiningNode, error) {
 	switch strings.ToUpper(chain) {
+	case "BCH":
+		return btcMining.New(btcMining.BCH, mainnet, urls, privKey, logger, tunnel)
+	case "BTC":
+		return btcMining.New(btcMining.BTC, mainnet, urls, privKey, logger, tunnel)
 	case "CFX":
 		return cfx.New(mainnet, urls, privKey, logger, tunnel)
 	case "ERG":
@@ -43,19 +48,29 @@ func GetMiningNode(
 	case "FIRO":
 		return firo.New(mainnet, urls, privKey, logger, tunnel)
 	case "FLUX":
-		return flux.New(mainnet, urls, privKey, logger, tunnel)
+		return flux.New(flux.FLUX, mainnet, urls, privKey, logger, tunnel)
 	case "KAS":
-		return kas.New(mainnet, urls, privKey, logger, tunnel)
+		return kas.New(kas.KAS, mainnet, urls, privKey, logger, tunnel)
+	case "KLS":
+		return kas.New(kas.KLS, mainnet, urls, privKey, logger, tunnel)
 	case "NEXA":
 		return nexa.New(mainnet, urls, privKey, logger, tunnel)
 	case "RVN":
 		return rvn.New(mainnet, urls, privKey, logger, tunnel)
+	case "ZEN":
+		return flux.New(flux.ZEN, mainnet, urls, privKey, logger, tunnel)
 	default:
 		return nil, ErrUnsupportedChain
 	}
 }
 
 func GetPayoutNode(mainnet bool, chain, privKey, apiKey, url string, logger *log.Logger) (types.PayoutNode, error) {
+	// btc as a payout only chain goes through blockchair instead of a node, when
+	// it is also mined the worker uses the mining node for payouts directly
+	if strings.ToUpper(chain) == "BTC" {
+		return btc.New(mainnet, privKey, apiKey)
+	}
+
 	node, err := GetMiningNode(mainnet, chain, privKey, []string{url}, logger, nil)
 	if err != nil && err != ErrUnsupportedChain {
 		return nil, err
@@ -66,8 +81,6 @@ func GetPayoutNode(mainnet bool, chain, privKey, apiKey, url string, logger *log
 	switch strings.ToUpper(chain) {
 	case "BSC":
 		return bsc.New(mainnet, url, privKey, logger)
-	case "BTC":
-		return btc.New(mainnet, privKey, apiKey)
 	case "ETH":
 		return eth.New(mainnet, url, privKey, nil, logger)
 	case "USDC":
@@ -28,6 +28,10 @@ func (node Node) ShouldMergeUTXOs() bool {
 	return false
 }
 
+func (node Node) GetBatchSize() int {
+	return 100
+}
+
 func (node Node) GetUnits() *types.Number {
 	return new(types.Number).SetFromValue(1e18)
 }
@@ -98,6 +98,20 @@ func (node Node) getPendingNonce(address string) (uint64, error) {
 	return common.HexToUint64(hexNonce)
 }
 
+func (node Node) getCode(address string) (string, error) {
+	res, err := node.rpcHost.ExecRPCFromArgs("eth_getCode", address, "latest")
+	if err != nil {
+		return "", err
+	}
+
+	var code string
+	if err := json.Unmarshal(res.Result, &code); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
 func (node Node) sendEstimateGas(
 	from, to string,
 	data []byte,
@@ -58,6 +58,7 @@ func (node Node) GetTx(txid string) (*types.TxResponse, error) {
 	}
 
 	confirmed := txReceipt.Status == "0x1"
+	failed := txReceipt.Status == "0x0"
 	gasLeftover := new(big.Int).Sub(gasTotal, gasUsed)
 	fees := new(big.Int).Mul(gasUsed, gasPrice)
 	feeBalance := new(big.Int).Mul(gasLeftover, gasPrice)
@@ -69,13 +70,130 @@ func (node Node) GetTx(txid string) (*types.TxResponse, error) {
 		Fee:         fees,
 		FeeBalance:  feeBalance,
 		Confirmed:   confirmed,
+		Failed:      failed,
 	}
 
 	return res, nil
 }
 
+func (node Node) IsContractAddress(address string) (bool, error) {
+	code, err := node.getCode(address)
+	if err != nil {
+		return false, err
+	}
+
+	return len(code) > 2, nil
+}
+
+// SetDisperseAddress sets the disperse contract that batch payouts are sent through, after checking
+// that there is contract code at the address. Without one, every payout is sent individually.
+func (node *Node) SetDisperseAddress(address string) error {
+	isContract, err := node.IsContractAddress(address)
+	if err != nil {
+		return err
+	} else if !isContract {
+		return fmt.Errorf("no contract code found at disperse address %s", address)
+	}
+	node.disperseAddress = address
+
+	return nil
+}
+
+// ApproveBatchPayouts only checks that a disperse contract is configured, since
+// the native coin is sent along with the batch tx.
+func (node Node) ApproveBatchPayouts() (bool, error) {
+	return node.disperseAddress != "", nil
+}
+
+// createBatchTx creates a single tx that pays all outputs through the disperse contract.
+// The fee is split between the outputs proportional to their values and subtracted from them.
+func (node Node) createBatchTx(inputs []*types.TxInput, outputs []*types.TxOutput) (string, string, error) {
+	if node.disperseAddress == "" {
+		return "", "", fmt.Errorf("no disperse address set")
+	} else if len(inputs) != 1 {
+		return "", "", fmt.Errorf("must have exactly one input")
+	} else if len(outputs) > node.GetBatchSize() {
+		return "", "", fmt.Errorf("batch size exceeded: %d > %d", len(outputs), node.GetBatchSize())
+	}
+	input := inputs[0]
+
+	addresses := make([]string, len(outputs))
+	values := make([]*big.Int, len(outputs))
+	outputSum := new(big.Int)
+	for i, output := range outputs {
+		addresses[i] = output.Address
+		values[i] = new(big.Int).Set(output.Value)
+		outputSum.Add(outputSum, output.Value)
+	}
+
+	if input.Value.Cmp(outputSum) != 0 {
+		return "", "", fmt.Errorf("inputs and outputs must have same value")
+	}
+
+	nonce, err := node.getPendingNonce(node.address)
+	if err != nil {
+		return "", "", err
+	}
+	// handle for future nonces
+	nonce += uint64(input.Index)
+
+	chainID, err := node.getChainID()
+	if err != nil {
+		return "", "", err
+	}
+
+	gasPrice, err := node.getGasPrice()
+	if err != nil {
+		return "", "", err
+	}
+
+	data, err := ethtx.GenerateDisperseEtherData(addresses, values)
+	if err != nil {
+		return "", "", err
+	}
+
+	gasLimit, err := node.sendEstimateGas(node.address, node.disperseAddress,
+		data, outputSum, gasPrice, nonce)
+	if err != nil {
+		return "", "", err
+	}
+	// add a buffer since the calldata changes slightly once the fees are split
+	gasLimit += gasLimit / 10
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+
+	for _, output := range outputs {
+		output.SplitFee = true
+	}
+
+	values, err = ethtx.DistributeDisperseFees(outputs, fee, true)
+	if err != nil {
+		return "", "", err
+	}
+
+	value := new(big.Int)
+	for _, output := range outputs {
+		value.Add(value, output.Value)
+	}
+
+	data, err = ethtx.GenerateDisperseEtherData(addresses, values)
+	if err != nil {
+		return "", "", err
+	}
+
+	tx, _, err := ethtx.NewLegacyContractTx(node.privKey, node.disperseAddress,
+		data, value, gasPrice, gasLimit, nonce, chainID)
+	if err != nil {
+		return "", "", err
+	}
+	txid := ethtx.CalculateTxID(tx)
+
+	return txid, tx, nil
+}
+
 func (node Node) CreateTx(inputs []*types.TxInput, outputs []*types.TxOutput) (string, string, error) {
-	if len(inputs) != 1 || len(outputs) != 1 {
+	if len(outputs) > 1 {
+		return node.createBatchTx(inputs, outputs)
+	} else if len(inputs) != 1 || len(outputs) != 1 {
 		return "", "", fmt.Errorf("must have exactly one input and output")
 	} else if inputs[0].Value.Cmp(outputs[0].Value) != 0 {
 		return "", "", fmt.Errorf("inputs and outputs must have same value")
@@ -122,3 +240,7 @@ func (node Node) CreateTx(inputs []*types.TxInput, outputs []*types.TxOutput) (s
 func (node Node) BroadcastTx(tx string) (string, error) {
 	return node.sendRawTransaction(tx)
 }
+
+func (node Node) SignMessage(message string) (string, error) {
+	return ethtx.SignMessage(node.privKey, message)
+}
@@ -63,6 +63,8 @@ type Node struct {
 	privKey *secp256k1.PrivateKey
 	rpcHost *hostpool.HTTPPool
 	logger  *log.Logger
+	// the disperse contract used for batch payouts (see SetDisperseAddress)
+	disperseAddress string
 }
 
 type Transaction struct {
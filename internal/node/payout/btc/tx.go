@@ -171,3 +171,7 @@ func (node Node) CreateTx(inputs []*types.TxInput, outputs []*types.TxOutput) (s
 func (node Node) BroadcastTx(tx string) (string, error) {
 	return blockchair.New(node.blockchairKey).BroadcastTxBTC(tx)
 }
+
+func (node Node) SignMessage(message string) (string, error) {
+	return btctx.SignMessage(node.privKey, message)
+}
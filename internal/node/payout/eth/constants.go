@@ -52,6 +52,10 @@ func (node Node) ShouldMergeUTXOs() bool {
 	return false
 }
 
+func (node Node) GetBatchSize() int {
+	return 100
+}
+
 func ValidateAddress(address string) bool {
 	return addressExpr.MatchString(address)
 }
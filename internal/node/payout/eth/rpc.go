@@ -110,7 +110,11 @@ func (node Node) getGasPrice() (*big.Int, error) {
 }
 
 func (node Node) getPendingNonce(address string) (uint64, error) {
-	res, err := node.rpcHost.ExecRPCFromArgs("eth_getTransactionCount", address, "pending")
+	return node.getNonce(address, "pending")
+}
+
+func (node Node) getNonce(address, block string) (uint64, error) {
+	res, err := node.rpcHost.ExecRPCFromArgs("eth_getTransactionCount", address, block)
 	if err != nil {
 		return 0, err
 	}
@@ -151,6 +155,20 @@ func (node Node) getBlockByNumber(height uint64) (*Block, error) {
 	return block, nil
 }
 
+func (node Node) getCode(address string) (string, error) {
+	res, err := node.rpcHost.ExecRPCFromArgs("eth_getCode", address, "latest")
+	if err != nil {
+		return "", err
+	}
+
+	var code string
+	if err := json.Unmarshal(res.Result, &code); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
 func (node Node) sendEstimateGas(from, to, value string) (uint64, error) {
 	tx := map[string]interface{}{"from": from, "to": to, "value": value}
 	res, err := node.rpcHost.ExecRPCFromArgs("eth_estimateGas", tx)
@@ -167,6 +185,22 @@ func (node Node) sendEstimateGas(from, to, value string) (uint64, error) {
 	return common.HexToUint64(hexEstimate)
 }
 
+func (node Node) sendEstimateGasWithData(from, to, value, data string) (uint64, error) {
+	tx := map[string]interface{}{"from": from, "to": to, "value": value, "data": data}
+	res, err := node.rpcHost.ExecRPCFromArgs("eth_estimateGas", tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var hexEstimate string
+	err = json.Unmarshal(res.Result, &hexEstimate)
+	if err != nil {
+		return 0, err
+	}
+
+	return common.HexToUint64(hexEstimate)
+}
+
 func (node Node) sendCall(params []interface{}) (*big.Int, error) {
 	res, err := node.rpcHost.ExecRPCFromArgs("eth_call", params...)
 	if err != nil {
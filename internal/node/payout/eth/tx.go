@@ -150,6 +150,7 @@ func (node Node) GetTx(txid string) (*types.TxResponse, error) {
 	}
 
 	confirmed := txReceipt.Status == "0x1"
+	failed := txReceipt.Status == "0x0"
 	gasLeftover := new(big.Int).Sub(gasTotal, gasUsed)
 	fees := new(big.Int).Mul(gasUsed, gasPrice)
 	feeBalance := new(big.Int).Mul(gasLeftover, gasPrice)
@@ -199,13 +200,215 @@ func (node Node) GetTx(txid string) (*types.TxResponse, error) {
 		Fee:         fees,
 		FeeBalance:  feeBalance,
 		Confirmed:   confirmed,
+		Failed:      failed,
 	}
 
 	return res, nil
 }
 
+func (node Node) IsContractAddress(address string) (bool, error) {
+	code, err := node.getCode(address)
+	if err != nil {
+		return false, err
+	}
+
+	return len(code) > 2, nil
+}
+
+// SetDisperseAddress sets the disperse contract that batch payouts are sent through, after checking
+// that there is contract code at the address. Without one, every payout is sent individually.
+func (node *Node) SetDisperseAddress(address string) error {
+	if !node.mocked {
+		isContract, err := node.IsContractAddress(address)
+		if err != nil {
+			return err
+		} else if !isContract {
+			return fmt.Errorf("no contract code found at disperse address %s", address)
+		}
+	}
+	node.disperseAddress = address
+
+	return nil
+}
+
+// ApproveBatchPayouts approves the disperse contract as a spender of the token, since disperseToken
+// transfers the token from the wallet. The native coin is sent along with the tx, so there is nothing
+// to approve. Returns false while the approval is pending (the gas for the approval is paid in ETH)
+// or if no disperse contract is configured.
+func (node Node) ApproveBatchPayouts() (bool, error) {
+	if node.disperseAddress == "" {
+		return false, nil
+	} else if node.erc20 == nil {
+		return true, nil
+	}
+
+	data := ethtx.GenerateAllowanceData(node.address, node.disperseAddress)
+	params := []interface{}{
+		map[string]interface{}{"to": node.erc20.Address, "data": "0x" + hex.EncodeToString(data)},
+		"latest",
+	}
+
+	// the allowance is approved as the maximum and only decreases by the amounts paid
+	// out through the contract, so anything above half of it is treated as unlimited
+	allowance, err := node.sendCall(params)
+	if err != nil {
+		return false, err
+	} else if allowance.Cmp(new(big.Int).Rsh(ethtx.MaxAllowance, 1)) >= 0 {
+		return true, nil
+	}
+
+	// wait for any pending tx (including a previous approval) to be mined
+	pendingNonce, err := node.getPendingNonce(node.address)
+	if err != nil {
+		return false, err
+	}
+
+	latestNonce, err := node.getNonce(node.address, "latest")
+	if err != nil {
+		return false, err
+	} else if pendingNonce > latestNonce {
+		return false, nil
+	}
+
+	chainID, err := node.getChainID()
+	if err != nil {
+		return false, err
+	}
+
+	data = ethtx.GenerateApproveData(node.disperseAddress, ethtx.MaxAllowance)
+	gasLimit, err := node.sendEstimateGasWithData(node.address, node.erc20.Address,
+		"0x0", "0x"+hex.EncodeToString(data))
+	if err != nil {
+		return false, err
+	}
+
+	baseFee, err := node.getBaseFee()
+	if err != nil {
+		return false, err
+	}
+
+	tx, _, err := ethtx.NewContractTx(node.privKey, node.erc20.Address,
+		data, new(big.Int), baseFee, gasLimit, pendingNonce, chainID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = node.BroadcastTx(tx)
+
+	return false, err
+}
+
+// createBatchTx creates a single tx that pays all outputs through the disperse contract. The fee is
+// split between the outputs proportional to their values. For the native coin, the fee is subtracted
+// from the values of the outputs. For ERC20 tokens, the fee is paid from the fee balance, so it is
+// only recorded on the outputs (the disperse contract has to have been approved as a spender
+// through ApproveBatchPayouts).
+func (node Node) createBatchTx(inputs []*types.TxInput, outputs []*types.TxOutput) (string, string, error) {
+	if node.disperseAddress == "" {
+		return "", "", fmt.Errorf("no disperse address set")
+	} else if len(inputs) != 1 {
+		return "", "", fmt.Errorf("must have exactly one input")
+	} else if len(outputs) > node.GetBatchSize() {
+		return "", "", fmt.Errorf("batch size exceeded: %d > %d", len(outputs), node.GetBatchSize())
+	}
+	input := inputs[0]
+
+	addresses := make([]string, len(outputs))
+	values := make([]*big.Int, len(outputs))
+	outputSum := new(big.Int)
+	for i, output := range outputs {
+		addresses[i] = output.Address
+		values[i] = new(big.Int).Set(output.Value)
+		outputSum.Add(outputSum, output.Value)
+	}
+
+	if input.Value.Cmp(outputSum) != 0 {
+		return "", "", fmt.Errorf("inputs and outputs must have same value")
+	}
+
+	chainID, err := node.getChainID()
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce, err := node.getPendingNonce(node.address)
+	if err != nil {
+		return "", "", err
+	}
+	// handle for future nonces
+	nonce += uint64(input.Index)
+
+	var value *big.Int
+	var data []byte
+	if node.erc20 != nil {
+		value = new(big.Int)
+		data, err = ethtx.GenerateDisperseTokenData(node.erc20.Address, addresses, values)
+	} else {
+		value = new(big.Int).Set(outputSum)
+		data, err = ethtx.GenerateDisperseEtherData(addresses, values)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	gasLimit, err := node.sendEstimateGasWithData(node.address, node.disperseAddress,
+		"0x"+value.Text(16), "0x"+hex.EncodeToString(data))
+	if err != nil {
+		return "", "", err
+	}
+	// add a buffer since the calldata changes slightly once the fees are split
+	gasLimit += gasLimit / 10
+
+	baseFee, err := node.getBaseFee()
+	if err != nil {
+		return "", "", err
+	}
+	fee := ethtx.CalculateFees(baseFee, gasLimit)
+
+	if node.erc20 != nil {
+		if input.FeeBalance == nil || input.FeeBalance.Cmp(fee) < 0 {
+			return "", "", fmt.Errorf("insufficient fee balance")
+		}
+
+		_, err = ethtx.DistributeDisperseFees(outputs, fee, false)
+		if err != nil {
+			return "", "", err
+		}
+	} else {
+		for _, output := range outputs {
+			output.SplitFee = true
+		}
+
+		values, err = ethtx.DistributeDisperseFees(outputs, fee, true)
+		if err != nil {
+			return "", "", err
+		}
+
+		value = new(big.Int)
+		for _, output := range outputs {
+			value.Add(value, output.Value)
+		}
+
+		data, err = ethtx.GenerateDisperseEtherData(addresses, values)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	tx, _, err := ethtx.NewContractTx(node.privKey, node.disperseAddress,
+		data, value, baseFee, gasLimit, nonce, chainID)
+	if err != nil {
+		return "", "", err
+	}
+	txid := ethtx.CalculateTxID(tx)
+
+	return txid, tx, nil
+}
+
 func (node Node) CreateTx(inputs []*types.TxInput, outputs []*types.TxOutput) (string, string, error) {
-	if len(inputs) != 1 || len(outputs) != 1 {
+	if len(outputs) > 1 {
+		return node.createBatchTx(inputs, outputs)
+	} else if len(inputs) != 1 || len(outputs) != 1 {
 		return "", "", fmt.Errorf("must have exactly one input and output")
 	} else if inputs[0].Value.Cmp(outputs[0].Value) != 0 {
 		return "", "", fmt.Errorf("inputs and outputs must have same value")
@@ -270,3 +473,7 @@ func (node Node) CreateTx(inputs []*types.TxInput, outputs []*types.TxOutput) (s
 func (node Node) BroadcastTx(tx string) (string, error) {
 	return node.sendRawTransaction(tx)
 }
+
+func (node Node) SignMessage(message string) (string, error) {
+	return ethtx.SignMessage(node.privKey, message)
+}
@@ -75,6 +75,8 @@ type Node struct {
 	rpcHost *hostpool.HTTPPool
 	erc20   *ERC20
 	logger  *log.Logger
+	// the disperse contract used for batch payouts (see SetDisperseAddress)
+	disperseAddress string
 }
 
 type ERC20 struct {
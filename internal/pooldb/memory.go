@@ -0,0 +1,1514 @@
+package pooldb
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/magicpool-co/pool/pkg/dbcl"
+)
+
+/* helpers */
+
+var nullBigIntType = reflect.TypeOf(dbcl.NullBigInt{})
+
+type minerChainKey struct {
+	minerID uint64
+	chainID string
+}
+
+// copyValue deep copies a column value, so that rows held by the memory
+// store never share pointers (or big ints) with the objects of the caller.
+func copyValue(src reflect.Value) reflect.Value {
+	switch {
+	case src.Type() == nullBigIntType:
+		value := src.Interface().(dbcl.NullBigInt)
+		if value.BigInt != nil {
+			value.BigInt = new(big.Int).Set(value.BigInt)
+		}
+		return reflect.ValueOf(value)
+	case src.Kind() == reflect.Pointer:
+		if src.IsNil() {
+			return reflect.Zero(src.Type())
+		}
+		dst := reflect.New(src.Type().Elem())
+		dst.Elem().Set(src.Elem())
+		return dst
+	default:
+		return src
+	}
+}
+
+func columnIndex(t reflect.Type) map[string]int {
+	idx := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("db"); tag != "" {
+			idx[tag] = i
+		}
+	}
+
+	return idx
+}
+
+// copyColumns copies the given columns from src to dst, or every column if cols is nil.
+func copyColumns[T any](dst, src *T, cols []string) error {
+	dstValue, srcValue := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	idx := columnIndex(dstValue.Type())
+	if cols == nil {
+		for _, i := range idx {
+			dstValue.Field(i).Set(copyValue(srcValue.Field(i)))
+		}
+		return nil
+	}
+
+	for _, col := range cols {
+		i, ok := idx[col]
+		if !ok {
+			return fmt.Errorf("unknown column %s", col)
+		}
+		dstValue.Field(i).Set(copyValue(srcValue.Field(i)))
+	}
+
+	return nil
+}
+
+func cloneRow[T any](src *T) *T {
+	dst := new(T)
+	copyColumns(dst, src, nil)
+
+	return dst
+}
+
+func setColumn[T any](row *T, col string, value interface{}) {
+	rowValue := reflect.ValueOf(row).Elem()
+	if i, ok := columnIndex(rowValue.Type())[col]; ok {
+		rowValue.Field(i).Set(reflect.ValueOf(value))
+	}
+}
+
+// uint64Value is types.Uint64Value, which can't be imported since types imports pooldb.
+func uint64Value(v *uint64) uint64 {
+	if v == nil {
+		return 0
+	}
+
+	return *v
+}
+
+func addNullBigInt(a, b dbcl.NullBigInt, sign int) dbcl.NullBigInt {
+	sum := new(big.Int)
+	if a.Valid && a.BigInt != nil {
+		sum.Set(a.BigInt)
+	}
+
+	if b.Valid && b.BigInt != nil {
+		if sign < 0 {
+			sum.Sub(sum, b.BigInt)
+		} else {
+			sum.Add(sum, b.BigInt)
+		}
+	}
+
+	return dbcl.NullBigInt{Valid: true, BigInt: sum}
+}
+
+// sumNullBigInt is SUM() over a column, which is NULL if every value is NULL.
+func sumNullBigInt(a, b dbcl.NullBigInt) dbcl.NullBigInt {
+	if !b.Valid || b.BigInt == nil {
+		return a
+	}
+
+	return addNullBigInt(a, b, 1)
+}
+
+func nullBigIntFloat64(value dbcl.NullBigInt) float64 {
+	if !value.Valid || value.BigInt == nil {
+		return 0
+	}
+	floatValue, _ := new(big.Float).SetInt(value.BigInt).Float64()
+
+	return floatValue
+}
+
+/* tables */
+
+type memoryTable[T any] struct {
+	rows   map[uint64]*T
+	nextID uint64
+}
+
+func newMemoryTable[T any]() *memoryTable[T] {
+	return &memoryTable[T]{rows: make(map[uint64]*T), nextID: 1}
+}
+
+func (t *memoryTable[T]) clone() *memoryTable[T] {
+	rows := make(map[uint64]*T, len(t.rows))
+	for id, row := range t.rows {
+		rows[id] = row
+	}
+
+	return &memoryTable[T]{rows: rows, nextID: t.nextID}
+}
+
+// insert stores a copy of the insert columns of obj, the same way MySQL
+// only writes the listed columns and defaults the rest.
+func (t *memoryTable[T]) insert(obj *T, cols []string) (uint64, error) {
+	row := new(T)
+	err := copyColumns(row, obj, cols)
+	if err != nil {
+		return 0, err
+	}
+
+	id := t.nextID
+	t.nextID++
+
+	now := time.Now()
+	setColumn(row, "id", id)
+	setColumn(row, "created_at", now)
+	setColumn(row, "updated_at", now)
+	t.rows[id] = row
+
+	return id, nil
+}
+
+// update replaces the row instead of modifying it, since rows
+// are shared with the snapshot of an open transaction.
+func (t *memoryTable[T]) update(id uint64, fn func(*T) error) error {
+	row, ok := t.rows[id]
+	if !ok {
+		return nil
+	}
+
+	row = cloneRow(row)
+	err := fn(row)
+	if err != nil {
+		return err
+	}
+	setColumn(row, "updated_at", time.Now())
+	t.rows[id] = row
+
+	return nil
+}
+
+func (t *memoryTable[T]) updateColumns(id uint64, obj *T, cols []string) error {
+	return t.update(id, func(row *T) error {
+		return copyColumns(row, obj, cols)
+	})
+}
+
+// sortedIDs returns the ids in primary key order, which is
+// the order InnoDB returns rows in without an ORDER BY.
+func (t *memoryTable[T]) sortedIDs() []uint64 {
+	ids := make([]uint64, 0, len(t.rows))
+	for id := range t.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func (t *memoryTable[T]) get(id uint64) *T {
+	row, ok := t.rows[id]
+	if !ok {
+		return nil
+	}
+
+	return cloneRow(row)
+}
+
+func (t *memoryTable[T]) selectRows(match func(*T) bool) []*T {
+	output := make([]*T, 0)
+	for _, id := range t.sortedIDs() {
+		if row := t.rows[id]; match(row) {
+			output = append(output, cloneRow(row))
+		}
+	}
+
+	return output
+}
+
+func (t *memoryTable[T]) deleteRows(match func(*T) bool) {
+	for id, row := range t.rows {
+		if match(row) {
+			delete(t.rows, id)
+		}
+	}
+}
+
+type memoryData struct {
+	miners          *memoryTable[Miner]
+	workers         *memoryTable[Worker]
+	shareAudits     map[minerChainKey]*MinerShareAudit
+	rounds          *memoryTable[Round]
+	roundRecipients *memoryTable[RoundRecipient]
+	shares          *memoryTable[Share]
+	balanceInputs   *memoryTable[BalanceInput]
+	balanceOutputs  *memoryTable[BalanceOutput]
+	balanceSums     map[minerChainKey]*BalanceSum
+	payouts         *memoryTable[Payout]
+	transactions    *memoryTable[Transaction]
+	utxos           *memoryTable[UTXO]
+	exchangeBatches *memoryTable[ExchangeBatch]
+	exchangeInputs  *memoryTable[ExchangeInput]
+	deposits        *memoryTable[ExchangeDeposit]
+	trades          *memoryTable[ExchangeTrade]
+	withdrawals     *memoryTable[ExchangeWithdrawal]
+}
+
+func newMemoryData() *memoryData {
+	data := &memoryData{
+		miners:          newMemoryTable[Miner](),
+		workers:         newMemoryTable[Worker](),
+		shareAudits:     make(map[minerChainKey]*MinerShareAudit),
+		rounds:          newMemoryTable[Round](),
+		roundRecipients: newMemoryTable[RoundRecipient](),
+		shares:          newMemoryTable[Share](),
+		balanceInputs:   newMemoryTable[BalanceInput](),
+		balanceOutputs:  newMemoryTable[BalanceOutput](),
+		balanceSums:     make(map[minerChainKey]*BalanceSum),
+		payouts:         newMemoryTable[Payout](),
+		transactions:    newMemoryTable[Transaction](),
+		utxos:           newMemoryTable[UTXO](),
+		exchangeBatches: newMemoryTable[ExchangeBatch](),
+		exchangeInputs:  newMemoryTable[ExchangeInput](),
+		deposits:        newMemoryTable[ExchangeDeposit](),
+		trades:          newMemoryTable[ExchangeTrade](),
+		withdrawals:     newMemoryTable[ExchangeWithdrawal](),
+	}
+
+	return data
+}
+
+func (d *memoryData) clone() *memoryData {
+	shareAudits := make(map[minerChainKey]*MinerShareAudit, len(d.shareAudits))
+	for key, shareAudit := range d.shareAudits {
+		shareAudits[key] = shareAudit
+	}
+
+	balanceSums := make(map[minerChainKey]*BalanceSum, len(d.balanceSums))
+	for key, balanceSum := range d.balanceSums {
+		balanceSums[key] = balanceSum
+	}
+
+	data := &memoryData{
+		miners:          d.miners.clone(),
+		workers:         d.workers.clone(),
+		shareAudits:     shareAudits,
+		rounds:          d.rounds.clone(),
+		roundRecipients: d.roundRecipients.clone(),
+		shares:          d.shares.clone(),
+		balanceInputs:   d.balanceInputs.clone(),
+		balanceOutputs:  d.balanceOutputs.clone(),
+		balanceSums:     balanceSums,
+		payouts:         d.payouts.clone(),
+		transactions:    d.transactions.clone(),
+		utxos:           d.utxos.clone(),
+		exchangeBatches: d.exchangeBatches.clone(),
+		exchangeInputs:  d.exchangeInputs.clone(),
+		deposits:        d.deposits.clone(),
+		trades:          d.trades.clone(),
+		withdrawals:     d.withdrawals.clone(),
+	}
+
+	return data
+}
+
+/* store */
+
+// MemoryStore is an in-memory Store for unit tests. it mirrors the queries of the MySQL
+// store (including which columns are written on insert and the unique keys of miners
+// and workers), but does not enforce foreign keys. transactions are serialized and
+// rolled back by restoring a snapshot taken when the transaction started.
+type MemoryStore struct {
+	mu   *sync.Mutex
+	data *memoryData
+	inTx bool
+}
+
+func NewMemoryStore() *MemoryStore {
+	store := &MemoryStore{
+		mu:   new(sync.Mutex),
+		data: newMemoryData(),
+	}
+
+	return store
+}
+
+func (s *MemoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+
+	return s.mu.Unlock
+}
+
+func (s *MemoryStore) Miners() MinerRepository       { return s }
+func (s *MemoryStore) Shares() ShareRepository       { return s }
+func (s *MemoryStore) Rounds() RoundRepository       { return s }
+func (s *MemoryStore) Balances() BalanceRepository   { return s }
+func (s *MemoryStore) Payouts() PayoutRepository     { return s }
+func (s *MemoryStore) Exchanges() ExchangeRepository { return s }
+
+func (s *MemoryStore) Transact(fn func(Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.data.clone()
+	err := fn(&MemoryStore{mu: s.mu, data: s.data, inTx: true})
+	if err != nil {
+		*s.data = *snapshot
+	}
+
+	return err
+}
+
+/* memory miners */
+
+func (s *MemoryStore) GetMiner(id uint64) (*Miner, error) {
+	defer s.lock()()
+
+	return s.data.miners.get(id), nil
+}
+
+func (s *MemoryStore) GetMiners(ids []uint64) ([]*Miner, error) {
+	defer s.lock()()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	idx := make(map[uint64]bool)
+	for _, id := range ids {
+		idx[id] = true
+	}
+
+	output := s.data.miners.selectRows(func(miner *Miner) bool {
+		return idx[miner.ID]
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetMinerAddress(id uint64) (string, error) {
+	defer s.lock()()
+
+	miner, ok := s.data.miners.rows[id]
+	if !ok {
+		return "", nil
+	}
+
+	return miner.Address, nil
+}
+
+func (s *MemoryStore) GetMinerIDByChainAddress(chain, address string) (uint64, error) {
+	defer s.lock()()
+
+	miners := s.data.miners.selectRows(func(miner *Miner) bool {
+		return miner.ChainID == chain && miner.Address == address
+	})
+	if len(miners) == 0 {
+		return 0, nil
+	}
+
+	return miners[0].ID, nil
+}
+
+func (s *MemoryStore) GetRecipients() ([]*Miner, error) {
+	defer s.lock()()
+
+	output := s.data.miners.selectRows(func(miner *Miner) bool {
+		return miner.RecipientFeePercent != nil
+	})
+
+	return output, nil
+}
+
+// hasUnconfirmedPayout expects the lock to be held. like the MySQL queries,
+// failed payouts count as unconfirmed.
+func (s *MemoryStore) hasUnconfirmedPayout(minerID uint64) bool {
+	for _, payout := range s.data.payouts.rows {
+		if payout.MinerID == minerID && !payout.Confirmed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matureBalanceCmp expects the lock to be held, it compares the mature balance
+// sum of the miner to value and returns false if the miner has no balance sum.
+func (s *MemoryStore) matureBalanceCmp(miner *Miner, value string) (int, bool) {
+	balanceSum, ok := s.data.balanceSums[minerChainKey{miner.ID, miner.ChainID}]
+	if !ok || !balanceSum.MatureValue.Valid {
+		return 0, false
+	}
+
+	bigValue, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return 0, false
+	}
+
+	return balanceSum.MatureValue.BigInt.Cmp(bigValue), true
+}
+
+func (s *MemoryStore) GetMinersWithBalanceAboveThresholdByChain(chain, threshold string) ([]*Miner, error) {
+	defer s.lock()()
+
+	output := s.data.miners.selectRows(func(miner *Miner) bool {
+		if miner.ChainID != chain || s.hasUnconfirmedPayout(miner.ID) {
+			return false
+		}
+
+		minerThreshold := threshold
+		if miner.Threshold.Valid && miner.Threshold.BigInt.Sign() > 0 {
+			minerThreshold = miner.Threshold.BigInt.String()
+		}
+		cmp, ok := s.matureBalanceCmp(miner, minerThreshold)
+
+		return ok && cmp >= 0
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetMinersWithPayoutScheduleByChain(chain, minValue string) ([]*Miner, error) {
+	defer s.lock()()
+
+	output := s.data.miners.selectRows(func(miner *Miner) bool {
+		if miner.ChainID != chain || s.hasUnconfirmedPayout(miner.ID) {
+			return false
+		} else if miner.PayoutSchedule == 0 && !miner.PayoutRequested {
+			return false
+		}
+		cmp, ok := s.matureBalanceCmp(miner, minValue)
+
+		return ok && cmp >= 0
+	})
+
+	for _, miner := range output {
+		for _, payout := range s.data.payouts.rows {
+			if payout.MinerID != miner.ID {
+				continue
+			} else if miner.LastPayout == nil || payout.CreatedAt.After(*miner.LastPayout) {
+				createdAt := payout.CreatedAt
+				miner.LastPayout = &createdAt
+			}
+		}
+	}
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetMinersWithHeldBalanceByChain(chain string) ([]*Miner, error) {
+	defer s.lock()()
+
+	idx := make(map[uint64]bool)
+	for _, balanceInput := range s.data.balanceInputs.rows {
+		round, ok := s.data.rounds.rows[balanceInput.RoundID]
+		if ok && round.Held && balanceInput.OutChainID == chain {
+			idx[balanceInput.MinerID] = true
+		}
+	}
+
+	output := s.data.miners.selectRows(func(miner *Miner) bool {
+		return idx[miner.ID]
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertMiner(obj *Miner) (uint64, error) {
+	defer s.lock()()
+
+	cols := []string{
+		"chain_id", "address", "email", "threshold",
+		"active", "enabled_worker_notifications",
+		"enabled_payout_notifications",
+	}
+
+	for _, miner := range s.data.miners.rows {
+		if miner.ChainID == obj.ChainID && miner.Address == obj.Address {
+			return 0, fmt.Errorf("duplicate miner %s:%s", obj.ChainID, obj.Address)
+		}
+	}
+
+	return s.data.miners.insert(obj, cols)
+}
+
+func (s *MemoryStore) UpdateMiner(obj *Miner, updateCols []string) error {
+	defer s.lock()()
+
+	return s.data.miners.updateColumns(obj.ID, obj, updateCols)
+}
+
+func (s *MemoryStore) GetWorkerID(minerID uint64, name string) (uint64, error) {
+	defer s.lock()()
+
+	workers := s.data.workers.selectRows(func(worker *Worker) bool {
+		return worker.MinerID == minerID && worker.Name == name
+	})
+	if len(workers) == 0 {
+		return 0, nil
+	}
+
+	return workers[0].ID, nil
+}
+
+func (s *MemoryStore) InsertWorker(obj *Worker) (uint64, error) {
+	defer s.lock()()
+
+	cols := []string{"miner_id", "name", "active", "notified"}
+
+	for _, worker := range s.data.workers.rows {
+		if worker.MinerID == obj.MinerID && worker.Name == obj.Name {
+			return 0, fmt.Errorf("duplicate worker %d:%s", obj.MinerID, obj.Name)
+		}
+	}
+
+	return s.data.workers.insert(obj, cols)
+}
+
+func (s *MemoryStore) GetExcludedMinerShareAuditsByChain(chain string) ([]*MinerShareAudit, error) {
+	defer s.lock()()
+
+	output := make([]*MinerShareAudit, 0)
+	for _, shareAudit := range s.data.shareAudits {
+		if shareAudit.ChainID == chain && (shareAudit.Excluded || shareAudit.Banned) {
+			output = append(output, cloneRow(shareAudit))
+		}
+	}
+	sort.Slice(output, func(i, j int) bool { return output[i].MinerID < output[j].MinerID })
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetBannedMinerShareAuditsByChain(chain string) ([]*MinerShareAudit, error) {
+	defer s.lock()()
+
+	output := make([]*MinerShareAudit, 0)
+	for _, shareAudit := range s.data.shareAudits {
+		if shareAudit.ChainID == chain && shareAudit.Banned {
+			output = append(output, cloneRow(shareAudit))
+		}
+	}
+	sort.Slice(output, func(i, j int) bool { return output[i].MinerID < output[j].MinerID })
+
+	return output, nil
+}
+
+// InsertAddMinerShareAudits adds the counters to the existing audit of a miner.
+// audits are otherwise only flagged, excluded or banned through SetMinerShareAudit,
+// which only exists on the memory store to set up tests.
+func (s *MemoryStore) InsertAddMinerShareAudits(objects ...*MinerShareAudit) error {
+	defer s.lock()()
+
+	now := time.Now()
+	for _, obj := range objects {
+		key := minerChainKey{obj.MinerID, obj.ChainID}
+		existing, ok := s.data.shareAudits[key]
+		if !ok {
+			row := cloneRow(obj)
+			row.Flagged, row.Excluded, row.Banned, row.FlagReason = false, false, false, nil
+			row.CreatedAt, row.UpdatedAt = now, now
+			s.data.shareAudits[key] = row
+			continue
+		}
+
+		row := cloneRow(existing)
+		row.AcceptedShares += obj.AcceptedShares
+		row.HighDiffShares += obj.HighDiffShares
+		row.NearBlockShares += obj.NearBlockShares
+		row.EarlyShares += obj.EarlyShares
+		row.FoundBlocks += obj.FoundBlocks
+		row.ExpectedBlocks += obj.ExpectedBlocks
+		row.ExpectedNearBlockShares += obj.ExpectedNearBlockShares
+		row.UpdatedAt = now
+		s.data.shareAudits[key] = row
+	}
+
+	return nil
+}
+
+// SetMinerShareAudit overwrites the share audit of a miner.
+func (s *MemoryStore) SetMinerShareAudit(obj *MinerShareAudit) {
+	defer s.lock()()
+
+	s.data.shareAudits[minerChainKey{obj.MinerID, obj.ChainID}] = cloneRow(obj)
+}
+
+/* memory shares */
+
+func (s *MemoryStore) GetSharesByRound(roundID uint64) ([]*Share, error) {
+	defer s.lock()()
+
+	output := s.data.shares.selectRows(func(share *Share) bool {
+		return share.RoundID == roundID
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertShares(objects ...*Share) error {
+	defer s.lock()()
+
+	cols := []string{"round_id", "miner_id", "count"}
+	for _, obj := range objects {
+		_, err := s.data.shares.insert(obj, cols)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* memory rounds */
+
+func (s *MemoryStore) GetRound(id uint64) (*Round, error) {
+	defer s.lock()()
+
+	return s.data.rounds.get(id), nil
+}
+
+func (s *MemoryStore) GetPendingRoundsByChain(chain string, maxHeight uint64) ([]*Round, error) {
+	defer s.lock()()
+
+	output := s.data.rounds.selectRows(func(round *Round) bool {
+		return round.ChainID == chain && round.Height < maxHeight && round.Pending
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetImmatureRoundsByChain(chain string, maxHeight uint64) ([]*Round, error) {
+	defer s.lock()()
+
+	output := s.data.rounds.selectRows(func(round *Round) bool {
+		return !round.Pending && !round.Mature && !round.Orphan &&
+			round.ChainID == chain && round.Height < maxHeight
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetUnspentRoundsByChain(chain string) ([]*Round, error) {
+	defer s.lock()()
+
+	output := s.data.rounds.selectRows(func(round *Round) bool {
+		return !round.Pending && !round.Spent && !round.Orphan && round.ChainID == chain
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetRevalidationRoundsByChain(chain string, minHeight uint64) ([]*Round, error) {
+	defer s.lock()()
+
+	output := s.data.rounds.selectRows(func(round *Round) bool {
+		return !round.Pending && !round.Orphan && round.ChainID == chain &&
+			(round.Height >= minHeight || round.Held)
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertRound(obj *Round) (uint64, error) {
+	defer s.lock()()
+
+	cols := []string{
+		"chain_id", "miner_id", "solo", "self_paid", "height", "epoch_height", "uncle_height",
+		"hash", "nonce", "mix_digest", "coinbase_txid", "value", "difficulty",
+		"luck", "accepted_shares", "rejected_shares", "invalid_shares", "mature",
+		"pending", "uncle", "orphan", "spent",
+	}
+
+	return s.data.rounds.insert(obj, cols)
+}
+
+func (s *MemoryStore) UpdateRound(obj *Round, updateCols []string) error {
+	defer s.lock()()
+
+	return s.data.rounds.updateColumns(obj.ID, obj, updateCols)
+}
+
+func (s *MemoryStore) GetRoundRecipients(roundID uint64) ([]*RoundRecipient, error) {
+	defer s.lock()()
+
+	output := s.data.roundRecipients.selectRows(func(recipient *RoundRecipient) bool {
+		return recipient.RoundID == roundID
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertRoundRecipients(objects ...*RoundRecipient) error {
+	defer s.lock()()
+
+	cols := []string{"round_id", "miner_id", "fee_percent"}
+	for _, obj := range objects {
+		_, err := s.data.roundRecipients.insert(obj, cols)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteRoundRecipientsByRound(roundID uint64) error {
+	defer s.lock()()
+
+	s.data.roundRecipients.deleteRows(func(recipient *RoundRecipient) bool {
+		return recipient.RoundID == roundID
+	})
+
+	return nil
+}
+
+/* memory balances */
+
+func (s *MemoryStore) GetBalanceInputsByRound(roundID uint64) ([]*BalanceInput, error) {
+	defer s.lock()()
+
+	output := s.data.balanceInputs.selectRows(func(balanceInput *BalanceInput) bool {
+		return balanceInput.RoundID == roundID
+	})
+
+	return output, nil
+}
+
+// isPendingBalanceInputWithoutBatch expects the lock to be held.
+func (s *MemoryStore) isPendingBalanceInputWithoutBatch(balanceInput *BalanceInput) bool {
+	if !balanceInput.Pending || !balanceInput.Mature || balanceInput.BatchID != nil {
+		return false
+	} else if round, ok := s.data.rounds.rows[balanceInput.RoundID]; ok && round.Held {
+		return false
+	}
+
+	return true
+}
+
+func (s *MemoryStore) GetPendingBalanceInputsWithoutBatch() ([]*BalanceInput, error) {
+	defer s.lock()()
+
+	output := s.data.balanceInputs.selectRows(s.isPendingBalanceInputWithoutBatch)
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetPendingBalanceInputsSumWithoutBatch() ([]*BalanceInput, error) {
+	defer s.lock()()
+
+	output := make([]*BalanceInput, 0)
+	sumIdx := make(map[[2]string]*BalanceInput)
+	for _, balanceInput := range s.data.balanceInputs.selectRows(s.isPendingBalanceInputWithoutBatch) {
+		key := [2]string{balanceInput.ChainID, balanceInput.OutChainID}
+		sum, ok := sumIdx[key]
+		if !ok {
+			sum = &BalanceInput{
+				ChainID:    balanceInput.ChainID,
+				OutChainID: balanceInput.OutChainID,
+			}
+			sumIdx[key] = sum
+			output = append(output, sum)
+		}
+		sum.Value = addNullBigInt(sum.Value, balanceInput.Value, 1)
+	}
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetBalanceInputsByBatch(batchID uint64) ([]*BalanceInput, error) {
+	defer s.lock()()
+
+	output := s.data.balanceInputs.selectRows(func(balanceInput *BalanceInput) bool {
+		return balanceInput.BatchID != nil && uint64Value(balanceInput.BatchID) == batchID
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertBalanceInputs(objects ...*BalanceInput) error {
+	defer s.lock()()
+
+	cols := []string{
+		"round_id", "chain_id", "miner_id", "out_chain_id",
+		"balance_output_id", "batch_id", "value",
+		"pool_fees", "mature", "pending",
+	}
+
+	for _, obj := range objects {
+		_, err := s.data.balanceInputs.insert(obj, cols)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) UpdateBalanceInput(obj *BalanceInput, updateCols []string) error {
+	defer s.lock()()
+
+	return s.data.balanceInputs.updateColumns(obj.ID, obj, updateCols)
+}
+
+func (s *MemoryStore) UpdateBalanceInputsSetMatureByRound(roundID uint64) error {
+	defer s.lock()()
+
+	for _, id := range s.data.balanceInputs.sortedIDs() {
+		if s.data.balanceInputs.rows[id].RoundID != roundID {
+			continue
+		}
+
+		s.data.balanceInputs.update(id, func(balanceInput *BalanceInput) error {
+			balanceInput.Mature = true
+			return nil
+		})
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) UpdateBalanceInputsUnsetBatch(batchID uint64) error {
+	defer s.lock()()
+
+	for _, id := range s.data.balanceInputs.sortedIDs() {
+		if uint64Value(s.data.balanceInputs.rows[id].BatchID) != batchID {
+			continue
+		}
+
+		s.data.balanceInputs.update(id, func(balanceInput *BalanceInput) error {
+			balanceInput.BatchID = nil
+			return nil
+		})
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteBalanceInputsByRound(roundID uint64) error {
+	defer s.lock()()
+
+	s.data.balanceInputs.deleteRows(func(balanceInput *BalanceInput) bool {
+		return balanceInput.RoundID == roundID
+	})
+
+	return nil
+}
+
+func (s *MemoryStore) balanceOutputIDsByRound(roundID uint64) map[uint64]bool {
+	idx := make(map[uint64]bool)
+	for _, balanceInput := range s.data.balanceInputs.rows {
+		if balanceInput.RoundID == roundID && balanceInput.BalanceOutputID != nil {
+			idx[uint64Value(balanceInput.BalanceOutputID)] = true
+		}
+	}
+
+	return idx
+}
+
+func (s *MemoryStore) GetBalanceOutputsByRound(roundID uint64) ([]*BalanceOutput, error) {
+	defer s.lock()()
+
+	idx := s.balanceOutputIDsByRound(roundID)
+	output := s.data.balanceOutputs.selectRows(func(balanceOutput *BalanceOutput) bool {
+		return idx[balanceOutput.ID]
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetBalanceOutputsByPayout(payoutID uint64) ([]*BalanceOutput, error) {
+	defer s.lock()()
+
+	output := s.data.balanceOutputs.selectRows(func(balanceOutput *BalanceOutput) bool {
+		return balanceOutput.OutPayoutID != nil && uint64Value(balanceOutput.OutPayoutID) == payoutID
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetBalanceOutputsByBatch(batchID uint64) ([]*BalanceOutput, error) {
+	defer s.lock()()
+
+	output := s.data.balanceOutputs.selectRows(func(balanceOutput *BalanceOutput) bool {
+		return balanceOutput.InBatchID != nil && uint64Value(balanceOutput.InBatchID) == batchID
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetUnpaidBalanceOutputsByMiner(minerID uint64, chain string) ([]*BalanceOutput, error) {
+	defer s.lock()()
+
+	output := s.data.balanceOutputs.selectRows(func(balanceOutput *BalanceOutput) bool {
+		return balanceOutput.MinerID == minerID && balanceOutput.ChainID == chain &&
+			balanceOutput.Mature && balanceOutput.OutPayoutID == nil
+	})
+
+	return output, nil
+}
+
+var memoryBalanceOutputCols = []string{
+	"chain_id", "miner_id", "in_batch_id", "in_deposit_id",
+	"in_payout_id", "out_payout_id", "out_merge_transaction_id",
+	"value", "pool_fees", "exchange_fees", "tx_fees", "mature", "spent",
+}
+
+func (s *MemoryStore) InsertBalanceOutput(obj *BalanceOutput) (uint64, error) {
+	defer s.lock()()
+
+	return s.data.balanceOutputs.insert(obj, memoryBalanceOutputCols)
+}
+
+func (s *MemoryStore) InsertBalanceOutputs(objects ...*BalanceOutput) error {
+	defer s.lock()()
+
+	for _, obj := range objects {
+		_, err := s.data.balanceOutputs.insert(obj, memoryBalanceOutputCols)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) UpdateBalanceOutput(obj *BalanceOutput, updateCols []string) error {
+	defer s.lock()()
+
+	return s.data.balanceOutputs.updateColumns(obj.ID, obj, updateCols)
+}
+
+func (s *MemoryStore) UpdateBalanceOutputsSetMatureByRound(roundID uint64) error {
+	defer s.lock()()
+
+	for id := range s.balanceOutputIDsByRound(roundID) {
+		s.data.balanceOutputs.update(id, func(balanceOutput *BalanceOutput) error {
+			balanceOutput.Mature = true
+			return nil
+		})
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteBalanceOutputsByID(ids ...uint64) error {
+	defer s.lock()()
+
+	for _, id := range ids {
+		delete(s.data.balanceOutputs.rows, id)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) GetBalanceSumsByChain(chain string) ([]*BalanceSum, error) {
+	defer s.lock()()
+
+	output := make([]*BalanceSum, 0)
+	for _, balanceSum := range s.data.balanceSums {
+		if balanceSum.ChainID == chain {
+			output = append(output, cloneRow(balanceSum))
+		}
+	}
+	sort.Slice(output, func(i, j int) bool { return output[i].MinerID < output[j].MinerID })
+
+	return output, nil
+}
+
+func (s *MemoryStore) insertUpdateBalanceSums(sign int, objects ...*BalanceSum) {
+	now := time.Now()
+	for _, obj := range objects {
+		// the MySQL store sets missing values to zero on the caller's object
+		if !obj.ImmatureValue.Valid {
+			obj.ImmatureValue = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)}
+		}
+
+		if !obj.MatureValue.Valid {
+			obj.MatureValue = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)}
+		}
+
+		key := minerChainKey{obj.MinerID, obj.ChainID}
+		existing, ok := s.data.balanceSums[key]
+		if !ok {
+			// like MySQL, a new row takes the inserted value regardless of the operator
+			row := cloneRow(obj)
+			row.CreatedAt, row.UpdatedAt = now, now
+			s.data.balanceSums[key] = row
+			continue
+		}
+
+		row := cloneRow(existing)
+		row.ImmatureValue = addNullBigInt(row.ImmatureValue, obj.ImmatureValue, sign)
+		row.MatureValue = addNullBigInt(row.MatureValue, obj.MatureValue, sign)
+		row.UpdatedAt = now
+		s.data.balanceSums[key] = row
+	}
+}
+
+func (s *MemoryStore) InsertAddBalanceSums(objects ...*BalanceSum) error {
+	defer s.lock()()
+
+	s.insertUpdateBalanceSums(1, objects...)
+
+	return nil
+}
+
+func (s *MemoryStore) InsertSubtractBalanceSums(objects ...*BalanceSum) error {
+	defer s.lock()()
+
+	s.insertUpdateBalanceSums(-1, objects...)
+
+	return nil
+}
+
+/* memory payouts */
+
+func (s *MemoryStore) GetPayout(id uint64) (*Payout, error) {
+	defer s.lock()()
+
+	return s.data.payouts.get(id), nil
+}
+
+func (s *MemoryStore) GetUnconfirmedPayouts(chain string) ([]*Payout, error) {
+	defer s.lock()()
+
+	output := s.data.payouts.selectRows(func(payout *Payout) bool {
+		return payout.ChainID == chain && !payout.Confirmed && !payout.Failed
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) GetPayoutsByTransaction(transactionID uint64) ([]*Payout, error) {
+	defer s.lock()()
+
+	output := s.data.payouts.selectRows(func(payout *Payout) bool {
+		return payout.TransactionID != nil && uint64Value(payout.TransactionID) == transactionID
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertPayout(obj *Payout) (uint64, error) {
+	defer s.lock()()
+
+	cols := []string{
+		"chain_id", "miner_id", "address", "transaction_id", "txid",
+		"height", "value", "fee_balance", "pool_fees", "exchange_fees",
+		"tx_fees", "early_fees", "pending", "confirmed", "failed",
+	}
+
+	return s.data.payouts.insert(obj, cols)
+}
+
+func (s *MemoryStore) UpdatePayout(obj *Payout, updateCols []string) error {
+	defer s.lock()()
+
+	return s.data.payouts.updateColumns(obj.ID, obj, updateCols)
+}
+
+func (s *MemoryStore) GetTransaction(id uint64) (*Transaction, error) {
+	defer s.lock()()
+
+	return s.data.transactions.get(id), nil
+}
+
+func (s *MemoryStore) GetTransactionByTxID(txid string) (*Transaction, error) {
+	defer s.lock()()
+
+	txs := s.data.transactions.selectRows(func(tx *Transaction) bool {
+		return tx.TxID == txid
+	})
+	if len(txs) == 0 {
+		return nil, nil
+	}
+
+	return txs[0], nil
+}
+
+func (s *MemoryStore) InsertTransaction(obj *Transaction) (uint64, error) {
+	defer s.lock()()
+
+	cols := []string{
+		"chain_id", "type", "txid", "tx_hex", "height", "value",
+		"fee", "fee_balance", "remainder", "remainder_idx",
+		"spent", "confirmed", "failed",
+	}
+
+	return s.data.transactions.insert(obj, cols)
+}
+
+func (s *MemoryStore) GetUTXOsByTxIDs(chain string, txids []string) ([]*UTXO, error) {
+	defer s.lock()()
+
+	idx := make(map[string]bool)
+	for _, txid := range txids {
+		idx[txid] = true
+	}
+
+	output := s.data.utxos.selectRows(func(utxo *UTXO) bool {
+		return utxo.ChainID == chain && idx[utxo.TxID]
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertUTXOs(objects ...*UTXO) error {
+	defer s.lock()()
+
+	cols := []string{"chain_id", "value", "txid", "idx", "active", "spent"}
+	for _, obj := range objects {
+		_, err := s.data.utxos.insert(obj, cols)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) UpdateUTXO(obj *UTXO, updateCols []string) error {
+	defer s.lock()()
+
+	return s.data.utxos.updateColumns(obj.ID, obj, updateCols)
+}
+
+/* memory exchanges */
+
+func (s *MemoryStore) GetExchangeBatch(batchID uint64) (*ExchangeBatch, error) {
+	defer s.lock()()
+
+	return s.data.exchangeBatches.get(batchID), nil
+}
+
+func (s *MemoryStore) GetActiveExchangeBatches(exchangeID uint64) ([]*ExchangeBatch, error) {
+	defer s.lock()()
+
+	output := s.data.exchangeBatches.selectRows(func(batch *ExchangeBatch) bool {
+		return batch.CompletedAt == nil && uint64(batch.ExchangeID) == exchangeID
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertExchangeBatch(obj *ExchangeBatch) (uint64, error) {
+	defer s.lock()()
+
+	cols := []string{"exchange_id", "status"}
+
+	return s.data.exchangeBatches.insert(obj, cols)
+}
+
+func (s *MemoryStore) UpdateExchangeBatch(obj *ExchangeBatch, updateCols []string) error {
+	defer s.lock()()
+
+	return s.data.exchangeBatches.updateColumns(obj.ID, obj, updateCols)
+}
+
+func (s *MemoryStore) UpdateExchangeBatchStatus(obj *ExchangeBatch, prevStatus int) (bool, error) {
+	defer s.lock()()
+
+	batch, ok := s.data.exchangeBatches.rows[obj.ID]
+	if !ok || batch.Status != prevStatus {
+		return false, nil
+	}
+
+	cols := []string{"status", "completed_at"}
+	err := s.data.exchangeBatches.updateColumns(obj.ID, obj, cols)
+
+	return err == nil, err
+}
+
+func (s *MemoryStore) GetExchangeInputs(batchID uint64) ([]*ExchangeInput, error) {
+	defer s.lock()()
+
+	output := s.data.exchangeInputs.selectRows(func(exchangeInput *ExchangeInput) bool {
+		return exchangeInput.BatchID == batchID
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertExchangeInputs(objects ...*ExchangeInput) error {
+	defer s.lock()()
+
+	cols := []string{"batch_id", "in_chain_id", "out_chain_id", "value"}
+	for _, obj := range objects {
+		_, err := s.data.exchangeInputs.insert(obj, cols)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteExchangeInputsByBatch(batchID uint64) error {
+	defer s.lock()()
+
+	s.data.exchangeInputs.deleteRows(func(exchangeInput *ExchangeInput) bool {
+		return exchangeInput.BatchID == batchID
+	})
+
+	return nil
+}
+
+func (s *MemoryStore) GetExchangeDeposits(batchID uint64) ([]*ExchangeDeposit, error) {
+	defer s.lock()()
+
+	output := s.data.deposits.selectRows(func(deposit *ExchangeDeposit) bool {
+		return deposit.BatchID == batchID
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertExchangeDeposit(obj *ExchangeDeposit) (uint64, error) {
+	defer s.lock()()
+
+	cols := []string{
+		"batch_id", "chain_id", "network_id", "transaction_id",
+		"deposit_txid", "exchange_txid", "exchange_deposit_id",
+		"value", "fees", "registered", "confirmed",
+	}
+
+	return s.data.deposits.insert(obj, cols)
+}
+
+func (s *MemoryStore) UpdateExchangeDeposit(obj *ExchangeDeposit, updateCols []string) error {
+	defer s.lock()()
+
+	return s.data.deposits.updateColumns(obj.ID, obj, updateCols)
+}
+
+func (s *MemoryStore) GetExchangeTrades(batchID uint64) ([]*ExchangeTrade, error) {
+	defer s.lock()()
+
+	output := s.data.trades.selectRows(func(trade *ExchangeTrade) bool {
+		return trade.BatchID == batchID
+	})
+
+	return output, nil
+}
+
+// GetExchangeTradesByStage returns the last step of every path in the stage.
+func (s *MemoryStore) GetExchangeTradesByStage(batchID uint64, stage int) ([]*ExchangeTrade, error) {
+	defer s.lock()()
+
+	lastSteps := make(map[int]*ExchangeTrade)
+	for _, trade := range s.data.trades.selectRows(func(trade *ExchangeTrade) bool {
+		return trade.BatchID == batchID && trade.StageID == stage
+	}) {
+		if last, ok := lastSteps[trade.PathID]; !ok || trade.StepID > last.StepID {
+			lastSteps[trade.PathID] = trade
+		}
+	}
+
+	output := make([]*ExchangeTrade, 0, len(lastSteps))
+	for _, trade := range lastSteps {
+		output = append(output, trade)
+	}
+	sort.Slice(output, func(i, j int) bool { return output[i].ID < output[j].ID })
+
+	return output, nil
+}
+
+type exchangeTradeGroupKey struct {
+	pathID         int
+	stageID        int
+	initialChainID string
+	fromChainID    string
+	toChainID      string
+	direction      int
+}
+
+// groupExchangeTrades mirrors the GROUP BY of the aggregated trade queries, summing the values
+// and averaging the prices weighted by the proceeds (a NULL average if there are no proceeds).
+// the cumulative fill price is summed by GetFinalExchangeTrades and averaged by
+// GetExchangeTradeByPathAndStage, which is the only one of the two to select the (minimum) id.
+func groupExchangeTrades(trades []*ExchangeTrade, byPathAndStage bool) []*ExchangeTrade {
+	type priceSum struct {
+		valid bool
+		sum   float64
+	}
+
+	output := make([]*ExchangeTrade, 0)
+	groups := make(map[exchangeTradeGroupKey]*ExchangeTrade)
+	proceeds := make(map[*ExchangeTrade]float64)
+	prices := make(map[*ExchangeTrade]*[4]priceSum)
+	for _, trade := range trades {
+		key := exchangeTradeGroupKey{trade.PathID, trade.StageID, trade.InitialChainID,
+			trade.FromChainID, trade.ToChainID, trade.Direction}
+		group, ok := groups[key]
+		if !ok {
+			group = &ExchangeTrade{
+				BatchID:        trade.BatchID,
+				PathID:         trade.PathID,
+				StageID:        trade.StageID,
+				InitialChainID: trade.InitialChainID,
+				FromChainID:    trade.FromChainID,
+				ToChainID:      trade.ToChainID,
+				Direction:      trade.Direction,
+				Initiated:      true,
+				Confirmed:      true,
+				CreatedAt:      trade.CreatedAt,
+				UpdatedAt:      trade.UpdatedAt,
+			}
+			if byPathAndStage {
+				group.ID = trade.ID
+			}
+			groups[key] = group
+			prices[group] = new([4]priceSum)
+			output = append(output, group)
+		}
+
+		group.Value = sumNullBigInt(group.Value, trade.Value)
+		group.Proceeds = sumNullBigInt(group.Proceeds, trade.Proceeds)
+		group.TradeFees = sumNullBigInt(group.TradeFees, trade.TradeFees)
+		group.CumulativeDepositFees = sumNullBigInt(group.CumulativeDepositFees, trade.CumulativeDepositFees)
+		group.CumulativeTradeFees = sumNullBigInt(group.CumulativeTradeFees, trade.CumulativeTradeFees)
+		group.Initiated = group.Initiated && trade.Initiated
+		group.Confirmed = group.Confirmed && trade.Confirmed
+		if trade.CreatedAt.Before(group.CreatedAt) {
+			group.CreatedAt = trade.CreatedAt
+		}
+		if trade.UpdatedAt.After(group.UpdatedAt) {
+			group.UpdatedAt = trade.UpdatedAt
+		}
+
+		weight := nullBigIntFloat64(trade.Proceeds)
+		proceeds[group] += weight
+		for i, price := range []*float64{trade.OrderPrice, trade.FillPrice, trade.CumulativeFillPrice, trade.Slippage} {
+			if price == nil {
+				continue
+			}
+
+			prices[group][i].valid = true
+			if i == 2 && !byPathAndStage {
+				prices[group][i].sum += *price
+			} else {
+				prices[group][i].sum += *price * weight
+			}
+		}
+	}
+
+	for _, group := range output {
+		values := make([]*float64, 4)
+		for i, price := range prices[group] {
+			value := price.sum
+			if !price.valid {
+				continue
+			} else if i == 2 && !byPathAndStage {
+				values[i] = &value
+			} else if proceeds[group] != 0 {
+				value /= proceeds[group]
+				values[i] = &value
+			}
+		}
+		group.OrderPrice, group.FillPrice, group.CumulativeFillPrice, group.Slippage =
+			values[0], values[1], values[2], values[3]
+	}
+
+	return output
+}
+
+func (s *MemoryStore) GetExchangeTradeByPathAndStage(batchID uint64, path, stage int) (*ExchangeTrade, error) {
+	defer s.lock()()
+
+	trades := s.data.trades.selectRows(func(trade *ExchangeTrade) bool {
+		return trade.BatchID == batchID && trade.PathID == path && trade.StageID == stage
+	})
+
+	groups := groupExchangeTrades(trades, true)
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	return groups[0], nil
+}
+
+// GetFinalExchangeTrades returns the aggregated trades of the last stage of every path.
+func (s *MemoryStore) GetFinalExchangeTrades(batchID uint64) ([]*ExchangeTrade, error) {
+	defer s.lock()()
+
+	maxStages := make(map[int]int)
+	for _, trade := range s.data.trades.rows {
+		if trade.BatchID == batchID && trade.StageID > maxStages[trade.PathID] {
+			maxStages[trade.PathID] = trade.StageID
+		}
+	}
+
+	trades := s.data.trades.selectRows(func(trade *ExchangeTrade) bool {
+		return trade.BatchID == batchID && trade.StageID == maxStages[trade.PathID]
+	})
+
+	return groupExchangeTrades(trades, false), nil
+}
+
+func (s *MemoryStore) InsertExchangeTrades(objects ...*ExchangeTrade) error {
+	defer s.lock()()
+
+	cols := []string{
+		"batch_id", "path_id", "stage_id", "step_id", "is_market_order",
+		"trade_strategy", "exchange_trade_id", "initial_chain_id",
+		"from_chain_id", "to_chain_id", "market", "direction", "value",
+		"proceeds", "trade_fees", "cumulative_deposit_fees",
+		"cumulative_trade_fees", "order_price", "fill_price",
+		"cumulative_fill_price", "slippage", "initiated", "confirmed",
+	}
+
+	for _, obj := range objects {
+		_, err := s.data.trades.insert(obj, cols)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) UpdateExchangeTrade(obj *ExchangeTrade, updateCols []string) error {
+	defer s.lock()()
+
+	return s.data.trades.updateColumns(obj.ID, obj, updateCols)
+}
+
+func (s *MemoryStore) GetExchangeWithdrawals(batchID uint64) ([]*ExchangeWithdrawal, error) {
+	defer s.lock()()
+
+	output := s.data.withdrawals.selectRows(func(withdrawal *ExchangeWithdrawal) bool {
+		return withdrawal.BatchID == batchID
+	})
+
+	return output, nil
+}
+
+func (s *MemoryStore) InsertExchangeWithdrawal(obj *ExchangeWithdrawal) (uint64, error) {
+	defer s.lock()()
+
+	cols := []string{
+		"batch_id", "chain_id", "network_id", "exchange_txid",
+		"exchange_withdrawal_id", "value", "deposit_fees", "trade_fees",
+		"withdrawal_fees", "cumulative_fees", "confirmed", "spent",
+	}
+
+	return s.data.withdrawals.insert(obj, cols)
+}
+
+func (s *MemoryStore) UpdateExchangeWithdrawal(obj *ExchangeWithdrawal, updateCols []string) error {
+	defer s.lock()()
+
+	return s.data.withdrawals.updateColumns(obj.ID, obj, updateCols)
+}
@@ -0,0 +1,234 @@
+package pooldb
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/magicpool-co/pool/pkg/dbcl"
+)
+
+func TestMemoryStoreTransact(t *testing.T) {
+	store := NewMemoryStore()
+
+	minerID, err := store.Miners().InsertMiner(&Miner{ChainID: "ETC", Address: "0x01"})
+	if err != nil {
+		t.Fatalf("failed to insert miner: %v", err)
+	}
+
+	balanceSum := &BalanceSum{
+		MinerID:     minerID,
+		ChainID:     "ETC",
+		MatureValue: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(100)},
+	}
+	if err := store.Balances().InsertAddBalanceSums(balanceSum); err != nil {
+		t.Fatalf("failed to insert balance sum: %v", err)
+	}
+
+	// a failed transaction should restore every write made within it
+	err = store.Transact(func(tx Store) error {
+		if _, err := tx.Miners().InsertMiner(&Miner{ChainID: "ETC", Address: "0x02"}); err != nil {
+			return err
+		} else if err := tx.Balances().InsertAddBalanceSums(balanceSum); err != nil {
+			return err
+		}
+
+		// nested transactions join the outer one
+		return tx.Transact(func(tx Store) error {
+			return fmt.Errorf("rollback")
+		})
+	})
+	if err == nil {
+		t.Fatalf("expected transaction error")
+	}
+
+	if minerID, err := store.Miners().GetMinerIDByChainAddress("ETC", "0x02"); err != nil {
+		t.Errorf("failed to fetch miner: %v", err)
+	} else if minerID != 0 {
+		t.Errorf("miner mismatch: have %d, want 0", minerID)
+	}
+
+	balanceSums, err := store.Balances().GetBalanceSumsByChain("ETC")
+	if err != nil {
+		t.Fatalf("failed to fetch balance sums: %v", err)
+	} else if len(balanceSums) != 1 {
+		t.Fatalf("balance sum length mismatch: have %d, want 1", len(balanceSums))
+	} else if balanceSums[0].MatureValue.BigInt.Cmp(new(big.Int).SetUint64(100)) != 0 {
+		t.Errorf("balance sum mismatch: have %s, want 100", balanceSums[0].MatureValue.BigInt)
+	}
+
+	// a successful transaction should keep its writes
+	err = store.Transact(func(tx Store) error {
+		_, err := tx.Miners().InsertMiner(&Miner{ChainID: "ETC", Address: "0x02"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to commit transaction: %v", err)
+	}
+
+	if minerID, err := store.Miners().GetMinerIDByChainAddress("ETC", "0x02"); err != nil {
+		t.Errorf("failed to fetch miner: %v", err)
+	} else if minerID == 0 {
+		t.Errorf("miner not found after commit")
+	}
+}
+
+func TestMemoryStoreUniqueMiner(t *testing.T) {
+	store := NewMemoryStore()
+
+	if _, err := store.Miners().InsertMiner(&Miner{ChainID: "ETC", Address: "0x01"}); err != nil {
+		t.Fatalf("failed to insert miner: %v", err)
+	} else if _, err := store.Miners().InsertMiner(&Miner{ChainID: "ETC", Address: "0x01"}); err == nil {
+		t.Errorf("expected duplicate miner error")
+	} else if _, err := store.Miners().InsertMiner(&Miner{ChainID: "ETH", Address: "0x01"}); err != nil {
+		t.Errorf("failed to insert miner on another chain: %v", err)
+	}
+}
+
+func TestMemoryStoreHeldBalanceInputs(t *testing.T) {
+	store := NewMemoryStore()
+
+	for i, held := range []bool{false, true} {
+		round := &Round{ChainID: "ETC", Height: uint64(100 + i), Mature: true}
+		roundID, err := store.Rounds().InsertRound(round)
+		if err != nil {
+			t.Fatalf("failed to insert round: %v", err)
+		}
+
+		round.ID = roundID
+		round.Held = held
+		if err := store.Rounds().UpdateRound(round, []string{"held"}); err != nil {
+			t.Fatalf("failed to update round: %v", err)
+		}
+
+		err = store.Balances().InsertBalanceInputs(&BalanceInput{
+			RoundID:    roundID,
+			ChainID:    "ETC",
+			MinerID:    1,
+			OutChainID: "BTC",
+			Value:      dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(100)},
+			PoolFees:   dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)},
+			Mature:     true,
+			Pending:    true,
+		})
+		if err != nil {
+			t.Fatalf("failed to insert balance input: %v", err)
+		}
+	}
+
+	// balance inputs from held rounds should never be exchanged
+	balanceInputs, err := store.Balances().GetPendingBalanceInputsWithoutBatch()
+	if err != nil {
+		t.Fatalf("failed to fetch balance inputs: %v", err)
+	} else if len(balanceInputs) != 1 {
+		t.Fatalf("balance input length mismatch: have %d, want 1", len(balanceInputs))
+	} else if balanceInputs[0].RoundID != 1 {
+		t.Errorf("balance input round mismatch: have %d, want 1", balanceInputs[0].RoundID)
+	}
+
+	balanceInputSums, err := store.Balances().GetPendingBalanceInputsSumWithoutBatch()
+	if err != nil {
+		t.Fatalf("failed to fetch balance input sums: %v", err)
+	} else if len(balanceInputSums) != 1 {
+		t.Fatalf("balance input sum length mismatch: have %d, want 1", len(balanceInputSums))
+	} else if balanceInputSums[0].Value.BigInt.Cmp(new(big.Int).SetUint64(100)) != 0 {
+		t.Errorf("balance input sum mismatch: have %s, want 100", balanceInputSums[0].Value.BigInt)
+	}
+}
+
+func TestMemoryStoreGroupedExchangeTrades(t *testing.T) {
+	store := NewMemoryStore()
+
+	newTrade := func(path, stage, step int, value, proceeds uint64, fillPrice float64) *ExchangeTrade {
+		cumulativeFillPrice := fillPrice
+		return &ExchangeTrade{
+			BatchID:        1,
+			PathID:         path,
+			StageID:        stage,
+			StepID:         step,
+			InitialChainID: "ETC",
+			FromChainID:    "ETC",
+			ToChainID:      "BTC",
+			Value:          dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(value)},
+			Proceeds:       dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(proceeds)},
+
+			FillPrice:           &fillPrice,
+			CumulativeFillPrice: &cumulativeFillPrice,
+			Initiated:           true,
+			Confirmed:           true,
+		}
+	}
+
+	err := store.Exchanges().InsertExchangeTrades(
+		newTrade(1, 1, 1, 100, 100, 1),
+		newTrade(1, 1, 2, 300, 300, 2),
+		newTrade(1, 2, 1, 400, 200, 3),
+		newTrade(2, 1, 1, 50, 100, 4),
+		newTrade(2, 1, 2, 50, 300, 8),
+	)
+	if err != nil {
+		t.Fatalf("failed to insert trades: %v", err)
+	}
+
+	// only the last step of every path is returned for a stage
+	trades, err := store.Exchanges().GetExchangeTradesByStage(1, 1)
+	if err != nil {
+		t.Fatalf("failed to get trades by stage: %v", err)
+	} else if len(trades) != 2 {
+		t.Fatalf("trade length mismatch: have %d, want 2", len(trades))
+	} else if trades[0].ID != 2 || trades[1].ID != 5 {
+		t.Errorf("trade id mismatch: have %d and %d, want 2 and 5", trades[0].ID, trades[1].ID)
+	}
+
+	// the steps of a path and stage are summed, with prices weighted by proceeds
+	trade, err := store.Exchanges().GetExchangeTradeByPathAndStage(1, 1, 1)
+	if err != nil {
+		t.Fatalf("failed to get trade by path and stage: %v", err)
+	} else if trade == nil {
+		t.Fatalf("trade mismatch: have nil")
+	} else if trade.ID != 1 {
+		t.Errorf("trade id mismatch: have %d, want 1", trade.ID)
+	} else if trade.Value.BigInt.Uint64() != 400 {
+		t.Errorf("trade value mismatch: have %s, want 400", trade.Value.BigInt)
+	} else if fillPrice := *trade.FillPrice; fillPrice != 1.75 {
+		t.Errorf("trade fill price mismatch: have %f, want 1.75", fillPrice)
+	}
+
+	trade, err = store.Exchanges().GetExchangeTradeByPathAndStage(1, 1, 3)
+	if err != nil {
+		t.Fatalf("failed to get missing trade by path and stage: %v", err)
+	} else if trade != nil {
+		t.Errorf("missing trade mismatch: have %d, want nil", trade.ID)
+	}
+
+	// only the last stage of every path is final and the cumulative fill prices are summed
+	finalTrades, err := store.Exchanges().GetFinalExchangeTrades(1)
+	if err != nil {
+		t.Fatalf("failed to get final trades: %v", err)
+	} else if len(finalTrades) != 2 {
+		t.Fatalf("final trade length mismatch: have %d, want 2", len(finalTrades))
+	}
+
+	for _, finalTrade := range finalTrades {
+		var wantStage int
+		var wantProceeds uint64
+		var wantFillPrice, wantCumulativeFillPrice float64
+		switch finalTrade.PathID {
+		case 1:
+			wantStage, wantProceeds, wantFillPrice, wantCumulativeFillPrice = 2, 200, 3, 3
+		case 2:
+			wantStage, wantProceeds, wantFillPrice, wantCumulativeFillPrice = 1, 400, 7, 12
+		}
+
+		if finalTrade.StageID != wantStage {
+			t.Errorf("path %d: stage mismatch: have %d, want %d", finalTrade.PathID, finalTrade.StageID, wantStage)
+		} else if finalTrade.Proceeds.BigInt.Uint64() != wantProceeds {
+			t.Errorf("path %d: proceeds mismatch: have %s, want %d", finalTrade.PathID, finalTrade.Proceeds.BigInt, wantProceeds)
+		} else if *finalTrade.FillPrice != wantFillPrice {
+			t.Errorf("path %d: fill price mismatch: have %f, want %f", finalTrade.PathID, *finalTrade.FillPrice, wantFillPrice)
+		} else if *finalTrade.CumulativeFillPrice != wantCumulativeFillPrice {
+			t.Errorf("path %d: cumulative fill price mismatch: have %f, want %f",
+				finalTrade.PathID, *finalTrade.CumulativeFillPrice, wantCumulativeFillPrice)
+		}
+	}
+}
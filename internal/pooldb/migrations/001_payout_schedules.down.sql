@@ -0,0 +1,5 @@
+ALTER TABLE miners
+	DROP COLUMN payout_requested,
+	DROP COLUMN payout_weekday,
+	DROP COLUMN payout_hour,
+	DROP COLUMN payout_schedule;
@@ -0,0 +1,5 @@
+ALTER TABLE miners
+	ADD COLUMN payout_schedule		tinyint(1)		UNSIGNED NOT NULL DEFAULT 0 AFTER threshold,
+	ADD COLUMN payout_hour			tinyint(1)		UNSIGNED NOT NULL DEFAULT 0 AFTER payout_schedule,
+	ADD COLUMN payout_weekday		tinyint(1)		UNSIGNED NOT NULL DEFAULT 0 AFTER payout_hour,
+	ADD COLUMN payout_requested		bool			NOT NULL DEFAULT FALSE AFTER payout_weekday;
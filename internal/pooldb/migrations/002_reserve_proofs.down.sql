@@ -0,0 +1,2 @@
+DROP TABLE reserve_proof_leaves;
+DROP TABLE reserve_proofs;
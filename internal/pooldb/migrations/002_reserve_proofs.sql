@@ -0,0 +1,36 @@
+CREATE TABLE reserve_proofs (
+	id				bigint			UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	chain_id		varchar(4)		NOT NULL,
+
+	merkle_root		varchar(64)		NOT NULL,
+	nonce			varchar(64)		NOT NULL,
+	leaf_count		int				UNSIGNED NOT NULL,
+	liabilities		decimal(25,0)	NOT NULL,
+
+	wallet_address	varchar(100)	NOT NULL,
+	wallet_balance	decimal(25,0)	NOT NULL,
+	message			varchar(255)	NOT NULL,
+	signature		varchar(255),
+
+	created_at		datetime		NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	CONSTRAINT fk_reserve_proofs_chain_id
+	FOREIGN KEY (chain_id)			REFERENCES	chains(id),
+
+	INDEX idx_reserve_proofs_chain_id (chain_id)
+);
+
+CREATE TABLE reserve_proof_leaves (
+	reserve_proof_id	bigint			UNSIGNED NOT NULL,
+	miner_id			int				UNSIGNED NOT NULL,
+	idx					int				UNSIGNED NOT NULL,
+	value				decimal(25,0)	NOT NULL,
+
+	CONSTRAINT fk_reserve_proof_leaves_reserve_proof_id
+	FOREIGN KEY (reserve_proof_id)	REFERENCES	reserve_proofs(id),
+	CONSTRAINT fk_reserve_proof_leaves_miner_id
+	FOREIGN KEY (miner_id)			REFERENCES	miners(id),
+
+	PRIMARY KEY (reserve_proof_id, idx),
+	INDEX idx_reserve_proof_leaves_miner_id (miner_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE rounds
+	ADD COLUMN self_paid		bool			NOT NULL DEFAULT FALSE AFTER solo;
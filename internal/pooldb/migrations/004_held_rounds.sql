@@ -0,0 +1,2 @@
+ALTER TABLE rounds
+	ADD COLUMN held			bool			NOT NULL DEFAULT FALSE AFTER spent;
@@ -0,0 +1 @@
+DROP TABLE miner_share_audits;
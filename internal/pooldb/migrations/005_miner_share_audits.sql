@@ -0,0 +1,28 @@
+CREATE TABLE miner_share_audits (
+	miner_id					int				UNSIGNED NOT NULL,
+	chain_id					varchar(4)		NOT NULL,
+
+	accepted_shares				bigint			UNSIGNED NOT NULL DEFAULT 0,
+	high_diff_shares			bigint			UNSIGNED NOT NULL DEFAULT 0,
+	near_block_shares			bigint			UNSIGNED NOT NULL DEFAULT 0,
+	early_shares				bigint			UNSIGNED NOT NULL DEFAULT 0,
+	found_blocks				int				UNSIGNED NOT NULL DEFAULT 0,
+	expected_blocks				double			NOT NULL DEFAULT 0,
+	expected_near_block_shares	double			NOT NULL DEFAULT 0,
+
+	flagged						bool			NOT NULL DEFAULT FALSE,
+	excluded					bool			NOT NULL DEFAULT FALSE,
+	flag_reason					varchar(255),
+
+	created_at					datetime		NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at					datetime		NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	CONSTRAINT fk_miner_share_audits_chain_id
+	FOREIGN KEY (chain_id)			REFERENCES	chains(id),
+	CONSTRAINT fk_miner_share_audits_miner_id
+	FOREIGN KEY (miner_id)			REFERENCES	miners(id),
+
+	PRIMARY KEY (miner_id, chain_id),
+	INDEX idx_miner_share_audits_chain_id (chain_id),
+	INDEX idx_miner_share_audits_flagged (flagged)
+);
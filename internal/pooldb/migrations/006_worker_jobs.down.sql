@@ -0,0 +1,2 @@
+DROP TABLE worker_job_runs;
+DROP TABLE worker_jobs;
@@ -0,0 +1,26 @@
+CREATE TABLE worker_jobs (
+	name			varchar(50)		NOT NULL PRIMARY KEY,
+
+	paused			bool			NOT NULL DEFAULT FALSE,
+	schedule		varchar(100),
+
+	created_at		datetime		NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at		datetime		NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE worker_job_runs (
+	id				bigint			UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	job_name		varchar(50)		NOT NULL,
+
+	manual			bool			NOT NULL DEFAULT FALSE,
+	status			varchar(20)		NOT NULL,
+	error_count		int				UNSIGNED NOT NULL DEFAULT 0,
+	error_message	text,
+	lock_holder		varchar(255),
+
+	started_at		datetime		NOT NULL,
+	ended_at		datetime,
+
+	INDEX idx_worker_job_runs_job_name_started_at (job_name, started_at),
+	INDEX idx_worker_job_runs_started_at (started_at)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE miner_share_audits
+	DROP COLUMN banned;
+DROP TABLE admin_audit_logs;
@@ -0,0 +1,23 @@
+CREATE TABLE admin_audit_logs (
+	id				bigint			UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	ref_id			bigint			UNSIGNED,
+
+	actor			varchar(50)		NOT NULL,
+	role			varchar(20)		NOT NULL,
+	action			varchar(50)		NOT NULL,
+	target			varchar(100)	NOT NULL,
+	params			text,
+	status			varchar(20)		NOT NULL,
+	error_message	text,
+
+	created_at		datetime		NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	CONSTRAINT fk_admin_audit_logs_ref_id
+	FOREIGN KEY (ref_id)			REFERENCES	admin_audit_logs(id),
+
+	INDEX idx_admin_audit_logs_actor (actor),
+	INDEX idx_admin_audit_logs_created_at (created_at)
+);
+
+ALTER TABLE miner_share_audits
+	ADD COLUMN banned		bool			NOT NULL DEFAULT FALSE AFTER excluded;
@@ -0,0 +1 @@
+DROP TABLE pplns_window_snapshots;
@@ -0,0 +1,14 @@
+CREATE TABLE pplns_window_snapshots (
+	id				bigint			UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	chain_id		varchar(4)		NOT NULL,
+
+	share_count		bigint			UNSIGNED NOT NULL,
+	data			longblob		NOT NULL,
+
+	created_at		datetime(6)		NOT NULL,
+
+	CONSTRAINT fk_pplns_window_snapshots_chain_id
+	FOREIGN KEY (chain_id)			REFERENCES	chains(id),
+
+	INDEX idx_pplns_window_snapshots_chain_id_created_at (chain_id, created_at)
+);
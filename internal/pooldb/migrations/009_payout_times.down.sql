@@ -0,0 +1,2 @@
+ALTER TABLE miners
+	DROP COLUMN payout_time;
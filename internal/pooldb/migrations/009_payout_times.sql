@@ -0,0 +1,2 @@
+ALTER TABLE miners
+	ADD COLUMN payout_time			datetime		AFTER payout_weekday;
@@ -0,0 +1,4 @@
+DROP TABLE round_recipients;
+
+ALTER TABLE rounds
+	DROP COLUMN tx_fees;
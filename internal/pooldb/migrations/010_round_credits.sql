@@ -0,0 +1,19 @@
+ALTER TABLE rounds
+	ADD COLUMN tx_fees			decimal(25,0)	AFTER value;
+
+CREATE TABLE round_recipients (
+	id				bigint			UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	round_id		int         	UNSIGNED NOT NULL,
+	miner_id		int				UNSIGNED NOT NULL,
+
+	fee_percent		int				UNSIGNED NOT NULL,
+
+	created_at		datetime		NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+	CONSTRAINT fk_round_recipients_round_id
+	FOREIGN KEY (round_id)			REFERENCES	rounds(id),
+	CONSTRAINT fk_round_recipients_miner_id
+	FOREIGN KEY (miner_id)			REFERENCES	miners(id),
+
+	INDEX idx_round_recipients_round_id (round_id)
+);
@@ -0,0 +1,2 @@
+-- +lock none
+CREATE INDEX idx_miner_share_audits_chain_id_banned ON miner_share_audits (chain_id, banned);
@@ -0,0 +1,2 @@
+ALTER TABLE payouts
+	DROP COLUMN early_fees;
@@ -0,0 +1,2 @@
+ALTER TABLE payouts
+	ADD COLUMN early_fees		decimal(25,0)	AFTER tx_fees;
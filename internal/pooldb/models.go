@@ -49,6 +49,12 @@ type Miner struct {
 	Email     *string         `db:"email"`
 	Threshold dbcl.NullBigInt `db:"threshold"`
 
+	PayoutSchedule  int        `db:"payout_schedule"`
+	PayoutHour      int        `db:"payout_hour"`
+	PayoutWeekday   int        `db:"payout_weekday"`
+	PayoutTime      *time.Time `db:"payout_time"`
+	PayoutRequested bool       `db:"payout_requested"`
+
 	Active                     bool `db:"active"`
 	EnabledWorkerNotifications bool `db:"enabled_worker_notifications"`
 	EnabledPayoutNotifications bool `db:"enabled_payout_notifications"`
@@ -61,6 +67,9 @@ type Miner struct {
 	// column not present in the table, only
 	// helpful for a specific join query (GetActiveWorkers)
 	LastShare time.Time `db:"last_share"`
+	// column not present in the table, only helpful for
+	// a specific join query (GetMinersWithPayoutScheduleByChain)
+	LastPayout *time.Time `db:"last_payout"`
 }
 
 type Worker struct {
@@ -102,8 +111,9 @@ type Round struct {
 	MinerID uint64 `db:"miner_id"`
 	// column not present in the table, only
 	// helpful for a specific join query (GetRounds)
-	Miner *string `db:"miner"`
-	Solo  bool    `db:"solo"`
+	Miner    *string `db:"miner"`
+	Solo     bool    `db:"solo"`
+	SelfPaid bool    `db:"self_paid"`
 
 	Height      uint64  `db:"height"`
 	UncleHeight *uint64 `db:"uncle_height"`
@@ -115,6 +125,9 @@ type Round struct {
 	Solution     *string         `db:"solution"`
 	CoinbaseTxID *string         `db:"coinbase_txid"`
 	Value        dbcl.NullBigInt `db:"value"`
+	// the part of the value that comes from transaction fees, only
+	// set for chains where the node reports it separately
+	TxFees dbcl.NullBigInt `db:"tx_fees"`
 
 	AcceptedShares uint64  `db:"accepted_shares"`
 	RejectedShares uint64  `db:"rejected_shares"`
@@ -131,6 +144,7 @@ type Round struct {
 	Orphan  bool `db:"orphan"`
 	Mature  bool `db:"mature"`
 	Spent   bool `db:"spent"`
+	Held    bool `db:"held"`
 
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
@@ -146,6 +160,17 @@ type Share struct {
 	CreatedAt time.Time `db:"created_at"`
 }
 
+// RoundRecipient is a fee recipient of a round, as it was when the round was credited.
+type RoundRecipient struct {
+	ID      uint64 `db:"id"`
+	RoundID uint64 `db:"round_id"`
+	MinerID uint64 `db:"miner_id"`
+
+	FeePercent uint64 `db:"fee_percent"`
+
+	CreatedAt time.Time `db:"created_at"`
+}
+
 /* transaction */
 
 type Transaction struct {
@@ -301,6 +326,7 @@ type Payout struct {
 	PoolFees     dbcl.NullBigInt `db:"pool_fees"`
 	ExchangeFees dbcl.NullBigInt `db:"exchange_fees"`
 	TxFees       dbcl.NullBigInt `db:"tx_fees"`
+	EarlyFees    dbcl.NullBigInt `db:"early_fees"`
 	Pending      bool            `db:"pending"`
 	Confirmed    bool            `db:"confirmed"`
 	Failed       bool            `db:"failed"`
@@ -364,3 +390,108 @@ type BalanceSum struct {
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
+
+/* reserves */
+
+type ReserveProof struct {
+	ID      uint64 `db:"id"`
+	ChainID string `db:"chain_id"`
+
+	MerkleRoot  string          `db:"merkle_root"`
+	Nonce       string          `db:"nonce"`
+	LeafCount   uint64          `db:"leaf_count"`
+	Liabilities dbcl.NullBigInt `db:"liabilities"`
+
+	WalletAddress string          `db:"wallet_address"`
+	WalletBalance dbcl.NullBigInt `db:"wallet_balance"`
+	Message       string          `db:"message"`
+	Signature     *string         `db:"signature"`
+
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type ReserveProofLeaf struct {
+	ReserveProofID uint64          `db:"reserve_proof_id"`
+	MinerID        uint64          `db:"miner_id"`
+	Idx            uint64          `db:"idx"`
+	Value          dbcl.NullBigInt `db:"value"`
+}
+
+/* share audits */
+
+type MinerShareAudit struct {
+	MinerID uint64 `db:"miner_id"`
+	ChainID string `db:"chain_id"`
+
+	AcceptedShares          uint64  `db:"accepted_shares"`
+	HighDiffShares          uint64  `db:"high_diff_shares"`
+	NearBlockShares         uint64  `db:"near_block_shares"`
+	EarlyShares             uint64  `db:"early_shares"`
+	FoundBlocks             uint64  `db:"found_blocks"`
+	ExpectedBlocks          float64 `db:"expected_blocks"`
+	ExpectedNearBlockShares float64 `db:"expected_near_block_shares"`
+
+	Flagged    bool    `db:"flagged"`
+	Excluded   bool    `db:"excluded"`
+	Banned     bool    `db:"banned"`
+	FlagReason *string `db:"flag_reason"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+/* worker jobs */
+
+type WorkerJob struct {
+	Name string `db:"name"`
+
+	Paused   bool    `db:"paused"`
+	Schedule *string `db:"schedule"`
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type WorkerJobRun struct {
+	ID      uint64 `db:"id"`
+	JobName string `db:"job_name"`
+
+	Manual       bool    `db:"manual"`
+	Status       string  `db:"status"`
+	ErrorCount   uint64  `db:"error_count"`
+	ErrorMessage *string `db:"error_message"`
+	LockHolder   *string `db:"lock_holder"`
+
+	StartedAt time.Time  `db:"started_at"`
+	EndedAt   *time.Time `db:"ended_at"`
+}
+
+/* pplns window snapshots */
+
+// WindowSnapshot is a copy of the PPLNS window in redis, encoded by journal.EncodeWindow.
+type WindowSnapshot struct {
+	ID      uint64 `db:"id"`
+	ChainID string `db:"chain_id"`
+
+	ShareCount uint64 `db:"share_count"`
+	Data       []byte `db:"data"`
+
+	CreatedAt time.Time `db:"created_at"`
+}
+
+/* admin */
+
+type AdminAuditLog struct {
+	ID    uint64  `db:"id"`
+	RefID *uint64 `db:"ref_id"`
+
+	Actor        string  `db:"actor"`
+	Role         string  `db:"role"`
+	Action       string  `db:"action"`
+	Target       string  `db:"target"`
+	Params       *string `db:"params"`
+	Status       string  `db:"status"`
+	ErrorMessage *string `db:"error_message"`
+
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -89,6 +89,19 @@ func GetMinerIDByChainAddress(q dbcl.Querier, chain, address string) (uint64, er
 	return dbcl.GetUint64(q, query, chain, address)
 }
 
+func GetMinersByChain(q dbcl.Querier, chain string) ([]*Miner, error) {
+	const query = `SELECT *
+	FROM miners
+	WHERE
+		chain_id = ?
+	ORDER BY id;`
+
+	output := []*Miner{}
+	err := q.Select(&output, query, chain)
+
+	return output, err
+}
+
 func GetMinerAddress(q dbcl.Querier, minerID uint64) (string, error) {
 	const query = `SELECT address
 	FROM miners
@@ -386,6 +399,25 @@ func GetRoundsByChain(q dbcl.Querier, chain string, page, size uint64) ([]*Round
 	return output, err
 }
 
+func GetRoundsByChainAndTimeRange(q dbcl.Querier, chain string, start, end time.Time) ([]*Round, error) {
+	const query = `SELECT *
+	FROM rounds
+	WHERE
+		chain_id = ?
+	AND
+		solo = FALSE
+	AND
+		value IS NOT NULL
+	AND
+		created_at BETWEEN ? AND ?
+	ORDER BY created_at`
+
+	output := []*Round{}
+	err := q.Select(&output, query, chain, start, end)
+
+	return output, err
+}
+
 func GetRoundsCount(q dbcl.Querier) (uint64, error) {
 	const query = `SELECT count(id)
 	FROM rounds`
@@ -503,6 +535,26 @@ func GetUnspentRoundsByChain(q dbcl.Querier, chain string) ([]*Round, error) {
 	return output, err
 }
 
+// GetRevalidationRoundsByChain returns every unlocked, non-orphaned round at or above
+// minHeight, along with any held round regardless of height.
+func GetRevalidationRoundsByChain(q dbcl.Querier, chain string, minHeight uint64) ([]*Round, error) {
+	const query = `SELECT *
+	FROM rounds
+	WHERE
+		pending = FALSE
+	AND
+		orphan = FALSE
+	AND
+		chain_id = ?
+	AND
+		(height >= ? OR held = TRUE);`
+
+	output := []*Round{}
+	err := q.Select(&output, query, chain, minHeight)
+
+	return output, err
+}
+
 func GetRoundLuckByChain(
 	q dbcl.Querier,
 	chain string,
@@ -535,6 +587,18 @@ func GetSharesByRound(q dbcl.Querier, roundID uint64) ([]*Share, error) {
 	return output, err
 }
 
+func GetRoundRecipients(q dbcl.Querier, roundID uint64) ([]*RoundRecipient, error) {
+	const query = `SELECT *
+	FROM round_recipients
+	WHERE
+		round_id = ?`
+
+	output := []*RoundRecipient{}
+	err := q.Select(&output, query, roundID)
+
+	return output, err
+}
+
 /* utxos */
 
 func GetUnspentUTXOsByChain(q dbcl.Querier, chainID string) ([]*UTXO, error) {
@@ -555,6 +619,29 @@ func GetUnspentUTXOsByChain(q dbcl.Querier, chainID string) ([]*UTXO, error) {
 	return output, err
 }
 
+func GetUTXOsByTxIDs(q dbcl.Querier, chainID string, txids []string) ([]*UTXO, error) {
+	const rawQuery = `SELECT *
+	FROM utxos
+	WHERE
+		chain_id = ?
+	AND
+		txid IN (?);`
+
+	output := []*UTXO{}
+	if len(txids) == 0 {
+		return output, nil
+	}
+
+	query, args, err := sqlx.In(rawQuery, chainID, txids)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.Select(&output, query, args...)
+
+	return output, err
+}
+
 func GetUTXOsByTransactionID(q dbcl.Querier, transactionID uint64) ([]*UTXO, error) {
 	const query = `SELECT *
 	FROM utxos
@@ -689,6 +776,25 @@ func GetExchangeBatch(q dbcl.Querier, batchID uint64) (*ExchangeBatch, error) {
 	return output, nil
 }
 
+func GetExchangeBatches(q dbcl.Querier, page, size uint64) ([]*ExchangeBatch, error) {
+	const query = `SELECT *
+	FROM exchange_batches
+	ORDER BY id DESC
+	LIMIT ? OFFSET ?;`
+
+	output := []*ExchangeBatch{}
+	err := q.Select(&output, query, size, page*size)
+
+	return output, err
+}
+
+func GetExchangeBatchesCount(q dbcl.Querier) (uint64, error) {
+	const query = `SELECT count(id)
+	FROM exchange_batches;`
+
+	return dbcl.GetUint64(q, query)
+}
+
 func GetActiveExchangeBatches(q dbcl.Querier, exchangeID uint64) ([]*ExchangeBatch, error) {
 	const query = `SELECT *
 	FROM exchange_batches
@@ -883,6 +989,8 @@ func GetExchangeWithdrawals(q dbcl.Querier, batchID uint64) ([]*ExchangeWithdraw
 
 /* balance queries */
 
+// GetPendingBalanceInputsWithoutBatch skips balance inputs from rounds that
+// are held after a reorg, so they are never exchanged while held.
 func GetPendingBalanceInputsWithoutBatch(q dbcl.Querier) ([]*BalanceInput, error) {
 	const query = `SELECT *
 	FROM balance_inputs
@@ -891,7 +999,9 @@ func GetPendingBalanceInputsWithoutBatch(q dbcl.Querier) ([]*BalanceInput, error
 	AND
 		mature = TRUE
 	AND
-		batch_id IS NULL;`
+		batch_id IS NULL
+	AND
+		round_id NOT IN (SELECT id FROM rounds WHERE held = TRUE);`
 
 	output := []*BalanceInput{}
 	err := q.Select(&output, query)
@@ -911,6 +1021,8 @@ func GetPendingBalanceInputsSumWithoutBatch(q dbcl.Querier) ([]*BalanceInput, er
 		mature = TRUE
 	AND
 		batch_id IS NULL
+	AND
+		round_id NOT IN (SELECT id FROM rounds WHERE held = TRUE)
 	GROUP BY chain_id, out_chain_id;`
 
 	output := []*BalanceInput{}
@@ -943,6 +1055,19 @@ func GetBalanceInputsByRound(q dbcl.Querier, roundID uint64) ([]*BalanceInput, e
 	return output, err
 }
 
+func GetBalanceOutputsByRound(q dbcl.Querier, roundID uint64) ([]*BalanceOutput, error) {
+	const query = `SELECT balance_outputs.*
+	FROM balance_outputs
+	JOIN balance_inputs ON balance_inputs.balance_output_id = balance_outputs.id
+	WHERE
+		balance_inputs.round_id = ?;`
+
+	output := []*BalanceOutput{}
+	err := q.Select(&output, query, roundID)
+
+	return output, err
+}
+
 func GetImmatureBalanceInputSumByChain(q dbcl.Querier, chain string) (*big.Int, error) {
 	const query = `SELECT sum(value)
 	FROM balance_inputs
@@ -1124,19 +1249,71 @@ func GetMinersWithBalanceAboveThresholdByChain(
 	WHERE
 		miners.chain_id = ?
 	AND
-		balance_sums.mature_value > ?
+		balance_sums.mature_value >= IFNULL(NULLIF(miners.threshold, 0), ?)
 	AND
 	    payouts.id IS NULL;`
 
-	// AND
-	//     balance_sums.mature_value >= IFNULL(miners.threshold, ?)
-
 	output := []*Miner{}
 	err := q.Select(&output, query, chain, threshold)
 
 	return output, err
 }
 
+func GetMinersWithHeldBalanceByChain(q dbcl.Querier, chain string) ([]*Miner, error) {
+	const query = `SELECT DISTINCT miners.*
+	FROM miners
+	JOIN balance_inputs ON
+	        miners.id = balance_inputs.miner_id
+	JOIN rounds ON
+	        balance_inputs.round_id = rounds.id
+	WHERE
+		balance_inputs.out_chain_id = ?
+	AND
+		rounds.held = TRUE;`
+
+	output := []*Miner{}
+	err := q.Select(&output, query, chain)
+
+	return output, err
+}
+
+func GetMinersWithPayoutScheduleByChain(
+	q dbcl.Querier,
+	chain, minValue string,
+) ([]*Miner, error) {
+	const query = `WITH cte AS (
+		SELECT
+			miner_id,
+			MAX(created_at) last_payout
+		FROM payouts
+		GROUP BY miner_id
+	) SELECT DISTINCT miners.*, cte.last_payout
+	FROM miners
+	JOIN balance_sums ON
+	        miners.id = balance_sums.miner_id
+	    AND
+	        miners.chain_id = balance_sums.chain_id
+	LEFT OUTER JOIN cte ON
+	        miners.id = cte.miner_id
+	LEFT OUTER JOIN payouts on
+	        miners.id = payouts.miner_id
+	    AND
+	        payouts.confirmed = FALSE
+	WHERE
+		miners.chain_id = ?
+	AND
+		balance_sums.mature_value >= ?
+	AND
+		(miners.payout_schedule != 0 OR miners.payout_requested = TRUE)
+	AND
+	    payouts.id IS NULL;`
+
+	output := []*Miner{}
+	err := q.Select(&output, query, chain, minValue)
+
+	return output, err
+}
+
 func GetBalanceSumsByMinerIDs(
 	q dbcl.Querier,
 	minerIDs []uint64,
@@ -1164,6 +1341,23 @@ func GetBalanceSumsByMinerIDs(
 
 /* payout */
 
+func GetPayout(q dbcl.Querier, id uint64) (*Payout, error) {
+	const query = `SELECT *
+	FROM payouts
+	WHERE
+		id = ?;`
+
+	output := new(Payout)
+	err := q.Get(output, query, id)
+	if err != nil && err != sql.ErrNoRows {
+		return output, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return output, nil
+}
+
 func GetUnconfirmedPayouts(
 	q dbcl.Querier,
 	chain string,
@@ -1183,6 +1377,22 @@ func GetUnconfirmedPayouts(
 	return output, err
 }
 
+func GetPayoutsByTransaction(
+	q dbcl.Querier,
+	transactionID uint64,
+) ([]*Payout, error) {
+	const query = `SELECT *
+	FROM payouts
+	WHERE
+		transaction_id = ?
+	ORDER BY id;`
+
+	output := []*Payout{}
+	err := q.Select(&output, query, transactionID)
+
+	return output, err
+}
+
 func GetUnconfirmedPayoutSum(
 	q dbcl.Querier,
 	chain string,
@@ -1306,3 +1516,241 @@ func GetPayoutBalanceInputSums(
 
 	return output, err
 }
+
+/* reserves */
+
+// GetComputedBalanceSumsByChain derives the balance sums from the balance inputs and outputs
+// instead of reading the balance_sums table: immature inputs are the immature value, mature
+// inputs that still have to be exchanged and unspent mature outputs are the mature value.
+func GetComputedBalanceSumsByChain(
+	q dbcl.Querier,
+	chain string,
+) ([]*BalanceSum, error) {
+	const query = `SELECT
+		miner_id,
+		chain_id,
+		sum(immature_value) immature_value,
+		sum(mature_value) mature_value
+	FROM (
+		SELECT
+			miner_id,
+			chain_id,
+			IF(mature = FALSE, value, 0) immature_value,
+			IF(mature = TRUE AND pending = TRUE, value, 0) mature_value
+		FROM balance_inputs
+		WHERE
+			chain_id = ?
+		UNION ALL
+		SELECT
+			miner_id,
+			chain_id,
+			0 immature_value,
+			value mature_value
+		FROM balance_outputs
+		WHERE
+			chain_id = ?
+		AND
+			mature = TRUE
+		AND
+			spent = FALSE
+	) balances
+	GROUP BY miner_id, chain_id
+	ORDER BY miner_id;`
+
+	output := []*BalanceSum{}
+	err := q.Select(&output, query, chain, chain)
+
+	return output, err
+}
+
+func GetBalanceSumsByChain(
+	q dbcl.Querier,
+	chain string,
+) ([]*BalanceSum, error) {
+	const query = `SELECT *
+	FROM balance_sums
+	WHERE
+		chain_id = ?
+	ORDER BY miner_id;`
+
+	output := []*BalanceSum{}
+	err := q.Select(&output, query, chain)
+
+	return output, err
+}
+
+func GetLastReserveProofs(
+	q dbcl.Querier,
+) ([]*ReserveProof, error) {
+	const query = `SELECT reserve_proofs.*
+	FROM reserve_proofs
+	JOIN (
+		SELECT chain_id, MAX(id) AS id
+		FROM reserve_proofs
+		GROUP BY chain_id
+	) last_reserve_proofs ON reserve_proofs.id = last_reserve_proofs.id
+	ORDER BY reserve_proofs.chain_id;`
+
+	output := []*ReserveProof{}
+	err := q.Select(&output, query)
+
+	return output, err
+}
+
+func GetReserveProofLeaves(
+	q dbcl.Querier,
+	reserveProofID uint64,
+) ([]*ReserveProofLeaf, error) {
+	const query = `SELECT *
+	FROM reserve_proof_leaves
+	WHERE
+		reserve_proof_id = ?
+	ORDER BY idx;`
+
+	output := []*ReserveProofLeaf{}
+	err := q.Select(&output, query, reserveProofID)
+
+	return output, err
+}
+
+/* share audits */
+
+func GetMinerShareAuditsByChain(q dbcl.Querier, chain string) ([]*MinerShareAudit, error) {
+	const query = `SELECT *
+	FROM miner_share_audits
+	WHERE
+		chain_id = ?
+	ORDER BY miner_id;`
+
+	output := []*MinerShareAudit{}
+	err := q.Select(&output, query, chain)
+
+	return output, err
+}
+
+func GetFlaggedMinerShareAudits(q dbcl.Querier) ([]*MinerShareAudit, error) {
+	const query = `SELECT *
+	FROM miner_share_audits
+	WHERE
+		flagged = TRUE
+	ORDER BY chain_id, miner_id;`
+
+	output := []*MinerShareAudit{}
+	err := q.Select(&output, query)
+
+	return output, err
+}
+
+func GetExcludedMinerShareAuditsByChain(q dbcl.Querier, chain string) ([]*MinerShareAudit, error) {
+	const query = `SELECT *
+	FROM miner_share_audits
+	WHERE
+		chain_id = ?
+	AND
+		(excluded = TRUE OR banned = TRUE);`
+
+	output := []*MinerShareAudit{}
+	err := q.Select(&output, query, chain)
+
+	return output, err
+}
+
+func GetBannedMinerShareAuditsByChain(q dbcl.Querier, chain string) ([]*MinerShareAudit, error) {
+	const query = `SELECT *
+	FROM miner_share_audits
+	WHERE
+		chain_id = ?
+	AND
+		banned = TRUE;`
+
+	output := []*MinerShareAudit{}
+	err := q.Select(&output, query, chain)
+
+	return output, err
+}
+
+/* admin */
+
+func GetAdminAuditLogs(q dbcl.Querier, page, size uint64) ([]*AdminAuditLog, error) {
+	const query = `SELECT *
+	FROM admin_audit_logs
+	ORDER BY id DESC
+	LIMIT ? OFFSET ?;`
+
+	output := []*AdminAuditLog{}
+	err := q.Select(&output, query, size, page*size)
+
+	return output, err
+}
+
+func GetAdminAuditLogsCount(q dbcl.Querier) (uint64, error) {
+	const query = `SELECT count(id)
+	FROM admin_audit_logs;`
+
+	return dbcl.GetUint64(q, query)
+}
+
+/* pplns window snapshots */
+
+func GetLastWindowSnapshot(q dbcl.Querier, chain string) (*WindowSnapshot, error) {
+	const query = `SELECT *
+	FROM pplns_window_snapshots
+	WHERE
+		chain_id = ?
+	ORDER BY created_at DESC
+	LIMIT 1;`
+
+	output := new(WindowSnapshot)
+	err := q.Get(output, query, chain)
+	if err != nil && err != sql.ErrNoRows {
+		return output, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return output, nil
+}
+
+/* worker jobs */
+
+func GetWorkerJobs(q dbcl.Querier) ([]*WorkerJob, error) {
+	const query = `SELECT *
+	FROM worker_jobs
+	ORDER BY name;`
+
+	output := []*WorkerJob{}
+	err := q.Select(&output, query)
+
+	return output, err
+}
+
+func GetWorkerJob(q dbcl.Querier, name string) (*WorkerJob, error) {
+	const query = `SELECT *
+	FROM worker_jobs
+	WHERE
+		name = ?`
+
+	output := new(WorkerJob)
+	err := q.Get(output, query, name)
+	if err != nil && err != sql.ErrNoRows {
+		return output, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return output, nil
+}
+
+func GetWorkerJobRuns(q dbcl.Querier, jobName string, limit uint64) ([]*WorkerJobRun, error) {
+	const query = `SELECT *
+	FROM worker_job_runs
+	WHERE
+		job_name = ?
+	ORDER BY id DESC
+	LIMIT ?;`
+
+	output := []*WorkerJobRun{}
+	err := q.Select(&output, query, jobName, limit)
+
+	return output, err
+}
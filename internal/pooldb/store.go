@@ -0,0 +1,529 @@
+package pooldb
+
+import (
+	"github.com/magicpool-co/pool/pkg/dbcl"
+)
+
+/* repositories */
+
+// MinerRepository holds the miners, workers and share audits.
+type MinerRepository interface {
+	GetMiner(id uint64) (*Miner, error)
+	GetMiners(ids []uint64) ([]*Miner, error)
+	GetMinerAddress(id uint64) (string, error)
+	GetMinerIDByChainAddress(chain, address string) (uint64, error)
+	GetRecipients() ([]*Miner, error)
+	GetMinersWithBalanceAboveThresholdByChain(chain, threshold string) ([]*Miner, error)
+	GetMinersWithPayoutScheduleByChain(chain, minValue string) ([]*Miner, error)
+	GetMinersWithHeldBalanceByChain(chain string) ([]*Miner, error)
+	InsertMiner(obj *Miner) (uint64, error)
+	UpdateMiner(obj *Miner, updateCols []string) error
+
+	GetWorkerID(minerID uint64, name string) (uint64, error)
+	InsertWorker(obj *Worker) (uint64, error)
+
+	GetExcludedMinerShareAuditsByChain(chain string) ([]*MinerShareAudit, error)
+	GetBannedMinerShareAuditsByChain(chain string) ([]*MinerShareAudit, error)
+	InsertAddMinerShareAudits(objects ...*MinerShareAudit) error
+}
+
+// ShareRepository holds the shares credited to a round.
+type ShareRepository interface {
+	GetSharesByRound(roundID uint64) ([]*Share, error)
+	InsertShares(objects ...*Share) error
+}
+
+// RoundRepository holds the rounds (blocks found by the pool).
+type RoundRepository interface {
+	GetRound(id uint64) (*Round, error)
+	GetPendingRoundsByChain(chain string, maxHeight uint64) ([]*Round, error)
+	GetImmatureRoundsByChain(chain string, maxHeight uint64) ([]*Round, error)
+	GetUnspentRoundsByChain(chain string) ([]*Round, error)
+	GetRevalidationRoundsByChain(chain string, minHeight uint64) ([]*Round, error)
+	InsertRound(obj *Round) (uint64, error)
+	UpdateRound(obj *Round, updateCols []string) error
+
+	GetRoundRecipients(roundID uint64) ([]*RoundRecipient, error)
+	InsertRoundRecipients(objects ...*RoundRecipient) error
+	DeleteRoundRecipientsByRound(roundID uint64) error
+}
+
+// BalanceRepository holds the balance inputs, outputs and sums.
+type BalanceRepository interface {
+	GetBalanceInputsByRound(roundID uint64) ([]*BalanceInput, error)
+	GetPendingBalanceInputsWithoutBatch() ([]*BalanceInput, error)
+	GetPendingBalanceInputsSumWithoutBatch() ([]*BalanceInput, error)
+	GetBalanceInputsByBatch(batchID uint64) ([]*BalanceInput, error)
+	InsertBalanceInputs(objects ...*BalanceInput) error
+	UpdateBalanceInput(obj *BalanceInput, updateCols []string) error
+	UpdateBalanceInputsSetMatureByRound(roundID uint64) error
+	UpdateBalanceInputsUnsetBatch(batchID uint64) error
+	DeleteBalanceInputsByRound(roundID uint64) error
+
+	GetBalanceOutputsByRound(roundID uint64) ([]*BalanceOutput, error)
+	GetBalanceOutputsByPayout(payoutID uint64) ([]*BalanceOutput, error)
+	GetBalanceOutputsByBatch(batchID uint64) ([]*BalanceOutput, error)
+	GetUnpaidBalanceOutputsByMiner(minerID uint64, chain string) ([]*BalanceOutput, error)
+	InsertBalanceOutput(obj *BalanceOutput) (uint64, error)
+	InsertBalanceOutputs(objects ...*BalanceOutput) error
+	UpdateBalanceOutput(obj *BalanceOutput, updateCols []string) error
+	UpdateBalanceOutputsSetMatureByRound(roundID uint64) error
+	DeleteBalanceOutputsByID(ids ...uint64) error
+
+	GetBalanceSumsByChain(chain string) ([]*BalanceSum, error)
+	InsertAddBalanceSums(objects ...*BalanceSum) error
+	InsertSubtractBalanceSums(objects ...*BalanceSum) error
+}
+
+// PayoutRepository holds the payouts and the wallet side of the
+// pool (transactions and UTXOs).
+type PayoutRepository interface {
+	GetPayout(id uint64) (*Payout, error)
+	GetUnconfirmedPayouts(chain string) ([]*Payout, error)
+	GetPayoutsByTransaction(transactionID uint64) ([]*Payout, error)
+	InsertPayout(obj *Payout) (uint64, error)
+	UpdatePayout(obj *Payout, updateCols []string) error
+
+	GetTransaction(id uint64) (*Transaction, error)
+	GetTransactionByTxID(txid string) (*Transaction, error)
+	InsertTransaction(obj *Transaction) (uint64, error)
+
+	GetUTXOsByTxIDs(chain string, txids []string) ([]*UTXO, error)
+	InsertUTXOs(objects ...*UTXO) error
+	UpdateUTXO(obj *UTXO, updateCols []string) error
+}
+
+// ExchangeRepository holds the exchange batches along with their
+// inputs, deposits, trades and withdrawals.
+type ExchangeRepository interface {
+	GetExchangeBatch(batchID uint64) (*ExchangeBatch, error)
+	GetActiveExchangeBatches(exchangeID uint64) ([]*ExchangeBatch, error)
+	InsertExchangeBatch(obj *ExchangeBatch) (uint64, error)
+	UpdateExchangeBatch(obj *ExchangeBatch, updateCols []string) error
+	UpdateExchangeBatchStatus(obj *ExchangeBatch, prevStatus int) (bool, error)
+
+	GetExchangeInputs(batchID uint64) ([]*ExchangeInput, error)
+	InsertExchangeInputs(objects ...*ExchangeInput) error
+	DeleteExchangeInputsByBatch(batchID uint64) error
+
+	GetExchangeDeposits(batchID uint64) ([]*ExchangeDeposit, error)
+	InsertExchangeDeposit(obj *ExchangeDeposit) (uint64, error)
+	UpdateExchangeDeposit(obj *ExchangeDeposit, updateCols []string) error
+
+	GetExchangeTrades(batchID uint64) ([]*ExchangeTrade, error)
+	GetExchangeTradesByStage(batchID uint64, stage int) ([]*ExchangeTrade, error)
+	GetExchangeTradeByPathAndStage(batchID uint64, path, stage int) (*ExchangeTrade, error)
+	GetFinalExchangeTrades(batchID uint64) ([]*ExchangeTrade, error)
+	InsertExchangeTrades(objects ...*ExchangeTrade) error
+	UpdateExchangeTrade(obj *ExchangeTrade, updateCols []string) error
+
+	GetExchangeWithdrawals(batchID uint64) ([]*ExchangeWithdrawal, error)
+	InsertExchangeWithdrawal(obj *ExchangeWithdrawal) (uint64, error)
+	UpdateExchangeWithdrawal(obj *ExchangeWithdrawal, updateCols []string) error
+}
+
+// Store groups the repositories of the pool database. it is implemented on top of
+// MySQL (NewStore, NewTxStore) and in memory (NewMemoryStore) for unit tests.
+type Store interface {
+	Miners() MinerRepository
+	Shares() ShareRepository
+	Rounds() RoundRepository
+	Balances() BalanceRepository
+	Payouts() PayoutRepository
+	Exchanges() ExchangeRepository
+
+	// Transact runs fn with a store bound to a single transaction, committing it if
+	// fn returns nil and rolling it back otherwise. calling Transact on a store that
+	// is already bound to a transaction runs fn within that transaction.
+	Transact(fn func(Store) error) error
+}
+
+/* mysql */
+
+type sqlStore struct {
+	client *dbcl.Client
+	reader dbcl.Querier
+	writer dbcl.Querier
+}
+
+// NewStore returns a store that reads from the reader and writes to the writer of client.
+func NewStore(client *dbcl.Client) Store {
+	store := &sqlStore{
+		client: client,
+		reader: client.Reader(),
+		writer: client.Writer(),
+	}
+
+	return store
+}
+
+// NewTxStore returns a store bound to a transaction owned by the caller, which
+// is neither committed nor rolled back by the store.
+func NewTxStore(tx *dbcl.Tx) Store {
+	store := &sqlStore{
+		reader: tx,
+		writer: tx,
+	}
+
+	return store
+}
+
+func (s *sqlStore) Miners() MinerRepository       { return s }
+func (s *sqlStore) Shares() ShareRepository       { return s }
+func (s *sqlStore) Rounds() RoundRepository       { return s }
+func (s *sqlStore) Balances() BalanceRepository   { return s }
+func (s *sqlStore) Payouts() PayoutRepository     { return s }
+func (s *sqlStore) Exchanges() ExchangeRepository { return s }
+
+func (s *sqlStore) Transact(fn func(Store) error) error {
+	if s.client == nil {
+		return fn(s)
+	}
+
+	tx, err := s.client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.SafeRollback()
+
+	err = fn(NewTxStore(tx))
+	if err != nil {
+		return err
+	}
+
+	return tx.SafeCommit()
+}
+
+/* mysql miners */
+
+func (s *sqlStore) GetMiner(id uint64) (*Miner, error) {
+	return GetMiner(s.reader, id)
+}
+
+func (s *sqlStore) GetMiners(ids []uint64) ([]*Miner, error) {
+	return GetMiners(s.reader, ids)
+}
+
+func (s *sqlStore) GetMinerAddress(id uint64) (string, error) {
+	return GetMinerAddress(s.reader, id)
+}
+
+// GetMinerIDByChainAddress reads from the writer, since it is used
+// to check for a miner right before inserting it.
+func (s *sqlStore) GetMinerIDByChainAddress(chain, address string) (uint64, error) {
+	return GetMinerIDByChainAddress(s.writer, chain, address)
+}
+
+func (s *sqlStore) GetRecipients() ([]*Miner, error) {
+	return GetRecipients(s.reader)
+}
+
+func (s *sqlStore) GetMinersWithBalanceAboveThresholdByChain(chain, threshold string) ([]*Miner, error) {
+	return GetMinersWithBalanceAboveThresholdByChain(s.reader, chain, threshold)
+}
+
+func (s *sqlStore) GetMinersWithPayoutScheduleByChain(chain, minValue string) ([]*Miner, error) {
+	return GetMinersWithPayoutScheduleByChain(s.reader, chain, minValue)
+}
+
+func (s *sqlStore) GetMinersWithHeldBalanceByChain(chain string) ([]*Miner, error) {
+	return GetMinersWithHeldBalanceByChain(s.reader, chain)
+}
+
+func (s *sqlStore) InsertMiner(obj *Miner) (uint64, error) {
+	return InsertMiner(s.writer, obj)
+}
+
+func (s *sqlStore) UpdateMiner(obj *Miner, updateCols []string) error {
+	return UpdateMiner(s.writer, obj, updateCols)
+}
+
+// GetWorkerID reads from the writer, since it is used to check
+// for a worker right before inserting it.
+func (s *sqlStore) GetWorkerID(minerID uint64, name string) (uint64, error) {
+	return GetWorkerID(s.writer, minerID, name)
+}
+
+func (s *sqlStore) InsertWorker(obj *Worker) (uint64, error) {
+	return InsertWorker(s.writer, obj)
+}
+
+func (s *sqlStore) GetExcludedMinerShareAuditsByChain(chain string) ([]*MinerShareAudit, error) {
+	return GetExcludedMinerShareAuditsByChain(s.reader, chain)
+}
+
+func (s *sqlStore) GetBannedMinerShareAuditsByChain(chain string) ([]*MinerShareAudit, error) {
+	return GetBannedMinerShareAuditsByChain(s.reader, chain)
+}
+
+func (s *sqlStore) InsertAddMinerShareAudits(objects ...*MinerShareAudit) error {
+	return InsertAddMinerShareAudits(s.writer, objects...)
+}
+
+/* mysql shares */
+
+func (s *sqlStore) GetSharesByRound(roundID uint64) ([]*Share, error) {
+	return GetSharesByRound(s.reader, roundID)
+}
+
+func (s *sqlStore) InsertShares(objects ...*Share) error {
+	return InsertShares(s.writer, objects...)
+}
+
+/* mysql rounds */
+
+func (s *sqlStore) GetRound(id uint64) (*Round, error) {
+	return GetRound(s.reader, id)
+}
+
+func (s *sqlStore) GetPendingRoundsByChain(chain string, maxHeight uint64) ([]*Round, error) {
+	return GetPendingRoundsByChain(s.reader, chain, maxHeight)
+}
+
+func (s *sqlStore) GetImmatureRoundsByChain(chain string, maxHeight uint64) ([]*Round, error) {
+	return GetImmatureRoundsByChain(s.reader, chain, maxHeight)
+}
+
+func (s *sqlStore) GetUnspentRoundsByChain(chain string) ([]*Round, error) {
+	return GetUnspentRoundsByChain(s.reader, chain)
+}
+
+func (s *sqlStore) GetRevalidationRoundsByChain(chain string, minHeight uint64) ([]*Round, error) {
+	return GetRevalidationRoundsByChain(s.reader, chain, minHeight)
+}
+
+func (s *sqlStore) InsertRound(obj *Round) (uint64, error) {
+	return InsertRound(s.writer, obj)
+}
+
+func (s *sqlStore) UpdateRound(obj *Round, updateCols []string) error {
+	return UpdateRound(s.writer, obj, updateCols)
+}
+
+func (s *sqlStore) GetRoundRecipients(roundID uint64) ([]*RoundRecipient, error) {
+	return GetRoundRecipients(s.reader, roundID)
+}
+
+func (s *sqlStore) InsertRoundRecipients(objects ...*RoundRecipient) error {
+	return InsertRoundRecipients(s.writer, objects...)
+}
+
+func (s *sqlStore) DeleteRoundRecipientsByRound(roundID uint64) error {
+	return DeleteRoundRecipientsByRound(s.writer, roundID)
+}
+
+/* mysql balances */
+
+func (s *sqlStore) GetBalanceInputsByRound(roundID uint64) ([]*BalanceInput, error) {
+	return GetBalanceInputsByRound(s.reader, roundID)
+}
+
+func (s *sqlStore) GetPendingBalanceInputsWithoutBatch() ([]*BalanceInput, error) {
+	return GetPendingBalanceInputsWithoutBatch(s.reader)
+}
+
+func (s *sqlStore) GetPendingBalanceInputsSumWithoutBatch() ([]*BalanceInput, error) {
+	return GetPendingBalanceInputsSumWithoutBatch(s.reader)
+}
+
+func (s *sqlStore) GetBalanceInputsByBatch(batchID uint64) ([]*BalanceInput, error) {
+	return GetBalanceInputsByBatch(s.reader, batchID)
+}
+
+func (s *sqlStore) InsertBalanceInputs(objects ...*BalanceInput) error {
+	return InsertBalanceInputs(s.writer, objects...)
+}
+
+func (s *sqlStore) UpdateBalanceInput(obj *BalanceInput, updateCols []string) error {
+	return UpdateBalanceInput(s.writer, obj, updateCols)
+}
+
+func (s *sqlStore) UpdateBalanceInputsSetMatureByRound(roundID uint64) error {
+	return UpdateBalanceInputsSetMatureByRound(s.writer, roundID)
+}
+
+func (s *sqlStore) UpdateBalanceInputsUnsetBatch(batchID uint64) error {
+	return UpdateBalanceInputsUnsetBatch(s.writer, batchID)
+}
+
+func (s *sqlStore) DeleteBalanceInputsByRound(roundID uint64) error {
+	return DeleteBalanceInputsByRound(s.writer, roundID)
+}
+
+func (s *sqlStore) GetBalanceOutputsByRound(roundID uint64) ([]*BalanceOutput, error) {
+	return GetBalanceOutputsByRound(s.reader, roundID)
+}
+
+func (s *sqlStore) GetBalanceOutputsByPayout(payoutID uint64) ([]*BalanceOutput, error) {
+	return GetBalanceOutputsByPayout(s.reader, payoutID)
+}
+
+func (s *sqlStore) GetBalanceOutputsByBatch(batchID uint64) ([]*BalanceOutput, error) {
+	return GetBalanceOutputsByBatch(s.reader, batchID)
+}
+
+func (s *sqlStore) GetUnpaidBalanceOutputsByMiner(minerID uint64, chain string) ([]*BalanceOutput, error) {
+	return GetUnpaidBalanceOutputsByMiner(s.reader, minerID, chain)
+}
+
+func (s *sqlStore) InsertBalanceOutput(obj *BalanceOutput) (uint64, error) {
+	return InsertBalanceOutput(s.writer, obj)
+}
+
+func (s *sqlStore) InsertBalanceOutputs(objects ...*BalanceOutput) error {
+	return InsertBalanceOutputs(s.writer, objects...)
+}
+
+func (s *sqlStore) UpdateBalanceOutput(obj *BalanceOutput, updateCols []string) error {
+	return UpdateBalanceOutput(s.writer, obj, updateCols)
+}
+
+func (s *sqlStore) UpdateBalanceOutputsSetMatureByRound(roundID uint64) error {
+	return UpdateBalanceOutputsSetMatureByRound(s.writer, roundID)
+}
+
+func (s *sqlStore) DeleteBalanceOutputsByID(ids ...uint64) error {
+	return DeleteBalanceOutputsByID(s.writer, ids...)
+}
+
+func (s *sqlStore) GetBalanceSumsByChain(chain string) ([]*BalanceSum, error) {
+	return GetBalanceSumsByChain(s.reader, chain)
+}
+
+func (s *sqlStore) InsertAddBalanceSums(objects ...*BalanceSum) error {
+	return InsertAddBalanceSums(s.writer, objects...)
+}
+
+func (s *sqlStore) InsertSubtractBalanceSums(objects ...*BalanceSum) error {
+	return InsertSubtractBalanceSums(s.writer, objects...)
+}
+
+/* mysql payouts */
+
+func (s *sqlStore) GetPayout(id uint64) (*Payout, error) {
+	return GetPayout(s.reader, id)
+}
+
+func (s *sqlStore) GetUnconfirmedPayouts(chain string) ([]*Payout, error) {
+	return GetUnconfirmedPayouts(s.reader, chain)
+}
+
+func (s *sqlStore) GetPayoutsByTransaction(transactionID uint64) ([]*Payout, error) {
+	return GetPayoutsByTransaction(s.reader, transactionID)
+}
+
+func (s *sqlStore) InsertPayout(obj *Payout) (uint64, error) {
+	return InsertPayout(s.writer, obj)
+}
+
+func (s *sqlStore) UpdatePayout(obj *Payout, updateCols []string) error {
+	return UpdatePayout(s.writer, obj, updateCols)
+}
+
+func (s *sqlStore) GetTransaction(id uint64) (*Transaction, error) {
+	return GetTransaction(s.reader, id)
+}
+
+func (s *sqlStore) GetTransactionByTxID(txid string) (*Transaction, error) {
+	return GetTransactionByTxID(s.reader, txid)
+}
+
+func (s *sqlStore) InsertTransaction(obj *Transaction) (uint64, error) {
+	return InsertTransaction(s.writer, obj)
+}
+
+func (s *sqlStore) GetUTXOsByTxIDs(chain string, txids []string) ([]*UTXO, error) {
+	return GetUTXOsByTxIDs(s.reader, chain, txids)
+}
+
+func (s *sqlStore) InsertUTXOs(objects ...*UTXO) error {
+	return InsertUTXOs(s.writer, objects...)
+}
+
+func (s *sqlStore) UpdateUTXO(obj *UTXO, updateCols []string) error {
+	return UpdateUTXO(s.writer, obj, updateCols)
+}
+
+/* mysql exchanges */
+
+func (s *sqlStore) GetExchangeBatch(batchID uint64) (*ExchangeBatch, error) {
+	return GetExchangeBatch(s.reader, batchID)
+}
+
+func (s *sqlStore) GetActiveExchangeBatches(exchangeID uint64) ([]*ExchangeBatch, error) {
+	return GetActiveExchangeBatches(s.reader, exchangeID)
+}
+
+func (s *sqlStore) InsertExchangeBatch(obj *ExchangeBatch) (uint64, error) {
+	return InsertExchangeBatch(s.writer, obj)
+}
+
+func (s *sqlStore) UpdateExchangeBatch(obj *ExchangeBatch, updateCols []string) error {
+	return UpdateExchangeBatch(s.writer, obj, updateCols)
+}
+
+func (s *sqlStore) UpdateExchangeBatchStatus(obj *ExchangeBatch, prevStatus int) (bool, error) {
+	return UpdateExchangeBatchStatus(s.writer, obj, prevStatus)
+}
+
+func (s *sqlStore) GetExchangeInputs(batchID uint64) ([]*ExchangeInput, error) {
+	return GetExchangeInputs(s.reader, batchID)
+}
+
+func (s *sqlStore) InsertExchangeInputs(objects ...*ExchangeInput) error {
+	return InsertExchangeInputs(s.writer, objects...)
+}
+
+func (s *sqlStore) DeleteExchangeInputsByBatch(batchID uint64) error {
+	return DeleteExchangeInputsByBatch(s.writer, batchID)
+}
+
+func (s *sqlStore) GetExchangeDeposits(batchID uint64) ([]*ExchangeDeposit, error) {
+	return GetExchangeDeposits(s.reader, batchID)
+}
+
+func (s *sqlStore) InsertExchangeDeposit(obj *ExchangeDeposit) (uint64, error) {
+	return InsertExchangeDeposit(s.writer, obj)
+}
+
+func (s *sqlStore) UpdateExchangeDeposit(obj *ExchangeDeposit, updateCols []string) error {
+	return UpdateExchangeDeposit(s.writer, obj, updateCols)
+}
+
+func (s *sqlStore) GetExchangeTrades(batchID uint64) ([]*ExchangeTrade, error) {
+	return GetExchangeTrades(s.reader, batchID)
+}
+
+// GetExchangeTradesByStage reads from the writer, since the trades of a stage
+// are initiated right after the previous stage has been written.
+func (s *sqlStore) GetExchangeTradesByStage(batchID uint64, stage int) ([]*ExchangeTrade, error) {
+	return GetExchangeTradesByStage(s.writer, batchID, stage)
+}
+
+func (s *sqlStore) GetExchangeTradeByPathAndStage(batchID uint64, path, stage int) (*ExchangeTrade, error) {
+	return GetExchangeTradeByPathAndStage(s.reader, batchID, path, stage)
+}
+
+func (s *sqlStore) GetFinalExchangeTrades(batchID uint64) ([]*ExchangeTrade, error) {
+	return GetFinalExchangeTrades(s.reader, batchID)
+}
+
+func (s *sqlStore) InsertExchangeTrades(objects ...*ExchangeTrade) error {
+	return InsertExchangeTrades(s.writer, objects...)
+}
+
+func (s *sqlStore) UpdateExchangeTrade(obj *ExchangeTrade, updateCols []string) error {
+	return UpdateExchangeTrade(s.writer, obj, updateCols)
+}
+
+func (s *sqlStore) GetExchangeWithdrawals(batchID uint64) ([]*ExchangeWithdrawal, error) {
+	return GetExchangeWithdrawals(s.reader, batchID)
+}
+
+func (s *sqlStore) InsertExchangeWithdrawal(obj *ExchangeWithdrawal) (uint64, error) {
+	return InsertExchangeWithdrawal(s.writer, obj)
+}
+
+func (s *sqlStore) UpdateExchangeWithdrawal(obj *ExchangeWithdrawal, updateCols []string) error {
+	return UpdateExchangeWithdrawal(s.writer, obj, updateCols)
+}
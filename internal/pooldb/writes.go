@@ -2,6 +2,7 @@ package pooldb
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
@@ -115,7 +116,7 @@ func InsertIPAddresses(q dbcl.Querier, objects ...*IPAddress) error {
 func InsertRound(q dbcl.Querier, obj *Round) (uint64, error) {
 	const table = "rounds"
 	cols := []string{
-		"chain_id", "miner_id", "solo", "height", "epoch_height", "uncle_height",
+		"chain_id", "miner_id", "solo", "self_paid", "height", "epoch_height", "uncle_height",
 		"hash", "nonce", "mix_digest", "coinbase_txid", "value", "difficulty",
 		"luck", "accepted_shares", "rejected_shares", "invalid_shares", "mature",
 		"pending", "uncle", "orphan", "spent",
@@ -143,6 +144,28 @@ func InsertShares(q dbcl.Querier, objects ...*Share) error {
 	return dbcl.ExecBulkInsert(q, table, cols, rawObjects)
 }
 
+func InsertRoundRecipients(q dbcl.Querier, objects ...*RoundRecipient) error {
+	const table = "round_recipients"
+	cols := []string{"round_id", "miner_id", "fee_percent"}
+
+	rawObjects := make([]interface{}, len(objects))
+	for i, object := range objects {
+		rawObjects[i] = object
+	}
+
+	return dbcl.ExecBulkInsert(q, table, cols, rawObjects)
+}
+
+func DeleteRoundRecipientsByRound(q dbcl.Querier, roundID uint64) error {
+	const query = `DELETE FROM round_recipients
+	WHERE
+		round_id = ?;`
+
+	_, err := q.Exec(query, roundID)
+
+	return err
+}
+
 /* utxos */
 
 func InsertUTXO(q dbcl.Querier, obj *UTXO) (uint64, error) {
@@ -214,6 +237,29 @@ func UpdateExchangeBatch(q dbcl.Querier, obj *ExchangeBatch, updateCols []string
 	return dbcl.ExecUpdate(q, table, updateCols, whereCols, true, obj)
 }
 
+// UpdateExchangeBatchStatus only updates the status (and completed at) if the
+// batch is still in prevStatus, returning false if no batch was updated.
+func UpdateExchangeBatchStatus(q dbcl.Querier, obj *ExchangeBatch, prevStatus int) (bool, error) {
+	const query = `UPDATE exchange_batches
+	SET status = ?, completed_at = ?, updated_at = CURRENT_TIMESTAMP
+	WHERE
+		id = ?
+	AND
+		status = ?;`
+
+	res, err := q.Exec(query, obj.Status, obj.CompletedAt, obj.ID, prevStatus)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 func InsertExchangeInputs(q dbcl.Querier, objects ...*ExchangeInput) error {
 	const table = "exchange_inputs"
 	cols := []string{"batch_id", "in_chain_id", "out_chain_id", "value"}
@@ -226,6 +272,16 @@ func InsertExchangeInputs(q dbcl.Querier, objects ...*ExchangeInput) error {
 	return dbcl.ExecBulkInsert(q, table, cols, rawObjects)
 }
 
+func DeleteExchangeInputsByBatch(q dbcl.Querier, batchID uint64) error {
+	const query = `DELETE FROM exchange_inputs
+	WHERE
+		batch_id = ?;`
+
+	_, err := q.Exec(query, batchID)
+
+	return err
+}
+
 func InsertExchangeDeposit(q dbcl.Querier, obj *ExchangeDeposit) (uint64, error) {
 	const table = "exchange_deposits"
 	cols := []string{
@@ -313,6 +369,17 @@ func UpdateBalanceInput(q dbcl.Querier, obj *BalanceInput, updateCols []string)
 	return dbcl.ExecUpdate(q, table, updateCols, whereCols, true, obj)
 }
 
+func UpdateBalanceInputsUnsetBatch(q dbcl.Querier, batchID uint64) error {
+	const query = `UPDATE balance_inputs
+	SET batch_id = NULL
+	WHERE
+		batch_id = ?;`
+
+	_, err := q.Exec(query, batchID)
+
+	return err
+}
+
 func UpdateBalanceInputsSetMatureByRound(q dbcl.Querier, roundID uint64) error {
 	const query = `UPDATE balance_inputs
 	SET mature = TRUE
@@ -424,6 +491,27 @@ func InsertAddBalanceSums(q dbcl.Querier, objects ...*BalanceSum) error {
 	return dbcl.ExecBulkInsertUpdateAdd(q, table, insertCols, updateCols, rawObjects)
 }
 
+func InsertOverwriteBalanceSums(q dbcl.Querier, objects ...*BalanceSum) error {
+	const table = "balance_sums"
+	insertCols := []string{"miner_id", "chain_id", "immature_value", "mature_value"}
+	updateCols := []string{"immature_value", "mature_value"}
+
+	rawObjects := make([]interface{}, len(objects))
+	for i, object := range objects {
+		if !object.ImmatureValue.Valid {
+			object.ImmatureValue = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)}
+		}
+
+		if !object.MatureValue.Valid {
+			object.MatureValue = dbcl.NullBigInt{Valid: true, BigInt: new(big.Int)}
+		}
+
+		rawObjects[i] = object
+	}
+
+	return dbcl.ExecBulkInsertUpdateOverwrite(q, table, insertCols, updateCols, rawObjects)
+}
+
 func InsertSubtractBalanceSums(q dbcl.Querier, objects ...*BalanceSum) error {
 	const table = "balance_sums"
 	insertCols := []string{"miner_id", "chain_id", "immature_value", "mature_value"}
@@ -452,7 +540,7 @@ func InsertPayout(q dbcl.Querier, obj *Payout) (uint64, error) {
 	cols := []string{
 		"chain_id", "miner_id", "address", "transaction_id", "txid",
 		"height", "value", "fee_balance", "pool_fees", "exchange_fees",
-		"tx_fees", "pending", "confirmed", "failed",
+		"tx_fees", "early_fees", "pending", "confirmed", "failed",
 	}
 
 	return dbcl.ExecInsert(q, table, cols, obj)
@@ -464,3 +552,119 @@ func UpdatePayout(q dbcl.Querier, obj *Payout, updateCols []string) error {
 
 	return dbcl.ExecUpdate(q, table, updateCols, whereCols, true, obj)
 }
+
+/* reserves */
+
+func InsertReserveProof(q dbcl.Querier, obj *ReserveProof) (uint64, error) {
+	const table = "reserve_proofs"
+	cols := []string{
+		"chain_id", "merkle_root", "nonce", "leaf_count", "liabilities",
+		"wallet_address", "wallet_balance", "message", "signature",
+	}
+
+	return dbcl.ExecInsert(q, table, cols, obj)
+}
+
+func InsertReserveProofLeaves(q dbcl.Querier, objects ...*ReserveProofLeaf) error {
+	const table = "reserve_proof_leaves"
+	cols := []string{"reserve_proof_id", "miner_id", "idx", "value"}
+
+	rawObjects := make([]interface{}, len(objects))
+	for i, object := range objects {
+		rawObjects[i] = object
+	}
+
+	return dbcl.ExecBulkInsert(q, table, cols, rawObjects)
+}
+
+/* share audits */
+
+func InsertAddMinerShareAudits(q dbcl.Querier, objects ...*MinerShareAudit) error {
+	const table = "miner_share_audits"
+	insertCols := []string{
+		"miner_id", "chain_id", "accepted_shares", "high_diff_shares", "near_block_shares",
+		"early_shares", "found_blocks", "expected_blocks", "expected_near_block_shares",
+	}
+	updateCols := []string{
+		"accepted_shares", "high_diff_shares", "near_block_shares", "early_shares",
+		"found_blocks", "expected_blocks", "expected_near_block_shares",
+	}
+
+	rawObjects := make([]interface{}, len(objects))
+	for i, object := range objects {
+		rawObjects[i] = object
+	}
+
+	return dbcl.ExecBulkInsertUpdateAdd(q, table, insertCols, updateCols, rawObjects)
+}
+
+func UpdateMinerShareAudit(q dbcl.Querier, obj *MinerShareAudit, updateCols []string) error {
+	const table = "miner_share_audits"
+	whereCols := []string{"miner_id", "chain_id"}
+
+	return dbcl.ExecUpdate(q, table, updateCols, whereCols, true, obj)
+}
+
+/* admin */
+
+// admin audit logs are append-only, there is intentionally no update or delete
+func InsertAdminAuditLog(q dbcl.Querier, obj *AdminAuditLog) (uint64, error) {
+	const table = "admin_audit_logs"
+	cols := []string{"ref_id", "actor", "role", "action", "target", "params", "status", "error_message"}
+
+	return dbcl.ExecInsert(q, table, cols, obj)
+}
+
+/* pplns window snapshots */
+
+func InsertWindowSnapshot(q dbcl.Querier, obj *WindowSnapshot) (uint64, error) {
+	const table = "pplns_window_snapshots"
+	cols := []string{"chain_id", "share_count", "data", "created_at"}
+
+	return dbcl.ExecInsert(q, table, cols, obj)
+}
+
+func DeleteWindowSnapshotsBefore(q dbcl.Querier, chain string, cutoff time.Time) error {
+	const query = `DELETE FROM pplns_window_snapshots
+	WHERE
+		chain_id = ?
+	AND
+		created_at < ?;`
+
+	_, err := q.Exec(query, chain, cutoff)
+
+	return err
+}
+
+/* worker jobs */
+
+func InsertUpdateWorkerJob(q dbcl.Querier, obj *WorkerJob, updateCols []string) error {
+	const table = "worker_jobs"
+	insertCols := []string{"name", "paused", "schedule"}
+
+	return dbcl.ExecBulkInsertUpdateOverwrite(q, table, insertCols, updateCols, []interface{}{obj})
+}
+
+func InsertWorkerJobRun(q dbcl.Querier, obj *WorkerJobRun) (uint64, error) {
+	const table = "worker_job_runs"
+	cols := []string{"job_name", "manual", "status", "lock_holder", "started_at"}
+
+	return dbcl.ExecInsert(q, table, cols, obj)
+}
+
+func UpdateWorkerJobRun(q dbcl.Querier, obj *WorkerJobRun, updateCols []string) error {
+	const table = "worker_job_runs"
+	whereCols := []string{"id"}
+
+	return dbcl.ExecUpdate(q, table, updateCols, whereCols, false, obj)
+}
+
+func DeleteWorkerJobRunsBefore(q dbcl.Querier, cutoff time.Time) error {
+	const query = `DELETE FROM worker_job_runs
+	WHERE
+		started_at < ?;`
+
+	_, err := q.Exec(query, cutoff)
+
+	return err
+}
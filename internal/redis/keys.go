@@ -80,6 +80,10 @@ func (c *Client) getRoundSharesKey(chain string) string {
 	return c.getKey("pool", strings.ToLower(chain), "shr")
 }
 
+func (c *Client) getRoundSharesMarkerKey(chain string) string {
+	return c.getKey("pool", strings.ToLower(chain), "shr", "mrkr")
+}
+
 func (c *Client) getRoundAcceptedSharesKey(chain string) string {
 	return c.getKey("pool", strings.ToLower(chain), "ash")
 }
@@ -200,3 +204,9 @@ func (c *Client) getCachedMinersByChainKey(chain string) string {
 func (c *Client) getCachedWorkersByChainKey(chain string) string {
 	return c.getKey("cache", "wrkrs", strings.ToLower(chain))
 }
+
+/* reorg */
+
+func (c *Client) getReorgChainTipsKey(chain string) string {
+	return c.getKey("reorg", "tips", strings.ToLower(chain))
+}
@@ -0,0 +1,561 @@
+package redis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MemoryShareStore is an in-memory ShareStore for unit tests. it keeps the same keys
+// as Client (with the "memory" environment), holding strings, sets, lists and sorted
+// sets in maps, so it behaves like the pipelines of Client without a redis server.
+type MemoryShareStore struct {
+	mu   sync.Mutex
+	keys *Client
+
+	strings map[string]string
+	sets    map[string]map[string]bool
+	lists   map[string][]string
+	zsets   map[string]map[string]float64
+}
+
+func NewMemoryShareStore() *MemoryShareStore {
+	store := &MemoryShareStore{
+		keys:    &Client{env: "memory"},
+		strings: make(map[string]string),
+		sets:    make(map[string]map[string]bool),
+		lists:   make(map[string][]string),
+		zsets:   make(map[string]map[string]float64),
+	}
+
+	return store
+}
+
+var _ ShareStore = (*MemoryShareStore)(nil)
+
+/* helpers */
+
+func (s *MemoryShareStore) getUint64(key string) (uint64, error) {
+	value, ok := s.strings[key]
+	if !ok {
+		return 0, nil
+	}
+
+	return strconv.ParseUint(value, 10, 64)
+}
+
+func (s *MemoryShareStore) incrBy(key string, count int) error {
+	value, err := s.getUint64(key)
+	if err != nil {
+		return err
+	}
+	s.strings[key] = strconv.FormatInt(int64(value)+int64(count), 10)
+
+	return nil
+}
+
+// sAdd returns true if the member was not in the set yet.
+func (s *MemoryShareStore) sAdd(key, member string) bool {
+	if _, ok := s.sets[key]; !ok {
+		s.sets[key] = make(map[string]bool)
+	} else if s.sets[key][member] {
+		return false
+	}
+	s.sets[key][member] = true
+
+	return true
+}
+
+func (s *MemoryShareStore) sMembers(key string) []string {
+	members := make([]string, 0, len(s.sets[key]))
+	for member := range s.sets[key] {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	return members
+}
+
+func (s *MemoryShareStore) zIncrBy(key string, increment float64, member string) {
+	if _, ok := s.zsets[key]; !ok {
+		s.zsets[key] = make(map[string]float64)
+	}
+	s.zsets[key][member] += increment
+}
+
+func (s *MemoryShareStore) zAddBulk(key string, values map[string]int64) {
+	if _, ok := s.zsets[key]; !ok {
+		s.zsets[key] = make(map[string]float64)
+	}
+
+	for member, score := range values {
+		s.zsets[key][member] = float64(score)
+	}
+}
+
+func (s *MemoryShareStore) zRange(key string) map[string]float64 {
+	if _, ok := s.zsets[key]; !ok {
+		return nil
+	}
+
+	values := make(map[string]float64, len(s.zsets[key]))
+	for member, score := range s.zsets[key] {
+		values[member] = score
+	}
+
+	return values
+}
+
+func (s *MemoryShareStore) zRangeUint64(key string) map[string]uint64 {
+	values := make(map[string]uint64)
+	for member, score := range s.zsets[key] {
+		values[member] = uint64(score)
+	}
+
+	return values
+}
+
+/* miners/workers */
+
+func (s *MemoryShareStore) GetMinerID(miner string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.getUint64(s.keys.getMinersKey(miner))
+}
+
+func (s *MemoryShareStore) SetMinerID(miner string, minerID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.strings[s.keys.getMinersKey(miner)] = strconv.FormatUint(minerID, 10)
+
+	return nil
+}
+
+func (s *MemoryShareStore) GetWorkerID(minerID uint64, worker string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.getUint64(s.keys.getWorkersKey(minerID, worker))
+}
+
+func (s *MemoryShareStore) SetWorkerID(minerID uint64, worker string, workerID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.strings[s.keys.getWorkersKey(minerID, worker)] = strconv.FormatUint(workerID, 10)
+
+	return nil
+}
+
+func (s *MemoryShareStore) GetMinerIPAddresses(chain string) (map[string]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.zRange(s.keys.getMinerIPAddressesKey(chain)), nil
+}
+
+func (s *MemoryShareStore) GetMinerDifficulties(chain string) (map[string]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.zRange(s.keys.getMinerDifficultiesKey(chain)), nil
+}
+
+func (s *MemoryShareStore) GetMinerLatencies(chain string) (map[string]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.zRange(s.keys.getMinerLatenciesKey(chain)), nil
+}
+
+func (s *MemoryShareStore) SetMinerIPAddressesBulk(chain string, values map[string]int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.zAddBulk(s.keys.getMinerIPAddressesKey(chain), values)
+
+	return nil
+}
+
+func (s *MemoryShareStore) SetMinerDifficultiesBulk(chain string, values map[string]int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.zAddBulk(s.keys.getMinerDifficultiesKey(chain), values)
+
+	return nil
+}
+
+func (s *MemoryShareStore) SetMinerLatenciesBulk(chain string, values map[string]int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.zAddBulk(s.keys.getMinerLatenciesKey(chain), values)
+
+	return nil
+}
+
+/* share indexes */
+
+func (s *MemoryShareStore) GetShareIndexes(chain string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sMembers(s.keys.getShareIndexKey(chain)), nil
+}
+
+func (s *MemoryShareStore) AddShareIndexHeight(chain string, height uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sAdd(s.keys.getShareIndexKey(chain), strconv.FormatUint(height, 10))
+
+	return nil
+}
+
+func (s *MemoryShareStore) DeleteShareIndexHeight(chain string, height uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sets, s.keys.getUniqueSharesKey(chain, height))
+	delete(s.sets[s.keys.getShareIndexKey(chain)], strconv.FormatUint(height, 10))
+
+	return nil
+}
+
+func (s *MemoryShareStore) AddUniqueShare(chain string, height uint64, hash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sAdd(s.keys.getUniqueSharesKey(chain, height), hash), nil
+}
+
+/* rounds */
+
+func (s *MemoryShareStore) GetRoundShares(chain string) (map[uint64]uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make(map[uint64]uint64)
+	for _, k := range s.lists[s.keys.getRoundSharesKey(chain)] {
+		parts := strings.Split(k, ":")
+		id, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[id]++
+	}
+
+	return values, nil
+}
+
+func (s *MemoryShareStore) GetRoundShareList(chain string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.lists[s.keys.getRoundSharesKey(chain)]
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	return append([]string{}, list...), nil
+}
+
+func (s *MemoryShareStore) GetRoundShareListLength(chain string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.lists[s.keys.getRoundSharesKey(chain)])), nil
+}
+
+func (s *MemoryShareStore) GetRoundSharesMarker(chain string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.strings[s.keys.getRoundSharesMarkerKey(chain)], nil
+}
+
+func (s *MemoryShareStore) GetRoundSoloShares(chain string, minerID uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.getUint64(s.keys.getRoundSoloAcceptedSharesKey(chain, minerID))
+}
+
+func (s *MemoryShareStore) GetRoundShareCounts(chain string, soloMinerID uint64) (uint64, uint64, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var acceptedKey, rejectedKey, invalidKey string
+	if soloMinerID == 0 {
+		acceptedKey = s.keys.getRoundAcceptedSharesKey(chain)
+		rejectedKey = s.keys.getRoundRejectedSharesKey(chain)
+		invalidKey = s.keys.getRoundInvalidSharesKey(chain)
+	} else {
+		acceptedKey = s.keys.getRoundSoloAcceptedSharesKey(chain, soloMinerID)
+		rejectedKey = s.keys.getRoundSoloRejectedSharesKey(chain, soloMinerID)
+		invalidKey = s.keys.getRoundSoloInvalidSharesKey(chain, soloMinerID)
+	}
+
+	counts := make([]uint64, 3)
+	for i, key := range []string{acceptedKey, rejectedKey, invalidKey} {
+		count, err := s.getUint64(key)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		counts[i] = count
+		s.strings[key] = "0"
+	}
+
+	return counts[0], counts[1], counts[2], nil
+}
+
+func (s *MemoryShareStore) AddAcceptedShare(
+	chain, interval, compoundID string,
+	soloMinerID uint64,
+	count int,
+	window int64,
+) error {
+	if count <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if soloMinerID == 0 {
+		key := s.keys.getRoundSharesKey(chain)
+		list := s.lists[key]
+		for i := 0; i < count; i++ {
+			list = append([]string{compoundID}, list...)
+		}
+		if int64(len(list)) > window {
+			list = list[:window]
+		}
+		s.lists[key] = list
+
+		err := s.incrBy(s.keys.getRoundAcceptedSharesKey(chain), count)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := s.incrBy(s.keys.getRoundSoloAcceptedSharesKey(chain, soloMinerID), count)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.zIncrBy(s.keys.getIntervalAcceptedSharesKey(chain, interval), float64(count), compoundID)
+	s.zIncrBy(s.keys.getIntervalAcceptedAdjustedSharesKey(chain, interval), 1, compoundID)
+
+	return nil
+}
+
+func (s *MemoryShareStore) AddRejectedShare(
+	chain, interval, compoundID string,
+	soloMinerID uint64,
+	count int,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.keys.getRoundRejectedSharesKey(chain)
+	if soloMinerID != 0 {
+		key = s.keys.getRoundSoloRejectedSharesKey(chain, soloMinerID)
+	}
+
+	err := s.incrBy(key, count)
+	if err != nil {
+		return err
+	}
+
+	s.zIncrBy(s.keys.getIntervalRejectedSharesKey(chain, interval), float64(count), compoundID)
+	s.zIncrBy(s.keys.getIntervalRejectedAdjustedSharesKey(chain, interval), 1, compoundID)
+
+	return nil
+}
+
+func (s *MemoryShareStore) AddInvalidShare(
+	chain, interval, compoundID string,
+	soloMinerID uint64,
+	count int,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.keys.getRoundInvalidSharesKey(chain)
+	if soloMinerID != 0 {
+		key = s.keys.getRoundSoloInvalidSharesKey(chain, soloMinerID)
+	}
+
+	err := s.incrBy(key, count)
+	if err != nil {
+		return err
+	}
+
+	s.zIncrBy(s.keys.getIntervalInvalidSharesKey(chain, interval), float64(count), compoundID)
+	s.zIncrBy(s.keys.getIntervalInvalidAdjustedSharesKey(chain, interval), 1, compoundID)
+
+	return nil
+}
+
+/* restore */
+
+func (s *MemoryShareStore) RestoreRoundShares(chain string, window []string, accepted uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lists[s.keys.getRoundSharesKey(chain)] = append([]string{}, window...)
+	s.strings[s.keys.getRoundAcceptedSharesKey(chain)] = strconv.FormatUint(accepted, 10)
+
+	return nil
+}
+
+func (s *MemoryShareStore) SetRoundSharesMarker(chain, marker string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.strings[s.keys.getRoundSharesMarkerKey(chain)] = marker
+
+	return nil
+}
+
+func (s *MemoryShareStore) RestoreRoundSoloShares(chain string, minerID, accepted uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.strings[s.keys.getRoundSoloAcceptedSharesKey(chain, minerID)] = strconv.FormatUint(accepted, 10)
+
+	return nil
+}
+
+func (s *MemoryShareStore) RestoreIntervalAcceptedShares(
+	chain, interval string,
+	accepted, adjusted map[string]uint64,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters := map[string]map[string]uint64{
+		s.keys.getIntervalAcceptedSharesKey(chain, interval):         accepted,
+		s.keys.getIntervalAcceptedAdjustedSharesKey(chain, interval): adjusted,
+	}
+
+	for key, values := range counters {
+		delete(s.zsets, key)
+		for member, score := range values {
+			s.zIncrBy(key, float64(score), member)
+		}
+	}
+	s.sAdd(s.keys.getIntervalsKey(chain), interval)
+
+	return nil
+}
+
+/* intervals */
+
+func (s *MemoryShareStore) GetIntervals(chain string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sMembers(s.keys.getIntervalsKey(chain)), nil
+}
+
+func (s *MemoryShareStore) AddInterval(chain, interval string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sAdd(s.keys.getIntervalsKey(chain), interval)
+
+	return nil
+}
+
+func (s *MemoryShareStore) GetIntervalAcceptedShares(chain, interval string) (
+	map[string]uint64,
+	map[string]uint64,
+	error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	raw := s.zRangeUint64(s.keys.getIntervalAcceptedSharesKey(chain, interval))
+	adjusted := s.zRangeUint64(s.keys.getIntervalAcceptedAdjustedSharesKey(chain, interval))
+
+	return raw, adjusted, nil
+}
+
+func (s *MemoryShareStore) GetIntervalRejectedShares(chain, interval string) (
+	map[string]uint64,
+	map[string]uint64,
+	error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	raw := s.zRangeUint64(s.keys.getIntervalRejectedSharesKey(chain, interval))
+	adjusted := s.zRangeUint64(s.keys.getIntervalRejectedAdjustedSharesKey(chain, interval))
+
+	return raw, adjusted, nil
+}
+
+func (s *MemoryShareStore) GetIntervalInvalidShares(chain, interval string) (
+	map[string]uint64,
+	map[string]uint64,
+	error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	raw := s.zRangeUint64(s.keys.getIntervalInvalidSharesKey(chain, interval))
+	adjusted := s.zRangeUint64(s.keys.getIntervalInvalidAdjustedSharesKey(chain, interval))
+
+	return raw, adjusted, nil
+}
+
+// MemoryTipStore is an in-memory TipStore for unit tests.
+type MemoryTipStore struct {
+	mu   sync.Mutex
+	tips map[string]map[uint64]string
+}
+
+func NewMemoryTipStore() *MemoryTipStore {
+	store := &MemoryTipStore{
+		tips: make(map[string]map[uint64]string),
+	}
+
+	return store
+}
+
+var _ TipStore = (*MemoryTipStore)(nil)
+
+func (s *MemoryTipStore) GetReorgChainTips(chain string) (map[uint64]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tips, ok := s.tips[chain]
+	if !ok {
+		return nil, nil
+	}
+
+	output := make(map[uint64]string, len(tips))
+	for height, hash := range tips {
+		output[height] = hash
+	}
+
+	return output, nil
+}
+
+func (s *MemoryTipStore) SetReorgChainTips(chain string, tips map[uint64]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tips[chain] = make(map[uint64]string, len(tips))
+	for height, hash := range tips {
+		s.tips[chain][height] = hash
+	}
+
+	return nil
+}
@@ -150,6 +150,38 @@ func (c *Client) GetRoundShares(chain string) (map[uint64]uint64, error) {
 	return values, nil
 }
 
+// GetRoundShareList returns the PPLNS window of chain as compound IDs, newest share first.
+func (c *Client) GetRoundShareList(chain string) ([]string, error) {
+	key := c.getRoundSharesKey(chain)
+	raw, err := c.readClient.LRange(context.Background(), key, 0, -1).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+
+	return raw, err
+}
+
+// GetRoundShareListLength returns the length of the PPLNS window of chain. it reads from
+// the write client, since a lagging replica would look like a window that has been reset.
+func (c *Client) GetRoundShareListLength(chain string) (int64, error) {
+	key := c.getRoundSharesKey(chain)
+
+	return c.writeClient.LLen(context.Background(), key).Result()
+}
+
+// GetRoundSharesMarker returns the marker set by the last journal rebuild of chain, which is
+// empty once redis has lost the share state (e.g. after a failover). like the window length,
+// it reads from the write client since the marker has usually just been set.
+func (c *Client) GetRoundSharesMarker(chain string) (string, error) {
+	key := c.getRoundSharesMarkerKey(chain)
+	value, err := c.writeClient.Get(context.Background(), key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+
+	return value, err
+}
+
 func (c *Client) GetRoundSoloShares(chain string, minerID uint64) (uint64, error) {
 	return c.baseGetUint64(c.getRoundSoloAcceptedSharesKey(chain, minerID))
 }
@@ -349,3 +381,22 @@ func (c *Client) GetCachedMinersByChain(chain string) (int64, error) {
 func (c *Client) GetCachedWorkersByChain(chain string) (int64, error) {
 	return c.baseGetInt64(c.getCachedWorkersByChainKey(chain))
 }
+
+/* reorg */
+
+func (c *Client) GetReorgChainTips(chain string) (map[uint64]string, error) {
+	encoded, err := c.baseGet(c.getReorgChainTipsKey(chain))
+	if err != nil {
+		return nil, err
+	} else if len(encoded) == 0 {
+		return nil, nil
+	}
+
+	var tips map[uint64]string
+	err = decode(encoded, &tips)
+	if err != nil {
+		return nil, err
+	}
+
+	return tips, nil
+}
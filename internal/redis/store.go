@@ -0,0 +1,54 @@
+package redis
+
+// ShareStore holds the share counters, the PPLNS window and the miner and worker id
+// caches that the pool writes for every share. it is implemented by Client and, for
+// unit tests, by MemoryShareStore.
+type ShareStore interface {
+	GetMinerID(miner string) (uint64, error)
+	SetMinerID(miner string, minerID uint64) error
+	GetWorkerID(minerID uint64, worker string) (uint64, error)
+	SetWorkerID(minerID uint64, worker string, workerID uint64) error
+
+	GetMinerIPAddresses(chain string) (map[string]float64, error)
+	GetMinerDifficulties(chain string) (map[string]float64, error)
+	GetMinerLatencies(chain string) (map[string]float64, error)
+	SetMinerIPAddressesBulk(chain string, values map[string]int64) error
+	SetMinerDifficultiesBulk(chain string, values map[string]int64) error
+	SetMinerLatenciesBulk(chain string, values map[string]int64) error
+
+	GetShareIndexes(chain string) ([]string, error)
+	AddShareIndexHeight(chain string, height uint64) error
+	DeleteShareIndexHeight(chain string, height uint64) error
+	AddUniqueShare(chain string, height uint64, hash string) (bool, error)
+
+	GetRoundShares(chain string) (map[uint64]uint64, error)
+	GetRoundShareList(chain string) ([]string, error)
+	GetRoundShareListLength(chain string) (int64, error)
+	GetRoundSharesMarker(chain string) (string, error)
+	GetRoundSoloShares(chain string, minerID uint64) (uint64, error)
+	GetRoundShareCounts(chain string, soloMinerID uint64) (uint64, uint64, uint64, error)
+	AddAcceptedShare(chain, interval, compoundID string, soloMinerID uint64, count int, window int64) error
+	AddRejectedShare(chain, interval, compoundID string, soloMinerID uint64, count int) error
+	AddInvalidShare(chain, interval, compoundID string, soloMinerID uint64, count int) error
+	RestoreRoundShares(chain string, window []string, accepted uint64) error
+	SetRoundSharesMarker(chain, marker string) error
+	RestoreRoundSoloShares(chain string, minerID, accepted uint64) error
+	RestoreIntervalAcceptedShares(chain, interval string, accepted, adjusted map[string]uint64) error
+
+	GetIntervals(chain string) ([]string, error)
+	AddInterval(chain, interval string) error
+	GetIntervalAcceptedShares(chain, interval string) (map[string]uint64, map[string]uint64, error)
+	GetIntervalRejectedShares(chain, interval string) (map[string]uint64, map[string]uint64, error)
+	GetIntervalInvalidShares(chain, interval string) (map[string]uint64, map[string]uint64, error)
+}
+
+var _ ShareStore = (*Client)(nil)
+
+// TipStore holds the recent chain tips that the reorg watcher compares between
+// runs. it is implemented by Client and, for unit tests, by MemoryTipStore.
+type TipStore interface {
+	GetReorgChainTips(chain string) (map[uint64]string, error)
+	SetReorgChainTips(chain string, tips map[uint64]string) error
+}
+
+var _ TipStore = (*Client)(nil)
@@ -229,6 +229,81 @@ func (c *Client) AddInvalidShare(
 	return err
 }
 
+/* restore */
+
+// RestoreRoundShares replaces the PPLNS window (newest share first) and the
+// accepted share counter of the current round for chain.
+func (c *Client) RestoreRoundShares(chain string, window []string, accepted uint64) error {
+	ctx := context.Background()
+	pipe := c.writeClient.TxPipeline()
+
+	key := c.getRoundSharesKey(chain)
+	pipe.Del(ctx, key)
+
+	const batchSize = 10000
+	for start := 0; start < len(window); start += batchSize {
+		end := start + batchSize
+		if end > len(window) {
+			end = len(window)
+		}
+
+		values := make([]interface{}, end-start)
+		for i, value := range window[start:end] {
+			values[i] = value
+		}
+		pipe.RPush(ctx, key, values...)
+	}
+	pipe.Set(ctx, c.getRoundAcceptedSharesKey(chain), strconv.FormatUint(accepted, 10), 0)
+
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+func (c *Client) SetRoundSharesMarker(chain, marker string) error {
+	return c.baseSet(c.getRoundSharesMarkerKey(chain), marker)
+}
+
+func (c *Client) RestoreRoundSoloShares(chain string, minerID, accepted uint64) error {
+	return c.baseSet(c.getRoundSoloAcceptedSharesKey(chain, minerID), strconv.FormatUint(accepted, 10))
+}
+
+// RestoreIntervalAcceptedShares replaces the accepted share counters of an interval
+// and adds the interval to the open intervals of chain.
+func (c *Client) RestoreIntervalAcceptedShares(
+	chain, interval string,
+	accepted, adjusted map[string]uint64,
+) error {
+	ctx := context.Background()
+	pipe := c.writeClient.TxPipeline()
+
+	counters := []struct {
+		key    string
+		values map[string]uint64
+	}{
+		{c.getIntervalAcceptedSharesKey(chain, interval), accepted},
+		{c.getIntervalAcceptedAdjustedSharesKey(chain, interval), adjusted},
+	}
+
+	for _, counter := range counters {
+		pipe.Del(ctx, counter.key)
+		if len(counter.values) == 0 {
+			continue
+		}
+
+		members := make([]redis.Z, 0, len(counter.values))
+		for member, score := range counter.values {
+			members = append(members, redis.Z{Member: member, Score: float64(score)})
+		}
+		pipe.ZAdd(ctx, counter.key, members...)
+	}
+	pipe.SAdd(ctx, c.getIntervalsKey(chain), interval)
+
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
 /* interval */
 
 func (c *Client) AddInterval(chain, interval string) error {
@@ -315,3 +390,14 @@ func (c *Client) SetCachedWorkersByChain(chain string, workers int64, exp time.D
 
 	return c.baseSetExp(c.getCachedWorkersByChainKey(chain), encoded, exp)
 }
+
+/* reorg */
+
+func (c *Client) SetReorgChainTips(chain string, tips map[uint64]string) error {
+	encoded, err := encode(tips)
+	if err != nil {
+		return err
+	}
+
+	return c.baseSet(c.getReorgChainTipsKey(chain), encoded)
+}
@@ -166,6 +166,48 @@ func (t *Client) NotifyNodeInstanceTerminated(chain, region, ip string) error {
 	return t.sendMessage(msg, t.ErrorChatID)
 }
 
+func (t *Client) NotifyChainReorg(chain string, height, depth uint64) error {
+	msg := fmt.Sprintf("detected %d block reorg for `%s` at height %d",
+		depth, chain, height)
+
+	return t.sendMessage(msg, t.ErrorChatID)
+}
+
+func (t *Client) NotifyRoundHeld(chain string, height uint64, hash string) error {
+	msg := fmt.Sprintf("round for `%s` at height %d is no longer in the main chain, payouts on hold: `%s`",
+		chain, height, hash)
+
+	return t.sendMessage(msg, t.ErrorChatID)
+}
+
+func (t *Client) NotifyRoundReleased(chain string, height uint64, hash string) error {
+	msg := fmt.Sprintf("round for `%s` at height %d is back in the main chain, hold released: `%s`",
+		chain, height, hash)
+
+	return t.sendMessage(msg, t.ErrorChatID)
+}
+
+func (t *Client) NotifyMinerFlagged(chain string, minerID uint64, reason string, excluded bool) error {
+	msg := fmt.Sprintf("flagged miner %d on `%s` for suspicious shares: %s",
+		minerID, chain, reason)
+	if excluded {
+		msg += " \\(excluded from PPLNS\\)"
+	}
+
+	return t.sendMessage(msg, t.ErrorChatID)
+}
+
+func (t *Client) NotifyTransactionFailed(
+	id uint64,
+	chain, txid, explorerURL string,
+	value float64,
+) error {
+	msg := fmt.Sprintf("transaction %d reverted, refunded %.4f %s at [%s](%s)",
+		id, value, chain, txid, explorerURL)
+
+	return t.sendMessage(msg, t.ErrorChatID)
+}
+
 /* info channel */
 
 func (t *Client) NotifyNewBlockCandidate(
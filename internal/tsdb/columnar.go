@@ -0,0 +1,1366 @@
+package tsdb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/* series */
+
+type shareKind int
+
+const (
+	globalShareKind shareKind = iota
+	minerShareKind
+	workerShareKind
+)
+
+var shareKindNames = map[shareKind]string{
+	globalShareKind: "global",
+	minerShareKind:  "miner",
+	workerShareKind: "worker",
+}
+
+func (k shareKind) String() string {
+	return shareKindNames[k]
+}
+
+func (k shareKind) shareID(share *Share) uint64 {
+	var id *uint64
+	switch k {
+	case minerShareKind:
+		id = share.MinerID
+	case workerShareKind:
+		id = share.WorkerID
+	}
+
+	if id == nil {
+		return 0
+	}
+
+	return *id
+}
+
+func (k shareKind) setShareID(share *Share, id uint64) {
+	switch k {
+	case minerShareKind:
+		share.MinerID = &id
+	case workerShareKind:
+		share.WorkerID = &id
+	}
+}
+
+// newRow copies share as it would be stored in the kind's table, which means
+// the columns the table does not have are dropped.
+func (k shareKind) newRow(share *Share) (*Share, error) {
+	row := *share
+	row.MinerID, row.WorkerID = nil, nil
+	switch k {
+	case minerShareKind:
+		if share.MinerID == nil {
+			return nil, fmt.Errorf("minerID is nil")
+		}
+		row.Miners = 0
+	case workerShareKind:
+		if share.WorkerID == nil {
+			return nil, fmt.Errorf("workerID is nil")
+		}
+		row.Miners, row.Workers = 0, 0
+	}
+	k.setShareID(&row, k.shareID(share))
+
+	return &row, nil
+}
+
+// addRow adds the summed columns of share to row, mirroring the update
+// columns of the partial inserts (InsertPartialGlobalShares etc.).
+func (k shareKind) addRow(row, share *Share) {
+	switch k {
+	case globalShareKind:
+		row.Miners += share.Miners
+		row.Workers += share.Workers
+		row.AvgHashrate += share.AvgHashrate
+	case minerShareKind:
+		row.Workers += share.Workers
+	}
+
+	row.AcceptedShares += share.AcceptedShares
+	row.AcceptedAdjustedShares += share.AcceptedAdjustedShares
+	row.RejectedShares += share.RejectedShares
+	row.RejectedAdjustedShares += share.RejectedAdjustedShares
+	row.InvalidShares += share.InvalidShares
+	row.InvalidAdjustedShares += share.InvalidAdjustedShares
+	row.Hashrate += share.Hashrate
+	row.Count += share.Count
+}
+
+// the rollup of each period, mirrored from types.PeriodType since types imports tsdb.
+var periodRollups = map[int]time.Duration{
+	0: time.Minute * 15,
+	1: time.Hour,
+	2: time.Hour * 4,
+	3: time.Hour * 24,
+}
+
+// partitionRollups is the number of rollups held by a single partition. with the
+// current retentions, a series has between 6 (15m) and 23 (1d) partitions.
+const partitionRollups = 16
+
+func partitionWidth(period int) int64 {
+	rollup, ok := periodRollups[period]
+	if !ok {
+		rollup = time.Minute
+	}
+
+	return int64(rollup/time.Second) * partitionRollups
+}
+
+func partitionStart(period int, endTime int64) int64 {
+	width := partitionWidth(period)
+	start := endTime - endTime%width
+	if endTime%width < 0 {
+		start -= width
+	}
+
+	return start
+}
+
+type seriesKey struct {
+	kind   shareKind
+	chain  string
+	period int
+}
+
+type partitionKey struct {
+	seriesKey
+	start int64
+}
+
+func (k partitionKey) end() int64 {
+	return k.start + partitionWidth(k.period)
+}
+
+// overlaps returns true if the partition can hold rows with an end time in [from, to].
+func (k partitionKey) overlaps(from, to int64) bool {
+	return k.start <= to && k.end() > from
+}
+
+/* views */
+
+// shareView is a read-only view of the partitions, either the committed partitions
+// of the store or those partitions overlaid with the changes of a transaction.
+type shareView struct {
+	base      map[partitionKey]*sharePartition
+	overrides map[partitionKey]*sharePartition
+}
+
+func (v shareView) keys(match func(partitionKey) bool) []partitionKey {
+	keys := make([]partitionKey, 0)
+	for key := range v.base {
+		if _, ok := v.overrides[key]; !ok && match(key) {
+			keys = append(keys, key)
+		}
+	}
+	for key, partition := range v.overrides {
+		if partition != nil && match(key) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func (v shareView) partition(key partitionKey) *sharePartition {
+	if partition, ok := v.overrides[key]; ok {
+		return partition
+	}
+
+	return v.base[key]
+}
+
+// scan calls fn for every row of the matching partitions, with cols decoded.
+// only the rows of ids are scanned, unless ids is nil.
+func (v shareView) scan(
+	match func(partitionKey) bool,
+	ids map[uint64]bool,
+	cols []int,
+	fn func(key partitionKey, c *partitionColumns, i int),
+) error {
+	for _, key := range v.keys(match) {
+		c, err := v.partition(key).decode(ids, cols...)
+		if err != nil {
+			return fmt.Errorf("%s %s %d %d: %v", key.kind, key.chain, key.period, key.start, err)
+		}
+
+		for i := 0; i < c.rows; i++ {
+			fn(key, c, i)
+		}
+	}
+
+	return nil
+}
+
+// lastEndTime returns the latest end time of the rows of ids in the matching partitions.
+// partitions are scanned from the latest, stopping at the first with a row of ids.
+func (v shareView) lastEndTime(match func(partitionKey) bool, ids map[uint64]bool) (int64, bool, error) {
+	keys := v.keys(match)
+	sort.Slice(keys, func(i, j int) bool { return keys[i].start > keys[j].start })
+
+	var last int64
+	var found bool
+	for i, key := range keys {
+		if found && key.start != keys[i-1].start {
+			break
+		}
+
+		c, err := v.partition(key).decode(ids)
+		if err != nil {
+			return 0, false, err
+		}
+
+		for j := 0; j < c.rows; j++ {
+			if !found || c.endTime(j) > last {
+				last, found = c.endTime(j), true
+			}
+		}
+	}
+
+	return last, found, nil
+}
+
+func sortShares(kind shareKind, shares []*Share) {
+	sort.Slice(shares, func(i, j int) bool {
+		if !shares[i].EndTime.Equal(shares[j].EndTime) {
+			return shares[i].EndTime.Before(shares[j].EndTime)
+		} else if shares[i].ChainID != shares[j].ChainID {
+			return shares[i].ChainID < shares[j].ChainID
+		}
+		return kind.shareID(shares[i]) < kind.shareID(shares[j])
+	})
+}
+
+func idSet(ids []uint64) map[uint64]bool {
+	set := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	return set
+}
+
+const (
+	minTime = math.MinInt64
+	maxTime = math.MaxInt64
+)
+
+var pendingShareColumns = []int{colStartTime, colPending, colCount, colHashrate}
+
+var allShareColumns = func() []int {
+	cols := make([]int, numShareColumns)
+	for i := range cols {
+		cols[i] = i
+	}
+	return cols
+}()
+
+/* view queries */
+
+// selectShares returns the rows of ids (every id if ids is nil) in a series (every
+// chain if chain is empty) with an end time in [from, to] that pass filter.
+func (v shareView) selectShares(
+	kind shareKind,
+	ids map[uint64]bool,
+	chain string,
+	period int,
+	from, to int64,
+	cols []int,
+	filter func(c *partitionColumns, i int) bool,
+) ([]*Share, error) {
+	match := func(key partitionKey) bool {
+		return key.kind == kind && key.period == period &&
+			(chain == "" || key.chain == chain) && key.overlaps(from, to)
+	}
+
+	output := make([]*Share, 0)
+	err := v.scan(match, ids, cols, func(key partitionKey, c *partitionColumns, i int) {
+		end := c.endTime(i)
+		if end >= from && end <= to && filter(c, i) {
+			output = append(output, c.share(key, i))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortShares(kind, output)
+
+	return output, nil
+}
+
+func notPending(c *partitionColumns, i int) bool {
+	return c.ints[colPending][i] == 0
+}
+
+func isPending(c *partitionColumns, i int) bool {
+	return c.ints[colPending][i] == 1
+}
+
+func anyRow(c *partitionColumns, i int) bool {
+	return true
+}
+
+func (v shareView) getShares(kind shareKind, ids map[uint64]bool, chain string, period int) ([]*Share, error) {
+	return v.selectShares(kind, ids, chain, period, minTime, maxTime, allShareColumns, notPending)
+}
+
+func (v shareView) getSharesSingleMetric(kind shareKind, ids map[uint64]bool, metric string, period int) ([]*Share, error) {
+	cols, err := parseShareMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.selectShares(kind, ids, "", period, minTime, maxTime, append(cols, colPending), notPending)
+}
+
+func (v shareView) getPendingSharesByEndTime(kind shareKind, timestamp time.Time, chain string, period int) ([]*Share, error) {
+	ts := timestamp.Unix()
+
+	return v.selectShares(kind, nil, chain, period, ts, ts, pendingShareColumns, isPending)
+}
+
+func (v shareView) getSharesByEndTime(
+	kind shareKind,
+	timestamp time.Time,
+	ids map[uint64]bool,
+	chain string,
+	period int,
+) ([]*Share, error) {
+	ts := timestamp.Unix()
+
+	return v.selectShares(kind, ids, chain, period, ts, ts, []int{colHashrate, colAvgHashrate}, anyRow)
+}
+
+func (v shareView) getSharesAverage(
+	kind shareKind,
+	timestamp time.Time,
+	chain string,
+	period int,
+	duration time.Duration,
+) (map[uint64]float64, error) {
+	to := timestamp.Unix()
+	from := timestamp.Add(-duration).Unix()
+	shares, err := v.selectShares(kind, nil, chain, period, from, to, []int{colHashrate}, anyRow)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[uint64]float64)
+	counts := make(map[uint64]float64)
+	for _, share := range shares {
+		id := kind.shareID(share)
+		sums[id] += share.Hashrate
+		counts[id]++
+	}
+
+	output := make(map[uint64]float64, len(sums))
+	for id, sum := range sums {
+		output[id] = sum / counts[id]
+	}
+
+	return output, nil
+}
+
+// getSharesSum sums the adjusted share counts over the duration, grouped
+// by chain (and by id if byID is set).
+func (v shareView) getSharesSum(
+	kind shareKind,
+	ids map[uint64]bool,
+	period int,
+	duration time.Duration,
+	byID bool,
+) ([]*Share, error) {
+	now := time.Now()
+	from, to := now.Add(-duration).Unix(), now.Unix()
+	cols := []int{colAcceptedAdjustedShares, colRejectedAdjustedShares, colInvalidAdjustedShares}
+	shares, err := v.selectShares(kind, ids, "", period, from, to, cols, anyRow)
+	if err != nil {
+		return nil, err
+	}
+
+	type sumKey struct {
+		id    uint64
+		chain string
+	}
+
+	idx := make(map[sumKey]*Share)
+	for _, share := range shares {
+		key := sumKey{chain: share.ChainID}
+		if byID {
+			key.id = kind.shareID(share)
+		}
+
+		sum, ok := idx[key]
+		if !ok {
+			sum = &Share{ChainID: share.ChainID}
+			if byID {
+				kind.setShareID(sum, key.id)
+			}
+			idx[key] = sum
+		}
+
+		sum.AcceptedAdjustedShares += share.AcceptedAdjustedShares
+		sum.RejectedAdjustedShares += share.RejectedAdjustedShares
+		sum.InvalidAdjustedShares += share.InvalidAdjustedShares
+	}
+
+	output := make([]*Share, 0, len(idx))
+	for _, sum := range idx {
+		output = append(output, sum)
+	}
+	sortShares(kind, output)
+
+	return output, nil
+}
+
+// getSharesLast returns the rows of ids (every id if ids is nil) at the latest end time of the first id.
+func (v shareView) getSharesLast(kind shareKind, ids []uint64, period int, cols []int) ([]*Share, error) {
+	match := func(key partitionKey) bool {
+		return key.kind == kind && key.period == period
+	}
+
+	var first, set map[uint64]bool
+	if ids != nil {
+		first, set = idSet(ids[:1]), idSet(ids)
+	}
+
+	last, found, err := v.lastEndTime(match, first)
+	if err != nil || !found {
+		return []*Share{}, err
+	}
+
+	return v.selectShares(kind, set, "", period, last, last, cols, anyRow)
+}
+
+/* transactions */
+
+type shareRowKey struct {
+	endTime int64
+	id      uint64
+}
+
+type mutablePartition struct {
+	rows map[shareRowKey]*Share
+}
+
+type insertMode int
+
+const (
+	insertOnly insertMode = iota
+	insertUpdateAdd
+	insertUpdateOverwrite
+)
+
+// columnarTx holds the changes of a write. partitions that are written to are
+// decoded into rows, which are encoded again when the transaction is flushed.
+type columnarTx struct {
+	base      map[partitionKey]*sharePartition
+	overrides map[partitionKey]*sharePartition
+	mutable   map[partitionKey]*mutablePartition
+}
+
+func newColumnarTx(base map[partitionKey]*sharePartition) *columnarTx {
+	tx := &columnarTx{
+		base:      base,
+		overrides: make(map[partitionKey]*sharePartition),
+		mutable:   make(map[partitionKey]*mutablePartition),
+	}
+
+	return tx
+}
+
+func (t *columnarTx) view() shareView {
+	t.flush()
+
+	return shareView{base: t.base, overrides: t.overrides}
+}
+
+func (t *columnarTx) mutablePartition(key partitionKey) (*mutablePartition, error) {
+	if partition, ok := t.mutable[key]; ok {
+		return partition, nil
+	}
+
+	partition := &mutablePartition{rows: make(map[shareRowKey]*Share)}
+	if encoded := (shareView{base: t.base, overrides: t.overrides}).partition(key); encoded != nil {
+		shares, err := encoded.decodeAll(key)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, share := range shares {
+			partition.rows[shareRowKey{share.EndTime.Unix(), key.kind.shareID(share)}] = share
+		}
+	}
+	t.mutable[key] = partition
+
+	return partition, nil
+}
+
+// flush encodes every partition that has been written to.
+func (t *columnarTx) flush() {
+	for key, partition := range t.mutable {
+		if len(partition.rows) == 0 {
+			t.overrides[key] = nil
+		} else {
+			rows := make([]*Share, 0, len(partition.rows))
+			for _, row := range partition.rows {
+				rows = append(rows, row)
+			}
+			t.overrides[key] = encodePartition(key.kind, rows)
+		}
+		delete(t.mutable, key)
+	}
+}
+
+func (t *columnarTx) insertShares(kind shareKind, mode insertMode, objects []*Share) error {
+	for _, object := range objects {
+		row, err := kind.newRow(object)
+		if err != nil {
+			return err
+		}
+
+		end := row.EndTime.Unix()
+		key := partitionKey{
+			seriesKey: seriesKey{kind: kind, chain: row.ChainID, period: row.Period},
+			start:     partitionStart(row.Period, end),
+		}
+
+		partition, err := t.mutablePartition(key)
+		if err != nil {
+			return err
+		}
+
+		rowKey := shareRowKey{end, kind.shareID(row)}
+		existing, ok := partition.rows[rowKey]
+		switch {
+		case !ok:
+			partition.rows[rowKey] = row
+		case mode == insertOnly:
+			return fmt.Errorf("duplicate %s share %s:%d at %s", kind, row.ChainID, rowKey.id, row.EndTime)
+		case mode == insertUpdateAdd:
+			updated := *existing
+			kind.addRow(&updated, row)
+			partition.rows[rowKey] = &updated
+		case mode == insertUpdateOverwrite:
+			updated := *existing
+			updated.Hashrate = row.Hashrate
+			updated.AvgHashrate = row.AvgHashrate
+			updated.Pending = row.Pending
+			partition.rows[rowKey] = &updated
+		}
+	}
+
+	return nil
+}
+
+// deleteSharesBefore drops every partition of the series that ends before the
+// timestamp and removes the older rows of the partition holding the timestamp.
+func (t *columnarTx) deleteSharesBefore(kind shareKind, timestamp time.Time, chain string, period int) error {
+	ts := timestamp.Unix()
+	series := seriesKey{kind: kind, chain: chain, period: period}
+	match := func(key partitionKey) bool {
+		return key.seriesKey == series && key.start < ts
+	}
+
+	keys := (shareView{base: t.base, overrides: t.overrides}).keys(match)
+	for key := range t.mutable {
+		if match(key) {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		if key.end() <= ts {
+			delete(t.mutable, key)
+			t.overrides[key] = nil
+			continue
+		}
+
+		partition, err := t.mutablePartition(key)
+		if err != nil {
+			return err
+		}
+
+		for rowKey := range partition.rows {
+			if rowKey.endTime < ts {
+				delete(partition.rows, rowKey)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *columnarTx) read(fn func(shareView) error) error {
+	return fn(t.view())
+}
+
+func (t *columnarTx) write(fn func(*columnarTx) error) error {
+	return fn(t)
+}
+
+// columnarTxStore is the ShareStore passed to Transact, nested calls join the transaction.
+type columnarTxStore struct {
+	columnarQueries
+}
+
+func (s *columnarTxStore) Transact(fn func(ShareStore) error) error {
+	return fn(s)
+}
+
+/* queries */
+
+type columnarBackend interface {
+	read(fn func(shareView) error) error
+	write(fn func(*columnarTx) error) error
+}
+
+// columnarQueries implements the queries of ShareStore for both the store and its transactions.
+type columnarQueries struct {
+	backend columnarBackend
+}
+
+func (q columnarQueries) readShares(fn func(v shareView) ([]*Share, error)) ([]*Share, error) {
+	var output []*Share
+	err := q.backend.read(func(v shareView) error {
+		var err error
+		output, err = fn(v)
+		return err
+	})
+
+	return output, err
+}
+
+func (q columnarQueries) readAverages(fn func(v shareView) (map[uint64]float64, error)) (map[uint64]float64, error) {
+	var output map[uint64]float64
+	err := q.backend.read(func(v shareView) error {
+		var err error
+		output, err = fn(v)
+		return err
+	})
+
+	return output, err
+}
+
+func (q columnarQueries) insertShares(kind shareKind, mode insertMode, objects []*Share) error {
+	if len(objects) == 0 {
+		return nil
+	}
+
+	return q.backend.write(func(tx *columnarTx) error {
+		return tx.insertShares(kind, mode, objects)
+	})
+}
+
+func (q columnarQueries) deleteSharesBefore(kind shareKind, timestamp time.Time, chain string, period int) error {
+	return q.backend.write(func(tx *columnarTx) error {
+		return tx.deleteSharesBefore(kind, timestamp, chain, period)
+	})
+}
+
+// sumSharesByChain sums the hashrates of shares by chain.
+func sumSharesByChain(shares []*Share) []*Share {
+	idx := make(map[string]*Share)
+	output := make([]*Share, 0)
+	for _, share := range shares {
+		sum, ok := idx[share.ChainID]
+		if !ok {
+			sum = &Share{ChainID: share.ChainID}
+			idx[share.ChainID] = sum
+			output = append(output, sum)
+		}
+		sum.Hashrate += share.Hashrate
+		sum.AvgHashrate += share.AvgHashrate
+	}
+
+	return output
+}
+
+/* global share queries */
+
+func (q columnarQueries) GetGlobalShares(chain string, period int) ([]*Share, error) {
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getShares(globalShareKind, nil, chain, period)
+	})
+}
+
+func (q columnarQueries) GetGlobalSharesSingleMetric(metric string, period int) ([]*Share, error) {
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getSharesSingleMetric(globalShareKind, nil, metric, period)
+	})
+}
+
+func (q columnarQueries) GetPendingGlobalSharesByEndTime(timestamp time.Time, chain string, period int) ([]*Share, error) {
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getPendingSharesByEndTime(globalShareKind, timestamp, chain, period)
+	})
+}
+
+func (q columnarQueries) GetGlobalSharesAverage(
+	timestamp time.Time,
+	chain string,
+	period int,
+	duration time.Duration,
+) (float64, error) {
+	averages, err := q.readAverages(func(v shareView) (map[uint64]float64, error) {
+		return v.getSharesAverage(globalShareKind, timestamp, chain, period, duration)
+	})
+
+	return averages[0], err
+}
+
+func (q columnarQueries) GetGlobalSharesLast(period int) ([]*Share, error) {
+	cols := []int{colMiners, colWorkers, colHashrate, colAvgHashrate}
+
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getSharesLast(globalShareKind, nil, period, cols)
+	})
+}
+
+func (q columnarQueries) InsertGlobalShares(objects ...*Share) error {
+	return q.insertShares(globalShareKind, insertOnly, objects)
+}
+
+func (q columnarQueries) InsertPartialGlobalShares(objects ...*Share) error {
+	return q.insertShares(globalShareKind, insertUpdateAdd, objects)
+}
+
+func (q columnarQueries) InsertFinalGlobalShares(objects ...*Share) error {
+	return q.insertShares(globalShareKind, insertUpdateOverwrite, objects)
+}
+
+func (q columnarQueries) DeleteGlobalSharesBeforeEndTime(timestamp time.Time, chain string, period int) error {
+	return q.deleteSharesBefore(globalShareKind, timestamp, chain, period)
+}
+
+/* miner share queries */
+
+func (q columnarQueries) GetMinerShares(minerIDs []uint64, chain string, period int) ([]*Share, error) {
+	if len(minerIDs) == 0 {
+		return nil, nil
+	}
+
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getShares(minerShareKind, idSet(minerIDs), chain, period)
+	})
+}
+
+func (q columnarQueries) GetMinerSharesByTimeRange(chain string, period int, start, end time.Time) ([]*Share, error) {
+	cols := []int{colStartTime, colPending, colAcceptedShares}
+
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.selectShares(minerShareKind, nil, chain, period, start.Unix(), end.Unix(), cols, notPending)
+	})
+}
+
+func (q columnarQueries) GetMinerSharesSingleMetric(minerIDs []uint64, metric string, period int) ([]*Share, error) {
+	if len(minerIDs) == 0 {
+		return nil, nil
+	}
+
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getSharesSingleMetric(minerShareKind, idSet(minerIDs), metric, period)
+	})
+}
+
+func (q columnarQueries) GetPendingMinerSharesByEndTime(timestamp time.Time, chain string, period int) ([]*Share, error) {
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getPendingSharesByEndTime(minerShareKind, timestamp, chain, period)
+	})
+}
+
+func (q columnarQueries) GetMinerSharesByEndTime(
+	timestamp time.Time,
+	minerIDs []uint64,
+	chain string,
+	period int,
+) ([]*Share, error) {
+	if len(minerIDs) == 0 {
+		return nil, nil
+	}
+
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getSharesByEndTime(minerShareKind, timestamp, idSet(minerIDs), chain, period)
+	})
+}
+
+func (q columnarQueries) GetMinerSharesAverage(
+	timestamp time.Time,
+	chain string,
+	period int,
+	duration time.Duration,
+) (map[uint64]float64, error) {
+	return q.readAverages(func(v shareView) (map[uint64]float64, error) {
+		return v.getSharesAverage(minerShareKind, timestamp, chain, period, duration)
+	})
+}
+
+func (q columnarQueries) GetMinersSharesSum(minerIDs []uint64, period int, duration time.Duration) ([]*Share, error) {
+	if len(minerIDs) == 0 {
+		return nil, nil
+	}
+
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getSharesSum(minerShareKind, idSet(minerIDs), period, duration, false)
+	})
+}
+
+func (q columnarQueries) GetMinersSharesLast(minerIDs []uint64, period int) ([]*Share, error) {
+	if len(minerIDs) == 0 {
+		return nil, nil
+	}
+
+	cols := []int{colHashrate, colAvgHashrate}
+	shares, err := q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getSharesLast(minerShareKind, minerIDs, period, cols)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sumSharesByChain(shares), nil
+}
+
+func (q columnarQueries) InsertMinerShares(objects ...*Share) error {
+	return q.insertShares(minerShareKind, insertOnly, objects)
+}
+
+func (q columnarQueries) InsertPartialMinerShares(objects ...*Share) error {
+	return q.insertShares(minerShareKind, insertUpdateAdd, objects)
+}
+
+func (q columnarQueries) InsertFinalMinerShares(objects ...*Share) error {
+	return q.insertShares(minerShareKind, insertUpdateOverwrite, objects)
+}
+
+func (q columnarQueries) DeleteMinerSharesBeforeEndTime(timestamp time.Time, chain string, period int) error {
+	return q.deleteSharesBefore(minerShareKind, timestamp, chain, period)
+}
+
+/* worker share queries */
+
+func (q columnarQueries) GetWorkerShares(workerID uint64, chain string, period int) ([]*Share, error) {
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getShares(workerShareKind, idSet([]uint64{workerID}), chain, period)
+	})
+}
+
+func (q columnarQueries) GetWorkerSharesSingleMetric(workerID uint64, metric string, period int) ([]*Share, error) {
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getSharesSingleMetric(workerShareKind, idSet([]uint64{workerID}), metric, period)
+	})
+}
+
+func (q columnarQueries) GetPendingWorkerSharesByEndTime(timestamp time.Time, chain string, period int) ([]*Share, error) {
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getPendingSharesByEndTime(workerShareKind, timestamp, chain, period)
+	})
+}
+
+func (q columnarQueries) GetWorkerSharesAllChainsByEndTime(
+	timestamp time.Time,
+	workerIDs []uint64,
+	period int,
+) ([]*Share, error) {
+	if len(workerIDs) == 0 {
+		return nil, nil
+	}
+
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getSharesByEndTime(workerShareKind, timestamp, idSet(workerIDs), "", period)
+	})
+}
+
+func (q columnarQueries) GetWorkerSharesAverage(
+	timestamp time.Time,
+	chain string,
+	period int,
+	duration time.Duration,
+) (map[uint64]float64, error) {
+	return q.readAverages(func(v shareView) (map[uint64]float64, error) {
+		return v.getSharesAverage(workerShareKind, timestamp, chain, period, duration)
+	})
+}
+
+func (q columnarQueries) GetWorkerSharesSum(workerIDs []uint64, period int, duration time.Duration) ([]*Share, error) {
+	if len(workerIDs) == 0 {
+		return nil, nil
+	}
+
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getSharesSum(workerShareKind, idSet(workerIDs), period, duration, true)
+	})
+}
+
+func (q columnarQueries) GetWorkerSharesLast(workerID uint64, period int) ([]*Share, error) {
+	cols := []int{colHashrate, colAvgHashrate}
+
+	return q.readShares(func(v shareView) ([]*Share, error) {
+		return v.getSharesLast(workerShareKind, []uint64{workerID}, period, cols)
+	})
+}
+
+func (q columnarQueries) InsertWorkerShares(objects ...*Share) error {
+	return q.insertShares(workerShareKind, insertOnly, objects)
+}
+
+func (q columnarQueries) InsertPartialWorkerShares(objects ...*Share) error {
+	return q.insertShares(workerShareKind, insertUpdateAdd, objects)
+}
+
+func (q columnarQueries) InsertFinalWorkerShares(objects ...*Share) error {
+	return q.insertShares(workerShareKind, insertUpdateOverwrite, objects)
+}
+
+func (q columnarQueries) DeleteWorkerSharesBeforeEndTime(timestamp time.Time, chain string, period int) error {
+	return q.deleteSharesBefore(workerShareKind, timestamp, chain, period)
+}
+
+/* store */
+
+// ColumnarShareStore is an embedded ShareStore. every series (kind, chain and period) is
+// split into partitions of 16 rollups, which hold the delta and XOR encoded columns of
+// every id separately, so chart queries only decode the partitions, ids and columns they
+// read, and retention drops whole partitions. with a dir, every partition is an immutable
+// file under dir and a commit is only published once the manifest listing its partitions
+// replaces the previous one. writers sharing dir serialize on a file lock and reload before
+// every write, any number of processes (the api) can open it read only and reload the
+// changed partitions after every commit.
+type ColumnarShareStore struct {
+	columnarQueries
+
+	mu         sync.RWMutex
+	dir        string
+	readOnly   bool
+	generation uint64
+	files      map[partitionKey]string
+	partitions map[partitionKey]*sharePartition
+}
+
+// OpenColumnarShareStore opens the store in dir, creating it if it does not exist.
+// with an empty dir, the store is only held in memory.
+func OpenColumnarShareStore(dir string, readOnly bool) (*ColumnarShareStore, error) {
+	if dir == "" && readOnly {
+		return nil, fmt.Errorf("a read only columnar share store requires a dir")
+	}
+
+	store := &ColumnarShareStore{
+		dir:        dir,
+		readOnly:   readOnly,
+		files:      make(map[partitionKey]string),
+		partitions: make(map[partitionKey]*sharePartition),
+	}
+	store.backend = store
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		} else if readOnly {
+			return store, store.reload()
+		}
+
+		unlock, err := lockDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+
+		if err := store.reload(); err != nil {
+			return nil, err
+		} else if err := store.sweep(); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+func (s *ColumnarShareStore) read(fn func(shareView) error) error {
+	if s.dir != "" {
+		if err := s.refresh(); err != nil {
+			return err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return fn(shareView{base: s.partitions})
+}
+
+func (s *ColumnarShareStore) write(fn func(*columnarTx) error) error {
+	if s.readOnly {
+		return fmt.Errorf("columnar share store is read only")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dir != "" {
+		unlock, err := lockDir(s.dir)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		// another writer sharing dir may have committed since the last load
+		if err := s.reload(); err != nil {
+			return err
+		}
+	}
+
+	tx := newColumnarTx(s.partitions)
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return s.commit(tx)
+}
+
+func (s *ColumnarShareStore) Transact(fn func(ShareStore) error) error {
+	return s.write(func(tx *columnarTx) error {
+		return fn(&columnarTxStore{columnarQueries{backend: tx}})
+	})
+}
+
+// commit writes the changed partitions to new files and publishes them with the manifest
+// before applying them in memory. a crash before the manifest is replaced leaves the previous
+// commit in place, the files it had already written are removed by the next writer to open dir.
+func (s *ColumnarShareStore) commit(tx *columnarTx) error {
+	tx.flush()
+	if len(tx.overrides) == 0 {
+		return nil
+	}
+
+	generation := s.generation + 1
+	if s.dir != "" {
+		files := make(map[partitionKey]string, len(s.files)+len(tx.overrides))
+		for key, name := range s.files {
+			files[key] = name
+		}
+
+		for key, partition := range tx.overrides {
+			if partition == nil {
+				delete(files, key)
+				continue
+			}
+
+			name := partitionFile(key, generation)
+			err := writeFileAtomic(filepath.Join(s.dir, name), partition.marshal())
+			if err != nil {
+				return err
+			}
+			files[key] = name
+		}
+
+		err := writeManifest(s.dir, generation, files)
+		if err != nil {
+			return err
+		}
+
+		// readers retry a load that races the removal of the replaced partitions
+		// and a partition that fails to be removed is swept on the next open.
+		for key := range tx.overrides {
+			if name, ok := s.files[key]; ok && name != files[key] {
+				os.Remove(filepath.Join(s.dir, name))
+			}
+		}
+		s.files = files
+	}
+	s.generation = generation
+
+	for key, partition := range tx.overrides {
+		if partition == nil {
+			delete(s.partitions, key)
+		} else {
+			s.partitions[key] = partition
+		}
+	}
+
+	return nil
+}
+
+// size returns the number of partitions, rows and encoded bytes held by the store.
+func (s *ColumnarShareStore) size() (int, int, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rows, bytes int
+	for _, partition := range s.partitions {
+		rows += partition.rows
+		bytes += partition.size()
+	}
+
+	return len(s.partitions), rows, bytes
+}
+
+/* files */
+
+const (
+	manifestFile = "MANIFEST"
+	lockFile     = "LOCK"
+)
+
+var errPartitionRemoved = fmt.Errorf("columnar share store partition removed during load")
+
+// partitionFile returns the path of the partition written by the commit of generation,
+// relative to dir. partition files are never rewritten in place.
+func partitionFile(key partitionKey, generation uint64) string {
+	name := strconv.FormatInt(key.start, 10) + "_" + strconv.FormatUint(generation, 10) + ".part"
+
+	return filepath.Join(key.kind.String(), key.chain, strconv.Itoa(key.period), name)
+}
+
+func parsePartitionPath(rel string) (partitionKey, bool) {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) != 4 || !strings.HasSuffix(parts[3], ".part") {
+		return partitionKey{}, false
+	}
+
+	var kind shareKind
+	var ok bool
+	for k, name := range shareKindNames {
+		if name == parts[0] {
+			kind, ok = k, true
+		}
+	}
+
+	period, err := strconv.Atoi(parts[2])
+	if err != nil || !ok {
+		return partitionKey{}, false
+	}
+
+	startPart, generationPart, ok := strings.Cut(strings.TrimSuffix(parts[3], ".part"), "_")
+	if !ok {
+		return partitionKey{}, false
+	} else if _, err := strconv.ParseUint(generationPart, 10, 64); err != nil {
+		return partitionKey{}, false
+	}
+
+	start, err := strconv.ParseInt(startPart, 10, 64)
+	if err != nil {
+		return partitionKey{}, false
+	}
+
+	key := partitionKey{
+		seriesKey: seriesKey{kind: kind, chain: parts[1], period: period},
+		start:     start,
+	}
+
+	return key, true
+}
+
+// writeFileAtomic writes data to a temp file in the dir of path and renames it over path,
+// syncing both the file and the dir so the rename survives a crash.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	} else if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	} else if err := file.Close(); err != nil {
+		return err
+	} else if err := os.Rename(file.Name(), path); err != nil {
+		return err
+	}
+
+	dirFile, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer dirFile.Close()
+
+	return dirFile.Sync()
+}
+
+// the manifest holds the generation of the last commit on its first
+// line, followed by the path of every partition file it published.
+func writeManifest(dir string, generation uint64, files map[partitionKey]string) error {
+	names := make([]string, 0, len(files))
+	for _, name := range files {
+		names = append(names, filepath.ToSlash(name))
+	}
+	sort.Strings(names)
+
+	var data strings.Builder
+	data.WriteString(strconv.FormatUint(generation, 10) + "\n")
+	for _, name := range names {
+		data.WriteString(name + "\n")
+	}
+
+	return writeFileAtomic(filepath.Join(dir, manifestFile), []byte(data.String()))
+}
+
+func readManifest(dir string) (uint64, map[partitionKey]string, error) {
+	files := make(map[partitionKey]string)
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if os.IsNotExist(err) {
+		return 0, files, nil
+	} else if err != nil {
+		return 0, nil, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	generation, err := strconv.ParseUint(lines[0], 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %v", manifestFile, err)
+	}
+
+	for _, line := range lines[1:] {
+		key, ok := parsePartitionPath(line)
+		if !ok {
+			return 0, nil, fmt.Errorf("%s: invalid partition %s", manifestFile, line)
+		}
+		files[key] = filepath.FromSlash(line)
+	}
+
+	return generation, files, nil
+}
+
+// readGeneration only reads the first line of the manifest, which is all
+// a reader needs to know whether anything has been committed since its last load.
+func readGeneration(dir string) (uint64, error) {
+	file, err := os.Open(filepath.Join(dir, manifestFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(line), 10, 64)
+}
+
+// load expects the lock to be held, it reads every partition file of the manifest
+// that changed since it was last read and forgets the partitions it no longer lists.
+func (s *ColumnarShareStore) load(generation uint64, files map[partitionKey]string) error {
+	partitions := make(map[partitionKey]*sharePartition, len(files))
+	for key, name := range files {
+		if s.files[key] == name {
+			partitions[key] = s.partitions[key]
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if os.IsNotExist(err) {
+			return errPartitionRemoved
+		} else if err != nil {
+			return err
+		}
+
+		partition, err := unmarshalPartition(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		partitions[key] = partition
+	}
+
+	s.generation = generation
+	s.files = files
+	s.partitions = partitions
+
+	return nil
+}
+
+// reload expects the lock to be held. a writer removes the partitions replaced by its
+// commit once the manifest is published, so a load that races it is retried.
+func (s *ColumnarShareStore) reload() error {
+	var err error
+	for i := 0; i < 3; i++ {
+		var generation uint64
+		var files map[partitionKey]string
+		generation, files, err = readManifest(s.dir)
+		if err != nil {
+			return err
+		} else if generation == s.generation {
+			return nil
+		}
+
+		err = s.load(generation, files)
+		if err != errPartitionRemoved {
+			return err
+		}
+	}
+
+	return err
+}
+
+// refresh reloads the store if a writer has committed since the last load.
+func (s *ColumnarShareStore) refresh() error {
+	generation, err := readGeneration(s.dir)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	current := s.generation
+	s.mu.RUnlock()
+	if generation == current {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reload()
+}
+
+// sweep expects the dir lock to be held, it removes the partitions and temp files
+// that are not in the manifest, which are left by a crash or a failed removal.
+func (s *ColumnarShareStore) sweep() error {
+	published := make(map[string]bool, len(s.files))
+	for _, name := range s.files {
+		published[name] = true
+	}
+
+	return filepath.Walk(s.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		} else if _, ok := parsePartitionPath(rel); ok && !published[rel] {
+			return os.Remove(path)
+		} else if strings.HasPrefix(info.Name(), ".tmp-") {
+			return os.Remove(path)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,384 @@
+package tsdb
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func uint64Ptr(value uint64) *uint64 {
+	return &value
+}
+
+func newTestMinerShare(minerID uint64, endTime time.Time, accepted uint64, hashrate float64) *Share {
+	share := &Share{
+		ChainID:                "ETC",
+		MinerID:                uint64Ptr(minerID),
+		Miners:                 1,
+		Workers:                1,
+		AcceptedShares:         accepted,
+		AcceptedAdjustedShares: accepted,
+		Hashrate:               hashrate,
+		Count:                  1,
+		Period:                 0,
+		StartTime:              endTime.Add(-15 * time.Minute),
+		EndTime:                endTime,
+	}
+
+	return share
+}
+
+func TestEncodeColumns(t *testing.T) {
+	intTests := [][]int64{
+		{},
+		{0},
+		{1, 2, 3, 1000, 999, -5},
+		{math.MaxInt64, math.MinInt64, 0},
+	}
+
+	for i, tt := range intTests {
+		values, err := decodeIntColumn(encodeIntColumn(tt), len(tt))
+		if err != nil {
+			t.Errorf("failed on %d: int column: %v", i, err)
+		} else if !reflect.DeepEqual(values, tt) && len(tt) > 0 {
+			t.Errorf("failed on %d: int column mismatch: have %v, want %v", i, values, tt)
+		}
+	}
+
+	floatTests := [][]float64{
+		{0},
+		{1.5, 1.5, 1.25, 1e18, -3},
+		{math.Inf(1), math.SmallestNonzeroFloat64},
+	}
+
+	for i, tt := range floatTests {
+		values, err := decodeFloatColumn(encodeFloatColumn(tt), len(tt))
+		if err != nil {
+			t.Errorf("failed on %d: float column: %v", i, err)
+		} else if !reflect.DeepEqual(values, tt) {
+			t.Errorf("failed on %d: float column mismatch: have %v, want %v", i, values, tt)
+		}
+	}
+
+	if _, err := decodeIntColumn(encodeIntColumn([]int64{1, 2}), 3); err == nil {
+		t.Errorf("expected error decoding a short column")
+	}
+}
+
+func TestColumnarShareStoreWrites(t *testing.T) {
+	store, err := OpenColumnarShareStore("", false)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	endTime := time.Unix(1700000100, 0).UTC()
+	err = store.InsertMinerShares(
+		newTestMinerShare(2, endTime, 10, 100),
+		newTestMinerShare(1, endTime, 20, 200),
+	)
+	if err != nil {
+		t.Fatalf("failed to insert miner shares: %v", err)
+	} else if err := store.InsertMinerShares(newTestMinerShare(1, endTime, 1, 1)); err == nil {
+		t.Errorf("expected error inserting a duplicate share")
+	}
+
+	// pending shares are summed by partial inserts, then overwritten by final inserts
+	pendingEndTime := endTime.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		share := newTestMinerShare(1, pendingEndTime, 5, 50)
+		share.Pending = true
+		if err := store.InsertPartialMinerShares(share); err != nil {
+			t.Fatalf("failed to insert partial miner share: %v", err)
+		}
+	}
+
+	shares, err := store.GetMinerShares([]uint64{1, 2}, "ETC", 0)
+	if err != nil {
+		t.Fatalf("failed to get miner shares: %v", err)
+	} else if len(shares) != 2 {
+		t.Fatalf("miner share length mismatch: have %d, want 2", len(shares))
+	} else if *shares[0].MinerID != 1 || shares[0].AcceptedShares != 20 || !shares[0].StartTime.Equal(endTime.Add(-15*time.Minute)) {
+		t.Errorf("miner share mismatch: have %+v", shares[0])
+	} else if shares[0].Miners != 0 {
+		t.Errorf("miners mismatch: have %d, want 0", shares[0].Miners)
+	}
+
+	pending, err := store.GetPendingMinerSharesByEndTime(pendingEndTime, "ETC", 0)
+	if err != nil {
+		t.Fatalf("failed to get pending miner shares: %v", err)
+	} else if len(pending) != 1 {
+		t.Fatalf("pending miner share length mismatch: have %d, want 1", len(pending))
+	} else if pending[0].Count != 3 || pending[0].Hashrate != 150 {
+		t.Errorf("pending miner share mismatch: have count %d, hashrate %g", pending[0].Count, pending[0].Hashrate)
+	}
+
+	pending[0].Pending = false
+	pending[0].Hashrate /= float64(pending[0].Count)
+	pending[0].AvgHashrate = 40
+	if err := store.InsertFinalMinerShares(pending...); err != nil {
+		t.Fatalf("failed to insert final miner shares: %v", err)
+	}
+
+	metrics, err := store.GetMinerSharesSingleMetric([]uint64{1}, "hashrate, avg_hashrate", 0)
+	if err != nil {
+		t.Fatalf("failed to get single metric: %v", err)
+	}
+
+	wantMetrics := []*Share{
+		{ChainID: "ETC", MinerID: uint64Ptr(1), Period: 0, EndTime: endTime, Hashrate: 200},
+		{ChainID: "ETC", MinerID: uint64Ptr(1), Period: 0, EndTime: pendingEndTime, Hashrate: 50, AvgHashrate: 40},
+	}
+	if !reflect.DeepEqual(metrics, wantMetrics) {
+		t.Errorf("single metric mismatch: have %+v, want %+v", metrics, wantMetrics)
+	}
+
+	if _, err := store.GetMinerSharesSingleMetric([]uint64{1}, "hashrate; DROP", 0); err == nil {
+		t.Errorf("expected error on unknown metric")
+	}
+
+	averages, err := store.GetMinerSharesAverage(pendingEndTime, "ETC", 0, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to get averages: %v", err)
+	} else if want := map[uint64]float64{1: 125, 2: 100}; !reflect.DeepEqual(averages, want) {
+		t.Errorf("average mismatch: have %v, want %v", averages, want)
+	}
+
+	last, err := store.GetMinersSharesLast([]uint64{2, 1}, 0)
+	if err != nil {
+		t.Fatalf("failed to get last shares: %v", err)
+	} else if len(last) != 1 || last[0].Hashrate != 300 {
+		t.Errorf("last share mismatch: have %+v", last)
+	}
+}
+
+func TestColumnarShareStoreTransact(t *testing.T) {
+	store, err := OpenColumnarShareStore("", false)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	endTime := time.Unix(1700000100, 0).UTC()
+	errAbort := fmt.Errorf("abort")
+	err = store.Transact(func(tx ShareStore) error {
+		if err := tx.InsertMinerShares(newTestMinerShare(1, endTime, 1, 1)); err != nil {
+			return err
+		}
+
+		shares, err := tx.GetMinerShares([]uint64{1}, "ETC", 0)
+		if err != nil {
+			return err
+		} else if len(shares) != 1 {
+			t.Errorf("transaction share length mismatch: have %d, want 1", len(shares))
+		}
+
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("transaction error mismatch: have %v, want %v", err, errAbort)
+	}
+
+	shares, err := store.GetMinerShares([]uint64{1}, "ETC", 0)
+	if err != nil {
+		t.Fatalf("failed to get miner shares: %v", err)
+	} else if len(shares) != 0 {
+		t.Errorf("rolled back share length mismatch: have %d, want 0", len(shares))
+	}
+}
+
+func TestColumnarShareStoreRetention(t *testing.T) {
+	store, err := OpenColumnarShareStore("", false)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	// 64 rollups of 15m are 4 partitions of 16 rollups
+	start := time.Unix(partitionStart(0, 1700000000), 0).UTC()
+	for i := 0; i < 64; i++ {
+		endTime := start.Add(time.Duration(i) * 15 * time.Minute)
+		if err := store.InsertMinerShares(newTestMinerShare(1, endTime, 1, 1)); err != nil {
+			t.Fatalf("failed to insert miner share %d: %v", i, err)
+		}
+	}
+
+	tests := []struct {
+		cutoff     int
+		partitions int
+		rows       int
+	}{
+		{0, 4, 64},
+		{16, 3, 48},
+		{20, 3, 44},
+		{64, 0, 0},
+	}
+
+	for i, tt := range tests {
+		err := store.DeleteMinerSharesBeforeEndTime(start.Add(time.Duration(tt.cutoff)*15*time.Minute), "ETC", 0)
+		if err != nil {
+			t.Errorf("failed on %d: delete: %v", i, err)
+			continue
+		}
+
+		partitions, rows, _ := store.size()
+		if partitions != tt.partitions {
+			t.Errorf("failed on %d: partition mismatch: have %d, want %d", i, partitions, tt.partitions)
+		} else if rows != tt.rows {
+			t.Errorf("failed on %d: row mismatch: have %d, want %d", i, rows, tt.rows)
+		}
+	}
+}
+
+func TestColumnarShareStorePersistence(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := OpenColumnarShareStore(dir, false)
+	if err != nil {
+		t.Fatalf("failed to open writer: %v", err)
+	}
+
+	endTime := time.Unix(1700000100, 0).UTC()
+	if err := writer.InsertMinerShares(newTestMinerShare(1, endTime, 1, 1)); err != nil {
+		t.Fatalf("failed to insert miner share: %v", err)
+	}
+
+	reader, err := OpenColumnarShareStore(dir, true)
+	if err != nil {
+		t.Fatalf("failed to open reader: %v", err)
+	} else if err := reader.InsertMinerShares(newTestMinerShare(2, endTime, 1, 1)); err == nil {
+		t.Errorf("expected error writing to a read only store")
+	}
+
+	steps := []func() error{
+		func() error {
+			return nil
+		},
+		func() error {
+			return writer.InsertMinerShares(newTestMinerShare(2, endTime, 1, 1))
+		},
+		func() error {
+			return writer.InsertMinerShares(newTestMinerShare(1, endTime.Add(time.Hour*24), 1, 1))
+		},
+		func() error {
+			return writer.DeleteMinerSharesBeforeEndTime(endTime.Add(time.Hour), "ETC", 0)
+		},
+	}
+
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Errorf("failed on %d: step: %v", i, err)
+			continue
+		}
+
+		want, err := writer.GetMinerShares([]uint64{1, 2}, "ETC", 0)
+		if err != nil {
+			t.Errorf("failed on %d: writer: %v", i, err)
+			continue
+		}
+
+		have, err := reader.GetMinerShares([]uint64{1, 2}, "ETC", 0)
+		if err != nil {
+			t.Errorf("failed on %d: reader: %v", i, err)
+		} else if !reflect.DeepEqual(have, want) {
+			t.Errorf("failed on %d: share mismatch: have %+v, want %+v", i, have, want)
+		}
+	}
+
+	// a reopened writer continues from the files
+	reopened, err := OpenColumnarShareStore(dir, false)
+	if err != nil {
+		t.Fatalf("failed to reopen writer: %v", err)
+	} else if partitions, rows, _ := reopened.size(); partitions != 1 || rows != 1 {
+		t.Errorf("reopened size mismatch: have %d partitions and %d rows, want 1 and 1", partitions, rows)
+	}
+
+	// corrupt partitions are rejected
+	files, err := filepath.Glob(filepath.Join(dir, "miner", "ETC", "0", "*.part"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("partition file mismatch: have %v (%v)", files, err)
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read partition: %v", err)
+	}
+	data[len(data)/2] ^= 0xff
+	if err := os.WriteFile(files[0], data, 0644); err != nil {
+		t.Fatalf("failed to write partition: %v", err)
+	} else if _, err := OpenColumnarShareStore(dir, true); err == nil {
+		t.Errorf("expected error opening a corrupt partition")
+	}
+}
+
+func TestColumnarShareStoreWriters(t *testing.T) {
+	dir := t.TempDir()
+	writers := make([]*ColumnarShareStore, 2)
+	for i := range writers {
+		writer, err := OpenColumnarShareStore(dir, false)
+		if err != nil {
+			t.Fatalf("failed to open writer %d: %v", i, err)
+		}
+		writers[i] = writer
+	}
+
+	// every writer reloads before it writes, so none of them overwrites the
+	// partition with a stale copy that is missing the rows of the other writer
+	endTime := time.Unix(1700000100, 0).UTC()
+	for i := 0; i < 4; i++ {
+		minerID := uint64(i + 1)
+		err := writers[i%2].InsertMinerShares(newTestMinerShare(minerID, endTime, minerID, 1))
+		if err != nil {
+			t.Fatalf("failed on %d: insert miner share: %v", i, err)
+		}
+	}
+
+	for i, writer := range writers {
+		shares, err := writer.GetMinerShares([]uint64{1, 2, 3, 4}, "ETC", 0)
+		if err != nil {
+			t.Errorf("failed on %d: get miner shares: %v", i, err)
+		} else if len(shares) != 4 {
+			t.Errorf("failed on %d: share length mismatch: have %d, want 4", i, len(shares))
+		}
+	}
+
+	// a commit that crashed before its manifest was published leaves orphaned partitions
+	// and temp files behind, which readers ignore and the next writer to open dir removes
+	key := partitionKey{
+		seriesKey: seriesKey{kind: minerShareKind, chain: "ETC", period: 0},
+		start:     partitionStart(0, endTime.Unix()),
+	}
+	orphans := []string{
+		filepath.Join(dir, partitionFile(key, 100)),
+		filepath.Join(dir, "miner", "ETC", "0", ".tmp-1"),
+	}
+	for _, orphan := range orphans {
+		if err := os.WriteFile(orphan, []byte("torn"), 0644); err != nil {
+			t.Fatalf("failed to write orphan: %v", err)
+		}
+	}
+
+	reader, err := OpenColumnarShareStore(dir, true)
+	if err != nil {
+		t.Fatalf("failed to open reader: %v", err)
+	} else if shares, err := reader.GetMinerShares([]uint64{1, 2, 3, 4}, "ETC", 0); err != nil {
+		t.Errorf("failed to get miner shares: %v", err)
+	} else if len(shares) != 4 {
+		t.Errorf("share length mismatch: have %d, want 4", len(shares))
+	}
+
+	if _, err := OpenColumnarShareStore(dir, false); err != nil {
+		t.Fatalf("failed to reopen writer: %v", err)
+	}
+
+	for _, orphan := range orphans {
+		if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+			t.Errorf("orphan %s mismatch: have %v, want not exist", filepath.Base(orphan), err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "miner", "ETC", "0", "*"))
+	if err != nil || len(files) != 1 {
+		t.Errorf("partition file mismatch: have %v (%v)", files, err)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package tsdb
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// lockDir fails where there are no file locks, since the
+// writers sharing dir could not be serialized otherwise.
+func lockDir(dir string) (func(), error) {
+	return nil, fmt.Errorf("columnar share store writers are not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,483 @@
+package tsdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* columns */
+
+// the columns of a share partition. every column is stored for every kind of share,
+// the columns a table does not have (miners for miner shares, miners and workers
+// for worker shares) are always zero.
+const (
+	colID = iota
+	colEndTime
+	colStartTime
+	colPending
+	colCount
+	colMiners
+	colWorkers
+	colAcceptedShares
+	colAcceptedAdjustedShares
+	colRejectedShares
+	colRejectedAdjustedShares
+	colInvalidShares
+	colInvalidAdjustedShares
+	colHashrate
+	colAvgHashrate
+	numShareColumns
+)
+
+var shareMetricColumns = map[string]int{
+	"miners":                   colMiners,
+	"workers":                  colWorkers,
+	"accepted_shares":          colAcceptedShares,
+	"accepted_adjusted_shares": colAcceptedAdjustedShares,
+	"rejected_shares":          colRejectedShares,
+	"rejected_adjusted_shares": colRejectedAdjustedShares,
+	"invalid_shares":           colInvalidShares,
+	"invalid_adjusted_shares":  colInvalidAdjustedShares,
+	"hashrate":                 colHashrate,
+	"avg_hashrate":             colAvgHashrate,
+	"count":                    colCount,
+	"pending":                  colPending,
+}
+
+func isFloatColumn(col int) bool {
+	return col == colHashrate || col == colAvgHashrate
+}
+
+// parseShareMetric parses a metric (a comma separated list of columns, as used in
+// the single metric queries) into its columns.
+func parseShareMetric(metric string) ([]int, error) {
+	parts := strings.Split(metric, ",")
+	cols := make([]int, len(parts))
+	for i, part := range parts {
+		col, ok := shareMetricColumns[strings.TrimSpace(part)]
+		if !ok {
+			return nil, fmt.Errorf("unknown share metric %s", part)
+		}
+		cols[i] = col
+	}
+
+	return cols, nil
+}
+
+func boolToInt64(value bool) int64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// shareIntValue returns the value of an integer column of share.
+func shareIntValue(kind shareKind, share *Share, col int) int64 {
+	switch col {
+	case colID:
+		return int64(kind.shareID(share))
+	case colEndTime:
+		return share.EndTime.Unix()
+	case colStartTime:
+		// stored relative to the end time, which is constant for a period
+		return share.EndTime.Unix() - share.StartTime.Unix()
+	case colPending:
+		return boolToInt64(share.Pending)
+	case colCount:
+		return int64(share.Count)
+	case colMiners:
+		return int64(share.Miners)
+	case colWorkers:
+		return int64(share.Workers)
+	case colAcceptedShares:
+		return int64(share.AcceptedShares)
+	case colAcceptedAdjustedShares:
+		return int64(share.AcceptedAdjustedShares)
+	case colRejectedShares:
+		return int64(share.RejectedShares)
+	case colRejectedAdjustedShares:
+		return int64(share.RejectedAdjustedShares)
+	case colInvalidShares:
+		return int64(share.InvalidShares)
+	case colInvalidAdjustedShares:
+		return int64(share.InvalidAdjustedShares)
+	default:
+		return 0
+	}
+}
+
+// shareFloatValue returns the value of a float column of share.
+func shareFloatValue(share *Share, col int) float64 {
+	switch col {
+	case colHashrate:
+		return share.Hashrate
+	case colAvgHashrate:
+		return share.AvgHashrate
+	default:
+		return 0
+	}
+}
+
+/* encoding */
+
+// integer columns are delta encoded as zigzag varints, which makes the sorted id
+// and end time columns (and slowly changing counters) a byte or two per row.
+func encodeIntColumn(values []int64) []byte {
+	buf := make([]byte, 0, len(values)*2)
+	var prev int64
+	for _, value := range values {
+		buf = binary.AppendVarint(buf, value-prev)
+		prev = value
+	}
+
+	return buf
+}
+
+func decodeIntColumn(buf []byte, rows int) ([]int64, error) {
+	values := make([]int64, rows)
+	var prev int64
+	for i := range values {
+		delta, n := binary.Varint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupt int column at row %d", i)
+		}
+		buf = buf[n:]
+		prev += delta
+		values[i] = prev
+	}
+
+	return values, nil
+}
+
+// float columns are XORed with the previous value (as in Gorilla) and stored as
+// varints, so repeated and similar values only take the bytes that changed.
+func encodeFloatColumn(values []float64) []byte {
+	buf := make([]byte, 0, len(values)*4)
+	var prev uint64
+	for _, value := range values {
+		bits := math.Float64bits(value)
+		buf = binary.AppendUvarint(buf, bits^prev)
+		prev = bits
+	}
+
+	return buf
+}
+
+func decodeFloatColumn(buf []byte, rows int) ([]float64, error) {
+	values := make([]float64, rows)
+	var prev uint64
+	for i := range values {
+		xor, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupt float column at row %d", i)
+		}
+		buf = buf[n:]
+		prev ^= xor
+		values[i] = math.Float64frombits(prev)
+	}
+
+	return values, nil
+}
+
+/* partitions */
+
+// shareGroup holds the rows of a single id within a partition, sorted by end time.
+// every column is encoded separately so that queries only decode the columns they
+// need, the id is implied by the group.
+type shareGroup struct {
+	id      uint64
+	rows    int
+	columns [numShareColumns][]byte
+}
+
+// sharePartition is an immutable, encoded time range of a share series. rows are
+// grouped by id so that queries for a few ids skip the rest of the partition.
+type sharePartition struct {
+	rows   int
+	groups []*shareGroup
+}
+
+func (p *sharePartition) size() int {
+	var size int
+	for _, group := range p.groups {
+		for _, column := range group.columns {
+			size += len(column)
+		}
+	}
+
+	return size
+}
+
+func encodeGroup(kind shareKind, shares []*Share) *shareGroup {
+	group := &shareGroup{
+		id:   kind.shareID(shares[0]),
+		rows: len(shares),
+	}
+
+	for col := colEndTime; col < numShareColumns; col++ {
+		if isFloatColumn(col) {
+			values := make([]float64, len(shares))
+			for i, share := range shares {
+				values[i] = shareFloatValue(share, col)
+			}
+			group.columns[col] = encodeFloatColumn(values)
+		} else {
+			values := make([]int64, len(shares))
+			for i, share := range shares {
+				values[i] = shareIntValue(kind, share, col)
+			}
+			group.columns[col] = encodeIntColumn(values)
+		}
+	}
+
+	return group
+}
+
+func encodePartition(kind shareKind, shares []*Share) *sharePartition {
+	sort.Slice(shares, func(i, j int) bool {
+		if kind.shareID(shares[i]) != kind.shareID(shares[j]) {
+			return kind.shareID(shares[i]) < kind.shareID(shares[j])
+		}
+		return shares[i].EndTime.Before(shares[j].EndTime)
+	})
+
+	p := &sharePartition{rows: len(shares)}
+	for start := 0; start < len(shares); {
+		end := start + 1
+		for end < len(shares) && kind.shareID(shares[end]) == kind.shareID(shares[start]) {
+			end++
+		}
+		p.groups = append(p.groups, encodeGroup(kind, shares[start:end]))
+		start = end
+	}
+
+	return p
+}
+
+// partitionColumns holds the decoded columns of a partition.
+type partitionColumns struct {
+	rows    int
+	decoded [numShareColumns]bool
+	ints    [numShareColumns][]int64
+	floats  [numShareColumns][]float64
+}
+
+// decode decodes the given columns, along with the id and end time columns, of the
+// groups of ids (or of every group if ids is nil).
+func (p *sharePartition) decode(ids map[uint64]bool, cols ...int) (*partitionColumns, error) {
+	c := &partitionColumns{}
+	c.decoded[colID] = true
+	for _, col := range append([]int{colEndTime}, cols...) {
+		c.decoded[col] = true
+	}
+
+	for _, group := range p.groups {
+		if ids != nil && !ids[group.id] {
+			continue
+		}
+
+		for i := 0; i < group.rows; i++ {
+			c.ints[colID] = append(c.ints[colID], int64(group.id))
+		}
+		c.rows += group.rows
+
+		for col := colEndTime; col < numShareColumns; col++ {
+			if !c.decoded[col] {
+				continue
+			}
+
+			if isFloatColumn(col) {
+				values, err := decodeFloatColumn(group.columns[col], group.rows)
+				if err != nil {
+					return nil, err
+				}
+				c.floats[col] = append(c.floats[col], values...)
+			} else {
+				values, err := decodeIntColumn(group.columns[col], group.rows)
+				if err != nil {
+					return nil, err
+				}
+				c.ints[col] = append(c.ints[col], values...)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// decodeAll decodes every row of the partition into a share.
+func (p *sharePartition) decodeAll(key partitionKey) ([]*Share, error) {
+	cols := make([]int, numShareColumns)
+	for i := range cols {
+		cols[i] = i
+	}
+
+	c, err := p.decode(nil, cols...)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]*Share, c.rows)
+	for i := range shares {
+		shares[i] = c.share(key, i)
+	}
+
+	return shares, nil
+}
+
+func (c *partitionColumns) id(i int) uint64 {
+	return uint64(c.ints[colID][i])
+}
+
+func (c *partitionColumns) endTime(i int) int64 {
+	return c.ints[colEndTime][i]
+}
+
+func (c *partitionColumns) uint(col, i int) uint64 {
+	return uint64(c.ints[col][i])
+}
+
+// share builds a share from row i, only setting the decoded columns.
+func (c *partitionColumns) share(key partitionKey, i int) *Share {
+	share := &Share{
+		ChainID: key.chain,
+		Period:  key.period,
+		EndTime: time.Unix(c.endTime(i), 0).UTC(),
+	}
+	key.kind.setShareID(share, c.id(i))
+
+	if c.decoded[colStartTime] {
+		share.StartTime = time.Unix(c.endTime(i)-c.ints[colStartTime][i], 0).UTC()
+	}
+	if c.decoded[colPending] {
+		share.Pending = c.ints[colPending][i] == 1
+	}
+	if c.decoded[colCount] {
+		share.Count = c.uint(colCount, i)
+	}
+	if c.decoded[colMiners] {
+		share.Miners = c.uint(colMiners, i)
+	}
+	if c.decoded[colWorkers] {
+		share.Workers = c.uint(colWorkers, i)
+	}
+	if c.decoded[colAcceptedShares] {
+		share.AcceptedShares = c.uint(colAcceptedShares, i)
+	}
+	if c.decoded[colAcceptedAdjustedShares] {
+		share.AcceptedAdjustedShares = c.uint(colAcceptedAdjustedShares, i)
+	}
+	if c.decoded[colRejectedShares] {
+		share.RejectedShares = c.uint(colRejectedShares, i)
+	}
+	if c.decoded[colRejectedAdjustedShares] {
+		share.RejectedAdjustedShares = c.uint(colRejectedAdjustedShares, i)
+	}
+	if c.decoded[colInvalidShares] {
+		share.InvalidShares = c.uint(colInvalidShares, i)
+	}
+	if c.decoded[colInvalidAdjustedShares] {
+		share.InvalidAdjustedShares = c.uint(colInvalidAdjustedShares, i)
+	}
+	if c.decoded[colHashrate] {
+		share.Hashrate = c.floats[colHashrate][i]
+	}
+	if c.decoded[colAvgHashrate] {
+		share.AvgHashrate = c.floats[colAvgHashrate][i]
+	}
+
+	return share
+}
+
+/* files */
+
+var partitionMagic = []byte("TSP1")
+
+// marshal serializes the partition as the magic, the row and group counts, every group
+// (its id, row count and every column prefixed by its length) and a trailing CRC32 of
+// everything before it.
+func (p *sharePartition) marshal() []byte {
+	buf := make([]byte, 0, p.size()+len(p.groups)*32+64)
+	buf = append(buf, partitionMagic...)
+	buf = binary.AppendUvarint(buf, uint64(p.rows))
+	buf = binary.AppendUvarint(buf, uint64(len(p.groups)))
+	for _, group := range p.groups {
+		buf = binary.AppendUvarint(buf, group.id)
+		buf = binary.AppendUvarint(buf, uint64(group.rows))
+		for col := colEndTime; col < numShareColumns; col++ {
+			buf = binary.AppendUvarint(buf, uint64(len(group.columns[col])))
+			buf = append(buf, group.columns[col]...)
+		}
+	}
+
+	return binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+}
+
+func unmarshalPartition(data []byte) (*sharePartition, error) {
+	if len(data) < len(partitionMagic)+4 || !bytes.Equal(data[:len(partitionMagic)], partitionMagic) {
+		return nil, fmt.Errorf("invalid partition header")
+	}
+
+	body, checksum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != checksum {
+		return nil, fmt.Errorf("partition checksum mismatch")
+	}
+	body = body[len(partitionMagic):]
+
+	readUvarint := func() (uint64, bool) {
+		value, n := binary.Uvarint(body)
+		if n <= 0 {
+			return 0, false
+		}
+		body = body[n:]
+		return value, true
+	}
+
+	rows, ok := readUvarint()
+	if !ok {
+		return nil, fmt.Errorf("invalid partition row count")
+	}
+	groups, ok := readUvarint()
+	if !ok || groups > rows {
+		return nil, fmt.Errorf("invalid partition group count")
+	}
+
+	p := &sharePartition{rows: int(rows), groups: make([]*shareGroup, groups)}
+	var groupRows uint64
+	for i := range p.groups {
+		id, ok := readUvarint()
+		if !ok {
+			return nil, fmt.Errorf("invalid partition group %d", i)
+		}
+		rows, ok := readUvarint()
+		if !ok || rows == 0 {
+			return nil, fmt.Errorf("invalid partition group %d row count", i)
+		}
+		groupRows += rows
+
+		group := &shareGroup{id: id, rows: int(rows)}
+		for col := colEndTime; col < numShareColumns; col++ {
+			length, ok := readUvarint()
+			if !ok || uint64(len(body)) < length {
+				return nil, fmt.Errorf("invalid partition group %d column %d", i, col)
+			}
+			group.columns[col] = body[:length]
+			body = body[length:]
+		}
+		p.groups[i] = group
+	}
+
+	if groupRows != rows {
+		return nil, fmt.Errorf("partition row count mismatch")
+	} else if len(body) != 0 {
+		return nil, fmt.Errorf("trailing partition data")
+	}
+
+	return p, nil
+}
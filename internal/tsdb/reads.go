@@ -219,6 +219,35 @@ func GetMinerShares(q dbcl.Querier, minerIDs []uint64, chain string, period int)
 	return output, err
 }
 
+func GetMinerSharesByTimeRange(
+	q dbcl.Querier,
+	chain string,
+	period int,
+	start, end time.Time,
+) ([]*Share, error) {
+	const query = `SELECT
+		miner_id,
+		chain_id,
+		accepted_shares,
+		start_time,
+		end_time
+	FROM miner_shares
+	WHERE
+		chain_id = ?
+	AND
+		period = ?
+	AND
+		pending = FALSE
+	AND
+		end_time BETWEEN ? AND ?
+	ORDER BY end_time;`
+
+	output := []*Share{}
+	err := q.Select(&output, query, chain, period, start, end)
+
+	return output, err
+}
+
 func GetMinerSharesSingleMetric(
 	q dbcl.Querier,
 	minerIDs []uint64,
@@ -0,0 +1,247 @@
+package tsdb
+
+import (
+	"time"
+
+	"github.com/magicpool-co/pool/pkg/dbcl"
+)
+
+// ShareStore holds the global, miner and worker share rollups that back the share charts.
+// it is implemented on top of MySQL (NewShareStore) and by an embedded columnar store
+// (OpenColumnarShareStore), which both serve the same queries.
+type ShareStore interface {
+	GetGlobalShares(chain string, period int) ([]*Share, error)
+	GetGlobalSharesSingleMetric(metric string, period int) ([]*Share, error)
+	GetPendingGlobalSharesByEndTime(timestamp time.Time, chain string, period int) ([]*Share, error)
+	GetGlobalSharesAverage(timestamp time.Time, chain string, period int, duration time.Duration) (float64, error)
+	GetGlobalSharesLast(period int) ([]*Share, error)
+	InsertGlobalShares(objects ...*Share) error
+	InsertPartialGlobalShares(objects ...*Share) error
+	InsertFinalGlobalShares(objects ...*Share) error
+	DeleteGlobalSharesBeforeEndTime(timestamp time.Time, chain string, period int) error
+
+	GetMinerShares(minerIDs []uint64, chain string, period int) ([]*Share, error)
+	GetMinerSharesByTimeRange(chain string, period int, start, end time.Time) ([]*Share, error)
+	GetMinerSharesSingleMetric(minerIDs []uint64, metric string, period int) ([]*Share, error)
+	GetPendingMinerSharesByEndTime(timestamp time.Time, chain string, period int) ([]*Share, error)
+	GetMinerSharesByEndTime(timestamp time.Time, minerIDs []uint64, chain string, period int) ([]*Share, error)
+	GetMinerSharesAverage(timestamp time.Time, chain string, period int, duration time.Duration) (map[uint64]float64, error)
+	GetMinersSharesSum(minerIDs []uint64, period int, duration time.Duration) ([]*Share, error)
+	GetMinersSharesLast(minerIDs []uint64, period int) ([]*Share, error)
+	InsertMinerShares(objects ...*Share) error
+	InsertPartialMinerShares(objects ...*Share) error
+	InsertFinalMinerShares(objects ...*Share) error
+	DeleteMinerSharesBeforeEndTime(timestamp time.Time, chain string, period int) error
+
+	GetWorkerShares(workerID uint64, chain string, period int) ([]*Share, error)
+	GetWorkerSharesSingleMetric(workerID uint64, metric string, period int) ([]*Share, error)
+	GetPendingWorkerSharesByEndTime(timestamp time.Time, chain string, period int) ([]*Share, error)
+	GetWorkerSharesAllChainsByEndTime(timestamp time.Time, workerIDs []uint64, period int) ([]*Share, error)
+	GetWorkerSharesAverage(timestamp time.Time, chain string, period int, duration time.Duration) (map[uint64]float64, error)
+	GetWorkerSharesSum(workerIDs []uint64, period int, duration time.Duration) ([]*Share, error)
+	GetWorkerSharesLast(workerID uint64, period int) ([]*Share, error)
+	InsertWorkerShares(objects ...*Share) error
+	InsertPartialWorkerShares(objects ...*Share) error
+	InsertFinalWorkerShares(objects ...*Share) error
+	DeleteWorkerSharesBeforeEndTime(timestamp time.Time, chain string, period int) error
+
+	// Transact runs fn with a store whose writes are applied together if fn returns
+	// nil and discarded otherwise. reads within fn see the writes made before them.
+	Transact(fn func(ShareStore) error) error
+}
+
+/* mysql */
+
+type sqlShareStore struct {
+	client *dbcl.Client
+	reader dbcl.Querier
+	writer dbcl.Querier
+}
+
+// NewShareStore returns a share store that reads from the reader and writes to the writer of client.
+func NewShareStore(client *dbcl.Client) ShareStore {
+	store := &sqlShareStore{
+		client: client,
+		reader: client.Reader(),
+		writer: client.Writer(),
+	}
+
+	return store
+}
+
+func (s *sqlShareStore) Transact(fn func(ShareStore) error) error {
+	if s.client == nil {
+		return fn(s)
+	}
+
+	tx, err := s.client.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.SafeRollback()
+
+	err = fn(&sqlShareStore{reader: tx, writer: tx})
+	if err != nil {
+		return err
+	}
+
+	return tx.SafeCommit()
+}
+
+/* mysql global shares */
+
+func (s *sqlShareStore) GetGlobalShares(chain string, period int) ([]*Share, error) {
+	return GetGlobalShares(s.reader, chain, period)
+}
+
+func (s *sqlShareStore) GetGlobalSharesSingleMetric(metric string, period int) ([]*Share, error) {
+	return GetGlobalSharesSingleMetric(s.reader, metric, period)
+}
+
+func (s *sqlShareStore) GetPendingGlobalSharesByEndTime(timestamp time.Time, chain string, period int) ([]*Share, error) {
+	return GetPendingGlobalSharesByEndTime(s.reader, timestamp, chain, period)
+}
+
+func (s *sqlShareStore) GetGlobalSharesAverage(
+	timestamp time.Time,
+	chain string,
+	period int,
+	duration time.Duration,
+) (float64, error) {
+	return GetGlobalSharesAverage(s.reader, timestamp, chain, period, duration)
+}
+
+func (s *sqlShareStore) GetGlobalSharesLast(period int) ([]*Share, error) {
+	return GetGlobalSharesLast(s.reader, period)
+}
+
+func (s *sqlShareStore) InsertGlobalShares(objects ...*Share) error {
+	return InsertGlobalShares(s.writer, objects...)
+}
+
+func (s *sqlShareStore) InsertPartialGlobalShares(objects ...*Share) error {
+	return InsertPartialGlobalShares(s.writer, objects...)
+}
+
+func (s *sqlShareStore) InsertFinalGlobalShares(objects ...*Share) error {
+	return InsertFinalGlobalShares(s.writer, objects...)
+}
+
+func (s *sqlShareStore) DeleteGlobalSharesBeforeEndTime(timestamp time.Time, chain string, period int) error {
+	return DeleteGlobalSharesBeforeEndTime(s.writer, timestamp, chain, period)
+}
+
+/* mysql miner shares */
+
+func (s *sqlShareStore) GetMinerShares(minerIDs []uint64, chain string, period int) ([]*Share, error) {
+	return GetMinerShares(s.reader, minerIDs, chain, period)
+}
+
+func (s *sqlShareStore) GetMinerSharesByTimeRange(chain string, period int, start, end time.Time) ([]*Share, error) {
+	return GetMinerSharesByTimeRange(s.reader, chain, period, start, end)
+}
+
+func (s *sqlShareStore) GetMinerSharesSingleMetric(minerIDs []uint64, metric string, period int) ([]*Share, error) {
+	return GetMinerSharesSingleMetric(s.reader, minerIDs, metric, period)
+}
+
+func (s *sqlShareStore) GetPendingMinerSharesByEndTime(timestamp time.Time, chain string, period int) ([]*Share, error) {
+	return GetPendingMinerSharesByEndTime(s.reader, timestamp, chain, period)
+}
+
+func (s *sqlShareStore) GetMinerSharesByEndTime(
+	timestamp time.Time,
+	minerIDs []uint64,
+	chain string,
+	period int,
+) ([]*Share, error) {
+	return GetMinerSharesByEndTime(s.reader, timestamp, minerIDs, chain, period)
+}
+
+func (s *sqlShareStore) GetMinerSharesAverage(
+	timestamp time.Time,
+	chain string,
+	period int,
+	duration time.Duration,
+) (map[uint64]float64, error) {
+	return GetMinerSharesAverage(s.reader, timestamp, chain, period, duration)
+}
+
+func (s *sqlShareStore) GetMinersSharesSum(minerIDs []uint64, period int, duration time.Duration) ([]*Share, error) {
+	return GetMinersSharesSum(s.reader, minerIDs, period, duration)
+}
+
+func (s *sqlShareStore) GetMinersSharesLast(minerIDs []uint64, period int) ([]*Share, error) {
+	return GetMinersSharesLast(s.reader, minerIDs, period)
+}
+
+func (s *sqlShareStore) InsertMinerShares(objects ...*Share) error {
+	return InsertMinerShares(s.writer, objects...)
+}
+
+func (s *sqlShareStore) InsertPartialMinerShares(objects ...*Share) error {
+	return InsertPartialMinerShares(s.writer, objects...)
+}
+
+func (s *sqlShareStore) InsertFinalMinerShares(objects ...*Share) error {
+	return InsertFinalMinerShares(s.writer, objects...)
+}
+
+func (s *sqlShareStore) DeleteMinerSharesBeforeEndTime(timestamp time.Time, chain string, period int) error {
+	return DeleteMinerSharesBeforeEndTime(s.writer, timestamp, chain, period)
+}
+
+/* mysql worker shares */
+
+func (s *sqlShareStore) GetWorkerShares(workerID uint64, chain string, period int) ([]*Share, error) {
+	return GetWorkerShares(s.reader, workerID, chain, period)
+}
+
+func (s *sqlShareStore) GetWorkerSharesSingleMetric(workerID uint64, metric string, period int) ([]*Share, error) {
+	return GetWorkerSharesSingleMetric(s.reader, workerID, metric, period)
+}
+
+func (s *sqlShareStore) GetPendingWorkerSharesByEndTime(timestamp time.Time, chain string, period int) ([]*Share, error) {
+	return GetPendingWorkerSharesByEndTime(s.reader, timestamp, chain, period)
+}
+
+func (s *sqlShareStore) GetWorkerSharesAllChainsByEndTime(
+	timestamp time.Time,
+	workerIDs []uint64,
+	period int,
+) ([]*Share, error) {
+	return GetWorkerSharesAllChainsByEndTime(s.reader, timestamp, workerIDs, period)
+}
+
+func (s *sqlShareStore) GetWorkerSharesAverage(
+	timestamp time.Time,
+	chain string,
+	period int,
+	duration time.Duration,
+) (map[uint64]float64, error) {
+	return GetWorkerSharesAverage(s.reader, timestamp, chain, period, duration)
+}
+
+func (s *sqlShareStore) GetWorkerSharesSum(workerIDs []uint64, period int, duration time.Duration) ([]*Share, error) {
+	return GetWorkerSharesSum(s.reader, workerIDs, period, duration)
+}
+
+func (s *sqlShareStore) GetWorkerSharesLast(workerID uint64, period int) ([]*Share, error) {
+	return GetWorkerSharesLast(s.reader, workerID, period)
+}
+
+func (s *sqlShareStore) InsertWorkerShares(objects ...*Share) error {
+	return InsertWorkerShares(s.writer, objects...)
+}
+
+func (s *sqlShareStore) InsertPartialWorkerShares(objects ...*Share) error {
+	return InsertPartialWorkerShares(s.writer, objects...)
+}
+
+func (s *sqlShareStore) InsertFinalWorkerShares(objects ...*Share) error {
+	return InsertFinalWorkerShares(s.writer, objects...)
+}
+
+func (s *sqlShareStore) DeleteWorkerSharesBeforeEndTime(timestamp time.Time, chain string, period int) error {
+	return DeleteWorkerSharesBeforeEndTime(s.writer, timestamp, chain, period)
+}
@@ -31,7 +31,7 @@ func GetDefaultUnits(chain string) (*big.Int, error) {
 	switch strings.ToUpper(chain) {
 	case "NEXA":
 		units = 1e2
-	case "BTC", "FIRO", "FLUX", "KAS", "RVN":
+	case "BCH", "BTC", "FIRO", "FLUX", "KAS", "KLS", "RVN", "ZEN":
 		units = 1e8
 	case "CFX", "ETC", "ETH":
 		units = 1e18
@@ -52,6 +52,9 @@ type PayoutBounds struct {
 	Max       *big.Int
 	Precision uint64
 	Units     uint64
+	// EarlyFee is the fee charged for payouts below the
+	// miner's threshold, in basis points (0.01%)
+	EarlyFee uint64
 }
 
 func (b *PayoutBounds) PrecisionMask() *big.Int {
@@ -61,9 +64,24 @@ func (b *PayoutBounds) PrecisionMask() *big.Int {
 	return mask
 }
 
+// EarlyPayoutFee returns the fee charged for a scheduled or requested
+// payout that is below the miner's threshold.
+func (b *PayoutBounds) EarlyPayoutFee(value *big.Int) *big.Int {
+	return SplitBigPercentage(value, b.EarlyFee, 10000)
+}
+
 func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 	var bounds *PayoutBounds
 	switch strings.ToUpper(chain) {
+	case "BCH":
+		bounds = &PayoutBounds{
+			Min:       MustParseBigInt("1000000"),
+			Default:   MustParseBigInt("10000000"),
+			Max:       MustParseBigInt("10000000000"),
+			Precision: 4,
+			Units:     8,
+			EarlyFee:  100,
+		}
 	case "BTC":
 		bounds = &PayoutBounds{
 			Min:       MustParseBigInt("75000"),
@@ -71,6 +89,7 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("200000000"),
 			Precision: 4,
 			Units:     8,
+			EarlyFee:  100,
 		}
 	case "CFX":
 		bounds = &PayoutBounds{
@@ -79,6 +98,7 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("1000000000000000000000000"),
 			Precision: 1,
 			Units:     18,
+			EarlyFee:  100,
 		}
 	case "ERG":
 		bounds = &PayoutBounds{
@@ -87,6 +107,7 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("25000000000000"),
 			Precision: 2,
 			Units:     9,
+			EarlyFee:  100,
 		}
 	case "ETC":
 		bounds = &PayoutBounds{
@@ -95,6 +116,7 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("2000000000000000000000"),
 			Precision: 3,
 			Units:     18,
+			EarlyFee:  100,
 		}
 	case "ETH":
 		bounds = &PayoutBounds{
@@ -103,6 +125,7 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("25000000000000000000"),
 			Precision: 4,
 			Units:     18,
+			EarlyFee:  100,
 		}
 	case "ETHW":
 		bounds = &PayoutBounds{
@@ -111,6 +134,7 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("20000000000000000000000"),
 			Precision: 3,
 			Units:     18,
+			EarlyFee:  100,
 		}
 	case "FIRO":
 		bounds = &PayoutBounds{
@@ -119,6 +143,7 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("2500000000000"),
 			Precision: 2,
 			Units:     8,
+			EarlyFee:  100,
 		}
 	case "FLUX":
 		bounds = &PayoutBounds{
@@ -127,6 +152,7 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("5000000000000"),
 			Precision: 2,
 			Units:     8,
+			EarlyFee:  100,
 		}
 	case "KAS":
 		bounds = &PayoutBounds{
@@ -135,6 +161,16 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("1000000000000000"),
 			Precision: 1,
 			Units:     8,
+			EarlyFee:  100,
+		}
+	case "KLS":
+		bounds = &PayoutBounds{
+			Min:       MustParseBigInt("100000000000"),
+			Default:   MustParseBigInt("1000000000000"),
+			Max:       MustParseBigInt("10000000000000000"),
+			Precision: 1,
+			Units:     8,
+			EarlyFee:  100,
 		}
 	case "NEXA":
 		bounds = &PayoutBounds{
@@ -143,6 +179,7 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("100000000000"),
 			Precision: 1,
 			Units:     2,
+			EarlyFee:  100,
 		}
 	case "RVN":
 		bounds = &PayoutBounds{
@@ -151,6 +188,16 @@ func GetDefaultPayoutBounds(chain string) (*PayoutBounds, error) {
 			Max:       MustParseBigInt("200000000000000"),
 			Precision: 1,
 			Units:     8,
+			EarlyFee:  100,
+		}
+	case "ZEN":
+		bounds = &PayoutBounds{
+			Min:       MustParseBigInt("10000000"),
+			Default:   MustParseBigInt("100000000"),
+			Max:       MustParseBigInt("500000000000"),
+			Precision: 2,
+			Units:     8,
+			EarlyFee:  100,
 		}
 	default:
 		return nil, fmt.Errorf("unsupported chain %s for get payout bounds", chain)
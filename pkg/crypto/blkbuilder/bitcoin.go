@@ -3,12 +3,25 @@ package blkbuilder
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/hex"
+	"fmt"
 
 	"github.com/magicpool-co/pool/pkg/crypto"
 	"github.com/magicpool-co/pool/pkg/crypto/merkle"
 	"github.com/magicpool-co/pool/pkg/crypto/wire"
+	"github.com/magicpool-co/pool/types"
 )
 
+// RollBitcoinVersion applies the version bits submitted by a miner to the job's version
+// according to the negotiated mask (BIP310), rejecting any bits outside of the mask.
+func RollBitcoinVersion(version, versionBits, mask uint32) (uint32, error) {
+	if versionBits&^mask != 0 {
+		return 0, fmt.Errorf("version bits %08x outside of mask %08x", versionBits, mask)
+	}
+
+	return (version &^ mask) | (versionBits & mask), nil
+}
+
 func SerializeBitcoinBlockHeader(
 	nonce, nTime, version uint32,
 	bits, prevHash string,
@@ -16,6 +29,14 @@ func SerializeBitcoinBlockHeader(
 ) ([]byte, []byte, error) {
 	merkleRoot := merkle.CalculateRoot(txHashes)
 
+	return serializeBitcoinBlockHeader(nonce, nTime, version, bits, prevHash, merkleRoot)
+}
+
+func serializeBitcoinBlockHeader(
+	nonce, nTime, version uint32,
+	bits, prevHash string,
+	merkleRoot []byte,
+) ([]byte, []byte, error) {
 	var buf bytes.Buffer
 	var order = binary.BigEndian
 	if err := wire.WriteElement(&buf, order, nonce); err != nil {
@@ -50,3 +71,140 @@ func SerializeBitcoinBlock(header []byte, txHexes [][]byte) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+// BitcoinBuilder builds SHA256d blocks from the standard stratum job, where the coinbase
+// is split around the extranonce and the merkle root is calculated from the branch.
+type BitcoinBuilder struct {
+	version      uint32
+	nTime        uint32
+	bits         string
+	prevHash     string
+	coinbase1    []byte
+	coinbase2    []byte
+	segwit       bool
+	merkleBranch [][]byte
+	txHexes      [][]byte
+}
+
+func NewBitcoinBuilder(
+	version, nTime uint32,
+	bits, prevHash string,
+	coinbase1, coinbase2 []byte,
+	segwit bool,
+	txHashes, txHexes [][]byte,
+) (*BitcoinBuilder, error) {
+	if len(bits) != 8 {
+		return nil, fmt.Errorf("invalid bits length")
+	} else if len(prevHash) != 64 {
+		return nil, fmt.Errorf("invalid prevhash length")
+	} else if len(txHashes) != len(txHexes) {
+		return nil, fmt.Errorf("tx hash and hex length mismatch")
+	}
+
+	builder := &BitcoinBuilder{
+		version:      version,
+		nTime:        nTime,
+		bits:         bits,
+		prevHash:     prevHash,
+		coinbase1:    coinbase1,
+		coinbase2:    coinbase2,
+		segwit:       segwit,
+		merkleBranch: merkle.CalculateBranch(txHashes),
+		txHexes:      txHexes,
+	}
+
+	return builder, nil
+}
+
+// SerializeCoinbase returns the coinbase (without witness data) and the coinbase
+// txid for the extranonce of the given work.
+func (b *BitcoinBuilder) SerializeCoinbase(work *types.StratumWork) ([]byte, []byte, error) {
+	if work.ExtraNonce == nil {
+		return nil, nil, fmt.Errorf("no extranonce")
+	}
+
+	coinbase := bytes.Join([][]byte{b.coinbase1, work.ExtraNonce, b.coinbase2}, nil)
+	coinbaseHash := crypto.ReverseBytes(crypto.Sha256d(coinbase))
+
+	return coinbase, coinbaseHash, nil
+}
+
+func (b *BitcoinBuilder) SerializeHeader(work *types.StratumWork) ([]byte, []byte, error) {
+	if work.Nonce == nil {
+		return nil, nil, fmt.Errorf("no nonce")
+	}
+
+	_, coinbaseHash, err := b.SerializeCoinbase(work)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nTime := b.nTime
+	if work.Time != nil {
+		nTime = uint32(work.Time.Value())
+	}
+
+	version := b.version
+	if work.Version != nil {
+		version = uint32(work.Version.Value())
+	}
+
+	merkleRoot := merkle.CalculateRootFromBranch(coinbaseHash, b.merkleBranch)
+
+	return serializeBitcoinBlockHeader(uint32(work.Nonce.Value()), nTime, version, b.bits, b.prevHash, merkleRoot)
+}
+
+func (b *BitcoinBuilder) SerializeBlock(work *types.StratumWork) ([]byte, error) {
+	header, _, err := b.SerializeHeader(work)
+	if err != nil {
+		return nil, err
+	}
+
+	coinbase, _, err := b.SerializeCoinbase(work)
+	if err != nil {
+		return nil, err
+	}
+
+	// segwit blocks require the coinbase to have a single witness item, the 32 byte
+	// reserved value, that is committed to in the witness commitment output
+	if b.segwit {
+		coinbase = bytes.Join([][]byte{
+			coinbase[:4],
+			[]byte{0x00, 0x01},
+			coinbase[4 : len(coinbase)-4],
+			[]byte{0x01, 0x20},
+			make([]byte, 32),
+			coinbase[len(coinbase)-4:],
+		}, nil)
+	}
+
+	return SerializeBitcoinBlock(header, append([][]byte{coinbase}, b.txHexes...))
+}
+
+func (b *BitcoinBuilder) PartialJob() []interface{} {
+	// stratum expects the prevhash as the internal byte order
+	// with each 4 byte word reversed, which is the same as
+	// reversing the word order of the display prevhash
+	prevHashBytes, _ := hex.DecodeString(b.prevHash)
+	prevHash := make([]byte, len(prevHashBytes))
+	for i := 0; i < len(prevHashBytes); i += 4 {
+		copy(prevHash[i:i+4], prevHashBytes[len(prevHashBytes)-i-4:len(prevHashBytes)-i])
+	}
+
+	merkleBranch := make([]string, len(b.merkleBranch))
+	for i, step := range b.merkleBranch {
+		merkleBranch[i] = hex.EncodeToString(step)
+	}
+
+	result := []interface{}{
+		hex.EncodeToString(prevHash),
+		hex.EncodeToString(b.coinbase1),
+		hex.EncodeToString(b.coinbase2),
+		merkleBranch,
+		fmt.Sprintf("%08x", b.version),
+		b.bits,
+		fmt.Sprintf("%08x", b.nTime),
+	}
+
+	return result
+}
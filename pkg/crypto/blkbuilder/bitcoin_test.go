@@ -3,6 +3,9 @@ package blkbuilder
 import (
 	"bytes"
 	"testing"
+
+	"github.com/magicpool-co/pool/pkg/crypto"
+	"github.com/magicpool-co/pool/types"
 )
 
 func TestSerializeBitcoinBlockHeader(t *testing.T) {
@@ -145,6 +148,54 @@ func TestSerializeBitcoinBlockHeader(t *testing.T) {
 	}
 }
 
+func TestRollBitcoinVersion(t *testing.T) {
+	tests := []struct {
+		version     uint32
+		versionBits uint32
+		mask        uint32
+		rolled      uint32
+		valid       bool
+	}{
+		{
+			version:     0x20000000,
+			versionBits: 0x096ee000,
+			mask:        0x1fffe000,
+			rolled:      695132160,
+			valid:       true,
+		},
+		{
+			version:     0x20000004,
+			versionBits: 0x00000000,
+			mask:        0x1fffe000,
+			rolled:      536870916,
+			valid:       true,
+		},
+		{
+			version:     0x20000000,
+			versionBits: 0x00000001,
+			mask:        0x1fffe000,
+			valid:       false,
+		},
+		{
+			version:     0x20000000,
+			versionBits: 0x00002000,
+			mask:        0,
+			valid:       false,
+		},
+	}
+
+	for i, tt := range tests {
+		rolled, err := RollBitcoinVersion(tt.version, tt.versionBits, tt.mask)
+		if tt.valid && err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("failed on %d: expected error", i)
+		} else if rolled != tt.rolled {
+			t.Errorf("failed on %d: version mismatch: have %d, want %d", i, rolled, tt.rolled)
+		}
+	}
+}
+
 func TestSerializeBitcoinBlock(t *testing.T) {
 	tests := []struct {
 		header   []byte
@@ -1148,3 +1199,56 @@ func TestSerializeBitcoinBlock(t *testing.T) {
 		}
 	}
 }
+
+func TestBitcoinBuilder(t *testing.T) {
+	const (
+		nonce    = 3771968490
+		nTime    = 1654279674
+		version  = 536870916
+		bits     = "17096a20"
+		prevHash = "0000000000000000000812e3fbb6ee9d13bd83a070e360db9d4ffa727fbd31ea"
+	)
+
+	coinbase1 := []byte{0x01, 0x00, 0x00, 0x00, 0x01, 0x03, 0x5d, 0x47, 0x0b}
+	coinbase2 := []byte{0xff, 0xff, 0xff, 0xff, 0x01, 0x00, 0x00, 0x00, 0x00}
+	extraNonce := []byte{0xde, 0xad, 0xbe, 0xef, 0x00, 0x00, 0x00, 0x01}
+	coinbaseHash := crypto.ReverseBytes(crypto.Sha256d(bytes.Join([][]byte{coinbase1, extraNonce, coinbase2}, nil)))
+
+	// the header from the merkle branch should always match
+	// the header from the full list of transaction hashes
+	for txCount := 0; txCount < 8; txCount++ {
+		txHashes := make([][]byte, txCount)
+		txHexes := make([][]byte, txCount)
+		for i := range txHashes {
+			txHashes[i] = crypto.Sha256([]byte{byte(i)})
+			txHexes[i] = []byte{byte(i)}
+		}
+
+		builder, err := NewBitcoinBuilder(version, nTime, bits, prevHash, coinbase1, coinbase2, false, txHashes, txHexes)
+		if err != nil {
+			t.Errorf("failed on %d: NewBitcoinBuilder: %v", txCount, err)
+			continue
+		}
+
+		work := &types.StratumWork{
+			Nonce:      new(types.Number).SetFromValue(nonce),
+			ExtraNonce: extraNonce,
+		}
+
+		header, headerHash, err := builder.SerializeHeader(work)
+		if err != nil {
+			t.Errorf("failed on %d: SerializeHeader: %v", txCount, err)
+			continue
+		}
+
+		expectedHeader, expectedHeaderHash, err := SerializeBitcoinBlockHeader(nonce, nTime, version,
+			bits, prevHash, append([][]byte{coinbaseHash}, txHashes...))
+		if err != nil {
+			t.Errorf("failed on %d: SerializeBitcoinBlockHeader: %v", txCount, err)
+		} else if bytes.Compare(header, expectedHeader) != 0 {
+			t.Errorf("failed on %d: header mismatch: have %x, want %x", txCount, header, expectedHeader)
+		} else if bytes.Compare(headerHash, expectedHeaderHash) != 0 {
+			t.Errorf("failed on %d: header hash mismatch: have %x, want %x", txCount, headerHash, expectedHeaderHash)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package khash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// minimal single-chunk blake3, only what is needed for the (<= 80 byte) heavyhash
+// inputs. anything larger than a single chunk would need the full merkle tree mode.
+
+const (
+	blake3ChunkLen = 1024
+	blake3BlockLen = 64
+
+	blake3FlagChunkStart = 1 << 0
+	blake3FlagChunkEnd   = 1 << 1
+	blake3FlagRoot       = 1 << 3
+)
+
+var (
+	blake3IV = [8]uint32{
+		0x6A09E667, 0xBB67AE85, 0x3C6EF372, 0xA54FF53A,
+		0x510E527F, 0x9B05688C, 0x1F83D9AB, 0x5BE0CD19,
+	}
+	blake3Permutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+)
+
+func blake3G(s *[16]uint32, a, b, c, d int, mx, my uint32) {
+	s[a] = s[a] + s[b] + mx
+	s[d] = bits.RotateLeft32(s[d]^s[a], -16)
+	s[c] = s[c] + s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -12)
+	s[a] = s[a] + s[b] + my
+	s[d] = bits.RotateLeft32(s[d]^s[a], -8)
+	s[c] = s[c] + s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], -7)
+}
+
+func blake3Compress(cv [8]uint32, m [16]uint32, counter uint64, blockLen, flags uint32) [8]uint32 {
+	s := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		blake3IV[0], blake3IV[1], blake3IV[2], blake3IV[3],
+		uint32(counter), uint32(counter >> 32), blockLen, flags,
+	}
+
+	for round := 0; round < 7; round++ {
+		blake3G(&s, 0, 4, 8, 12, m[0], m[1])
+		blake3G(&s, 1, 5, 9, 13, m[2], m[3])
+		blake3G(&s, 2, 6, 10, 14, m[4], m[5])
+		blake3G(&s, 3, 7, 11, 15, m[6], m[7])
+		blake3G(&s, 0, 5, 10, 15, m[8], m[9])
+		blake3G(&s, 1, 6, 11, 12, m[10], m[11])
+		blake3G(&s, 2, 7, 8, 13, m[12], m[13])
+		blake3G(&s, 3, 4, 9, 14, m[14], m[15])
+
+		var permuted [16]uint32
+		for i, j := range blake3Permutation {
+			permuted[i] = m[j]
+		}
+		m = permuted
+	}
+
+	var out [8]uint32
+	for i := range out {
+		out[i] = s[i] ^ s[i+8]
+	}
+
+	return out
+}
+
+func blake3Sum256(data []byte) []byte {
+	if len(data) > blake3ChunkLen {
+		panic("blake3: input larger than a single chunk")
+	}
+
+	cv := blake3IV
+	flags := uint32(blake3FlagChunkStart)
+	for {
+		var block [blake3BlockLen]byte
+		blockLen := copy(block[:], data)
+		data = data[blockLen:]
+		if len(data) == 0 {
+			flags |= blake3FlagChunkEnd | blake3FlagRoot
+		}
+
+		var m [16]uint32
+		for i := range m {
+			m[i] = binary.LittleEndian.Uint32(block[i*4:])
+		}
+
+		cv = blake3Compress(cv, m, 0, uint32(blockLen), flags)
+		if len(data) == 0 {
+			break
+		}
+		flags = 0
+	}
+
+	digest := make([]byte, 32)
+	for i, word := range cv {
+		binary.LittleEndian.PutUint32(digest[i*4:], word)
+	}
+
+	return digest
+}
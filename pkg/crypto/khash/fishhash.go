@@ -0,0 +1,214 @@
+package khash
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// FishHash is an ethash style memory hard hash: a light cache is built from a fixed
+// seed and every dataset item is derived from 512 light cache items. unlike ethash
+// there are no epochs, so the light cache is built once and dataset items are computed
+// on demand (the full dataset would be ~4.6GB) with a bounded item cache.
+
+const (
+	fnvPrime = 0x01000193
+
+	fishHashLightCacheItems  = 1179641
+	fishHashDatasetItems     = 37748717
+	fishHashDatasetParents   = 512
+	fishHashDatasetAccesses  = 32
+	fishHashLightCacheRounds = 3
+	fishHashMaxCachedItems   = 1 << 16
+	fishHashWords            = 16 // 64 byte light cache items
+	fishHashDatasetItemWords = 2 * fishHashWords
+)
+
+var fishHashSeed = []byte{
+	0xeb, 0x01, 0x63, 0xae, 0xf2, 0xab, 0x1c, 0x5a, 0x66, 0x31, 0x0c, 0x1c, 0x14, 0xd6, 0x0f, 0x42,
+	0x55, 0xa9, 0xb3, 0x9b, 0x0e, 0xdf, 0x26, 0x53, 0x98, 0x44, 0xf1, 0x17, 0xad, 0x67, 0x21, 0x19,
+}
+
+func fnv1(u, v uint32) uint32 {
+	return (u * fnvPrime) ^ v
+}
+
+func keccak512(dst []uint32, src []uint32) {
+	var buf [64]byte
+	for i, word := range src {
+		binary.LittleEndian.PutUint32(buf[i*4:], word)
+	}
+
+	h := sha3.NewLegacyKeccak512()
+	h.Write(buf[:])
+	h.Sum(buf[:0])
+	for i := range dst {
+		dst[i] = binary.LittleEndian.Uint32(buf[i*4:])
+	}
+}
+
+type fishHash struct {
+	lightCacheItems int
+	datasetItems    uint32
+
+	cacheOnce  sync.Once
+	lightCache []uint32
+
+	mu    sync.Mutex
+	items map[uint32][]uint32
+}
+
+func newFishHash(lightCacheItems int, datasetItems uint32) *fishHash {
+	f := &fishHash{
+		lightCacheItems: lightCacheItems,
+		datasetItems:    datasetItems,
+		items:           make(map[uint32][]uint32),
+	}
+
+	return f
+}
+
+// buildLightCache fills the light cache the same way ethash does, sequential
+// keccak512 hashes of the seed followed by rounds of RandMemoHash.
+func (f *fishHash) buildLightCache() {
+	n := f.lightCacheItems
+	cache := make([]uint32, n*fishHashWords)
+
+	h := sha3.NewLegacyKeccak512()
+	h.Write(fishHashSeed)
+	seed := h.Sum(nil)
+	for i := 0; i < fishHashWords; i++ {
+		cache[i] = binary.LittleEndian.Uint32(seed[i*4:])
+	}
+
+	for i := 1; i < n; i++ {
+		keccak512(cache[i*fishHashWords:(i+1)*fishHashWords], cache[(i-1)*fishHashWords:i*fishHashWords])
+	}
+
+	temp := make([]uint32, fishHashWords)
+	for round := 0; round < fishHashLightCacheRounds; round++ {
+		for i := 0; i < n; i++ {
+			v := int(cache[i*fishHashWords] % uint32(n))
+			w := (n + i - 1) % n
+			for j := range temp {
+				temp[j] = cache[v*fishHashWords+j] ^ cache[w*fishHashWords+j]
+			}
+			keccak512(cache[i*fishHashWords:(i+1)*fishHashWords], temp)
+		}
+	}
+
+	f.lightCache = cache
+}
+
+func (f *fishHash) getLightCache() []uint32 {
+	f.cacheOnce.Do(f.buildLightCache)
+
+	return f.lightCache
+}
+
+// calculateDatasetHalf computes a 64 byte half of a dataset item from 512 light cache items.
+func (f *fishHash) calculateDatasetHalf(cache []uint32, dst []uint32, index uint32) {
+	n := uint32(f.lightCacheItems)
+
+	var mix [fishHashWords]uint32
+	copy(mix[:], cache[(index%n)*fishHashWords:])
+	mix[0] ^= index
+	keccak512(mix[:], mix[:])
+
+	for round := uint32(0); round < fishHashDatasetParents; round++ {
+		parent := fnv1(index^round, mix[round%fishHashWords]) % n
+		parentItem := (*[fishHashWords]uint32)(cache[parent*fishHashWords:])
+		for j := range mix {
+			mix[j] = fnv1(mix[j], parentItem[j])
+		}
+	}
+
+	keccak512(dst, mix[:])
+}
+
+// lookup returns the 128 byte dataset item, built from the 64 byte items 2*index and 2*index+1.
+func (f *fishHash) lookup(index uint32) []uint32 {
+	f.mu.Lock()
+	item, ok := f.items[index]
+	f.mu.Unlock()
+	if ok {
+		return item
+	}
+
+	cache := f.getLightCache()
+	item = make([]uint32, fishHashDatasetItemWords)
+	f.calculateDatasetHalf(cache, item[:fishHashWords], index*2)
+	f.calculateDatasetHalf(cache, item[fishHashWords:], index*2+1)
+
+	f.mu.Lock()
+	if len(f.items) >= fishHashMaxCachedItems {
+		f.items = make(map[uint32][]uint32)
+	}
+	f.items[index] = item
+	f.mu.Unlock()
+
+	return item
+}
+
+// kernel runs the FishHash kernel over a 64 byte seed, returning the 32 byte mix hash.
+func (f *fishHash) kernel(seed []byte) []byte {
+	mix := make([]uint32, fishHashDatasetItemWords)
+	for i := 0; i < fishHashWords; i++ {
+		mix[i] = binary.LittleEndian.Uint32(seed[i*4:])
+		mix[i+fishHashWords] = mix[i]
+	}
+
+	fetch1 := make([]uint32, fishHashDatasetItemWords)
+	fetch2 := make([]uint32, fishHashDatasetItemWords)
+	for i := 0; i < fishHashDatasetAccesses; i++ {
+		fetch0 := f.lookup(mix[0] % f.datasetItems)
+		copy(fetch1, f.lookup(mix[4]%f.datasetItems))
+		copy(fetch2, f.lookup(mix[8]%f.datasetItems))
+
+		for j := range mix {
+			fetch1[j] = fnv1(mix[j], fetch1[j])
+			fetch2[j] = mix[j] ^ fetch2[j]
+		}
+
+		for j := 0; j < len(mix)/2; j++ {
+			f0 := uint64(fetch0[2*j]) | uint64(fetch0[2*j+1])<<32
+			f1 := uint64(fetch1[2*j]) | uint64(fetch1[2*j+1])<<32
+			f2 := uint64(fetch2[2*j]) | uint64(fetch2[2*j+1])<<32
+			value := f0*f1 + f2
+			mix[2*j] = uint32(value)
+			mix[2*j+1] = uint32(value >> 32)
+		}
+	}
+
+	digest := make([]byte, 32)
+	for i := 0; i < len(mix); i += 4 {
+		h := fnv1(fnv1(fnv1(mix[i], mix[i+1]), mix[i+2]), mix[i+3])
+		binary.LittleEndian.PutUint32(digest[i:], h)
+	}
+
+	return digest
+}
+
+// sum256 hashes a 32 byte input, zero padded to the 64 byte seed of the kernel.
+func (f *fishHash) sum256(data []byte) []byte {
+	seed := make([]byte, 64)
+	copy(seed, data)
+
+	return f.kernel(seed)
+}
+
+var (
+	fishHashOnce   sync.Once
+	fishHashShared *fishHash
+)
+
+// fishHashSum256 hashes with the shared FishHash context, the light
+// cache (~72MB) is only built on first use.
+func fishHashSum256(data []byte) []byte {
+	fishHashOnce.Do(func() {
+		fishHashShared = newFishHash(fishHashLightCacheItems, fishHashDatasetItems)
+	})
+
+	return fishHashShared.sum256(data)
+}
@@ -0,0 +1,206 @@
+package khash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// kHeavyHash family: every variant shares the matrix multiplication step and
+// only differs in the hash functions wrapped around it.
+
+const (
+	size       = 64
+	iterations = size / 4
+	epsilon    = 1e-9
+)
+
+type hashFunc func([]byte) []byte
+
+type Client struct {
+	powHash    hashFunc
+	middleHash hashFunc // optional, applied between the pow hash and the matrix step
+	heavyHash  hashFunc
+}
+
+func cshake256(personal string) hashFunc {
+	return func(data []byte) []byte {
+		out := make([]byte, 32)
+		h := sha3.NewCShake256(nil, []byte(personal))
+		h.Write(data)
+		h.Read(out)
+
+		return out
+	}
+}
+
+func NewKaspa() *Client {
+	client := &Client{
+		powHash:   cshake256("ProofOfWorkHash"),
+		heavyHash: cshake256("HeavyHash"),
+	}
+
+	return client
+}
+
+// NewKarlsen returns KarlsenHashV1 (kHeavyHash with blake3), used for block version 1.
+func NewKarlsen() *Client {
+	client := &Client{
+		powHash:   blake3Sum256,
+		heavyHash: blake3Sum256,
+	}
+
+	return client
+}
+
+// NewKarlsenV2 returns KarlsenHashV2, used from block version 2: the blake3 pow hash is
+// run through FishHash before the matrix step.
+func NewKarlsenV2() *Client {
+	client := &Client{
+		powHash:    blake3Sum256,
+		middleHash: fishHashSum256,
+		heavyHash:  blake3Sum256,
+	}
+
+	return client
+}
+
+func (c *Client) Compute(hash []byte, timestamp int64, nonce uint64) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("hash must be 32 bytes")
+	}
+
+	// initialize the matrix
+	s0 := binary.LittleEndian.Uint64(hash[0:8])
+	s1 := binary.LittleEndian.Uint64(hash[8:16])
+	s2 := binary.LittleEndian.Uint64(hash[16:24])
+	s3 := binary.LittleEndian.Uint64(hash[24:32])
+	mat := newMatrix(s0, s1, s2, s3)
+
+	// build the header (PRE_POW_HASH || TIME || 32 zero bytes || NONCE)
+	header := make([]byte, 32+8+32+8)
+	copy(header[:32], hash)
+	binary.LittleEndian.PutUint64(header[32:40], uint64(timestamp))
+	binary.LittleEndian.PutUint64(header[72:80], nonce)
+	header = c.powHash(header)
+	if c.middleHash != nil {
+		header = c.middleHash(header)
+	}
+
+	// initialize the vector and product arrays
+	var v, p [size]uint16
+	for i := 0; i < size/2; i++ {
+		v[i*2] = uint16(header[i] >> 4)
+		v[i*2+1] = uint16(header[i] & 0x0f)
+	}
+
+	// build the product array
+	for i := 0; i < size; i++ {
+		var s uint16
+		for j := 0; j < size; j++ {
+			s += mat[i][j] * v[j]
+		}
+		p[i] = s >> 10
+	}
+
+	// calculate the digest
+	digest := make([]byte, 32)
+	for i := range digest {
+		digest[i] = header[i] ^ (byte(p[i*2]<<4) | byte(p[i*2+1]))
+	}
+
+	// hash the digest a final time, reverse bytes
+	digest = c.heavyHash(digest)
+	for i, j := 0, len(digest)-1; i < j; i, j = i+1, j-1 {
+		digest[i], digest[j] = digest[j], digest[i]
+	}
+
+	return digest, nil
+}
+
+/* matrix */
+
+type matrix [size][size]uint16
+
+type xoshiro256PlusPlus struct {
+	s0, s1, s2, s3 uint64
+}
+
+func (x *xoshiro256PlusPlus) next() uint64 {
+	value := rotl(x.s0+x.s3, 23) + x.s0
+	state := x.s1 << 17
+
+	x.s2 ^= x.s0
+	x.s3 ^= x.s1
+	x.s1 ^= x.s2
+	x.s0 ^= x.s3
+
+	x.s2 ^= state
+	x.s3 = rotl(x.s3, 45)
+
+	return value
+}
+
+func rotl(a, b uint64) uint64 {
+	return (a << b) | (a >> (64 - b))
+}
+
+func newMatrix(s0, s1, s2, s3 uint64) *matrix {
+	hasher := &xoshiro256PlusPlus{s0: s0, s1: s1, s2: s2, s3: s3}
+
+	var mat matrix
+	for calculateRank(&mat) != size {
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j += iterations {
+				value := hasher.next()
+				for k := 0; k < iterations; k++ {
+					mat[i][j+k] = uint16(value >> (4 * k) & 0x0f)
+				}
+			}
+		}
+	}
+
+	return &mat
+}
+
+func calculateRank(mat *matrix) int {
+	var copied [size][size]float64
+	for i := range mat {
+		for j := range mat[i] {
+			copied[i][j] = float64(mat[i][j])
+		}
+	}
+
+	var rank int
+	var rowsSelected [size]bool
+	for i := 0; i < size; i++ {
+		var j int
+		for j = 0; j < size; j++ {
+			if !rowsSelected[j] && math.Abs(copied[j][i]) > epsilon {
+				break
+			}
+		}
+
+		if j != size {
+			rank++
+			rowsSelected[j] = true
+			for k := i + 1; k < size; k++ {
+				copied[j][k] /= copied[j][i]
+			}
+
+			for k := 0; k < size; k++ {
+				if k == j || math.Abs(copied[k][i]) <= epsilon {
+					continue
+				}
+
+				for l := i + 1; l < size; l++ {
+					copied[k][l] -= copied[j][l] * copied[k][i]
+				}
+			}
+		}
+	}
+
+	return rank
+}
@@ -0,0 +1,226 @@
+package khash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/sencha-dev/powkit/heavyhash"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestBlake3Sum256(t *testing.T) {
+	tests := []struct {
+		input  []byte
+		digest string
+	}{
+		{
+			input:  []byte(""),
+			digest: "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262",
+		},
+		{
+			input:  []byte("abc"),
+			digest: "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85",
+		},
+	}
+
+	for i, tt := range tests {
+		digest := hex.EncodeToString(blake3Sum256(tt.input))
+		if digest != tt.digest {
+			t.Errorf("failed on %d: digest mismatch: have %s, want %s", i, digest, tt.digest)
+		}
+	}
+}
+
+func TestComputeKaspa(t *testing.T) {
+	tests := []struct {
+		hash      string
+		timestamp int64
+		nonce     uint64
+	}{
+		{
+			hash:      "352ef090484127512003b6a3119758e44b231a193b69a4519ecf5a369c0b571e",
+			timestamp: 1662650470960,
+			nonce:     11593478245369485089,
+		},
+		{
+			hash:      "bafee3d9fb38f13784b3910964c4b469621a9a9128d67c034e586f558304e68e",
+			timestamp: 1656450648874,
+			nonce:     0,
+		},
+	}
+
+	reference := heavyhash.NewKaspa()
+	client := NewKaspa()
+	for i, tt := range tests {
+		hash, err := hex.DecodeString(tt.hash)
+		if err != nil {
+			t.Errorf("failed on %d: decode: %v", i, err)
+			continue
+		}
+
+		expected, err := reference.Compute(hash, tt.timestamp, tt.nonce)
+		if err != nil {
+			t.Errorf("failed on %d: reference: %v", i, err)
+			continue
+		}
+
+		digest, err := client.Compute(hash, tt.timestamp, tt.nonce)
+		if err != nil {
+			t.Errorf("failed on %d: compute: %v", i, err)
+		} else if bytes.Compare(digest, expected) != 0 {
+			t.Errorf("failed on %d: digest mismatch: have %x, want %x", i, digest, expected)
+		}
+	}
+}
+
+func refKeccak512(data []byte) []byte {
+	h := sha3.NewLegacyKeccak512()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// refFishHash is a byte oriented transcription of the FishHash reference (light cache,
+// dataset items and kernel) used to check the word oriented implementation.
+func refFishHash(lightCacheItems, datasetItems uint32, input []byte) []byte {
+	word := func(b []byte, i uint32) uint32 { return binary.LittleEndian.Uint32(b[i*4:]) }
+	putWord := func(b []byte, i, v uint32) { binary.LittleEndian.PutUint32(b[i*4:], v) }
+
+	cache := make([][]byte, lightCacheItems)
+	cache[0] = refKeccak512(fishHashSeed)
+	for i := uint32(1); i < lightCacheItems; i++ {
+		cache[i] = refKeccak512(cache[i-1])
+	}
+
+	for round := 0; round < fishHashLightCacheRounds; round++ {
+		for i := uint32(0); i < lightCacheItems; i++ {
+			v := word(cache[i], 0) % lightCacheItems
+			w := (lightCacheItems + i - 1) % lightCacheItems
+			x := make([]byte, 64)
+			for j := range x {
+				x[j] = cache[v][j] ^ cache[w][j]
+			}
+			cache[i] = refKeccak512(x)
+		}
+	}
+
+	itemHalf := func(index uint32) []byte {
+		mix := append([]byte{}, cache[index%lightCacheItems]...)
+		putWord(mix, 0, word(mix, 0)^index)
+		mix = refKeccak512(mix)
+		for round := uint32(0); round < fishHashDatasetParents; round++ {
+			parent := fnv1(index^round, word(mix, round%16)) % lightCacheItems
+			for j := uint32(0); j < 16; j++ {
+				putWord(mix, j, fnv1(word(mix, j), word(cache[parent], j)))
+			}
+		}
+		return refKeccak512(mix)
+	}
+	item := func(index uint32) []byte {
+		return append(itemHalf(index*2), itemHalf(index*2+1)...)
+	}
+
+	seed := make([]byte, 64)
+	copy(seed, input)
+	mix := append(append([]byte{}, seed...), seed...)
+	for i := 0; i < fishHashDatasetAccesses; i++ {
+		fetch0 := item(word(mix, 0) % datasetItems)
+		fetch1 := item(word(mix, 4) % datasetItems)
+		fetch2 := item(word(mix, 8) % datasetItems)
+		for j := uint32(0); j < 32; j++ {
+			putWord(fetch1, j, fnv1(word(mix, j), word(fetch1, j)))
+			putWord(fetch2, j, word(mix, j)^word(fetch2, j))
+		}
+		for j := 0; j < 16; j++ {
+			value := binary.LittleEndian.Uint64(fetch0[j*8:])*binary.LittleEndian.Uint64(fetch1[j*8:]) +
+				binary.LittleEndian.Uint64(fetch2[j*8:])
+			binary.LittleEndian.PutUint64(mix[j*8:], value)
+		}
+	}
+
+	digest := make([]byte, 32)
+	for i := uint32(0); i < 32; i += 4 {
+		putWord(digest, i/4, fnv1(fnv1(fnv1(word(mix, i), word(mix, i+1)), word(mix, i+2)), word(mix, i+3)))
+	}
+
+	return digest
+}
+
+func TestFishHash(t *testing.T) {
+	tests := []struct {
+		lightCacheItems uint32
+		datasetItems    uint32
+		input           string
+	}{
+		{
+			lightCacheItems: 61,
+			datasetItems:    1021,
+			input:           "0000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			lightCacheItems: 1031,
+			datasetItems:    65521,
+			input:           "352ef090484127512003b6a3119758e44b231a193b69a4519ecf5a369c0b571e",
+		},
+	}
+
+	for i, tt := range tests {
+		input, err := hex.DecodeString(tt.input)
+		if err != nil {
+			t.Errorf("failed on %d: decode: %v", i, err)
+			continue
+		}
+
+		f := newFishHash(int(tt.lightCacheItems), tt.datasetItems)
+		expected := refFishHash(tt.lightCacheItems, tt.datasetItems, input)
+		digest := f.sum256(input)
+		if bytes.Compare(digest, expected) != 0 {
+			t.Errorf("failed on %d: digest mismatch: have %x, want %x", i, digest, expected)
+		}
+
+		// cached dataset items give the same result
+		if digest = f.sum256(input); bytes.Compare(digest, expected) != 0 {
+			t.Errorf("failed on %d: cached digest mismatch: have %x, want %x", i, digest, expected)
+		}
+	}
+}
+
+func TestComputeKarlsenV2(t *testing.T) {
+	hash, err := hex.DecodeString("352ef090484127512003b6a3119758e44b231a193b69a4519ecf5a369c0b571e")
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+
+	// KarlsenHashV2 is KarlsenHashV1 with the pow hash run through FishHash
+	f := newFishHash(61, 1021)
+	v1 := NewKarlsen()
+	v2 := &Client{powHash: blake3Sum256, middleHash: f.sum256, heavyHash: blake3Sum256}
+	middle := &Client{
+		powHash: func(data []byte) []byte {
+			return f.sum256(blake3Sum256(data))
+		},
+		heavyHash: blake3Sum256,
+	}
+
+	digestV1, err := v1.Compute(hash, 1662650470960, 11593478245369485089)
+	if err != nil {
+		t.Fatalf("failed to compute v1: %v", err)
+	}
+
+	digestV2, err := v2.Compute(hash, 1662650470960, 11593478245369485089)
+	if err != nil {
+		t.Fatalf("failed to compute v2: %v", err)
+	}
+
+	expected, err := middle.Compute(hash, 1662650470960, 11593478245369485089)
+	if err != nil {
+		t.Fatalf("failed to compute expected: %v", err)
+	}
+
+	if bytes.Compare(digestV2, expected) != 0 {
+		t.Errorf("digest mismatch: have %x, want %x", digestV2, expected)
+	} else if bytes.Compare(digestV1, digestV2) == 0 {
+		t.Errorf("v1 and v2 digests match")
+	}
+}
@@ -58,3 +58,40 @@ func CalculateRoot(items [][]byte) []byte {
 
 	return crypto.ReverseBytes(nodes[0].Data)
 }
+
+// CalculateBranch returns the merkle branch (in internal byte order) for the first
+// item of a tree whose remaining items are given, which is what stratum sends with
+// each job so that miners can recalculate the root after changing the coinbase.
+func CalculateBranch(items [][]byte) [][]byte {
+	level := make([][]byte, len(items)+1)
+	for i, item := range items {
+		level[i+1] = crypto.ReverseBytes(item)
+	}
+
+	branch := make([][]byte, 0)
+	for len(level) > 1 {
+		branch = append(branch, level[1])
+		if len(level)%2 != 0 {
+			level = append(level, level[len(level)-1])
+		}
+
+		next := [][]byte{nil}
+		for i := 2; i < len(level); i += 2 {
+			next = append(next, crypto.Sha256d(append(append([]byte{}, level[i]...), level[i+1]...)))
+		}
+		level = next
+	}
+
+	return branch
+}
+
+// CalculateRootFromBranch returns the merkle root for the item using the branch
+// generated by CalculateBranch. the item and the root are both in display order.
+func CalculateRootFromBranch(item []byte, branch [][]byte) []byte {
+	root := crypto.ReverseBytes(item)
+	for _, step := range branch {
+		root = crypto.Sha256d(append(root, step...))
+	}
+
+	return crypto.ReverseBytes(root)
+}
@@ -0,0 +1,121 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/magicpool-co/pool/pkg/crypto"
+)
+
+// SumNode is a node in a Merkle-sum tree, where every node commits
+// to both the hash and the sum of the values of its children.
+type SumNode struct {
+	Hash []byte
+	Sum  *big.Int
+}
+
+func (n SumNode) serialize() []byte {
+	return append(ethCommon.CopyBytes(n.Hash), ethCommon.LeftPadBytes(n.Sum.Bytes(), 32)...)
+}
+
+// emptySumNode is used to pad odd levels since, unlike a regular Merkle
+// tree, duplicating the last node would double count its sum.
+func emptySumNode() SumNode {
+	return SumNode{Hash: make([]byte, 32), Sum: new(big.Int)}
+}
+
+func newSumParent(left, right SumNode) SumNode {
+	node := SumNode{
+		Hash: crypto.Sha256(append(left.serialize(), right.serialize()...)),
+		Sum:  new(big.Int).Add(left.Sum, right.Sum),
+	}
+
+	return node
+}
+
+// NewSumLeaf creates a leaf for an account with the given id and value. The nonce
+// is unique per tree so that leaves cannot be linked across publications.
+func NewSumLeaf(id uint64, value *big.Int, nonce []byte) SumNode {
+	idBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(idBytes, id)
+
+	data := append(ethCommon.CopyBytes(nonce), idBytes...)
+	data = append(data, ethCommon.LeftPadBytes(value.Bytes(), 32)...)
+	leaf := SumNode{
+		Hash: crypto.Sha256(data),
+		Sum:  new(big.Int).Set(value),
+	}
+
+	return leaf
+}
+
+func nextSumLevel(nodes []SumNode) []SumNode {
+	if len(nodes)%2 != 0 {
+		nodes = append(nodes, emptySumNode())
+	}
+
+	level := make([]SumNode, len(nodes)/2)
+	for i := range level {
+		level[i] = newSumParent(nodes[i*2], nodes[i*2+1])
+	}
+
+	return level
+}
+
+// CalculateSumRoot returns the root of the Merkle-sum tree built from the leaves.
+func CalculateSumRoot(leaves []SumNode) SumNode {
+	if len(leaves) == 0 {
+		return emptySumNode()
+	}
+
+	nodes := leaves
+	for len(nodes) > 1 {
+		nodes = nextSumLevel(nodes)
+	}
+
+	return nodes[0]
+}
+
+// CalculateSumProof returns the sibling path from the leaf at index to the root.
+func CalculateSumProof(leaves []SumNode, index int) ([]SumNode, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf index out of range: %d", index)
+	}
+
+	proof := make([]SumNode, 0)
+	nodes := leaves
+	for len(nodes) > 1 {
+		if len(nodes)%2 != 0 {
+			nodes = append(nodes, emptySumNode())
+		}
+
+		proof = append(proof, nodes[index^1])
+		nodes = nextSumLevel(nodes)
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// VerifySumProof verifies that the leaf at index is included in the tree with the given root. Since
+// every sibling sum has to be non-negative, a valid proof also shows that the leaf's value is counted
+// in the root sum.
+func VerifySumProof(leaf SumNode, index int, proof []SumNode, root SumNode) bool {
+	node := leaf
+	for _, sibling := range proof {
+		if sibling.Sum == nil || sibling.Sum.Sign() < 0 {
+			return false
+		} else if index%2 == 0 {
+			node = newSumParent(node, sibling)
+		} else {
+			node = newSumParent(sibling, node)
+		}
+		index /= 2
+	}
+
+	return bytes.Equal(node.Hash, root.Hash) && node.Sum.Cmp(root.Sum) == 0
+}
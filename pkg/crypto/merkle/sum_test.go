@@ -0,0 +1,58 @@
+package merkle
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSumProof(t *testing.T) {
+	tests := []struct {
+		values []int64
+		sum    int64
+	}{
+		{
+			values: []int64{100},
+			sum:    100,
+		},
+		{
+			values: []int64{100, 250},
+			sum:    350,
+		},
+		{
+			values: []int64{100, 250, 3, 0, 7000},
+			sum:    7353,
+		},
+		{
+			values: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+			sum:    66,
+		},
+	}
+
+	nonce := []byte("nonce")
+	for i, tt := range tests {
+		leaves := make([]SumNode, len(tt.values))
+		for j, value := range tt.values {
+			leaves[j] = NewSumLeaf(uint64(j+1), big.NewInt(value), nonce)
+		}
+
+		root := CalculateSumRoot(leaves)
+		if root.Sum.Cmp(big.NewInt(tt.sum)) != 0 {
+			t.Errorf("failed on %d: sum mismatch: have %s, want %d", i, root.Sum, tt.sum)
+			continue
+		}
+
+		for j, leaf := range leaves {
+			proof, err := CalculateSumProof(leaves, j)
+			if err != nil {
+				t.Errorf("failed on %d: leaf %d: %v", i, j, err)
+			} else if !VerifySumProof(leaf, j, proof, root) {
+				t.Errorf("failed on %d: leaf %d: proof not valid", i, j)
+			}
+
+			tampered := NewSumLeaf(uint64(j+1), big.NewInt(tt.values[j]+1), nonce)
+			if VerifySumProof(tampered, j, proof, root) {
+				t.Errorf("failed on %d: leaf %d: tampered proof valid", i, j)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package btctx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secp256k1signer "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+
+	"github.com/magicpool-co/pool/pkg/crypto"
+	"github.com/magicpool-co/pool/pkg/crypto/base58"
+	"github.com/magicpool-co/pool/pkg/crypto/wire"
+)
+
+// MessagePrefix is the message magic of bitcoin. most forks only change the name of the chain.
+const MessagePrefix = "Bitcoin Signed Message:\n"
+
+func hashMessage(prefix, message string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := wire.WriteVarString(&buf, binary.LittleEndian, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	err = wire.WriteVarString(&buf, binary.LittleEndian, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.Sha256d(buf.Bytes()), nil
+}
+
+// SignMessage signs the message in the standard "signmessage" format (base64 compact signature),
+// which can be verified against the P2PKH address of the (uncompressed) key.
+func SignMessage(privKey *secp256k1.PrivateKey, message string) (string, error) {
+	return SignMessageWithPrefix(privKey, MessagePrefix, message)
+}
+
+// SignMessageWithPrefix signs the message in the "signmessage" format of a fork with its own message magic.
+func SignMessageWithPrefix(privKey *secp256k1.PrivateKey, prefix, message string) (string, error) {
+	hash, err := hashMessage(prefix, message)
+	if err != nil {
+		return "", err
+	}
+	sig := secp256k1signer.SignCompact(privKey, hash, false)
+
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// RecoverMessageAddress recovers the P2PKH address that signed the message.
+func RecoverMessageAddress(message, signature string, version []byte) (string, error) {
+	return RecoverMessageAddressWithPrefix(MessagePrefix, message, signature, version)
+}
+
+// RecoverMessageAddressWithPrefix recovers the P2PKH address that signed the message with the given message magic.
+func RecoverMessageAddressWithPrefix(prefix, message, signature string, version []byte) (string, error) {
+	hash, err := hashMessage(prefix, message)
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return "", err
+	}
+
+	pubKey, compressed, err := secp256k1signer.RecoverCompact(sig, hash)
+	if err != nil {
+		return "", err
+	}
+
+	pubKeyBytes := pubKey.SerializeUncompressed()
+	if compressed {
+		pubKeyBytes = pubKey.SerializeCompressed()
+	}
+	pubKeyHash := crypto.Ripemd160(crypto.Sha256(pubKeyBytes))
+
+	return base58.CheckEncode(version, pubKeyHash), nil
+}
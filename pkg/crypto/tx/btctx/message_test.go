@@ -0,0 +1,71 @@
+package btctx
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestSignMessage(t *testing.T) {
+	tests := []struct {
+		privKey string
+		prefix  string
+		message string
+	}{
+		{
+			privKey: "ab3f4f0d4b1a5b8ad9b8f4d5e0e7d9a9a44ff0b7f3c9cf86a0f5b9a1c6a3e2d1",
+			prefix:  MessagePrefix,
+			message: "BTC proof of reserves",
+		},
+		{
+			privKey: "0000000000000000000000000000000000000000000000000000000000000001",
+			prefix:  MessagePrefix,
+			message: "",
+		},
+		{
+			privKey: "ab3f4f0d4b1a5b8ad9b8f4d5e0e7d9a9a44ff0b7f3c9cf86a0f5b9a1c6a3e2d1",
+			prefix:  "Raven Signed Message:\n",
+			message: "RVN proof of reserves",
+		},
+	}
+
+	version := []byte{0x00}
+	for i, tt := range tests {
+		privBytes, err := hex.DecodeString(tt.privKey)
+		if err != nil {
+			t.Errorf("failed on %d: decode priv key: %v", i, err)
+			continue
+		}
+		privKey := secp256k1.PrivKeyFromBytes(privBytes)
+
+		sig, err := SignMessageWithPrefix(privKey, tt.prefix, tt.message)
+		if err != nil {
+			t.Errorf("failed on %d: sign: %v", i, err)
+			continue
+		}
+
+		expected := PrivKeyToAddress(privKey, version)
+		address, err := RecoverMessageAddressWithPrefix(tt.prefix, tt.message, sig, version)
+		if err != nil {
+			t.Errorf("failed on %d: recover: %v", i, err)
+		} else if address != expected {
+			t.Errorf("failed on %d: address mismatch: have %s, want %s", i, address, expected)
+		}
+
+		// the message magic is part of the signed hash
+		if tt.prefix != MessagePrefix {
+			address, err := RecoverMessageAddress(tt.message, sig, version)
+			if err == nil && address == expected {
+				t.Errorf("failed on %d: signature valid without the message magic", i)
+			}
+		} else {
+			defaultSig, err := SignMessage(privKey, tt.message)
+			if err != nil {
+				t.Errorf("failed on %d: sign: %v", i, err)
+			} else if defaultSig != sig {
+				t.Errorf("failed on %d: signature mismatch: have %s, want %s", i, defaultSig, sig)
+			}
+		}
+	}
+}
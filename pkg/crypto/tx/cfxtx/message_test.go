@@ -0,0 +1,53 @@
+package cfxtx
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/magicpool-co/pool/pkg/crypto"
+)
+
+func TestSignMessage(t *testing.T) {
+	tests := []struct {
+		privKey string
+		message string
+	}{
+		{
+			privKey: "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318",
+			message: "CFX proof of reserves",
+		},
+		{
+			privKey: "0000000000000000000000000000000000000000000000000000000000000001",
+			message: "",
+		},
+	}
+
+	for i, tt := range tests {
+		privBytes, err := hex.DecodeString(tt.privKey)
+		if err != nil {
+			t.Errorf("failed on %d: decode priv key: %v", i, err)
+			continue
+		}
+		privKey := secp256k1.PrivKeyFromBytes(privBytes)
+
+		sig, err := SignMessage(privKey, tt.message)
+		if err != nil {
+			t.Errorf("failed on %d: sign: %v", i, err)
+			continue
+		} else if sigBytes, _ := hex.DecodeString(sig[2:]); sigBytes[64] > 1 {
+			t.Errorf("failed on %d: recovery id mismatch: have %d, want 0 or 1", i, sigBytes[64])
+		}
+
+		pubKeyBytes := privKey.PubKey().SerializeUncompressed()
+		expected := "0x" + hex.EncodeToString(crypto.Keccak256(pubKeyBytes[1:])[12:])
+
+		address, err := RecoverMessageAddress(tt.message, sig)
+		if err != nil {
+			t.Errorf("failed on %d: recover: %v", i, err)
+		} else if address != expected {
+			t.Errorf("failed on %d: address mismatch: have %s, want %s", i, address, expected)
+		}
+	}
+}
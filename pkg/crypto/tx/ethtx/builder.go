@@ -22,26 +22,51 @@ func GenerateContractData(function string, args ...[]byte) []byte {
 	return data
 }
 
-func NewTx(
-	privKey *secp256k1.PrivateKey,
-	address string,
-	data []byte,
-	value, baseFee *big.Int,
-	gasLimit, nonce, chainID uint64,
-) (string, *big.Int, error) {
-	toAddress := ethCommon.HexToAddress(address)
-
+// calculateDynamicFee returns the max fee and priority tip per gas for a dynamic fee tx.
+func calculateDynamicFee(baseFee *big.Int) (*big.Int, *big.Int) {
 	// maxFee = (baseFee * 2) + priorityTip
 	priorityTip := new(big.Int).SetUint64(3 * uint64(1e9))
 	maxFee := new(big.Int).Mul(baseFee, big.NewInt(1))
 	maxFee.Add(maxFee, priorityTip)
+
+	return maxFee, priorityTip
+}
+
+// CalculateFees returns the maximum fee that a dynamic fee tx will spend for the given gas limit.
+func CalculateFees(baseFee *big.Int, gasLimit uint64) *big.Int {
+	maxFee, _ := calculateDynamicFee(baseFee)
 	fees := new(big.Int).Mul(maxFee, new(big.Int).SetUint64(gasLimit))
-	if value.Cmp(fees) <= 0 {
-		return "", nil, fmt.Errorf("fees greater than value")
-	}
-	value = new(big.Int).Sub(value, fees)
 
+	return fees
+}
+
+func signTx(privKey *secp256k1.PrivateKey, tx *ethTypes.Transaction, chainID uint64) (string, error) {
 	signer := ethTypes.NewLondonSigner(new(big.Int).SetUint64(chainID))
+	signedTx, err := ethTypes.SignTx(tx, signer, privKey.ToECDSA())
+	if err != nil {
+		return "", err
+	}
+
+	txBin, err := signedTx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	encodedTx := make([]byte, len(txBin)*2+2)
+	copy(encodedTx, "0x")
+	hex.Encode(encodedTx[2:], txBin)
+
+	return string(encodedTx), nil
+}
+
+func newDynamicFeeTx(
+	privKey *secp256k1.PrivateKey,
+	address string,
+	data []byte,
+	value, maxFee, priorityTip *big.Int,
+	gasLimit, nonce, chainID uint64,
+) (string, error) {
+	toAddress := ethCommon.HexToAddress(address)
 	tx := ethTypes.NewTx(&ethTypes.DynamicFeeTx{
 		ChainID:   new(big.Int).SetUint64(chainID),
 		Nonce:     nonce,
@@ -53,21 +78,50 @@ func NewTx(
 		Data:      data,
 	})
 
-	signedTx, err := ethTypes.SignTx(tx, signer, privKey.ToECDSA())
+	return signTx(privKey, tx, chainID)
+}
+
+func NewTx(
+	privKey *secp256k1.PrivateKey,
+	address string,
+	data []byte,
+	value, baseFee *big.Int,
+	gasLimit, nonce, chainID uint64,
+) (string, *big.Int, error) {
+	maxFee, priorityTip := calculateDynamicFee(baseFee)
+	fees := CalculateFees(baseFee, gasLimit)
+	if value.Cmp(fees) <= 0 {
+		return "", nil, fmt.Errorf("fees greater than value")
+	}
+	value = new(big.Int).Sub(value, fees)
+
+	tx, err := newDynamicFeeTx(privKey, address, data, value, maxFee, priorityTip, gasLimit, nonce, chainID)
 	if err != nil {
 		return "", nil, err
 	}
 
-	txBin, err := signedTx.MarshalBinary()
+	return tx, fees, nil
+}
+
+// NewContractTx creates a dynamic fee tx the same way as NewTx, except the fees are not
+// subtracted from the value since the caller is expected to have already accounted for them
+// (e.g. when the fees are split amongst the recipients of a contract call).
+func NewContractTx(
+	privKey *secp256k1.PrivateKey,
+	address string,
+	data []byte,
+	value, baseFee *big.Int,
+	gasLimit, nonce, chainID uint64,
+) (string, *big.Int, error) {
+	maxFee, priorityTip := calculateDynamicFee(baseFee)
+	fees := CalculateFees(baseFee, gasLimit)
+
+	tx, err := newDynamicFeeTx(privKey, address, data, value, maxFee, priorityTip, gasLimit, nonce, chainID)
 	if err != nil {
 		return "", nil, err
 	}
 
-	encodedTx := make([]byte, len(txBin)*2+2)
-	copy(encodedTx, "0x")
-	hex.Encode(encodedTx[2:], txBin)
-
-	return string(encodedTx), fees, nil
+	return tx, fees, nil
 }
 
 func NewLegacyTx(
@@ -84,7 +138,6 @@ func NewLegacyTx(
 	}
 	value = new(big.Int).Sub(value, fees)
 
-	signer := ethTypes.NewLondonSigner(new(big.Int).SetUint64(chainID))
 	tx := ethTypes.NewTx(&ethTypes.LegacyTx{
 		Nonce:    nonce,
 		GasPrice: gasPrice,
@@ -94,21 +147,40 @@ func NewLegacyTx(
 		Data:     data,
 	})
 
-	signedTx, err := ethTypes.SignTx(tx, signer, privKey.ToECDSA())
+	encodedTx, err := signTx(privKey, tx, chainID)
 	if err != nil {
 		return "", nil, err
 	}
 
-	txBin, err := signedTx.MarshalBinary()
+	return encodedTx, fees, nil
+}
+
+// NewLegacyContractTx creates a legacy tx that calls a contract. Unlike NewLegacyTx,
+// the fee is not deducted from the value, since the value is split by the contract.
+func NewLegacyContractTx(
+	privKey *secp256k1.PrivateKey,
+	address string,
+	data []byte,
+	value, gasPrice *big.Int, gasLimit, nonce, chainID uint64,
+) (string, *big.Int, error) {
+	toAddress := ethCommon.HexToAddress(address)
+	fees := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+
+	tx := ethTypes.NewTx(&ethTypes.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      gasLimit,
+		To:       &toAddress,
+		Value:    value,
+		Data:     data,
+	})
+
+	encodedTx, err := signTx(privKey, tx, chainID)
 	if err != nil {
 		return "", nil, err
 	}
 
-	encodedTx := make([]byte, len(txBin)*2+2)
-	copy(encodedTx, "0x")
-	hex.Encode(encodedTx[2:], txBin)
-
-	return string(encodedTx), fees, nil
+	return encodedTx, fees, nil
 }
 
 func CalculateTxID(tx string) string {
@@ -0,0 +1,127 @@
+package ethtx
+
+import (
+	"fmt"
+	"math/big"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+
+	txCommon "github.com/magicpool-co/pool/pkg/crypto/tx"
+	"github.com/magicpool-co/pool/types"
+)
+
+// encodeDisperseArrays encodes the dynamic address and value arrays as the tail
+// of the calldata, returning the tail along with the offset of the value array.
+func encodeDisperseArrays(addresses []string, values []*big.Int) ([]byte, int, error) {
+	if len(addresses) == 0 {
+		return nil, 0, fmt.Errorf("no recipients")
+	} else if len(addresses) != len(values) {
+		return nil, 0, fmt.Errorf("address and value length mismatch: %d and %d",
+			len(addresses), len(values))
+	}
+
+	count := new(big.Int).SetUint64(uint64(len(addresses))).Bytes()
+	tail := ethCommon.LeftPadBytes(count, 32)
+	for _, address := range addresses {
+		tail = append(tail, ethCommon.LeftPadBytes(ethCommon.HexToAddress(address).Bytes(), 32)...)
+	}
+
+	valueOffset := len(tail)
+	tail = append(tail, ethCommon.LeftPadBytes(count, 32)...)
+	for _, value := range values {
+		if value == nil || value.Sign() < 0 {
+			return nil, 0, fmt.Errorf("invalid value")
+		}
+		tail = append(tail, ethCommon.LeftPadBytes(value.Bytes(), 32)...)
+	}
+
+	return tail, valueOffset, nil
+}
+
+// GenerateDisperseEtherData generates the calldata for disperseEther(address[],uint256[]),
+// which sends the native value of the tx to each of the recipients.
+func GenerateDisperseEtherData(addresses []string, values []*big.Int) ([]byte, error) {
+	tail, valueOffset, err := encodeDisperseArrays(addresses, values)
+	if err != nil {
+		return nil, err
+	}
+
+	const headSize = 32 * 2
+	addressOffset := new(big.Int).SetUint64(headSize)
+	valueOffsetBig := new(big.Int).SetUint64(uint64(headSize + valueOffset))
+	data := GenerateContractData("disperseEther(address[],uint256[])",
+		addressOffset.Bytes(), valueOffsetBig.Bytes())
+
+	return append(data, tail...), nil
+}
+
+// GenerateDisperseTokenData generates the calldata for disperseToken(address,address[],uint256[]),
+// which transfers the ERC20 token from the sender to each of the recipients (requires an allowance).
+func GenerateDisperseTokenData(token string, addresses []string, values []*big.Int) ([]byte, error) {
+	tail, valueOffset, err := encodeDisperseArrays(addresses, values)
+	if err != nil {
+		return nil, err
+	}
+
+	const headSize = 32 * 3
+	addressOffset := new(big.Int).SetUint64(headSize)
+	valueOffsetBig := new(big.Int).SetUint64(uint64(headSize + valueOffset))
+	data := GenerateContractData("disperseToken(address,address[],uint256[])",
+		ethCommon.HexToAddress(token).Bytes(), addressOffset.Bytes(), valueOffsetBig.Bytes())
+
+	return append(data, tail...), nil
+}
+
+// MaxAllowance is the largest ERC20 allowance (2^256 - 1), which most
+// tokens never decrease, so the disperse contract only has to be approved once.
+var MaxAllowance = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// GenerateAllowanceData generates the calldata for allowance(address,address), which
+// returns the amount of the ERC20 token that the spender can transfer from the owner.
+func GenerateAllowanceData(owner, spender string) []byte {
+	return GenerateContractData("allowance(address,address)",
+		ethCommon.HexToAddress(owner).Bytes(), ethCommon.HexToAddress(spender).Bytes())
+}
+
+// GenerateApproveData generates the calldata for approve(address,uint256), which allows
+// the spender to transfer up to value of the ERC20 token from the sender.
+func GenerateApproveData(spender string, value *big.Int) []byte {
+	return GenerateContractData("approve(address,uint256)",
+		ethCommon.HexToAddress(spender).Bytes(), value.Bytes())
+}
+
+// DistributeDisperseFees splits the fee of a disperse tx between the outputs, proportional to their
+// values (through tx.DistributeFees), and records each output's share as its fee. The rounding remainder
+// is included in the share of the output it was deducted from. If deduct is set, the fee is subtracted
+// from the output values, otherwise (for tokens, where the fee is paid from the fee balance) the values
+// are left as is. The returned values are copies of the final output values, in the same order.
+func DistributeDisperseFees(outputs []*types.TxOutput, fee *big.Int, deduct bool) ([]*big.Int, error) {
+	if !fee.IsUint64() {
+		return nil, fmt.Errorf("fee too large: %s", fee)
+	}
+
+	feeOutputs := make([]*types.TxOutput, len(outputs))
+	for i, output := range outputs {
+		feeOutputs[i] = &types.TxOutput{
+			Address:  output.Address,
+			Value:    new(big.Int).Set(output.Value),
+			SplitFee: true,
+		}
+	}
+
+	err := txCommon.DistributeFees(nil, feeOutputs, fee.Uint64(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*big.Int, len(outputs))
+	for i, output := range outputs {
+		output.Fee = new(big.Int).Sub(output.Value, feeOutputs[i].Value)
+		if deduct {
+			output.Value = new(big.Int).Set(feeOutputs[i].Value)
+		}
+		values[i] = new(big.Int).Set(output.Value)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,156 @@
+package ethtx
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/magicpool-co/pool/types"
+)
+
+func TestGenerateDisperseData(t *testing.T) {
+	tests := []struct {
+		token     string
+		addresses []string
+		values    []*big.Int
+		data      string
+	}{
+		{
+			token: "",
+			addresses: []string{
+				"0xae8c89152d34206b5bbaaebee2a50e163466f73d",
+				"0x8ba1f109551bd432803012645ac136ddd64dba72",
+			},
+			values: []*big.Int{
+				new(big.Int).SetUint64(1000000000000000000),
+				new(big.Int).SetUint64(250000),
+			},
+			data: "e63d38ed" +
+				"0000000000000000000000000000000000000000000000000000000000000040" +
+				"00000000000000000000000000000000000000000000000000000000000000a0" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"000000000000000000000000ae8c89152d34206b5bbaaebee2a50e163466f73d" +
+				"0000000000000000000000008ba1f109551bd432803012645ac136ddd64dba72" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"0000000000000000000000000000000000000000000000000de0b6b3a7640000" +
+				"000000000000000000000000000000000000000000000000000000000003d090",
+		},
+		{
+			token: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+			addresses: []string{
+				"0xae8c89152d34206b5bbaaebee2a50e163466f73d",
+				"0x8ba1f109551bd432803012645ac136ddd64dba72",
+			},
+			values: []*big.Int{
+				new(big.Int).SetUint64(1000000000000000000),
+				new(big.Int).SetUint64(250000),
+			},
+			data: "c73a2d60" +
+				"000000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48" +
+				"0000000000000000000000000000000000000000000000000000000000000060" +
+				"00000000000000000000000000000000000000000000000000000000000000c0" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"000000000000000000000000ae8c89152d34206b5bbaaebee2a50e163466f73d" +
+				"0000000000000000000000008ba1f109551bd432803012645ac136ddd64dba72" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"0000000000000000000000000000000000000000000000000de0b6b3a7640000" +
+				"000000000000000000000000000000000000000000000000000000000003d090",
+		},
+	}
+
+	for i, tt := range tests {
+		var data []byte
+		var err error
+		if tt.token == "" {
+			data, err = GenerateDisperseEtherData(tt.addresses, tt.values)
+		} else {
+			data, err = GenerateDisperseTokenData(tt.token, tt.addresses, tt.values)
+		}
+
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if hex.EncodeToString(data) != tt.data {
+			t.Errorf("failed on %d: data mismatch: have %x, want %s", i, data, tt.data)
+		}
+	}
+}
+
+func TestGenerateAllowanceData(t *testing.T) {
+	owner := "0xae8c89152d34206b5bbaaebee2a50e163466f73d"
+	spender := "0xD152f549545093347A162Dce210e7293f1452150"
+
+	data := GenerateAllowanceData(owner, spender)
+	want := "dd62ed3e" +
+		"000000000000000000000000ae8c89152d34206b5bbaaebee2a50e163466f73d" +
+		"000000000000000000000000d152f549545093347a162dce210e7293f1452150"
+	if hex.EncodeToString(data) != want {
+		t.Errorf("allowance data mismatch: have %x, want %s", data, want)
+	}
+
+	data = GenerateApproveData(spender, MaxAllowance)
+	want = "095ea7b3" +
+		"000000000000000000000000d152f549545093347a162dce210e7293f1452150" +
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+	if hex.EncodeToString(data) != want {
+		t.Errorf("approve data mismatch: have %x, want %s", data, want)
+	}
+}
+
+func TestDistributeDisperseFees(t *testing.T) {
+	tests := []struct {
+		values    []uint64
+		fee       uint64
+		deduct    bool
+		fees      []uint64
+		outValues []uint64
+	}{
+		{
+			values:    []uint64{1_000_000, 3_000_000},
+			fee:       40_000,
+			deduct:    true,
+			fees:      []uint64{10_000, 30_000},
+			outValues: []uint64{990_000, 2_970_000},
+		},
+		{
+			// the rounding remainder goes to the first output
+			values:    []uint64{1_000_000, 1_000_000, 1_000_000},
+			fee:       10_001,
+			deduct:    true,
+			fees:      []uint64{3_335, 3_333, 3_333},
+			outValues: []uint64{996_665, 996_667, 996_667},
+		},
+		{
+			// tokens only record the fee
+			values:    []uint64{1_000_000, 3_000_000},
+			fee:       40_001,
+			deduct:    false,
+			fees:      []uint64{10_001, 30_000},
+			outValues: []uint64{1_000_000, 3_000_000},
+		},
+	}
+
+	for i, tt := range tests {
+		outputs := make([]*types.TxOutput, len(tt.values))
+		for j, value := range tt.values {
+			outputs[j] = &types.TxOutput{Value: new(big.Int).SetUint64(value)}
+		}
+
+		values, err := DistributeDisperseFees(outputs, new(big.Int).SetUint64(tt.fee), tt.deduct)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		}
+
+		for j, output := range outputs {
+			if output.Fee.Uint64() != tt.fees[j] {
+				t.Errorf("failed on %d: output %d: fee mismatch: have %s, want %d", i, j, output.Fee, tt.fees[j])
+			} else if output.Value.Uint64() != tt.outValues[j] {
+				t.Errorf("failed on %d: output %d: value mismatch: have %s, want %d", i, j, output.Value, tt.outValues[j])
+			} else if values[j].Cmp(output.Value) != 0 {
+				t.Errorf("failed on %d: output %d: returned value mismatch: have %s, want %s", i, j, values[j], output.Value)
+			} else if values[j] == output.Value {
+				t.Errorf("failed on %d: output %d: returned value aliases the output value", i, j)
+			}
+		}
+	}
+}
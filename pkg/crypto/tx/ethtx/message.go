@@ -0,0 +1,51 @@
+package ethtx
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secp256k1signer "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+
+	"github.com/magicpool-co/pool/pkg/crypto"
+)
+
+func hashMessage(message string) []byte {
+	prefix := "\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message))
+	return crypto.Keccak256([]byte(prefix + message))
+}
+
+// SignMessage signs the message in the EIP-191 "personal_sign" format,
+// returning the signature as hex (r || s || v).
+func SignMessage(privKey *secp256k1.PrivateKey, message string) (string, error) {
+	sig := secp256k1signer.SignCompact(privKey, hashMessage(message), false)
+	if len(sig) != 65 {
+		return "", fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	sig = append(sig[1:], sig[0])
+
+	return "0x" + hex.EncodeToString(sig), nil
+}
+
+// RecoverMessageAddress recovers the address that signed the message in the EIP-191 format.
+func RecoverMessageAddress(message, signature string) (string, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return "", err
+	} else if len(sig) != 65 {
+		return "", fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+	sig = append([]byte{sig[64]}, sig[:64]...)
+
+	pubKey, _, err := secp256k1signer.RecoverCompact(sig, hashMessage(message))
+	if err != nil {
+		return "", err
+	}
+
+	pubKeyBytes := pubKey.SerializeUncompressed()
+	address := "0x" + hex.EncodeToString(crypto.Keccak256(pubKeyBytes[1:])[12:])
+
+	return address, nil
+}
@@ -0,0 +1,48 @@
+package ethtx
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+func TestSignMessage(t *testing.T) {
+	tests := []struct {
+		privKey   string
+		message   string
+		signature string
+		address   string
+	}{
+		{
+			privKey:   "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318",
+			message:   "Some data",
+			signature: "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c",
+			address:   "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23",
+		},
+	}
+
+	for i, tt := range tests {
+		privBytes, err := hex.DecodeString(tt.privKey)
+		if err != nil {
+			t.Errorf("failed on %d: decode priv key: %v", i, err)
+			continue
+		}
+		privKey := secp256k1.PrivKeyFromBytes(privBytes)
+
+		sig, err := SignMessage(privKey, tt.message)
+		if err != nil {
+			t.Errorf("failed on %d: sign: %v", i, err)
+			continue
+		} else if sig != tt.signature {
+			t.Errorf("failed on %d: signature mismatch: have %s, want %s", i, sig, tt.signature)
+		}
+
+		address, err := RecoverMessageAddress(tt.message, sig)
+		if err != nil {
+			t.Errorf("failed on %d: recover: %v", i, err)
+		} else if address != tt.address {
+			t.Errorf("failed on %d: address mismatch: have %s, want %s", i, address, tt.address)
+		}
+	}
+}
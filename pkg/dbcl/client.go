@@ -2,6 +2,7 @@ package dbcl
 
 import (
 	"fmt"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
@@ -97,3 +98,30 @@ func New(
 
 	return client, nil
 }
+
+// NewFromDSN opens a client that reads from and writes to the single database at dsn
+// (user:pass@tcp(host:port)/name), e.g. a snapshot restored from a backup. the default
+// query string is used if dsn has none.
+func NewFromDSN(dsn string, migrations map[string]string) (*Client, error) {
+	if !strings.Contains(dsn, "?") {
+		dsn += defaultQs
+	}
+
+	dbClient, err := initConnection(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		readClient:  dbClient,
+		writeClient: dbClient,
+		migrations:  migrations,
+	}
+
+	if err := client.Ping(); err != nil {
+		dbClient.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
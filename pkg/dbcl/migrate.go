@@ -1,7 +1,11 @@
 package dbcl
 
 import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
 	"embed"
+	"encoding/hex"
 	"fmt"
 	"io/fs"
 	"path/filepath"
@@ -9,9 +13,23 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	migrationTable       = "schema_migrations"
+	legacyMigrationTable = "migrations"
+	migrationLockTimeout = 60
 )
 
-var migrationExpr = regexp.MustCompile(`\d{3}.*\.(sql|down\.sql)`)
+var (
+	migrationExpr = regexp.MustCompile(`\d{3}.*\.(sql|down\.sql)`)
+
+	ErrMigrationChecksum = fmt.Errorf("migration checksum mismatch")
+	ErrMigrationLock     = fmt.Errorf("failed to acquire migration lock")
+)
 
 func FetchMigrations(path string, migrationFS *embed.FS) (map[string]string, error) {
 	matches, err := fs.Glob(migrationFS, path)
@@ -71,49 +89,64 @@ func FetchMigrations(path string, migrationFS *embed.FS) (map[string]string, err
 	return migrationIdx, nil
 }
 
-func (c *Client) initMigrationTable() error {
-	_, err := c.writeClient.Exec(`CREATE TABLE IF NOT EXISTS 
-				migrations(id VARCHAR(100) PRIMARY KEY)
-				AS SELECT "" AS id;`)
-	return err
+/* types */
+
+// MigrationRecord is the applied state of a single migration. a migration is
+// dirty while it is being applied (or after it failed part way through), in
+// which case AppliedStatements is the number of statements that succeeded.
+type MigrationRecord struct {
+	Version           int       `db:"version"`
+	Name              string    `db:"name"`
+	Checksum          string    `db:"checksum"`
+	Statements        int       `db:"statements"`
+	AppliedStatements int       `db:"applied_statements"`
+	Dirty             bool      `db:"dirty"`
+	Baseline          bool      `db:"baseline"`
+	ErrorMessage      *string   `db:"error_message"`
+	AppliedAt         time.Time `db:"applied_at"`
 }
 
-func (c *Client) migrationStatus() (string, error) {
-	var id string
-
-	err := c.initMigrationTable()
-	if err != nil {
-		return id, err
-	}
-
-	err = c.readClient.QueryRow("SELECT id FROM migrations").Scan(&id)
-	if err != nil {
-		return id, err
-	}
+// MigrationStep is a migration that still has to be executed. for a dirty
+// migration, Resume is the index of the first statement that has not been applied.
+type MigrationStep struct {
+	Version    int
+	Name       string
+	Checksum   string
+	Statements []*Statement
+	Resume     int
+}
 
-	return id, nil
+// Pending returns the statements of the step that have not been applied yet.
+func (s *MigrationStep) Pending() []*Statement {
+	return s.Statements[s.Resume:]
 }
 
-func (c *Client) updateMigrationStatus(status string) error {
-	_, err := c.writeClient.Exec("UPDATE migrations SET id = ?", status)
-	if err != nil {
-		return err
+func (s *MigrationStep) isDML() bool {
+	for _, statement := range s.Pending() {
+		if !statement.isDML() {
+			return false
+		}
 	}
 
-	return nil
+	return len(s.Pending()) > 0
 }
 
-func (c *Client) getMigrationList() ([]string, error) {
-	var files []string
-	for k := range c.migrations {
-		if !strings.HasSuffix(k, ".down.sql") {
-			files = append(files, k)
-		}
-	}
+type migrationFile struct {
+	version  int
+	name     string
+	up       string
+	down     string
+	checksum string
+}
 
-	return files, nil
+type migrationQuerier interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
 
+/* files */
+
 func parseVersion(path string) (int, error) {
 	s := strings.Split(path, "_")
 	version, err := strconv.Atoi(s[0])
@@ -124,152 +157,576 @@ func parseVersion(path string) (int, error) {
 	return version, nil
 }
 
-func (c *Client) getNextMigrations() ([]string, error) {
-	var versions []string
+func checksumMigration(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
 
-	current, err := c.migrationStatus()
-	if err != nil {
-		return versions, err
+func (c *Client) getMigrationFiles() ([]*migrationFile, error) {
+	fileIdx := make(map[int]*migrationFile)
+	for path, text := range c.migrations {
+		version, err := parseVersion(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		isDown := strings.HasSuffix(path, ".down.sql")
+		name := strings.TrimSuffix(strings.TrimSuffix(path, ".sql"), ".down")
+		file, ok := fileIdx[version]
+		if !ok {
+			file = &migrationFile{version: version, name: name}
+			fileIdx[version] = file
+		} else if file.name != name {
+			return nil, fmt.Errorf("duplicate migration version %03d: %s, %s", version, file.name, name)
+		}
+
+		if isDown {
+			file.down = text
+		} else {
+			file.up = text
+			file.checksum = checksumMigration(text)
+		}
 	}
 
-	files, err := c.getMigrationList()
-	if err != nil {
-		return versions, err
+	files := make([]*migrationFile, 0, len(fileIdx))
+	for _, file := range fileIdx {
+		files = append(files, file)
 	}
+	sort.Slice(files, func(i, j int) bool { return files[i].version < files[j].version })
 
-	currentVersion := -1
-	if len(current) > 0 {
-		currentVersion, err = parseVersion(current)
-		if err != nil {
-			return versions, err
+	return files, nil
+}
+
+// planMigrations compares the migration files with the applied records and returns
+// the migrations that still have to be executed. applied migrations that were edited
+// afterwards fail with ErrMigrationChecksum, dirty migrations are resumed (and are
+// allowed to differ, since the file was most likely fixed after the failure).
+func planMigrations(files []*migrationFile, records []*MigrationRecord) ([]*MigrationStep, error) {
+	fileIdx := make(map[int]*migrationFile)
+	for _, file := range files {
+		fileIdx[file.version] = file
+	}
+
+	maxVersion := -1
+	recordIdx := make(map[int]*MigrationRecord)
+	for _, record := range records {
+		if _, ok := fileIdx[record.Version]; !ok {
+			return nil, fmt.Errorf("applied migration %s has no file", record.Name)
+		} else if record.Version > maxVersion {
+			maxVersion = record.Version
 		}
+		recordIdx[record.Version] = record
 	}
 
-	for _, path := range files {
-		v, err := parseVersion(path)
+	steps := make([]*MigrationStep, 0)
+	for _, file := range files {
+		record, ok := recordIdx[file.version]
+		if ok && !record.Dirty {
+			if record.Checksum != file.checksum {
+				return nil, fmt.Errorf("%s: %w: applied %s, have %s",
+					file.name, ErrMigrationChecksum, record.Checksum, file.checksum)
+			}
+			continue
+		} else if !ok && file.version < maxVersion {
+			return nil, fmt.Errorf("%s: not applied, but later migrations are", file.name)
+		}
+
+		statements, err := SplitStatements(file.up)
 		if err != nil {
-			return versions, err
+			return nil, fmt.Errorf("%s: %w", file.name, err)
 		}
 
-		if v > currentVersion {
-			versions = append(versions, path)
+		step := &MigrationStep{
+			Version:    file.version,
+			Name:       file.name,
+			Checksum:   file.checksum,
+			Statements: statements,
 		}
+
+		if ok {
+			if record.AppliedStatements > len(statements) {
+				return nil, fmt.Errorf("%s: dirty with %d applied statements, file has %d",
+					file.name, record.AppliedStatements, len(statements))
+			}
+			step.Resume = record.AppliedStatements
+		}
+
+		steps = append(steps, step)
 	}
 
-	return versions, nil
+	return steps, nil
 }
 
-func (c *Client) getPrevMigration() (string, error) {
-	var version string
+func baselineRecords(files []*migrationFile, version int) ([]*MigrationRecord, error) {
+	records := make([]*MigrationRecord, 0)
+	for _, file := range files {
+		if file.version > version {
+			break
+		}
+
+		statements, err := SplitStatements(file.up)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.name, err)
+		}
+
+		records = append(records, &MigrationRecord{
+			Version:           file.version,
+			Name:              file.name,
+			Checksum:          file.checksum,
+			Statements:        len(statements),
+			AppliedStatements: len(statements),
+			Baseline:          true,
+		})
+	}
 
-	current, err := c.migrationStatus()
+	return records, nil
+}
+
+/* state */
+
+func hasTable(ctx context.Context, q migrationQuerier, table string) (bool, error) {
+	const query = `SELECT COUNT(*)
+	FROM information_schema.tables
+	WHERE table_schema = DATABASE() AND table_name = ?`
+
+	var count int
+	err := q.GetContext(ctx, &count, query, table)
+
+	return count > 0, err
+}
+
+func getMigrationRecords(ctx context.Context, q migrationQuerier) ([]*MigrationRecord, error) {
+	const query = `SELECT *
+	FROM schema_migrations
+	ORDER BY version`
+
+	exists, err := hasTable(ctx, q, migrationTable)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	output := []*MigrationRecord{}
+	err = q.SelectContext(ctx, &output, query)
+
+	return output, err
+}
+
+// getLegacyMigrationVersion returns the version stored in the single row migrations
+// table that was used before checksums were tracked (-1 if there is none).
+func getLegacyMigrationVersion(ctx context.Context, q migrationQuerier) (int, error) {
+	exists, err := hasTable(ctx, q, legacyMigrationTable)
+	if err != nil || !exists {
+		return -1, err
+	}
+
+	var id string
+	err = q.GetContext(ctx, &id, "SELECT id FROM migrations")
+	if err == sql.ErrNoRows || (err == nil && len(id) == 0) {
+		return -1, nil
+	} else if err != nil {
+		return -1, err
+	}
+
+	return parseVersion(id)
+}
+
+// readMigrationState returns the migration files and applied records without
+// writing anything. a database that is still on the legacy table is returned
+// as if it was baselined at the legacy version.
+func (c *Client) readMigrationState(ctx context.Context) ([]*migrationFile, []*MigrationRecord, error) {
+	files, err := c.getMigrationFiles()
 	if err != nil {
-		return version, err
+		return nil, nil, err
 	}
 
-	files, err := c.getMigrationList()
+	records, err := getMigrationRecords(ctx, c.writeClient)
 	if err != nil {
-		return version, err
+		return nil, nil, err
+	} else if len(records) > 0 {
+		return files, records, nil
 	}
 
-	currentVersion, err := parseVersion(current)
+	legacyVersion, err := getLegacyMigrationVersion(ctx, c.writeClient)
 	if err != nil {
-		return version, err
+		return nil, nil, err
+	}
+
+	records, err = baselineRecords(files, legacyVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, records, nil
+}
+
+// Migrations returns the applied (or dirty) migrations, ordered by version.
+func (c *Client) Migrations() ([]*MigrationRecord, error) {
+	_, records, err := c.readMigrationState(context.Background())
+	return records, err
+}
+
+// Plan returns the migrations UpgradeMigrations would execute, without
+// executing anything. the statements include the lock and algorithm clauses.
+func (c *Client) Plan() ([]*MigrationStep, error) {
+	files, records, err := c.readMigrationState(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return planMigrations(files, records)
+}
+
+// DowngradePlan returns the down migration DowngradeMigration would
+// execute, or nil if no migrations have been applied.
+func (c *Client) DowngradePlan() (*MigrationStep, error) {
+	files, records, err := c.readMigrationState(context.Background())
+	if err != nil || len(records) == 0 {
+		return nil, err
 	}
 
-	highestVersion := -1
+	return downgradeStep(files, records[len(records)-1])
+}
 
-	for _, path := range files {
-		v, err := parseVersion(path)
+func downgradeStep(files []*migrationFile, record *MigrationRecord) (*MigrationStep, error) {
+	for _, file := range files {
+		if file.version != record.Version {
+			continue
+		}
+
+		statements, err := SplitStatements(file.down)
 		if err != nil {
-			return version, err
+			return nil, fmt.Errorf("%s: %w", file.name, err)
 		}
 
-		if v < currentVersion && v > highestVersion {
-			highestVersion = v
-			version = path
+		step := &MigrationStep{
+			Version:    file.version,
+			Name:       file.name + ".down",
+			Checksum:   checksumMigration(file.down),
+			Statements: statements,
 		}
+
+		return step, nil
 	}
 
-	return version, nil
+	return nil, fmt.Errorf("applied migration %s has no file", record.Name)
 }
 
-func (c *Client) UpgradeMigrations() error {
-	migrations, err := c.getNextMigrations()
+/* execution */
+
+// withMigrationLock runs fn on a single connection holding a named lock, so that
+// multiple services starting at once don't apply the same migration concurrently.
+func (c *Client) withMigrationLock(fn func(context.Context, *sqlx.Conn) error) error {
+	const (
+		lockQuery    = "SELECT GET_LOCK(CONCAT('dbcl_migrations:', DATABASE()), ?)"
+		releaseQuery = "SELECT RELEASE_LOCK(CONCAT('dbcl_migrations:', DATABASE()))"
+	)
+
+	ctx := context.Background()
+	conn, err := c.writeClient.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	err = conn.GetContext(ctx, &acquired, lockQuery, migrationLockTimeout)
 	if err != nil {
 		return err
+	} else if !acquired.Valid || acquired.Int64 != 1 {
+		return ErrMigrationLock
 	}
+	defer conn.GetContext(ctx, &acquired, releaseQuery)
 
-	if len(migrations) > 0 {
-		sort.Strings(migrations)
+	return fn(ctx, conn)
+}
 
-		for _, migration := range migrations {
-			err = c.execMigration(migration)
-			if err != nil {
-				return fmt.Errorf("%s: %w", migration, err)
-			}
-		}
+func initMigrationTable(ctx context.Context, q migrationQuerier) error {
+	const query = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version				int				UNSIGNED NOT NULL PRIMARY KEY,
+		name				varchar(100)	NOT NULL,
+		checksum			char(64)		NOT NULL,
+		statements			int				UNSIGNED NOT NULL,
+		applied_statements	int				UNSIGNED NOT NULL DEFAULT 0,
+		dirty				bool			NOT NULL DEFAULT FALSE,
+		baseline			bool			NOT NULL DEFAULT FALSE,
+		error_message		text,
+		applied_at			datetime		NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	_, err := q.ExecContext(ctx, query)
+
+	return err
+}
+
+func insertMigrationRecords(ctx context.Context, q migrationQuerier, records []*MigrationRecord) error {
+	const query = `INSERT INTO schema_migrations
+	(version, name, checksum, statements, applied_statements, dirty, baseline)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-		c.updateMigrationStatus(migrations[len(migrations)-1])
+	for _, record := range records {
+		_, err := q.ExecContext(ctx, query, record.Version, record.Name, record.Checksum,
+			record.Statements, record.AppliedStatements, record.Dirty, record.Baseline)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (c *Client) DowngradeMigration() error {
-	current, err := c.migrationStatus()
+// baseline marks every migration up to version as applied without executing it and
+// drops the legacy table, since it would otherwise be picked up again once every
+// migration is downgraded.
+func baseline(ctx context.Context, conn *sqlx.Conn, files []*migrationFile, version int) error {
+	records, err := baselineRecords(files, version)
 	if err != nil {
 		return err
 	}
 
-	if len(current) > 0 {
-		down := strings.ReplaceAll(current, ".sql", ".down.sql")
-		err := c.execMigration(down)
+	err = insertMigrationRecords(ctx, conn, records)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, "DROP TABLE IF EXISTS migrations")
+
+	return err
+}
+
+// loadMigrationRecords creates the migration table and returns the applied records,
+// baselining from the legacy table the first time it runs on an existing database.
+func (c *Client) loadMigrationRecords(ctx context.Context, conn *sqlx.Conn) ([]*migrationFile, []*MigrationRecord, error) {
+	files, err := c.getMigrationFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = initMigrationTable(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, err := getMigrationRecords(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	} else if len(records) > 0 {
+		return files, records, nil
+	}
+
+	legacyVersion, err := getLegacyMigrationVersion(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	} else if legacyVersion < 0 {
+		return files, records, nil
+	}
+
+	err = baseline(ctx, conn, files, legacyVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("legacy baseline: %w", err)
+	}
+
+	records, err = getMigrationRecords(ctx, conn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, records, nil
+}
+
+func setMigrationError(ctx context.Context, q migrationQuerier, version int, migrationErr error) {
+	const query = `UPDATE schema_migrations
+	SET error_message = ?
+	WHERE version = ?`
+
+	q.ExecContext(ctx, query, migrationErr.Error(), version)
+}
+
+// applyMigration executes a step one statement at a time, recording the progress
+// after each statement. MySQL commits DDL implicitly, so a failure leaves the
+// migration dirty and the next run resumes from the failed statement. steps that
+// only modify rows run in a single transaction instead.
+func applyMigration(ctx context.Context, conn *sqlx.Conn, step *MigrationStep) error {
+	const (
+		markQuery = `INSERT INTO schema_migrations
+		(version, name, checksum, statements, applied_statements, dirty)
+		VALUES (?, ?, ?, ?, 0, TRUE)
+		ON DUPLICATE KEY UPDATE
+			name = VALUES(name),
+			checksum = VALUES(checksum),
+			statements = VALUES(statements),
+			dirty = TRUE,
+			error_message = NULL`
+		progressQuery = `UPDATE schema_migrations
+		SET applied_statements = ?
+		WHERE version = ?`
+		completeQuery = `UPDATE schema_migrations
+		SET applied_statements = statements, dirty = FALSE, applied_at = CURRENT_TIMESTAMP
+		WHERE version = ?`
+	)
+
+	_, err := conn.ExecContext(ctx, markQuery, step.Version, step.Name, step.Checksum, len(step.Statements))
+	if err != nil {
+		return err
+	}
+
+	if step.isDML() {
+		tx, err := conn.BeginTxx(ctx, nil)
 		if err != nil {
-			return fmt.Errorf("%s: %w", down, err)
+			return err
+		}
+		defer tx.Rollback()
+
+		for i, statement := range step.Pending() {
+			_, err = tx.ExecContext(ctx, statement.SQL())
+			if err != nil {
+				err = fmt.Errorf("statement %d: %w", step.Resume+i+1, err)
+				tx.Rollback()
+				setMigrationError(ctx, conn, step.Version, err)
+				return err
+			}
 		}
 
-		prev, err := c.getPrevMigration()
+		_, err = tx.ExecContext(ctx, completeQuery, step.Version)
 		if err != nil {
 			return err
 		}
 
-		c.updateMigrationStatus(prev)
+		return tx.Commit()
 	}
 
-	return nil
+	for i := step.Resume; i < len(step.Statements); i++ {
+		_, err = conn.ExecContext(ctx, step.Statements[i].SQL())
+		if err != nil {
+			err = fmt.Errorf("statement %d: %w", i+1, err)
+			setMigrationError(ctx, conn, step.Version, err)
+			return err
+		}
+
+		_, err = conn.ExecContext(ctx, progressQuery, i+1, step.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = conn.ExecContext(ctx, completeQuery, step.Version)
+
+	return err
 }
 
-func (c *Client) DowngradeMigrations() error {
-	for {
-		current, err := c.migrationStatus()
+// UpgradeMigrations applies every pending migration in order, resuming a dirty
+// migration from its first unapplied statement. it fails without executing
+// anything if an applied migration has been edited since it was applied.
+func (c *Client) UpgradeMigrations() error {
+	return c.withMigrationLock(func(ctx context.Context, conn *sqlx.Conn) error {
+		files, records, err := c.loadMigrationRecords(ctx, conn)
 		if err != nil {
 			return err
 		}
 
-		if len(current) == 0 {
-			break
+		steps, err := planMigrations(files, records)
+		if err != nil {
+			return err
 		}
 
-		err = c.DowngradeMigration()
+		for _, step := range steps {
+			err = applyMigration(ctx, conn, step)
+			if err != nil {
+				return fmt.Errorf("%s: %w", step.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Baseline marks every migration up to and including version as applied without
+// executing it, for databases whose schema was created some other way. it only
+// works on a database without any applied migrations.
+func (c *Client) Baseline(version int) error {
+	return c.withMigrationLock(func(ctx context.Context, conn *sqlx.Conn) error {
+		files, records, err := c.loadMigrationRecords(ctx, conn)
 		if err != nil {
 			return err
+		} else if len(records) > 0 {
+			return fmt.Errorf("migrations have already been applied")
 		}
-	}
 
-	return nil
+		var found bool
+		for _, file := range files {
+			if file.version == version {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("no migration with version %03d", version)
+		}
+
+		return baseline(ctx, conn, files, version)
+	})
 }
 
-func (c *Client) execMigration(path string) error {
-	text, ok := c.migrations[path]
-	if !ok {
-		return fmt.Errorf("invalid migration")
+// downgradeMigration reverts the highest applied migration and returns false
+// if there was nothing to revert. down migrations are not resumable, a failure
+// leaves the migration dirty with the error message for an operator to resolve.
+func (c *Client) downgradeMigration(ctx context.Context, conn *sqlx.Conn) (bool, error) {
+	const (
+		markQuery = `UPDATE schema_migrations
+		SET dirty = TRUE
+		WHERE version = ?`
+		deleteQuery = `DELETE FROM schema_migrations
+		WHERE version = ?`
+	)
+
+	files, records, err := c.loadMigrationRecords(ctx, conn)
+	if err != nil || len(records) == 0 {
+		return false, err
 	}
 
-	_, err := c.writeClient.Exec(string(text))
+	step, err := downgradeStep(files, records[len(records)-1])
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	_, err = conn.ExecContext(ctx, markQuery, step.Version)
+	if err != nil {
+		return false, err
+	}
+
+	for i, statement := range step.Statements {
+		_, err = conn.ExecContext(ctx, statement.SQL())
+		if err != nil {
+			err = fmt.Errorf("%s: statement %d: %w", step.Name, i+1, err)
+			setMigrationError(ctx, conn, step.Version, err)
+			return false, err
+		}
+	}
+
+	_, err = conn.ExecContext(ctx, deleteQuery, step.Version)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (c *Client) DowngradeMigration() error {
+	return c.withMigrationLock(func(ctx context.Context, conn *sqlx.Conn) error {
+		_, err := c.downgradeMigration(ctx, conn)
+		return err
+	})
+}
+
+func (c *Client) DowngradeMigrations() error {
+	return c.withMigrationLock(func(ctx context.Context, conn *sqlx.Conn) error {
+		for {
+			downgraded, err := c.downgradeMigration(ctx, conn)
+			if err != nil {
+				return err
+			} else if !downgraded {
+				return nil
+			}
+		}
+	})
 }
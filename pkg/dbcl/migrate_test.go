@@ -0,0 +1,165 @@
+package dbcl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetMigrationFiles(t *testing.T) {
+	client := &Client{
+		migrations: map[string]string{
+			"001_b.sql":      "CREATE TABLE b (id int);",
+			"001_b.down.sql": "DROP TABLE b;",
+			"000_a.sql":      "CREATE TABLE a (id int);",
+			"000_a.down.sql": "DROP TABLE a;",
+		},
+	}
+
+	files, err := client.getMigrationFiles()
+	if err != nil {
+		t.Fatalf("failed on get files: %v", err)
+	} else if len(files) != 2 {
+		t.Fatalf("file count mismatch: have %d, want %d", len(files), 2)
+	} else if files[0].name != "000_a" || files[1].name != "001_b" {
+		t.Errorf("file order mismatch: have %s, %s", files[0].name, files[1].name)
+	} else if files[1].down != "DROP TABLE b;" {
+		t.Errorf("down mismatch: have %s", files[1].down)
+	} else if files[0].checksum != checksumMigration("CREATE TABLE a (id int);") {
+		t.Errorf("checksum mismatch: have %s", files[0].checksum)
+	}
+
+	client.migrations["001_c.sql"] = "CREATE TABLE c (id int);"
+	_, err = client.getMigrationFiles()
+	if err == nil {
+		t.Errorf("expected duplicate version error")
+	}
+}
+
+func TestPlanMigrations(t *testing.T) {
+	files := []*migrationFile{
+		{version: 0, name: "000_a", up: "CREATE TABLE a (id int);"},
+		{version: 1, name: "001_b", up: "CREATE TABLE b (id int);\nCREATE TABLE c (id int);"},
+		{version: 2, name: "002_d", up: "-- +lock none\nALTER TABLE a ADD COLUMN d int;"},
+	}
+	for _, file := range files {
+		file.checksum = checksumMigration(file.up)
+	}
+
+	tests := []struct {
+		records  []*MigrationRecord
+		versions []int
+		resume   []int
+		err      error
+	}{
+		{
+			records:  nil,
+			versions: []int{0, 1, 2},
+			resume:   []int{0, 0, 0},
+		},
+		{
+			records: []*MigrationRecord{
+				{Version: 0, Name: "000_a", Checksum: files[0].checksum},
+			},
+			versions: []int{1, 2},
+			resume:   []int{0, 0},
+		},
+		{
+			records: []*MigrationRecord{
+				{Version: 0, Name: "000_a", Checksum: files[0].checksum},
+				{Version: 1, Name: "001_b", Checksum: "edited", Dirty: true, AppliedStatements: 1},
+			},
+			versions: []int{1, 2},
+			resume:   []int{1, 0},
+		},
+		{
+			records: []*MigrationRecord{
+				{Version: 0, Name: "000_a", Checksum: "edited"},
+			},
+			err: ErrMigrationChecksum,
+		},
+		{
+			records: []*MigrationRecord{
+				{Version: 0, Name: "000_a", Checksum: files[0].checksum},
+				{Version: 2, Name: "002_d", Checksum: files[2].checksum},
+			},
+			err: errors.New("gap"),
+		},
+		{
+			records: []*MigrationRecord{
+				{Version: 3, Name: "003_e", Checksum: "missing"},
+			},
+			err: errors.New("missing file"),
+		},
+		{
+			records: []*MigrationRecord{
+				{Version: 0, Name: "000_a", Checksum: files[0].checksum, Dirty: true, AppliedStatements: 2},
+			},
+			err: errors.New("too many applied statements"),
+		},
+	}
+
+	for i, tt := range tests {
+		steps, err := planMigrations(files, tt.records)
+		if tt.err != nil {
+			if err == nil {
+				t.Errorf("failed on %d: expected error", i)
+			} else if errors.Is(tt.err, ErrMigrationChecksum) && !errors.Is(err, ErrMigrationChecksum) {
+				t.Errorf("failed on %d: error mismatch: have %v, want %v", i, err, tt.err)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		} else if len(steps) != len(tt.versions) {
+			t.Errorf("failed on %d: step count mismatch: have %d, want %d", i, len(steps), len(tt.versions))
+			continue
+		}
+
+		for j, step := range steps {
+			if step.Version != tt.versions[j] {
+				t.Errorf("failed on %d: step %d: version mismatch: have %d, want %d", i, j, step.Version, tt.versions[j])
+			} else if step.Resume != tt.resume[j] {
+				t.Errorf("failed on %d: step %d: resume mismatch: have %d, want %d", i, j, step.Resume, tt.resume[j])
+			}
+		}
+	}
+}
+
+func TestBaselineRecords(t *testing.T) {
+	files := []*migrationFile{
+		{version: 0, name: "000_a", up: "CREATE TABLE a (id int);", checksum: "a"},
+		{version: 1, name: "001_b", up: "CREATE TABLE b (id int);\nCREATE TABLE c (id int);", checksum: "b"},
+		{version: 2, name: "002_d", up: "DROP TABLE c;", checksum: "d"},
+	}
+
+	tests := []struct {
+		version int
+		names   []string
+	}{
+		{version: -1, names: []string{}},
+		{version: 0, names: []string{"000_a"}},
+		{version: 1, names: []string{"000_a", "001_b"}},
+		{version: 5, names: []string{"000_a", "001_b", "002_d"}},
+	}
+
+	for i, tt := range tests {
+		records, err := baselineRecords(files, tt.version)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		} else if len(records) != len(tt.names) {
+			t.Errorf("failed on %d: record count mismatch: have %d, want %d", i, len(records), len(tt.names))
+			continue
+		}
+
+		for j, record := range records {
+			if record.Name != tt.names[j] {
+				t.Errorf("failed on %d: record %d: name mismatch: have %s, want %s", i, j, record.Name, tt.names[j])
+			} else if !record.Baseline || record.Dirty {
+				t.Errorf("failed on %d: record %d: state mismatch", i, j)
+			} else if record.AppliedStatements != record.Statements {
+				t.Errorf("failed on %d: record %d: statement mismatch", i, j)
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package dbcl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	markerExpr      = regexp.MustCompile(`^--\s*\+(\w+)\s*(\w*)\s*$`)
+	alterTableExpr  = regexp.MustCompile(`(?i)^ALTER\s+TABLE\s`)
+	createIndexExpr = regexp.MustCompile(`(?i)^CREATE\s+(UNIQUE\s+|FULLTEXT\s+|SPATIAL\s+)?INDEX\s`)
+	dropIndexExpr   = regexp.MustCompile(`(?i)^DROP\s+INDEX\s`)
+	dmlExpr         = regexp.MustCompile(`(?i)^(INSERT|UPDATE|DELETE|REPLACE)\s`)
+
+	lockValues = map[string]bool{
+		"none": true, "shared": true, "exclusive": true, "default": true,
+	}
+	algorithmValues = map[string]bool{
+		"instant": true, "inplace": true, "copy": true, "default": true,
+	}
+)
+
+// Statement is a single statement of a migration file. statements can be preceded
+// by markers (comment lines starting with "-- +") to run DDL with an explicit
+// lock and algorithm, so that MySQL fails the statement instead of silently
+// falling back to a table copy or a blocking lock:
+//
+//	-- +lock none
+//	-- +algorithm inplace
+//	ALTER TABLE rounds ADD COLUMN held bool NOT NULL DEFAULT FALSE;
+//
+// is executed as "ALTER TABLE ... , ALGORITHM=INPLACE, LOCK=NONE".
+type Statement struct {
+	Query     string
+	Lock      string
+	Algorithm string
+}
+
+// SQL returns the statement as it is executed, including the lock and algorithm clauses.
+func (s *Statement) SQL() string {
+	if len(s.Lock) == 0 && len(s.Algorithm) == 0 {
+		return s.Query
+	}
+
+	clauses := make([]string, 0)
+	if len(s.Algorithm) > 0 {
+		clauses = append(clauses, "ALGORITHM="+strings.ToUpper(s.Algorithm))
+	}
+	if len(s.Lock) > 0 {
+		clauses = append(clauses, "LOCK="+strings.ToUpper(s.Lock))
+	}
+
+	// ALTER TABLE takes the clauses as comma separated alter options,
+	// CREATE INDEX and DROP INDEX take them space separated
+	if alterTableExpr.MatchString(s.Query) {
+		return s.Query + ", " + strings.Join(clauses, ", ")
+	}
+
+	return s.Query + " " + strings.Join(clauses, " ")
+}
+
+func (s *Statement) isDML() bool {
+	return dmlExpr.MatchString(s.Query)
+}
+
+func (s *Statement) setMarker(name, value string) error {
+	value = strings.ToLower(value)
+	switch name {
+	case "lock":
+		if !lockValues[value] {
+			return fmt.Errorf("invalid lock %s", value)
+		}
+		s.Lock = value
+	case "algorithm":
+		if !algorithmValues[value] {
+			return fmt.Errorf("invalid algorithm %s", value)
+		}
+		s.Algorithm = value
+	default:
+		return fmt.Errorf("unknown marker +%s", name)
+	}
+
+	return nil
+}
+
+// SplitStatements splits a migration file into its statements, ignoring
+// semicolons inside of quotes and comments.
+func SplitStatements(text string) ([]*Statement, error) {
+	statements := make([]*Statement, 0)
+	current := new(Statement)
+	var buf strings.Builder
+
+	flush := func() error {
+		query := strings.TrimSpace(buf.String())
+		buf.Reset()
+		if len(query) == 0 {
+			if len(current.Lock) > 0 || len(current.Algorithm) > 0 {
+				return fmt.Errorf("marker without a statement")
+			}
+			return nil
+		}
+
+		current.Query = query
+		if len(current.Lock) > 0 || len(current.Algorithm) > 0 {
+			if !alterTableExpr.MatchString(query) && !createIndexExpr.MatchString(query) &&
+				!dropIndexExpr.MatchString(query) {
+				return fmt.Errorf("markers are only supported for ALTER TABLE and CREATE/DROP INDEX: %s", query)
+			}
+		}
+
+		statements = append(statements, current)
+		current = new(Statement)
+
+		return nil
+	}
+
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			buf.WriteByte(c)
+			if c == '\\' && quote != '`' && i+1 < len(text) {
+				i++
+				buf.WriteByte(text[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			buf.WriteByte(c)
+		case c == '-' && strings.HasPrefix(text[i:], "--"), c == '#':
+			end := strings.IndexByte(text[i:], '\n')
+			if end == -1 {
+				end = len(text) - i
+			}
+
+			line := strings.TrimSpace(text[i : i+end])
+			if matches := markerExpr.FindStringSubmatch(line); len(matches) == 3 {
+				if len(strings.TrimSpace(buf.String())) > 0 {
+					return nil, fmt.Errorf("marker inside of a statement: %s", line)
+				} else if err := current.setMarker(matches[1], matches[2]); err != nil {
+					return nil, err
+				}
+			}
+			i += end - 1
+		case c == '/' && strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 3
+		case c == ';':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	} else if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return statements, nil
+}
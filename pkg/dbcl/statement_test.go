@@ -0,0 +1,114 @@
+package dbcl
+
+import (
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		text       string
+		statements []string
+	}{
+		{
+			text:       "CREATE TABLE a (id int);\n\nDROP TABLE b;",
+			statements: []string{"CREATE TABLE a (id int)", "DROP TABLE b"},
+		},
+		{
+			text:       "INSERT INTO a VALUES ('x;y', \"z;\", `c;`);",
+			statements: []string{"INSERT INTO a VALUES ('x;y', \"z;\", `c;`)"},
+		},
+		{
+			text:       "INSERT INTO a VALUES ('it\\'s;', 'it''s;');",
+			statements: []string{"INSERT INTO a VALUES ('it\\'s;', 'it''s;')"},
+		},
+		{
+			text:       "-- drop; everything\nDROP TABLE a; # also; this\nDROP TABLE b; /* and; this */",
+			statements: []string{"DROP TABLE a", "DROP TABLE b"},
+		},
+		{
+			text:       "UPDATE a SET b = 1 - -1;",
+			statements: []string{"UPDATE a SET b = 1 - -1"},
+		},
+		{
+			text:       "\n-- nothing here\n;;",
+			statements: []string{},
+		},
+	}
+
+	for i, tt := range tests {
+		statements, err := SplitStatements(tt.text)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		} else if len(statements) != len(tt.statements) {
+			t.Errorf("failed on %d: statement count mismatch: have %d, want %d", i, len(statements), len(tt.statements))
+			continue
+		}
+
+		for j, statement := range statements {
+			if statement.Query != tt.statements[j] {
+				t.Errorf("failed on %d: statement %d: have %s, want %s", i, j, statement.Query, tt.statements[j])
+			}
+		}
+	}
+}
+
+func TestSplitStatementsMarkers(t *testing.T) {
+	tests := []struct {
+		text       string
+		statements []string
+	}{
+		{
+			text:       "-- +lock none\nALTER TABLE a ADD COLUMN b int;",
+			statements: []string{"ALTER TABLE a ADD COLUMN b int, LOCK=NONE"},
+		},
+		{
+			text:       "-- +algorithm INSTANT\n-- +lock default\nALTER TABLE a\n\tADD COLUMN b int;",
+			statements: []string{"ALTER TABLE a\n\tADD COLUMN b int, ALGORITHM=INSTANT, LOCK=DEFAULT"},
+		},
+		{
+			text:       "-- +lock none\nCREATE UNIQUE INDEX idx_a_b ON a (b);\nDROP TABLE c;",
+			statements: []string{"CREATE UNIQUE INDEX idx_a_b ON a (b) LOCK=NONE", "DROP TABLE c"},
+		},
+		{
+			text:       "-- +algorithm inplace\n-- +lock shared\nDROP INDEX idx_a_b ON a;",
+			statements: []string{"DROP INDEX idx_a_b ON a ALGORITHM=INPLACE LOCK=SHARED"},
+		},
+	}
+
+	for i, tt := range tests {
+		statements, err := SplitStatements(tt.text)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		} else if len(statements) != len(tt.statements) {
+			t.Errorf("failed on %d: statement count mismatch: have %d, want %d", i, len(statements), len(tt.statements))
+			continue
+		}
+
+		for j, statement := range statements {
+			if statement.SQL() != tt.statements[j] {
+				t.Errorf("failed on %d: statement %d: have %s, want %s", i, j, statement.SQL(), tt.statements[j])
+			}
+		}
+	}
+}
+
+func TestSplitStatementsErrors(t *testing.T) {
+	tests := []string{
+		"INSERT INTO a VALUES ('b);",
+		"DROP TABLE a; /* unterminated",
+		"-- +lock none\nDROP TABLE a;",
+		"-- +lock always\nALTER TABLE a ADD COLUMN b int;",
+		"-- +timeout 10\nALTER TABLE a ADD COLUMN b int;",
+		"ALTER TABLE a\n-- +lock none\nADD COLUMN b int;",
+		"DROP TABLE a;\n-- +lock none\n",
+	}
+
+	for i, tt := range tests {
+		_, err := SplitStatements(tt)
+		if err == nil {
+			t.Errorf("failed on %d: expected error", i)
+		}
+	}
+}
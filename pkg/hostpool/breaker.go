@@ -0,0 +1,267 @@
+package hostpool
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a host's circuit breaker.
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerOptions specifies when a host's circuit breaker opens. ErrorThreshold is the
+// number of consecutive errors and SpikeThreshold is the number of consecutive latency spikes
+// (a response slower than SpikeFactor times the host's average latency for the same method,
+// and slower than MinSpikeLatency). Once open, a single probe request is allowed through after Cooldown,
+// doubling on every failed probe up to MaxCooldown.
+type BreakerOptions struct {
+	ErrorThreshold  int
+	SpikeThreshold  int
+	SpikeFactor     float64
+	MinSpikeLatency time.Duration
+	Cooldown        time.Duration
+	MaxCooldown     time.Duration
+}
+
+func (opts *BreakerOptions) setDefaults() {
+	if opts.ErrorThreshold == 0 {
+		opts.ErrorThreshold = 3
+	}
+	if opts.SpikeThreshold == 0 {
+		opts.SpikeThreshold = 3
+	}
+	if opts.SpikeFactor == 0 {
+		opts.SpikeFactor = 5
+	}
+	if opts.MinSpikeLatency == 0 {
+		opts.MinSpikeLatency = time.Second
+	}
+	if opts.Cooldown == 0 {
+		opts.Cooldown = time.Second * 5
+	}
+	if opts.MaxCooldown == 0 {
+		opts.MaxCooldown = time.Minute
+	}
+}
+
+// weight of the latest response in the average latency
+const breakerLatencyAlpha = 0.2
+
+// maximum number of methods to track the average latency of, past
+// which the latency of new methods is no longer checked for spikes
+const breakerMaxMethods = 256
+
+// circuitBreaker passively tracks the outcome of every request to a single host. the host
+// is removed from rotation once it opens, and is only returned to rotation after a successful
+// probe (either a regular request while half-open, or the periodic health check). the
+// average latency is tracked per method, since methods differ widely in latency (e.g.
+// fetching a block template versus a full block) and would otherwise look like spikes.
+type circuitBreaker struct {
+	mu         sync.Mutex
+	opts       BreakerOptions
+	state      BreakerState
+	errors     uint
+	spikes     uint
+	avgLatency map[string]time.Duration
+	cooldown   time.Duration
+	openedAt   time.Time
+	probing    bool
+	probedAt   time.Time
+}
+
+func newCircuitBreaker(opts BreakerOptions) *circuitBreaker {
+	opts.setDefaults()
+	b := &circuitBreaker{
+		opts:       opts,
+		state:      BreakerClosed,
+		avgLatency: make(map[string]time.Duration),
+		cooldown:   opts.Cooldown,
+	}
+
+	return b
+}
+
+func (b *circuitBreaker) setOptions(opts BreakerOptions) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	opts.setDefaults()
+	b.opts = opts
+	b.cooldown = opts.Cooldown
+}
+
+// open the breaker, doubling the cooldown if it was already half-open (a failed probe)
+func (b *circuitBreaker) open(now time.Time) {
+	if b.state == BreakerHalfOpen {
+		b.cooldown = time.Duration(math.Min(float64(b.cooldown*2), float64(b.opts.MaxCooldown)))
+	}
+
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.probing = false
+}
+
+func (b *circuitBreaker) close() {
+	b.state = BreakerClosed
+	b.errors = 0
+	b.spikes = 0
+	b.cooldown = b.opts.Cooldown
+	b.probing = false
+}
+
+// checks if a probe can be sent, without reserving it
+func (b *circuitBreaker) canProbe(now time.Time) bool {
+	switch b.state {
+	case BreakerOpen:
+		return now.Sub(b.openedAt) >= b.cooldown
+	case BreakerHalfOpen:
+		// a probe that never reported back shouldn't block the host forever
+		return !b.probing || now.Sub(b.probedAt) >= b.cooldown
+	default:
+		return true
+	}
+}
+
+// available returns true if a request could be sent to the host,
+// without reserving the half-open probe.
+func (b *circuitBreaker) available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.canProbe(time.Now())
+}
+
+// acquire returns true if a request can be sent to the host. if the breaker is
+// open (and cooled down) or half-open, the request is reserved as the only probe.
+func (b *circuitBreaker) acquire() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.state == BreakerClosed {
+		return true
+	} else if !b.canProbe(now) {
+		return false
+	}
+
+	b.state = BreakerHalfOpen
+	b.probing = true
+	b.probedAt = now
+
+	return true
+}
+
+// recordSuccess records a successful request, checking the latency
+// against the average latency of the method for spikes.
+func (b *circuitBreaker) recordSuccess(method string, latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.errors = 0
+	avgLatency, ok := b.avgLatency[method]
+	spike := avgLatency > 0 && latency > b.opts.MinSpikeLatency &&
+		float64(latency) > float64(avgLatency)*b.opts.SpikeFactor
+	if !spike {
+		// spikes are kept out of the average to keep the baseline from drifting
+		if avgLatency == 0 {
+			avgLatency = latency
+		} else {
+			avgLatency = time.Duration(breakerLatencyAlpha*float64(latency) +
+				(1-breakerLatencyAlpha)*float64(avgLatency))
+		}
+
+		if ok || len(b.avgLatency) < breakerMaxMethods {
+			b.avgLatency[method] = avgLatency
+		}
+
+		b.spikes = 0
+		if b.state == BreakerHalfOpen {
+			b.close()
+		}
+
+		return
+	}
+
+	b.spikes++
+	if b.state == BreakerHalfOpen || b.spikes >= uint(b.opts.SpikeThreshold) {
+		b.open(time.Now())
+	}
+}
+
+// recordFailure records a failed request.
+func (b *circuitBreaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.errors++
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.errors >= uint(b.opts.ErrorThreshold)) {
+		b.open(time.Now())
+	}
+}
+
+// recordHealthCheck resets the error count after a successful health check. an open
+// breaker is moved to half-open, since the health check isn't necessarily representative
+// of the requests that opened it.
+func (b *circuitBreaker) recordHealthCheck() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.errors = 0
+	if b.state == BreakerOpen {
+		b.state = BreakerHalfOpen
+		b.probing = false
+	}
+}
+
+func (b *circuitBreaker) getState() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+func (b *circuitBreaker) getErrors() uint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.errors
+}
+
+func (b *circuitBreaker) info() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	latency := make(map[string]float64, len(b.avgLatency))
+	for method, avgLatency := range b.avgLatency {
+		latency[method] = float64(avgLatency.Microseconds()) / 1000
+	}
+
+	info := map[string]interface{}{
+		"state":    b.state.String(),
+		"errors":   b.errors,
+		"spikes":   b.spikes,
+		"latency":  latency,
+		"cooldown": b.cooldown.Seconds(),
+	}
+
+	return info
+}
@@ -0,0 +1,210 @@
+package hostpool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/magicpool-co/pool/pkg/stratum/rpc"
+)
+
+func TestCircuitBreakerErrors(t *testing.T) {
+	b := newCircuitBreaker(BreakerOptions{
+		ErrorThreshold: 3,
+		Cooldown:       time.Millisecond * 20,
+		MaxCooldown:    time.Millisecond * 30,
+	})
+
+	for i := 0; i < 2; i++ {
+		b.recordFailure()
+		if state := b.getState(); state != BreakerClosed {
+			t.Fatalf("failed on %d: state mismatch: have %s, want %s", i, state, BreakerClosed)
+		}
+	}
+
+	b.recordFailure()
+	if state := b.getState(); state != BreakerOpen {
+		t.Fatalf("state mismatch: have %s, want %s", state, BreakerOpen)
+	} else if b.available() || b.acquire() {
+		t.Fatalf("open breaker should not be available")
+	}
+
+	// only a single probe is allowed through once cooled down
+	time.Sleep(time.Millisecond * 25)
+	if !b.acquire() {
+		t.Fatalf("cooled down breaker should allow a probe")
+	} else if state := b.getState(); state != BreakerHalfOpen {
+		t.Fatalf("state mismatch: have %s, want %s", state, BreakerHalfOpen)
+	} else if b.acquire() {
+		t.Fatalf("half-open breaker should only allow a single probe")
+	}
+
+	// a failed probe reopens the breaker with a longer (capped) cooldown
+	b.recordFailure()
+	if state := b.getState(); state != BreakerOpen {
+		t.Fatalf("state mismatch: have %s, want %s", state, BreakerOpen)
+	} else if b.cooldown != time.Millisecond*30 {
+		t.Fatalf("cooldown mismatch: have %s, want %s", b.cooldown, time.Millisecond*30)
+	}
+
+	time.Sleep(time.Millisecond * 35)
+	if !b.acquire() {
+		t.Fatalf("cooled down breaker should allow a probe")
+	}
+
+	b.recordSuccess("getblock", time.Millisecond)
+	if state := b.getState(); state != BreakerClosed {
+		t.Fatalf("state mismatch: have %s, want %s", state, BreakerClosed)
+	} else if b.cooldown != time.Millisecond*20 {
+		t.Fatalf("cooldown mismatch: have %s, want %s", b.cooldown, time.Millisecond*20)
+	}
+}
+
+func TestCircuitBreakerSpikes(t *testing.T) {
+	b := newCircuitBreaker(BreakerOptions{
+		SpikeThreshold:  2,
+		SpikeFactor:     5,
+		MinSpikeLatency: time.Millisecond * 100,
+		Cooldown:        time.Minute,
+	})
+
+	b.recordSuccess("getblock", time.Millisecond*50)
+	b.recordSuccess("getblock", time.Millisecond*50)
+
+	// slow, but under the minimum spike latency
+	b.recordSuccess("getblock", time.Millisecond*90)
+	if b.spikes != 0 {
+		t.Fatalf("spikes mismatch: have %d, want %d", b.spikes, 0)
+	}
+
+	b.recordSuccess("getblock", time.Second)
+	if state := b.getState(); state != BreakerClosed {
+		t.Fatalf("state mismatch: have %s, want %s", state, BreakerClosed)
+	}
+
+	b.recordSuccess("getblock", time.Second)
+	if state := b.getState(); state != BreakerOpen {
+		t.Fatalf("state mismatch: have %s, want %s", state, BreakerOpen)
+	}
+
+	// a successful health check moves the breaker to half-open
+	b.recordHealthCheck()
+	if state := b.getState(); state != BreakerHalfOpen {
+		t.Fatalf("state mismatch: have %s, want %s", state, BreakerHalfOpen)
+	} else if !b.acquire() {
+		t.Fatalf("half-open breaker should allow a probe")
+	}
+
+	b.recordSuccess("getblock", time.Millisecond*60)
+	if state := b.getState(); state != BreakerClosed {
+		t.Fatalf("state mismatch: have %s, want %s", state, BreakerClosed)
+	}
+
+	// slow methods are compared against their own average, not the host's
+	b.recordSuccess("getblocktemplate", time.Second)
+	b.recordSuccess("getblocktemplate", time.Second)
+	if b.spikes != 0 {
+		t.Fatalf("spikes mismatch: have %d, want %d", b.spikes, 0)
+	} else if state := b.getState(); state != BreakerClosed {
+		t.Fatalf("state mismatch: have %s, want %s", state, BreakerClosed)
+	}
+}
+
+func TestRequestPolicy(t *testing.T) {
+	policy := newRequestPolicy(&RequestPolicy{
+		MethodTimeouts: map[string]time.Duration{
+			"getblocktemplate": time.Second * 5,
+			"submitblock":      time.Second * 30,
+			"/mining/solution": time.Second * 10,
+		},
+	}, httpTimeout)
+
+	tests := []struct {
+		httpMethod string
+		path       string
+		body       interface{}
+		method     string
+		timeout    time.Duration
+		idempotent bool
+	}{
+		{
+			httpMethod: "POST",
+			body:       &rpc.Request{Method: "getblocktemplate"},
+			method:     "getblocktemplate",
+			timeout:    time.Second * 5,
+			idempotent: true,
+		},
+		{
+			httpMethod: "POST",
+			body:       &rpc.Request{Method: "submitblock"},
+			method:     "submitblock",
+			timeout:    time.Second * 30,
+			idempotent: false,
+		},
+		{
+			httpMethod: "POST",
+			body:       []*rpc.Request{{Method: "getblock"}, {Method: "getblocktemplate"}},
+			method:     "getblock",
+			timeout:    httpTimeout,
+			idempotent: true,
+		},
+		{
+			httpMethod: "POST",
+			body:       []*rpc.Request{{Method: "getblock"}, {Method: "sendrawtransaction"}},
+			method:     "getblock",
+			timeout:    httpTimeout,
+			idempotent: false,
+		},
+		{
+			httpMethod: "GET",
+			path:       "/info",
+			method:     "/info",
+			timeout:    httpTimeout,
+			idempotent: true,
+		},
+		{
+			httpMethod: "GET",
+			path:       "/blocks/6f2a9b3c8d1e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a",
+			method:     "/blocks/{id}",
+			timeout:    httpTimeout,
+			idempotent: true,
+		},
+		{
+			httpMethod: "GET",
+			path:       "/blocks/at/1024",
+			method:     "/blocks/at/{id}",
+			timeout:    httpTimeout,
+			idempotent: true,
+		},
+		{
+			httpMethod: "GET",
+			path:       "/wallet/transactionById?id=6f2a9b3c",
+			method:     "/wallet/transactionById?id={id}",
+			timeout:    httpTimeout,
+			idempotent: true,
+		},
+		{
+			httpMethod: "POST",
+			path:       "/mining/solution",
+			method:     "/mining/solution",
+			timeout:    time.Second * 10,
+			idempotent: false,
+		},
+	}
+
+	for i, tt := range tests {
+		method, timeout, idempotent := policy.getHTTPMethod(tt.httpMethod, tt.path, tt.body)
+		if method != tt.method {
+			t.Errorf("failed on %d: method mismatch: have %s, want %s", i, method, tt.method)
+		} else if timeout != tt.timeout {
+			t.Errorf("failed on %d: timeout mismatch: have %s, want %s", i, timeout, tt.timeout)
+		} else if idempotent != tt.idempotent {
+			t.Errorf("failed on %d: idempotent mismatch: have %t, want %t", i, idempotent, tt.idempotent)
+		}
+	}
+
+	if retries := policy.retries(true); retries != 2 {
+		t.Errorf("retries mismatch: have %d, want %d", retries, 2)
+	} else if retries := policy.retries(false); retries != 0 {
+		t.Errorf("retries mismatch: have %d, want %d", retries, 0)
+	}
+}
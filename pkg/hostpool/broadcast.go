@@ -0,0 +1,179 @@
+package hostpool
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/log"
+)
+
+// BroadcastResult is the outcome of a broadcast request to a single host.
+type BroadcastResult struct {
+	HostID  string
+	Latency time.Duration
+	Err     error
+}
+
+// BroadcastHandler is called once every host in a broadcast has responded
+// (or failed), even if the broadcast itself already returned a winner.
+type BroadcastHandler func([]*BroadcastResult)
+
+type broadcastFunc func(hostID string) (interface{}, error)
+
+type broadcastStat struct {
+	count   uint64
+	errors  uint64
+	latency time.Duration
+}
+
+// broadcastStats keeps track of the broadcast results for every host, along
+// with the (optional) handler for every completed broadcast.
+type broadcastStats struct {
+	mu      sync.RWMutex
+	index   map[string]*broadcastStat
+	handler BroadcastHandler
+}
+
+func newBroadcastStats() *broadcastStats {
+	stats := &broadcastStats{
+		index: make(map[string]*broadcastStat),
+	}
+
+	return stats
+}
+
+func (s *broadcastStats) setHandler(handler BroadcastHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handler = handler
+}
+
+func (s *broadcastStats) record(results []*BroadcastResult) {
+	s.mu.Lock()
+	handler := s.handler
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+
+		stat, ok := s.index[result.HostID]
+		if !ok {
+			stat = new(broadcastStat)
+			s.index[result.HostID] = stat
+		}
+
+		stat.count++
+		stat.latency = result.Latency
+		if result.Err != nil {
+			stat.errors++
+		}
+	}
+	s.mu.Unlock()
+
+	if handler != nil {
+		handler(results)
+	}
+}
+
+func (s *broadcastStats) info(hostID string) map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stat, ok := s.index[hostID]
+	if !ok {
+		stat = new(broadcastStat)
+	}
+
+	info := map[string]interface{}{
+		"count":   stat.count,
+		"errors":  stat.errors,
+		"latency": float64(stat.latency.Microseconds()) / 1000,
+	}
+
+	return info
+}
+
+// broadcast executes fn against every host in parallel. the first successful response
+// is returned as soon as it is available, otherwise the last error is returned once every
+// host has failed. the results for all hosts are recorded after every host has finished.
+func broadcast(
+	hostIDs []string,
+	fn broadcastFunc,
+	stats *broadcastStats,
+	logger *log.Logger,
+) (interface{}, string, error) {
+	if len(hostIDs) == 0 {
+		return nil, "", ErrNoHealthyHosts
+	}
+
+	type response struct {
+		hostID string
+		res    interface{}
+		err    error
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*BroadcastResult, len(hostIDs))
+	responseCh := make(chan *response, len(hostIDs))
+	for i, hostID := range hostIDs {
+		wg.Add(1)
+		go func(i int, hostID string) {
+			defer logger.RecoverPanic()
+			defer wg.Done()
+
+			start := time.Now()
+			res, err := fn(hostID)
+			results[i] = &BroadcastResult{
+				HostID:  hostID,
+				Latency: time.Since(start),
+				Err:     err,
+			}
+
+			responseCh <- &response{hostID: hostID, res: res, err: err}
+		}(i, hostID)
+	}
+
+	go func() {
+		defer logger.RecoverPanic()
+
+		wg.Wait()
+		close(responseCh)
+		stats.record(results)
+	}()
+
+	var lastErr error
+	for res := range responseCh {
+		if res.err == nil {
+			return res.res, res.hostID, nil
+		}
+
+		lastErr = fmt.Errorf("%s: %v", res.hostID, res.err)
+		logger.Error(fmt.Errorf("hostpool: broadcast: %v", lastErr))
+	}
+
+	return nil, "", lastErr
+}
+
+// relay executes fn against every host in parallel without waiting for any of the
+// responses, the results are only recorded.
+func relay(
+	hostIDs []string,
+	fn broadcastFunc,
+	stats *broadcastStats,
+	logger *log.Logger,
+) {
+	if len(hostIDs) == 0 {
+		return
+	}
+
+	go func() {
+		defer logger.RecoverPanic()
+
+		_, _, err := broadcast(hostIDs, fn, stats, logger)
+		if err != nil {
+			logger.Error(fmt.Errorf("hostpool: relay: %v", err))
+		}
+	}()
+}
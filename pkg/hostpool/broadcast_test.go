@@ -0,0 +1,81 @@
+package hostpool
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/log"
+)
+
+func TestBroadcast(t *testing.T) {
+	logger, err := log.New(map[string]string{"LOG_LEVEL": "ERROR"}, "hostpooltest", nil)
+	if err != nil {
+		t.Fatalf("failed to init logger: %v", err)
+	}
+
+	tests := []struct {
+		hostIDs []string
+		delays  map[string]time.Duration
+		errors  map[string]error
+		winner  string
+		valid   bool
+	}{
+		{
+			hostIDs: []string{"a", "b", "c"},
+			delays:  map[string]time.Duration{"a": time.Millisecond * 200, "b": 0, "c": time.Millisecond * 100},
+			winner:  "b",
+			valid:   true,
+		},
+		{
+			hostIDs: []string{"a", "b"},
+			delays:  map[string]time.Duration{"a": time.Millisecond * 100},
+			errors:  map[string]error{"b": fmt.Errorf("rejected")},
+			winner:  "a",
+			valid:   true,
+		},
+		{
+			hostIDs: []string{"a", "b"},
+			errors:  map[string]error{"a": fmt.Errorf("rejected"), "b": fmt.Errorf("rejected")},
+			valid:   false,
+		},
+		{
+			hostIDs: []string{},
+			valid:   false,
+		},
+	}
+
+	for i, tt := range tests {
+		fn := func(hostID string) (interface{}, error) {
+			time.Sleep(tt.delays[hostID])
+			if err := tt.errors[hostID]; err != nil {
+				return nil, err
+			}
+
+			return hostID, nil
+		}
+
+		resultsCh := make(chan []*BroadcastResult, 1)
+		stats := newBroadcastStats()
+		stats.setHandler(func(results []*BroadcastResult) { resultsCh <- results })
+
+		res, winner, err := broadcast(tt.hostIDs, fn, stats, logger)
+		if tt.valid != (err == nil) {
+			t.Errorf("failed on %d: validity mismatch: have %v, want %v", i, err, tt.valid)
+			continue
+		} else if !tt.valid {
+			continue
+		} else if winner != tt.winner || res.(string) != tt.winner {
+			t.Errorf("failed on %d: winner mismatch: have %s, want %s", i, winner, tt.winner)
+		}
+
+		select {
+		case results := <-resultsCh:
+			if len(results) != len(tt.hostIDs) {
+				t.Errorf("failed on %d: result length mismatch: have %d, want %d", i, len(results), len(tt.hostIDs))
+			}
+		case <-time.After(time.Second):
+			t.Errorf("failed on %d: results never recorded", i)
+		}
+	}
+}
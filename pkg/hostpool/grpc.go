@@ -27,6 +27,20 @@ type GRPCClient interface {
 
 type GRPCClientFactory func(string, time.Duration) (GRPCClient, error)
 
+// GRPCMethodRequest is optionally implemented by GRPC requests, the method
+// is used for timeouts, idempotency and metrics.
+type GRPCMethodRequest interface {
+	Method() string
+}
+
+func getGRPCMethod(req interface{}) string {
+	if methodReq, ok := req.(GRPCMethodRequest); ok {
+		return methodReq.Method()
+	}
+
+	return "unknown"
+}
+
 // HTTPPool represents a pool of HTTP hosts with methods to make standard HTTP calls.
 type GRPCPool struct {
 	ctx         context.Context
@@ -34,6 +48,9 @@ type GRPCPool struct {
 	index       map[string]*grpcConn
 	order       []string
 	latencyIdx  map[string]int
+	broadcasts  *broadcastStats
+	requests    *requestStats
+	policy      *RequestPolicy
 	factory     GRPCClientFactory
 	healthCheck *GRPCHealthCheck
 	tunnel      *sshtunnel.SSHTunnel
@@ -75,6 +92,9 @@ func NewGRPCPool(
 		ctx:         ctx,
 		index:       make(map[string]*grpcConn),
 		order:       make([]string, 0),
+		broadcasts:  newBroadcastStats(),
+		requests:    new(requestStats),
+		policy:      newRequestPolicy(nil, 0),
 		factory:     factory,
 		healthCheck: healthCheck,
 		tunnel:      tunnel,
@@ -122,7 +142,7 @@ func (p *GRPCPool) GetAllHosts() []string {
 
 // Adds a host to the pool. If the host already exists, nothing happens.
 func (p *GRPCPool) AddHost(url string, port int) error {
-	finalURL, id, err := parseURL(url, port, p.tunnel)
+	finalURL, id, tunnel, err := parseURL(url, port, p.tunnel)
 	if err != nil {
 		return err
 	}
@@ -141,6 +161,8 @@ func (p *GRPCPool) AddHost(url string, port int) error {
 		p.order = append(p.order, id)
 		p.index[id] = &grpcConn{
 			id:      id,
+			breaker: newCircuitBreaker(p.policy.Breaker),
+			tunnel:  tunnel,
 			enabled: true,
 			synced:  true,
 			client:  client,
@@ -181,30 +203,67 @@ func (p *GRPCPool) SetHostSyncStatus(id string, synced bool) {
 	}
 }
 
-// Executes a HTTP call to a specific host. If the host is not healthy,
+// Sets the request policy (timeouts, retries and circuit breakers) for the pool. Without
+// a timeout (or method timeout), the GRPC client's own timeout is used.
+func (p *GRPCPool) SetRequestPolicy(policy *RequestPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.policy = newRequestPolicy(policy, 0)
+	for _, gc := range p.index {
+		gc.breaker.setOptions(p.policy.Breaker)
+	}
+}
+
+// Sets the handler called with the result of every request to an individual host.
+func (p *GRPCPool) SetRequestHandler(handler RequestHandler) {
+	p.requests.setHandler(handler)
+}
+
+func (p *GRPCPool) getPolicy() *RequestPolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.policy
+}
+
+// Executes a GRPC call to a specific host. If the host is not healthy,
 // ErrNoHealthyHosts is returned. If the host is healthy, the error is returned.
 func (p *GRPCPool) exec(
 	hostID string,
 	req interface{},
 	needsSynced bool,
 ) (interface{}, string, error) {
-	// iterate through all host connections until no healthy connections
-	// are left or a valid response is returned
+	policy := p.getPolicy()
+	method := getGRPCMethod(req)
+	timeout := policy.timeout(method)
+	retries := policy.retries(policy.idempotent(method))
+
+	// iterate through the host connections (skipping any that were already attempted)
+	// until no healthy connections are left, the retries are exhausted or a valid
+	// response is returned
 	var res interface{}
 	var err error
 	var failed bool
 	var count int
+	attempted := make(map[string]bool)
 	for {
 		count++
-		gc := p.getConn(hostID, count, needsSynced)
+		gc := p.getConn(hostID, count, needsSynced, attempted)
 		if gc == nil {
 			failed = true
 			break
 		}
+		attempted[gc.id] = true
 
-		res, hostID, err = gc.exec(req)
+		start := time.Now()
+		res, hostID, err = gc.exec(req, timeout)
+		p.requests.record(gc.id, method, time.Since(start), err, gc.breaker)
 		if err != nil {
-			p.logger.Error(fmt.Errorf("grpcpool: grpc: %v", err))
+			p.logger.Error(fmt.Errorf("grpcpool: grpc: %s: %v", method, err))
+			if count > retries {
+				break
+			}
 			continue
 		}
 
@@ -234,14 +293,65 @@ func (p *GRPCPool) ExecSynced(req interface{}) (interface{}, string, error) {
 	return p.exec("", req, true)
 }
 
-// pop the fastest healthy connection
-func (p *GRPCPool) getConn(hostID string, count int, needsSynced bool) *grpcConn {
+// Sets the handler called with the results of every broadcast.
+func (p *GRPCPool) SetBroadcastHandler(handler BroadcastHandler) {
+	p.broadcasts.setHandler(handler)
+}
+
+// Executes a GRPC call against every synced host (and hostID, if set) in parallel.
+// The first response that passes validation is returned as soon as it is available,
+// while the remaining responses are only recorded.
+func (p *GRPCPool) ExecBroadcast(
+	hostID string,
+	req interface{},
+	validate func(interface{}) error,
+) (interface{}, string, error) {
+	p.mu.RLock()
+	hostIDs := make([]string, 0)
+	if gc, ok := p.index[hostID]; ok && gc.healthy() {
+		hostIDs = append(hostIDs, hostID)
+	}
+	for _, id := range p.order {
+		if id != hostID && p.index[id].usable(true) {
+			hostIDs = append(hostIDs, id)
+		}
+	}
+	p.mu.RUnlock()
+
+	fn := func(hostID string) (interface{}, error) {
+		p.mu.RLock()
+		gc, ok := p.index[hostID]
+		p.mu.RUnlock()
+		if !ok {
+			return nil, ErrNoHealthyHosts
+		}
+
+		method := getGRPCMethod(req)
+		start := time.Now()
+		res, _, err := gc.exec(req, p.getPolicy().timeout(method))
+		p.requests.record(hostID, method, time.Since(start), err, gc.breaker)
+		if err != nil {
+			return nil, err
+		} else if validate != nil {
+			if err := validate(res); err != nil {
+				return nil, err
+			}
+		}
+
+		return res, nil
+	}
+
+	return broadcast(hostIDs, fn, p.broadcasts, p.logger)
+}
+
+// pop the fastest healthy connection that hasn't been attempted yet
+func (p *GRPCPool) getConn(hostID string, count int, needsSynced bool, attempted map[string]bool) *grpcConn {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
 	if hostID != "" && hostID != onceHostID {
 		gc, ok := p.index[hostID]
-		if ok && gc.healthy() {
+		if ok && !attempted[hostID] && gc.healthy() && gc.breaker.acquire() {
 			return gc
 		}
 
@@ -252,7 +362,7 @@ func (p *GRPCPool) getConn(hostID string, count int, needsSynced bool) *grpcConn
 
 	for _, id := range p.order {
 		gc := p.index[id]
-		if gc.usable(needsSynced) {
+		if !attempted[id] && gc.usable(needsSynced) && gc.breaker.acquire() {
 			return gc
 		}
 	}
@@ -293,19 +403,21 @@ func (p *GRPCPool) HandleInfoRequest(w http.ResponseWriter, r *http.Request) {
 	for i, id := range p.order {
 		gc := p.index[id]
 		gc.mu.Lock()
-		url, errCount, synced := gc.client.URL(), gc.errors, gc.synced
+		url, synced := gc.client.URL(), gc.synced
 		gc.mu.Unlock()
 
 		latency := float64(p.latencyIdx[id]) / float64(time.Millisecond)
 		latency = math.Round(latency*100) / 100
 
 		hosts[i] = map[string]interface{}{
-			"id":      id,
-			"url":     url,
-			"index":   i,
-			"synced":  synced,
-			"latency": latency,
-			"errors":  errCount,
+			"id":        id,
+			"url":       url,
+			"index":     i,
+			"synced":    synced,
+			"latency":   latency,
+			"errors":    gc.breaker.getErrors(),
+			"breaker":   gc.breaker.info(),
+			"broadcast": p.broadcasts.info(id),
 		}
 	}
 
@@ -6,12 +6,14 @@ import (
 	"time"
 
 	"github.com/magicpool-co/pool/internal/log"
+	"github.com/magicpool-co/pool/pkg/sshtunnel"
 )
 
 type grpcConn struct {
 	id      string
 	mu      sync.RWMutex
-	errors  uint
+	breaker *circuitBreaker
+	tunnel  *sshtunnel.SSHTunnel
 	enabled bool
 	synced  bool
 
@@ -19,17 +21,14 @@ type grpcConn struct {
 }
 
 func (gc *grpcConn) healthy() bool {
-	gc.mu.RLock()
-	defer gc.mu.RUnlock()
-
-	return gc.errors < 3
+	return gc.breaker.available()
 }
 
 func (gc *grpcConn) usable(needsSynced bool) bool {
 	gc.mu.RLock()
 	defer gc.mu.RUnlock()
 
-	healthy := gc.enabled && gc.errors < 3
+	healthy := gc.enabled && gc.breaker.available()
 	if needsSynced {
 		return healthy && gc.synced
 	}
@@ -42,9 +41,15 @@ func (gc *grpcConn) healthCheck(healthCheck *GRPCHealthCheck, logger *log.Logger
 	var unit = time.Nanosecond
 	var maxLatency = int(healthCheck.Timeout/unit) * 2
 
+	if err := checkTunnel(gc.tunnel); err != nil {
+		gc.markHealthy(false)
+		logger.Error(fmt.Errorf("grpcconn: healthcheck: %s: %v", gc.id, err))
+		return maxLatency
+	}
+
 	start := time.Now()
 
-	_, _, err := gc.exec(healthCheck.Request)
+	_, _, err := gc.exec(healthCheck.Request, healthCheck.Timeout)
 	if err != nil {
 		logger.Error(fmt.Errorf("grpcconn: healthcheck: %s: %v", gc.id, err))
 		return maxLatency
@@ -62,13 +67,10 @@ func (gc *grpcConn) markHealthy(healthy bool) {
 		gc.client.Reconnect()
 	}
 
-	gc.mu.Lock()
-	defer gc.mu.Unlock()
-
 	if healthy {
-		gc.errors = 0
+		gc.breaker.recordHealthCheck()
 	} else {
-		gc.errors++
+		gc.breaker.recordFailure()
 	}
 }
 
@@ -80,13 +82,43 @@ func (gc *grpcConn) markSynced(synced bool) {
 	gc.synced = synced
 }
 
-// Execute a request.
-func (gc *grpcConn) exec(req interface{}) (interface{}, string, error) {
-	res, err := gc.client.Send(req)
+// Execute a request. If timeout is non-zero, the request fails once the timeout
+// is reached, regardless of the client's own timeout.
+func (gc *grpcConn) exec(req interface{}, timeout time.Duration) (interface{}, string, error) {
+	start := time.Now()
+	res, err := gc.send(req, timeout)
 	if err != nil {
 		gc.markHealthy(false)
 		return nil, gc.id, err
 	}
+	gc.breaker.recordSuccess(getGRPCMethod(req), time.Since(start))
 
 	return res, gc.id, nil
 }
+
+func (gc *grpcConn) send(req interface{}, timeout time.Duration) (interface{}, error) {
+	if timeout == 0 {
+		return gc.client.Send(req)
+	}
+
+	type response struct {
+		res interface{}
+		err error
+	}
+
+	responseCh := make(chan *response, 1)
+	go func() {
+		res, err := gc.client.Send(req)
+		responseCh <- &response{res: res, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil, ErrRequestTimedOut
+	case r := <-responseCh:
+		return r.res, r.err
+	}
+}
@@ -23,7 +23,8 @@ const (
 )
 
 var (
-	ErrNoHealthyHosts = fmt.Errorf("no healthy hosts")
+	ErrNoHealthyHosts  = fmt.Errorf("no healthy hosts")
+	ErrRequestTimedOut = fmt.Errorf("request timed out")
 )
 
 // HTTPError is a convenient custom error type for HTTP errors.
@@ -49,6 +50,9 @@ type HTTPPool struct {
 	index       map[string]*httpConn
 	order       []string
 	latencyIdx  map[string]int
+	broadcasts  *broadcastStats
+	requests    *requestStats
+	policy      *RequestPolicy
 	healthCheck *HTTPHealthCheck
 	tunnel      *sshtunnel.SSHTunnel
 	logger      *log.Logger
@@ -91,6 +95,9 @@ func NewHTTPPool(
 		ctx:         ctx,
 		index:       make(map[string]*httpConn),
 		order:       make([]string, 0),
+		broadcasts:  newBroadcastStats(),
+		requests:    new(requestStats),
+		policy:      newRequestPolicy(nil, httpTimeout),
 		healthCheck: healthCheck,
 		tunnel:      tunnel,
 		logger:      logger,
@@ -139,7 +146,7 @@ func (p *HTTPPool) GetAllHosts() []string {
 
 // Adds a host to the pool. If the host already exists, nothing happens.
 func (p *HTTPPool) AddHost(url string, port int, opt *HTTPHostOptions) error {
-	finalURL, id, err := parseURL(url, port, p.tunnel)
+	finalURL, id, tunnel, err := parseURL(url, port, p.tunnel)
 	if err != nil {
 		return err
 	}
@@ -170,6 +177,8 @@ func (p *HTTPPool) AddHost(url string, port int, opt *HTTPHostOptions) error {
 		p.order = append(p.order, id)
 		p.index[id] = &httpConn{
 			id:      id,
+			breaker: newCircuitBreaker(p.policy.Breaker),
+			tunnel:  tunnel,
 			enabled: true,
 			synced:  true,
 			client:  new(http.Client),
@@ -212,6 +221,29 @@ func (p *HTTPPool) SetHostSyncStatus(id string, synced bool) {
 	}
 }
 
+// Sets the request policy (timeouts, retries and circuit breakers) for the pool.
+func (p *HTTPPool) SetRequestPolicy(policy *RequestPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.policy = newRequestPolicy(policy, httpTimeout)
+	for _, hc := range p.index {
+		hc.breaker.setOptions(p.policy.Breaker)
+	}
+}
+
+// Sets the handler called with the result of every request to an individual host.
+func (p *HTTPPool) SetRequestHandler(handler RequestHandler) {
+	p.requests.setHandler(handler)
+}
+
+func (p *HTTPPool) getPolicy() *RequestPolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.policy
+}
+
 // Executes a HTTP call to a specific host. If the host is not healthy,
 // ErrNoHealthyHosts is returned. If the host is healthy, the error is returned.
 func (p *HTTPPool) execHTTP(
@@ -219,33 +251,46 @@ func (p *HTTPPool) execHTTP(
 	body, target interface{},
 	needsSynced bool,
 ) (string, error) {
-	// iterate through all host connections until no healthy connections
-	// are left or a valid response is returned
+	policy := p.getPolicy()
+	name, timeout, idempotent := policy.getHTTPMethod(method, path, body)
+	retries := policy.retries(idempotent)
+
+	// iterate through the host connections (skipping any that were already attempted)
+	// until no healthy connections are left, the retries are exhausted or a valid
+	// response is returned
 	var res []byte
 	var err error
 	var failed bool
 	var count int
+	attempted := make(map[string]bool)
 	for {
 		count++
-		hc := p.getConn(hostID, count, needsSynced)
+		hc := p.getConn(hostID, count, needsSynced, attempted)
 		if hc == nil {
 			failed = true
 			break
 		}
+		attempted[hc.id] = true
 
 		// enforce a request timeout
-		ctx, cancelFunc := context.WithTimeout(context.Background(), httpTimeout)
-		defer cancelFunc()
-
-		res, hostID, err = hc.exec(ctx, method, path, body)
-		if err != nil {
-			p.logger.Error(fmt.Errorf("httppool: http: %v", err))
-			continue
+		ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+		start := time.Now()
+		res, hostID, err = hc.exec(ctx, name, method, path, body)
+		cancelFunc()
+		if err == nil {
+			err = json.Unmarshal(res, target)
+			if err != nil {
+				err = fmt.Errorf("json: %s: %v: %s", hostID, err, res)
+				hostID = ""
+			}
 		}
+		p.requests.record(hc.id, name, time.Since(start), err, hc.breaker)
 
-		err = json.Unmarshal(res, target)
 		if err != nil {
-			p.logger.Error(fmt.Errorf("httppool: json: %s: %v: %s", hostID, err, res))
+			p.logger.Error(fmt.Errorf("httppool: http: %s: %v", name, err))
+			if count > retries {
+				break
+			}
 			continue
 		}
 
@@ -373,14 +418,155 @@ func (p *HTTPPool) ExecRPCBulk(reqs []*rpc.Request) ([]*rpc.Response, error) {
 	return responses, nil
 }
 
-// pop the fastest healthy connection
-func (p *HTTPPool) getConn(hostID string, count int, needsSynced bool) *httpConn {
+// Sets the handler called with the results of every broadcast or relay.
+func (p *HTTPPool) SetBroadcastHandler(handler BroadcastHandler) {
+	p.broadcasts.setHandler(handler)
+}
+
+// returns every usable host (plus the given host, if it is healthy at all),
+// with the given host always placed first
+func (p *HTTPPool) getBroadcastHosts(hostID, excludeID string) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	hostIDs := make([]string, 0)
+	if hc, ok := p.index[hostID]; ok && hostID != excludeID && hc.healthy() {
+		hostIDs = append(hostIDs, hostID)
+	}
+
+	for _, id := range p.order {
+		if id != hostID && id != excludeID && p.index[id].usable(true) {
+			hostIDs = append(hostIDs, id)
+		}
+	}
+
+	return hostIDs
+}
+
+// execute an RPC call against a single host, without any retries
+func (p *HTTPPool) execRPCOnce(hostID string, req *rpc.Request, validate func(*rpc.Response) error) (interface{}, error) {
+	p.mu.RLock()
+	hc, ok := p.index[hostID]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, ErrNoHealthyHosts
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), p.getPolicy().timeout(req.Method))
+	defer cancelFunc()
+
+	start := time.Now()
+	data, _, err := hc.exec(ctx, req.Method, "POST", "", req)
+	p.requests.record(hostID, req.Method, time.Since(start), err, hc.breaker)
+	if err != nil {
+		return nil, err
+	}
+
+	var res rpc.Response
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	} else if res.Error != nil {
+		return nil, HTTPError{
+			Status:     res.Error.Message,
+			StatusCode: res.Error.Code,
+			Body:       []byte(res.Error.Data),
+		}
+	}
+	res.HostID = hostID
+
+	if validate != nil {
+		if err := validate(&res); err != nil {
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
+
+// Executes an RPC call against every synced host (and req.HostID, if set) in parallel.
+// The first response that passes validation is returned as soon as it is available,
+// while the remaining responses are only recorded. This is meant for block submissions,
+// where a single slow or partitioned host shouldn't be able to orphan a block.
+func (p *HTTPPool) ExecRPCBroadcast(
+	req *rpc.Request,
+	validate func(*rpc.Response) error,
+) (*rpc.Response, error) {
+	if len(req.JSONRPC) == 0 {
+		req.JSONRPC = "2.0"
+	}
+
+	hostIDs := p.getBroadcastHosts(req.HostID, "")
+	fn := func(hostID string) (interface{}, error) {
+		return p.execRPCOnce(hostID, req, validate)
+	}
+
+	res, _, err := broadcast(hostIDs, fn, p.broadcasts, p.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*rpc.Response), nil
+}
+
+// Relays an RPC call to every synced host other than excludeID in the background.
+// This is meant for chains where the block has to be submitted to the host that
+// created the template, after which the raw block can be relayed to the others.
+func (p *HTTPPool) RelayRPC(
+	req *rpc.Request,
+	excludeID string,
+	validate func(*rpc.Response) error,
+) {
+	if len(req.JSONRPC) == 0 {
+		req.JSONRPC = "2.0"
+	}
+
+	hostIDs := p.getBroadcastHosts("", excludeID)
+	fn := func(hostID string) (interface{}, error) {
+		return p.execRPCOnce(hostID, req, validate)
+	}
+
+	relay(hostIDs, fn, p.broadcasts, p.logger)
+}
+
+// execute an HTTP call against a single host, without any retries
+func (p *HTTPPool) execHTTPOnce(hostID, method, path string, body interface{}) (interface{}, error) {
+	p.mu.RLock()
+	hc, ok := p.index[hostID]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, ErrNoHealthyHosts
+	}
+
+	name, timeout, _ := p.getPolicy().getHTTPMethod(method, path, body)
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	start := time.Now()
+	data, _, err := hc.exec(ctx, name, method, path, body)
+	p.requests.record(hostID, name, time.Since(start), err, hc.breaker)
+
+	return data, err
+}
+
+// Relays an HTTP call to every synced host other than excludeID in the background,
+// the same way as RelayRPC. The responses are only recorded.
+func (p *HTTPPool) RelayHTTP(method, path string, body interface{}, excludeID string) {
+	hostIDs := p.getBroadcastHosts("", excludeID)
+	fn := func(hostID string) (interface{}, error) {
+		return p.execHTTPOnce(hostID, method, path, body)
+	}
+
+	relay(hostIDs, fn, p.broadcasts, p.logger)
+}
+
+// pop the fastest healthy connection that hasn't been attempted yet
+func (p *HTTPPool) getConn(hostID string, count int, needsSynced bool, attempted map[string]bool) *httpConn {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
 	if hostID != "" && hostID != onceHostID {
 		hc, ok := p.index[hostID]
-		if ok && hc.healthy() {
+		if ok && !attempted[hostID] && hc.healthy() && hc.breaker.acquire() {
 			return hc
 		}
 
@@ -391,7 +577,7 @@ func (p *HTTPPool) getConn(hostID string, count int, needsSynced bool) *httpConn
 
 	for _, id := range p.order {
 		hc := p.index[id]
-		if hc.usable(needsSynced) {
+		if !attempted[id] && hc.usable(needsSynced) && hc.breaker.acquire() {
 			return hc
 		}
 	}
@@ -432,19 +618,21 @@ func (p *HTTPPool) HandleInfoRequest(w http.ResponseWriter, r *http.Request) {
 	for i, id := range p.order {
 		hc := p.index[id]
 		hc.mu.Lock()
-		url, errCount, synced := hc.url, hc.errors, hc.synced
+		url, synced := hc.url, hc.synced
 		hc.mu.Unlock()
 
 		latency := float64(p.latencyIdx[id]) / float64(time.Millisecond)
 		latency = math.Round(latency*100) / 100
 
 		hosts[i] = map[string]interface{}{
-			"id":      id,
-			"url":     url,
-			"index":   i,
-			"synced":  synced,
-			"latency": latency,
-			"errors":  errCount,
+			"id":        id,
+			"url":       url,
+			"index":     i,
+			"synced":    synced,
+			"latency":   latency,
+			"errors":    hc.breaker.getErrors(),
+			"breaker":   hc.breaker.info(),
+			"broadcast": p.broadcasts.info(id),
 		}
 	}
 
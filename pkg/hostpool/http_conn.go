@@ -13,13 +13,15 @@ import (
 	"github.com/goccy/go-json"
 
 	"github.com/magicpool-co/pool/internal/log"
+	"github.com/magicpool-co/pool/pkg/sshtunnel"
 )
 
 // An internal representation of an individual http connection.
 type httpConn struct {
 	id      string
 	mu      sync.RWMutex
-	errors  uint
+	breaker *circuitBreaker
+	tunnel  *sshtunnel.SSHTunnel
 	enabled bool
 	synced  bool
 
@@ -29,17 +31,14 @@ type httpConn struct {
 }
 
 func (hc *httpConn) healthy() bool {
-	hc.mu.RLock()
-	defer hc.mu.RUnlock()
-
-	return hc.errors < 3
+	return hc.breaker.available()
 }
 
 func (hc *httpConn) usable(needsSynced bool) bool {
 	hc.mu.RLock()
 	defer hc.mu.RUnlock()
 
-	healthy := hc.enabled && hc.errors < 3
+	healthy := hc.enabled && hc.breaker.available()
 	if needsSynced {
 		return healthy && hc.synced
 	}
@@ -56,15 +55,22 @@ func (hc *httpConn) healthCheck(
 	var unit = time.Nanosecond
 	var maxLatency = int(healthCheck.Timeout/unit) * 2
 
+	if err := checkTunnel(hc.tunnel); err != nil {
+		hc.markHealthy(false)
+		logger.Error(fmt.Errorf("httpconn: healthcheck: %s: %v", hc.id, err))
+		return maxLatency
+	}
+
 	start := time.Now()
 	ctx, cancelFunc := context.WithTimeout(context.Background(), healthCheck.Timeout)
 	defer cancelFunc()
 
 	var err error
 	if len(healthCheck.HTTPMethod) > 0 {
-		_, _, err = hc.exec(ctx, healthCheck.HTTPMethod, healthCheck.HTTPPath, healthCheck.HTTPBody)
+		name := normalizeHTTPPath(healthCheck.HTTPPath)
+		_, _, err = hc.exec(ctx, name, healthCheck.HTTPMethod, healthCheck.HTTPPath, healthCheck.HTTPBody)
 	} else if healthCheck.RPCRequest != nil {
-		_, _, err = hc.exec(ctx, "POST", "", healthCheck.RPCRequest)
+		_, _, err = hc.exec(ctx, healthCheck.RPCRequest.Method, "POST", "", healthCheck.RPCRequest)
 	} else {
 		return maxLatency
 	}
@@ -82,13 +88,10 @@ func (hc *httpConn) healthCheck(
 
 // Change a host's healthiness.
 func (hc *httpConn) markHealthy(healthy bool) {
-	hc.mu.Lock()
-	defer hc.mu.Unlock()
-
 	if healthy {
-		hc.errors = 0
+		hc.breaker.recordHealthCheck()
 	} else {
-		hc.errors++
+		hc.breaker.recordFailure()
 	}
 }
 
@@ -101,10 +104,11 @@ func (hc *httpConn) markSynced(synced bool) {
 }
 
 // Base call to execute an HTTP call. If the request succeeeds, but the status code
-// is non-2xx and not 300, a HTTPError is returned.
+// is non-2xx and not 300, a HTTPError is returned. name is the method name used
+// to track the latency (see getHTTPMethod).
 func (hc *httpConn) exec(
 	ctx context.Context,
-	method, path string,
+	name, method, path string,
 	msg interface{},
 ) ([]byte, string, error) {
 	body, err := json.Marshal(msg)
@@ -123,6 +127,7 @@ func (hc *httpConn) exec(
 	req.Header = hc.headers.Clone()
 	hc.mu.Unlock()
 
+	start := time.Now()
 	res, err := hc.client.Do(req)
 	if err != nil {
 		hc.markHealthy(false)
@@ -146,6 +151,12 @@ func (hc *httpConn) exec(
 
 	defer res.Body.Close()
 	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		hc.markHealthy(false)
+
+		return nil, "", err
+	}
+	hc.breaker.recordSuccess(name, time.Since(start))
 
-	return data, hc.id, err
+	return data, hc.id, nil
 }
@@ -0,0 +1,211 @@
+package hostpool
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magicpool-co/pool/pkg/stratum/rpc"
+)
+
+// methods that are never retried on another host, since the call can't
+// be safely repeated (or is already broadcast to every host)
+var defaultNonIdempotentMethods = []string{
+	"submitblock",
+	"submitminingsolution",
+	"sendrawtransaction",
+	"eth_submitWork",
+	"eth_sendRawTransaction",
+	"cfx_sendRawTransaction",
+	"mining.submit",
+	"SubmitBlock",
+	"SubmitTransaction",
+}
+
+// RequestPolicy specifies how requests are executed across the pool. Timeout is the default
+// request timeout, MethodTimeouts overrides it per RPC method (or per path for plain HTTP calls).
+// Idempotent calls that fail are retried on the next-best host up to MaxRetries times (a negative
+// value disables retries), calls in NonIdempotentMethods are never retried. Plain HTTP calls are
+// only retried for GET and HEAD requests.
+type RequestPolicy struct {
+	Timeout              time.Duration
+	MethodTimeouts       map[string]time.Duration
+	MaxRetries           int
+	NonIdempotentMethods []string
+	Breaker              BreakerOptions
+}
+
+func newRequestPolicy(policy *RequestPolicy, timeout time.Duration) *RequestPolicy {
+	final := &RequestPolicy{
+		Timeout:        timeout,
+		MethodTimeouts: make(map[string]time.Duration),
+		MaxRetries:     2,
+	}
+
+	if policy != nil {
+		if policy.Timeout > 0 {
+			final.Timeout = policy.Timeout
+		}
+		if policy.MaxRetries != 0 {
+			final.MaxRetries = policy.MaxRetries
+		}
+		for method, timeout := range policy.MethodTimeouts {
+			final.MethodTimeouts[method] = timeout
+		}
+		final.NonIdempotentMethods = append(final.NonIdempotentMethods, policy.NonIdempotentMethods...)
+		final.Breaker = policy.Breaker
+	}
+
+	final.NonIdempotentMethods = append(final.NonIdempotentMethods, defaultNonIdempotentMethods...)
+	final.Breaker.setDefaults()
+
+	return final
+}
+
+func (p *RequestPolicy) timeout(method string) time.Duration {
+	if timeout, ok := p.MethodTimeouts[method]; ok && timeout > 0 {
+		return timeout
+	}
+
+	return p.Timeout
+}
+
+func (p *RequestPolicy) idempotent(method string) bool {
+	for _, nonIdempotent := range p.NonIdempotentMethods {
+		if method == nonIdempotent {
+			return false
+		}
+	}
+
+	return true
+}
+
+// retries returns the number of hosts the request can be
+// retried on after the first attempt fails.
+func (p *RequestPolicy) retries(idempotent bool) int {
+	if !idempotent || p.MaxRetries < 0 {
+		return 0
+	}
+
+	return p.MaxRetries
+}
+
+// segments at least this long are treated as an ID (a hash, address or script)
+const minPathIDLength = 32
+
+func isPathID(segment string) bool {
+	if len(segment) >= minPathIDLength {
+		return true
+	}
+
+	for _, c := range segment {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return len(segment) > 0
+}
+
+// normalizeHTTPPath returns the route template of a path, replacing every segment that
+// is a number or an ID with {id} and every query value with {key}, so that calls to the
+// same route share a name (e.g. /blocks/{id} and /wallet/transactionById?id={id}).
+func normalizeHTTPPath(path string) string {
+	path, query, hasQuery := strings.Cut(path, "?")
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isPathID(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	path = strings.Join(segments, "/")
+
+	if hasQuery {
+		params := strings.Split(query, "&")
+		for i, param := range params {
+			key, _, _ := strings.Cut(param, "=")
+			params[i] = key + "={" + key + "}"
+		}
+		path += "?" + strings.Join(params, "&")
+	}
+
+	return path
+}
+
+// getHTTPMethod returns the name used for timeouts, idempotency and metrics for an HTTP call,
+// along with whether or not it is idempotent. RPC calls use the RPC method, plain HTTP calls
+// use the route template of the path. Bulk RPC calls use the slowest method and are only
+// idempotent if every method is.
+func (p *RequestPolicy) getHTTPMethod(httpMethod, path string, body interface{}) (string, time.Duration, bool) {
+	switch req := body.(type) {
+	case *rpc.Request:
+		return req.Method, p.timeout(req.Method), p.idempotent(req.Method)
+	case []*rpc.Request:
+		var method string
+		var timeout time.Duration
+		idempotent := true
+		for _, r := range req {
+			if t := p.timeout(r.Method); t > timeout {
+				method, timeout = r.Method, t
+			}
+			idempotent = idempotent && p.idempotent(r.Method)
+		}
+
+		if len(method) == 0 {
+			method, timeout = "bulk", p.Timeout
+		}
+
+		return method, timeout, idempotent
+	}
+
+	method := normalizeHTTPPath(path)
+	if len(method) == 0 {
+		method = httpMethod
+	}
+	idempotent := (httpMethod == "GET" || httpMethod == "HEAD") && p.idempotent(method)
+
+	return method, p.timeout(method), idempotent
+}
+
+// RequestResult is the outcome of a single request to a single host.
+type RequestResult struct {
+	HostID  string
+	Method  string
+	Latency time.Duration
+	Err     error
+	State   BreakerState
+}
+
+// RequestHandler is called after every request to an individual host (including
+// retries and broadcasts), meant to feed per-host metrics.
+type RequestHandler func(*RequestResult)
+
+type requestStats struct {
+	mu      sync.RWMutex
+	handler RequestHandler
+}
+
+func (s *requestStats) setHandler(handler RequestHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handler = handler
+}
+
+func (s *requestStats) record(hostID, method string, latency time.Duration, err error, breaker *circuitBreaker) {
+	s.mu.RLock()
+	handler := s.handler
+	s.mu.RUnlock()
+
+	if handler == nil {
+		return
+	}
+
+	handler(&RequestResult{
+		HostID:  hostID,
+		Method:  method,
+		Latency: latency,
+		Err:     err,
+		State:   breaker.getState(),
+	})
+}
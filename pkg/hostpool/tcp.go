@@ -35,6 +35,9 @@ type TCPPool struct {
 	order       []string
 	counts      map[string]int
 	latencyIdx  map[string]int
+	broadcasts  *broadcastStats
+	requests    *requestStats
+	policy      *RequestPolicy
 	healthCheck *TCPHealthCheck
 	tunnel      *sshtunnel.SSHTunnel
 	logger      *log.Logger
@@ -62,6 +65,9 @@ func NewTCPPool(
 		index:       make(map[string]*tcpConn),
 		order:       make([]string, 0),
 		counts:      make(map[string]int),
+		broadcasts:  newBroadcastStats(),
+		requests:    new(requestStats),
+		policy:      newRequestPolicy(nil, tcpTimeout),
 		healthCheck: healthCheck,
 		tunnel:      tunnel,
 		logger:      logger,
@@ -93,7 +99,7 @@ func NewTCPPool(
 
 // Adds a host to the pool. If the host already exists, nothing happens.
 func (p *TCPPool) AddHost(url string, port int) error {
-	finalURL, id, err := parseURL(url, port, p.tunnel)
+	finalURL, id, tunnel, err := parseURL(url, port, p.tunnel)
 	if err != nil {
 		return err
 	}
@@ -111,6 +117,8 @@ func (p *TCPPool) AddHost(url string, port int) error {
 		p.index[id] = &tcpConn{
 			id:      id,
 			ctx:     ctx,
+			breaker: newCircuitBreaker(p.policy.Breaker),
+			tunnel:  tunnel,
 			enabled: true,
 			synced:  true,
 			client:  stratum.NewClient(ctx, finalURL, time.Second*10, time.Second*30),
@@ -192,20 +200,56 @@ func (p *TCPPool) Subscribe(method string) chan *rpc.Request {
 	return p.reqChs[method]
 }
 
+// Sets the request policy (timeouts, retries and circuit breakers) for the pool.
+func (p *TCPPool) SetRequestPolicy(policy *RequestPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.policy = newRequestPolicy(policy, tcpTimeout)
+	for _, tc := range p.index {
+		tc.breaker.setOptions(p.policy.Breaker)
+	}
+}
+
+// Sets the handler called with the result of every request to an individual host.
+func (p *TCPPool) SetRequestHandler(handler RequestHandler) {
+	p.requests.setHandler(handler)
+}
+
+func (p *TCPPool) getPolicy() *RequestPolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.policy
+}
+
 func (p *TCPPool) exec(req *rpc.Request, needsSynced bool) (*rpc.Response, error) {
+	policy := p.getPolicy()
+	timeout := policy.timeout(req.Method)
+	retries := policy.retries(policy.idempotent(req.Method))
+
 	var res *rpc.Response
 	var err error
 	var failed bool
+	var count int
+	attempted := make(map[string]bool)
 	for {
-		tc := p.getConn(req.HostID, needsSynced)
+		count++
+		tc := p.getConn(req.HostID, needsSynced, attempted)
 		if tc == nil {
 			failed = true
 			break
 		}
+		attempted[tc.id] = true
 
-		res, err = tc.client.WriteRequestWithTimeout(req, tcpTimeout)
+		start := time.Now()
+		res, err = tc.exec(req, timeout)
+		p.requests.record(tc.id, req.Method, time.Since(start), err, tc.breaker)
 		if err != nil {
-			tc.markHealthy(false)
+			p.logger.Error(fmt.Errorf("tcppool: tcp: %s: %v", req.Method, err))
+			if count > retries {
+				break
+			}
 			continue
 		}
 
@@ -227,14 +271,68 @@ func (p *TCPPool) ExecSynced(req *rpc.Request) (*rpc.Response, error) {
 	return p.exec(req, true)
 }
 
-// pop the fastest healthy connection
-func (p *TCPPool) getConn(hostID string, needsSynced bool) *tcpConn {
+// Sets the handler called with the results of every broadcast.
+func (p *TCPPool) SetBroadcastHandler(handler BroadcastHandler) {
+	p.broadcasts.setHandler(handler)
+}
+
+// Executes a request against every synced host (and req.HostID, if set) in parallel.
+// The first response that passes validation is returned as soon as it is available,
+// while the remaining responses are only recorded.
+func (p *TCPPool) ExecBroadcast(
+	req *rpc.Request,
+	validate func(*rpc.Response) error,
+) (*rpc.Response, error) {
+	p.mu.RLock()
+	hostIDs := make([]string, 0)
+	if tc, ok := p.index[req.HostID]; ok && tc.healthy() {
+		hostIDs = append(hostIDs, req.HostID)
+	}
+	for _, id := range p.order {
+		if id != req.HostID && p.index[id].usable(true) {
+			hostIDs = append(hostIDs, id)
+		}
+	}
+	p.mu.RUnlock()
+
+	fn := func(hostID string) (interface{}, error) {
+		p.mu.RLock()
+		tc, ok := p.index[hostID]
+		p.mu.RUnlock()
+		if !ok {
+			return nil, ErrNoHealthyHosts
+		}
+
+		start := time.Now()
+		res, err := tc.exec(req, p.getPolicy().timeout(req.Method))
+		p.requests.record(hostID, req.Method, time.Since(start), err, tc.breaker)
+		if err != nil {
+			return nil, err
+		} else if validate != nil {
+			if err := validate(res); err != nil {
+				return nil, err
+			}
+		}
+
+		return res, nil
+	}
+
+	res, _, err := broadcast(hostIDs, fn, p.broadcasts, p.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(*rpc.Response), nil
+}
+
+// pop the fastest healthy connection that hasn't been attempted yet
+func (p *TCPPool) getConn(hostID string, needsSynced bool, attempted map[string]bool) *tcpConn {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
 	if hostID != "" {
 		tc, ok := p.index[hostID]
-		if ok && tc.healthy() {
+		if ok && !attempted[hostID] && tc.healthy() && tc.breaker.acquire() {
 			return tc
 		}
 
@@ -243,7 +341,7 @@ func (p *TCPPool) getConn(hostID string, needsSynced bool) *tcpConn {
 
 	for _, id := range p.order {
 		tc := p.index[id]
-		if tc.usable(needsSynced) {
+		if !attempted[id] && tc.usable(needsSynced) && tc.breaker.acquire() {
 			return tc
 		}
 	}
@@ -285,19 +383,21 @@ func (p *TCPPool) HandleInfoRequest(w http.ResponseWriter, r *http.Request) {
 	for i, id := range p.order {
 		tc := p.index[id]
 		tc.mu.Lock()
-		url, errCount, synced := tc.client.URL(), tc.errors, tc.synced
+		url, synced := tc.client.URL(), tc.synced
 		tc.mu.Unlock()
 
 		latency := float64(p.latencyIdx[id]) / float64(time.Millisecond)
 		latency = math.Round(latency*100) / 100
 
 		hosts[i] = map[string]interface{}{
-			"id":      id,
-			"url":     url,
-			"index":   i,
-			"synced":  synced,
-			"latency": latency,
-			"errors":  errCount,
+			"id":        id,
+			"url":       url,
+			"index":     i,
+			"synced":    synced,
+			"latency":   latency,
+			"errors":    tc.breaker.getErrors(),
+			"breaker":   tc.breaker.info(),
+			"broadcast": p.broadcasts.info(id),
 		}
 	}
 
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/magicpool-co/pool/internal/log"
+	"github.com/magicpool-co/pool/pkg/sshtunnel"
 	"github.com/magicpool-co/pool/pkg/stratum"
 	"github.com/magicpool-co/pool/pkg/stratum/rpc"
 )
@@ -15,7 +16,8 @@ type tcpConn struct {
 	id      string
 	ctx     context.Context
 	mu      sync.RWMutex
-	errors  uint
+	breaker *circuitBreaker
+	tunnel  *sshtunnel.SSHTunnel
 	enabled bool
 	synced  bool
 
@@ -23,17 +25,14 @@ type tcpConn struct {
 }
 
 func (tc *tcpConn) healthy() bool {
-	tc.mu.RLock()
-	defer tc.mu.RUnlock()
-
-	return tc.errors < 3
+	return tc.breaker.available()
 }
 
 func (tc *tcpConn) usable(needsSynced bool) bool {
 	tc.mu.RLock()
 	defer tc.mu.RUnlock()
 
-	healthy := tc.enabled && tc.errors < 3
+	healthy := tc.enabled && tc.breaker.available()
 	if needsSynced {
 		return healthy && tc.synced
 	}
@@ -46,6 +45,12 @@ func (tc *tcpConn) healthCheck(healthCheck *TCPHealthCheck, logger *log.Logger)
 	var unit = time.Nanosecond
 	var maxLatency = int(healthCheck.Timeout/unit) * 2
 
+	if err := checkTunnel(tc.tunnel); err != nil {
+		tc.markHealthy(false)
+		logger.Error(fmt.Errorf("tcpconn: healthcheck: %s: %v", tc.id, err))
+		return maxLatency
+	}
+
 	start := time.Now()
 
 	var err error
@@ -72,13 +77,10 @@ func (tc *tcpConn) markHealthy(healthy bool) {
 		tc.client.ForceReconnect()
 	}
 
-	tc.mu.Lock()
-	defer tc.mu.Unlock()
-
 	if healthy {
-		tc.errors = 0
+		tc.breaker.recordHealthCheck()
 	} else {
-		tc.errors++
+		tc.breaker.recordFailure()
 	}
 }
 
@@ -92,11 +94,13 @@ func (tc *tcpConn) markSynced(synced bool) {
 
 // Execute a request with a given timeout.
 func (tc *tcpConn) exec(req *rpc.Request, timeout time.Duration) (*rpc.Response, error) {
-	res, err := tc.client.WriteRequestWithTimeout(req, tcpTimeout)
+	start := time.Now()
+	res, err := tc.client.WriteRequestWithTimeout(req, timeout)
 	if err != nil {
 		tc.markHealthy(false)
 		return nil, err
 	}
+	tc.breaker.recordSuccess(req.Method, time.Since(start))
 
 	res.HostID = tc.id
 
@@ -8,7 +8,9 @@ import (
 	"github.com/magicpool-co/pool/pkg/sshtunnel"
 )
 
-func parseURL(url string, port int, tunnel *sshtunnel.SSHTunnel) (string, string, error) {
+// parseURL returns the final url and the id for the host, along with
+// the tunnel the host is routed through (if any).
+func parseURL(url string, port int, tunnel *sshtunnel.SSHTunnel) (string, string, *sshtunnel.SSHTunnel, error) {
 	id := url
 	if port != 0 {
 		url = fmt.Sprintf("%s:%d", url, port)
@@ -16,20 +18,32 @@ func parseURL(url string, port int, tunnel *sshtunnel.SSHTunnel) (string, string
 
 	// tunnel the host if required (and active tunnel exists)
 	if len(url) < 9 {
-		return "", "", fmt.Errorf("url too short: %s", url)
+		return "", "", nil, fmt.Errorf("url too short: %s", url)
 	} else if url[:9] == "tunnel://" {
 		if tunnel == nil {
-			return "", "", fmt.Errorf("no active tunnel to use")
+			return "", "", nil, fmt.Errorf("no active tunnel to use")
 		}
 
 		var err error
 		url, err = tunnel.AddDestination(url[9:])
 		if err != nil {
-			return "", "", err
+			return "", "", nil, err
 		}
+
+		return url, id, tunnel, nil
+	}
+
+	return url, id, nil, nil
+}
+
+// checkTunnel returns an error if the host is routed through a tunnel that is
+// down, so health checks can fail immediately instead of timing out.
+func checkTunnel(tunnel *sshtunnel.SSHTunnel) error {
+	if tunnel != nil && !tunnel.Connected() {
+		return sshtunnel.ErrNotConnected
 	}
 
-	return url, id, nil
+	return nil
 }
 
 func generateID(input string) string {
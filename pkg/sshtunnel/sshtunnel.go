@@ -5,8 +5,21 @@ import (
 	"io"
 	"io/ioutil"
 	"net"
+	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/magicpool-co/pool/internal/log"
+)
+
+var (
+	ErrNotConnected   = fmt.Errorf("tunnel not connected")
+	ErrClosed         = fmt.Errorf("tunnel closed")
+	ErrNoHosts        = fmt.Errorf("no tunnel hosts")
+	ErrNoVerification = fmt.Errorf("no known hosts file or host key fingerprints")
 )
 
 /* helpers */
@@ -31,24 +44,313 @@ func PrivateKeyFile(file, password string) (ssh.AuthMethod, error) {
 	return ssh.PublicKeys(signer), nil
 }
 
+// HostKeyCallback verifies the host key against a known_hosts file and/or a set
+// of pinned SHA256 fingerprints (in the "SHA256:..." format of ssh-keygen -l). If both
+// are given, the key has to pass both. At least one of them is required, since an
+// unverified tunnel exposes every node (and the payout keys) to a man-in-the-middle.
+func HostKeyCallback(knownHostsFile string, fingerprints []string) (ssh.HostKeyCallback, error) {
+	pinned := make(map[string]bool, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		fingerprint = strings.TrimSpace(fingerprint)
+		if len(fingerprint) > 0 {
+			pinned[fingerprint] = true
+		}
+	}
+
+	var knownHostsCallback ssh.HostKeyCallback
+	if len(knownHostsFile) > 0 {
+		var err error
+		knownHostsCallback, err = knownhosts.New(knownHostsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if knownHostsCallback == nil && len(pinned) == 0 {
+		return nil, ErrNoVerification
+	}
+
+	callback := func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if knownHostsCallback != nil {
+			if err := knownHostsCallback(hostname, remote, key); err != nil {
+				return err
+			}
+		}
+
+		if len(pinned) > 0 {
+			fingerprint := ssh.FingerprintSHA256(key)
+			if !pinned[fingerprint] {
+				return fmt.Errorf("host key mismatch for %s: %s", hostname, fingerprint)
+			}
+		}
+
+		return nil
+	}
+
+	return callback, nil
+}
+
 /* tunnel */
 
+// Options specifies the tunnel configuration. Hosts are the bastions in order of
+// preference, the tunnel fails over to the next host whenever the active one is
+// unreachable. HostKeyCallback is required (see HostKeyCallback).
+//
+// KeepaliveInterval defaults to 15 seconds, KeepaliveTimeout and DialTimeout default to
+// 10 seconds. Reconnects back off exponentially from MinBackoff (one second) to MaxBackoff (one minute).
+type Options struct {
+	User              string
+	Hosts             []string
+	Auth              ssh.AuthMethod
+	HostKeyCallback   ssh.HostKeyCallback
+	KeepaliveInterval time.Duration
+	KeepaliveTimeout  time.Duration
+	DialTimeout       time.Duration
+	MinBackoff        time.Duration
+	MaxBackoff        time.Duration
+	Logger            *log.Logger
+}
+
 type SSHTunnel struct {
-	conn *ssh.Client
+	mu          sync.RWMutex
+	conn        *ssh.Client
+	host        string
+	connected   bool
+	closed      bool
+	reconnectCh chan struct{}
+	closeCh     chan struct{}
+
+	cfg  *ssh.ClientConfig
+	opts Options
 }
 
-func New(user, host string, auth ssh.AuthMethod) (*SSHTunnel, error) {
-	cfg := &ssh.ClientConfig{
-		User: user,
-		Auth: []ssh.AuthMethod{auth},
-		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
-			return nil
+func New(opts Options) (*SSHTunnel, error) {
+	if len(opts.Hosts) == 0 {
+		return nil, ErrNoHosts
+	} else if opts.HostKeyCallback == nil {
+		return nil, ErrNoVerification
+	}
+
+	if opts.KeepaliveInterval == 0 {
+		opts.KeepaliveInterval = time.Second * 15
+	}
+	if opts.KeepaliveTimeout == 0 {
+		opts.KeepaliveTimeout = time.Second * 10
+	}
+	if opts.DialTimeout == 0 {
+		opts.DialTimeout = time.Second * 10
+	}
+	if opts.MinBackoff == 0 {
+		opts.MinBackoff = time.Second
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = time.Minute
+	}
+
+	tunnel := &SSHTunnel{
+		reconnectCh: make(chan struct{}, 1),
+		closeCh:     make(chan struct{}),
+		cfg: &ssh.ClientConfig{
+			User:            opts.User,
+			Auth:            []ssh.AuthMethod{opts.Auth},
+			HostKeyCallback: opts.HostKeyCallback,
+			Timeout:         opts.DialTimeout,
 		},
+		opts: opts,
+	}
+
+	err := tunnel.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	go tunnel.keepalive()
+	go tunnel.reconnect()
+
+	return tunnel, nil
+}
+
+func (tunnel *SSHTunnel) logError(err error) {
+	if tunnel.opts.Logger != nil {
+		tunnel.opts.Logger.Error(fmt.Errorf("sshtunnel: %v", err))
+	}
+}
+
+func (tunnel *SSHTunnel) logInfo(message string) {
+	if tunnel.opts.Logger != nil {
+		tunnel.opts.Logger.Info("sshtunnel: " + message)
+	}
+}
+
+// connect dials every host in order of preference until one succeeds.
+func (tunnel *SSHTunnel) connect() error {
+	var err error
+	for _, host := range tunnel.opts.Hosts {
+		var conn *ssh.Client
+		conn, err = ssh.Dial("tcp", host, tunnel.cfg)
+		if err != nil {
+			err = fmt.Errorf("%s: %v", host, err)
+			tunnel.logError(err)
+			continue
+		}
+
+		tunnel.mu.Lock()
+		if tunnel.closed {
+			tunnel.mu.Unlock()
+			conn.Close()
+			return ErrClosed
+		}
+		tunnel.conn = conn
+		tunnel.host = host
+		tunnel.connected = true
+		tunnel.mu.Unlock()
+
+		// trigger a reconnect as soon as the connection drops
+		go func() {
+			conn.Wait()
+			tunnel.disconnect(conn)
+		}()
+
+		return nil
+	}
+
+	return err
+}
+
+// disconnect marks the given connection as dropped and triggers a reconnect,
+// unless the connection was already replaced.
+func (tunnel *SSHTunnel) disconnect(conn *ssh.Client) {
+	tunnel.mu.Lock()
+	if tunnel.conn != conn || !tunnel.connected {
+		tunnel.mu.Unlock()
+		return
 	}
+	tunnel.connected = false
+	host := tunnel.host
+	tunnel.mu.Unlock()
+
+	conn.Close()
+	tunnel.logError(fmt.Errorf("%s: %v", host, ErrNotConnected))
+
+	select {
+	case tunnel.reconnectCh <- struct{}{}:
+	default:
+	}
+}
+
+// keepalive sends a keepalive request on the interval, dropping the
+// connection if it fails or doesn't respond within the timeout.
+func (tunnel *SSHTunnel) keepalive() {
+	ticker := time.NewTicker(tunnel.opts.KeepaliveInterval)
+	defer ticker.Stop()
 
-	tunnelConn, err := ssh.Dial("tcp", host, cfg)
+	for {
+		select {
+		case <-tunnel.closeCh:
+			return
+		case <-ticker.C:
+			tunnel.mu.RLock()
+			conn, connected := tunnel.conn, tunnel.connected
+			tunnel.mu.RUnlock()
+			if !connected {
+				continue
+			}
+
+			errCh := make(chan error, 1)
+			go func() {
+				_, _, err := conn.SendRequest("keepalive@openssh.com", true, nil)
+				errCh <- err
+			}()
 
-	return &SSHTunnel{tunnelConn}, err
+			timer := time.NewTimer(tunnel.opts.KeepaliveTimeout)
+			select {
+			case err := <-errCh:
+				if err != nil {
+					tunnel.logError(fmt.Errorf("keepalive: %v", err))
+					tunnel.disconnect(conn)
+				}
+			case <-timer.C:
+				tunnel.logError(fmt.Errorf("keepalive: timed out"))
+				tunnel.disconnect(conn)
+			}
+			timer.Stop()
+		}
+	}
+}
+
+// reconnect waits for dropped connections and reconnects with exponential backoff.
+func (tunnel *SSHTunnel) reconnect() {
+	for {
+		select {
+		case <-tunnel.closeCh:
+			return
+		case <-tunnel.reconnectCh:
+		}
+
+		backoff := tunnel.opts.MinBackoff
+		for {
+			err := tunnel.connect()
+			if err == nil {
+				tunnel.mu.RLock()
+				host := tunnel.host
+				tunnel.mu.RUnlock()
+				tunnel.logInfo("reconnected to " + host)
+				break
+			} else if err == ErrClosed {
+				return
+			}
+
+			select {
+			case <-tunnel.closeCh:
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > tunnel.opts.MaxBackoff {
+				backoff = tunnel.opts.MaxBackoff
+			}
+		}
+	}
+}
+
+// Connected returns true if the tunnel currently has an active SSH connection.
+func (tunnel *SSHTunnel) Connected() bool {
+	if tunnel == nil {
+		return false
+	}
+
+	tunnel.mu.RLock()
+	defer tunnel.mu.RUnlock()
+
+	return tunnel.connected
+}
+
+// Host returns the active bastion host.
+func (tunnel *SSHTunnel) Host() string {
+	tunnel.mu.RLock()
+	defer tunnel.mu.RUnlock()
+
+	return tunnel.host
+}
+
+// Close closes the tunnel, every forwarded connection is dropped.
+func (tunnel *SSHTunnel) Close() error {
+	tunnel.mu.Lock()
+	defer tunnel.mu.Unlock()
+
+	if tunnel.closed {
+		return nil
+	}
+	tunnel.closed = true
+	tunnel.connected = false
+	close(tunnel.closeCh)
+
+	if tunnel.conn != nil {
+		return tunnel.conn.Close()
+	}
+
+	return nil
 }
 
 func (tunnel *SSHTunnel) AddDestination(dest string) (string, error) {
@@ -60,6 +362,11 @@ func (tunnel *SSHTunnel) AddDestination(dest string) (string, error) {
 	port := listener.Addr().(*net.TCPAddr).Port
 	host := fmt.Sprintf("http://localhost:%d", port)
 
+	go func() {
+		<-tunnel.closeCh
+		listener.Close()
+	}()
+
 	go func() {
 		defer listener.Close()
 
@@ -69,9 +376,12 @@ func (tunnel *SSHTunnel) AddDestination(dest string) (string, error) {
 				return
 			}
 
+			// the listener outlives any single ssh connection, so failed
+			// forwards only drop the local connection
 			err = tunnel.forward(conn, dest)
 			if err != nil {
-				return
+				conn.Close()
+				tunnel.logError(fmt.Errorf("forward: %s: %v", dest, err))
 			}
 		}
 	}()
@@ -80,13 +390,29 @@ func (tunnel *SSHTunnel) AddDestination(dest string) (string, error) {
 }
 
 func (tunnel *SSHTunnel) forward(localConn net.Conn, dest string) error {
-	remoteConn, err := tunnel.conn.Dial("tcp", dest)
+	tunnel.mu.RLock()
+	conn, connected := tunnel.conn, tunnel.connected
+	tunnel.mu.RUnlock()
+	if !connected {
+		return ErrNotConnected
+	}
+
+	remoteConn, err := conn.Dial("tcp", dest)
 	if err != nil {
 		return err
 	}
 
-	go io.Copy(localConn, remoteConn)
-	go io.Copy(remoteConn, localConn)
+	go func() {
+		io.Copy(localConn, remoteConn)
+		localConn.Close()
+		remoteConn.Close()
+	}()
+
+	go func() {
+		io.Copy(remoteConn, localConn)
+		localConn.Close()
+		remoteConn.Close()
+	}()
 
 	return nil
 }
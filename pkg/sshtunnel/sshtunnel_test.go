@@ -0,0 +1,93 @@
+package sshtunnel
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func generateHostKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("failed to parse key: %v", err)
+	}
+
+	return key
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	const hostname = "bastion.example.com:22"
+	remote := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}
+
+	trustedKey := generateHostKey(t)
+	untrustedKey := generateHostKey(t)
+
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, trustedKey) + "\n"
+	if err := os.WriteFile(knownHostsFile, []byte(line), 0600); err != nil {
+		t.Fatalf("failed to write known hosts: %v", err)
+	}
+
+	_, err := HostKeyCallback("", nil)
+	if err != ErrNoVerification {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrNoVerification)
+	}
+
+	tests := []struct {
+		knownHostsFile string
+		fingerprints   []string
+		key            ssh.PublicKey
+		valid          bool
+	}{
+		{
+			fingerprints: []string{ssh.FingerprintSHA256(trustedKey)},
+			key:          trustedKey,
+			valid:        true,
+		},
+		{
+			fingerprints: []string{ssh.FingerprintSHA256(trustedKey)},
+			key:          untrustedKey,
+			valid:        false,
+		},
+		{
+			knownHostsFile: knownHostsFile,
+			key:            trustedKey,
+			valid:          true,
+		},
+		{
+			knownHostsFile: knownHostsFile,
+			key:            untrustedKey,
+			valid:          false,
+		},
+		{
+			knownHostsFile: knownHostsFile,
+			fingerprints:   []string{ssh.FingerprintSHA256(untrustedKey)},
+			key:            trustedKey,
+			valid:          false,
+		},
+	}
+
+	for i, tt := range tests {
+		callback, err := HostKeyCallback(tt.knownHostsFile, tt.fingerprints)
+		if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+			continue
+		}
+
+		err = callback(hostname, remote, tt.key)
+		if valid := err == nil; valid != tt.valid {
+			t.Errorf("failed on %d: valid mismatch: have %t, want %t: %v", i, valid, tt.valid, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package stratum
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// banList is a set of banned IPs and CIDR ranges.
+type banList struct {
+	mu    sync.RWMutex
+	ips   map[string]bool
+	cidrs []*net.IPNet
+}
+
+func newBanList() *banList {
+	list := &banList{
+		ips:   make(map[string]bool),
+		cidrs: make([]*net.IPNet, 0),
+	}
+
+	return list
+}
+
+// parseBanList parses a list of IPs and CIDR ranges (e.g. "10.0.0.1" or "10.0.0.0/8").
+func parseBanList(entries []string) (map[string]bool, []*net.IPNet, error) {
+	ips := make(map[string]bool)
+	cidrs := make([]*net.IPNet, 0)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			_, cidr, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid ban list entry %s", entry)
+			}
+			cidrs = append(cidrs, cidr)
+		} else if ip := net.ParseIP(entry); ip != nil {
+			ips[ip.String()] = true
+		} else {
+			return nil, nil, fmt.Errorf("invalid ban list entry %s", entry)
+		}
+	}
+
+	return ips, cidrs, nil
+}
+
+func (l *banList) set(entries []string) error {
+	ips, cidrs, err := parseBanList(entries)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.ips = ips
+	l.cidrs = cidrs
+
+	return nil
+}
+
+func (l *banList) contains(rawIP string) bool {
+	ip := net.ParseIP(rawIP)
+	if ip == nil {
+		return false
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.ips[ip.String()] {
+		return true
+	}
+
+	for _, cidr := range l.cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -34,6 +34,8 @@ type Server struct {
 	mu             sync.RWMutex
 	counter        uint64
 	varDiffEnabled bool
+	varDiffOpts    VarDiffOptions
+	banList        *banList
 	conns          map[uint64]*Conn
 }
 
@@ -63,6 +65,8 @@ func NewServer(
 		addrs:          addrs,
 		listeners:      make([]net.Listener, len(addrs)),
 		varDiffEnabled: enableVarDiff,
+		varDiffOpts:    DefaultVarDiffOptions(),
+		banList:        newBanList(),
 		conns:          make(map[uint64]*Conn),
 	}
 
@@ -91,12 +95,60 @@ func (s *Server) newConn(rawConn net.Conn, port int) *Conn {
 		ip = addr.IP.String()
 	}
 
-	conn := NewConn(s.counter, port, ip, s.varDiffEnabled, rawConn)
+	var varDiffOpts *VarDiffOptions
+	if s.varDiffEnabled {
+		opts := s.varDiffOpts
+		varDiffOpts = &opts
+	}
+
+	conn := NewConn(s.counter, port, ip, varDiffOpts, rawConn)
 	s.conns[conn.id] = conn
 
 	return conn
 }
 
+// SetVarDiffOptions replaces the vardiff options for new and existing conns.
+func (s *Server) SetVarDiffOptions(opts VarDiffOptions) {
+	opts.setDefaults()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.varDiffOpts = opts
+	for _, conn := range s.conns {
+		conn.setVarDiffOptions(opts)
+	}
+}
+
+// SetBanList replaces the list of banned IPs and CIDR ranges. new conns from a
+// banned IP are rejected, existing conns from a banned IP are closed.
+func (s *Server) SetBanList(entries []string) error {
+	err := s.banList.set(entries)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, conn := range s.conns {
+		if s.banList.contains(conn.ip) {
+			conn.SoftClose()
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) isBanned(rawConn net.Conn) bool {
+	addr, ok := rawConn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	return s.banList.contains(addr.IP.String())
+}
+
 func (s *Server) GetConn(id uint64) (*Conn, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -156,6 +208,11 @@ func (s *Server) Start(connTimeout time.Duration) (
 					}
 				}
 
+				if s.isBanned(rawConn) {
+					rawConn.Close()
+					continue
+				}
+
 				go func() {
 					defer recoverPanic(errCh)
 					s.wg.Add(1)
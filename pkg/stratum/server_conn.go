@@ -27,8 +27,10 @@ type Conn struct {
 	subscribed           uint32
 	authorized           uint32
 	isSolo               uint32
+	soloAddress          *atomic.Value
 	client               *atomic.Value
 	clientType           int32
+	versionMask          uint32
 	diffFactor           int32
 	lastDiffFactor       int32
 	lastDiffFactorAt     int64
@@ -59,10 +61,11 @@ func loadString(val *atomic.Value) string {
 	return ""
 }
 
-func NewConn(id uint64, port int, ip string, enableVarDiff bool, rawConn net.Conn) *Conn {
+// NewConn creates a new conn, vardiff is disabled if varDiffOpts is nil.
+func NewConn(id uint64, port int, ip string, varDiffOpts *VarDiffOptions, rawConn net.Conn) *Conn {
 	var varDiff *varDiffManager
-	if enableVarDiff {
-		varDiff = newVarDiffManager(0)
+	if varDiffOpts != nil {
+		varDiff = newVarDiffManager(0, *varDiffOpts)
 	}
 
 	conn := &Conn{
@@ -73,11 +76,12 @@ func NewConn(id uint64, port int, ip string, enableVarDiff bool, rawConn net.Con
 		varDiff: varDiff,
 		quit:    make(chan struct{}),
 
-		miner:      new(atomic.Value),
-		worker:     new(atomic.Value),
-		compoundID: new(atomic.Value),
-		extraNonce: new(atomic.Value),
-		client:     new(atomic.Value),
+		miner:       new(atomic.Value),
+		worker:      new(atomic.Value),
+		compoundID:  new(atomic.Value),
+		extraNonce:  new(atomic.Value),
+		soloAddress: new(atomic.Value),
+		client:      new(atomic.Value),
 	}
 
 	return conn
@@ -96,8 +100,10 @@ func (c *Conn) GetExtraNonceSubscribed() bool      { return loadBool(&(c.extraNo
 func (c *Conn) GetSubscribed() bool                { return loadBool(&(c.subscribed)) }
 func (c *Conn) GetAuthorized() bool                { return loadBool(&(c.authorized)) }
 func (c *Conn) GetIsSolo() bool                    { return loadBool(&(c.isSolo)) }
+func (c *Conn) GetSoloAddress() string             { return loadString(c.soloAddress) }
 func (c *Conn) GetClient() string                  { return loadString(c.client) }
 func (c *Conn) GetClientType() int                 { return int(atomic.LoadInt32(&(c.clientType))) }
+func (c *Conn) GetVersionMask() uint32             { return atomic.LoadUint32(&(c.versionMask)) }
 func (c *Conn) GetDiffFactor() int                 { return int(atomic.LoadInt32(&(c.diffFactor))) }
 func (c *Conn) GetLastDiffFactor() int             { return int(atomic.LoadInt32(&(c.lastDiffFactor))) }
 func (c *Conn) GetLastDiffFactorAt() time.Time     { return time.Unix(atomic.LoadInt64(&c.lastErrorAt), 0) }
@@ -128,8 +134,10 @@ func (c *Conn) SetExtraNonceSubscribed(extraNonceSubscribed bool) {
 func (c *Conn) SetSubscribed(subscribed bool) { storeBool(&(c.subscribed), subscribed) }
 func (c *Conn) SetAuthorized(authorized bool) { storeBool(&(c.authorized), authorized) }
 func (c *Conn) SetIsSolo(isSolo bool)         { storeBool(&(c.isSolo), isSolo) }
+func (c *Conn) SetSoloAddress(address string) { c.soloAddress.Store(address) }
 func (c *Conn) SetClient(client string)       { c.client.Store(client) }
 func (c *Conn) SetClientType(clientType int)  { atomic.StoreInt32(&(c.clientType), int32(clientType)) }
+func (c *Conn) SetVersionMask(mask uint32)    { atomic.StoreUint32(&(c.versionMask), mask) }
 func (c *Conn) SetDiffFactor(diffFactor int) {
 	if c.varDiff != nil {
 		c.varDiff.SetCurrentDiff(diffFactor, c.GetDiffFactor() == 0)
@@ -151,6 +159,12 @@ func (c *Conn) SetLastShareAt(ts time.Time) int {
 	return -1
 }
 
+func (c *Conn) setVarDiffOptions(opts VarDiffOptions) {
+	if c.varDiff != nil {
+		c.varDiff.SetOptions(opts)
+	}
+}
+
 func (c *Conn) Write(data []byte) error {
 	_, err := c.conn.Write(append(data, '\n'))
 	if err != nil {
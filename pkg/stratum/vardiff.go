@@ -5,19 +5,68 @@ import (
 	"time"
 )
 
-const (
-	targetTime    = time.Second * 10
-	retargetDelay = time.Second * 90
-	bufferSize    = (int(retargetDelay) / int(targetTime)) * 4
-
-	variance      = time.Duration(float64(targetTime) * 0.6)
-	minTargetTime = targetTime - variance
-	maxTargetTime = targetTime + variance
-
-	globalMinDiff   = 1
-	globalMaxDiff   = 256
-	diffBoundFactor = 8
-)
+// VarDiffOptions specifies the difficulty retargeting. Miners are retargeted to submit a share
+// every TargetTime (within the Variance fraction of it), at most once every RetargetDelay.
+// Each miner's difficulty stays within BoundFactor of the difficulty it started at, and
+// within MinDiff and MaxDiff overall.
+type VarDiffOptions struct {
+	TargetTime    time.Duration
+	RetargetDelay time.Duration
+	Variance      float64
+	MinDiff       int
+	MaxDiff       int
+	BoundFactor   int
+}
+
+func DefaultVarDiffOptions() VarDiffOptions {
+	opts := VarDiffOptions{
+		TargetTime:    time.Second * 10,
+		RetargetDelay: time.Second * 90,
+		Variance:      0.6,
+		MinDiff:       1,
+		MaxDiff:       256,
+		BoundFactor:   8,
+	}
+
+	return opts
+}
+
+func (opts *VarDiffOptions) setDefaults() {
+	defaults := DefaultVarDiffOptions()
+	if opts.TargetTime <= 0 {
+		opts.TargetTime = defaults.TargetTime
+	}
+	if opts.RetargetDelay <= 0 {
+		opts.RetargetDelay = defaults.RetargetDelay
+	}
+	if opts.Variance <= 0 || opts.Variance >= 1 {
+		opts.Variance = defaults.Variance
+	}
+	if opts.MinDiff <= 0 {
+		opts.MinDiff = defaults.MinDiff
+	}
+	if opts.MaxDiff < opts.MinDiff {
+		opts.MaxDiff = opts.MinDiff
+	}
+	if opts.BoundFactor <= 0 {
+		opts.BoundFactor = defaults.BoundFactor
+	}
+}
+
+func (opts VarDiffOptions) bufferSize() int {
+	size := int(opts.RetargetDelay/opts.TargetTime) * 4
+	if size < 1 {
+		size = 1
+	}
+
+	return size
+}
+
+func (opts VarDiffOptions) targetBounds() (time.Duration, time.Duration) {
+	variance := time.Duration(float64(opts.TargetTime) * opts.Variance)
+
+	return opts.TargetTime - variance, opts.TargetTime + variance
+}
 
 type ringBuffer struct {
 	size   int
@@ -72,6 +121,7 @@ func (b *ringBuffer) Average() int64 {
 }
 
 type varDiffManager struct {
+	opts          VarDiffOptions
 	diff          int
 	minDiff       int
 	maxDiff       int
@@ -84,37 +134,53 @@ type varDiffManager struct {
 	mu         sync.Mutex
 }
 
-func floorDiff(currentDiff int) int {
-	diff := currentDiff / diffBoundFactor
-	if diff < globalMinDiff {
-		return globalMinDiff
+func (m *varDiffManager) floorDiff(currentDiff int) int {
+	diff := currentDiff / m.opts.BoundFactor
+	if diff < m.opts.MinDiff {
+		return m.opts.MinDiff
 	}
 	return diff
 }
 
-func ceilDiff(currentDiff int) int {
-	diff := currentDiff * diffBoundFactor
-	if diff > globalMaxDiff {
-		return globalMaxDiff
+func (m *varDiffManager) ceilDiff(currentDiff int) int {
+	diff := currentDiff * m.opts.BoundFactor
+	if diff > m.opts.MaxDiff {
+		return m.opts.MaxDiff
 	}
 	return diff
 }
 
-func newVarDiffManager(currentDiff int) *varDiffManager {
+func newVarDiffManager(currentDiff int, opts VarDiffOptions) *varDiffManager {
+	opts.setDefaults()
+
 	now := time.Now()
 	manager := &varDiffManager{
+		opts:         opts,
 		diff:         currentDiff,
-		minDiff:      floorDiff(currentDiff),
-		maxDiff:      ceilDiff(currentDiff),
 		lastDiff:     currentDiff,
-		ringBuffer:   newRingBuffer(bufferSize),
+		ringBuffer:   newRingBuffer(opts.bufferSize()),
 		lastShare:    now,
-		lastRetarget: now.Add(-retargetDelay / 2),
+		lastRetarget: now.Add(-opts.RetargetDelay / 2),
 	}
+	manager.minDiff = manager.floorDiff(currentDiff)
+	manager.maxDiff = manager.ceilDiff(currentDiff)
 
 	return manager
 }
 
+// SetOptions replaces the retargeting options, resetting the share buffer
+// and the difficulty bounds (around the current difficulty).
+func (m *varDiffManager) SetOptions(opts VarDiffOptions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	opts.setDefaults()
+	m.opts = opts
+	m.ringBuffer = newRingBuffer(opts.bufferSize())
+	m.minDiff = m.floorDiff(m.diff)
+	m.maxDiff = m.ceilDiff(m.diff)
+}
+
 func (m *varDiffManager) SetCurrentDiff(currentDiff int, shiftBounds bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -122,8 +188,8 @@ func (m *varDiffManager) SetCurrentDiff(currentDiff int, shiftBounds bool) {
 	m.lastDiff = m.diff
 	m.diff = currentDiff
 	if shiftBounds {
-		m.minDiff = floorDiff(currentDiff)
-		m.maxDiff = ceilDiff(currentDiff)
+		m.minDiff = m.floorDiff(currentDiff)
+		m.maxDiff = m.ceilDiff(currentDiff)
 	}
 }
 
@@ -141,7 +207,7 @@ func (m *varDiffManager) Retarget(shareAt time.Time) int {
 	m.ringBuffer.Append(int64(timeSinceLastShare))
 
 	timeSinceLastRetarget := now.Sub(m.lastRetarget)
-	if timeSinceLastRetarget < retargetDelay {
+	if timeSinceLastRetarget < m.opts.RetargetDelay {
 		// if time since last retarget is less than the
 		// retarget wait period, don't do anything
 		return m.diff
@@ -150,6 +216,7 @@ func (m *varDiffManager) Retarget(shareAt time.Time) int {
 
 	// fetch the average share submit time
 	avg := time.Duration(m.ringBuffer.Average())
+	minTargetTime, maxTargetTime := m.opts.targetBounds()
 	var newDiff int
 	if avg > maxTargetTime && m.diff > m.minDiff {
 		// decrease the difficulty by a factor of 2
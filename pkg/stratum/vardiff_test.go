@@ -96,7 +96,7 @@ func TestVarDiffManager(t *testing.T) {
 	}
 
 	for i, tt := range tests {
-		mgr := newVarDiffManager(tt.startDiff)
+		mgr := newVarDiffManager(tt.startDiff, DefaultVarDiffOptions())
 		mgr.lastShare = tt.startLastShare
 		for j, lastShare := range tt.lastShares {
 			mgr.lastRetarget = tt.lastRetargets[j]
@@ -4,9 +4,9 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/magicpool-co/pool/app/api"
+	"github.com/magicpool-co/pool/internal/config"
 	"github.com/magicpool-co/pool/internal/log"
 	"github.com/magicpool-co/pool/internal/node"
 	"github.com/magicpool-co/pool/internal/pooldb"
@@ -17,7 +17,7 @@ import (
 	"github.com/magicpool-co/pool/types"
 )
 
-func newAPI(secrets map[string]string, port int) (*http.Server, *log.Logger, error) {
+func newAPI(secrets map[string]string, cfg *config.Config) (*http.Server, *log.Logger, error) {
 	telegramClient, err := telegram.New(secrets)
 	if err != nil {
 		return nil, nil, err
@@ -42,50 +42,63 @@ func newAPI(secrets map[string]string, port int) (*http.Server, *log.Logger, err
 		return nil, nil, err
 	}
 
+	shareStore, err := svc.NewShareStore(cfg, tsdbClient, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	redisClient, err := redis.New(secrets)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	chains := []string{"ERG", "ETC", "KAS", "NEXA"}
-	nodes := make([]types.MiningNode, len(chains))
-	for i, chain := range chains {
+	nodes := make([]types.MiningNode, len(cfg.API.Chains))
+	for i, chain := range cfg.API.Chains {
 		nodes[i], err = node.GetMiningNode(true, chain, secrets[chain+"_PRIVATE_KEY"], nil, logger, nil)
 		if err != nil {
 			return nil, nil, err
 		}
 	}
 
-	var cacheEnabled bool
-	if rawCacheEnabled, ok := secrets["REDIS_CACHE_ENABLED"]; ok {
-		if strings.ToLower(rawCacheEnabled) == "true" {
-			cacheEnabled = true
+	ctx := api.NewContext(logger, nil, pooldbClient, tsdbClient, shareStore, redisClient, nodes,
+		cfg.API.CacheEnabled, cfg.Fees.PoolFeeBasisPoints)
+	if len(secrets["ADMIN_TOKENS"]) > 0 {
+		err = ctx.SetAdminTokens(secrets["ADMIN_TOKENS"])
+		if err != nil {
+			return nil, nil, err
 		}
+	} else {
+		logger.Info("admin routes disabled, no admin tokens")
 	}
 
-	ctx := api.NewContext(logger, nil, pooldbClient, tsdbClient, redisClient, nodes, cacheEnabled)
-	server := api.New(ctx, port)
+	server := api.New(ctx, cfg.API.Port)
 
 	return server, logger, nil
 }
 
 func main() {
-	argPort := flag.Int("port", 8080, "The port to use")
 	argSecretVar := flag.String("secret", "", "ENV variable defined by ECS")
+	argConfig := flag.String("config", "", "The config file to use (the default config if empty)")
+	argCheckConfig := flag.Bool("check-config", false, "Validate and print the config, then exit")
 
 	flag.Parse()
 
 	secrets, err := svc.ParseSecrets(*argSecretVar)
+	if err != nil && !*argCheckConfig {
+		panic(err)
+	}
+
+	cfg, err := svc.LoadConfig(*argConfig, *argCheckConfig, secrets)
 	if err != nil {
 		panic(err)
 	}
 
-	apiServer, logger, err := newAPI(secrets, *argPort)
+	apiServer, logger, err := newAPI(secrets, cfg)
 	if err != nil {
 		panic(err)
 	}
 
-	logger.Info(fmt.Sprintf("api running on port %d", *argPort))
+	logger.Info(fmt.Sprintf("api running on port %d", cfg.API.Port))
 
 	runner := svc.NewRunner(logger)
 	runner.AddHTTPServer(apiServer)
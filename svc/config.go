@@ -0,0 +1,43 @@
+package svc
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/magicpool-co/pool/internal/config"
+	"github.com/magicpool-co/pool/internal/tsdb"
+	"github.com/magicpool-co/pool/pkg/dbcl"
+)
+
+// LoadConfig loads the config file, or the default config if the path is empty. In
+// check mode, the config is validated and printed (with credentials redacted) and the
+// process exits, with a non-zero status if the config is invalid.
+func LoadConfig(path string, check bool, secrets map[string]string) (*config.Config, error) {
+	cfg, err := config.Load(path, secrets)
+	if !check {
+		return cfg, err
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Print(cfg.String())
+	os.Exit(0)
+
+	return cfg, nil
+}
+
+// NewShareStore returns the share store of the configured backend. the columnar backend
+// is written by the workers, every other service should open it read only.
+func NewShareStore(cfg *config.Config, tsdbClient *dbcl.Client, readOnly bool) (tsdb.ShareStore, error) {
+	switch cfg.TSDB.ShareBackend {
+	case "columnar":
+		store, err := tsdb.OpenColumnarShareStore(cfg.TSDB.ShareDir, readOnly)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return tsdb.NewShareStore(tsdbClient), nil
+	}
+}
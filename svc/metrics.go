@@ -0,0 +1,28 @@
+package svc
+
+import (
+	"github.com/magicpool-co/pool/internal/metrics"
+	"github.com/magicpool-co/pool/pkg/hostpool"
+	"github.com/magicpool-co/pool/types"
+)
+
+// InitHostMetrics reports the outcome of every request the node makes to its hosts
+// (latency, status and breaker state), if the node supports it and metrics are enabled.
+func InitHostMetrics(chain string, miningNode types.MiningNode, metricsClient *metrics.Client) {
+	hostNode, ok := miningNode.(types.HostRequestNode)
+	if !ok || metricsClient == nil {
+		return
+	}
+
+	hostNode.SetRequestHandler(func(result *hostpool.RequestResult) {
+		status := "success"
+		if result.Err != nil {
+			status = "error"
+		}
+
+		latency := float64(result.Latency.Microseconds()) / 1000
+		metricsClient.ObserveHistogram("node_request_duration_ms", latency, chain, result.HostID, result.Method)
+		metricsClient.IncrementCounter("node_requests_total", chain, result.HostID, result.Method, status)
+		metricsClient.SetGauge("node_breaker_state", float64(result.State), chain, result.HostID)
+	})
+}
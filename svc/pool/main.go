@@ -3,148 +3,153 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/magicpool-co/pool/app/pool"
+	"github.com/magicpool-co/pool/internal/config"
+	"github.com/magicpool-co/pool/pkg/stratum"
 	"github.com/magicpool-co/pool/svc"
 )
 
-var defaultOptions = map[string]*pool.Options{
-	"CFX": &pool.Options{
-		Chain:           "CFX",
-		WindowSize:      100000,
-		JobListSize:     100,
-		JobListAgeLimit: -1,
-		SoloEnabled:     true,
-		VarDiffEnabled:  false,
-		StreamEnabled:   true,
-		PollingPeriod:   time.Millisecond * 100,
-	},
-	"ERG": &pool.Options{
-		Chain:                "ERG",
-		WindowSize:           100000,
-		ExtraNonceSize:       2,
-		JobListSize:          5,
-		SoloEnabled:          true,
-		VarDiffEnabled:       false,
-		StreamEnabled:        true,
-		ForceErrorOnResponse: true,
-		PollingPeriod:        time.Millisecond * 100,
-		PingingPeriod:        time.Minute,
-	},
-	"ETC": &pool.Options{
-		Chain:           "ETC",
-		WindowSize:      100000,
-		JobListSize:     25,
-		JobListAgeLimit: 7,
-		SoloEnabled:     true,
-		VarDiffEnabled:  false,
-		StreamEnabled:   true,
-		PollingPeriod:   time.Millisecond * 100,
-	},
-	"ETHW": &pool.Options{
-		Chain:           "ETHW",
-		WindowSize:      2000000,
-		JobListSize:     25,
-		JobListAgeLimit: 7,
-		SoloEnabled:     true,
-		VarDiffEnabled:  false,
-		StreamEnabled:   true,
-		PollingPeriod:   time.Millisecond * 100,
-	},
-	"FIRO": &pool.Options{
-		Chain:          "FIRO",
-		WindowSize:     300000,
-		ExtraNonceSize: 1,
-		JobListSize:    5,
-		SoloEnabled:    true,
-		VarDiffEnabled: false,
-		StreamEnabled:  true,
-		PollingPeriod:  time.Second,
-	},
-	"FLUX": &pool.Options{
-		Chain:          "FLUX",
-		WindowSize:     100000,
-		ExtraNonceSize: 4,
-		JobListSize:    5,
-		SoloEnabled:    true,
-		VarDiffEnabled: false,
-		StreamEnabled:  true,
-		PollingPeriod:  time.Second,
-	},
-	"KAS": &pool.Options{
-		Chain:           "KAS",
-		WindowSize:      100000,
-		ExtraNonceSize:  2,
-		JobListSize:     50,
-		JobListAgeLimit: 30,
-		SoloEnabled:     true,
-		VarDiffEnabled:  true,
-		StreamEnabled:   true,
-		PollingPeriod:   time.Millisecond * 100,
-		PingingPeriod:   time.Second * 10,
-	},
-	"NEXA": &pool.Options{
-		Chain:          "NEXA",
-		WindowSize:     150000,
-		ExtraNonceSize: 4,
-		JobListSize:    5,
-		SoloEnabled:    true,
-		VarDiffEnabled: false,
-		StreamEnabled:  true,
-		PollingPeriod:  time.Second,
-	},
-	"RVN": &pool.Options{
-		Chain:          "RVN",
-		WindowSize:     300000,
-		ExtraNonceSize: 1,
-		JobListSize:    5,
-		SoloEnabled:    true,
-		VarDiffEnabled: false,
-		StreamEnabled:  true,
-		PollingPeriod:  time.Second,
-	},
+const configReloadInterval = time.Second * 10
+
+func newVarDiffOptions(cfg config.VarDiffConfig) stratum.VarDiffOptions {
+	opts := stratum.VarDiffOptions{
+		TargetTime:    cfg.TargetTime,
+		RetargetDelay: cfg.RetargetDelay,
+		Variance:      cfg.Variance,
+		MinDiff:       cfg.MinDiff,
+		MaxDiff:       cfg.MaxDiff,
+		BoundFactor:   cfg.BoundFactor,
+	}
+
+	return opts
+}
+
+func newPoolOptions(cfg *config.Config, chain string) (*pool.Options, error) {
+	chainCfg, err := cfg.GetChain(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &pool.Options{
+		Chain:                chain,
+		PortDiffIdx:          cfg.GetPorts(chain),
+		WindowSize:           chainCfg.WindowSize,
+		ExtraNonceSize:       chainCfg.ExtraNonceSize,
+		VersionMask:          chainCfg.VersionMask,
+		JobListSize:          chainCfg.JobListSize,
+		JobListAgeLimit:      chainCfg.JobListAgeLimit,
+		SoloEnabled:          chainCfg.Solo,
+		TrueSoloEnabled:      chainCfg.TrueSolo,
+		VarDiffEnabled:       chainCfg.VarDiff.Enabled,
+		VarDiffOptions:       newVarDiffOptions(chainCfg.VarDiff),
+		BanList:              cfg.BanList,
+		PoolFeeBasisPoints:   cfg.Fees.PoolFeeBasisPoints,
+		StreamEnabled:        chainCfg.Stream,
+		ForceErrorOnResponse: chainCfg.ForceErrorOnResponse,
+		PollingPeriod:        chainCfg.PollingPeriod,
+		PingingPeriod:        chainCfg.PingingPeriod,
+		JournalDir:           cfg.Pool.Journal.Dir,
+		JournalInstance:      cfg.Pool.Journal.Instance,
+		JournalSegmentSize:   cfg.Pool.Journal.SegmentSize,
+		JournalMinAge:        cfg.Pool.Journal.MinAge,
+	}
+
+	if len(opts.JournalDir) > 0 && len(opts.JournalInstance) == 0 {
+		opts.JournalInstance, err = os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return opts, nil
+}
+
+// deprecatedPortFlags are the port flags from before the config file. if any of them
+// is set, they override the config the same way they used to set the ports.
+type deprecatedPortFlags struct {
+	port              *int
+	highDiffPort      *int
+	extraHighDiffPort *int
+	metricsPort       *int
+}
+
+func (f *deprecatedPortFlags) apply(opts *pool.Options, cfg *config.Config) error {
+	set := make(map[string]bool)
+	flag.Visit(func(fl *flag.Flag) {
+		set[fl.Name] = true
+	})
+
+	for _, name := range []string{"port", "high-diff-port", "extra-high-diff-port", "metrics-port"} {
+		if set[name] {
+			fmt.Fprintf(os.Stderr, "-%s is deprecated, use the config file instead\n", name)
+		}
+	}
+
+	if set["port"] || set["high-diff-port"] || set["extra-high-diff-port"] {
+		if *f.port <= 0 || *f.port >= 10000 {
+			return fmt.Errorf("port must be between 1 and 9999")
+		}
+
+		portDiffIdx := map[int]int{*f.port: 1}
+		if *f.highDiffPort != -1 {
+			portDiffIdx[*f.highDiffPort] = 16
+		}
+		if *f.extraHighDiffPort != -1 {
+			portDiffIdx[*f.extraHighDiffPort] = 256
+		}
+		opts.PortDiffIdx = portDiffIdx
+	}
+
+	if set["metrics-port"] {
+		cfg.Pool.MetricsPort = *f.metricsPort
+	}
+
+	return nil
 }
 
 func main() {
 	argChain := flag.String("chain", "ETC", "The chain to run the pool for")
 	argMainnet := flag.Bool("mainnet", true, "Whether or not to run on the mainnet")
 	argSecretVar := flag.String("secret", "", "ENV variable defined by ECS")
-	argStandardPort := flag.Int("port", 3333, "The pool port to use")
-	argHighDiffPort := flag.Int("high-diff-port", -1, "The port for high difficulty (-1 is disabled)")
-	argExtraHighDiffPort := flag.Int("extra-high-diff-port", -1, "The port for extra high difficulty (-1 is disabled)")
-	argMetricsPort := flag.Int("metrics-port", 6060, "The metrics port to use")
+	argConfig := flag.String("config", "", "The config file to use (the default config if empty)")
+	argCheckConfig := flag.Bool("check-config", false, "Validate and print the config, then exit")
+	portFlags := &deprecatedPortFlags{
+		port:              flag.Int("port", 3333, "Deprecated: the pool port to use (overrides the config)"),
+		highDiffPort:      flag.Int("high-diff-port", -1, "Deprecated: the port for high difficulty (-1 is disabled)"),
+		extraHighDiffPort: flag.Int("extra-high-diff-port", -1, "Deprecated: the port for extra high difficulty (-1 is disabled)"),
+		metricsPort:       flag.Int("metrics-port", 6060, "Deprecated: the metrics port to use (overrides the config)"),
+	}
 
 	flag.Parse()
 
-	opts, ok := defaultOptions[strings.ToUpper(*argChain)]
-	if !ok {
-		panic(fmt.Errorf("invalid chain %s", *argChain))
-	} else if *argStandardPort > 10000 {
-		panic(fmt.Errorf("port must be less than 10000"))
+	secrets, err := svc.ParseSecrets(*argSecretVar)
+	if err != nil && !*argCheckConfig {
+		panic(err)
 	}
 
-	portDiffIdx := map[int]int{*argStandardPort: 1}
-	if *argHighDiffPort != -1 {
-		portDiffIdx[*argHighDiffPort] = 16
-	}
-	if *argExtraHighDiffPort != -1 {
-		portDiffIdx[*argExtraHighDiffPort] = 256
+	cfg, err := svc.LoadConfig(*argConfig, *argCheckConfig, secrets)
+	if err != nil {
+		panic(err)
 	}
 
-	opts.PortDiffIdx = portDiffIdx
-	secrets, err := svc.ParseSecrets(*argSecretVar)
+	chain := strings.ToUpper(*argChain)
+	opts, err := newPoolOptions(cfg, chain)
 	if err != nil {
 		panic(err)
+	} else if err := portFlags.apply(opts, cfg); err != nil {
+		panic(err)
 	}
 
-	metricsClient, err := initMetrics(secrets["ENVIRONMENT"], *argMetricsPort)
+	metricsClient, err := initMetrics(secrets["ENVIRONMENT"], cfg.Pool.MetricsPort)
 	if err != nil {
 		panic(err)
 	}
 
-	poolServer, logger, err := newPool(secrets, *argMainnet, opts, metricsClient)
+	poolServer, logger, err := newPool(secrets, *argMainnet, opts, cfg.GetNodes(chain), metricsClient)
 	if err != nil {
 		panic(err)
 	}
@@ -154,5 +159,23 @@ func main() {
 	runner := svc.NewRunner(logger)
 	runner.AddTCPServer(poolServer)
 	runner.AddHTTPServer(metricsClient.Server())
+
+	if len(*argConfig) > 0 {
+		watcher, err := config.NewWatcher(*argConfig, secrets, cfg, configReloadInterval, logger,
+			func(cfg *config.Config) {
+				if err := poolServer.SetBanList(cfg.BanList); err != nil {
+					logger.Error(fmt.Errorf("config: %v", err))
+				}
+
+				if chainCfg, err := cfg.GetChain(chain); err == nil {
+					poolServer.SetVarDiffOptions(newVarDiffOptions(chainCfg.VarDiff))
+				}
+			})
+		if err != nil {
+			panic(err)
+		}
+		runner.AddWorker(watcher)
+	}
+
 	runner.Run()
 }
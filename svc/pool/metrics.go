@@ -66,5 +66,35 @@ func initMetrics(env string, port int) (*metrics.Client, error) {
 		return nil, err
 	}
 
+	err = metricsClient.NewHistogram("pool", "block_submit_duration_ms", env,
+		"The duration of block submissions to each node host in milliseconds", "chain", "host", "status")
+	if err != nil {
+		return nil, err
+	}
+
+	err = metricsClient.NewCounter("pool", "block_submits_total", env,
+		"The number of block submissions to each node host", "chain", "host", "status")
+	if err != nil {
+		return nil, err
+	}
+
+	err = metricsClient.NewHistogram("pool", "node_request_duration_ms", env,
+		"The duration of requests to each node host in milliseconds", "chain", "host", "method")
+	if err != nil {
+		return nil, err
+	}
+
+	err = metricsClient.NewCounter("pool", "node_requests_total", env,
+		"The number of requests to each node host", "chain", "host", "method", "status")
+	if err != nil {
+		return nil, err
+	}
+
+	err = metricsClient.NewGauge("pool", "node_breaker_state", env,
+		"The circuit breaker state of each node host (0 closed, 1 open, 2 half-open)", "chain", "host")
+	if err != nil {
+		return nil, err
+	}
+
 	return metricsClient, nil
 }
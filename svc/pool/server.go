@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/magicpool-co/pool/app/pool"
 	"github.com/magicpool-co/pool/internal/log"
@@ -10,10 +11,15 @@ import (
 	"github.com/magicpool-co/pool/internal/pooldb"
 	"github.com/magicpool-co/pool/internal/redis"
 	"github.com/magicpool-co/pool/internal/telegram"
+	"github.com/magicpool-co/pool/pkg/hostpool"
 	"github.com/magicpool-co/pool/pkg/sshtunnel"
+	"github.com/magicpool-co/pool/svc"
+	"github.com/magicpool-co/pool/types"
 )
 
-func initTunnel(secrets map[string]string) (*sshtunnel.SSHTunnel, error) {
+const defaultCoinbaseSignature = "/MagicPool/"
+
+func initTunnel(secrets map[string]string, logger *log.Logger) (*sshtunnel.SSHTunnel, error) {
 	keys := []string{"TUNNEL_USER", "TUNNEL_HOST", "TUNNEL_KEYPAIR"}
 	for _, k := range keys {
 		if _, ok := secrets[k]; !ok {
@@ -30,7 +36,25 @@ func initTunnel(secrets map[string]string) (*sshtunnel.SSHTunnel, error) {
 		return nil, err
 	}
 
-	tunnel, err := sshtunnel.New(secrets["TUNNEL_USER"], secrets["TUNNEL_HOST"], keyFile)
+	// the host key is verified against a known_hosts file, pinned fingerprints, or both
+	var fingerprints []string
+	if len(secrets["TUNNEL_HOST_FINGERPRINTS"]) > 0 {
+		fingerprints = strings.Split(secrets["TUNNEL_HOST_FINGERPRINTS"], ",")
+	}
+
+	hostKeyCallback, err := sshtunnel.HostKeyCallback(secrets["TUNNEL_KNOWN_HOSTS"], fingerprints)
+	if err != nil {
+		return nil, err
+	}
+
+	// multiple bastions can be given as a comma separated list, in order of preference
+	tunnel, err := sshtunnel.New(sshtunnel.Options{
+		User:            secrets["TUNNEL_USER"],
+		Hosts:           strings.Split(secrets["TUNNEL_HOST"], ","),
+		Auth:            keyFile,
+		HostKeyCallback: hostKeyCallback,
+		Logger:          logger,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -38,10 +62,62 @@ func initTunnel(secrets map[string]string) (*sshtunnel.SSHTunnel, error) {
 	return tunnel, nil
 }
 
+func initCoinbasePolicy(secrets map[string]string, chain string, miningNode types.MiningNode) error {
+	signature, ok := secrets[chain+"_COINBASE_SIGNATURE"]
+	if !ok {
+		signature = defaultCoinbaseSignature
+	}
+
+	extraOutputs, err := types.ParseCoinbaseOutputs(secrets[chain+"_COINBASE_OUTPUTS"])
+	if err != nil {
+		return err
+	}
+
+	policyNode, ok := miningNode.(types.CoinbasePolicyNode)
+	if !ok {
+		if len(extraOutputs) > 0 {
+			return fmt.Errorf("%s does not support coinbase outputs", chain)
+		}
+		return nil
+	}
+
+	policy := &types.CoinbasePolicy{
+		Signature:    signature,
+		ExtraOutputs: extraOutputs,
+	}
+
+	return policyNode.SetCoinbasePolicy(policy)
+}
+
+func initBroadcastMetrics(chain string, miningNode types.MiningNode, metricsClient *metrics.Client) {
+	broadcastNode, ok := miningNode.(types.BroadcastNode)
+	if !ok || metricsClient == nil {
+		return
+	}
+
+	broadcastNode.SetBroadcastHandler(func(results []*hostpool.BroadcastResult) {
+		for _, result := range results {
+			if result == nil {
+				continue
+			}
+
+			status := "accepted"
+			if result.Err != nil {
+				status = "rejected"
+			}
+
+			latency := float64(result.Latency.Microseconds()) / 1000
+			metricsClient.ObserveHistogram("block_submit_duration_ms", latency, chain, result.HostID, status)
+			metricsClient.IncrementCounter("block_submits_total", chain, result.HostID, status)
+		}
+	})
+}
+
 func newPool(
 	secrets map[string]string,
 	mainnet bool,
 	opts *pool.Options,
+	nodeHosts []string,
 	metricsClient *metrics.Client,
 ) (*pool.Pool, *log.Logger, error) {
 	telegramClient, err := telegram.New(secrets)
@@ -66,14 +142,18 @@ func newPool(
 		return nil, nil, err
 	}
 
-	tunnel, err := initTunnel(secrets)
+	tunnel, err := initTunnel(secrets, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	urls, err := pooldb.GetNodeURLsByChain(dbClient.Reader(), opts.Chain, mainnet)
-	if err != nil {
-		return nil, nil, err
+	// node hosts from the config take precedence over the database
+	urls := append([]string{}, nodeHosts...)
+	if len(urls) == 0 {
+		urls, err = pooldb.GetNodeURLsByChain(dbClient.Reader(), opts.Chain, mainnet)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	for i, url := range urls {
@@ -92,6 +172,12 @@ func newPool(
 		metricsClient.AddHandler("/hostinfo", miningNode.HandleHostPoolInfoRequest)
 	}
 
+	if err := initCoinbasePolicy(secrets, opts.Chain, miningNode); err != nil {
+		return nil, nil, err
+	}
+	initBroadcastMetrics(opts.Chain, miningNode, metricsClient)
+	svc.InitHostMetrics(opts.Chain, miningNode, metricsClient)
+
 	poolServer, err := pool.New(miningNode, dbClient, redisClient, logger, telegramClient, metricsClient, opts)
 	if err != nil {
 		return nil, nil, err
@@ -3,9 +3,12 @@ package main
 import (
 	"flag"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/magicpool-co/pool/app/worker"
 	"github.com/magicpool-co/pool/core/trade"
+	"github.com/magicpool-co/pool/internal/config"
 	"github.com/magicpool-co/pool/internal/log"
 	"github.com/magicpool-co/pool/internal/metrics"
 	"github.com/magicpool-co/pool/internal/node"
@@ -19,12 +22,9 @@ import (
 	"github.com/magicpool-co/pool/types"
 )
 
-var (
-	miningChains = []string{"ERG", "ETC", "FIRO", "FLUX", "KAS", "NEXA", "RVN"}
-	payoutChains = []string{"BTC", "ETH"}
-)
+const configReloadInterval = time.Second * 10
 
-func initTunnel(secrets map[string]string) (*sshtunnel.SSHTunnel, error) {
+func initTunnel(secrets map[string]string, logger *log.Logger) (*sshtunnel.SSHTunnel, error) {
 	keys := []string{"TUNNEL_USER", "TUNNEL_HOST", "TUNNEL_KEYPAIR"}
 	for _, k := range keys {
 		if _, ok := secrets[k]; !ok {
@@ -41,7 +41,25 @@ func initTunnel(secrets map[string]string) (*sshtunnel.SSHTunnel, error) {
 		return nil, err
 	}
 
-	tunnel, err := sshtunnel.New(secrets["TUNNEL_USER"], secrets["TUNNEL_HOST"], keyFile)
+	// the host key is verified against a known_hosts file, pinned fingerprints, or both
+	var fingerprints []string
+	if len(secrets["TUNNEL_HOST_FINGERPRINTS"]) > 0 {
+		fingerprints = strings.Split(secrets["TUNNEL_HOST_FINGERPRINTS"], ",")
+	}
+
+	hostKeyCallback, err := sshtunnel.HostKeyCallback(secrets["TUNNEL_KNOWN_HOSTS"], fingerprints)
+	if err != nil {
+		return nil, err
+	}
+
+	// multiple bastions can be given as a comma separated list, in order of preference
+	tunnel, err := sshtunnel.New(sshtunnel.Options{
+		User:            secrets["TUNNEL_USER"],
+		Hosts:           strings.Split(secrets["TUNNEL_HOST"], ","),
+		Auth:            keyFile,
+		HostKeyCallback: hostKeyCallback,
+		Logger:          logger,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -49,9 +67,49 @@ func initTunnel(secrets map[string]string) (*sshtunnel.SSHTunnel, error) {
 	return tunnel, nil
 }
 
+func initExchanges(cfg *config.Config) ([]types.Exchange, error) {
+	exchanges := make([]types.Exchange, len(cfg.Worker.Exchanges))
+	for i, exchangeCfg := range cfg.Worker.Exchanges {
+		exchangeID, err := config.GetExchangeID(exchangeCfg.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		exchanges[i], err = trade.NewExchange(exchangeID, exchangeCfg.APIKey,
+			exchangeCfg.APISecret, exchangeCfg.APIPassphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return exchanges, nil
+}
+
+// initDisperse sets the configured disperse contract of a chain on its node, which
+// checks that the contract exists. Without one, the chain has no batch payouts.
+func initDisperse(cfg *config.Config, chain string, payoutNode types.PayoutNode) error {
+	address, ok := cfg.Worker.DisperseAddresses[chain]
+	if !ok {
+		return nil
+	}
+
+	batchNode, ok := payoutNode.(types.BatchPayoutNode)
+	if !ok {
+		return fmt.Errorf("%s: disperse address set, but batch payouts are not supported", chain)
+	}
+
+	err := batchNode.SetDisperseAddress(address)
+	if err != nil {
+		return fmt.Errorf("%s: %v", chain, err)
+	}
+
+	return nil
+}
+
 func newWorker(
 	secrets map[string]string,
 	mainnet bool,
+	cfg *config.Config,
 	metricsClient *metrics.Client,
 ) (*worker.Worker, *log.Logger, error) {
 	telegramClient, err := telegram.New(secrets)
@@ -78,40 +136,45 @@ func newWorker(
 		return nil, nil, err
 	}
 
-	redisClient, err := redis.New(secrets)
+	shareStore, err := svc.NewShareStore(cfg, tsdbClient, false)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	awsClient, err := aws.NewSession(secrets["AWS_REGION"], secrets["AWS_PROFILE"])
+	redisClient, err := redis.New(secrets)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	kucoin, err := trade.NewExchange(types.KucoinID, secrets["KUCOIN_API_KEY"],
-		secrets["KUCOIN_API_SECRET"], secrets["KUCOIN_API_PASSPHRASE"])
+	awsClient, err := aws.NewSession(secrets["AWS_REGION"], secrets["AWS_PROFILE"])
 	if err != nil {
 		return nil, nil, err
 	}
 
-	mexc, err := trade.NewExchange(types.MEXCGlobalID, secrets["MEXC_API_KEY"], secrets["MEXC_API_SECRET"], "")
+	exchanges, err := initExchanges(cfg)
 	if err != nil {
 		return nil, nil, err
 	}
-	exchanges := []types.Exchange{kucoin, mexc}
 
-	tunnel, err := initTunnel(secrets)
+	tunnel, err := initTunnel(secrets, logger)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	miningNodes := make([]types.MiningNode, 0)
 	payoutNodes := make([]types.PayoutNode, 0)
-	for _, chain := range miningChains {
-		urls, err := pooldb.GetNodeURLsByChain(pooldbClient.Reader(), chain, mainnet)
-		if err != nil {
-			return nil, nil, err
-		} else if len(urls) == 0 {
+	miningNodeIdx := make(map[string]bool)
+	for _, chain := range cfg.Worker.MiningChains {
+		// node hosts from the config take precedence over the database
+		urls := append([]string{}, cfg.GetNodes(chain)...)
+		if len(urls) == 0 {
+			urls, err = pooldb.GetNodeURLsByChain(pooldbClient.Reader(), chain, mainnet)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if len(urls) == 0 {
 			logger.Info(fmt.Sprintf("ignoring %s, no node hosts found", chain))
 			continue
 		}
@@ -128,22 +191,49 @@ func newWorker(
 		if err != nil {
 			return nil, nil, err
 		}
+		err = initDisperse(cfg, chain, node)
+		if err != nil {
+			return nil, nil, err
+		}
+		svc.InitHostMetrics(chain, node, metricsClient)
 		miningNodes = append(miningNodes, node)
 		payoutNodes = append(payoutNodes, node)
+		miningNodeIdx[chain] = true
 	}
 
-	for _, chain := range payoutChains {
+	for _, chain := range cfg.Worker.PayoutChains {
+		// chains that are also mined (BTC) pay out through the mining node
+		if miningNodeIdx[chain] {
+			continue
+		}
+
 		priv := secrets[chain+"_PRIVATE_KEY"]
 		url := secrets[chain+"_NODE_URL"]
+		if nodes := cfg.GetNodes(chain); len(nodes) > 0 {
+			url = nodes[0]
+		}
 		blockchairKey := secrets["BLOCKCHAIR_API_KEY"]
 		node, err := node.GetPayoutNode(mainnet, chain, priv, blockchairKey, url, logger)
 		if err != nil {
 			return nil, nil, err
 		}
+
+		err = initDisperse(cfg, chain, node)
+		if err != nil {
+			return nil, nil, err
+		}
 		payoutNodes = append(payoutNodes, node)
 	}
 
-	workerClient, err := worker.NewWorker(secrets["ENVIRONMENT"], mainnet, logger, miningNodes, payoutNodes,
+	opts := &worker.Options{
+		AutoExclude:        cfg.Worker.ShareAudit.AutoExclude,
+		PoolFeeBasisPoints: cfg.Fees.PoolFeeBasisPoints,
+		ReorgAlertDepths:   cfg.Alerts.ReorgDepth,
+		Schedules:          cfg.Worker.Schedules,
+		ShareStore:         shareStore,
+	}
+
+	workerClient, err := worker.NewWorker(secrets["ENVIRONMENT"], mainnet, opts, logger, miningNodes, payoutNodes,
 		pooldbClient, tsdbClient, redisClient, awsClient, metricsClient, exchanges, telegramClient)
 
 	return workerClient, logger, err
@@ -152,21 +242,27 @@ func newWorker(
 func main() {
 	argMainnet := flag.Bool("mainnet", true, "Whether or not to run on the mainnet")
 	argSecretVar := flag.String("secret", "", "ENV variable defined by ECS")
-	argMetricsPort := flag.Int("metrics-port", 6060, "The metrics port to use")
+	argConfig := flag.String("config", "", "The config file to use (the default config if empty)")
+	argCheckConfig := flag.Bool("check-config", false, "Validate and print the config, then exit")
 
 	flag.Parse()
 
 	secrets, err := svc.ParseSecrets(*argSecretVar)
+	if err != nil && !*argCheckConfig {
+		panic(err)
+	}
+
+	cfg, err := svc.LoadConfig(*argConfig, *argCheckConfig, secrets)
 	if err != nil {
 		panic(err)
 	}
 
-	metricsClient, err := initMetrics(secrets["ENVIRONMENT"], *argMetricsPort)
+	metricsClient, err := initMetrics(secrets["ENVIRONMENT"], cfg.Worker.MetricsPort)
 	if err != nil {
 		panic(err)
 	}
 
-	workerServer, logger, err := newWorker(secrets, *argMainnet, metricsClient)
+	workerServer, logger, err := newWorker(secrets, *argMainnet, cfg, metricsClient)
 	if err != nil {
 		panic(err)
 	}
@@ -176,5 +272,28 @@ func main() {
 	runner := svc.NewRunner(logger)
 	runner.AddWorker(workerServer)
 	runner.AddHTTPServer(metricsClient.Server())
+
+	// the operator endpoint is only served if an admin token is set
+	if token := secrets["WORKER_ADMIN_TOKEN"]; len(token) > 0 {
+		adminServer, err := workerServer.NewAdminServer(cfg.Worker.AdminPort, token)
+		if err != nil {
+			panic(err)
+		}
+		runner.AddHTTPServer(adminServer)
+	} else {
+		logger.Info("worker admin endpoint disabled, no admin token")
+	}
+
+	if len(*argConfig) > 0 {
+		watcher, err := config.NewWatcher(*argConfig, secrets, cfg, configReloadInterval, logger,
+			func(cfg *config.Config) {
+				workerServer.SetReorgAlertDepths(cfg.Alerts.ReorgDepth)
+			})
+		if err != nil {
+			panic(err)
+		}
+		runner.AddWorker(watcher)
+	}
+
 	runner.Run()
 }
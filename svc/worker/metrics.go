@@ -12,5 +12,47 @@ func initMetrics(env string, port int) (*metrics.Client, error) {
 		return nil, err
 	}
 
+	err = metricsClient.NewHistogram("worker", "node_request_duration_ms", env,
+		"The duration of requests to each node host in milliseconds", "chain", "host", "method")
+	if err != nil {
+		return nil, err
+	}
+
+	err = metricsClient.NewCounter("worker", "node_requests_total", env,
+		"The number of requests to each node host", "chain", "host", "method", "status")
+	if err != nil {
+		return nil, err
+	}
+
+	err = metricsClient.NewGauge("worker", "node_breaker_state", env,
+		"The circuit breaker state of each node host (0 closed, 1 open, 2 half-open)", "chain", "host")
+	if err != nil {
+		return nil, err
+	}
+
+	err = metricsClient.NewCounter("worker", "job_runs_total", env,
+		"The number of runs of each job by outcome", "job", "status")
+	if err != nil {
+		return nil, err
+	}
+
+	err = metricsClient.NewHistogram("worker", "job_duration_seconds", env,
+		"The duration of each job run in seconds", "job")
+	if err != nil {
+		return nil, err
+	}
+
+	err = metricsClient.NewGauge("worker", "job_paused", env,
+		"Whether or not each job is paused (0 running, 1 paused)", "job")
+	if err != nil {
+		return nil, err
+	}
+
+	err = metricsClient.NewGauge("worker", "job_last_success_timestamp", env,
+		"The unix timestamp of the last successful run of each job", "job")
+	if err != nil {
+		return nil, err
+	}
+
 	return metricsClient, nil
 }
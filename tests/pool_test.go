@@ -250,6 +250,61 @@ func (suite *PoolSuite) TestPool() {
 				common.MustMarshalJSON(false),
 			},
 		},
+		{
+			chain: "ETC", // EthereumStratum/1.0.0 testing
+			priv:  "03620b2ed304234abe4f02e4f95ece19626989351487c0f93821e4827ed1301e",
+			opts: &pool.Options{
+				Chain:          "ETC",
+				PortDiffIdx:    map[int]int{0: 1},
+				WindowSize:     100000,
+				ExtraNonceSize: 2,
+				JobListSize:    10,
+				PollingPeriod:  time.Millisecond * 100,
+			},
+			handshake: []*rpc.Request{
+				// the protocol is identified by the second parameter, the pool answers
+				// with the extranonce, a mining.set_difficulty and a mining.notify
+				rpc.MustNewRequest("mining.subscribe", "ethminer/0.19.0", "EthereumStratum/1.0.0"),
+				rpc.MustNewRequest("mining.authorize",
+					"eth:0x0000000000000000000000000000000000000000.worker",
+					"x",
+				),
+			},
+			requests: []*rpc.Request{
+				rpc.MustNewRequest("eth_submitHashrate",
+					"0x000000000000000000000000025fc9f3",
+					"0x8f4c405730375083a74b95eee0ff1ab1d472f176a13f115af1d553408a9add5d",
+				),
+				// a submission consists of:
+				// 	- worker id
+				//	- job id (the header hash)
+				// 	- nonce (w/o extranonce)
+				// the mocked extranonce is "ffff", so the share is below the share difficulty
+				rpc.MustNewRequest("mining.submit",
+					"eth:0x0000000000000000000000000000000000000000.worker",
+					"48b9e2560c8263614076d943d2c848044604b6e43c2423ed670ea5cc18b6edd8",
+					"25a6eeb65f92",
+				),
+				// test unknown job
+				rpc.MustNewRequest("mining.submit",
+					"eth:0x0000000000000000000000000000000000000000.worker",
+					"0000000000000000000000000000000000000000000000000000000000000000",
+					"25a6eeb65f92",
+				),
+				// test malformed nonce
+				rpc.MustNewRequest("mining.submit",
+					"eth:0x0000000000000000000000000000000000000000.worker",
+					"48b9e2560c8263614076d943d2c848044604b6e43c2423ed670ea5cc18b6edd8",
+					"25a6eeb6",
+				),
+			},
+			responses: [][]byte{
+				common.MustMarshalJSON(true),
+				common.MustMarshalJSON(false),
+				common.MustMarshalJSON(false),
+				common.MustMarshalJSON(false),
+			},
+		},
 		{
 			chain: "FIRO",
 			priv:  "03620b2ed304234abe4f02e4f95ece19626989351487c0f93821e4827ed1301e",
@@ -56,6 +56,11 @@ func (suite *PooldbReadsSuite) TestReadMiner() {
 		suite.T().Errorf("failed: GetMiners: %v", err)
 	}
 
+	_, err = pooldb.GetMinersByChain(pooldbClient.Reader(), "ETC")
+	if err != nil {
+		suite.T().Errorf("failed: GetMinersByChain: %v", err)
+	}
+
 	_, err = pooldb.GetMinersWithLastShares(pooldbClient.Reader(), []uint64{1, 2, 3})
 	if err != nil {
 		suite.T().Errorf("failed: GetMinersWithLastShares: %v", err)
@@ -132,6 +137,11 @@ func (suite *PooldbReadsSuite) TestReadRound() {
 		suite.T().Errorf("failed: GetRoundsByChain: %v", err)
 	}
 
+	_, err = pooldb.GetRoundsByChainAndTimeRange(pooldbClient.Reader(), "ETH", time.Now(), time.Now())
+	if err != nil {
+		suite.T().Errorf("failed: GetRoundsByChainAndTimeRange: %v", err)
+	}
+
 	_, err = pooldb.GetRoundsCount(pooldbClient.Reader())
 	if err != nil {
 		suite.T().Errorf("failed: GetRoundsCount: %v", err)
@@ -167,6 +177,11 @@ func (suite *PooldbReadsSuite) TestReadRound() {
 		suite.T().Errorf("failed: GetUnspentRoundsByChain: %v", err)
 	}
 
+	_, err = pooldb.GetRevalidationRoundsByChain(pooldbClient.Reader(), "ETC", 100000)
+	if err != nil {
+		suite.T().Errorf("failed: GetRevalidationRoundsByChain: %v", err)
+	}
+
 	_, err = pooldb.GetRoundLuckByChain(pooldbClient.Reader(), "ETC", true, time.Hour*24*30)
 	if err != nil {
 		suite.T().Errorf("failed: GetRoundLuckByChain: %v", err)
@@ -195,6 +210,11 @@ func (suite *PooldbReadsSuite) TestReadUTXO() {
 		suite.T().Errorf("failed: GetUTXOsByTransactionID: %v", err)
 	}
 
+	_, err = pooldb.GetUTXOsByTxIDs(pooldbClient.Reader(), "ETH", []string{"0x0", "0x1"})
+	if err != nil {
+		suite.T().Errorf("failed: GetUTXOsByTxIDs: %v", err)
+	}
+
 	_, err = pooldb.GetSumUnspentUTXOValueByChain(pooldbClient.Reader(), "ETH")
 	if err != nil {
 		suite.T().Errorf("failed: GetSumUnspentUTXOValueByChain: %v", err)
@@ -247,6 +267,16 @@ func (suite *PooldbReadsSuite) TestReadExchangeBatch() {
 	if err != nil {
 		suite.T().Errorf("failed: GetActiveExchangeBatches: %v", err)
 	}
+
+	_, err = pooldb.GetExchangeBatches(pooldbClient.Reader(), 0, 10)
+	if err != nil {
+		suite.T().Errorf("failed: GetExchangeBatches: %v", err)
+	}
+
+	_, err = pooldb.GetExchangeBatchesCount(pooldbClient.Reader())
+	if err != nil {
+		suite.T().Errorf("failed: GetExchangeBatchesCount: %v", err)
+	}
 }
 
 func (suite *PooldbReadsSuite) TestReadExchangeInput() {
@@ -357,6 +387,11 @@ func (suite *PooldbReadsSuite) TestReadBalanceOutput() {
 		suite.T().Errorf("failed: GetBalanceOutputsByBatch: %v", err)
 	}
 
+	_, err = pooldb.GetBalanceOutputsByRound(pooldbClient.Reader(), 1)
+	if err != nil {
+		suite.T().Errorf("failed: GetBalanceOutputsByRound: %v", err)
+	}
+
 	_, err = pooldb.GetBalanceOutputsByPayout(pooldbClient.Reader(), 1)
 	if err != nil {
 		suite.T().Errorf("failed: GetBalanceOutputsByPayout: %v", err)
@@ -386,6 +421,16 @@ func (suite *PooldbReadsSuite) TestReadBalanceOutput() {
 	if err != nil {
 		suite.T().Errorf("failed: GetMinersWithBalanceAboveThresholdByChain: %v", err)
 	}
+
+	_, err = pooldb.GetMinersWithPayoutScheduleByChain(pooldbClient.Reader(), "ETH", "10")
+	if err != nil {
+		suite.T().Errorf("failed: GetMinersWithPayoutScheduleByChain: %v", err)
+	}
+
+	_, err = pooldb.GetMinersWithHeldBalanceByChain(pooldbClient.Reader(), "ETH")
+	if err != nil {
+		suite.T().Errorf("failed: GetMinersWithHeldBalanceByChain: %v", err)
+	}
 }
 
 func (suite *PooldbReadsSuite) TestReadBalanceSum() {
@@ -405,6 +450,11 @@ func (suite *PooldbReadsSuite) TestReadPayout() {
 		suite.T().Errorf("failed: GetUnconfirmedPayouts: %v", err)
 	}
 
+	_, err = pooldb.GetPayoutsByTransaction(pooldbClient.Reader(), 1)
+	if err != nil {
+		suite.T().Errorf("failed: GetPayoutsByTransaction: %v", err)
+	}
+
 	_, err = pooldb.GetUnconfirmedPayoutSum(pooldbClient.Reader(), "ETH")
 	if err != nil {
 		suite.T().Errorf("failed: GetUnconfirmedPayoutSum: %v", err)
@@ -435,3 +485,84 @@ func (suite *PooldbReadsSuite) TestReadPayout() {
 		suite.T().Errorf("failed: GetPayoutBalanceInputSums: %v", err)
 	}
 }
+
+func (suite *PooldbReadsSuite) TestReadReserveProof() {
+	var err error
+
+	_, err = pooldb.GetBalanceSumsByChain(pooldbClient.Reader(), "ETH")
+	if err != nil {
+		suite.T().Errorf("failed: GetBalanceSumsByChain: %v", err)
+	}
+
+	_, err = pooldb.GetComputedBalanceSumsByChain(pooldbClient.Reader(), "ETH")
+	if err != nil {
+		suite.T().Errorf("failed: GetComputedBalanceSumsByChain: %v", err)
+	}
+
+	_, err = pooldb.GetLastReserveProofs(pooldbClient.Reader())
+	if err != nil {
+		suite.T().Errorf("failed: GetLastReserveProofs: %v", err)
+	}
+
+	_, err = pooldb.GetReserveProofLeaves(pooldbClient.Reader(), 1)
+	if err != nil {
+		suite.T().Errorf("failed: GetReserveProofLeaves: %v", err)
+	}
+}
+
+func (suite *PooldbReadsSuite) TestReadMinerShareAudit() {
+	var err error
+
+	_, err = pooldb.GetMinerShareAuditsByChain(pooldbClient.Reader(), "ETC")
+	if err != nil {
+		suite.T().Errorf("failed: GetMinerShareAuditsByChain: %v", err)
+	}
+
+	_, err = pooldb.GetFlaggedMinerShareAudits(pooldbClient.Reader())
+	if err != nil {
+		suite.T().Errorf("failed: GetFlaggedMinerShareAudits: %v", err)
+	}
+
+	_, err = pooldb.GetExcludedMinerShareAuditsByChain(pooldbClient.Reader(), "ETC")
+	if err != nil {
+		suite.T().Errorf("failed: GetExcludedMinerShareAuditsByChain: %v", err)
+	}
+
+	_, err = pooldb.GetBannedMinerShareAuditsByChain(pooldbClient.Reader(), "ETC")
+	if err != nil {
+		suite.T().Errorf("failed: GetBannedMinerShareAuditsByChain: %v", err)
+	}
+}
+
+func (suite *PooldbReadsSuite) TestReadWorkerJob() {
+	var err error
+
+	_, err = pooldb.GetWorkerJobs(pooldbClient.Reader())
+	if err != nil {
+		suite.T().Errorf("failed: GetWorkerJobs: %v", err)
+	}
+
+	_, err = pooldb.GetWorkerJob(pooldbClient.Reader(), "payout")
+	if err != nil {
+		suite.T().Errorf("failed: GetWorkerJob: %v", err)
+	}
+
+	_, err = pooldb.GetWorkerJobRuns(pooldbClient.Reader(), "payout", 10)
+	if err != nil {
+		suite.T().Errorf("failed: GetWorkerJobRuns: %v", err)
+	}
+}
+
+func (suite *PooldbReadsSuite) TestReadAdminAuditLog() {
+	var err error
+
+	_, err = pooldb.GetAdminAuditLogs(pooldbClient.Reader(), 0, 10)
+	if err != nil {
+		suite.T().Errorf("failed: GetAdminAuditLogs: %v", err)
+	}
+
+	_, err = pooldb.GetAdminAuditLogsCount(pooldbClient.Reader())
+	if err != nil {
+		suite.T().Errorf("failed: GetAdminAuditLogsCount: %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/magicpool-co/pool/internal/pooldb"
 	"github.com/magicpool-co/pool/pkg/dbcl"
+	"github.com/magicpool-co/pool/types"
 )
 
 type PooldbWritesSuite struct {
@@ -60,8 +61,8 @@ func (suite *PooldbWritesSuite) TestWriteMiner() {
 			suite.T().Errorf("failed on %d: insert: %v", i, err)
 		}
 
-		cols := []string{"email", "threshold", "active",
-			"enabled_worker_notifications", "enabled_payout_notifications"}
+		cols := []string{"email", "threshold", "payout_schedule", "payout_hour", "payout_weekday",
+			"payout_time", "payout_requested", "active", "enabled_worker_notifications", "enabled_payout_notifications"}
 		err = pooldb.UpdateMiner(pooldbClient.Writer(), tt.miner, cols)
 		if err != nil {
 			suite.T().Errorf("failed on %d: update %v", i, err)
@@ -306,6 +307,11 @@ func (suite *PooldbWritesSuite) TestWriteExchangeInput() {
 		if err != nil {
 			suite.T().Errorf("failed on %d: insert: %v", i, err)
 		}
+
+		err = pooldb.DeleteExchangeInputsByBatch(pooldbClient.Writer(), batchID)
+		if err != nil {
+			suite.T().Errorf("failed on %d: DeleteExchangeInputsByBatch: %v", i, err)
+		}
 	}
 }
 
@@ -448,6 +454,11 @@ func (suite *PooldbWritesSuite) TestWriteBalanceInput() {
 			suite.T().Errorf("failed on %d: update: %v", i, err)
 		}
 
+		err = pooldb.UpdateBalanceInputsUnsetBatch(pooldbClient.Writer(), 0)
+		if err != nil {
+			suite.T().Errorf("failed on %d: UpdateBalanceInputsUnsetBatch: %v", i, err)
+		}
+
 		err = pooldb.UpdateBalanceInputsSetMatureByRound(pooldbClient.Writer(), 0)
 		if err != nil {
 			suite.T().Errorf("failed on %d: UpdateBalanceInputsSetMatureByRound: %v", i, err)
@@ -537,6 +548,11 @@ func (suite *PooldbWritesSuite) TestWriteBalanceSum() {
 		if err != nil {
 			suite.T().Errorf("failed on %d: insert subtract: %v", i, err)
 		}
+
+		err = pooldb.InsertOverwriteBalanceSums(pooldbClient.Writer(), tt.output)
+		if err != nil {
+			suite.T().Errorf("failed on %d: insert overwrite: %v", i, err)
+		}
 	}
 }
 
@@ -574,3 +590,180 @@ func (suite *PooldbWritesSuite) TestWritePayout() {
 		}
 	}
 }
+
+func (suite *PooldbWritesSuite) TestWriteReserveProof() {
+	tests := []struct {
+		proof  *pooldb.ReserveProof
+		leaves []*pooldb.ReserveProofLeaf
+	}{
+		{
+			&pooldb.ReserveProof{
+				ChainID:       "ETC",
+				MerkleRoot:    "abcd",
+				Nonce:         "ef01",
+				LeafCount:     1,
+				Liabilities:   dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(5)},
+				WalletAddress: "0x0",
+				WalletBalance: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(5)},
+				Message:       "ETC proof of reserves",
+			},
+			[]*pooldb.ReserveProofLeaf{
+				&pooldb.ReserveProofLeaf{
+					Idx:   0,
+					Value: dbcl.NullBigInt{Valid: true, BigInt: new(big.Int).SetUint64(5)},
+				},
+			},
+		},
+	}
+
+	minerID, err := pooldb.InsertMiner(pooldbClient.Writer(), &pooldb.Miner{ChainID: "ETH", Address: "6"})
+	if err != nil {
+		suite.T().Errorf("failed on preliminary miner insert: %v", err)
+	}
+
+	for i, tt := range tests {
+		proofID, err := pooldb.InsertReserveProof(pooldbClient.Writer(), tt.proof)
+		if err != nil {
+			suite.T().Errorf("failed on %d: insert: %v", i, err)
+		}
+
+		for _, leaf := range tt.leaves {
+			leaf.ReserveProofID = proofID
+			leaf.MinerID = minerID
+		}
+
+		err = pooldb.InsertReserveProofLeaves(pooldbClient.Writer(), tt.leaves...)
+		if err != nil {
+			suite.T().Errorf("failed on %d: insert leaves: %v", i, err)
+		}
+	}
+}
+
+func (suite *PooldbWritesSuite) TestWriteMinerShareAudit() {
+	tests := []struct {
+		audit *pooldb.MinerShareAudit
+	}{
+		{
+			&pooldb.MinerShareAudit{
+				ChainID:                 "ETC",
+				AcceptedShares:          1000,
+				HighDiffShares:          125,
+				NearBlockShares:         2,
+				EarlyShares:             10,
+				FoundBlocks:             1,
+				ExpectedBlocks:          0.5,
+				ExpectedNearBlockShares: 8,
+			},
+		},
+	}
+
+	minerID, err := pooldb.InsertMiner(pooldbClient.Writer(), &pooldb.Miner{ChainID: "ETC", Address: "7"})
+	if err != nil {
+		suite.T().Errorf("failed on preliminary miner insert: %v", err)
+	}
+
+	for i, tt := range tests {
+		tt.audit.MinerID = minerID
+		err = pooldb.InsertAddMinerShareAudits(pooldbClient.Writer(), tt.audit)
+		if err != nil {
+			suite.T().Errorf("failed on %d: insert: %v", i, err)
+		}
+
+		tt.audit.Flagged = true
+		tt.audit.FlagReason = types.StringPtr("test")
+		err = pooldb.UpdateMinerShareAudit(pooldbClient.Writer(), tt.audit, []string{"flagged", "excluded", "flag_reason"})
+		if err != nil {
+			suite.T().Errorf("failed on %d: update: %v", i, err)
+		}
+
+		tt.audit.Banned = true
+		err = pooldb.UpdateMinerShareAudit(pooldbClient.Writer(), tt.audit, []string{"banned"})
+		if err != nil {
+			suite.T().Errorf("failed on %d: ban: %v", i, err)
+		}
+	}
+}
+
+func (suite *PooldbWritesSuite) TestWriteWorkerJob() {
+	tests := []struct {
+		job *pooldb.WorkerJob
+		run *pooldb.WorkerJobRun
+	}{
+		{
+			&pooldb.WorkerJob{
+				Name:   "payout",
+				Paused: true,
+			},
+			&pooldb.WorkerJobRun{
+				JobName:    "payout",
+				Status:     "running",
+				LockHolder: types.StringPtr("worker:1"),
+				StartedAt:  time.Now(),
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		err := pooldb.InsertUpdateWorkerJob(pooldbClient.Writer(), tt.job, []string{"paused"})
+		if err != nil {
+			suite.T().Errorf("failed on %d: insert job: %v", i, err)
+		}
+
+		tt.job.Schedule = types.StringPtr("*/10 * * * *")
+		err = pooldb.InsertUpdateWorkerJob(pooldbClient.Writer(), tt.job, []string{"schedule"})
+		if err != nil {
+			suite.T().Errorf("failed on %d: update job: %v", i, err)
+		}
+
+		tt.run.ID, err = pooldb.InsertWorkerJobRun(pooldbClient.Writer(), tt.run)
+		if err != nil {
+			suite.T().Errorf("failed on %d: insert run: %v", i, err)
+		}
+
+		tt.run.Status = "failed"
+		tt.run.ErrorCount = 1
+		tt.run.ErrorMessage = types.StringPtr("test")
+		tt.run.EndedAt = types.TimePtr(time.Now())
+		updateCols := []string{"status", "error_count", "error_message", "ended_at"}
+		err = pooldb.UpdateWorkerJobRun(pooldbClient.Writer(), tt.run, updateCols)
+		if err != nil {
+			suite.T().Errorf("failed on %d: update run: %v", i, err)
+		}
+
+		err = pooldb.DeleteWorkerJobRunsBefore(pooldbClient.Writer(), time.Now().Add(-time.Hour))
+		if err != nil {
+			suite.T().Errorf("failed on %d: delete runs: %v", i, err)
+		}
+	}
+}
+
+func (suite *PooldbWritesSuite) TestWriteAdminAuditLog() {
+	tests := []struct {
+		log *pooldb.AdminAuditLog
+	}{
+		{
+			&pooldb.AdminAuditLog{
+				Actor:  "alice",
+				Role:   "operator",
+				Action: "round.orphan",
+				Target: "round:1",
+				Status: "attempted",
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		attemptID, err := pooldb.InsertAdminAuditLog(pooldbClient.Writer(), tt.log)
+		if err != nil {
+			suite.T().Errorf("failed on %d: insert attempt: %v", i, err)
+		}
+
+		tt.log.RefID = types.Uint64Ptr(attemptID)
+		tt.log.Status = "failed"
+		tt.log.ErrorMessage = types.StringPtr("test")
+		_, err = pooldb.InsertAdminAuditLog(pooldbClient.Writer(), tt.log)
+		if err != nil {
+			suite.T().Errorf("failed on %d: insert outcome: %v", i, err)
+		}
+	}
+}
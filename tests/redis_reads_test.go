@@ -158,3 +158,12 @@ func (suite *RedisReadsSuite) TestWriteCachedStats() {
 		suite.T().Errorf("failed: GetCachedWorkersByChain: %v", err)
 	}
 }
+
+func (suite *RedisReadsSuite) TestReadReorg() {
+	var err error
+
+	_, err = redisClient.GetReorgChainTips("ETC")
+	if err != nil {
+		suite.T().Errorf("failed: GetReorgChainTips: %v", err)
+	}
+}
@@ -187,3 +187,12 @@ func (suite *RedisWritesSuite) TestWriteCachedStats() {
 		suite.T().Errorf("failed: SetCachedWorkersByChain: %v", err)
 	}
 }
+
+func (suite *RedisWritesSuite) TestWriteReorg() {
+	var err error
+
+	err = redisClient.SetReorgChainTips("ETC", map[uint64]string{1: "0x"})
+	if err != nil {
+		suite.T().Errorf("failed: SetReorgChainTips: %v", err)
+	}
+}
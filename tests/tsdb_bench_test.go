@@ -0,0 +1,133 @@
+//go:build integration
+
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/magicpool-co/pool/internal/tsdb"
+	"github.com/magicpool-co/pool/types"
+)
+
+const (
+	benchMiners  = 200
+	benchRollups = 672 // a week of 15m rollups
+)
+
+func seedBenchMinerShares(store tsdb.ShareStore, start time.Time) error {
+	return store.Transact(func(tx tsdb.ShareStore) error {
+		for i := 0; i < benchRollups; i++ {
+			endTime := start.Add(time.Duration(i) * types.Period15m.Rollup())
+			shares := make([]*tsdb.Share, benchMiners)
+			for j := range shares {
+				shares[j] = &tsdb.Share{
+					ChainID:                "ETC",
+					MinerID:                types.Uint64Ptr(uint64(j + 1)),
+					Workers:                uint64(j%4 + 1),
+					AcceptedShares:         uint64(100 + i%7 + j),
+					AcceptedAdjustedShares: uint64(100 + i%7 + j),
+					RejectedShares:         uint64(i % 3),
+					RejectedAdjustedShares: uint64(i % 3),
+					Hashrate:               float64(1000*j) + float64(i%11)*10.5,
+					AvgHashrate:            float64(1000 * j),
+					Count:                  1,
+					Period:                 int(types.Period15m),
+					StartTime:              endTime.Add(-types.Period15m.Rollup()),
+					EndTime:                endTime,
+				}
+			}
+
+			if err := tx.InsertMinerShares(shares...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return err
+	})
+
+	return size, err
+}
+
+// BenchmarkShareStore compares the queries behind the miner share charts
+// (stats.GetMinerShareChart) on the mysql and columnar share stores.
+func BenchmarkShareStore(b *testing.B) {
+	if err := tsdbClient.UpgradeMigrations(); err != nil {
+		b.Fatalf("failed to upgrade tsdb migrations: %v", err)
+	}
+	defer tsdbClient.DowngradeMigrations()
+
+	dir := b.TempDir()
+	columnarStore, err := tsdb.OpenColumnarShareStore(dir, false)
+	if err != nil {
+		b.Fatalf("failed to open columnar store: %v", err)
+	}
+
+	stores := []struct {
+		name  string
+		store tsdb.ShareStore
+	}{
+		{"mysql", tsdb.NewShareStore(tsdbClient)},
+		{"columnar", columnarStore},
+	}
+
+	rollup := types.Period15m.Rollup()
+	startTime := time.Now().Truncate(rollup).Add(-rollup * benchRollups)
+	for _, tt := range stores {
+		if err := seedBenchMinerShares(tt.store, startTime); err != nil {
+			b.Fatalf("%s: failed to seed shares: %v", tt.name, err)
+		}
+	}
+
+	size, err := dirSize(dir)
+	if err != nil {
+		b.Fatalf("failed to size columnar store: %v", err)
+	}
+	b.Logf("columnar store: %d rows in %d bytes", benchMiners*benchRollups, size)
+
+	for _, tt := range stores {
+		store := tt.store
+		b.Run(tt.name+"/GetMinerShares", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				minerID := uint64(i%benchMiners + 1)
+				_, err := store.GetMinerShares([]uint64{minerID}, "ETC", int(types.Period15m))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(tt.name+"/GetMinerSharesSingleMetric", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				minerID := uint64(i%benchMiners + 1)
+				_, err := store.GetMinerSharesSingleMetric([]uint64{minerID},
+					string(types.ShareHashrate), int(types.Period15m))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(tt.name+"/GetMinersSharesLast", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				minerID := uint64(i%benchMiners + 1)
+				_, err := store.GetMinersSharesLast([]uint64{minerID}, int(types.Period15m))
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
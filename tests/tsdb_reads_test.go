@@ -178,6 +178,11 @@ func (suite *TsdbReadsSuite) TestReadShares() {
 		suite.T().Errorf("failed: GetMinerSharesByEndTime: %v", err)
 	}
 
+	_, err = tsdb.GetMinerSharesByTimeRange(tsdbClient.Reader(), "ETH", 0, time.Now(), time.Now())
+	if err != nil {
+		suite.T().Errorf("failed: GetMinerSharesByTimeRange: %v", err)
+	}
+
 	_, err = tsdb.GetWorkerShares(tsdbClient.Reader(), 1, "ETH", 1)
 	if err != nil {
 		suite.T().Errorf("failed: GetPendingGlobalSharesByEndTime: %v", err)
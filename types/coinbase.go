@@ -0,0 +1,145 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const coinbaseShareBasis = 10000
+
+// CoinbaseOutput is an extra output paid directly out of the miner
+// reward, with the share given in basis points of the reward.
+type CoinbaseOutput struct {
+	Address string
+	Share   uint64
+}
+
+// CoinbasePolicy describes the pool controlled parts of a generated coinbase:
+// the signature added to the coinbase script, any extra outputs split off the
+// miner reward and (for solo miners) the address the miner reward is paid to.
+// A nil policy is valid and leaves the coinbase untouched.
+type CoinbasePolicy struct {
+	Signature     string
+	ExtraOutputs  []*CoinbaseOutput
+	PayoutAddress string
+}
+
+// ParseCoinbaseOutputs parses outputs in the form of "address:share,address:share".
+func ParseCoinbaseOutputs(raw string) ([]*CoinbaseOutput, error) {
+	outputs := make([]*CoinbaseOutput, 0)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(part, ":")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid coinbase output: %s", part)
+		}
+
+		share, err := strconv.ParseUint(part[idx+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coinbase output share: %s", part)
+		}
+
+		outputs = append(outputs, &CoinbaseOutput{Address: part[:idx], Share: share})
+	}
+
+	return outputs, nil
+}
+
+func (p *CoinbasePolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	var total uint64
+	for _, output := range p.ExtraOutputs {
+		if output.Address == "" {
+			return fmt.Errorf("empty coinbase output address")
+		} else if output.Share == 0 {
+			return fmt.Errorf("empty coinbase output share for %s", output.Address)
+		}
+		total += output.Share
+	}
+
+	if total >= coinbaseShareBasis {
+		return fmt.Errorf("coinbase output shares exceed the miner reward: %d", total)
+	}
+
+	return nil
+}
+
+// ForMiner returns a copy of the policy that pays the miner reward to address.
+func (p *CoinbasePolicy) ForMiner(address string) *CoinbasePolicy {
+	policy := &CoinbasePolicy{PayoutAddress: address}
+	if p != nil {
+		policy.Signature = p.Signature
+		policy.ExtraOutputs = p.ExtraOutputs
+	}
+
+	return policy
+}
+
+// ForSoloMiner returns a copy of the policy that pays the miner reward to minerAddress,
+// with the pool fee split off as an extra output paid to poolAddress.
+func (p *CoinbasePolicy) ForSoloMiner(minerAddress, poolAddress string, feeShare uint64) *CoinbasePolicy {
+	policy := p.ForMiner(minerAddress)
+	if feeShare > 0 {
+		extraOutputs := make([]*CoinbaseOutput, len(policy.ExtraOutputs), len(policy.ExtraOutputs)+1)
+		copy(extraOutputs, policy.ExtraOutputs)
+		policy.ExtraOutputs = append(extraOutputs, &CoinbaseOutput{Address: poolAddress, Share: feeShare})
+	}
+
+	return policy
+}
+
+func (p *CoinbasePolicy) GetSignature() string {
+	if p == nil {
+		return ""
+	}
+	return p.Signature
+}
+
+func (p *CoinbasePolicy) GetPayoutAddress(defaultAddress string) string {
+	if p == nil || p.PayoutAddress == "" {
+		return defaultAddress
+	}
+	return p.PayoutAddress
+}
+
+func (p *CoinbasePolicy) IsExtraAddress(address string) bool {
+	if p == nil {
+		return false
+	}
+
+	for _, output := range p.ExtraOutputs {
+		if output.Address == address {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SplitReward splits the miner reward into the amount left for the miner
+// and the amounts for each of the extra outputs (rounded down).
+func (p *CoinbasePolicy) SplitReward(reward uint64) (uint64, []string, []uint64) {
+	if p == nil || len(p.ExtraOutputs) == 0 {
+		return reward, nil, nil
+	}
+
+	remainder := reward
+	addresses := make([]string, len(p.ExtraOutputs))
+	amounts := make([]uint64, len(p.ExtraOutputs))
+	for i, output := range p.ExtraOutputs {
+		addresses[i] = output.Address
+		amounts[i] = reward / coinbaseShareBasis * output.Share
+		amounts[i] += reward % coinbaseShareBasis * output.Share / coinbaseShareBasis
+		remainder -= amounts[i]
+	}
+
+	return remainder, addresses, amounts
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestCoinbasePolicySplitReward(t *testing.T) {
+	tests := []struct {
+		policy    *CoinbasePolicy
+		reward    uint64
+		remainder uint64
+		amounts   []uint64
+	}{
+		{
+			policy:    nil,
+			reward:    250000000,
+			remainder: 250000000,
+		},
+		{
+			policy: &CoinbasePolicy{
+				ExtraOutputs: []*CoinbaseOutput{{Address: "a", Share: 100}},
+			},
+			reward:    250000000,
+			remainder: 247500000,
+			amounts:   []uint64{2500000},
+		},
+		{
+			policy: &CoinbasePolicy{
+				ExtraOutputs: []*CoinbaseOutput{{Address: "a", Share: 33}, {Address: "b", Share: 1}},
+			},
+			reward:    9999,
+			remainder: 9967,
+			amounts:   []uint64{32, 0},
+		},
+	}
+
+	for i, tt := range tests {
+		remainder, _, amounts := tt.policy.SplitReward(tt.reward)
+		if remainder != tt.remainder {
+			t.Errorf("failed on %d: remainder mismatch: have %d, want %d", i, remainder, tt.remainder)
+		} else if len(amounts) != len(tt.amounts) {
+			t.Errorf("failed on %d: amount length mismatch: have %d, want %d", i, len(amounts), len(tt.amounts))
+		} else {
+			for j := range amounts {
+				if amounts[j] != tt.amounts[j] {
+					t.Errorf("failed on %d: amount %d mismatch: have %d, want %d", i, j, amounts[j], tt.amounts[j])
+				}
+			}
+		}
+	}
+}
+
+func TestCoinbasePolicyForSoloMiner(t *testing.T) {
+	base := &CoinbasePolicy{
+		Signature:    "/MagicPool/",
+		ExtraOutputs: []*CoinbaseOutput{{Address: "partner", Share: 100}},
+	}
+
+	policy := base.ForSoloMiner("miner", "pool", 1)
+	if policy.GetPayoutAddress("pool") != "miner" {
+		t.Errorf("payout address mismatch: have %s, want miner", policy.GetPayoutAddress("pool"))
+	} else if policy.GetSignature() != base.Signature {
+		t.Errorf("signature mismatch: have %s, want %s", policy.GetSignature(), base.Signature)
+	} else if len(policy.ExtraOutputs) != 2 || !policy.IsExtraAddress("pool") {
+		t.Errorf("fee output missing from extra outputs")
+	} else if len(base.ExtraOutputs) != 1 || base.IsExtraAddress("pool") {
+		t.Errorf("base policy modified")
+	}
+
+	var empty *CoinbasePolicy
+	policy = empty.ForSoloMiner("miner", "pool", 0)
+	if len(policy.ExtraOutputs) != 0 {
+		t.Errorf("extra outputs mismatch: have %d, want 0", len(policy.ExtraOutputs))
+	}
+}
+
+func TestParseCoinbaseOutputs(t *testing.T) {
+	tests := []struct {
+		raw     string
+		outputs []*CoinbaseOutput
+		valid   bool
+	}{
+		{
+			raw:     "",
+			outputs: []*CoinbaseOutput{},
+			valid:   true,
+		},
+		{
+			raw: "RNs3ne88DoNEnXFTqUrj6zrYejeQpcj4jk:50, kaspa:qqg0m2cq9nls4s2mlj9estz6v947l8j6vmcvv4057clh688088ftg7ce6p895:25",
+			outputs: []*CoinbaseOutput{
+				{Address: "RNs3ne88DoNEnXFTqUrj6zrYejeQpcj4jk", Share: 50},
+				{Address: "kaspa:qqg0m2cq9nls4s2mlj9estz6v947l8j6vmcvv4057clh688088ftg7ce6p895", Share: 25},
+			},
+			valid: true,
+		},
+		{
+			raw:   "RNs3ne88DoNEnXFTqUrj6zrYejeQpcj4jk",
+			valid: false,
+		},
+	}
+
+	for i, tt := range tests {
+		outputs, err := ParseCoinbaseOutputs(tt.raw)
+		if tt.valid != (err == nil) {
+			t.Errorf("failed on %d: validity mismatch: have %v, want %v", i, err == nil, tt.valid)
+		} else if len(outputs) != len(tt.outputs) {
+			t.Errorf("failed on %d: output length mismatch: have %d, want %d", i, len(outputs), len(tt.outputs))
+		} else {
+			for j := range outputs {
+				if *outputs[j] != *tt.outputs[j] {
+					t.Errorf("failed on %d: output %d mismatch: have %v, want %v", i, j, outputs[j], tt.outputs[j])
+				}
+			}
+		}
+	}
+}
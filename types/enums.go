@@ -86,6 +86,91 @@ const (
 	MergeTx
 )
 
+/* payout */
+
+type PayoutSchedule int
+
+const (
+	PayoutThreshold PayoutSchedule = iota
+	PayoutDaily
+	PayoutWeekly
+	PayoutFixed
+)
+
+func ParsePayoutSchedule(raw string) (PayoutSchedule, error) {
+	switch strings.ToLower(raw) {
+	case "threshold":
+		return PayoutThreshold, nil
+	case "daily":
+		return PayoutDaily, nil
+	case "weekly":
+		return PayoutWeekly, nil
+	case "fixed":
+		return PayoutFixed, nil
+	default:
+		return 0, fmt.Errorf("invalid payout schedule")
+	}
+}
+
+func (s PayoutSchedule) String() string {
+	switch s {
+	case PayoutThreshold:
+		return "threshold"
+	case PayoutDaily:
+		return "daily"
+	case PayoutWeekly:
+		return "weekly"
+	case PayoutFixed:
+		return "fixed"
+	default:
+		return ""
+	}
+}
+
+// LastScheduledTime returns the most recent scheduled payout time at or before now
+// (in UTC). Fixed schedules are a single payout at fixedTime, while threshold schedules
+// have no scheduled time, so the zero time is returned (as it is for a future fixed time).
+func (s PayoutSchedule) LastScheduledTime(hour, weekday int, fixedTime *time.Time, now time.Time) time.Time {
+	now = now.UTC()
+	if s == PayoutFixed {
+		if fixedTime == nil || fixedTime.After(now) {
+			return time.Time{}
+		}
+		return fixedTime.UTC()
+	}
+
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+
+	switch s {
+	case PayoutDaily:
+		if scheduled.After(now) {
+			scheduled = scheduled.AddDate(0, 0, -1)
+		}
+	case PayoutWeekly:
+		scheduled = scheduled.AddDate(0, 0, weekday-int(now.Weekday()))
+		if scheduled.After(now) {
+			scheduled = scheduled.AddDate(0, 0, -7)
+		}
+	default:
+		return time.Time{}
+	}
+
+	return scheduled
+}
+
+// IsDue returns whether a scheduled payout should be initiated, which is
+// the case when no payout has been made since the last scheduled time.
+func (s PayoutSchedule) IsDue(hour, weekday int, fixedTime, lastPayout *time.Time, now time.Time) bool {
+	scheduled := s.LastScheduledTime(hour, weekday, fixedTime, now)
+	if scheduled.IsZero() {
+		return false
+	} else if lastPayout == nil {
+		return true
+	}
+
+	return lastPayout.Before(scheduled)
+}
+
 /* chart */
 
 type PeriodType int
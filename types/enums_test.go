@@ -0,0 +1,254 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePayoutSchedule(t *testing.T) {
+	tests := []struct {
+		raw      string
+		schedule PayoutSchedule
+		valid    bool
+	}{
+		{"threshold", PayoutThreshold, true},
+		{"daily", PayoutDaily, true},
+		{"Weekly", PayoutWeekly, true},
+		{"FIXED", PayoutFixed, true},
+		{"monthly", 0, false},
+		{"", 0, false},
+	}
+
+	for i, tt := range tests {
+		schedule, err := ParsePayoutSchedule(tt.raw)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("failed on %d: expected error", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("failed on %d: %v", i, err)
+		} else if schedule != tt.schedule {
+			t.Errorf("failed on %d: schedule mismatch: have %d, want %d", i, schedule, tt.schedule)
+		} else if parsed, _ := ParsePayoutSchedule(schedule.String()); parsed != schedule {
+			t.Errorf("failed on %d: string mismatch: have %s", i, schedule.String())
+		}
+	}
+
+	if PayoutSchedule(10).String() != "" {
+		t.Errorf("unknown schedule mismatch: have %s, want empty", PayoutSchedule(10).String())
+	}
+}
+
+func TestPayoutScheduleLastScheduledTime(t *testing.T) {
+	fixedTime := func(raw string) *time.Time {
+		ts, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			panic(err)
+		}
+		return &ts
+	}
+
+	tests := []struct {
+		schedule  PayoutSchedule
+		hour      int
+		weekday   int
+		fixedTime *time.Time
+		now       string
+		scheduled string
+	}{
+		{
+			schedule:  PayoutThreshold,
+			now:       "2023-06-14T12:00:00Z",
+			scheduled: "",
+		},
+		{
+			schedule:  PayoutDaily,
+			hour:      10,
+			now:       "2023-06-14T12:00:00Z",
+			scheduled: "2023-06-14T10:00:00Z",
+		},
+		{
+			schedule:  PayoutDaily,
+			hour:      14,
+			now:       "2023-06-14T12:00:00Z",
+			scheduled: "2023-06-13T14:00:00Z",
+		},
+		{
+			schedule:  PayoutDaily,
+			hour:      12,
+			now:       "2023-06-14T14:00:00+02:00",
+			scheduled: "2023-06-14T12:00:00Z",
+		},
+		{
+			schedule:  PayoutWeekly,
+			hour:      0,
+			weekday:   int(time.Monday),
+			now:       "2023-06-14T12:00:00Z",
+			scheduled: "2023-06-12T00:00:00Z",
+		},
+		{
+			schedule:  PayoutWeekly,
+			hour:      18,
+			weekday:   int(time.Wednesday),
+			now:       "2023-06-14T12:00:00Z",
+			scheduled: "2023-06-07T18:00:00Z",
+		},
+		{
+			schedule:  PayoutWeekly,
+			hour:      6,
+			weekday:   int(time.Saturday),
+			now:       "2023-06-14T12:00:00Z",
+			scheduled: "2023-06-10T06:00:00Z",
+		},
+		{
+			schedule:  PayoutFixed,
+			fixedTime: nil,
+			now:       "2023-06-14T12:00:00Z",
+			scheduled: "",
+		},
+		{
+			schedule:  PayoutFixed,
+			fixedTime: fixedTime("2023-06-01T08:30:00Z"),
+			now:       "2023-06-14T12:00:00Z",
+			scheduled: "2023-06-01T08:30:00Z",
+		},
+		{
+			schedule:  PayoutFixed,
+			fixedTime: fixedTime("2023-06-14T12:30:00Z"),
+			now:       "2023-06-14T12:00:00Z",
+			scheduled: "",
+		},
+	}
+
+	for i, tt := range tests {
+		now, err := time.Parse(time.RFC3339, tt.now)
+		if err != nil {
+			t.Errorf("failed on %d: parse now: %v", i, err)
+			continue
+		}
+
+		scheduled := tt.schedule.LastScheduledTime(tt.hour, tt.weekday, tt.fixedTime, now)
+		if tt.scheduled == "" {
+			if !scheduled.IsZero() {
+				t.Errorf("failed on %d: scheduled mismatch: have %s, want zero", i, scheduled)
+			}
+		} else if scheduled.Format(time.RFC3339) != tt.scheduled {
+			t.Errorf("failed on %d: scheduled mismatch: have %s, want %s",
+				i, scheduled.Format(time.RFC3339), tt.scheduled)
+		}
+	}
+}
+
+func TestPayoutScheduleIsDue(t *testing.T) {
+	lastPayout := func(raw string) *time.Time {
+		ts, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			panic(err)
+		}
+		return &ts
+	}
+
+	tests := []struct {
+		schedule   PayoutSchedule
+		hour       int
+		weekday    int
+		fixedTime  *time.Time
+		lastPayout *time.Time
+		now        string
+		due        bool
+	}{
+		{
+			schedule:   PayoutThreshold,
+			lastPayout: nil,
+			now:        "2023-06-14T12:00:00Z",
+			due:        false,
+		},
+		{
+			schedule:   PayoutDaily,
+			hour:       10,
+			lastPayout: nil,
+			now:        "2023-06-14T12:00:00Z",
+			due:        true,
+		},
+		{
+			schedule:   PayoutDaily,
+			hour:       10,
+			lastPayout: lastPayout("2023-06-14T10:05:00Z"),
+			now:        "2023-06-14T12:00:00Z",
+			due:        false,
+		},
+		{
+			schedule:   PayoutDaily,
+			hour:       10,
+			lastPayout: lastPayout("2023-06-13T10:05:00Z"),
+			now:        "2023-06-14T12:00:00Z",
+			due:        true,
+		},
+		{
+			schedule:   PayoutDaily,
+			hour:       14,
+			lastPayout: lastPayout("2023-06-13T14:05:00Z"),
+			now:        "2023-06-14T12:00:00Z",
+			due:        false,
+		},
+		{
+			schedule:   PayoutWeekly,
+			hour:       0,
+			weekday:    int(time.Monday),
+			lastPayout: lastPayout("2023-06-05T00:05:00Z"),
+			now:        "2023-06-14T12:00:00Z",
+			due:        true,
+		},
+		{
+			schedule:   PayoutWeekly,
+			hour:       0,
+			weekday:    int(time.Monday),
+			lastPayout: lastPayout("2023-06-12T00:05:00Z"),
+			now:        "2023-06-14T12:00:00Z",
+			due:        false,
+		},
+		{
+			schedule:   PayoutWeekly,
+			hour:       18,
+			weekday:    int(time.Wednesday),
+			lastPayout: lastPayout("2023-06-07T18:05:00Z"),
+			now:        "2023-06-14T12:00:00Z",
+			due:        false,
+		},
+		{
+			schedule:   PayoutFixed,
+			fixedTime:  lastPayout("2023-06-14T11:30:00Z"),
+			lastPayout: lastPayout("2023-06-01T00:05:00Z"),
+			now:        "2023-06-14T12:00:00Z",
+			due:        true,
+		},
+		{
+			schedule:   PayoutFixed,
+			fixedTime:  lastPayout("2023-06-14T11:30:00Z"),
+			lastPayout: lastPayout("2023-06-14T11:35:00Z"),
+			now:        "2023-06-14T12:00:00Z",
+			due:        false,
+		},
+		{
+			schedule:   PayoutFixed,
+			fixedTime:  lastPayout("2023-06-15T11:30:00Z"),
+			lastPayout: nil,
+			now:        "2023-06-14T12:00:00Z",
+			due:        false,
+		},
+	}
+
+	for i, tt := range tests {
+		now, err := time.Parse(time.RFC3339, tt.now)
+		if err != nil {
+			t.Errorf("failed on %d: parse now: %v", i, err)
+			continue
+		}
+
+		due := tt.schedule.IsDue(tt.hour, tt.weekday, tt.fixedTime, tt.lastPayout, now)
+		if due != tt.due {
+			t.Errorf("failed on %d: due mismatch: have %t, want %t", i, due, tt.due)
+		}
+	}
+}
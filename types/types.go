@@ -10,6 +10,7 @@ import (
 
 	"github.com/magicpool-co/pool/internal/pooldb"
 	"github.com/magicpool-co/pool/internal/tsdb"
+	"github.com/magicpool-co/pool/pkg/hostpool"
 )
 
 /* stratum */
@@ -39,6 +40,9 @@ type StratumWork struct {
 	WorkerID         string
 	JobID            string
 	Nonce            *Number
+	Version          *Number // for version rolling (BIP310)
+	Time             *Number // for ntime rolling (sha256d)
+	ExtraNonce       []byte  // for coinbase extranonce (sha256d)
 	Hash             *Hash
 	MixDigest        *Hash     // for ethash/progpow
 	CuckooSolution   *Solution // for cuckoo
@@ -80,7 +84,9 @@ type TxResponse struct {
 	Fee         *big.Int
 	FeeBalance  *big.Int
 	Confirmed   bool
-	Outputs     []*UTXOResponse
+	// Failed is set for txs that are included in a block but were reverted
+	Failed  bool
+	Outputs []*UTXOResponse
 }
 
 /* node */
@@ -104,6 +110,27 @@ type PayoutNode interface {
 	BroadcastTx(string) (string, error)
 }
 
+// BatchPayoutNode is implemented by account-based payout nodes that
+// are able to pay multiple recipients in a single (contract) transaction.
+type BatchPayoutNode interface {
+	PayoutNode
+	GetBatchSize() int
+	IsContractAddress(string) (bool, error)
+	// ApproveBatchPayouts makes sure the batch contract is allowed to spend the wallet's
+	// funds, returning false while the approval is still pending (only needed for tokens)
+	// or if no batch contract is configured.
+	ApproveBatchPayouts() (bool, error)
+	// SetDisperseAddress sets the batch (disperse) contract, which is configured per chain.
+	SetDisperseAddress(string) error
+}
+
+// MessageSigningNode is implemented by payout nodes that are able to sign
+// arbitrary messages with the wallet key to prove ownership of the wallet.
+type MessageSigningNode interface {
+	PayoutNode
+	SignMessage(string) (string, error)
+}
+
 type MiningNode interface {
 	PayoutNode
 	Mocked() bool
@@ -119,8 +146,8 @@ type MiningNode interface {
 
 	// stratum helpers
 	GetSubscribeResponses([]byte, string, string) ([]interface{}, error)
-	GetAuthorizeResponses(int) ([]interface{}, error)
-	GetSetDifficultyResponse(int) (interface{}, error)
+	GetAuthorizeResponses(int, int) ([]interface{}, error)
+	GetSetDifficultyResponse(int, int) (interface{}, error)
 	GetClientType(string) int
 	MarshalJob(interface{}, *StratumJob, bool, int, int) (interface{}, error)
 	ParseWork([]json.RawMessage, string) (*StratumWork, error)
@@ -137,6 +164,42 @@ type MiningNode interface {
 	MatureRound(*pooldb.Round) ([]*pooldb.UTXO, error)
 }
 
+// CoinbasePolicyNode is implemented by mining nodes that generate their
+// own coinbase and can apply a pool coinbase policy to it.
+type CoinbasePolicyNode interface {
+	MiningNode
+	SetCoinbasePolicy(*CoinbasePolicy) error
+}
+
+// SoloJobNode is implemented by mining nodes that are able to rebuild a job
+// with a coinbase paying the miner reward directly to a solo miner's address,
+// minus a pool fee (in basis points) paid to the pool address.
+type SoloJobNode interface {
+	CoinbasePolicyNode
+	GetSoloJob(*StratumJob, string, uint64) (*StratumJob, error)
+}
+
+// BroadcastNode is implemented by mining nodes that submit blocks to
+// every synced host, the handler receives the per-host results.
+type BroadcastNode interface {
+	MiningNode
+	SetBroadcastHandler(hostpool.BroadcastHandler)
+}
+
+// ChainTipNode is implemented by mining nodes with a single main chain block
+// per height, it returns the main chain hash for every height in the range
+// without fetching the blocks themselves.
+type ChainTipNode interface {
+	MiningNode
+	GetBlockHashes(uint64, uint64) ([]string, error)
+}
+
+// HostRequestNode is implemented by nodes backed by a host pool, the
+// handler receives the result of every request to an individual host.
+type HostRequestNode interface {
+	SetRequestHandler(hostpool.RequestHandler)
+}
+
 /* exchange */
 
 type Exchange interface {